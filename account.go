@@ -9,11 +9,10 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
-	"regexp"
 	"strings"
+	"time"
 
 	"github.com/circonus-labs/go-apiclient/config"
 	"github.com/pkg/errors"
@@ -26,6 +25,29 @@ type AccountLimit struct {
 	Used  uint   `json:"_used,omitempty"`  // uint >=0
 }
 
+// Account usage types, see AccountLimit.Type
+const (
+	AccountUsageCheck  = "Check"
+	AccountUsageHost   = "Host"
+	AccountUsageMetric = "Metric"
+)
+
+// Remaining returns the units left before the limit is reached (0 if the
+// limit has been reached or exceeded).
+func (l AccountLimit) Remaining() uint {
+	if l.Used >= l.Limit {
+		return 0
+	}
+	return l.Limit - l.Used
+}
+
+// AccountCapacity is a typed view of the usage and limits of an account
+type AccountCapacity struct {
+	Checks  *AccountLimit // nil if not reported for the account
+	Hosts   *AccountLimit // nil if not reported for the account
+	Metrics *AccountLimit // nil if not reported for the account
+}
+
 // AccountInvite defines outstanding invites
 type AccountInvite struct {
 	Email string `json:"email"` // string
@@ -40,26 +62,33 @@ type AccountUser struct {
 
 // Account defines an account. See https://login.circonus.com/resources/api/calls/account for more information.
 type Account struct {
-	Address1      *string         `json:"address1,omitempty"`        // string or null
-	Address2      *string         `json:"address2,omitempty"`        // string or null
-	CCEmail       *string         `json:"cc_email,omitempty"`        // string or null
-	CID           string          `json:"_cid,omitempty"`            // string
-	City          *string         `json:"city,omitempty"`            // string or null
-	ContactGroups []string        `json:"_contact_groups,omitempty"` // [] len >= 0
-	Country       string          `json:"country_code,omitempty"`    // string
-	Description   *string         `json:"description,omitempty"`     // string or null
-	Invites       []AccountInvite `json:"invites,omitempty"`         // [] len >= 0
-	Name          string          `json:"name,omitempty"`            // string
-	OwnerCID      string          `json:"_owner,omitempty"`          // string
-	StateProv     *string         `json:"state_prov,omitempty"`      // string or null
-	Timezone      string          `json:"timezone,omitempty"`        // string
-	UIBaseURL     string          `json:"_ui_base_url,omitempty"`    // string
-	Usage         []AccountLimit  `json:"_usage,omitempty"`          // [] len >= 0
-	Users         []AccountUser   `json:"users,omitempty"`           // [] len >= 0
+	Address1      *string         `json:"address1,omitempty"`          // string or null
+	Address2      *string         `json:"address2,omitempty"`          // string or null
+	CCEmail       *string         `json:"cc_email,omitempty"`          // string or null
+	CID           string          `json:"_cid,omitempty"`              // string
+	City          *string         `json:"city,omitempty"`              // string or null
+	ContactGroups []string        `json:"_contact_groups,omitempty"`   // [] len >= 0
+	Country       string          `json:"country_code,omitempty"`      // string
+	Dashboard     *string         `json:"default_dashboard,omitempty"` // string or null
+	Description   *string         `json:"description,omitempty"`       // string or null
+	Invites       []AccountInvite `json:"invites,omitempty"`           // [] len >= 0
+	Name          string          `json:"name,omitempty"`              // string
+	OwnerCID      string          `json:"_owner,omitempty"`            // string
+	StateProv     *string         `json:"state_prov,omitempty"`        // string or null
+	Timezone      string          `json:"timezone,omitempty"`          // string
+	UIBaseURL     string          `json:"_ui_base_url,omitempty"`      // string
+	Usage         []AccountLimit  `json:"_usage,omitempty"`            // [] len >= 0
+	Users         []AccountUser   `json:"users,omitempty"`             // [] len >= 0
 }
 
 // FetchAccount retrieves account with passed cid. Pass nil for '/account/current'.
 func (a *API) FetchAccount(cid CIDType) (*Account, error) {
+	return a.FetchAccountWithContext(context.Background(), cid)
+}
+
+// FetchAccountWithContext retrieves account with passed cid, stopping when
+// ctx is done.
+func (a *API) FetchAccountWithContext(ctx context.Context, cid CIDType) (*Account, error) {
 	var accountCID string
 
 	switch {
@@ -73,41 +102,81 @@ func (a *API) FetchAccount(cid CIDType) (*Account, error) {
 		accountCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.AccountCIDRegex, accountCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.AccountPrefix, accountCID) {
 		return nil, errors.Errorf("invalid account CID (%s)", accountCID)
 	}
 
-	result, err := a.Get(accountCID)
+	result, err := a.GetWithContext(ctx, accountCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching account")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch account, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch account, received JSON: %s", string(result))
 	}
 
 	account := new(Account)
-	if err := json.Unmarshal(result, account); err != nil {
+	if err := a.unmarshalJSON(result, account); err != nil {
 		return nil, errors.Wrap(err, "parsing account")
 	}
 
 	return account, nil
 }
 
-// FetchAccounts retrieves all accounts available to the API Token.
-func (a *API) FetchAccounts() (*[]Account, error) {
-	result, err := a.Get(config.AccountPrefix)
-	if err != nil {
-		return nil, errors.Wrap(err, "fetching accounts")
+// FetchCurrentAccount retrieves the account associated with the API Token ('/account/current').
+func (a *API) FetchCurrentAccount() (*Account, error) {
+	return a.FetchCurrentAccountWithContext(context.Background())
+}
+
+// FetchCurrentAccountWithContext retrieves the account associated with the
+// API Token ('/account/current'), stopping when ctx is done.
+func (a *API) FetchCurrentAccountWithContext(ctx context.Context) (*Account, error) {
+	cid := config.AccountPrefix + "/current"
+	return a.FetchAccountWithContext(ctx, CIDType(&cid))
+}
+
+// UsageFor returns the usage and limit of the passed type (e.g. AccountUsageMetric).
+func (acct *Account) UsageFor(usageType string) (*AccountLimit, bool) {
+	for i := range acct.Usage {
+		if strings.EqualFold(acct.Usage[i].Type, usageType) {
+			return &acct.Usage[i], true
+		}
 	}
+	return nil, false
+}
+
+// Capacity returns a typed view of the account usage and limits.
+func (acct *Account) Capacity() AccountCapacity {
+	var c AccountCapacity
+	c.Checks, _ = acct.UsageFor(AccountUsageCheck)
+	c.Hosts, _ = acct.UsageFor(AccountUsageHost)
+	c.Metrics, _ = acct.UsageFor(AccountUsageMetric)
+	return c
+}
+
+// Headroom returns the units of the passed usage type which can still be
+// provisioned on the account. Returns false if the account does not report
+// usage of that type.
+func (acct *Account) Headroom(usageType string) (uint, bool) {
+	l, ok := acct.UsageFor(usageType)
+	if !ok {
+		return 0, false
+	}
+	return l.Remaining(), true
+}
 
+// FetchAccounts retrieves all accounts available to the API Token. Use
+// WithAccount to act on a specific account.
+func (a *API) FetchAccounts() (*[]Account, error) {
+	return a.FetchAccountsWithContext(context.Background())
+}
+
+// FetchAccountsWithContext retrieves all accounts available to the API Token,
+// stopping when ctx is done.
+func (a *API) FetchAccountsWithContext(ctx context.Context) (*[]Account, error) {
 	var accounts []Account
-	if err := json.Unmarshal(result, &accounts); err != nil {
-		return nil, errors.Wrap(err, "parsing accounts")
+	if err := a.getJSONContext(ctx, config.AccountPrefix, &accounts); err != nil {
+		return nil, errors.Wrap(err, "fetching accounts")
 	}
 
 	return &accounts, nil
@@ -115,36 +184,37 @@ func (a *API) FetchAccounts() (*[]Account, error) {
 
 // UpdateAccount updates passed account.
 func (a *API) UpdateAccount(cfg *Account) (*Account, error) {
+	return a.UpdateAccountWithContext(context.Background(), cfg)
+}
+
+// UpdateAccountWithContext updates passed account, stopping when ctx is done.
+func (a *API) UpdateAccountWithContext(ctx context.Context, cfg *Account) (*Account, error) {
 	if cfg == nil {
 		return nil, errors.Errorf("invalid account config (nil)")
 	}
 
 	accountCID := cfg.CID
 
-	matched, err := regexp.MatchString(config.AccountCIDRegex, accountCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.AccountPrefix, accountCID) {
 		return nil, errors.Errorf("invalid account CID (%s)", accountCID)
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("account update, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("account update, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Put(accountCID, jsonCfg)
+	result, err := a.PutWithContext(ctx, accountCID, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "updating account")
 	}
 
 	account := &Account{}
-	if err := json.Unmarshal(result, account); err != nil {
+	if err := a.unmarshalJSON(result, account); err != nil {
 		return nil, errors.Wrap(err, "parsing account")
 	}
 
@@ -155,34 +225,195 @@ func (a *API) UpdateAccount(cfg *Account) (*Account, error) {
 // supported by the account endpoint). Pass nil as filter for all accounts the
 // API Token can access.
 func (a *API) SearchAccounts(filterCriteria *SearchFilterType) (*[]Account, error) {
-	q := url.Values{}
+	return a.SearchAccountsWithContext(context.Background(), filterCriteria)
+}
 
-	if filterCriteria != nil && len(*filterCriteria) > 0 {
-		for filter, criteria := range *filterCriteria {
-			for _, val := range criteria {
-				q.Add(filter, val)
-			}
+// SearchAccountsWithContext returns accounts matching a filter (search
+// queries are not supported by the account endpoint), stopping when ctx is
+// done.
+func (a *API) SearchAccountsWithContext(ctx context.Context, filterCriteria *SearchFilterType) (*[]Account, error) {
+	if emptySearch(nil, filterCriteria) {
+		return a.FetchAccountsWithContext(ctx)
+	}
+
+	var accounts []Account
+	if err := a.searchJSONContext(ctx, config.AccountPrefix, nil, filterCriteria, &accounts); err != nil {
+		return nil, errors.Wrap(err, "searching accounts")
+	}
+
+	return &accounts, nil
+}
+
+// InviteUser invites the passed email address to the current account with the
+// passed role. Returns the updated account; an existing invite for the address
+// is replaced if the role differs.
+func (a *API) InviteUser(email string, role AccountRole) (*Account, error) {
+	if email == "" {
+		return nil, errors.New("invalid invite email (none)")
+	}
+	if !strings.Contains(email, "@") {
+		return nil, errors.Errorf("invalid invite email (%s)", email)
+	}
+	if role.rank() == 0 {
+		return nil, errors.Errorf("invalid invite role (%s)", role)
+	}
+
+	acct, err := a.FetchCurrentAccount()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, invite := range acct.Invites {
+		if !strings.EqualFold(invite.Email, email) {
+			continue
 		}
+		if AccountRole(invite.Role).rank() == role.rank() {
+			return acct, nil
+		}
+		acct.Invites[i].Role = string(role)
+		return a.UpdateAccount(acct)
 	}
 
-	if q.Encode() == "" {
-		return a.FetchAccounts()
+	acct.Invites = append(acct.Invites, AccountInvite{Email: email, Role: string(role)})
+	return a.UpdateAccount(acct)
+}
+
+// ListPendingInvites retrieves the outstanding invites for the current account.
+func (a *API) ListPendingInvites() (*[]AccountInvite, error) {
+	acct, err := a.FetchCurrentAccount()
+	if err != nil {
+		return nil, err
 	}
 
-	reqURL := url.URL{
-		Path:     config.AccountPrefix,
-		RawQuery: q.Encode(),
+	invites := append([]AccountInvite{}, acct.Invites...)
+	return &invites, nil
+}
+
+// RevokeInvite removes the outstanding invite for the passed email address from
+// the current account. Returns false if there is no invite for the address.
+func (a *API) RevokeInvite(email string) (bool, error) {
+	if email == "" {
+		return false, errors.New("invalid invite email (none)")
 	}
 
-	result, err := a.Get(reqURL.String())
+	acct, err := a.FetchCurrentAccount()
 	if err != nil {
-		return nil, errors.Wrap(err, "searching accounts")
+		return false, err
 	}
 
-	var accounts []Account
-	if err := json.Unmarshal(result, &accounts); err != nil {
+	for i, invite := range acct.Invites {
+		if strings.EqualFold(invite.Email, email) {
+			acct.Invites = append(acct.Invites[:i], acct.Invites[i+1:]...)
+			if _, err := a.UpdateAccount(acct); err != nil {
+				return false, errors.Wrapf(err, "revoking invite for %s", email)
+			}
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ForEachAccount calls fn for every account available to the API Token, passing
+// an API which acts on that account. Stops at the first error returned by fn.
+func (a *API) ForEachAccount(fn func(acct *Account, api *API) error) error {
+	if fn == nil {
+		return errors.New("invalid account func (nil)")
+	}
+
+	accounts, err := a.FetchAccounts()
+	if err != nil {
+		return err
+	}
+
+	for i := range *accounts {
+		acct := &(*accounts)[i]
+		acctAPI, err := a.WithAccount(acct.CID)
+		if err != nil {
+			return err
+		}
+		if err := fn(acct, acctAPI); err != nil {
+			return errors.Wrapf(err, "account %s", acct.CID)
+		}
+	}
+
+	return nil
+}
+
+// AccountDefaults defines the default settings of an account. Empty values are
+// left unchanged by UpdateAccountDefaults.
+type AccountDefaults struct {
+	Country     string // ISO 3166-1 alpha-2 country code
+	Dashboard   string // dashboard cid
+	Description string
+	Timezone    string // IANA time zone name (e.g. America/New_York)
+}
+
+// Defaults returns the default settings of the account.
+func (acct *Account) Defaults() AccountDefaults {
+	d := AccountDefaults{
+		Country:  acct.Country,
+		Timezone: acct.Timezone,
+	}
+	if acct.Dashboard != nil {
+		d.Dashboard = *acct.Dashboard
+	}
+	if acct.Description != nil {
+		d.Description = *acct.Description
+	}
+	return d
+}
+
+// UpdateAccountDefaults applies the non-empty default settings to the account
+// with passed cid (nil for the current account). The account is only updated
+// if a setting changes.
+func (a *API) UpdateAccountDefaults(cid CIDType, defaults AccountDefaults) (*Account, error) {
+	return a.UpdateAccountDefaultsWithContext(context.Background(), cid, defaults)
+}
+
+// UpdateAccountDefaultsWithContext applies the non-empty default settings to
+// the account with passed cid (nil for the current account), stopping when
+// ctx is done.
+func (a *API) UpdateAccountDefaultsWithContext(ctx context.Context, cid CIDType, defaults AccountDefaults) (*Account, error) {
+	if defaults.Country != "" && (len(defaults.Country) != 2 || strings.ToUpper(defaults.Country) != defaults.Country) {
+		return nil, errors.Errorf("invalid account country code (%s)", defaults.Country)
+	}
+	if defaults.Timezone != "" {
+		if _, err := time.LoadLocation(defaults.Timezone); err != nil {
+			return nil, errors.Errorf("invalid account timezone (%s)", defaults.Timezone)
+		}
+	}
+	if defaults.Dashboard != "" && !strings.HasPrefix(defaults.Dashboard, config.DashboardPrefix) {
+		defaults.Dashboard = fmt.Sprintf("%s/%s", config.DashboardPrefix, defaults.Dashboard)
+	}
+
+	acct, err := a.FetchAccountWithContext(ctx, cid)
+	if err != nil {
 		return nil, err
 	}
 
-	return &accounts, nil
+	current := acct.Defaults()
+	changed := false
+	if defaults.Country != "" && defaults.Country != current.Country {
+		acct.Country = defaults.Country
+		changed = true
+	}
+	if defaults.Timezone != "" && defaults.Timezone != current.Timezone {
+		acct.Timezone = defaults.Timezone
+		changed = true
+	}
+	if defaults.Dashboard != "" && defaults.Dashboard != current.Dashboard {
+		acct.Dashboard = &defaults.Dashboard
+		changed = true
+	}
+	if defaults.Description != "" && defaults.Description != current.Description {
+		acct.Description = &defaults.Description
+		changed = true
+	}
+
+	if !changed {
+		return acct, nil
+	}
+
+	return a.UpdateAccountWithContext(ctx, acct)
 }
@@ -270,3 +270,289 @@ func TestSearchAccounts(t *testing.T) {
 		})
 	}
 }
+
+func TestAccountCapacity(t *testing.T) {
+	acct := testAccount
+	acct.Usage = []AccountLimit{
+		{Limit: 50, Type: "Host", Used: 7},
+		{Limit: 500, Type: "metric", Used: 600},
+	}
+
+	c := acct.Capacity()
+	if c.Checks != nil {
+		t.Fatalf("expected nil checks, got %#v", c.Checks)
+	}
+	if c.Hosts == nil || c.Hosts.Used != 7 {
+		t.Fatalf("unexpected hosts (%#v)", c.Hosts)
+	}
+	if c.Metrics == nil || c.Metrics.Limit != 500 {
+		t.Fatalf("unexpected metrics (%#v)", c.Metrics)
+	}
+
+	tests := []struct {
+		usageType string
+		expected  uint
+		found     bool
+	}{
+		{AccountUsageHost, 43, true},
+		{AccountUsageMetric, 0, true},
+		{AccountUsageCheck, 0, false},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.usageType, func(t *testing.T) {
+			n, ok := acct.Headroom(test.usageType)
+			if ok != test.found {
+				t.Fatalf("expected found %t", test.found)
+			}
+			if n != test.expected {
+				t.Fatalf("expected %d, got %d", test.expected, n)
+			}
+		})
+	}
+}
+
+func TestFetchCurrentAccount(t *testing.T) {
+	apih, server := accountTestBootstrap(t)
+	defer server.Close()
+
+	acct, err := apih.FetchCurrentAccount()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if n, ok := acct.Headroom(AccountUsageHost); !ok || n != 43 {
+		t.Fatalf("unexpected host headroom (%d)", n)
+	}
+}
+
+func TestInviteUser(t *testing.T) {
+	apih, server := accountTestBootstrap(t)
+	defer server.Close()
+
+	tests := []struct {
+		id              string
+		email           string
+		role            AccountRole
+		expectedInvites []AccountInvite
+		shouldFail      bool
+		expectedErr     string
+	}{
+		{"invalid (email none)", "", AccountRoleNormal, nil, true, "invalid invite email (none)"},
+		{"invalid (email)", "bob", AccountRoleNormal, nil, true, "invalid invite email (bob)"},
+		{"invalid (role)", "bob@example.com", AccountRole("Owner"), nil, true, "invalid invite role (Owner)"},
+		{"existing", "alan@example.com", AccountRoleAdmin, testAccount.Invites, false, ""},
+		{"changed role", "Alan@example.com", AccountRoleReadOnly, []AccountInvite{
+			{Email: "alan@example.com", Role: "Read-Only"},
+			testAccount.Invites[1],
+		}, false, ""},
+		{"new", "bob@example.com", AccountRoleNormal, append(append([]AccountInvite{}, testAccount.Invites...), AccountInvite{Email: "bob@example.com", Role: "Normal"}), false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			acct, err := apih.InviteUser(test.email, test.role)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if !reflect.DeepEqual(acct.Invites, test.expectedInvites) {
+				t.Fatalf("unexpected invites (%#v)", acct.Invites)
+			}
+		})
+	}
+}
+
+func TestListPendingInvites(t *testing.T) {
+	apih, server := accountTestBootstrap(t)
+	defer server.Close()
+
+	invites, err := apih.ListPendingInvites()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if !reflect.DeepEqual(*invites, testAccount.Invites) {
+		t.Fatalf("unexpected invites (%#v)", *invites)
+	}
+}
+
+func TestRevokeInvite(t *testing.T) {
+	apih, server := accountTestBootstrap(t)
+	defer server.Close()
+
+	tests := []struct {
+		id          string
+		email       string
+		expected    bool
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (none)", "", false, true, "invalid invite email (none)"},
+		{"not found", "bob@example.com", false, false, ""},
+		{"revoked", "chris.robinson@example.com", true, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			revoked, err := apih.RevokeInvite(test.email)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if revoked != test.expected {
+				t.Fatalf("expected %t", test.expected)
+			}
+		})
+	}
+}
+
+func TestForEachAccount(t *testing.T) {
+	accounts := []Account{{CID: "/account/1234"}, {CID: "/account/5678"}}
+	seen := map[string]string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/account":
+			ret, err := json.Marshal(accounts)
+			if err != nil {
+				panic(err)
+			}
+			w.WriteHeader(200)
+			fmt.Fprintln(w, string(ret))
+		case "/user/current":
+			seen[r.Header.Get("X-Circonus-Account-ID")] = r.URL.Path
+			w.WriteHeader(200)
+			fmt.Fprintln(w, `{"_cid":"/user/1"}`)
+		default:
+			w.WriteHeader(404)
+			fmt.Fprintln(w, "not found")
+		}
+	}))
+	defer server.Close()
+
+	apih, err := NewAPI(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if _, err := apih.WithAccount(""); err == nil || err.Error() != "invalid account CID (none)" {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+	if _, err := apih.WithAccount("/user/1234"); err == nil || err.Error() != "invalid account CID (/user/1234)" {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+	if err := apih.ForEachAccount(nil); err == nil {
+		t.Fatal("expected error")
+	}
+
+	err = apih.ForEachAccount(func(acct *Account, api *API) error {
+		_, err := api.FetchCurrentUser()
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(seen) != 2 || seen["1234"] == "" || seen["5678"] == "" {
+		t.Fatalf("unexpected account ids (%v)", seen)
+	}
+
+	err = apih.ForEachAccount(func(acct *Account, api *API) error {
+		return fmt.Errorf("stop")
+	})
+	if err == nil || err.Error() != "account /account/1234: stop" {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+}
+
+func TestWithAccountCopy(t *testing.T) {
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: "http://127.0.0.1:1/v2", CompressThreshold: 1024})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	apih.EnableExponentialBackoff()
+	acct, err := apih.WithAccount("/account/2")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if acct.accountID != "2" {
+		t.Fatalf("unexpected account id (%s)", acct.accountID)
+	}
+
+	// every field is copied, except the account and the locks
+	orig, cp := *apih, *acct
+	orig.accountID, orig.logmu, orig.useExponentialBackoffmu = "", nil, nil
+	cp.accountID, cp.logmu, cp.useExponentialBackoffmu = "", nil, nil
+	if !reflect.DeepEqual(orig, cp) {
+		t.Fatalf("unexpected copy\n%+v\nexpected\n%+v", cp, orig)
+	}
+	if acct.logmu == apih.logmu || acct.useExponentialBackoffmu == apih.useExponentialBackoffmu {
+		t.Fatal("locks shared with the copy")
+	}
+
+	// the settings of the copy are its own
+	acct.SetDebug(true)
+	acct.DisableExponentialBackoff()
+	if apih.debugEnabled() || !apih.useExponentialBackoff {
+		t.Fatal("settings of the copy changed the API")
+	}
+}
+
+func TestUpdateAccountDefaults(t *testing.T) {
+	apih, server := accountTestBootstrap(t)
+	defer server.Close()
+
+	tests := []struct {
+		id          string
+		defaults    AccountDefaults
+		expected    AccountDefaults
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (country)", AccountDefaults{Country: "usa"}, AccountDefaults{}, true, "invalid account country code (usa)"},
+		{"invalid (timezone)", AccountDefaults{Timezone: "Mars/Olympus_Mons"}, AccountDefaults{}, true, "invalid account timezone (Mars/Olympus_Mons)"},
+		{"unchanged", AccountDefaults{}, testAccount.Defaults(), false, ""},
+		{"changed", AccountDefaults{Country: "CA", Dashboard: "1234", Timezone: "America/Toronto"}, AccountDefaults{
+			Country:     "CA",
+			Dashboard:   "/dashboard/1234",
+			Description: *testAccount.Description,
+			Timezone:    "America/Toronto",
+		}, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			acct, err := apih.UpdateAccountDefaults(nil, test.defaults)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if d := acct.Defaults(); !reflect.DeepEqual(d, test.expected) {
+				t.Fatalf("unexpected defaults (%#v)", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Account usage trends - sample account usage and compute changes over time
+
+package apiclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AccountUsageSnapshot defines the usage of an account at a point in time
+type AccountUsageSnapshot struct {
+	AccountCID string
+	Time       time.Time
+	Used       map[string]uint // keyed by usage type (e.g. AccountUsageMetric)
+}
+
+// AccountUsageDelta defines the change in usage between two snapshots
+type AccountUsageDelta struct {
+	AccountCID string
+	From       time.Time
+	To         time.Time
+	Change     map[string]int64 // keyed by usage type, negative if usage dropped
+}
+
+// PerHour returns the change of the passed usage type normalized to one hour.
+func (d *AccountUsageDelta) PerHour(usageType string) float64 {
+	elapsed := d.To.Sub(d.From)
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(d.Change[usageType]) / elapsed.Hours()
+}
+
+// AccountUsageStore persists account usage snapshots between samples, e.g. so
+// deltas can be computed across process restarts.
+type AccountUsageStore interface {
+	// LastAccountUsage returns the most recent snapshot saved for the
+	// account, or nil if there is none.
+	LastAccountUsage(accountCID string) (*AccountUsageSnapshot, error)
+	// SaveAccountUsage saves a snapshot.
+	SaveAccountUsage(snapshot *AccountUsageSnapshot) error
+}
+
+// NewAccountUsageSnapshot returns a snapshot of the usage of the passed account.
+func NewAccountUsageSnapshot(acct *Account, at time.Time) *AccountUsageSnapshot {
+	snap := &AccountUsageSnapshot{
+		AccountCID: acct.CID,
+		Time:       at,
+		Used:       make(map[string]uint, len(acct.Usage)),
+	}
+	for _, l := range acct.Usage {
+		snap.Used[l.Type] = l.Used
+	}
+	return snap
+}
+
+// Delta returns the change in usage from the passed (earlier) snapshot. Usage
+// types missing from either snapshot are treated as zero.
+func (s *AccountUsageSnapshot) Delta(prev *AccountUsageSnapshot) *AccountUsageDelta {
+	d := &AccountUsageDelta{
+		AccountCID: s.AccountCID,
+		From:       prev.Time,
+		To:         s.Time,
+		Change:     make(map[string]int64, len(s.Used)),
+	}
+	for t, used := range s.Used {
+		d.Change[t] = int64(used) - int64(prev.Used[t])
+	}
+	for t, used := range prev.Used {
+		if _, ok := s.Used[t]; !ok {
+			d.Change[t] = -int64(used)
+		}
+	}
+	return d
+}
+
+// SnapshotAccountUsage samples the usage of the account with passed cid (nil for
+// the current account). If a store is passed, the delta from the last saved
+// snapshot is returned (nil if there is none) and the new snapshot is saved.
+func (a *API) SnapshotAccountUsage(cid CIDType, store AccountUsageStore) (*AccountUsageSnapshot, *AccountUsageDelta, error) {
+	acct, err := a.FetchAccount(cid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snap := NewAccountUsageSnapshot(acct, time.Now())
+	if store == nil {
+		return snap, nil, nil
+	}
+
+	prev, err := store.LastAccountUsage(snap.AccountCID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "loading account usage snapshot")
+	}
+	if err := store.SaveAccountUsage(snap); err != nil {
+		return nil, nil, errors.Wrap(err, "saving account usage snapshot")
+	}
+	if prev == nil {
+		return snap, nil, nil
+	}
+
+	return snap, snap.Delta(prev), nil
+}
+
+// TrackAccountUsage samples the usage of the account with passed cid every
+// interval until ctx is done, calling fn with each delta. The first sample is
+// compared with the last snapshot in the store, if one is passed; without a
+// store, snapshots are kept in memory. Returns ctx.Err() when ctx is done, or
+// the first error encountered sampling usage.
+func (a *API) TrackAccountUsage(ctx context.Context, cid CIDType, interval time.Duration, store AccountUsageStore, fn func(*AccountUsageDelta)) error {
+	if interval <= 0 {
+		return errors.Errorf("invalid account usage interval (%s)", interval)
+	}
+	if fn == nil {
+		return errors.New("invalid account usage func (nil)")
+	}
+	if store == nil {
+		store = &memoryAccountUsageStore{}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, delta, err := a.SnapshotAccountUsage(cid, store)
+		if err != nil {
+			return err
+		}
+		if delta != nil {
+			fn(delta)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// memoryAccountUsageStore keeps the last snapshot of each account in memory
+type memoryAccountUsageStore struct {
+	last map[string]*AccountUsageSnapshot
+}
+
+func (m *memoryAccountUsageStore) LastAccountUsage(accountCID string) (*AccountUsageSnapshot, error) {
+	return m.last[accountCID], nil
+}
+
+func (m *memoryAccountUsageStore) SaveAccountUsage(snapshot *AccountUsageSnapshot) error {
+	if m.last == nil {
+		m.last = make(map[string]*AccountUsageSnapshot)
+	}
+	m.last[snapshot.AccountCID] = snapshot
+	return nil
+}
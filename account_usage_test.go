@@ -0,0 +1,96 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAccountUsageSnapshotDelta(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	prev := &AccountUsageSnapshot{
+		AccountCID: "/account/1234",
+		Time:       start,
+		Used:       map[string]uint{"Host": 10, "Metric": 1000, "Check": 5},
+	}
+	cur := &AccountUsageSnapshot{
+		AccountCID: "/account/1234",
+		Time:       start.Add(2 * time.Hour),
+		Used:       map[string]uint{"Host": 8, "Metric": 1500},
+	}
+
+	d := cur.Delta(prev)
+	expected := map[string]int64{"Host": -2, "Metric": 500, "Check": -5}
+	if !reflect.DeepEqual(d.Change, expected) {
+		t.Fatalf("unexpected change (%v)", d.Change)
+	}
+	if rate := d.PerHour("Metric"); rate != 250 {
+		t.Fatalf("expected 250/hour, got %f", rate)
+	}
+}
+
+func TestSnapshotAccountUsage(t *testing.T) {
+	apih, server := accountTestBootstrap(t)
+	defer server.Close()
+
+	snap, delta, err := apih.SnapshotAccountUsage(nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if delta != nil {
+		t.Fatalf("expected nil delta without store, got %#v", delta)
+	}
+	if snap.AccountCID != testAccount.CID || snap.Used["Host"] != 7 {
+		t.Fatalf("unexpected snapshot (%#v)", snap)
+	}
+
+	store := &memoryAccountUsageStore{}
+	store.SaveAccountUsage(&AccountUsageSnapshot{
+		AccountCID: testAccount.CID,
+		Time:       time.Now().Add(-time.Hour),
+		Used:       map[string]uint{"Host": 4},
+	})
+
+	_, delta, err = apih.SnapshotAccountUsage(nil, store)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if delta == nil || delta.Change["Host"] != 3 {
+		t.Fatalf("unexpected delta (%#v)", delta)
+	}
+	if last, _ := store.LastAccountUsage(testAccount.CID); last.Used["Host"] != 7 {
+		t.Fatal("expected snapshot to be saved")
+	}
+}
+
+func TestTrackAccountUsage(t *testing.T) {
+	apih, server := accountTestBootstrap(t)
+	defer server.Close()
+
+	if err := apih.TrackAccountUsage(context.Background(), nil, 0, nil, func(*AccountUsageDelta) {}); err == nil {
+		t.Fatal("expected error")
+	}
+	if err := apih.TrackAccountUsage(context.Background(), nil, time.Millisecond, nil, nil); err == nil {
+		t.Fatal("expected error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var deltas []*AccountUsageDelta
+	err := apih.TrackAccountUsage(ctx, nil, 10*time.Millisecond, nil, func(d *AccountUsageDelta) {
+		deltas = append(deltas, d)
+		if len(deltas) == 2 {
+			cancel()
+		}
+	})
+	if err != context.Canceled {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+	if deltas[1].Change["Host"] != 0 {
+		t.Fatalf("unexpected delta (%#v)", deltas[1])
+	}
+}
@@ -9,10 +9,8 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
-	"regexp"
 	"strings"
 
 	"github.com/circonus-labs/go-apiclient/config"
@@ -39,6 +37,12 @@ func NewAcknowledgement() *Acknowledgement {
 
 // FetchAcknowledgement retrieves acknowledgement with passed cid.
 func (a *API) FetchAcknowledgement(cid CIDType) (*Acknowledgement, error) {
+	return a.FetchAcknowledgementWithContext(context.Background(), cid)
+}
+
+// FetchAcknowledgementWithContext retrieves acknowledgement with passed cid,
+// stopping when ctx is done.
+func (a *API) FetchAcknowledgementWithContext(ctx context.Context, cid CIDType) (*Acknowledgement, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.Errorf("invalid acknowledgement CID (none)")
 	}
@@ -50,25 +54,21 @@ func (a *API) FetchAcknowledgement(cid CIDType) (*Acknowledgement, error) {
 		acknowledgementCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.AcknowledgementCIDRegex, acknowledgementCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.AcknowledgementPrefix, acknowledgementCID) {
 		return nil, errors.Errorf("invalid acknowledgement CID (%s)", acknowledgementCID)
 	}
 
-	result, err := a.Get(acknowledgementCID)
+	result, err := a.GetWithContext(ctx, acknowledgementCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching acknowledgement")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch acknowledgement, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch acknowledgement, received JSON: %s", string(result))
 	}
 
 	acknowledgement := &Acknowledgement{}
-	if err := json.Unmarshal(result, acknowledgement); err != nil {
+	if err := a.unmarshalJSON(result, acknowledgement); err != nil {
 		return nil, errors.Wrap(err, "parsing acknowledgement")
 	}
 
@@ -77,14 +77,15 @@ func (a *API) FetchAcknowledgement(cid CIDType) (*Acknowledgement, error) {
 
 // FetchAcknowledgements retrieves all acknowledgements available to the API Token.
 func (a *API) FetchAcknowledgements() (*[]Acknowledgement, error) {
-	result, err := a.Get(config.AcknowledgementPrefix)
-	if err != nil {
-		return nil, errors.Wrap(err, "fetching acknowledgements")
-	}
+	return a.FetchAcknowledgementsWithContext(context.Background())
+}
 
+// FetchAcknowledgementsWithContext retrieves all acknowledgements available
+// to the API Token, stopping when ctx is done.
+func (a *API) FetchAcknowledgementsWithContext(ctx context.Context) (*[]Acknowledgement, error) {
 	var acknowledgements []Acknowledgement
-	if err := json.Unmarshal(result, &acknowledgements); err != nil {
-		return nil, errors.Wrap(err, "parsing acknowledgements")
+	if err := a.getJSONContext(ctx, config.AcknowledgementPrefix, &acknowledgements); err != nil {
+		return nil, errors.Wrap(err, "fetching acknowledgements")
 	}
 
 	return &acknowledgements, nil
@@ -92,36 +93,38 @@ func (a *API) FetchAcknowledgements() (*[]Acknowledgement, error) {
 
 // UpdateAcknowledgement updates passed acknowledgement.
 func (a *API) UpdateAcknowledgement(cfg *Acknowledgement) (*Acknowledgement, error) {
+	return a.UpdateAcknowledgementWithContext(context.Background(), cfg)
+}
+
+// UpdateAcknowledgementWithContext updates passed acknowledgement, stopping
+// when ctx is done.
+func (a *API) UpdateAcknowledgementWithContext(ctx context.Context, cfg *Acknowledgement) (*Acknowledgement, error) {
 	if cfg == nil {
 		return nil, errors.Errorf("invalid acknowledgement config (nil)")
 	}
 
 	acknowledgementCID := cfg.CID
 
-	matched, err := regexp.MatchString(config.AcknowledgementCIDRegex, acknowledgementCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.AcknowledgementPrefix, acknowledgementCID) {
 		return nil, errors.Errorf("invalid acknowledgement CID (%s)", acknowledgementCID)
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("acknowledgement update, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("acknowledgement update, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Put(acknowledgementCID, jsonCfg)
+	result, err := a.PutWithContext(ctx, acknowledgementCID, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "updating acknowledgement")
 	}
 
 	acknowledgement := &Acknowledgement{}
-	if err := json.Unmarshal(result, acknowledgement); err != nil {
+	if err := a.unmarshalJSON(result, acknowledgement); err != nil {
 		return nil, errors.Wrap(err, "parsing acknowledgement")
 	}
 
@@ -130,26 +133,32 @@ func (a *API) UpdateAcknowledgement(cfg *Acknowledgement) (*Acknowledgement, err
 
 // CreateAcknowledgement creates a new acknowledgement.
 func (a *API) CreateAcknowledgement(cfg *Acknowledgement) (*Acknowledgement, error) {
+	return a.CreateAcknowledgementWithContext(context.Background(), cfg)
+}
+
+// CreateAcknowledgementWithContext creates a new acknowledgement, stopping
+// when ctx is done.
+func (a *API) CreateAcknowledgementWithContext(ctx context.Context, cfg *Acknowledgement) (*Acknowledgement, error) {
 	if cfg == nil {
 		return nil, errors.Errorf("invalid acknowledgement config (nil)")
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	result, err := a.Post(config.AcknowledgementPrefix, jsonCfg)
+	result, err := a.PostWithContext(ctx, config.AcknowledgementPrefix, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "creating acknowledgement")
 	}
 
-	if a.Debug {
-		a.Log.Printf("acknowledgement create, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("acknowledgement create, sending JSON: %s", jsonCfg.String())
 	}
 
 	acknowledgement := &Acknowledgement{}
-	if err := json.Unmarshal(result, acknowledgement); err != nil {
+	if err := a.unmarshalJSON(result, acknowledgement); err != nil {
 		return nil, errors.Wrap(err, "parsing acknowledgement")
 	}
 
@@ -160,37 +169,19 @@ func (a *API) CreateAcknowledgement(cfg *Acknowledgement) (*Acknowledgement, err
 // the specified search query and/or filter. If nil is passed for
 // both parameters all acknowledgements will be returned.
 func (a *API) SearchAcknowledgements(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Acknowledgement, error) {
-	q := url.Values{}
-
-	if searchCriteria != nil && *searchCriteria != "" {
-		q.Set("search", string(*searchCriteria))
-	}
-
-	if filterCriteria != nil && len(*filterCriteria) > 0 {
-		for filter, criteria := range *filterCriteria {
-			for _, val := range criteria {
-				q.Add(filter, val)
-			}
-		}
-	}
-
-	if q.Encode() == "" {
-		return a.FetchAcknowledgements()
-	}
+	return a.SearchAcknowledgementsWithContext(context.Background(), searchCriteria, filterCriteria)
+}
 
-	reqURL := url.URL{
-		Path:     config.AcknowledgementPrefix,
-		RawQuery: q.Encode(),
-	}
-
-	result, err := a.Get(reqURL.String())
-	if err != nil {
-		return nil, errors.Wrap(err, "searching acknowledgements")
+// SearchAcknowledgementsWithContext returns acknowledgements matching the
+// specified search query and/or filter, stopping when ctx is done.
+func (a *API) SearchAcknowledgementsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Acknowledgement, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchAcknowledgementsWithContext(ctx)
 	}
 
 	var acknowledgements []Acknowledgement
-	if err := json.Unmarshal(result, &acknowledgements); err != nil {
-		return nil, errors.Wrap(err, "parsing acknowledgements")
+	if err := a.searchJSONContext(ctx, config.AcknowledgementPrefix, searchCriteria, filterCriteria, &acknowledgements); err != nil {
+		return nil, errors.Wrap(err, "searching acknowledgements")
 	}
 
 	return &acknowledgements, nil
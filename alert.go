@@ -8,10 +8,8 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
-	"regexp"
 	"strings"
 
 	"github.com/circonus-labs/go-apiclient/config"
@@ -41,6 +39,12 @@ type Alert struct {
 
 // FetchAlert retrieves alert with passed cid.
 func (a *API) FetchAlert(cid CIDType) (*Alert, error) {
+	return a.FetchAlertWithContext(context.Background(), cid)
+}
+
+// FetchAlertWithContext retrieves alert with passed cid, stopping when ctx is
+// done.
+func (a *API) FetchAlertWithContext(ctx context.Context, cid CIDType) (*Alert, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.New("invalid alert CID (none)")
 	}
@@ -52,25 +56,21 @@ func (a *API) FetchAlert(cid CIDType) (*Alert, error) {
 		alertCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.AlertCIDRegex, alertCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.AlertPrefix, alertCID) {
 		return nil, errors.Errorf("invalid alert CID (%s)", alertCID)
 	}
 
-	result, err := a.Get(alertCID)
+	result, err := a.GetWithContext(ctx, alertCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching alert")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch alert, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch alert, received JSON: %s", string(result))
 	}
 
 	alert := &Alert{}
-	if err := json.Unmarshal(result, alert); err != nil {
+	if err := a.unmarshalJSON(result, alert); err != nil {
 		return nil, errors.Wrap(err, "parsing alert")
 	}
 
@@ -79,14 +79,15 @@ func (a *API) FetchAlert(cid CIDType) (*Alert, error) {
 
 // FetchAlerts retrieves all alerts available to the API Token.
 func (a *API) FetchAlerts() (*[]Alert, error) {
-	result, err := a.Get(config.AlertPrefix)
-	if err != nil {
-		return nil, errors.Wrap(err, "fetching alerts")
-	}
+	return a.FetchAlertsWithContext(context.Background())
+}
 
+// FetchAlertsWithContext retrieves all alerts available to the API Token,
+// stopping when ctx is done.
+func (a *API) FetchAlertsWithContext(ctx context.Context) (*[]Alert, error) {
 	var alerts []Alert
-	if err := json.Unmarshal(result, &alerts); err != nil {
-		return nil, errors.Wrap(err, "parsing alerts")
+	if err := a.getJSONContext(ctx, config.AlertPrefix, &alerts); err != nil {
+		return nil, errors.Wrap(err, "fetching alerts")
 	}
 
 	return &alerts, nil
@@ -96,37 +97,19 @@ func (a *API) FetchAlerts() (*[]Alert, error) {
 // and/or filter. If nil is passed for both parameters all alerts
 // will be returned.
 func (a *API) SearchAlerts(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Alert, error) {
-	q := url.Values{}
-
-	if searchCriteria != nil && *searchCriteria != "" {
-		q.Set("search", string(*searchCriteria))
-	}
-
-	if filterCriteria != nil && len(*filterCriteria) > 0 {
-		for filter, criteria := range *filterCriteria {
-			for _, val := range criteria {
-				q.Add(filter, val)
-			}
-		}
-	}
-
-	if q.Encode() == "" {
-		return a.FetchAlerts()
-	}
-
-	reqURL := url.URL{
-		Path:     config.AlertPrefix,
-		RawQuery: q.Encode(),
-	}
+	return a.SearchAlertsWithContext(context.Background(), searchCriteria, filterCriteria)
+}
 
-	result, err := a.Get(reqURL.String())
-	if err != nil {
-		return nil, errors.Wrap(err, "searching alerts")
+// SearchAlertsWithContext returns alerts matching the specified search query
+// and/or filter, stopping when ctx is done.
+func (a *API) SearchAlertsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Alert, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchAlertsWithContext(ctx)
 	}
 
 	var alerts []Alert
-	if err := json.Unmarshal(result, &alerts); err != nil {
-		return nil, errors.Wrap(err, "parsing alerts")
+	if err := a.searchJSONContext(ctx, config.AlertPrefix, searchCriteria, filterCriteria, &alerts); err != nil {
+		return nil, errors.Wrap(err, "searching alerts")
 	}
 
 	return &alerts, nil
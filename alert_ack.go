@@ -0,0 +1,138 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Alert acknowledgement - acknowledging alerts, by cid or search
+
+package apiclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AcknowledgeAlert acknowledges the alert with passed cid for the passed
+// duration, with notes. An active acknowledgement of the alert is extended
+// (and its notes replaced) rather than another created. It returns the
+// acknowledgement, which the alert's AcknowledgementCID refers to.
+// Cleared alerts cannot be acknowledged.
+func (a *API) AcknowledgeAlert(cid CIDType, duration time.Duration, notes string) (*Acknowledgement, error) {
+	return a.AcknowledgeAlertWithContext(context.Background(), cid, duration, notes)
+}
+
+// AcknowledgeAlertWithContext acknowledges the alert with passed cid, see
+// AcknowledgeAlert, stopping when ctx is done.
+func (a *API) AcknowledgeAlertWithContext(ctx context.Context, cid CIDType, duration time.Duration, notes string) (*Acknowledgement, error) {
+	if duration <= 0 {
+		return nil, errors.Errorf("invalid acknowledgement duration (%s), must be greater than 0", duration)
+	}
+
+	alert, err := a.FetchAlertWithContext(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	return a.acknowledgeAlert(ctx, alert, time.Now().Add(duration), notes)
+}
+
+// UnacknowledgeAlert cancels the active acknowledgement of the alert with
+// passed cid. It returns false if the alert is not acknowledged.
+func (a *API) UnacknowledgeAlert(cid CIDType) (bool, error) {
+	return a.UnacknowledgeAlertWithContext(context.Background(), cid)
+}
+
+// UnacknowledgeAlertWithContext cancels the active acknowledgement of the
+// alert with passed cid, see UnacknowledgeAlert, stopping when ctx is done.
+func (a *API) UnacknowledgeAlertWithContext(ctx context.Context, cid CIDType) (bool, error) {
+	alert, err := a.FetchAlertWithContext(ctx, cid)
+	if err != nil {
+		return false, err
+	}
+	ack, err := a.activeAcknowledgement(ctx, alert)
+	if err != nil || ack == nil {
+		return false, err
+	}
+
+	// acknowledgements are cancelled by updating them to end now
+	ack.AcknowledgedUntil = 0
+	if _, err := a.UpdateAcknowledgementWithContext(ctx, ack); err != nil {
+		return false, errors.Wrapf(err, "unacknowledging %s", alert.CID)
+	}
+	return true, nil
+}
+
+// AcknowledgeAlertsMatching acknowledges the alerts matching the search
+// query and/or filter, as AcknowledgeAlert does, concurrently (see
+// BulkOptions). Cleared alerts are skipped. It returns the acknowledgements
+// (nil for failures) and the result of each, by alert.
+func (a *API) AcknowledgeAlertsMatching(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType, duration time.Duration, notes string, opts *BulkOptions) ([]*Acknowledgement, *BulkResult, error) {
+	if duration <= 0 {
+		return nil, nil, errors.Errorf("invalid acknowledgement duration (%s), must be greater than 0", duration)
+	}
+
+	found, err := a.FetchAllAlerts(ctx, searchCriteria, filterCriteria)
+	if err != nil {
+		return nil, nil, err
+	}
+	var alerts []*Alert
+	for i := range *found {
+		if (*found)[i].ClearedOn == nil {
+			alerts = append(alerts, &(*found)[i])
+		}
+	}
+
+	until := time.Now().Add(duration)
+	acks := make([]*Acknowledgement, len(alerts))
+	result := a.bulk(ctx, len(alerts), opts, func(ctx context.Context, i int) (string, error) {
+		ack, err := a.acknowledgeAlert(ctx, alerts[i], until, notes)
+		if err != nil {
+			return alerts[i].CID, err
+		}
+		acks[i] = ack
+		return alerts[i].CID, nil
+	})
+	return acks, result, nil
+}
+
+// acknowledgeAlert acknowledges the alert until the passed time, extending
+// its active acknowledgement if it has one
+func (a *API) acknowledgeAlert(ctx context.Context, alert *Alert, until time.Time, notes string) (*Acknowledgement, error) {
+	if alert.ClearedOn != nil {
+		return nil, errors.Errorf("alert %s cleared, not acknowledged", alert.CID)
+	}
+
+	ack, err := a.activeAcknowledgement(ctx, alert)
+	if err != nil {
+		return nil, err
+	}
+	if ack != nil {
+		ack.AcknowledgedUntil = uint(until.Unix())
+		ack.Notes = notes
+		ack, err = a.UpdateAcknowledgementWithContext(ctx, ack)
+		return ack, errors.Wrapf(err, "acknowledging %s", alert.CID)
+	}
+
+	ack, err = a.CreateAcknowledgementWithContext(ctx, &Acknowledgement{
+		AlertCID:          alert.CID,
+		AcknowledgedUntil: uint(until.Unix()),
+		Notes:             notes,
+	})
+	return ack, errors.Wrapf(err, "acknowledging %s", alert.CID)
+}
+
+// activeAcknowledgement returns the active acknowledgement of the alert,
+// nil if it has none
+func (a *API) activeAcknowledgement(ctx context.Context, alert *Alert) (*Acknowledgement, error) {
+	if alert.AcknowledgementCID == nil || *alert.AcknowledgementCID == "" {
+		return nil, nil
+	}
+	ack, err := a.FetchAcknowledgementWithContext(ctx, CIDType(alert.AcknowledgementCID))
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching acknowledgement of %s", alert.CID)
+	}
+	if !ack.Active {
+		return nil, nil
+	}
+	return ack, nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
+)
+
+func alertAckBootstrap(t *testing.T) (*API, *fakecirconus.Server) {
+	ackCID := "/acknowledgement/5"
+	cleared := uint(1500000000)
+	fake, err := fakecirconus.New(&fakecirconus.Config{Fixtures: map[string]interface{}{
+		"/alert/1":           Alert{CID: "/alert/1", Severity: 1},
+		"/alert/2":           Alert{CID: "/alert/2", Severity: 1, AcknowledgementCID: &ackCID},
+		"/alert/3":           Alert{CID: "/alert/3", Severity: 1, ClearedOn: &cleared},
+		"/alert/4":           Alert{CID: "/alert/4", Severity: 2},
+		"/acknowledgement/5": Acknowledgement{CID: ackCID, AlertCID: "/alert/2", Active: true, Notes: "looking"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	apih, err := New(&Config{TokenKey: "abc123", URL: fake.URL})
+	if err != nil {
+		fake.Close()
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	return apih, fake
+}
+
+func TestAcknowledgeAlert(t *testing.T) {
+	apih, fake := alertAckBootstrap(t)
+	defer fake.Close()
+
+	start := uint(time.Now().Add(time.Hour).Unix())
+	tests := []struct {
+		id          string
+		cid         string
+		expectedCID string // of the acknowledgement, "" for a new one
+		expectedErr string
+	}{
+		{"new", "/alert/1", "", ""},
+		{"extended", "/alert/2", "/acknowledgement/5", ""},
+		{"cleared", "/alert/3", "", "alert /alert/3 cleared, not acknowledged"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			cid := test.cid
+			ack, err := apih.AcknowledgeAlert(CIDType(&cid), time.Hour, "on it")
+			if test.expectedErr != "" {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%v)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if test.expectedCID != "" && ack.CID != test.expectedCID {
+				t.Fatalf("unexpected acknowledgement (%s)", ack.CID)
+			}
+			if ack.AlertCID != test.cid || ack.Notes != "on it" {
+				t.Fatalf("unexpected acknowledgement (%+v)", ack)
+			}
+			if until, ok := ack.AcknowledgedUntil.(float64); !ok || uint(until) < start {
+				t.Fatalf("unexpected until (%v)", ack.AcknowledgedUntil)
+			}
+		})
+	}
+
+	cid := "/alert/1"
+	if _, err := apih.AcknowledgeAlert(CIDType(&cid), 0, ""); err == nil || err.Error() != "invalid acknowledgement duration (0s), must be greater than 0" {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+}
+
+func TestUnacknowledgeAlert(t *testing.T) {
+	apih, fake := alertAckBootstrap(t)
+	defer fake.Close()
+
+	for cid, expected := range map[string]bool{"/alert/1": false, "/alert/2": true} {
+		cid := cid
+		ok, err := apih.UnacknowledgeAlert(CIDType(&cid))
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if ok != expected {
+			t.Fatalf("%s: unexpected result (%t)", cid, ok)
+		}
+	}
+
+	data, _ := fake.Object("/acknowledgement/5")
+	var ack Acknowledgement
+	if err := json.Unmarshal(data, &ack); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if ack.AcknowledgedUntil != float64(0) {
+		t.Fatalf("expected acknowledgement cancelled (%v)", ack.AcknowledgedUntil)
+	}
+}
+
+func TestAcknowledgeAlertsMatching(t *testing.T) {
+	apih, fake := alertAckBootstrap(t)
+	defer fake.Close()
+
+	// severity 1: /alert/3 is cleared and skipped
+	filter := SearchFilterType{"f__severity": []string{"1"}}
+	acks, result, err := apih.AcknowledgeAlertsMatching(context.Background(), nil, &filter, time.Hour, "incident 42", nil)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if err := result.Err(); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if !reflect.DeepEqual(result.CIDs, []string{"/alert/1", "/alert/2"}) {
+		t.Fatalf("unexpected alerts (%v)", result.CIDs)
+	}
+	for i, ack := range acks {
+		if ack == nil || ack.AlertCID != result.CIDs[i] || ack.Notes != "incident 42" {
+			t.Fatalf("unexpected acknowledgement (%+v)", ack)
+		}
+	}
+}
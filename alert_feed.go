@@ -0,0 +1,242 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Alert feed - poll alerts and deliver lifecycle events to subscribers
+
+package apiclient
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AlertEventType defines the type of an alert lifecycle event
+type AlertEventType string
+
+// Alert lifecycle events
+const (
+	AlertRaised       = AlertEventType("raised")
+	AlertAcknowledged = AlertEventType("acknowledged")
+	AlertCleared      = AlertEventType("cleared")
+)
+
+const (
+	defaultAlertFeedInterval   = 30 * time.Second
+	defaultAlertFeedMaxBackoff = 5 * time.Minute
+)
+
+// AlertEvent defines a change in the lifecycle of an alert
+type AlertEvent struct {
+	Type  AlertEventType
+	Alert Alert
+}
+
+// AlertFeedConfig defines the configuration of an alert feed
+type AlertFeedConfig struct {
+	// Search restricts the alerts in the feed (see NewSearch), optional
+	Search *SearchQueryType
+	// Filter restricts the alerts in the feed (e.g. f__severity), optional
+	Filter SearchFilterType
+	// Interval between polls - default 30s
+	Interval time.Duration
+	// MaxBackoff caps the delay between polls after errors - default 5m
+	MaxBackoff time.Duration
+	// Since is the initial cursor, alerts which occurred before it do not
+	// generate raised events (they are still tracked for acknowledgement
+	// and clearing) - default time the feed is created
+	Since time.Time
+}
+
+// alertFeedState is the last known state of an open alert
+type alertFeedState struct {
+	acknowledged bool
+}
+
+// AlertFeed polls alerts and delivers raised, acknowledged, and cleared events
+// to subscribers, each at most once per alert.
+type AlertFeed struct {
+	api         *API
+	cfg         AlertFeedConfig
+	mu          sync.Mutex
+	cursor      uint
+	open        map[string]alertFeedState
+	subscribers map[int]func(AlertEvent)
+	nextID      int
+}
+
+// NewAlertFeed returns an alert feed, call Run to start polling.
+func (a *API) NewAlertFeed(cfg *AlertFeedConfig) *AlertFeed {
+	f := &AlertFeed{
+		api:         a,
+		open:        make(map[string]alertFeedState),
+		subscribers: make(map[int]func(AlertEvent)),
+	}
+	if cfg != nil {
+		f.cfg = *cfg
+	}
+	if f.cfg.Interval <= 0 {
+		f.cfg.Interval = defaultAlertFeedInterval
+	}
+	if f.cfg.MaxBackoff <= 0 {
+		f.cfg.MaxBackoff = defaultAlertFeedMaxBackoff
+	}
+	if f.cfg.MaxBackoff < f.cfg.Interval {
+		f.cfg.MaxBackoff = f.cfg.Interval
+	}
+	if f.cfg.Since.IsZero() {
+		f.cfg.Since = time.Now()
+	}
+	f.cursor = uint(f.cfg.Since.Unix())
+	return f
+}
+
+// Subscribe registers fn to receive events, returns a func to unsubscribe.
+// Events are delivered synchronously from Poll, in the order they occur.
+func (f *AlertFeed) Subscribe(fn func(AlertEvent)) func() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := f.nextID
+	f.nextID++
+	f.subscribers[id] = fn
+
+	return func() {
+		f.mu.Lock()
+		delete(f.subscribers, id)
+		f.mu.Unlock()
+	}
+}
+
+// Cursor returns the occurrence time of the most recent alert seen.
+func (f *AlertFeed) Cursor() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return time.Unix(int64(f.cursor), 0)
+}
+
+// Poll fetches open alerts once, delivers the resulting events to subscribers,
+// and returns them.
+func (f *AlertFeed) Poll() ([]AlertEvent, error) {
+	return f.PollWithContext(context.Background())
+}
+
+// PollWithContext fetches open alerts once, see Poll, stopping when ctx is
+// done.
+func (f *AlertFeed) PollWithContext(ctx context.Context) ([]AlertEvent, error) {
+	filter := SearchFilterType{"f__cleared_on": []string{"null"}}
+	for k, v := range f.cfg.Filter {
+		filter[k] = v
+	}
+
+	alerts, err := f.api.SearchAlertsWithContext(ctx, f.cfg.Search, &filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "polling alert feed")
+	}
+
+	f.mu.Lock()
+	cursor := f.cursor
+	tracked := make(map[string]alertFeedState, len(f.open))
+	for cid, state := range f.open {
+		tracked[cid] = state
+	}
+	f.mu.Unlock()
+
+	since := uint(f.cfg.Since.Unix())
+	var events []AlertEvent
+	open := make(map[string]alertFeedState, len(*alerts))
+	for _, alert := range *alerts {
+		state := alertFeedState{acknowledged: alert.AcknowledgementCID != nil && *alert.AcknowledgementCID != ""}
+		prev, seen := tracked[alert.CID]
+		switch {
+		case !seen && alert.OccurredOn >= since:
+			events = append(events, AlertEvent{Type: AlertRaised, Alert: alert})
+			if state.acknowledged {
+				events = append(events, AlertEvent{Type: AlertAcknowledged, Alert: alert})
+			}
+		case seen && state.acknowledged && !prev.acknowledged:
+			events = append(events, AlertEvent{Type: AlertAcknowledged, Alert: alert})
+		}
+		if alert.OccurredOn > cursor {
+			cursor = alert.OccurredOn
+		}
+		open[alert.CID] = state
+		delete(tracked, alert.CID)
+	}
+
+	// alerts no longer open have cleared (or been deleted)
+	for cid := range tracked {
+		cid := cid
+		alert, err := f.api.FetchAlertWithContext(ctx, CIDType(&cid))
+		if err != nil {
+			// keep tracking, the clear is delivered on a later poll
+			open[cid] = tracked[cid]
+			continue
+		}
+		if alert.ClearedOn == nil {
+			open[cid] = tracked[cid]
+			continue
+		}
+		events = append(events, AlertEvent{Type: AlertCleared, Alert: *alert})
+	}
+
+	f.mu.Lock()
+	f.cursor = cursor
+	f.open = open
+	subscribers := make([]func(AlertEvent), 0, len(f.subscribers))
+	for id := 0; id < f.nextID; id++ {
+		if fn, ok := f.subscribers[id]; ok {
+			subscribers = append(subscribers, fn)
+		}
+	}
+	f.mu.Unlock()
+
+	for _, ev := range events {
+		for _, fn := range subscribers {
+			fn(ev)
+		}
+	}
+
+	return events, nil
+}
+
+// Run polls until ctx is done, backing off exponentially (up to MaxBackoff)
+// while polls fail. Errors are logged when Debug is enabled. Returns ctx.Err().
+func (f *AlertFeed) Run(ctx context.Context) error {
+	return f.api.pollLoop(ctx, "alert feed", f.cfg.Interval, f.cfg.MaxBackoff, func() error {
+		_, err := f.PollWithContext(ctx)
+		return err
+	})
+}
+
+// pollLoop calls poll every interval until ctx is done, backing off
+// exponentially (up to maxBackoff) while it fails. Returns ctx.Err().
+func (a *API) pollLoop(ctx context.Context, name string, interval, maxBackoff time.Duration, poll func() error) error {
+	delay := time.Duration(0)
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		if err := poll(); err != nil {
+			failures++
+			delay = interval << uint(failures)
+			if delay > maxBackoff || delay <= 0 {
+				delay = maxBackoff
+			}
+			if a.debugEnabled() {
+				a.apiLog().Printf("%s, poll failed (retry in %s): %s", name, delay, err)
+			}
+			continue
+		}
+
+		failures = 0
+		delay = interval
+	}
+}
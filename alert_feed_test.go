@@ -0,0 +1,211 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testAlertFeedServer serves the active alerts for searches with the passed
+// query, and the alerts by cid
+func testAlertFeedServer(mu *sync.Mutex, query string, active *[]Alert, alerts map[string]Alert) *httptest.Server {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var ret interface{}
+		path := r.URL.Path
+		switch {
+		case r.Method != "GET":
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
+			return
+		case path == "/alert" && r.URL.RawQuery == query:
+			ret = *active
+		default:
+			alert, ok := alerts[path]
+			if !ok {
+				w.WriteHeader(404)
+				fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
+				return
+			}
+			ret = alert
+		}
+
+		b, err := json.Marshal(ret)
+		if err != nil {
+			panic(err)
+		}
+		w.WriteHeader(200)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, string(b))
+	}
+
+	return httptest.NewServer(http.HandlerFunc(f))
+}
+
+func TestAlertFeed(t *testing.T) {
+	ack := "/acknowledgement/1"
+	cleared := uint(1483033300)
+	old := Alert{CID: "/alert/1", OccurredOn: 1483032000}
+	raised := Alert{CID: "/alert/2", OccurredOn: 1483033102}
+
+	var mu sync.Mutex
+	active := []Alert{old}
+	alerts := map[string]Alert{}
+	setActive := func(a ...Alert) {
+		mu.Lock()
+		active = a
+		mu.Unlock()
+	}
+	server := testAlertFeedServer(&mu, "f__cleared_on=null", &active, alerts)
+	defer server.Close()
+	apih := watchTestBootstrap(t, server)
+
+	feed := apih.NewAlertFeed(&AlertFeedConfig{Since: time.Unix(1483033000, 0)})
+
+	var delivered []AlertEventType
+	unsubscribe := feed.Subscribe(func(ev AlertEvent) {
+		delivered = append(delivered, ev.Type)
+	})
+
+	poll := func(expected ...AlertEventType) {
+		t.Helper()
+		events, err := feed.Poll()
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		var types []AlertEventType
+		for _, ev := range events {
+			types = append(types, ev.Type)
+		}
+		if !reflect.DeepEqual(types, expected) {
+			t.Fatalf("expected %v, got %v", expected, types)
+		}
+	}
+
+	// pre-existing alert is tracked but not raised
+	poll()
+
+	// new alert raised, once
+	setActive(old, raised)
+	poll(AlertRaised)
+	poll()
+	if !feed.Cursor().Equal(time.Unix(1483033102, 0)) {
+		t.Fatalf("unexpected cursor (%s)", feed.Cursor())
+	}
+
+	// acknowledged, once
+	acked := raised
+	acked.AcknowledgementCID = &ack
+	setActive(old, acked)
+	poll(AlertAcknowledged)
+	poll()
+
+	// cleared alerts are fetched to confirm
+	clearedAlert := old
+	clearedAlert.ClearedOn = &cleared
+	mu.Lock()
+	alerts["/alert/1"] = clearedAlert
+	mu.Unlock()
+	setActive(acked)
+	poll(AlertCleared)
+	poll()
+
+	unsubscribe()
+	setActive(acked, Alert{CID: "/alert/3", OccurredOn: 1483033400})
+	poll(AlertRaised)
+
+	expected := []AlertEventType{AlertRaised, AlertAcknowledged, AlertCleared}
+	if !reflect.DeepEqual(delivered, expected) {
+		t.Fatalf("expected %v delivered, got %v", expected, delivered)
+	}
+}
+
+func TestAlertFeedRun(t *testing.T) {
+	var mu sync.Mutex
+	active := []Alert{{CID: "/alert/1", OccurredOn: uint(time.Now().Unix())}}
+	server := testAlertFeedServer(&mu, "f__cleared_on=null&f__severity=1", &active, nil)
+	defer server.Close()
+	apih := watchTestBootstrap(t, server)
+
+	feed := apih.NewAlertFeed(&AlertFeedConfig{
+		Filter:   SearchFilterType{"f__severity": []string{"1"}},
+		Interval: 10 * time.Millisecond,
+		Since:    time.Now().Add(-time.Minute),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	feed.Subscribe(func(ev AlertEvent) {
+		if ev.Type == AlertRaised {
+			cancel()
+		}
+	})
+
+	done := make(chan error)
+	go func() { done <- feed.Run(ctx) }()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Fatalf("unexpected error (%v)", err)
+		}
+	case <-time.After(5 * time.Second):
+		cancel()
+		t.Fatal("timed out waiting for raised event")
+	}
+}
+
+func TestAlertFeedRunCancel(t *testing.T) {
+	requested := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case requested <- struct{}{}:
+		default:
+		}
+		// the poll is in flight until its request is cancelled
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	feed := apih.NewAlertFeed(&AlertFeedConfig{Interval: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- feed.Run(ctx) }()
+
+	<-requested
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Fatalf("unexpected error (%v)", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the poll in flight stopped")
+	}
+
+	if _, err := feed.PollWithContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Alert history - the alerts, acknowledgements, annotations, and
+// maintenance windows of a check or host over a time range, as one timeline
+
+package apiclient
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// TimelineEntryType defines the type of an alert history timeline entry
+type TimelineEntryType string
+
+// Alert history timeline entries
+const (
+	TimelineAlertRaised      = TimelineEntryType("alert_raised")
+	TimelineAlertCleared     = TimelineEntryType("alert_cleared")
+	TimelineAcknowledged     = TimelineEntryType("acknowledged")
+	TimelineAnnotation       = TimelineEntryType("annotation")
+	TimelineMaintenanceStart = TimelineEntryType("maintenance_start")
+	TimelineMaintenanceStop  = TimelineEntryType("maintenance_stop")
+)
+
+// AlertHistoryQuery defines the checks and time range of an alert history
+type AlertHistoryQuery struct {
+	// CheckCID defines the check (e.g. /check/1234), or Host the target of
+	// the checks, to reconstruct the history of - one is required
+	CheckCID string
+	Host     string
+
+	// Start and End define the time range - End defaults to now
+	Start time.Time
+	End   time.Time
+}
+
+// TimelineEntry defines an event of an alert history, with the object it
+// came from (only the one for its type is set)
+type TimelineEntry struct {
+	Time    time.Time
+	Type    TimelineEntryType
+	CID     string // of the alert, acknowledgement, annotation, or maintenance window
+	Summary string
+
+	Alert           *Alert
+	Acknowledgement *Acknowledgement
+	Annotation      *Annotation
+	Maintenance     *Maintenance
+}
+
+// AlertTimeline defines the history of the alerts of checks
+type AlertTimeline struct {
+	Start     time.Time
+	End       time.Time
+	CheckCIDs []string        // the checks of the history
+	Entries   []TimelineEntry // in chronological order
+}
+
+// AlertHistory reconstructs the history of the alerts of a check, or of the
+// checks of a host, between the query's Start and End: the alerts raised
+// and cleared, their acknowledgements, the annotations of the checks'
+// metrics (and those not related to any metric, e.g. deploys), and the
+// maintenance windows of the checks, their rule sets, the host, or the
+// account.
+func (a *API) AlertHistory(q *AlertHistoryQuery) (*AlertTimeline, error) {
+	if q == nil || (q.CheckCID == "" && q.Host == "") {
+		return nil, errors.New("invalid alert history query, check CID or host required")
+	}
+	if q.Start.IsZero() {
+		return nil, errors.New("invalid alert history query, start required")
+	}
+	end := q.End
+	if end.IsZero() {
+		end = time.Now()
+	}
+	if end.Before(q.Start) {
+		return nil, errors.New("invalid alert history query, end before start")
+	}
+	start, stop := uint(q.Start.Unix()), uint(end.Unix())
+	within := func(t uint) bool { return t >= start && t <= stop }
+	overlaps := func(from, to uint) bool { return from <= stop && (to == 0 || to >= start) }
+
+	timeline := &AlertTimeline{Start: q.Start, End: end}
+	items := map[string]bool{} // the CIDs maintenance windows apply to
+
+	if q.CheckCID != "" {
+		checkCID := q.CheckCID
+		if !strings.HasPrefix(checkCID, config.CheckPrefix) {
+			checkCID = fmt.Sprintf("%s/%s", config.CheckPrefix, checkCID)
+		}
+		if !validCID(config.CheckPrefix, checkCID) {
+			return nil, errors.Errorf("invalid check CID (%s)", checkCID)
+		}
+		timeline.CheckCIDs = append(timeline.CheckCIDs, checkCID)
+	}
+	if q.Host != "" {
+		filter := SearchFilterType{"f_target": []string{q.Host}}
+		bundles, err := a.SearchCheckBundles(nil, &filter)
+		if err != nil {
+			return nil, errors.Wrap(err, "alert history, searching check bundles")
+		}
+		for _, bundle := range *bundles {
+			items[bundle.CID] = true
+			timeline.CheckCIDs = append(timeline.CheckCIDs, bundle.Checks...)
+		}
+		items[q.Host] = true
+	}
+	checkIDs := make([]string, 0, len(timeline.CheckCIDs))
+	for _, checkCID := range timeline.CheckCIDs {
+		items[checkCID] = true
+		checkIDs = append(checkIDs, strings.TrimPrefix(checkCID, config.CheckPrefix+"/")+"_")
+	}
+
+	var entries []TimelineEntry
+	for _, checkCID := range timeline.CheckCIDs {
+		filter := SearchFilterType{"f__check": []string{checkCID}}
+		alerts, err := a.SearchAlerts(nil, &filter)
+		if err != nil {
+			return nil, errors.Wrapf(err, "alert history, searching alerts of %s", checkCID)
+		}
+		for i := range *alerts {
+			alert := &(*alerts)[i]
+			var cleared uint
+			if alert.ClearedOn != nil {
+				cleared = *alert.ClearedOn
+			}
+			if !overlaps(alert.OccurredOn, cleared) {
+				continue
+			}
+			if alert.RuleSetCID != "" {
+				items[alert.RuleSetCID] = true
+			}
+			if within(alert.OccurredOn) {
+				entries = append(entries, TimelineEntry{
+					Time:    time.Unix(int64(alert.OccurredOn), 0),
+					Type:    TimelineAlertRaised,
+					CID:     alert.CID,
+					Summary: fmt.Sprintf("sev %d alert on %s (%s)", alert.Severity, alert.MetricName, alert.Value),
+					Alert:   alert,
+				})
+			}
+
+			filter := SearchFilterType{"f_alert": []string{alert.CID}}
+			acks, err := a.SearchAcknowledgements(nil, &filter)
+			if err != nil {
+				return nil, errors.Wrapf(err, "alert history, searching acknowledgements of %s", alert.CID)
+			}
+			for j := range *acks {
+				ack := &(*acks)[j]
+				if !within(ack.AcknowledgedOn) {
+					continue
+				}
+				entries = append(entries, TimelineEntry{
+					Time:            time.Unix(int64(ack.AcknowledgedOn), 0),
+					Type:            TimelineAcknowledged,
+					CID:             ack.CID,
+					Summary:         fmt.Sprintf("%s acknowledged by %s: %s", alert.CID, ack.AcknowledgedBy, ack.Notes),
+					Acknowledgement: ack,
+				})
+			}
+
+			if alert.ClearedOn != nil && within(cleared) {
+				entries = append(entries, TimelineEntry{
+					Time:    time.Unix(int64(cleared), 0),
+					Type:    TimelineAlertCleared,
+					CID:     alert.CID,
+					Summary: fmt.Sprintf("sev %d alert on %s cleared", alert.Severity, alert.MetricName),
+					Alert:   alert,
+				})
+			}
+		}
+	}
+
+	annotations, err := a.FetchAnnotations()
+	if err != nil {
+		return nil, errors.Wrap(err, "alert history, fetching annotations")
+	}
+	for i := range *annotations {
+		annotation := &(*annotations)[i]
+		if !overlaps(annotation.Start, annotation.Stop) || !annotationRelated(annotation, checkIDs) {
+			continue
+		}
+		t := annotation.Start
+		if t < start {
+			t = start
+		}
+		entries = append(entries, TimelineEntry{
+			Time:       time.Unix(int64(t), 0),
+			Type:       TimelineAnnotation,
+			CID:        annotation.CID,
+			Summary:    fmt.Sprintf("%s: %s", annotation.Category, annotation.Title),
+			Annotation: annotation,
+		})
+	}
+
+	windows, err := a.FetchMaintenanceWindows()
+	if err != nil {
+		return nil, errors.Wrap(err, "alert history, fetching maintenance windows")
+	}
+	for i := range *windows {
+		window := &(*windows)[i]
+		if !overlaps(window.Start, window.Stop) || (window.Type != "account" && !items[window.Item]) {
+			continue
+		}
+		if within(window.Start) {
+			entries = append(entries, TimelineEntry{
+				Time:        time.Unix(int64(window.Start), 0),
+				Type:        TimelineMaintenanceStart,
+				CID:         window.CID,
+				Summary:     fmt.Sprintf("maintenance of %s %s started: %s", window.Type, window.Item, window.Notes),
+				Maintenance: window,
+			})
+		}
+		if window.Stop != 0 && within(window.Stop) {
+			entries = append(entries, TimelineEntry{
+				Time:        time.Unix(int64(window.Stop), 0),
+				Type:        TimelineMaintenanceStop,
+				CID:         window.CID,
+				Summary:     fmt.Sprintf("maintenance of %s %s ended", window.Type, window.Item),
+				Maintenance: window,
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	timeline.Entries = entries
+
+	return timeline, nil
+}
+
+// annotationRelated returns true if the annotation is related to a metric
+// of the checks (rel_metrics are <check id>_<metric name>), or to no metric
+func annotationRelated(annotation *Annotation, checkIDs []string) bool {
+	if len(annotation.RelatedMetrics) == 0 {
+		return true
+	}
+	for _, metric := range annotation.RelatedMetrics {
+		for _, id := range checkIDs {
+			if strings.HasPrefix(metric, id) {
+				return true
+			}
+		}
+	}
+	return false
+}
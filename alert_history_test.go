@@ -0,0 +1,106 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
+)
+
+func TestAlertHistory(t *testing.T) {
+	cleared := uint(1500)
+	fixtures := map[string]interface{}{
+		"/check_bundle/1": CheckBundle{CID: "/check_bundle/1", Target: "db1", Checks: []string{"/check/10"}},
+		"/check_bundle/2": CheckBundle{CID: "/check_bundle/2", Target: "web1", Checks: []string{"/check/20"}},
+		"/alert/1": Alert{CID: "/alert/1", CheckCID: "/check/10", RuleSetCID: "/rule_set/10_cpu",
+			MetricName: "cpu", Severity: 1, Value: "99", OccurredOn: 1000, ClearedOn: &cleared},
+		"/alert/2": Alert{CID: "/alert/2", CheckCID: "/check/10", MetricName: "disk", Severity: 2, OccurredOn: 500},
+		"/alert/3": Alert{CID: "/alert/3", CheckCID: "/check/20", MetricName: "cpu", Severity: 1, OccurredOn: 1100},
+		"/acknowledgement/1": Acknowledgement{CID: "/acknowledgement/1", AlertCID: "/alert/1",
+			AcknowledgedBy: "/user/1", AcknowledgedOn: 1200, Notes: "looking"},
+		"/annotation/1": Annotation{CID: "/annotation/1", Category: "deploy", Title: "db upgrade",
+			RelatedMetrics: []string{"10_cpu"}, Start: 900, Stop: 950},
+		"/annotation/2": Annotation{CID: "/annotation/2", Category: "deploy", Title: "release", Start: 1300, Stop: 1300},
+		"/annotation/3": Annotation{CID: "/annotation/3", Category: "deploy", Title: "web only",
+			RelatedMetrics: []string{"20_cpu"}, Start: 1300, Stop: 1300},
+		"/maintenance/1": Maintenance{CID: "/maintenance/1", Type: "rule_set", Item: "/rule_set/10_cpu", Start: 1250, Stop: 1400},
+		"/maintenance/2": Maintenance{CID: "/maintenance/2", Type: "host", Item: "web1", Start: 1250, Stop: 1400},
+		"/maintenance/3": Maintenance{CID: "/maintenance/3", Type: "account", Start: 100, Stop: 200},
+	}
+	fake, err := fakecirconus.New(&fakecirconus.Config{Fixtures: fixtures})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", URL: fake.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	tests := []struct {
+		id          string
+		query       *AlertHistoryQuery
+		expected    []string
+		expectedErr string
+	}{
+		{"host", &AlertHistoryQuery{Host: "db1", Start: time.Unix(800, 0), End: time.Unix(2000, 0)}, []string{
+			"900 annotation /annotation/1",
+			"1000 alert_raised /alert/1",
+			"1200 acknowledged /acknowledgement/1",
+			"1250 maintenance_start /maintenance/1",
+			"1300 annotation /annotation/2",
+			"1400 maintenance_stop /maintenance/1",
+			"1500 alert_cleared /alert/1",
+		}, ""},
+		{"check, part of range", &AlertHistoryQuery{CheckCID: "10", Start: time.Unix(400, 0), End: time.Unix(1100, 0)}, []string{
+			"500 alert_raised /alert/2",
+			"900 annotation /annotation/1",
+			"1000 alert_raised /alert/1",
+		}, ""},
+		{"web host", &AlertHistoryQuery{Host: "web1", Start: time.Unix(1000, 0), End: time.Unix(2000, 0)}, []string{
+			"1100 alert_raised /alert/3",
+			"1250 maintenance_start /maintenance/2",
+			"1300 annotation /annotation/2",
+			"1300 annotation /annotation/3",
+			"1400 maintenance_stop /maintenance/2",
+		}, ""},
+		{"account maintenance", &AlertHistoryQuery{CheckCID: "/check/30", Start: time.Unix(0, 0), End: time.Unix(300, 0)}, []string{
+			"100 maintenance_start /maintenance/3",
+			"200 maintenance_stop /maintenance/3",
+		}, ""},
+		{"no check or host", &AlertHistoryQuery{Start: time.Unix(0, 0)}, nil, "invalid alert history query, check CID or host required"},
+		{"no start", &AlertHistoryQuery{Host: "db1"}, nil, "invalid alert history query, start required"},
+		{"end before start", &AlertHistoryQuery{Host: "db1", Start: time.Unix(10, 0), End: time.Unix(5, 0)}, nil, "invalid alert history query, end before start"},
+		{"invalid check", &AlertHistoryQuery{CheckCID: "/check/", Start: time.Unix(0, 0)}, nil, "invalid check CID (/check/)"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			timeline, err := apih.AlertHistory(test.query)
+			if test.expectedErr != "" {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%v)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			entries := make([]string, 0, len(timeline.Entries))
+			for _, e := range timeline.Entries {
+				entries = append(entries, fmt.Sprintf("%d %s %s", e.Time.Unix(), e.Type, e.CID))
+			}
+			if strings.Join(entries, "\n") != strings.Join(test.expected, "\n") {
+				t.Fatalf("unexpected entries\n%s\nexpected\n%s", strings.Join(entries, "\n"), strings.Join(test.expected, "\n"))
+			}
+		})
+	}
+}
@@ -8,10 +8,8 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
-	"regexp"
 	"strings"
 
 	"github.com/circonus-labs/go-apiclient/config"
@@ -39,6 +37,12 @@ func NewAnnotation() *Annotation {
 
 // FetchAnnotation retrieves annotation with passed cid.
 func (a *API) FetchAnnotation(cid CIDType) (*Annotation, error) {
+	return a.FetchAnnotationWithContext(context.Background(), cid)
+}
+
+// FetchAnnotationWithContext retrieves annotation with passed cid, stopping
+// when ctx is done.
+func (a *API) FetchAnnotationWithContext(ctx context.Context, cid CIDType) (*Annotation, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.New("invalid annotation CID (none)")
 	}
@@ -50,25 +54,21 @@ func (a *API) FetchAnnotation(cid CIDType) (*Annotation, error) {
 		annotationCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.AnnotationCIDRegex, annotationCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.AnnotationPrefix, annotationCID) {
 		return nil, errors.Errorf("invalid annotation CID (%s)", annotationCID)
 	}
 
-	result, err := a.Get(annotationCID)
+	result, err := a.GetWithContext(ctx, annotationCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching annotation")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch annotation, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch annotation, received JSON: %s", string(result))
 	}
 
 	annotation := &Annotation{}
-	if err := json.Unmarshal(result, annotation); err != nil {
+	if err := a.unmarshalJSON(result, annotation); err != nil {
 		return nil, errors.Wrap(err, "parsing annotation")
 	}
 
@@ -77,14 +77,15 @@ func (a *API) FetchAnnotation(cid CIDType) (*Annotation, error) {
 
 // FetchAnnotations retrieves all annotations available to the API Token.
 func (a *API) FetchAnnotations() (*[]Annotation, error) {
-	result, err := a.Get(config.AnnotationPrefix)
-	if err != nil {
-		return nil, errors.Wrap(err, "fetching annotations")
-	}
+	return a.FetchAnnotationsWithContext(context.Background())
+}
 
+// FetchAnnotationsWithContext retrieves all annotations available to the API
+// Token, stopping when ctx is done.
+func (a *API) FetchAnnotationsWithContext(ctx context.Context) (*[]Annotation, error) {
 	var annotations []Annotation
-	if err := json.Unmarshal(result, &annotations); err != nil {
-		return nil, errors.Wrap(err, "parsing annotations")
+	if err := a.getJSONContext(ctx, config.AnnotationPrefix, &annotations); err != nil {
+		return nil, errors.Wrap(err, "fetching annotations")
 	}
 
 	return &annotations, nil
@@ -92,36 +93,38 @@ func (a *API) FetchAnnotations() (*[]Annotation, error) {
 
 // UpdateAnnotation updates passed annotation.
 func (a *API) UpdateAnnotation(cfg *Annotation) (*Annotation, error) {
+	return a.UpdateAnnotationWithContext(context.Background(), cfg)
+}
+
+// UpdateAnnotationWithContext updates passed annotation, stopping when ctx is
+// done.
+func (a *API) UpdateAnnotationWithContext(ctx context.Context, cfg *Annotation) (*Annotation, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid annotation config (nil)")
 	}
 
 	annotationCID := cfg.CID
 
-	matched, err := regexp.MatchString(config.AnnotationCIDRegex, annotationCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.AnnotationPrefix, annotationCID) {
 		return nil, errors.Errorf("invalid annotation CID (%s)", annotationCID)
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("update annotation, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("update annotation, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Put(annotationCID, jsonCfg)
+	result, err := a.PutWithContext(ctx, annotationCID, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "updating annotation")
 	}
 
 	annotation := &Annotation{}
-	if err := json.Unmarshal(result, annotation); err != nil {
+	if err := a.unmarshalJSON(result, annotation); err != nil {
 		return nil, errors.Wrap(err, "parsing annotation")
 	}
 
@@ -130,26 +133,32 @@ func (a *API) UpdateAnnotation(cfg *Annotation) (*Annotation, error) {
 
 // CreateAnnotation creates a new annotation.
 func (a *API) CreateAnnotation(cfg *Annotation) (*Annotation, error) {
+	return a.CreateAnnotationWithContext(context.Background(), cfg)
+}
+
+// CreateAnnotationWithContext creates a new annotation, stopping when ctx is
+// done.
+func (a *API) CreateAnnotationWithContext(ctx context.Context, cfg *Annotation) (*Annotation, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid annotation config (nil)")
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("create annotation, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("create annotation, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Post(config.AnnotationPrefix, jsonCfg)
+	result, err := a.PostWithContext(ctx, config.AnnotationPrefix, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "creating annotation")
 	}
 
 	annotation := &Annotation{}
-	if err := json.Unmarshal(result, annotation); err != nil {
+	if err := a.unmarshalJSON(result, annotation); err != nil {
 		return nil, errors.Wrap(err, "parsing annotation")
 	}
 
@@ -158,15 +167,27 @@ func (a *API) CreateAnnotation(cfg *Annotation) (*Annotation, error) {
 
 // DeleteAnnotation deletes passed annotation.
 func (a *API) DeleteAnnotation(cfg *Annotation) (bool, error) {
+	return a.DeleteAnnotationWithContext(context.Background(), cfg)
+}
+
+// DeleteAnnotationWithContext deletes passed annotation, stopping when ctx is
+// done.
+func (a *API) DeleteAnnotationWithContext(ctx context.Context, cfg *Annotation) (bool, error) {
 	if cfg == nil {
 		return false, errors.New("invalid annotation config (nil)")
 	}
 
-	return a.DeleteAnnotationByCID(CIDType(&cfg.CID))
+	return a.DeleteAnnotationByCIDWithContext(ctx, CIDType(&cfg.CID))
 }
 
 // DeleteAnnotationByCID deletes annotation with passed cid.
 func (a *API) DeleteAnnotationByCID(cid CIDType) (bool, error) {
+	return a.DeleteAnnotationByCIDWithContext(context.Background(), cid)
+}
+
+// DeleteAnnotationByCIDWithContext deletes annotation with passed cid,
+// stopping when ctx is done.
+func (a *API) DeleteAnnotationByCIDWithContext(ctx context.Context, cid CIDType) (bool, error) {
 	if cid == nil || *cid == "" {
 		return false, errors.New("invalid annotation CID (none)")
 	}
@@ -178,15 +199,11 @@ func (a *API) DeleteAnnotationByCID(cid CIDType) (bool, error) {
 		annotationCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.AnnotationCIDRegex, annotationCID)
-	if err != nil {
-		return false, err
-	}
-	if !matched {
+	if !validCID(config.AnnotationPrefix, annotationCID) {
 		return false, errors.Errorf("invalid annotation CID (%s)", annotationCID)
 	}
 
-	_, err = a.Delete(annotationCID)
+	_, err := a.DeleteWithContext(ctx, annotationCID)
 	if err != nil {
 		return false, errors.Wrap(err, "deleting annotation")
 	}
@@ -198,37 +215,19 @@ func (a *API) DeleteAnnotationByCID(cid CIDType) (bool, error) {
 // search query and/or filter. If nil is passed for both parameters
 // all annotations will be returned.
 func (a *API) SearchAnnotations(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Annotation, error) {
-	q := url.Values{}
-
-	if searchCriteria != nil && *searchCriteria != "" {
-		q.Set("search", string(*searchCriteria))
-	}
-
-	if filterCriteria != nil && len(*filterCriteria) > 0 {
-		for filter, criteria := range *filterCriteria {
-			for _, val := range criteria {
-				q.Add(filter, val)
-			}
-		}
-	}
-
-	if q.Encode() == "" {
-		return a.FetchAnnotations()
-	}
-
-	reqURL := url.URL{
-		Path:     config.AnnotationPrefix,
-		RawQuery: q.Encode(),
-	}
+	return a.SearchAnnotationsWithContext(context.Background(), searchCriteria, filterCriteria)
+}
 
-	result, err := a.Get(reqURL.String())
-	if err != nil {
-		return nil, errors.Wrap(err, "searching annotations")
+// SearchAnnotationsWithContext returns annotations matching the specified
+// search query and/or filter, stopping when ctx is done.
+func (a *API) SearchAnnotationsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Annotation, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchAnnotationsWithContext(ctx)
 	}
 
 	var annotations []Annotation
-	if err := json.Unmarshal(result, &annotations); err != nil {
-		return nil, errors.Wrap(err, "parsing annotations")
+	if err := a.searchJSONContext(ctx, config.AnnotationPrefix, searchCriteria, filterCriteria, &annotations); err != nil {
+		return nil, errors.Wrap(err, "searching annotations")
 	}
 
 	return &annotations, nil
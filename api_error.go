@@ -0,0 +1,115 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// API errors - the status and Circonus error of unsuccessful responses
+
+package apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Sentinel errors, matched (with errors.Is) by the APIError of responses
+// with the corresponding status, through any wrapping by the API methods:
+//
+//	if _, err := client.FetchGraph(cid); errors.Is(err, apiclient.ErrNotFound) {
+//		...
+//	}
+var (
+	// ErrNotFound is matched by 404 responses
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized is matched by 401 and 403 responses
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrRateLimited is matched by 429 responses, once retries are exhausted
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// APIError is the error of an unsuccessful response from the API, retrieve
+// it from the errors of the API methods with errors.As
+type APIError struct {
+	StatusCode int    // HTTP status of the response
+	Method     string // of the request
+	Path       string // of the request, e.g. /graph/1234
+	Body       string // of the response
+
+	// the Circonus error, from the response body (if it is one)
+	Code        string // e.g. Forbidden.BadToken
+	Message     string
+	Explanation string
+	Reference   string // quote it to Circonus support
+}
+
+// circonusError is the body of Circonus API error responses
+type circonusError struct {
+	Code        string `json:"code"`
+	Message     string `json:"message"`
+	Explanation string `json:"explanation"`
+	Reference   string `json:"reference"`
+}
+
+// newAPIError returns the error of an unsuccessful response, with its body
+func newAPIError(req *http.Request, resp *http.Response, body []byte) *APIError {
+	e := &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	if req != nil {
+		e.Method = req.Method
+		e.Path = req.URL.Path
+	}
+
+	var ce circonusError
+	if err := json.Unmarshal(body, &ce); err == nil {
+		e.Code = ce.Code
+		e.Message = ce.Message
+		e.Explanation = ce.Explanation
+		e.Reference = ce.Reference
+	}
+	if e.Reference == "" {
+		e.Reference = resp.Header.Get("X-Circonus-Reference")
+	}
+
+	return e
+}
+
+// Error returns the status and body of the response.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API response code %d: %s", e.StatusCode, e.Body)
+}
+
+// Is returns true if target is the sentinel error of the status of the
+// response, see ErrNotFound, ErrUnauthorized, and ErrRateLimited.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// retryError is the error of the last attempt of a call whose retries are
+// exhausted, with the message of the API's retry errors
+type retryError struct {
+	err *APIError
+}
+
+func (e *retryError) Error() string {
+	return fmt.Sprintf("- response: %d %s", e.err.StatusCode, e.err.Body)
+}
+
+// Unwrap returns the APIError of the response.
+func (e *retryError) Unwrap() error {
+	return e.err
+}
+
+// isStatus returns true if err is (or wraps) an APIError with the status
+func isStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
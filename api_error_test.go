@@ -0,0 +1,120 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/graph/1":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"code":"NotFound","message":"Object not found","explanation":"no graph 1","reference":"ref-1"}`)
+		case "/graph/2":
+			w.Header().Set("X-Circonus-Reference", "ref-2")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"code":"Forbidden.BadToken","message":"Bad token"}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `bad request`)
+		}
+	}))
+	defer server.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	tests := []struct {
+		id       string
+		cid      string
+		expected APIError
+		sentinel error
+	}{
+		{"not found", "/graph/1", APIError{
+			StatusCode:  http.StatusNotFound,
+			Method:      "GET",
+			Path:        "/graph/1",
+			Body:        `{"code":"NotFound","message":"Object not found","explanation":"no graph 1","reference":"ref-1"}`,
+			Code:        "NotFound",
+			Message:     "Object not found",
+			Explanation: "no graph 1",
+			Reference:   "ref-1",
+		}, ErrNotFound},
+		{"forbidden", "/graph/2", APIError{
+			StatusCode: http.StatusForbidden,
+			Method:     "GET",
+			Path:       "/graph/2",
+			Body:       `{"code":"Forbidden.BadToken","message":"Bad token"}`,
+			Code:       "Forbidden.BadToken",
+			Message:    "Bad token",
+			Reference:  "ref-2",
+		}, ErrUnauthorized},
+		{"not circonus", "/graph/3", APIError{
+			StatusCode: http.StatusBadRequest,
+			Method:     "GET",
+			Path:       "/graph/3",
+			Body:       "bad request",
+		}, nil},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			cid := test.cid
+			_, err := apih.FetchGraph(CIDType(&cid))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected APIError (%v)", err)
+			}
+			if !reflect.DeepEqual(*apiErr, test.expected) {
+				t.Fatalf("unexpected APIError (%+v)", *apiErr)
+			}
+
+			for _, sentinel := range []error{ErrNotFound, ErrUnauthorized, ErrRateLimited} {
+				if is := errors.Is(err, sentinel); is != (sentinel == test.sentinel) {
+					t.Fatalf("unexpected errors.Is %q (%t)", sentinel, is)
+				}
+			}
+		})
+	}
+}
+
+func TestAPIErrorIs(t *testing.T) {
+	tests := []struct {
+		status   int
+		expected error
+	}{
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusInternalServerError, nil},
+	}
+
+	for _, test := range tests {
+		e := &APIError{StatusCode: test.status}
+		for _, sentinel := range []error{ErrNotFound, ErrUnauthorized, ErrRateLimited} {
+			if is := errors.Is(e, sentinel); is != (sentinel == test.expected) {
+				t.Errorf("%d: unexpected errors.Is %q (%t)", test.status, sentinel, is)
+			}
+		}
+		if e.Error() != fmt.Sprintf("API response code %d: ", test.status) {
+			t.Errorf("unexpected message (%s)", e)
+		}
+	}
+}
@@ -0,0 +1,454 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Code generated by genapi. DO NOT EDIT.
+
+package apiclient
+
+import (
+	"context"
+	"time"
+)
+
+// RequestAPI is the API of the methods in main.go, implemented by *API
+type RequestAPI interface {
+	Get(reqPath string) ([]byte, error)
+	Delete(reqPath string) ([]byte, error)
+	Post(reqPath string, data []byte) ([]byte, error)
+	Put(reqPath string, data []byte) ([]byte, error)
+	GetWithContext(ctx context.Context, reqPath string) ([]byte, error)
+	DeleteWithContext(ctx context.Context, reqPath string) ([]byte, error)
+	PostWithContext(ctx context.Context, reqPath string, data []byte) ([]byte, error)
+	PutWithContext(ctx context.Context, reqPath string, data []byte) ([]byte, error)
+}
+
+// AccountAPI is the API of the methods in account.go, implemented by *API
+type AccountAPI interface {
+	FetchAccount(cid CIDType) (*Account, error)
+	FetchAccountWithContext(ctx context.Context, cid CIDType) (*Account, error)
+	FetchCurrentAccount() (*Account, error)
+	FetchCurrentAccountWithContext(ctx context.Context) (*Account, error)
+	FetchAccounts() (*[]Account, error)
+	FetchAccountsWithContext(ctx context.Context) (*[]Account, error)
+	UpdateAccount(cfg *Account) (*Account, error)
+	UpdateAccountWithContext(ctx context.Context, cfg *Account) (*Account, error)
+	SearchAccounts(filterCriteria *SearchFilterType) (*[]Account, error)
+	SearchAccountsWithContext(ctx context.Context, filterCriteria *SearchFilterType) (*[]Account, error)
+	InviteUser(email string, role AccountRole) (*Account, error)
+	ListPendingInvites() (*[]AccountInvite, error)
+	RevokeInvite(email string) (bool, error)
+	ForEachAccount(fn func(acct *Account, api *API) error) error
+	UpdateAccountDefaults(cid CIDType, defaults AccountDefaults) (*Account, error)
+	UpdateAccountDefaultsWithContext(ctx context.Context, cid CIDType, defaults AccountDefaults) (*Account, error)
+}
+
+// AcknowledgementAPI is the API of the methods in acknowledgement.go, implemented by *API
+type AcknowledgementAPI interface {
+	FetchAcknowledgement(cid CIDType) (*Acknowledgement, error)
+	FetchAcknowledgementWithContext(ctx context.Context, cid CIDType) (*Acknowledgement, error)
+	FetchAcknowledgements() (*[]Acknowledgement, error)
+	FetchAcknowledgementsWithContext(ctx context.Context) (*[]Acknowledgement, error)
+	UpdateAcknowledgement(cfg *Acknowledgement) (*Acknowledgement, error)
+	UpdateAcknowledgementWithContext(ctx context.Context, cfg *Acknowledgement) (*Acknowledgement, error)
+	CreateAcknowledgement(cfg *Acknowledgement) (*Acknowledgement, error)
+	CreateAcknowledgementWithContext(ctx context.Context, cfg *Acknowledgement) (*Acknowledgement, error)
+	SearchAcknowledgements(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Acknowledgement, error)
+	SearchAcknowledgementsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Acknowledgement, error)
+}
+
+// AlertAPI is the API of the methods in alert.go, implemented by *API
+type AlertAPI interface {
+	FetchAlert(cid CIDType) (*Alert, error)
+	FetchAlertWithContext(ctx context.Context, cid CIDType) (*Alert, error)
+	FetchAlerts() (*[]Alert, error)
+	FetchAlertsWithContext(ctx context.Context) (*[]Alert, error)
+	SearchAlerts(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Alert, error)
+	SearchAlertsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Alert, error)
+}
+
+// AnnotationAPI is the API of the methods in annotation.go, implemented by *API
+type AnnotationAPI interface {
+	FetchAnnotation(cid CIDType) (*Annotation, error)
+	FetchAnnotationWithContext(ctx context.Context, cid CIDType) (*Annotation, error)
+	FetchAnnotations() (*[]Annotation, error)
+	FetchAnnotationsWithContext(ctx context.Context) (*[]Annotation, error)
+	UpdateAnnotation(cfg *Annotation) (*Annotation, error)
+	UpdateAnnotationWithContext(ctx context.Context, cfg *Annotation) (*Annotation, error)
+	CreateAnnotation(cfg *Annotation) (*Annotation, error)
+	CreateAnnotationWithContext(ctx context.Context, cfg *Annotation) (*Annotation, error)
+	DeleteAnnotation(cfg *Annotation) (bool, error)
+	DeleteAnnotationWithContext(ctx context.Context, cfg *Annotation) (bool, error)
+	DeleteAnnotationByCID(cid CIDType) (bool, error)
+	DeleteAnnotationByCIDWithContext(ctx context.Context, cid CIDType) (bool, error)
+	SearchAnnotations(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Annotation, error)
+	SearchAnnotationsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Annotation, error)
+}
+
+// BrokerAPI is the API of the methods in broker.go, implemented by *API
+type BrokerAPI interface {
+	FetchBroker(cid CIDType) (*Broker, error)
+	FetchBrokerWithContext(ctx context.Context, cid CIDType) (*Broker, error)
+	FetchBrokers() (*[]Broker, error)
+	FetchBrokersWithContext(ctx context.Context) (*[]Broker, error)
+	SearchBrokers(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Broker, error)
+	SearchBrokersWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Broker, error)
+}
+
+// CheckAPI is the API of the methods in check.go, implemented by *API
+type CheckAPI interface {
+	FetchCheck(cid CIDType) (*Check, error)
+	FetchCheckWithContext(ctx context.Context, cid CIDType) (*Check, error)
+	FetchChecks() (*[]Check, error)
+	FetchChecksWithContext(ctx context.Context) (*[]Check, error)
+	SearchChecks(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Check, error)
+	SearchChecksWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Check, error)
+}
+
+// CheckBundleAPI is the API of the methods in check_bundle.go, implemented by *API
+type CheckBundleAPI interface {
+	FetchCheckBundle(cid CIDType) (*CheckBundle, error)
+	FetchCheckBundleWithContext(ctx context.Context, cid CIDType) (*CheckBundle, error)
+	FetchCheckBundles() (*[]CheckBundle, error)
+	FetchCheckBundlesWithContext(ctx context.Context) (*[]CheckBundle, error)
+	UpdateCheckBundle(cfg *CheckBundle) (*CheckBundle, error)
+	UpdateCheckBundleWithContext(ctx context.Context, cfg *CheckBundle) (*CheckBundle, error)
+	CreateCheckBundle(cfg *CheckBundle) (*CheckBundle, error)
+	CreateCheckBundleWithContext(ctx context.Context, cfg *CheckBundle) (*CheckBundle, error)
+	DeleteCheckBundle(cfg *CheckBundle) (bool, error)
+	DeleteCheckBundleWithContext(ctx context.Context, cfg *CheckBundle) (bool, error)
+	DeleteCheckBundleByCID(cid CIDType) (bool, error)
+	DeleteCheckBundleByCIDWithContext(ctx context.Context, cid CIDType) (bool, error)
+	SearchCheckBundles(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]CheckBundle, error)
+	SearchCheckBundlesWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]CheckBundle, error)
+	UpsertCheckBundle(target *CheckBundle) (*CheckBundle, []AuditChange, error)
+	UpsertCheckBundleWithContext(ctx context.Context, target *CheckBundle) (*CheckBundle, []AuditChange, error)
+}
+
+// CheckBundleMetricsAPI is the API of the methods in check_bundle_metrics.go, implemented by *API
+type CheckBundleMetricsAPI interface {
+	FetchCheckBundleMetrics(cid CIDType) (*CheckBundleMetrics, error)
+	FetchCheckBundleMetricsWithContext(ctx context.Context, cid CIDType) (*CheckBundleMetrics, error)
+	UpdateCheckBundleMetrics(cfg *CheckBundleMetrics) (*CheckBundleMetrics, error)
+	UpdateCheckBundleMetricsWithContext(ctx context.Context, cfg *CheckBundleMetrics) (*CheckBundleMetrics, error)
+}
+
+// CheckMoveAPI is the API of the methods in check_move.go, implemented by *API
+type CheckMoveAPI interface {
+	FetchCheckMove(cid CIDType) (*CheckMove, error)
+	FetchCheckMoveWithContext(ctx context.Context, cid CIDType) (*CheckMove, error)
+	FetchCheckMoves() (*[]CheckMove, error)
+	FetchCheckMovesWithContext(ctx context.Context) (*[]CheckMove, error)
+	CreateCheckMove(cfg *CheckMove) (*CheckMove, error)
+	CreateCheckMoveWithContext(ctx context.Context, cfg *CheckMove) (*CheckMove, error)
+	DeleteCheckMove(cfg *CheckMove) (bool, error)
+	DeleteCheckMoveWithContext(ctx context.Context, cfg *CheckMove) (bool, error)
+	DeleteCheckMoveByCID(cid CIDType) (bool, error)
+	DeleteCheckMoveByCIDWithContext(ctx context.Context, cid CIDType) (bool, error)
+	SearchCheckMoves(filterCriteria *SearchFilterType) (*[]CheckMove, error)
+	SearchCheckMovesWithContext(ctx context.Context, filterCriteria *SearchFilterType) (*[]CheckMove, error)
+	MoveCheck(checkCID, brokerCID CIDType) (*CheckMove, error)
+	MoveCheckAndWait(checkCID, brokerCID CIDType, timeout time.Duration) (*CheckMove, error)
+}
+
+// CheckTemplateAPI is the API of the methods in check_template.go, implemented by *API
+type CheckTemplateAPI interface {
+	FetchCheckTemplate(cid CIDType) (*CheckTemplate, error)
+	FetchCheckTemplateWithContext(ctx context.Context, cid CIDType) (*CheckTemplate, error)
+	FetchCheckTemplates() (*[]CheckTemplate, error)
+	FetchCheckTemplatesWithContext(ctx context.Context) (*[]CheckTemplate, error)
+	UpdateCheckTemplate(cfg *CheckTemplate) (*CheckTemplate, error)
+	UpdateCheckTemplateWithContext(ctx context.Context, cfg *CheckTemplate) (*CheckTemplate, error)
+	CreateCheckTemplate(cfg *CheckTemplate) (*CheckTemplate, error)
+	CreateCheckTemplateWithContext(ctx context.Context, cfg *CheckTemplate) (*CheckTemplate, error)
+	DeleteCheckTemplate(cfg *CheckTemplate) (bool, error)
+	DeleteCheckTemplateWithContext(ctx context.Context, cfg *CheckTemplate) (bool, error)
+	DeleteCheckTemplateByCID(cid CIDType) (bool, error)
+	DeleteCheckTemplateByCIDWithContext(ctx context.Context, cid CIDType) (bool, error)
+	SearchCheckTemplates(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]CheckTemplate, error)
+	SearchCheckTemplatesWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]CheckTemplate, error)
+}
+
+// ContactGroupAPI is the API of the methods in contact_group.go, implemented by *API
+type ContactGroupAPI interface {
+	FetchContactGroup(cid CIDType) (*ContactGroup, error)
+	FetchContactGroupWithContext(ctx context.Context, cid CIDType) (*ContactGroup, error)
+	FetchContactGroups() (*[]ContactGroup, error)
+	FetchContactGroupsWithContext(ctx context.Context) (*[]ContactGroup, error)
+	UpdateContactGroup(cfg *ContactGroup) (*ContactGroup, error)
+	UpdateContactGroupWithContext(ctx context.Context, cfg *ContactGroup) (*ContactGroup, error)
+	CreateContactGroup(cfg *ContactGroup) (*ContactGroup, error)
+	CreateContactGroupWithContext(ctx context.Context, cfg *ContactGroup) (*ContactGroup, error)
+	DeleteContactGroup(cfg *ContactGroup) (bool, error)
+	DeleteContactGroupWithContext(ctx context.Context, cfg *ContactGroup) (bool, error)
+	DeleteContactGroupByCID(cid CIDType) (bool, error)
+	DeleteContactGroupByCIDWithContext(ctx context.Context, cid CIDType) (bool, error)
+	SearchContactGroups(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]ContactGroup, error)
+	SearchContactGroupsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]ContactGroup, error)
+}
+
+// DashboardAPI is the API of the methods in dashboard.go, implemented by *API
+type DashboardAPI interface {
+	FetchDashboard(cid CIDType) (*Dashboard, error)
+	FetchDashboardWithContext(ctx context.Context, cid CIDType) (*Dashboard, error)
+	FetchDashboards() (*[]Dashboard, error)
+	FetchDashboardsWithContext(ctx context.Context) (*[]Dashboard, error)
+	UpdateDashboard(cfg *Dashboard) (*Dashboard, error)
+	UpdateDashboardWithContext(ctx context.Context, cfg *Dashboard) (*Dashboard, error)
+	CreateDashboard(cfg *Dashboard) (*Dashboard, error)
+	CreateDashboardWithContext(ctx context.Context, cfg *Dashboard) (*Dashboard, error)
+	DeleteDashboard(cfg *Dashboard) (bool, error)
+	DeleteDashboardWithContext(ctx context.Context, cfg *Dashboard) (bool, error)
+	DeleteDashboardByCID(cid CIDType) (bool, error)
+	DeleteDashboardByCIDWithContext(ctx context.Context, cid CIDType) (bool, error)
+	SearchDashboards(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Dashboard, error)
+	SearchDashboardsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Dashboard, error)
+}
+
+// DataAPI is the API of the methods in data.go, implemented by *API
+type DataAPI interface {
+	FetchData(checkCID CIDType, metricName string, start, end time.Time, period time.Duration) (*Data, error)
+	FetchDataWithContext(ctx context.Context, checkCID CIDType, metricName string, start, end time.Time, period time.Duration) (*Data, error)
+	FetchDataWithOptions(checkCID CIDType, metricName string, opts *DataOptions) (*Data, error)
+	FetchDataWithOptionsWithContext(ctx context.Context, checkCID CIDType, metricName string, opts *DataOptions) (*Data, error)
+	FetchHistogramData(checkCID CIDType, metricName string, start, end time.Time, period time.Duration) (*HistogramData, error)
+	FetchHistogramDataWithContext(ctx context.Context, checkCID CIDType, metricName string, start, end time.Time, period time.Duration) (*HistogramData, error)
+	FetchHistogramDataWithOptions(checkCID CIDType, metricName string, opts *DataOptions) (*HistogramData, error)
+	FetchHistogramDataWithOptionsWithContext(ctx context.Context, checkCID CIDType, metricName string, opts *DataOptions) (*HistogramData, error)
+}
+
+// GraphAPI is the API of the methods in graph.go, implemented by *API
+type GraphAPI interface {
+	FetchGraph(cid CIDType) (*Graph, error)
+	FetchGraphWithContext(ctx context.Context, cid CIDType) (*Graph, error)
+	FetchGraphs() (*[]Graph, error)
+	FetchGraphsWithContext(ctx context.Context) (*[]Graph, error)
+	UpdateGraph(cfg *Graph) (*Graph, error)
+	UpdateGraphWithContext(ctx context.Context, cfg *Graph) (*Graph, error)
+	CreateGraph(cfg *Graph) (*Graph, error)
+	CreateGraphWithContext(ctx context.Context, cfg *Graph) (*Graph, error)
+	DeleteGraph(cfg *Graph) (bool, error)
+	DeleteGraphWithContext(ctx context.Context, cfg *Graph) (bool, error)
+	DeleteGraphByCID(cid CIDType) (bool, error)
+	DeleteGraphByCIDWithContext(ctx context.Context, cid CIDType) (bool, error)
+	SearchGraphs(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Graph, error)
+	SearchGraphsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Graph, error)
+}
+
+// MaintenanceAPI is the API of the methods in maintenance.go, implemented by *API
+type MaintenanceAPI interface {
+	FetchMaintenanceWindow(cid CIDType) (*Maintenance, error)
+	FetchMaintenanceWindowWithContext(ctx context.Context, cid CIDType) (*Maintenance, error)
+	FetchMaintenanceWindows() (*[]Maintenance, error)
+	FetchMaintenanceWindowsWithContext(ctx context.Context) (*[]Maintenance, error)
+	UpdateMaintenanceWindow(cfg *Maintenance) (*Maintenance, error)
+	UpdateMaintenanceWindowWithContext(ctx context.Context, cfg *Maintenance) (*Maintenance, error)
+	CreateMaintenanceWindow(cfg *Maintenance) (*Maintenance, error)
+	CreateMaintenanceWindowWithContext(ctx context.Context, cfg *Maintenance) (*Maintenance, error)
+	DeleteMaintenanceWindow(cfg *Maintenance) (bool, error)
+	DeleteMaintenanceWindowWithContext(ctx context.Context, cfg *Maintenance) (bool, error)
+	DeleteMaintenanceWindowByCID(cid CIDType) (bool, error)
+	DeleteMaintenanceWindowByCIDWithContext(ctx context.Context, cid CIDType) (bool, error)
+	SearchMaintenanceWindows(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Maintenance, error)
+	SearchMaintenanceWindowsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Maintenance, error)
+}
+
+// MetricAPI is the API of the methods in metric.go, implemented by *API
+type MetricAPI interface {
+	FetchMetric(cid CIDType) (*Metric, error)
+	FetchMetricWithContext(ctx context.Context, cid CIDType) (*Metric, error)
+	FetchMetrics() (*[]Metric, error)
+	FetchMetricsWithContext(ctx context.Context) (*[]Metric, error)
+	UpdateMetric(cfg *Metric) (*Metric, error)
+	UpdateMetricWithContext(ctx context.Context, cfg *Metric) (*Metric, error)
+	SearchMetrics(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Metric, error)
+	SearchMetricsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Metric, error)
+}
+
+// MetricClusterAPI is the API of the methods in metric_cluster.go, implemented by *API
+type MetricClusterAPI interface {
+	FetchMetricCluster(cid CIDType, extras string) (*MetricCluster, error)
+	FetchMetricClusterWithContext(ctx context.Context, cid CIDType, extras string) (*MetricCluster, error)
+	FetchMetricClusters(extras string) (*[]MetricCluster, error)
+	FetchMetricClustersWithContext(ctx context.Context, extras string) (*[]MetricCluster, error)
+	UpdateMetricCluster(cfg *MetricCluster) (*MetricCluster, error)
+	UpdateMetricClusterWithContext(ctx context.Context, cfg *MetricCluster) (*MetricCluster, error)
+	CreateMetricCluster(cfg *MetricCluster) (*MetricCluster, error)
+	CreateMetricClusterWithContext(ctx context.Context, cfg *MetricCluster) (*MetricCluster, error)
+	DeleteMetricCluster(cfg *MetricCluster) (bool, error)
+	DeleteMetricClusterWithContext(ctx context.Context, cfg *MetricCluster) (bool, error)
+	DeleteMetricClusterByCID(cid CIDType) (bool, error)
+	DeleteMetricClusterByCIDWithContext(ctx context.Context, cid CIDType) (bool, error)
+	SearchMetricClusters(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]MetricCluster, error)
+	SearchMetricClustersWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]MetricCluster, error)
+}
+
+// OutlierReportAPI is the API of the methods in outlier_report.go, implemented by *API
+type OutlierReportAPI interface {
+	FetchOutlierReport(cid CIDType) (*OutlierReport, error)
+	FetchOutlierReportWithContext(ctx context.Context, cid CIDType) (*OutlierReport, error)
+	FetchOutlierReports() (*[]OutlierReport, error)
+	FetchOutlierReportsWithContext(ctx context.Context) (*[]OutlierReport, error)
+	UpdateOutlierReport(cfg *OutlierReport) (*OutlierReport, error)
+	UpdateOutlierReportWithContext(ctx context.Context, cfg *OutlierReport) (*OutlierReport, error)
+	CreateOutlierReport(cfg *OutlierReport) (*OutlierReport, error)
+	CreateOutlierReportWithContext(ctx context.Context, cfg *OutlierReport) (*OutlierReport, error)
+	DeleteOutlierReport(cfg *OutlierReport) (bool, error)
+	DeleteOutlierReportWithContext(ctx context.Context, cfg *OutlierReport) (bool, error)
+	DeleteOutlierReportByCID(cid CIDType) (bool, error)
+	DeleteOutlierReportByCIDWithContext(ctx context.Context, cid CIDType) (bool, error)
+	SearchOutlierReports(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]OutlierReport, error)
+	SearchOutlierReportsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]OutlierReport, error)
+	SearchOutlierReportsByWindow(filter *OutlierReportWindowFilter) (*[]OutlierReport, error)
+	SearchOutlierReportsByWindowWithContext(ctx context.Context, filter *OutlierReportWindowFilter) (*[]OutlierReport, error)
+	PurgeOutlierReports(retention *OutlierReportRetention) (*[]OutlierReport, error)
+}
+
+// ProvisionBrokerAPI is the API of the methods in provision_broker.go, implemented by *API
+type ProvisionBrokerAPI interface {
+	FetchProvisionBroker(cid CIDType) (*ProvisionBroker, error)
+	FetchProvisionBrokerWithContext(ctx context.Context, cid CIDType) (*ProvisionBroker, error)
+	UpdateProvisionBroker(cid CIDType, cfg *ProvisionBroker) (*ProvisionBroker, error)
+	UpdateProvisionBrokerWithContext(ctx context.Context, cid CIDType, cfg *ProvisionBroker) (*ProvisionBroker, error)
+	CreateProvisionBroker(cfg *ProvisionBroker) (*ProvisionBroker, error)
+	CreateProvisionBrokerWithContext(ctx context.Context, cfg *ProvisionBroker) (*ProvisionBroker, error)
+	RotateProvisionBrokerCert(cid CIDType, csr string) (*ProvisionBrokerCert, error)
+	ProvisionBrokerAndWait(cfg *ProvisionBroker, timeout time.Duration) (*Broker, error)
+}
+
+// RuleSetAPI is the API of the methods in rule_set.go, implemented by *API
+type RuleSetAPI interface {
+	FetchRuleSet(cid CIDType) (*RuleSet, error)
+	FetchRuleSetWithContext(ctx context.Context, cid CIDType) (*RuleSet, error)
+	FetchRuleSets() (*[]RuleSet, error)
+	FetchRuleSetsWithContext(ctx context.Context) (*[]RuleSet, error)
+	UpdateRuleSet(cfg *RuleSet) (*RuleSet, error)
+	UpdateRuleSetWithContext(ctx context.Context, cfg *RuleSet) (*RuleSet, error)
+	CreateRuleSet(cfg *RuleSet) (*RuleSet, error)
+	CreateRuleSetWithContext(ctx context.Context, cfg *RuleSet) (*RuleSet, error)
+	DeleteRuleSet(cfg *RuleSet) (bool, error)
+	DeleteRuleSetWithContext(ctx context.Context, cfg *RuleSet) (bool, error)
+	DeleteRuleSetByCID(cid CIDType) (bool, error)
+	DeleteRuleSetByCIDWithContext(ctx context.Context, cid CIDType) (bool, error)
+	SearchRuleSets(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]RuleSet, error)
+	SearchRuleSetsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]RuleSet, error)
+	ValidateRuleSet(cfg *RuleSet) error
+	CloneRuleSet(cid CIDType, newCheckCID, newMetricName string) (*RuleSet, error)
+	RuleSetsNotifying(contactGroupCID CIDType) (*[]RuleSet, error)
+	RuleSetsWithSeverity(severity uint) (*[]RuleSet, error)
+	MuteRuleSet(cid CIDType) (*RuleSetMute, error)
+	UnmuteRuleSet(mute *RuleSetMute) (*RuleSet, error)
+}
+
+// RuleSetGroupAPI is the API of the methods in rule_set_group.go, implemented by *API
+type RuleSetGroupAPI interface {
+	FetchRuleSetGroup(cid CIDType) (*RuleSetGroup, error)
+	FetchRuleSetGroupWithContext(ctx context.Context, cid CIDType) (*RuleSetGroup, error)
+	FetchRuleSetGroups() (*[]RuleSetGroup, error)
+	FetchRuleSetGroupsWithContext(ctx context.Context) (*[]RuleSetGroup, error)
+	UpdateRuleSetGroup(cfg *RuleSetGroup) (*RuleSetGroup, error)
+	UpdateRuleSetGroupWithContext(ctx context.Context, cfg *RuleSetGroup) (*RuleSetGroup, error)
+	CreateRuleSetGroup(cfg *RuleSetGroup) (*RuleSetGroup, error)
+	CreateRuleSetGroupWithContext(ctx context.Context, cfg *RuleSetGroup) (*RuleSetGroup, error)
+	DeleteRuleSetGroup(cfg *RuleSetGroup) (bool, error)
+	DeleteRuleSetGroupWithContext(ctx context.Context, cfg *RuleSetGroup) (bool, error)
+	DeleteRuleSetGroupByCID(cid CIDType) (bool, error)
+	DeleteRuleSetGroupByCIDWithContext(ctx context.Context, cid CIDType) (bool, error)
+	SearchRuleSetGroups(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]RuleSetGroup, error)
+	SearchRuleSetGroupsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]RuleSetGroup, error)
+	ValidateRuleSetGroup(cfg *RuleSetGroup) error
+	CloneRuleSetGroup(cid CIDType, memberMap map[string]string) (*RuleSetGroup, error)
+}
+
+// TagAPI is the API of the methods in tag.go, implemented by *API
+type TagAPI interface {
+	FetchTag(cid CIDType) (*Tag, error)
+	FetchTagWithContext(ctx context.Context, cid CIDType) (*Tag, error)
+	FetchTags() (*[]Tag, error)
+	FetchTagsWithContext(ctx context.Context) (*[]Tag, error)
+	SearchTags(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Tag, error)
+	SearchTagsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Tag, error)
+	FetchTagCategories() (map[string][]string, error)
+	FetchTagCategoriesWithContext(ctx context.Context) (map[string][]string, error)
+}
+
+// UserAPI is the API of the methods in user.go, implemented by *API
+type UserAPI interface {
+	FetchUser(cid CIDType) (*User, error)
+	FetchUserWithContext(ctx context.Context, cid CIDType) (*User, error)
+	FetchUsers() (*[]User, error)
+	FetchUsersWithContext(ctx context.Context) (*[]User, error)
+	UpdateUser(cfg *User) (*User, error)
+	UpdateUserWithContext(ctx context.Context, cfg *User) (*User, error)
+	SearchUsers(filterCriteria *SearchFilterType) (*[]User, error)
+	SearchUsersWithContext(ctx context.Context, filterCriteria *SearchFilterType) (*[]User, error)
+	FetchCurrentUser() (*User, error)
+	FetchCurrentUserWithContext(ctx context.Context) (*User, error)
+	WhoAmI() (*WhoAmI, error)
+	SetUserContactInfo(cid CIDType, method, value string) (*User, error)
+	SearchUsersByEmail(addr string) (*[]User, error)
+	SearchUsersByEmailWithContext(ctx context.Context, addr string) (*[]User, error)
+	FetchUsersInAccount(role string) (*[]User, error)
+	FetchUsersInAccountWithContext(ctx context.Context, role string) (*[]User, error)
+	FetchUserRoles(cid CIDType) (*User, error)
+	FetchUserRolesWithContext(ctx context.Context, cid CIDType) (*User, error)
+}
+
+// WorksheetAPI is the API of the methods in worksheet.go, implemented by *API
+type WorksheetAPI interface {
+	FetchWorksheet(cid CIDType) (*Worksheet, error)
+	FetchWorksheetWithContext(ctx context.Context, cid CIDType) (*Worksheet, error)
+	FetchWorksheets() (*[]Worksheet, error)
+	FetchWorksheetsWithContext(ctx context.Context) (*[]Worksheet, error)
+	UpdateWorksheet(cfg *Worksheet) (*Worksheet, error)
+	UpdateWorksheetWithContext(ctx context.Context, cfg *Worksheet) (*Worksheet, error)
+	CreateWorksheet(cfg *Worksheet) (*Worksheet, error)
+	CreateWorksheetWithContext(ctx context.Context, cfg *Worksheet) (*Worksheet, error)
+	DeleteWorksheet(cfg *Worksheet) (bool, error)
+	DeleteWorksheetWithContext(ctx context.Context, cfg *Worksheet) (bool, error)
+	DeleteWorksheetByCID(cid CIDType) (bool, error)
+	DeleteWorksheetByCIDWithContext(ctx context.Context, cid CIDType) (bool, error)
+	SearchWorksheets(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Worksheet, error)
+	SearchWorksheetsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Worksheet, error)
+	FetchWorksheetSmartQueryGraphs(sq *WorksheetSmartQuery) (*[]Graph, error)
+	FetchWorksheetSmartQueryGraphsWithContext(ctx context.Context, sq *WorksheetSmartQuery) (*[]Graph, error)
+	AddGraphToWorksheet(worksheetCID CIDType, graphCID CIDType) (*Worksheet, error)
+	RemoveGraphFromWorksheet(worksheetCID CIDType, graphCID CIDType) (*Worksheet, error)
+	CloneWorksheet(cid CIDType, graphMap map[string]string) (*Worksheet, error)
+	SetWorksheetFavorite(cid CIDType, favorite bool) (*Worksheet, error)
+	SetWorksheetDescription(cid CIDType, description string) (*Worksheet, error)
+	FetchFavoriteWorksheets() (*[]Worksheet, error)
+	FetchFavoriteWorksheetsWithContext(ctx context.Context) (*[]Worksheet, error)
+}
+
+// CirconusAPI is the API of every resource, implemented by *API and, to
+// test code using the API, by apitest.Mock
+type CirconusAPI interface {
+	RequestAPI
+	AccountAPI
+	AcknowledgementAPI
+	AlertAPI
+	AnnotationAPI
+	BrokerAPI
+	CheckAPI
+	CheckBundleAPI
+	CheckBundleMetricsAPI
+	CheckMoveAPI
+	CheckTemplateAPI
+	ContactGroupAPI
+	DashboardAPI
+	DataAPI
+	GraphAPI
+	MaintenanceAPI
+	MetricAPI
+	MetricClusterAPI
+	OutlierReportAPI
+	ProvisionBrokerAPI
+	RuleSetAPI
+	RuleSetGroupAPI
+	TagAPI
+	UserAPI
+	WorksheetAPI
+}
+
+var _ CirconusAPI = (*API)(nil)
@@ -0,0 +1,167 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package apiotel instruments the calls of an apiclient API with
+// OpenTelemetry: a span per call (e.g. "GET /graph", with the cid, status,
+// and retries), and metrics of the duration of calls and the responses
+// refusing them for the API's rate limit.
+//
+//	cfg := &apiclient.Config{TokenKey: key}
+//	if err := apiotel.Instrument(cfg, &apiotel.Config{TracerProvider: tp, MeterProvider: mp}); err != nil {
+//		...
+//	}
+//	apih, _ := apiclient.New(cfg)
+//
+// Spans are children of the span of the context of the call, see the
+// WithContext variants of the API methods. It is a module of its own, so
+// the API package does not depend on OpenTelemetry.
+package apiotel
+
+import (
+	"context"
+	"strings"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer and meter of the calls
+const instrumentationName = "github.com/circonus-labs/go-apiclient/apiotel"
+
+// Span and metric attributes
+const (
+	ResourceKey    = attribute.Key("circonus.resource")     // e.g. graph
+	CIDKey         = attribute.Key("circonus.cid")          // of the object of the call, if any
+	RetriesKey     = attribute.Key("circonus.retries")      // requests made after the first
+	RateLimitedKey = attribute.Key("circonus.rate_limited") // responses refusing the call for the rate limit
+	MethodKey      = attribute.Key("http.request.method")
+	StatusKey      = attribute.Key("http.response.status_code")
+)
+
+// Config defines the providers of the spans and metrics
+type Config struct {
+	// TracerProvider provides the tracer of the spans - default the global
+	// provider (see otel.GetTracerProvider)
+	TracerProvider trace.TracerProvider
+
+	// MeterProvider provides the meter of the metrics - default the global
+	// provider (see otel.GetMeterProvider)
+	MeterProvider metric.MeterProvider
+}
+
+// instruments are the tracer and metrics of the calls
+type instruments struct {
+	tracer      trace.Tracer
+	duration    metric.Float64Histogram
+	rateLimited metric.Int64Counter
+}
+
+// Instrument sets the OnCall hook of the API config, so the calls of APIs
+// created with it are traced and measured. An OnCall hook already set is
+// still called, within the span of the call.
+func Instrument(cfg *apiclient.Config, oc *Config) error {
+	if cfg == nil {
+		return errors.New("invalid API config (nil)")
+	}
+	var c Config
+	if oc != nil {
+		c = *oc
+	}
+	if c.TracerProvider == nil {
+		c.TracerProvider = otel.GetTracerProvider()
+	}
+	if c.MeterProvider == nil {
+		c.MeterProvider = otel.GetMeterProvider()
+	}
+
+	meter := c.MeterProvider.Meter(instrumentationName)
+	in := &instruments{tracer: c.TracerProvider.Tracer(instrumentationName)}
+	var err error
+	in.duration, err = meter.Float64Histogram("circonus.api.call.duration",
+		metric.WithDescription("Duration of Circonus API calls, retries included"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return errors.Wrap(err, "creating call duration histogram")
+	}
+	in.rateLimited, err = meter.Int64Counter("circonus.api.rate_limited",
+		metric.WithDescription("Circonus API responses refusing a call for the rate limit (429)"),
+		metric.WithUnit("{response}"))
+	if err != nil {
+		return errors.Wrap(err, "creating rate limited counter")
+	}
+
+	cfg.OnCall = in.onCall(cfg.OnCall)
+	return nil
+}
+
+// onCall returns the OnCall hook starting and ending the span of each call,
+// calling next within it
+func (in *instruments) onCall(next func(ctx context.Context, method, path string) (context.Context, func(apiclient.CallInfo))) func(ctx context.Context, method, path string) (context.Context, func(apiclient.CallInfo)) {
+	return func(ctx context.Context, method, path string) (context.Context, func(apiclient.CallInfo)) {
+		resource, cid := splitPath(path)
+		attrs := []attribute.KeyValue{MethodKey.String(method), ResourceKey.String(resource)}
+		spanAttrs := attrs
+		if cid != "" {
+			spanAttrs = append([]attribute.KeyValue{CIDKey.String(cid)}, attrs...)
+		}
+		ctx, span := in.tracer.Start(ctx, method+" /"+resource,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(spanAttrs...))
+
+		var nextDone func(apiclient.CallInfo)
+		if next != nil {
+			ctx, nextDone = next(ctx, method, path)
+		}
+
+		return ctx, func(info apiclient.CallInfo) {
+			if nextDone != nil {
+				nextDone(info)
+			}
+
+			retries := info.Attempts - 1
+			if retries < 0 {
+				retries = 0
+			}
+			span.SetAttributes(RetriesKey.Int(retries), RateLimitedKey.Int(info.RateLimited))
+			durationAttrs := attrs
+			if info.Status > 0 {
+				status := StatusKey.Int(info.Status)
+				span.SetAttributes(status)
+				durationAttrs = append(append([]attribute.KeyValue{}, attrs...), status)
+			}
+			if info.Err != nil {
+				span.RecordError(info.Err)
+				span.SetStatus(codes.Error, info.Err.Error())
+			}
+			span.End()
+
+			// the context of the call may be done, the metrics are not
+			// cancelled with it
+			mctx := context.WithoutCancel(ctx)
+			in.duration.Record(mctx, info.Elapsed.Seconds(), metric.WithAttributes(durationAttrs...))
+			if info.RateLimited > 0 {
+				in.rateLimited.Add(mctx, int64(info.RateLimited), metric.WithAttributes(attrs...))
+			}
+		}
+	}
+}
+
+// splitPath returns the resource of a call's path, and the cid of its
+// object (e.g. graph and /graph/1234 for /graph/1234?x=y), "" if it is
+// not a call of an object
+func splitPath(path string) (string, string) {
+	if idx := strings.IndexAny(path, "?#"); idx >= 0 {
+		path = path[:idx]
+	}
+	parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)
+	if len(parts) < 2 || parts[1] == "" {
+		return parts[0], ""
+	}
+	return parts[0], path
+}
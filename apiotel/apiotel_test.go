@@ -0,0 +1,200 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiotel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInstrument(t *testing.T) {
+	var limited int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/graph/1":
+			if atomic.AddInt32(&limited, 1) == 1 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			fmt.Fprint(w, `{"_cid":"/graph/1"}`)
+		case "/graph":
+			fmt.Fprint(w, `[]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	var chained int32
+	cfg := &apiclient.Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      server.URL,
+		OnCall: func(ctx context.Context, method, path string) (context.Context, func(apiclient.CallInfo)) {
+			if !trace.SpanContextFromContext(ctx).IsValid() {
+				t.Errorf("expected the context of the call's span (%s %s)", method, path)
+			}
+			atomic.AddInt32(&chained, 1)
+			return ctx, nil
+		},
+	}
+	if err := Instrument(cfg, &Config{TracerProvider: tp, MeterProvider: mp}); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	apih, err := apiclient.New(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	if _, err := apih.FetchGraphWithContext(ctx, stringPtr("/graph/1")); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	parent.End()
+	if _, err := apih.FetchGraphs(); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if _, err := apih.Get("/rule_set/1"); err == nil {
+		t.Fatal("expected error")
+	}
+	if n := atomic.LoadInt32(&chained); n != 3 {
+		t.Fatalf("expected the existing OnCall hook called (%d)", n)
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 4 {
+		t.Fatalf("unexpected spans (%d)", len(ended))
+	}
+
+	tests := []struct {
+		name   string
+		attrs  map[attribute.Key]attribute.Value
+		status codes.Code
+	}{
+		{"GET /graph", map[attribute.Key]attribute.Value{
+			CIDKey:         attribute.StringValue("/graph/1"),
+			StatusKey:      attribute.IntValue(200),
+			RetriesKey:     attribute.IntValue(1),
+			RateLimitedKey: attribute.IntValue(1),
+		}, codes.Unset},
+		{"GET /graph", map[attribute.Key]attribute.Value{
+			StatusKey:  attribute.IntValue(200),
+			RetriesKey: attribute.IntValue(0),
+		}, codes.Unset},
+		{"GET /rule_set", map[attribute.Key]attribute.Value{
+			CIDKey:    attribute.StringValue("/rule_set/1"),
+			StatusKey: attribute.IntValue(404),
+		}, codes.Error},
+	}
+	// the parent span ends between the first and second call
+	calls := append(ended[:1:1], ended[2:]...)
+	for i, test := range tests {
+		span := calls[i]
+		if span.Name() != test.name || span.SpanKind() != trace.SpanKindClient {
+			t.Errorf("unexpected span %d (%s %s)", i, span.Name(), span.SpanKind())
+		}
+		attrs := map[attribute.Key]attribute.Value{}
+		for _, kv := range span.Attributes() {
+			attrs[kv.Key] = kv.Value
+		}
+		for k, v := range test.attrs {
+			if attrs[k] != v {
+				t.Errorf("span %d: expected %s=%s, got %s", i, k, v.Emit(), attrs[k].Emit())
+			}
+		}
+		if span.Status().Code != test.status {
+			t.Errorf("span %d: unexpected status (%s)", i, span.Status().Code)
+		}
+	}
+	if calls[0].Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("expected the span of the call to be a child of the context's span")
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	found := map[string]bool{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			found[m.Name] = true
+			switch data := m.Data.(type) {
+			case metricdata.Histogram[float64]:
+				var count uint64
+				for _, dp := range data.DataPoints {
+					count += dp.Count
+				}
+				if count != 3 {
+					t.Errorf("unexpected call durations (%d)", count)
+				}
+			case metricdata.Sum[int64]:
+				if len(data.DataPoints) != 1 || data.DataPoints[0].Value != 1 {
+					t.Errorf("unexpected rate limited responses (%+v)", data.DataPoints)
+				}
+			}
+		}
+	}
+	if !found["circonus.api.call.duration"] || !found["circonus.api.rate_limited"] {
+		t.Fatalf("unexpected metrics (%v)", found)
+	}
+}
+
+func TestInstrumentInvalid(t *testing.T) {
+	if err := Instrument(nil, nil); err == nil || err.Error() != "invalid API config (nil)" {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+
+	// default global providers
+	cfg := &apiclient.Config{}
+	if err := Instrument(cfg, nil); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if cfg.OnCall == nil {
+		t.Fatal("expected OnCall set")
+	}
+}
+
+func TestSplitPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		resource string
+		cid      string
+	}{
+		{"/graph", "graph", ""},
+		{"/graph/", "graph", ""},
+		{"/graph/1234", "graph", "/graph/1234"},
+		{"/graph/1234?x=y", "graph", "/graph/1234"},
+		{"/graph?search=foo", "graph", ""},
+		{"/check_bundle_metrics/1234", "check_bundle_metrics", "/check_bundle_metrics/1234"},
+	}
+	for _, test := range tests {
+		resource, cid := splitPath(test.path)
+		if resource != test.resource || cid != test.cid {
+			t.Errorf("%s: expected %s %s, got %s %s", test.path, test.resource, test.cid, resource, cid)
+		}
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
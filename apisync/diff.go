@@ -0,0 +1,273 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apisync
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// object is the generic (json) form of an api object
+type object map[string]interface{}
+
+// toObjects converts a slice of api objects to their generic form
+func toObjects(v interface{}) ([]object, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var objs []object
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&objs); err != nil {
+		return nil, err
+	}
+	return objs, nil
+}
+
+// decode converts the object to the passed api object
+func (o object) decode(v interface{}) error {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// str returns the string value of a field, empty if not a string
+func (o object) str(field string) string {
+	s, _ := o[field].(string)
+	return s
+}
+
+// hasTag reports whether the object tags contain tag
+func (o object) hasTag(tag string) bool {
+	tags, _ := o["tags"].([]interface{})
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// addTag adds tag to the object tags, if not already present
+func (o object) addTag(tag string) {
+	if o.hasTag(tag) {
+		return
+	}
+	tags, _ := o["tags"].([]interface{})
+	o["tags"] = append(tags, tag)
+}
+
+// FieldDiff defines a difference in a single field between a live object
+// and its desired definition
+type FieldDiff struct {
+	Path    string      // dotted path of the field, e.g. rules[0].value
+	Live    interface{} // nil if not set
+	Desired interface{} // nil if not set
+}
+
+// String returns the difference as "path: live => desired".
+func (d FieldDiff) String() string {
+	return fmt.Sprintf("%s: %s => %s", d.Path, diffValue(d.Live), diffValue(d.Desired))
+}
+
+func diffValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// diffObjects returns the differences between a live and desired object.
+// Only the fields the desired object sets are compared, fields it leaves
+// unset, null, empty, or at their zero value (e.g. defaults the API fills
+// in) keep their live values. Read-only fields (prefixed with _) are
+// ignored, arrays of named objects (e.g. metrics) are matched by name, and
+// arrays of other values (e.g. tags) are compared as sets.
+func diffObjects(live, desired object) []FieldDiff {
+	var diffs []FieldDiff
+	diffValues("", map[string]interface{}(live), map[string]interface{}(desired), &diffs)
+	return diffs
+}
+
+func diffValues(path string, live, desired interface{}, diffs *[]FieldDiff) {
+	if !isSet(desired) {
+		return
+	}
+
+	lm, lok := live.(map[string]interface{})
+	dm, dok := desired.(map[string]interface{})
+	if lok && dok {
+		keys := make([]string, 0, len(dm))
+		for k := range dm {
+			if !strings.HasPrefix(k, "_") {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			diffValues(p, lm[k], dm[k], diffs)
+		}
+		return
+	}
+
+	ls, lok := live.([]interface{})
+	ds, dok := desired.([]interface{})
+	if lok && dok {
+		lnamed, lnok := namedElements(ls)
+		dnamed, dnok := namedElements(ds)
+		switch {
+		case lnok && dnok:
+			names := make([]string, 0, len(lnamed)+len(dnamed))
+			for name := range dnamed {
+				names = append(names, name)
+			}
+			for name := range lnamed {
+				if _, ok := dnamed[name]; !ok {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				p := fmt.Sprintf("%s[%s]", path, name)
+				if _, ok := dnamed[name]; !ok {
+					// not desired, removed
+					*diffs = append(*diffs, FieldDiff{Path: p, Live: lnamed[name]})
+					continue
+				}
+				diffValues(p, lnamed[name], dnamed[name], diffs)
+			}
+			return
+		case sameElements(ls, ds):
+			return
+		case len(ls) == len(ds):
+			for i := range ls {
+				diffValues(fmt.Sprintf("%s[%d]", path, i), ls[i], ds[i], diffs)
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(live, desired) {
+		*diffs = append(*diffs, FieldDiff{Path: path, Live: live, Desired: desired})
+	}
+}
+
+// merge returns the live value with the fields the desired value sets (see
+// diffObjects) replaced, the value an update sends
+func merge(live, desired interface{}) interface{} {
+	if !isSet(desired) {
+		return live
+	}
+
+	lm, lok := live.(map[string]interface{})
+	dm, dok := desired.(map[string]interface{})
+	if lok && dok {
+		m := make(map[string]interface{}, len(lm)+len(dm))
+		for k, v := range lm {
+			m[k] = v
+		}
+		for k, v := range dm {
+			m[k] = merge(lm[k], v)
+		}
+		return m
+	}
+
+	ls, lok := live.([]interface{})
+	ds, dok := desired.([]interface{})
+	if lok && dok {
+		if lnamed, ok := namedElements(ls); ok {
+			if _, ok := namedElements(ds); ok {
+				merged := make([]interface{}, len(ds))
+				for i, v := range ds {
+					name, _ := v.(map[string]interface{})["name"].(string)
+					merged[i] = merge(lnamed[name], v)
+				}
+				return merged
+			}
+		}
+	}
+
+	return desired
+}
+
+// namedElements returns the elements of an array by name, if every element
+// is an object with a distinct name
+func namedElements(arr []interface{}) (map[string]interface{}, bool) {
+	named := make(map[string]interface{}, len(arr))
+	for _, v := range arr {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, ok := m["name"].(string)
+		if !ok || name == "" {
+			return nil, false
+		}
+		if _, dup := named[name]; dup {
+			return nil, false
+		}
+		named[name] = m
+	}
+	return named, true
+}
+
+// sameElements reports whether two arrays of values other than objects and
+// arrays hold the same elements, in any order
+func sameElements(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[interface{}]int, len(a))
+	for _, v := range a {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			return false
+		}
+		counts[v]++
+	}
+	for _, v := range b {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			return false
+		}
+		if counts[v] == 0 {
+			return false
+		}
+		counts[v]--
+	}
+	return true
+}
+
+// isSet reports whether v is set, not null, empty, or a zero value
+func isSet(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case []interface{}:
+		return len(t) > 0
+	case map[string]interface{}:
+		return len(t) > 0
+	case string:
+		return t != ""
+	case bool:
+		return t
+	case json.Number:
+		f, err := t.Float64()
+		return err != nil || f != 0
+	}
+	return true
+}
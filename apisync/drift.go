@@ -0,0 +1,99 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apisync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// DriftStatus defines how a live object drifted from its definition
+type DriftStatus string
+
+// Drift statuses
+const (
+	DriftMissing = DriftStatus("missing") // defined, no live object
+	DriftChanged = DriftStatus("changed") // live object differs from its definition
+	DriftExtra   = DriftStatus("extra")   // managed live object (carries Tag) which is not defined, Prune only
+)
+
+// Exit codes returned by DriftReport.ExitCode
+const (
+	ExitNoDrift = 0
+	ExitDrift   = 2
+)
+
+// Drift defines the drift of a single object
+type Drift struct {
+	Status DriftStatus
+	Kind   Kind
+	Key    string
+	CID    string      // empty if missing
+	Diffs  []FieldDiff // changed only
+}
+
+// DriftReport defines the drift of the live objects from their definitions
+type DriftReport struct {
+	Drifts []Drift
+}
+
+// HasDrift reports whether any live object drifted.
+func (d *DriftReport) HasDrift() bool {
+	return len(d.Drifts) > 0
+}
+
+// ExitCode returns ExitDrift if any live object drifted, ExitNoDrift
+// otherwise, for CI jobs guarding against changes made outside of the
+// definitions.
+func (d *DriftReport) ExitCode() int {
+	if d.HasDrift() {
+		return ExitDrift
+	}
+	return ExitNoDrift
+}
+
+// String returns the drifted objects, one per line, with the field
+// differences of changed objects.
+func (d *DriftReport) String() string {
+	var buf bytes.Buffer
+	for _, drift := range d.Drifts {
+		if drift.CID == "" {
+			fmt.Fprintf(&buf, "%s %s %q\n", drift.Status, drift.Kind, drift.Key)
+		} else {
+			fmt.Fprintf(&buf, "%s %s %q (%s)\n", drift.Status, drift.Kind, drift.Key, drift.CID)
+		}
+		for _, diff := range drift.Diffs {
+			fmt.Fprintf(&buf, "    %s\n", diff.String())
+		}
+	}
+	return buf.String()
+}
+
+// DriftReport compares the desired state to the live objects, without making
+// any changes, and reports per-field differences. Objects are matched as
+// for Plan.
+func (r *Reconciler) DriftReport(ctx context.Context, desired *State) (*DriftReport, error) {
+	plan, err := r.Plan(ctx, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DriftReport{}
+	for _, c := range plan.Changes {
+		drift := Drift{Kind: c.Kind, Key: c.Key, CID: c.CID, Diffs: c.Diffs}
+		switch c.Action {
+		case ActionCreate:
+			drift.Status = DriftMissing
+		case ActionUpdate:
+			drift.Status = DriftChanged
+		case ActionDelete:
+			drift.Status = DriftExtra
+		}
+		report.Drifts = append(report.Drifts, drift)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apisync
+
+import (
+	"context"
+	"testing"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+)
+
+func TestDriftReport(t *testing.T) {
+	store := map[string]interface{}{
+		"/graph/1":         apiclient.Graph{CID: "/graph/1", Title: "requests", Description: "edited", Tags: []string{"managed:sync"}},
+		"/graph/2":         apiclient.Graph{CID: "/graph/2", Title: "stray", Tags: []string{"managed:sync"}},
+		"/contact_group/1": apiclient.ContactGroup{CID: "/contact_group/1", Name: "ops", Tags: []string{"managed:sync"}},
+	}
+	r, server := testReconciler(t, store, &Config{Tag: "managed:sync", Prune: true})
+	defer server.Close()
+
+	desired := &State{
+		ContactGroups: []apiclient.ContactGroup{{Name: "ops"}},
+		Graphs: []apiclient.Graph{
+			{Title: "requests", Description: "original"},
+			{Title: "latency"},
+		},
+	}
+
+	report, err := r.DriftReport(context.Background(), desired)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	expected := `changed graph "requests" (/graph/1)
+    description: "edited" => "original"
+missing graph "latency"
+extra graph "stray" (/graph/2)
+`
+	if report.String() != expected {
+		t.Fatalf("unexpected report\n%s", report.String())
+	}
+	if !report.HasDrift() || report.ExitCode() != ExitDrift {
+		t.Fatalf("expected drift, exit code %d", report.ExitCode())
+	}
+	if len(store) != 3 {
+		t.Fatal("expected no changes to live objects")
+	}
+
+	report, err = r.DriftReport(context.Background(), &State{ContactGroups: desired.ContactGroups, Graphs: []apiclient.Graph{
+		{Title: "requests", Description: "edited"},
+		{Title: "stray"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if report.HasDrift() || report.ExitCode() != ExitNoDrift {
+		t.Fatalf("unexpected drift\n%s", report.String())
+	}
+}
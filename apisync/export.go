@@ -0,0 +1,416 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apisync
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Kinds of objects exported, in addition to the reconciled kinds
+const (
+	KindBroker        = Kind("broker")
+	KindMaintenance   = Kind("maintenance")
+	KindMetricCluster = Kind("metric_cluster")
+	KindRuleSetGroup  = Kind("rule_set_group")
+	KindWorksheet     = Kind("worksheet")
+)
+
+// Format defines the encoding of exported files
+type Format string
+
+// Formats
+const (
+	FormatJSON = Format("json")
+	FormatYAML = Format("yaml")
+)
+
+// RedactedValue replaces secrets in exported files
+const RedactedValue = "<redacted>"
+
+// ManifestFile is the name of the export manifest, in the export directory
+const ManifestFile = "manifest.json"
+
+// DefaultSecretPattern matches the check bundle config keys treated as secrets
+var DefaultSecretPattern = apiclient.DefaultSecretPattern
+
+// volatileFields are dropped from exported objects, they change without
+// any change to the configuration
+var volatileFields = []string{"_created", "_last_modified", "_last_modified_by", "_last_modifed_by"}
+
+// resource defines how objects of a kind are exported and imported
+type resource struct {
+	kind Kind
+	list func(api *apiclient.API) (interface{}, error)
+	// create, if set, creates an imported object and returns the result
+	create func(api *apiclient.API, o object) (interface{}, error)
+	// normalize, if set, adjusts an exported object
+	normalize func(o object)
+}
+
+// resources in dependency order, referenced objects first
+var resources = []*resource{
+	{
+		kind: KindBroker,
+		list: func(api *apiclient.API) (interface{}, error) { return api.FetchBrokers() },
+		// brokers are not restorable, only what identifies them is kept
+		normalize: func(o object) {
+			for k := range o {
+				switch k {
+				case "_cid", "_name", "_type":
+				default:
+					delete(o, k)
+				}
+			}
+		},
+	},
+	{
+		kind: KindContactGroup,
+		list: func(api *apiclient.API) (interface{}, error) { return api.FetchContactGroups() },
+		create: func(api *apiclient.API, o object) (interface{}, error) {
+			cfg := &apiclient.ContactGroup{}
+			if err := o.decode(cfg); err != nil {
+				return nil, err
+			}
+			return api.CreateContactGroup(cfg)
+		},
+	},
+	{
+		kind: KindCheckBundle,
+		list: func(api *apiclient.API) (interface{}, error) { return api.FetchCheckBundles() },
+		create: func(api *apiclient.API, o object) (interface{}, error) {
+			cfg := &apiclient.CheckBundle{}
+			if err := o.decode(cfg); err != nil {
+				return nil, err
+			}
+			return api.CreateCheckBundle(cfg)
+		},
+	},
+	{
+		kind: KindMetricCluster,
+		list: func(api *apiclient.API) (interface{}, error) { return api.FetchMetricClusters("") },
+		create: func(api *apiclient.API, o object) (interface{}, error) {
+			cfg := &apiclient.MetricCluster{}
+			if err := o.decode(cfg); err != nil {
+				return nil, err
+			}
+			return api.CreateMetricCluster(cfg)
+		},
+	},
+	{
+		kind: KindGraph,
+		list: func(api *apiclient.API) (interface{}, error) { return api.FetchGraphs() },
+		create: func(api *apiclient.API, o object) (interface{}, error) {
+			cfg := &apiclient.Graph{}
+			if err := o.decode(cfg); err != nil {
+				return nil, err
+			}
+			return api.CreateGraph(cfg)
+		},
+	},
+	{
+		kind: KindWorksheet,
+		list: func(api *apiclient.API) (interface{}, error) { return api.FetchWorksheets() },
+		create: func(api *apiclient.API, o object) (interface{}, error) {
+			cfg := &apiclient.Worksheet{}
+			if err := o.decode(cfg); err != nil {
+				return nil, err
+			}
+			return api.CreateWorksheet(cfg)
+		},
+	},
+	{
+		kind: KindDashboard,
+		list: func(api *apiclient.API) (interface{}, error) { return api.FetchDashboards() },
+		create: func(api *apiclient.API, o object) (interface{}, error) {
+			cfg := &apiclient.Dashboard{}
+			if err := o.decode(cfg); err != nil {
+				return nil, err
+			}
+			return api.CreateDashboard(cfg)
+		},
+	},
+	{
+		kind: KindRuleSet,
+		list: func(api *apiclient.API) (interface{}, error) { return api.FetchRuleSets() },
+		create: func(api *apiclient.API, o object) (interface{}, error) {
+			cfg := &apiclient.RuleSet{}
+			if err := o.decode(cfg); err != nil {
+				return nil, err
+			}
+			return api.CreateRuleSet(cfg)
+		},
+	},
+	{
+		kind: KindRuleSetGroup,
+		list: func(api *apiclient.API) (interface{}, error) { return api.FetchRuleSetGroups() },
+		create: func(api *apiclient.API, o object) (interface{}, error) {
+			cfg := &apiclient.RuleSetGroup{}
+			if err := o.decode(cfg); err != nil {
+				return nil, err
+			}
+			return api.CreateRuleSetGroup(cfg)
+		},
+	},
+	{
+		kind: KindMaintenance,
+		list: func(api *apiclient.API) (interface{}, error) { return api.FetchMaintenanceWindows() },
+		create: func(api *apiclient.API, o object) (interface{}, error) {
+			cfg := &apiclient.Maintenance{}
+			if err := o.decode(cfg); err != nil {
+				return nil, err
+			}
+			return api.CreateMaintenanceWindow(cfg)
+		},
+	},
+}
+
+// ExportOptions defines the export options
+type ExportOptions struct {
+	// Format of the exported files - default json
+	Format Format
+	// Kinds to export - default all
+	Kinds []Kind
+	// IncludeSecrets writes secrets as is, by default they are replaced
+	// with RedactedValue and listed in the manifest
+	IncludeSecrets bool
+	// SecretPattern matches the check bundle config keys which hold
+	// secrets - default DefaultSecretPattern
+	SecretPattern *regexp.Regexp
+	// Workers defines the number of kinds listed concurrently - default
+	// apiclient.DefaultFetchWorkers
+	Workers int
+}
+
+// SecretRef identifies a redacted secret
+type SecretRef struct {
+	CID  string `json:"cid"`
+	Path string `json:"path"` // e.g. config.password
+}
+
+// Manifest describes an export
+type Manifest struct {
+	Format   Format       `json:"format"`
+	Counts   map[Kind]int `json:"counts"`
+	Redacted []SecretRef  `json:"redacted,omitempty"`
+}
+
+// ExportAccount writes every object of the exported kinds to dir, one file
+// per object in a directory per kind (e.g. dir/graph/<id>.json), along with
+// a manifest. Objects are normalized (sorted keys, volatile fields dropped)
+// so successive exports can be diffed. Files left from a previous export of
+// an exported kind are removed.
+func (r *Reconciler) ExportAccount(ctx context.Context, dir string, opts *ExportOptions) (*Manifest, error) {
+	if dir == "" {
+		return nil, errors.New("invalid export directory (none)")
+	}
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+	format := opts.Format
+	if format == "" {
+		format = FormatJSON
+	}
+	if format != FormatJSON && format != FormatYAML {
+		return nil, errors.Errorf("invalid export format (%s)", format)
+	}
+	secretPattern := opts.SecretPattern
+	if secretPattern == nil {
+		secretPattern = DefaultSecretPattern
+	}
+	selected, err := selectResources(opts.Kinds)
+	if err != nil {
+		return nil, err
+	}
+
+	// list every kind up front, in parallel, then write them in order
+	listed := make([][]object, len(selected))
+	err = r.api.FetchEach(ctx, len(selected), &apiclient.FetchAllOptions{Workers: opts.Workers}, func(_ context.Context, i int) error {
+		res := selected[i]
+		v, err := res.list(r.api)
+		if err != nil {
+			return errors.Wrapf(err, "listing %ss", res.kind)
+		}
+		objs, err := toObjects(v)
+		if err != nil {
+			return errors.Wrapf(err, "converting %ss", res.kind)
+		}
+		listed[i] = objs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{Format: format, Counts: make(map[Kind]int)}
+	for i, res := range selected {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		objs := listed[i]
+
+		kindDir := filepath.Join(dir, string(res.kind))
+		if err := resetDir(kindDir); err != nil {
+			return nil, err
+		}
+
+		for _, o := range objs {
+			for _, f := range volatileFields {
+				delete(o, f)
+			}
+			if res.normalize != nil {
+				res.normalize(o)
+			}
+			if res.kind == KindCheckBundle && !opts.IncludeSecrets {
+				manifest.Redacted = append(manifest.Redacted, redactSecrets(o, secretPattern)...)
+			}
+
+			cid := o.str("_cid")
+			if cid == "" {
+				return nil, errors.Errorf("exporting %s, no cid", res.kind)
+			}
+			data, err := encodeObject(o, format)
+			if err != nil {
+				return nil, errors.Wrapf(err, "encoding %s", cid)
+			}
+			file := filepath.Join(kindDir, objectFileName(res.kind, cid, format))
+			if err := ioutil.WriteFile(file, data, 0600); err != nil {
+				return nil, errors.Wrapf(err, "writing %s", cid)
+			}
+		}
+		manifest.Counts[res.kind] = len(objs)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding manifest")
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, ManifestFile), append(data, '\n'), 0600); err != nil {
+		return nil, errors.Wrap(err, "writing manifest")
+	}
+
+	return manifest, nil
+}
+
+// selectResources returns the resources of the passed kinds, in dependency order
+func selectResources(kinds []Kind) ([]*resource, error) {
+	if len(kinds) == 0 {
+		return resources, nil
+	}
+	want := make(map[Kind]bool, len(kinds))
+	for _, k := range kinds {
+		want[k] = true
+	}
+	var selected []*resource
+	for _, res := range resources {
+		if want[res.kind] {
+			selected = append(selected, res)
+			delete(want, res.kind)
+		}
+	}
+	for k := range want {
+		return nil, errors.Errorf("invalid kind (%s)", k)
+	}
+	return selected, nil
+}
+
+// resetDir creates dir, removing any previously exported files
+func resetDir(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrap(err, "creating export directory")
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return errors.Wrap(err, "reading export directory")
+	}
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".json" && ext != ".yaml") {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+			return errors.Wrap(err, "removing previous export")
+		}
+	}
+	return nil
+}
+
+// objectFileName returns the file name for an object, its cid without the kind prefix
+func objectFileName(k Kind, cid string, format Format) string {
+	id := strings.TrimPrefix(cid, "/"+string(k)+"/")
+	return url.PathEscape(id) + "." + string(format)
+}
+
+// redactSecrets replaces the check bundle config values with keys matching
+// pattern, returns the secrets redacted
+func redactSecrets(o object, pattern *regexp.Regexp) []SecretRef {
+	cfg, ok := o["config"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(cfg))
+	for k := range cfg {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var refs []SecretRef
+	for _, k := range keys {
+		if s, ok := cfg[k].(string); !ok || s == "" || !pattern.MatchString(k) {
+			continue
+		}
+		cfg[k] = RedactedValue
+		refs = append(refs, SecretRef{CID: o.str("_cid"), Path: "config." + k})
+	}
+	return refs
+}
+
+// encodeObject encodes an object with sorted keys
+func encodeObject(o object, format Format) ([]byte, error) {
+	if format == FormatYAML {
+		return yaml.Marshal(plainNumbers(map[string]interface{}(o)))
+	}
+	data, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// plainNumbers converts json.Number values to int64 or float64, so they are
+// encoded as numbers (not strings) in yaml
+func plainNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		if f, err := t.Float64(); err == nil {
+			return f
+		}
+		return t.String()
+	case map[string]interface{}:
+		for k, val := range t {
+			t[k] = plainNumbers(val)
+		}
+	case []interface{}:
+		for i, val := range t {
+			t[i] = plainNumbers(val)
+		}
+	}
+	return v
+}
@@ -0,0 +1,165 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apisync
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+)
+
+func testExportStore() map[string]interface{} {
+	return map[string]interface{}{
+		"/broker/1": apiclient.Broker{
+			CID:     "/broker/1",
+			Name:    "public",
+			Type:    "circonus",
+			Details: []apiclient.BrokerDetail{{CN: "broker.example.com", Status: "active"}},
+		},
+		"/check_bundle/1": apiclient.CheckBundle{
+			CID:          "/check_bundle/1",
+			Brokers:      []string{"/broker/1"},
+			Config:       apiclient.CheckBundleConfig{"url": "https://example.com/", "auth_password": "hunter2", "header_X-Api-Token": "abc"},
+			DisplayName:  "web",
+			LastModified: 1234567890,
+			Period:       60,
+			Target:       "example.com",
+			Type:         "http",
+		},
+		"/graph/abc-123": apiclient.Graph{CID: "/graph/abc-123", Title: "requests"},
+	}
+}
+
+func TestExportAccount(t *testing.T) {
+	store := testExportStore()
+	r, server := testReconciler(t, store, &Config{})
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "export")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifest, err := r.ExportAccount(context.Background(), dir, nil)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if manifest.Counts[KindCheckBundle] != 1 || manifest.Counts[KindGraph] != 1 || manifest.Counts[KindDashboard] != 0 {
+		t.Fatalf("unexpected counts (%v)", manifest.Counts)
+	}
+	expectedRedacted := []SecretRef{
+		{CID: "/check_bundle/1", Path: "config.auth_password"},
+		{CID: "/check_bundle/1", Path: "config.header_X-Api-Token"},
+	}
+	if !reflect.DeepEqual(manifest.Redacted, expectedRedacted) {
+		t.Fatalf("unexpected redacted secrets (%v)", manifest.Redacted)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "check_bundle", "1.json"))
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	var bundle map[string]interface{}
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	cfg := bundle["config"].(map[string]interface{})
+	if cfg["auth_password"] != RedactedValue || cfg["url"] != "https://example.com/" {
+		t.Fatalf("unexpected config (%v)", cfg)
+	}
+	if _, ok := bundle["_last_modified"]; ok {
+		t.Fatal("expected volatile _last_modified to be dropped")
+	}
+
+	data, err = ioutil.ReadFile(filepath.Join(dir, "broker", "1.json"))
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if strings.Contains(string(data), "_details") {
+		t.Fatalf("expected broker details to be dropped\n%s", string(data))
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ManifestFile)); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	// a second export removes files of deleted objects
+	delete(store, "/graph/abc-123")
+	if _, err := r.ExportAccount(context.Background(), dir, &ExportOptions{Kinds: []Kind{KindGraph}}); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "graph", "abc-123.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected stale graph file to be removed (%v)", err)
+	}
+}
+
+func TestExportAccountYAML(t *testing.T) {
+	r, server := testReconciler(t, testExportStore(), &Config{})
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "export")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifest, err := r.ExportAccount(context.Background(), dir, &ExportOptions{Format: FormatYAML, IncludeSecrets: true, Kinds: []Kind{KindCheckBundle}})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(manifest.Redacted) != 0 {
+		t.Fatalf("unexpected redacted secrets (%v)", manifest.Redacted)
+	}
+	if _, ok := manifest.Counts[KindGraph]; ok {
+		t.Fatalf("unexpected counts (%v)", manifest.Counts)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "check_bundle", "1.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	for _, s := range []string{"period: 60\n", "auth_password: hunter2\n", "display_name: web\n"} {
+		if !strings.Contains(string(data), s) {
+			t.Fatalf("expected %q in\n%s", s, string(data))
+		}
+	}
+}
+
+func TestExportAccountErrors(t *testing.T) {
+	r, server := testReconciler(t, map[string]interface{}{}, &Config{})
+	defer server.Close()
+
+	tests := []struct {
+		id          string
+		dir         string
+		opts        *ExportOptions
+		expectedErr string
+	}{
+		{"invalid (dir)", "", nil, "invalid export directory (none)"},
+		{"invalid (format)", "x", &ExportOptions{Format: "xml"}, "invalid export format (xml)"},
+		{"invalid (kind)", "x", &ExportOptions{Kinds: []Kind{"alert"}}, "invalid kind (alert)"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			_, err := r.ExportAccount(context.Background(), test.dir, test.opts)
+			if err == nil {
+				t.Fatal("expected error")
+			} else if err.Error() != test.expectedErr {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+		})
+	}
+}
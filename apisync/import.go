@@ -0,0 +1,290 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apisync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// brokerAssignedConfig are check bundle config keys set by the broker, they
+// are not restorable
+var brokerAssignedConfig = []string{string(config.SubmissionURL), string(config.ReverseSecretKey)}
+
+// ImportOptions defines the import options
+type ImportOptions struct {
+	// Kinds to import - default all
+	Kinds []Kind
+	// BrokerMap maps exported broker cids to brokers of the target account,
+	// by default brokers are matched by name and type
+	BrokerMap map[string]string
+	// Secrets returns the value of a secret redacted on export, required
+	// to import check bundles with redacted secrets
+	Secrets func(ref SecretRef) (string, bool)
+}
+
+// ImportResult describes an import
+type ImportResult struct {
+	// CIDs maps the cids of exported objects to the objects created (or
+	// brokers matched) in the target account
+	CIDs map[string]string
+	// Counts of the objects created, by kind
+	Counts map[Kind]int
+}
+
+// ImportAccount recreates the objects exported to dir (see ExportAccount) in
+// dependency order: brokers (matched, not created), contact groups, check
+// bundles, metric clusters, graphs, worksheets, dashboards, rule sets, rule
+// set groups, and maintenance windows. References to other exported objects
+// are rewritten to the objects created. The import stops at the first error,
+// the result reports what was created up to then.
+func (r *Reconciler) ImportAccount(ctx context.Context, dir string, opts *ImportOptions) (*ImportResult, error) {
+	if dir == "" {
+		return nil, errors.New("invalid import directory (none)")
+	}
+	if opts == nil {
+		opts = &ImportOptions{}
+	}
+	selected, err := selectResources(opts.Kinds)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{CIDs: make(map[string]string), Counts: make(map[Kind]int)}
+	for _, res := range selected {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		objs, err := readObjects(filepath.Join(dir, string(res.kind)))
+		if err != nil {
+			return result, errors.Wrapf(err, "reading %ss", res.kind)
+		}
+		if len(objs) == 0 {
+			continue
+		}
+
+		if res.kind == KindBroker {
+			if err := r.mapBrokers(objs, opts.BrokerMap, result.CIDs); err != nil {
+				return result, err
+			}
+			continue
+		}
+
+		for _, o := range objs {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			if err := r.importObject(res, o, opts, result); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// importObject creates a single exported object
+func (r *Reconciler) importObject(res *resource, o object, opts *ImportOptions, result *ImportResult) error {
+	oldCID := o.str("_cid")
+	oldChecks, _ := o["_checks"].([]interface{})
+
+	if res.kind == KindCheckBundle {
+		if err := restoreSecrets(o, opts.Secrets); err != nil {
+			return err
+		}
+		if cfg, ok := o["config"].(map[string]interface{}); ok {
+			for _, k := range brokerAssignedConfig {
+				delete(cfg, k)
+			}
+		}
+	}
+	for k := range o {
+		if strings.HasPrefix(k, "_") {
+			delete(o, k)
+		}
+	}
+	remapCIDs(map[string]interface{}(o), result.CIDs)
+
+	v, err := res.create(r.api, o)
+	if err != nil {
+		return errors.Wrapf(err, "creating %s %s", res.kind, oldCID)
+	}
+	created, err := toObjects([]interface{}{v})
+	if err != nil {
+		return errors.Wrapf(err, "converting %s %s", res.kind, oldCID)
+	}
+	newCID := created[0].str("_cid")
+	r.api.Debugf("import, created %s %s => %s", res.kind, oldCID, newCID)
+
+	result.Counts[res.kind]++
+	if oldCID == "" {
+		return nil
+	}
+	result.CIDs[oldCID] = newCID
+
+	// the checks of a bundle are created with it, one per broker
+	newChecks, _ := created[0]["_checks"].([]interface{})
+	for i := range oldChecks {
+		if i >= len(newChecks) {
+			break
+		}
+		oc, _ := oldChecks[i].(string)
+		nc, _ := newChecks[i].(string)
+		if oc != "" && nc != "" {
+			result.CIDs[oc] = nc
+		}
+	}
+
+	return nil
+}
+
+// mapBrokers maps exported brokers to brokers of the target account
+func (r *Reconciler) mapBrokers(exported []object, brokerMap map[string]string, cids map[string]string) error {
+	brokers, err := r.api.FetchBrokers()
+	if err != nil {
+		return errors.Wrap(err, "listing brokers")
+	}
+	for _, o := range exported {
+		cid := o.str("_cid")
+		if mapped, ok := brokerMap[cid]; ok {
+			cids[cid] = mapped
+			continue
+		}
+		for _, b := range *brokers {
+			if b.Name == o.str("_name") && b.Type == o.str("_type") {
+				cids[cid] = b.CID
+				break
+			}
+		}
+		if _, ok := cids[cid]; !ok {
+			return errors.Errorf("no broker named %q (%s) for %s, set BrokerMap", o.str("_name"), o.str("_type"), cid)
+		}
+	}
+	return nil
+}
+
+// restoreSecrets replaces redacted check bundle config values
+func restoreSecrets(o object, secrets func(SecretRef) (string, bool)) error {
+	cfg, ok := o["config"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	for k, v := range cfg {
+		if v != RedactedValue {
+			continue
+		}
+		ref := SecretRef{CID: o.str("_cid"), Path: "config." + k}
+		if secrets == nil {
+			return errors.Errorf("secret %s of %s redacted, no Secrets", ref.Path, ref.CID)
+		}
+		val, ok := secrets(ref)
+		if !ok {
+			return errors.Errorf("secret %s of %s redacted, not provided", ref.Path, ref.CID)
+		}
+		cfg[k] = val
+	}
+	return nil
+}
+
+// remapCIDs rewrites references to mapped objects, in place
+func remapCIDs(v interface{}, cids map[string]string) interface{} {
+	switch t := v.(type) {
+	case string:
+		if mapped, ok := cids[t]; ok {
+			return mapped
+		}
+	case map[string]interface{}:
+		for k, val := range t {
+			if prefix, ok := config.BareIDFields[k]; ok {
+				t[k] = remapBareID(val, prefix, cids)
+				continue
+			}
+			t[k] = remapCIDs(val, cids)
+		}
+	case []interface{}:
+		for i, val := range t {
+			t[i] = remapCIDs(val, cids)
+		}
+	}
+	return v
+}
+
+// remapBareID rewrites an id without its cid prefix, keeping its type
+func remapBareID(v interface{}, prefix string, cids map[string]string) interface{} {
+	var id string
+	switch t := v.(type) {
+	case string:
+		id = t
+	case json.Number:
+		id = t.String()
+	default:
+		return v
+	}
+	mapped, ok := cids[prefix+"/"+id]
+	if !ok {
+		return v
+	}
+	mapped = strings.TrimPrefix(mapped, prefix+"/")
+	if _, isNum := v.(json.Number); isNum {
+		return json.Number(mapped)
+	}
+	return mapped
+}
+
+// readObjects reads the exported objects in dir, ordered by file name
+func readObjects(dir string) ([]object, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if !e.IsDir() && (ext == ".json" || ext == ".yaml") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	objs := make([]object, 0, len(names))
+	for _, name := range names {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		if filepath.Ext(name) == ".yaml" {
+			var raw interface{}
+			if err := yaml.Unmarshal(data, &raw); err != nil {
+				return nil, errors.Wrapf(err, "parsing %s", name)
+			}
+			if data, err = json.Marshal(jsonCompatible(raw)); err != nil {
+				return nil, errors.Wrapf(err, "parsing %s", name)
+			}
+		}
+		var o object
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&o); err != nil {
+			return nil, errors.Wrapf(err, "parsing %s", name)
+		}
+		objs = append(objs, o)
+	}
+
+	return objs, nil
+}
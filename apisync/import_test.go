@@ -0,0 +1,161 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apisync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+)
+
+func TestImportAccount(t *testing.T) {
+	source := map[string]interface{}{
+		"/broker/1":        apiclient.Broker{CID: "/broker/1", Name: "public", Type: "circonus"},
+		"/contact_group/1": apiclient.ContactGroup{CID: "/contact_group/1", Name: "ops"},
+		"/check_bundle/1": apiclient.CheckBundle{
+			CID:         "/check_bundle/1",
+			Brokers:     []string{"/broker/1"},
+			Checks:      []string{"/check/11"},
+			Config:      apiclient.CheckBundleConfig{"url": "https://example.com/", "auth_password": "hunter2"},
+			DisplayName: "web",
+			Target:      "example.com",
+			Type:        "http",
+		},
+		"/graph/abc-123": apiclient.Graph{
+			CID:        "/graph/abc-123",
+			Title:      "requests",
+			Datapoints: []apiclient.GraphDatapoint{{CheckID: 11, MetricName: "duration"}},
+		},
+		"/dashboard/1": apiclient.Dashboard{
+			CID:     "/dashboard/1",
+			Title:   "web",
+			Widgets: []apiclient.DashboardWidget{{Name: "Graph", Settings: apiclient.DashboardWidgetSettings{GraphUUID: "abc-123"}}},
+		},
+		"/rule_set/11_duration": apiclient.RuleSet{
+			CID:           "/rule_set/11_duration",
+			CheckCID:      "/check/11",
+			ContactGroups: map[uint8][]string{1: {"/contact_group/1"}},
+			MetricName:    "duration",
+		},
+	}
+	src, srcServer := testReconciler(t, source, &Config{})
+	defer srcServer.Close()
+
+	dir, err := ioutil.TempDir("", "import")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := src.ExportAccount(context.Background(), dir, &ExportOptions{Format: FormatYAML}); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	target := map[string]interface{}{
+		"/broker/9": apiclient.Broker{CID: "/broker/9", Name: "public", Type: "circonus"},
+	}
+	dst, dstServer := testReconciler(t, target, &Config{})
+	defer dstServer.Close()
+
+	if _, err := dst.ImportAccount(context.Background(), dir, nil); err == nil {
+		t.Fatal("expected error")
+	} else if err.Error() != "secret config.auth_password of /check_bundle/1 redacted, no Secrets" {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	for cid := range target {
+		if cid != "/broker/9" {
+			delete(target, cid)
+		}
+	}
+	secrets := func(ref SecretRef) (string, bool) {
+		return "hunter2", ref == SecretRef{CID: "/check_bundle/1", Path: "config.auth_password"}
+	}
+	result, err := dst.ImportAccount(context.Background(), dir, &ImportOptions{Secrets: secrets})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if result.CIDs["/broker/1"] != "/broker/9" {
+		t.Fatalf("unexpected broker mapping (%v)", result.CIDs)
+	}
+	if result.Counts[KindCheckBundle] != 1 || result.Counts[KindRuleSet] != 1 || result.Counts[KindDashboard] != 1 {
+		t.Fatalf("unexpected counts (%v)", result.Counts)
+	}
+
+	var bundle apiclient.CheckBundle
+	var ruleSet apiclient.RuleSet
+	var graph apiclient.Graph
+	var dashboard apiclient.Dashboard
+	for oldCID, v := range map[string]interface{}{
+		"/check_bundle/1":       &bundle,
+		"/rule_set/11_duration": &ruleSet,
+		"/graph/abc-123":        &graph,
+		"/dashboard/1":          &dashboard,
+	} {
+		newCID, ok := result.CIDs[oldCID]
+		if !ok {
+			t.Fatalf("no mapping for %s (%v)", oldCID, result.CIDs)
+		}
+		b, _ := json.Marshal(target[newCID])
+		if err := json.Unmarshal(b, v); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+	}
+
+	newCheck := bundle.Checks[0]
+	if result.CIDs["/check/11"] != newCheck {
+		t.Fatalf("unexpected check mapping (%v)", result.CIDs)
+	}
+	if bundle.Brokers[0] != "/broker/9" || bundle.Config["auth_password"] != "hunter2" {
+		t.Fatalf("unexpected check bundle (%#v)", bundle)
+	}
+	if ruleSet.CheckCID != newCheck || ruleSet.ContactGroups[1][0] != result.CIDs["/contact_group/1"] {
+		t.Fatalf("unexpected rule set (%#v)", ruleSet)
+	}
+	if fmt.Sprintf("/check/%d", graph.Datapoints[0].CheckID) != newCheck {
+		t.Fatalf("unexpected graph datapoint (%#v)", graph.Datapoints[0])
+	}
+	if "/graph/"+dashboard.Widgets[0].Settings.GraphUUID != result.CIDs["/graph/abc-123"] {
+		t.Fatalf("unexpected dashboard widget (%#v)", dashboard.Widgets[0].Settings)
+	}
+}
+
+func TestImportAccountBrokers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "import")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.Mkdir(dir+"/broker", 0700); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if err := ioutil.WriteFile(dir+"/broker/1.json", []byte(`{"_cid":"/broker/1","_name":"private","_type":"enterprise"}`), 0600); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	r, server := testReconciler(t, map[string]interface{}{}, &Config{})
+	defer server.Close()
+
+	if _, err := r.ImportAccount(context.Background(), dir, nil); err == nil {
+		t.Fatal("expected error")
+	} else if err.Error() != `no broker named "private" (enterprise) for /broker/1, set BrokerMap` {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	result, err := r.ImportAccount(context.Background(), dir, &ImportOptions{BrokerMap: map[string]string{"/broker/1": "/broker/5"}})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if result.CIDs["/broker/1"] != "/broker/5" {
+		t.Fatalf("unexpected broker mapping (%v)", result.CIDs)
+	}
+}
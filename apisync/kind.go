@@ -0,0 +1,209 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apisync
+
+import (
+	"fmt"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+)
+
+// kind defines how objects of a kind are identified, listed, and modified
+type kind struct {
+	kind    Kind
+	keyDesc string
+	tagged  bool
+	key     func(o object) string
+	desired func(s *State) interface{}
+	list    func(api *apiclient.API) (interface{}, error)
+	create  func(api *apiclient.API, o object) (interface{}, error)
+	update  func(api *apiclient.API, o object) error
+	delete  func(api *apiclient.API, cid string) error
+}
+
+// kinds in dependency order, referenced objects first
+var kinds = []*kind{
+	{
+		kind:    KindContactGroup,
+		keyDesc: "name",
+		tagged:  true,
+		key:     func(o object) string { return o.str("name") },
+		desired: func(s *State) interface{} { return s.ContactGroups },
+		list:    func(api *apiclient.API) (interface{}, error) { return api.FetchContactGroups() },
+		create: func(api *apiclient.API, o object) (interface{}, error) {
+			cfg := &apiclient.ContactGroup{}
+			if err := o.decode(cfg); err != nil {
+				return nil, err
+			}
+			res, err := api.CreateContactGroup(cfg)
+			if err != nil {
+				return nil, err
+			}
+			return res, nil
+		},
+		update: func(api *apiclient.API, o object) error {
+			cfg := &apiclient.ContactGroup{}
+			if err := o.decode(cfg); err != nil {
+				return err
+			}
+			_, err := api.UpdateContactGroup(cfg)
+			return err
+		},
+		delete: func(api *apiclient.API, cid string) error {
+			_, err := api.DeleteContactGroupByCID(&cid)
+			return err
+		},
+	},
+	{
+		kind:    KindCheckBundle,
+		keyDesc: "type, target, and display_name",
+		tagged:  true,
+		key: func(o object) string {
+			if o.str("type") == "" && o.str("target") == "" && o.str("display_name") == "" {
+				return ""
+			}
+			return checkBundleKey(o.str("type"), o.str("target"), o.str("display_name"))
+		},
+		desired: func(s *State) interface{} { return s.CheckBundles },
+		list:    func(api *apiclient.API) (interface{}, error) { return api.FetchCheckBundles() },
+		create: func(api *apiclient.API, o object) (interface{}, error) {
+			cfg := &apiclient.CheckBundle{}
+			if err := o.decode(cfg); err != nil {
+				return nil, err
+			}
+			res, err := api.CreateCheckBundle(cfg)
+			if err != nil {
+				return nil, err
+			}
+			return res, nil
+		},
+		update: func(api *apiclient.API, o object) error {
+			cfg := &apiclient.CheckBundle{}
+			if err := o.decode(cfg); err != nil {
+				return err
+			}
+			_, err := api.UpdateCheckBundle(cfg)
+			return err
+		},
+		delete: func(api *apiclient.API, cid string) error {
+			_, err := api.DeleteCheckBundleByCID(&cid)
+			return err
+		},
+	},
+	{
+		kind:    KindGraph,
+		keyDesc: "title",
+		tagged:  true,
+		key:     func(o object) string { return o.str("title") },
+		desired: func(s *State) interface{} { return s.Graphs },
+		list:    func(api *apiclient.API) (interface{}, error) { return api.FetchGraphs() },
+		create: func(api *apiclient.API, o object) (interface{}, error) {
+			cfg := &apiclient.Graph{}
+			if err := o.decode(cfg); err != nil {
+				return nil, err
+			}
+			res, err := api.CreateGraph(cfg)
+			if err != nil {
+				return nil, err
+			}
+			return res, nil
+		},
+		update: func(api *apiclient.API, o object) error {
+			cfg := &apiclient.Graph{}
+			if err := o.decode(cfg); err != nil {
+				return err
+			}
+			_, err := api.UpdateGraph(cfg)
+			return err
+		},
+		delete: func(api *apiclient.API, cid string) error {
+			_, err := api.DeleteGraphByCID(&cid)
+			return err
+		},
+	},
+	{
+		kind:    KindRuleSet,
+		keyDesc: "check and metric_name",
+		tagged:  true,
+		key: func(o object) string {
+			metric := o.str("metric_name")
+			if metric == "" {
+				metric = o.str("metric_pattern")
+			}
+			if o.str("check") == "" || metric == "" {
+				return ""
+			}
+			return o.str("check") + ":" + metric
+		},
+		desired: func(s *State) interface{} { return s.RuleSets },
+		list:    func(api *apiclient.API) (interface{}, error) { return api.FetchRuleSets() },
+		create: func(api *apiclient.API, o object) (interface{}, error) {
+			cfg := &apiclient.RuleSet{}
+			if err := o.decode(cfg); err != nil {
+				return nil, err
+			}
+			res, err := api.CreateRuleSet(cfg)
+			if err != nil {
+				return nil, err
+			}
+			return res, nil
+		},
+		update: func(api *apiclient.API, o object) error {
+			cfg := &apiclient.RuleSet{}
+			if err := o.decode(cfg); err != nil {
+				return err
+			}
+			_, err := api.UpdateRuleSet(cfg)
+			return err
+		},
+		delete: func(api *apiclient.API, cid string) error {
+			_, err := api.DeleteRuleSetByCID(&cid)
+			return err
+		},
+	},
+	{
+		kind:    KindDashboard,
+		keyDesc: "title",
+		key:     func(o object) string { return o.str("title") },
+		desired: func(s *State) interface{} { return s.Dashboards },
+		list:    func(api *apiclient.API) (interface{}, error) { return api.FetchDashboards() },
+		create: func(api *apiclient.API, o object) (interface{}, error) {
+			cfg := &apiclient.Dashboard{}
+			if err := o.decode(cfg); err != nil {
+				return nil, err
+			}
+			res, err := api.CreateDashboard(cfg)
+			if err != nil {
+				return nil, err
+			}
+			return res, nil
+		},
+		update: func(api *apiclient.API, o object) error {
+			cfg := &apiclient.Dashboard{}
+			if err := o.decode(cfg); err != nil {
+				return err
+			}
+			_, err := api.UpdateDashboard(cfg)
+			return err
+		},
+		delete: func(api *apiclient.API, cid string) error {
+			_, err := api.DeleteDashboardByCID(&cid)
+			return err
+		},
+	},
+}
+
+var kindsByName = func() map[Kind]*kind {
+	m := make(map[Kind]*kind, len(kinds))
+	for _, k := range kinds {
+		m[k.kind] = k
+	}
+	return m
+}()
+
+// checkBundleKey returns the natural key of a check bundle
+func checkBundleKey(checkType, target, displayName string) string {
+	return fmt.Sprintf("%s:%s:%s", checkType, target, displayName)
+}
@@ -0,0 +1,183 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apisync
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/pkg/errors"
+)
+
+// offlineURL is the API URL of offline APIs, never connected to
+const offlineURL = "https://offline.invalid/v2"
+
+// Snapshot serves API calls from the objects of an export directory (see
+// ExportAccount), as an http.RoundTripper for the apiclient Config
+// Transport. It is read-only: GET requests are served, other requests fail
+// with 405 Method Not Allowed.
+//
+// Objects are served as exported, without volatile fields and with secrets
+// redacted (unless exported with IncludeSecrets). Lists support the search
+// (objects containing the text) and f_<field> (equal fields) query
+// parameters; other parameters are ignored.
+type Snapshot struct {
+	Manifest *Manifest
+
+	objects map[string][]byte   // json by cid
+	lists   map[string][]string // cids by kind prefix (e.g. /graph), in file order
+}
+
+// OpenSnapshot reads the objects of an export directory
+func OpenSnapshot(dir string) (*Snapshot, error) {
+	if dir == "" {
+		return nil, errors.New("invalid snapshot directory (none)")
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Errorf("invalid snapshot directory (%s), no %s", dir, ManifestFile)
+		}
+		return nil, errors.Wrap(err, "reading manifest")
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, errors.Wrap(err, "parsing manifest")
+	}
+
+	s := &Snapshot{
+		Manifest: &manifest,
+		objects:  make(map[string][]byte),
+		lists:    make(map[string][]string),
+	}
+	for _, res := range resources {
+		objs, err := readObjects(filepath.Join(dir, string(res.kind)))
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %ss", res.kind)
+		}
+		prefix := "/" + string(res.kind)
+		s.lists[prefix] = make([]string, 0, len(objs))
+		for _, o := range objs {
+			cid := o.str("_cid")
+			if cid == "" {
+				return nil, errors.Errorf("reading %ss, no cid", res.kind)
+			}
+			data, err := json.Marshal(o)
+			if err != nil {
+				return nil, errors.Wrapf(err, "encoding %s", cid)
+			}
+			s.objects[cid] = data
+			s.lists[prefix] = append(s.lists[prefix], cid)
+		}
+	}
+
+	return s, nil
+}
+
+// OfflineAPI returns a read-only API serving calls from the export
+// directory, without credentials or network access - e.g. to run a
+// DriftReport in CI against the last export.
+func OfflineAPI(dir string) (*apiclient.API, error) {
+	s, err := OpenSnapshot(dir)
+	if err != nil {
+		return nil, err
+	}
+	return apiclient.New(&apiclient.Config{
+		TokenKey:  "offline",
+		URL:       offlineURL,
+		Transport: s,
+	})
+}
+
+// RoundTrip serves a request from the snapshot
+func (s *Snapshot) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	if req.Method != "GET" {
+		return snapshotResponse(req, http.StatusMethodNotAllowed, fmt.Sprintf("snapshot is read-only: %s %s", req.Method, req.URL.Path)), nil
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2")
+	if data, ok := s.objects[path]; ok {
+		return snapshotResponse(req, http.StatusOK, string(data)), nil
+	}
+	cids, ok := s.lists[path]
+	if !ok {
+		return snapshotResponse(req, http.StatusNotFound, fmt.Sprintf("not in snapshot: %s", path)), nil
+	}
+
+	q := req.URL.Query()
+	search := strings.ToLower(q.Get("search"))
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	n := 0
+	for _, cid := range cids {
+		data := s.objects[cid]
+		if search != "" && !strings.Contains(strings.ToLower(string(data)), search) {
+			continue
+		}
+		if !matchQueryFilters(data, q) {
+			continue
+		}
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(data)
+		n++
+	}
+	buf.WriteByte(']')
+	return snapshotResponse(req, http.StatusOK, buf.String()), nil
+}
+
+// matchQueryFilters reports whether an object matches the f_<field>=value
+// filters of a query
+func matchQueryFilters(data []byte, q map[string][]string) bool {
+	var o object
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&o); err != nil {
+		return false
+	}
+	for name, vals := range q {
+		if !strings.HasPrefix(name, "f_") {
+			continue
+		}
+		field := strings.TrimPrefix(name, "f_")
+		for _, val := range vals {
+			if fmt.Sprintf("%v", o[field]) != val {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// snapshotResponse returns a response to req, messages of errors are sent
+// in the API's error format
+func snapshotResponse(req *http.Request, status int, body string) *http.Response {
+	if status != http.StatusOK {
+		msg, _ := json.Marshal(body)
+		body = fmt.Sprintf(`{"code":"Snapshot","message":%s,"explanation":""}`, msg)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apisync
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+)
+
+func TestOfflineAPI(t *testing.T) {
+	store := testExportStore()
+	store["/graph/2"] = apiclient.Graph{CID: "/graph/2", Title: "latency"}
+	r, server := testReconciler(t, store, &Config{})
+
+	dir, err := ioutil.TempDir("", "offline")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := r.ExportAccount(context.Background(), dir, &ExportOptions{Format: FormatYAML}); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	server.Close() // offline from here
+
+	api, err := OfflineAPI(dir)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	graphs, err := api.FetchGraphs()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(*graphs) != 2 {
+		t.Fatalf("unexpected graphs (%v)", *graphs)
+	}
+	cid := "/graph/abc-123"
+	g, err := api.FetchGraph(apiclient.CIDType(&cid))
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if g.Title != "requests" {
+		t.Fatalf("unexpected graph (%#v)", g)
+	}
+	found, err := api.SearchGraphs(nil, &apiclient.SearchFilterType{"f_title": []string{"latency"}})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(*found) != 1 || (*found)[0].CID != "/graph/2" {
+		t.Fatalf("unexpected search (%v)", *found)
+	}
+	bundles, err := api.FetchCheckBundles()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(*bundles) != 1 || (*bundles)[0].Config["auth_password"] != RedactedValue {
+		t.Fatalf("unexpected check bundles (%v)", *bundles)
+	}
+
+	missing := "/graph/missing"
+	if _, err := api.FetchGraph(apiclient.CIDType(&missing)); err == nil || !strings.Contains(err.Error(), "not in snapshot") {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+	if _, err := api.CreateGraph(&apiclient.Graph{Title: "new"}); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+
+	// drift reports run against the snapshot
+	offline, err := New(&Config{API: api})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	report, err := offline.DriftReport(context.Background(), &State{Graphs: []apiclient.Graph{{Title: "requests"}, {Title: "latency", Description: "p99"}}})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	expected := `changed graph "latency" (/graph/2)
+    description: null => "p99"
+`
+	if report.String() != expected {
+		t.Fatalf("unexpected report\n%s", report.String())
+	}
+}
+
+func TestOpenSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "offline")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := OpenSnapshot(""); err == nil || err.Error() != "invalid snapshot directory (none)" {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+	if _, err := OpenSnapshot(dir); err == nil || !strings.Contains(err.Error(), "no manifest.json") {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apisync
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// State defines a desired set of objects. Only the fields set are
+// reconciled, fields which are not set (or are set to their zero value)
+// keep their live values, e.g. the defaults the API fills in.
+type State struct {
+	CheckBundles  []apiclient.CheckBundle  `json:"check_bundles,omitempty"`
+	ContactGroups []apiclient.ContactGroup `json:"contact_groups,omitempty"`
+	Dashboards    []apiclient.Dashboard    `json:"dashboards,omitempty"`
+	Graphs        []apiclient.Graph        `json:"graphs,omitempty"`
+	RuleSets      []apiclient.RuleSet      `json:"rule_sets,omitempty"`
+}
+
+// CheckBundleRefPrefix prefixes the references to check bundles, see
+// CheckBundleRef
+const CheckBundleRefPrefix = "check_bundle:"
+
+// CheckBundleRef returns the reference to the check of a check bundle (on its
+// first broker), by its type, target, and display name. A desired rule set
+// may set it as its check, e.g. when the check bundle is created by the same
+// plan, as the cid of its check is only known once it is created:
+//
+//	rule_sets:
+//	  - check: "check_bundle:http:web1.example.com:web1 http"
+//	    metric_name: duration
+func CheckBundleRef(b *apiclient.CheckBundle) string {
+	return CheckBundleRefPrefix + checkBundleKey(b.Type, b.Target, b.DisplayName)
+}
+
+// ParseState decodes a state from YAML (or JSON). Objects use the same field
+// names as the API, e.g.
+//
+//	graphs:
+//	  - title: requests
+//	    description: request rate
+//	    datapoints: [...]
+func ParseState(data []byte) (*State, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, errors.Wrap(err, "parsing state")
+	}
+
+	// the api objects only carry json tags, round trip through json
+	b, err := json.Marshal(jsonCompatible(raw))
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing state")
+	}
+
+	state := &State{}
+	if err := json.Unmarshal(b, state); err != nil {
+		return nil, errors.Wrap(err, "parsing state")
+	}
+
+	return state, nil
+}
+
+// LoadState reads a state from the passed YAML (or JSON) file.
+func LoadState(file string) (*State, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading state")
+	}
+	return ParseState(data)
+}
+
+// jsonCompatible converts the map[interface{}]interface{} values produced by
+// the yaml decoder to map[string]interface{}
+func jsonCompatible(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			m[fmt.Sprintf("%v", k)] = jsonCompatible(val)
+		}
+		return m
+	case []interface{}:
+		for i, val := range t {
+			t[i] = jsonCompatible(val)
+		}
+		return t
+	}
+	return v
+}
@@ -0,0 +1,353 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package apisync reconciles the check bundles, contact groups, graphs, rule
+// sets, and dashboards of an account with a desired state. A plan of the
+// changes needed to converge is built first, then applied.
+//
+//	state, _ := apisync.LoadState("monitoring.yaml")
+//	r, _ := apisync.New(&apisync.Config{API: apih, Tag: "managed:sync", Prune: true})
+//	plan, _ := r.Plan(ctx, state)
+//	fmt.Print(plan)
+//	_, err := r.Apply(ctx, plan)
+//
+// Desired objects are matched to live objects by CID when set, otherwise by
+// a natural key: check bundles by type, target, and display name; contact
+// groups by name; graphs and dashboards by title; rule sets by check and
+// metric name (or pattern). Rule sets may reference the check of a check
+// bundle by the check bundle, see CheckBundleRef.
+//
+// ExportAccount writes a normalized, diffable backup of an account to a
+// directory tree, ImportAccount restores it (to the same or another account).
+// DriftReport compares the live objects to their definitions without changes.
+// OfflineAPI serves an export as a read-only API, so reports can run without
+// credentials or network access.
+package apisync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/pkg/errors"
+)
+
+// Kind defines a type of object reconciled
+type Kind string
+
+// Kinds of objects reconciled
+const (
+	KindCheckBundle  = Kind("check_bundle")
+	KindContactGroup = Kind("contact_group")
+	KindDashboard    = Kind("dashboard")
+	KindGraph        = Kind("graph")
+	KindRuleSet      = Kind("rule_set")
+)
+
+// Action defines the change made to an object
+type Action string
+
+// Actions
+const (
+	ActionCreate = Action("create")
+	ActionUpdate = Action("update")
+	ActionDelete = Action("delete")
+)
+
+// Config defines the reconciler configuration
+type Config struct {
+	// API is used to retrieve and modify objects, required
+	API *apiclient.API
+
+	// Tag, if set, is added to every desired object which supports tags (all
+	// but dashboards), marking it as managed by the reconciler
+	Tag string
+
+	// Prune deletes live objects which are not in the desired state. Only
+	// objects carrying Tag are deleted, so Tag is required.
+	Prune bool
+}
+
+// Reconciler plans and applies the changes needed to converge an account
+// with a desired state
+type Reconciler struct {
+	api   *apiclient.API
+	tag   string
+	prune bool
+}
+
+// New returns a Reconciler for the passed config.
+func New(cfg *Config) (*Reconciler, error) {
+	if cfg == nil {
+		return nil, errors.New("invalid sync config (nil)")
+	}
+	if cfg.API == nil {
+		return nil, errors.New("invalid sync config, API (nil)")
+	}
+	if cfg.Prune && cfg.Tag == "" {
+		return nil, errors.New("invalid sync config, Tag required to Prune")
+	}
+
+	return &Reconciler{api: cfg.API, tag: cfg.Tag, prune: cfg.Prune}, nil
+}
+
+// Change defines a single change to an object
+type Change struct {
+	Action Action
+	Kind   Kind
+	Key    string      // natural key of the object
+	CID    string      // cid of the live object, set after apply for creates
+	Diffs  []FieldDiff // differences to the live object, updates only
+	object object
+}
+
+// String returns a one line summary of the change.
+func (c Change) String() string {
+	sym := map[Action]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-"}[c.Action]
+	if c.CID == "" {
+		return fmt.Sprintf("%s %s %q", sym, c.Kind, c.Key)
+	}
+	return fmt.Sprintf("%s %s %q (%s)", sym, c.Kind, c.Key, c.CID)
+}
+
+// Plan defines the changes needed to converge, in the order they are applied
+type Plan struct {
+	Changes []Change
+}
+
+// Empty reports whether the live objects already match the desired state.
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// String returns the changes, one per line, with the field differences of updates.
+func (p *Plan) String() string {
+	var buf bytes.Buffer
+	for _, c := range p.Changes {
+		fmt.Fprintln(&buf, c.String())
+		for _, d := range c.Diffs {
+			fmt.Fprintf(&buf, "    %s\n", d.String())
+		}
+	}
+	return buf.String()
+}
+
+// Plan compares the desired state to the live objects and returns the changes
+// needed to converge. Creates and updates are ordered so that referenced
+// objects come first (contact groups, check bundles, graphs, rule sets,
+// dashboards), deletes follow in reverse order.
+func (r *Reconciler) Plan(ctx context.Context, desired *State) (*Plan, error) {
+	if desired == nil {
+		return nil, errors.New("invalid sync state (nil)")
+	}
+
+	plan := &Plan{}
+	var deletes [][]Change
+	checks := make(map[string]string) // check bundle reference => check cid, none if created by the plan
+	for _, k := range kinds {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		upserts, dels, err := r.planKind(k, desired, checks)
+		if err != nil {
+			return nil, err
+		}
+		plan.Changes = append(plan.Changes, upserts...)
+		deletes = append(deletes, dels)
+	}
+	for i := len(deletes) - 1; i >= 0; i-- {
+		plan.Changes = append(plan.Changes, deletes[i]...)
+	}
+
+	return plan, nil
+}
+
+// planKind returns the creates/updates and deletes for a single kind. The
+// checks of the check bundles are added to checks, by reference (see
+// CheckBundleRef), and the references of rule sets resolved from them.
+func (r *Reconciler) planKind(k *kind, desired *State, checks map[string]string) ([]Change, []Change, error) {
+	want, err := toObjects(k.desired(desired))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "converting desired %ss", k.kind)
+	}
+	v, err := k.list(r.api)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "listing %ss", k.kind)
+	}
+	live, err := toObjects(v)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "converting live %ss", k.kind)
+	}
+
+	byCID := make(map[string]object, len(live))
+	byKey := make(map[string][]object, len(live))
+	for _, o := range live {
+		byCID[o.str("_cid")] = o
+		key := k.key(o)
+		byKey[key] = append(byKey[key], o)
+	}
+	if k.kind == KindCheckBundle {
+		for key, candidates := range byKey {
+			if len(candidates) == 1 {
+				checks[CheckBundleRefPrefix+key] = firstCheck(candidates[0])
+			}
+		}
+	}
+
+	var upserts []Change
+	matched := make(map[string]bool)
+	for i, o := range want {
+		if r.tag != "" && k.tagged {
+			o.addTag(r.tag)
+		}
+		if k.kind == KindRuleSet {
+			if ref := o.str("check"); strings.HasPrefix(ref, CheckBundleRefPrefix) {
+				check, ok := checks[ref]
+				if !ok {
+					return nil, nil, errors.Errorf("desired %s %d, check bundle %q not found", k.kind, i, strings.TrimPrefix(ref, CheckBundleRefPrefix))
+				}
+				if check != "" {
+					o["check"] = check
+				}
+			}
+		}
+
+		key := k.key(o)
+		var match object
+		if cid := o.str("_cid"); cid != "" {
+			lo, ok := byCID[cid]
+			if !ok {
+				return nil, nil, errors.Errorf("desired %s %s not found", k.kind, cid)
+			}
+			match = lo
+		} else {
+			if key == "" {
+				return nil, nil, errors.Errorf("desired %s %d, no cid or %s", k.kind, i, k.keyDesc)
+			}
+			switch candidates := byKey[key]; len(candidates) {
+			case 0:
+			case 1:
+				match = candidates[0]
+			default:
+				return nil, nil, errors.Errorf("desired %s %q matches %d live objects, set the cid", k.kind, key, len(candidates))
+			}
+		}
+
+		if match == nil {
+			if k.kind == KindCheckBundle {
+				// the check is known once the check bundle is created
+				checks[CheckBundleRefPrefix+key] = ""
+			}
+			upserts = append(upserts, Change{Action: ActionCreate, Kind: k.kind, Key: key, object: o})
+			continue
+		}
+
+		cid := match.str("_cid")
+		if matched[cid] {
+			return nil, nil, errors.Errorf("live %s %s matched by more than one desired object", k.kind, cid)
+		}
+		matched[cid] = true
+		if key == "" {
+			key = k.key(match)
+		}
+
+		if diffs := diffObjects(match, o); len(diffs) > 0 {
+			// the fields not set keep their live values
+			updated := object(merge(map[string]interface{}(match), map[string]interface{}(o)).(map[string]interface{}))
+			updated["_cid"] = cid
+			upserts = append(upserts, Change{Action: ActionUpdate, Kind: k.kind, Key: key, CID: cid, Diffs: diffs, object: updated})
+		}
+	}
+
+	var deletes []Change
+	if r.prune && k.tagged {
+		for _, o := range live {
+			cid := o.str("_cid")
+			if !matched[cid] && o.hasTag(r.tag) {
+				deletes = append(deletes, Change{Action: ActionDelete, Kind: k.kind, Key: k.key(o), CID: cid})
+			}
+		}
+	}
+
+	return upserts, deletes, nil
+}
+
+// Apply makes the changes in the passed plan, stopping at the first error.
+// Rule sets referencing check bundles created by the plan (see
+// CheckBundleRef) are set to the checks created. Returns the changes
+// applied, with the cid of created objects set.
+func (r *Reconciler) Apply(ctx context.Context, plan *Plan) ([]Change, error) {
+	if plan == nil {
+		return nil, errors.New("invalid sync plan (nil)")
+	}
+
+	applied := make([]Change, 0, len(plan.Changes))
+	checks := make(map[string]string) // check bundle reference => check cid, of those created
+	for _, c := range plan.Changes {
+		if err := ctx.Err(); err != nil {
+			return applied, err
+		}
+		k, ok := kindsByName[c.Kind]
+		if !ok {
+			return applied, errors.Errorf("invalid sync change kind (%s)", c.Kind)
+		}
+
+		if c.Kind == KindRuleSet && c.Action != ActionDelete {
+			if ref := c.object.str("check"); strings.HasPrefix(ref, CheckBundleRefPrefix) {
+				if checks[ref] == "" {
+					return applied, errors.Errorf("%s %s %q, check bundle %q not created", c.Action, c.Kind, c.Key, strings.TrimPrefix(ref, CheckBundleRefPrefix))
+				}
+				c.object["check"] = checks[ref]
+			}
+		}
+
+		var err error
+		switch c.Action {
+		case ActionCreate:
+			var check string
+			if c.CID, check, err = r.create(k, c.object); err == nil && c.Kind == KindCheckBundle {
+				checks[CheckBundleRefPrefix+c.Key] = check
+			}
+		case ActionUpdate:
+			err = k.update(r.api, c.object)
+		case ActionDelete:
+			err = k.delete(r.api, c.CID)
+		default:
+			err = errors.Errorf("invalid sync change action (%s)", c.Action)
+		}
+		if err != nil {
+			return applied, errors.Wrapf(err, "%s %s %q", c.Action, c.Kind, c.Key)
+		}
+		r.api.Debugf("sync, applied %s", c.String())
+		applied = append(applied, c)
+	}
+
+	return applied, nil
+}
+
+// create creates an object, returning its cid and the cid of its first check
+// (check bundles only)
+func (r *Reconciler) create(k *kind, o object) (string, string, error) {
+	v, err := k.create(r.api, o)
+	if err != nil {
+		return "", "", err
+	}
+	created, err := toObjects([]interface{}{v})
+	if err != nil {
+		return "", "", err
+	}
+	return created[0].str("_cid"), firstCheck(created[0]), nil
+}
+
+// firstCheck returns the cid of the first check of a check bundle
+func firstCheck(o object) string {
+	checks, _ := o["_checks"].([]interface{})
+	if len(checks) == 0 {
+		return ""
+	}
+	check, _ := checks[0].(string)
+	return check
+}
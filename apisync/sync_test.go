@@ -0,0 +1,377 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apisync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+)
+
+// testStoreServer serves the objects in store (keyed by cid) with list, fetch,
+// create, update, and delete support
+func testStoreServer(store map[string]interface{}) *httptest.Server {
+	var mu sync.Mutex
+	next := 1000
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		path := r.URL.Path
+		var ret interface{}
+		switch r.Method {
+		case "GET":
+			if obj, ok := store[path]; ok {
+				ret = obj
+				break
+			}
+			list := []interface{}{}
+			for cid, obj := range store {
+				if strings.HasPrefix(cid, path+"/") {
+					list = append(list, obj)
+				}
+			}
+			ret = list
+		case "POST", "PUT":
+			b, _ := ioutil.ReadAll(r.Body)
+			var obj map[string]interface{}
+			if err := json.Unmarshal(b, &obj); err != nil {
+				w.WriteHeader(400)
+				fmt.Fprintln(w, err)
+				return
+			}
+			if r.Method == "POST" {
+				next++
+				if path == "/check_bundle" {
+					obj["_checks"] = []string{fmt.Sprintf("/check/%d", next)}
+				}
+				path = fmt.Sprintf("%s/%d", path, next)
+				obj["_cid"] = path
+			} else if _, ok := store[path]; !ok {
+				w.WriteHeader(404)
+				fmt.Fprintln(w, "not found")
+				return
+			}
+			store[path] = obj
+			ret = obj
+		case "DELETE":
+			if _, ok := store[path]; !ok {
+				w.WriteHeader(404)
+				fmt.Fprintln(w, "not found")
+				return
+			}
+			delete(store, path)
+			ret = map[string]string{}
+		}
+		b, _ := json.Marshal(ret)
+		w.WriteHeader(200)
+		fmt.Fprintln(w, string(b))
+	}))
+}
+
+func testReconciler(t *testing.T, store map[string]interface{}, cfg *Config) (*Reconciler, *httptest.Server) {
+	server := testStoreServer(store)
+	apih, err := apiclient.New(&apiclient.Config{TokenKey: "abc123", URL: server.URL})
+	if err != nil {
+		server.Close()
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	cfg.API = apih
+	r, err := New(cfg)
+	if err != nil {
+		server.Close()
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	return r, server
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		id          string
+		cfg         *Config
+		expectedErr string
+	}{
+		{"invalid (nil)", nil, "invalid sync config (nil)"},
+		{"invalid (api)", &Config{}, "invalid sync config, API (nil)"},
+		{"invalid (prune)", &Config{API: &apiclient.API{}, Prune: true}, "invalid sync config, Tag required to Prune"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			_, err := New(test.cfg)
+			if err == nil {
+				t.Fatal("expected error")
+			} else if err.Error() != test.expectedErr {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+		})
+	}
+}
+
+func TestParseState(t *testing.T) {
+	data := []byte(`
+graphs:
+  - title: requests
+    description: line
+    tags: [service:web]
+rule_sets:
+  - check: /check/1234
+    metric_name: errors
+    metric_type: numeric
+    contact_groups:
+      1: [/contact_group/1]
+    rules:
+      - criteria: max value
+        severity: 1
+        value: "10"
+`)
+
+	state, err := ParseState(data)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(state.Graphs) != 1 || state.Graphs[0].Title != "requests" || state.Graphs[0].Description != "line" {
+		t.Fatalf("unexpected graphs (%#v)", state.Graphs)
+	}
+	if len(state.RuleSets) != 1 {
+		t.Fatalf("unexpected rule sets (%#v)", state.RuleSets)
+	}
+	rs := state.RuleSets[0]
+	if !reflect.DeepEqual(rs.ContactGroups, map[uint8][]string{1: {"/contact_group/1"}}) {
+		t.Fatalf("unexpected contact groups (%#v)", rs.ContactGroups)
+	}
+	if len(rs.Rules) != 1 || rs.Rules[0].Severity != 1 || rs.Rules[0].Value != "10" {
+		t.Fatalf("unexpected rules (%#v)", rs.Rules)
+	}
+
+	if _, err := ParseState([]byte("graphs: {")); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestPlanApply(t *testing.T) {
+	store := map[string]interface{}{
+		"/contact_group/1": apiclient.ContactGroup{CID: "/contact_group/1", Name: "ops", Tags: []string{"managed:sync"}},
+		"/graph/1":         apiclient.Graph{CID: "/graph/1", Title: "requests", Description: "line", Tags: []string{"managed:sync"}},
+		"/graph/2":         apiclient.Graph{CID: "/graph/2", Title: "old", Tags: []string{"managed:sync"}},
+		"/graph/3":         apiclient.Graph{CID: "/graph/3", Title: "manual"},
+	}
+	r, server := testReconciler(t, store, &Config{Tag: "managed:sync", Prune: true})
+	defer server.Close()
+
+	desired := &State{
+		ContactGroups: []apiclient.ContactGroup{{Name: "ops"}},
+		Graphs: []apiclient.Graph{
+			{Title: "requests", Description: "area"},
+			{Title: "latency", Description: "line"},
+		},
+	}
+
+	ctx := context.Background()
+	plan, err := r.Plan(ctx, desired)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	expected := `~ graph "requests" (/graph/1)
+    description: "line" => "area"
++ graph "latency"
+- graph "old" (/graph/2)
+`
+	if plan.String() != expected {
+		t.Fatalf("unexpected plan\n%s", plan.String())
+	}
+
+	applied, err := r.Apply(ctx, plan)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(applied) != 3 {
+		t.Fatalf("unexpected applied changes (%v)", applied)
+	}
+	if applied[1].CID == "" {
+		t.Fatalf("expected cid of created graph (%v)", applied[1])
+	}
+	if _, ok := store["/graph/2"]; ok {
+		t.Fatal("expected /graph/2 to be deleted")
+	}
+	if _, ok := store["/graph/3"]; !ok {
+		t.Fatal("expected unmanaged /graph/3 to be kept")
+	}
+
+	plan, err = r.Plan(ctx, desired)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if !plan.Empty() {
+		t.Fatalf("expected empty plan after apply\n%s", plan.String())
+	}
+}
+
+func TestPlanDefaults(t *testing.T) {
+	notes := "owned by web"
+	units := "milliseconds"
+	store := map[string]interface{}{
+		// as returned by the API, with the defaults it fills in
+		"/check_bundle/1": apiclient.CheckBundle{
+			CID:         "/check_bundle/1",
+			Checks:      []string{"/check/1"},
+			Brokers:     []string{"/broker/2", "/broker/1"},
+			Config:      apiclient.CheckBundleConfig{"url": "https://web1.example.com/health", "http_version": "1.1"},
+			DisplayName: "web1 http",
+			MetricLimit: -1,
+			Metrics: []apiclient.CheckBundleMetric{
+				{Name: "duration", Status: "active", Tags: []string{}, Type: "numeric", Units: &units},
+				{Name: "code", Status: "active", Tags: []string{}, Type: "text"},
+			},
+			Notes:   &notes,
+			Period:  60,
+			Status:  "active",
+			Tags:    []string{"service:web", "managed:sync"},
+			Target:  "web1.example.com",
+			Timeout: 10,
+			Type:    "http",
+		},
+	}
+	r, server := testReconciler(t, store, &Config{Tag: "managed:sync"})
+	defer server.Close()
+
+	desired := &State{
+		CheckBundles: []apiclient.CheckBundle{{
+			Brokers:     []string{"/broker/1", "/broker/2"},
+			Config:      apiclient.CheckBundleConfig{"url": "https://web1.example.com/health"},
+			DisplayName: "web1 http",
+			Metrics: []apiclient.CheckBundleMetric{
+				{Name: "code", Type: "text"},
+				{Name: "duration", Type: "numeric"},
+			},
+			Tags:   []string{"service:web"},
+			Target: "web1.example.com",
+			Type:   "http",
+		}},
+	}
+
+	ctx := context.Background()
+	plan, err := r.Plan(ctx, desired)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if !plan.Empty() {
+		t.Fatalf("expected empty plan\n%s", plan.String())
+	}
+
+	// the fields not set keep their live values
+	desired.CheckBundles[0].Metrics[1].Type = "histogram"
+	plan, err = r.Plan(ctx, desired)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	expected := `~ check_bundle "http:web1.example.com:web1 http" (/check_bundle/1)
+    metrics[duration].type: "numeric" => "histogram"
+`
+	if plan.String() != expected {
+		t.Fatalf("unexpected plan\n%s", plan.String())
+	}
+	if _, err := r.Apply(ctx, plan); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	var updated apiclient.CheckBundle
+	b, _ := json.Marshal(store["/check_bundle/1"])
+	if err := json.Unmarshal(b, &updated); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if updated.Notes == nil || *updated.Notes != notes || updated.Period != 60 || updated.Config["http_version"] != "1.1" {
+		t.Fatalf("unexpected update (%s)", b)
+	}
+	if m := updated.Metrics[1]; m.Name != "duration" || m.Type != "histogram" || m.Units == nil || *m.Units != units {
+		t.Fatalf("unexpected metric (%#v)", m)
+	}
+}
+
+func TestPlanCheckBundleRef(t *testing.T) {
+	store := map[string]interface{}{}
+	r, server := testReconciler(t, store, &Config{})
+	defer server.Close()
+
+	bundle := apiclient.CheckBundle{Type: "http", Target: "web1.example.com", DisplayName: "web1 http", Brokers: []string{"/broker/1"}}
+	desired := &State{
+		CheckBundles: []apiclient.CheckBundle{bundle},
+		RuleSets:     []apiclient.RuleSet{{CheckCID: CheckBundleRef(&bundle), MetricName: "duration", MetricType: "numeric"}},
+	}
+
+	ctx := context.Background()
+	plan, err := r.Plan(ctx, desired)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	expected := `+ check_bundle "http:web1.example.com:web1 http"
++ rule_set "check_bundle:http:web1.example.com:web1 http:duration"
+`
+	if plan.String() != expected {
+		t.Fatalf("unexpected plan\n%s", plan.String())
+	}
+
+	applied, err := r.Apply(ctx, plan)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	checks := store[applied[0].CID].(map[string]interface{})["_checks"].([]string)
+	if check := store[applied[1].CID].(map[string]interface{})["check"]; check != checks[0] {
+		t.Fatalf("unexpected rule set check (%v), expected %s", check, checks[0])
+	}
+
+	// the reference resolves to the live check bundle
+	plan, err = r.Plan(ctx, desired)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if !plan.Empty() {
+		t.Fatalf("expected empty plan after apply\n%s", plan.String())
+	}
+}
+
+func TestPlanErrors(t *testing.T) {
+	store := map[string]interface{}{
+		"/graph/1": apiclient.Graph{CID: "/graph/1", Title: "dup"},
+		"/graph/2": apiclient.Graph{CID: "/graph/2", Title: "dup"},
+	}
+	r, server := testReconciler(t, store, &Config{})
+	defer server.Close()
+
+	tests := []struct {
+		id          string
+		state       *State
+		expectedErr string
+	}{
+		{"invalid (nil)", nil, "invalid sync state (nil)"},
+		{"invalid (no key)", &State{Graphs: []apiclient.Graph{{Description: "line"}}}, "desired graph 0, no cid or title"},
+		{"invalid (ambiguous)", &State{Graphs: []apiclient.Graph{{Title: "dup"}}}, `desired graph "dup" matches 2 live objects, set the cid`},
+		{"invalid (cid)", &State{Graphs: []apiclient.Graph{{CID: "/graph/9"}}}, "desired graph /graph/9 not found"},
+		{"invalid (check bundle ref)", &State{RuleSets: []apiclient.RuleSet{{CheckCID: "check_bundle:http:web1:web1", MetricName: "duration"}}}, `desired rule_set 0, check bundle "http:web1:web1" not found`},
+		{"invalid (twice)", &State{Graphs: []apiclient.Graph{{CID: "/graph/1"}, {CID: "/graph/1"}}}, "live graph /graph/1 matched by more than one desired object"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			_, err := r.Plan(context.Background(), test.state)
+			if err == nil {
+				t.Fatal("expected error")
+			} else if err.Error() != test.expectedErr {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+		})
+	}
+}
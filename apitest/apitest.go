@@ -0,0 +1,62 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package apitest provides Mock, an implementation of the API interfaces
+// (apiclient.CirconusAPI, and each of the resource interfaces, e.g.
+// apiclient.GraphAPI), to test code using the API without a server. Code
+// accepting an interface rather than *apiclient.API can be given a Mock,
+// with funcs for the methods it is expected to call:
+//
+//	mock := &apitest.Mock{
+//		FetchGraphFunc: func(cid apiclient.CIDType) (*apiclient.Graph, error) {
+//			return &apiclient.Graph{CID: *cid, Title: "web"}, nil
+//		},
+//	}
+//	report(mock) // func report(api apiclient.GraphAPI)
+//	calls := mock.Calls()
+//
+// For tests exercising the requests made, see testutil/fakecirconus.
+package apitest
+
+//go:generate go run ../internal/genapi -dir ..
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ErrNotMocked is the cause of the errors returned by methods of a Mock
+// without a func
+var ErrNotMocked = errors.New("method not mocked")
+
+// notMocked returns the error of a method without a func
+func notMocked(method string) error {
+	return errors.WithMessage(ErrNotMocked, method)
+}
+
+// Call defines a call of a Mock method
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// record records a call
+func (m *Mock) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+	m.mu.Unlock()
+}
+
+// Calls returns the calls of the Mock methods, in order.
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call{}, m.calls...)
+}
+
+// Reset clears the calls recorded.
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	m.calls = nil
+	m.mu.Unlock()
+}
@@ -0,0 +1,3510 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Code generated by genapi. DO NOT EDIT.
+
+package apitest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/circonus-labs/go-apiclient"
+)
+
+// Mock implements apiclient.CirconusAPI, each method calling its func field
+// (e.g. FetchGraphFunc for FetchGraph), or returning zero values and
+// ErrNotMocked when it is nil. Calls are recorded, see Calls.
+type Mock struct {
+	mu    sync.Mutex
+	calls []Call
+
+	// RequestAPI
+
+	GetFunc               func(string) ([]byte, error)
+	DeleteFunc            func(string) ([]byte, error)
+	PostFunc              func(string, []byte) ([]byte, error)
+	PutFunc               func(string, []byte) ([]byte, error)
+	GetWithContextFunc    func(context.Context, string) ([]byte, error)
+	DeleteWithContextFunc func(context.Context, string) ([]byte, error)
+	PostWithContextFunc   func(context.Context, string, []byte) ([]byte, error)
+	PutWithContextFunc    func(context.Context, string, []byte) ([]byte, error)
+
+	// AccountAPI
+
+	FetchAccountFunc                     func(apiclient.CIDType) (*apiclient.Account, error)
+	FetchAccountWithContextFunc          func(context.Context, apiclient.CIDType) (*apiclient.Account, error)
+	FetchCurrentAccountFunc              func() (*apiclient.Account, error)
+	FetchCurrentAccountWithContextFunc   func(context.Context) (*apiclient.Account, error)
+	FetchAccountsFunc                    func() (*[]apiclient.Account, error)
+	FetchAccountsWithContextFunc         func(context.Context) (*[]apiclient.Account, error)
+	UpdateAccountFunc                    func(*apiclient.Account) (*apiclient.Account, error)
+	UpdateAccountWithContextFunc         func(context.Context, *apiclient.Account) (*apiclient.Account, error)
+	SearchAccountsFunc                   func(*apiclient.SearchFilterType) (*[]apiclient.Account, error)
+	SearchAccountsWithContextFunc        func(context.Context, *apiclient.SearchFilterType) (*[]apiclient.Account, error)
+	InviteUserFunc                       func(string, apiclient.AccountRole) (*apiclient.Account, error)
+	ListPendingInvitesFunc               func() (*[]apiclient.AccountInvite, error)
+	RevokeInviteFunc                     func(string) (bool, error)
+	ForEachAccountFunc                   func(func(acct *apiclient.Account, api *apiclient.API) error) error
+	UpdateAccountDefaultsFunc            func(apiclient.CIDType, apiclient.AccountDefaults) (*apiclient.Account, error)
+	UpdateAccountDefaultsWithContextFunc func(context.Context, apiclient.CIDType, apiclient.AccountDefaults) (*apiclient.Account, error)
+
+	// AcknowledgementAPI
+
+	FetchAcknowledgementFunc              func(apiclient.CIDType) (*apiclient.Acknowledgement, error)
+	FetchAcknowledgementWithContextFunc   func(context.Context, apiclient.CIDType) (*apiclient.Acknowledgement, error)
+	FetchAcknowledgementsFunc             func() (*[]apiclient.Acknowledgement, error)
+	FetchAcknowledgementsWithContextFunc  func(context.Context) (*[]apiclient.Acknowledgement, error)
+	UpdateAcknowledgementFunc             func(*apiclient.Acknowledgement) (*apiclient.Acknowledgement, error)
+	UpdateAcknowledgementWithContextFunc  func(context.Context, *apiclient.Acknowledgement) (*apiclient.Acknowledgement, error)
+	CreateAcknowledgementFunc             func(*apiclient.Acknowledgement) (*apiclient.Acknowledgement, error)
+	CreateAcknowledgementWithContextFunc  func(context.Context, *apiclient.Acknowledgement) (*apiclient.Acknowledgement, error)
+	SearchAcknowledgementsFunc            func(*apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Acknowledgement, error)
+	SearchAcknowledgementsWithContextFunc func(context.Context, *apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Acknowledgement, error)
+
+	// AlertAPI
+
+	FetchAlertFunc              func(apiclient.CIDType) (*apiclient.Alert, error)
+	FetchAlertWithContextFunc   func(context.Context, apiclient.CIDType) (*apiclient.Alert, error)
+	FetchAlertsFunc             func() (*[]apiclient.Alert, error)
+	FetchAlertsWithContextFunc  func(context.Context) (*[]apiclient.Alert, error)
+	SearchAlertsFunc            func(*apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Alert, error)
+	SearchAlertsWithContextFunc func(context.Context, *apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Alert, error)
+
+	// AnnotationAPI
+
+	FetchAnnotationFunc                  func(apiclient.CIDType) (*apiclient.Annotation, error)
+	FetchAnnotationWithContextFunc       func(context.Context, apiclient.CIDType) (*apiclient.Annotation, error)
+	FetchAnnotationsFunc                 func() (*[]apiclient.Annotation, error)
+	FetchAnnotationsWithContextFunc      func(context.Context) (*[]apiclient.Annotation, error)
+	UpdateAnnotationFunc                 func(*apiclient.Annotation) (*apiclient.Annotation, error)
+	UpdateAnnotationWithContextFunc      func(context.Context, *apiclient.Annotation) (*apiclient.Annotation, error)
+	CreateAnnotationFunc                 func(*apiclient.Annotation) (*apiclient.Annotation, error)
+	CreateAnnotationWithContextFunc      func(context.Context, *apiclient.Annotation) (*apiclient.Annotation, error)
+	DeleteAnnotationFunc                 func(*apiclient.Annotation) (bool, error)
+	DeleteAnnotationWithContextFunc      func(context.Context, *apiclient.Annotation) (bool, error)
+	DeleteAnnotationByCIDFunc            func(apiclient.CIDType) (bool, error)
+	DeleteAnnotationByCIDWithContextFunc func(context.Context, apiclient.CIDType) (bool, error)
+	SearchAnnotationsFunc                func(*apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Annotation, error)
+	SearchAnnotationsWithContextFunc     func(context.Context, *apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Annotation, error)
+
+	// BrokerAPI
+
+	FetchBrokerFunc              func(apiclient.CIDType) (*apiclient.Broker, error)
+	FetchBrokerWithContextFunc   func(context.Context, apiclient.CIDType) (*apiclient.Broker, error)
+	FetchBrokersFunc             func() (*[]apiclient.Broker, error)
+	FetchBrokersWithContextFunc  func(context.Context) (*[]apiclient.Broker, error)
+	SearchBrokersFunc            func(*apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Broker, error)
+	SearchBrokersWithContextFunc func(context.Context, *apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Broker, error)
+
+	// CheckAPI
+
+	FetchCheckFunc              func(apiclient.CIDType) (*apiclient.Check, error)
+	FetchCheckWithContextFunc   func(context.Context, apiclient.CIDType) (*apiclient.Check, error)
+	FetchChecksFunc             func() (*[]apiclient.Check, error)
+	FetchChecksWithContextFunc  func(context.Context) (*[]apiclient.Check, error)
+	SearchChecksFunc            func(*apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Check, error)
+	SearchChecksWithContextFunc func(context.Context, *apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Check, error)
+
+	// CheckBundleAPI
+
+	FetchCheckBundleFunc                  func(apiclient.CIDType) (*apiclient.CheckBundle, error)
+	FetchCheckBundleWithContextFunc       func(context.Context, apiclient.CIDType) (*apiclient.CheckBundle, error)
+	FetchCheckBundlesFunc                 func() (*[]apiclient.CheckBundle, error)
+	FetchCheckBundlesWithContextFunc      func(context.Context) (*[]apiclient.CheckBundle, error)
+	UpdateCheckBundleFunc                 func(*apiclient.CheckBundle) (*apiclient.CheckBundle, error)
+	UpdateCheckBundleWithContextFunc      func(context.Context, *apiclient.CheckBundle) (*apiclient.CheckBundle, error)
+	CreateCheckBundleFunc                 func(*apiclient.CheckBundle) (*apiclient.CheckBundle, error)
+	CreateCheckBundleWithContextFunc      func(context.Context, *apiclient.CheckBundle) (*apiclient.CheckBundle, error)
+	DeleteCheckBundleFunc                 func(*apiclient.CheckBundle) (bool, error)
+	DeleteCheckBundleWithContextFunc      func(context.Context, *apiclient.CheckBundle) (bool, error)
+	DeleteCheckBundleByCIDFunc            func(apiclient.CIDType) (bool, error)
+	DeleteCheckBundleByCIDWithContextFunc func(context.Context, apiclient.CIDType) (bool, error)
+	SearchCheckBundlesFunc                func(*apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.CheckBundle, error)
+	SearchCheckBundlesWithContextFunc     func(context.Context, *apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.CheckBundle, error)
+	UpsertCheckBundleFunc                 func(*apiclient.CheckBundle) (*apiclient.CheckBundle, []apiclient.AuditChange, error)
+	UpsertCheckBundleWithContextFunc      func(context.Context, *apiclient.CheckBundle) (*apiclient.CheckBundle, []apiclient.AuditChange, error)
+
+	// CheckBundleMetricsAPI
+
+	FetchCheckBundleMetricsFunc             func(apiclient.CIDType) (*apiclient.CheckBundleMetrics, error)
+	FetchCheckBundleMetricsWithContextFunc  func(context.Context, apiclient.CIDType) (*apiclient.CheckBundleMetrics, error)
+	UpdateCheckBundleMetricsFunc            func(*apiclient.CheckBundleMetrics) (*apiclient.CheckBundleMetrics, error)
+	UpdateCheckBundleMetricsWithContextFunc func(context.Context, *apiclient.CheckBundleMetrics) (*apiclient.CheckBundleMetrics, error)
+
+	// CheckMoveAPI
+
+	FetchCheckMoveFunc                  func(apiclient.CIDType) (*apiclient.CheckMove, error)
+	FetchCheckMoveWithContextFunc       func(context.Context, apiclient.CIDType) (*apiclient.CheckMove, error)
+	FetchCheckMovesFunc                 func() (*[]apiclient.CheckMove, error)
+	FetchCheckMovesWithContextFunc      func(context.Context) (*[]apiclient.CheckMove, error)
+	CreateCheckMoveFunc                 func(*apiclient.CheckMove) (*apiclient.CheckMove, error)
+	CreateCheckMoveWithContextFunc      func(context.Context, *apiclient.CheckMove) (*apiclient.CheckMove, error)
+	DeleteCheckMoveFunc                 func(*apiclient.CheckMove) (bool, error)
+	DeleteCheckMoveWithContextFunc      func(context.Context, *apiclient.CheckMove) (bool, error)
+	DeleteCheckMoveByCIDFunc            func(apiclient.CIDType) (bool, error)
+	DeleteCheckMoveByCIDWithContextFunc func(context.Context, apiclient.CIDType) (bool, error)
+	SearchCheckMovesFunc                func(*apiclient.SearchFilterType) (*[]apiclient.CheckMove, error)
+	SearchCheckMovesWithContextFunc     func(context.Context, *apiclient.SearchFilterType) (*[]apiclient.CheckMove, error)
+	MoveCheckFunc                       func(apiclient.CIDType, apiclient.CIDType) (*apiclient.CheckMove, error)
+	MoveCheckAndWaitFunc                func(apiclient.CIDType, apiclient.CIDType, time.Duration) (*apiclient.CheckMove, error)
+
+	// CheckTemplateAPI
+
+	FetchCheckTemplateFunc                  func(apiclient.CIDType) (*apiclient.CheckTemplate, error)
+	FetchCheckTemplateWithContextFunc       func(context.Context, apiclient.CIDType) (*apiclient.CheckTemplate, error)
+	FetchCheckTemplatesFunc                 func() (*[]apiclient.CheckTemplate, error)
+	FetchCheckTemplatesWithContextFunc      func(context.Context) (*[]apiclient.CheckTemplate, error)
+	UpdateCheckTemplateFunc                 func(*apiclient.CheckTemplate) (*apiclient.CheckTemplate, error)
+	UpdateCheckTemplateWithContextFunc      func(context.Context, *apiclient.CheckTemplate) (*apiclient.CheckTemplate, error)
+	CreateCheckTemplateFunc                 func(*apiclient.CheckTemplate) (*apiclient.CheckTemplate, error)
+	CreateCheckTemplateWithContextFunc      func(context.Context, *apiclient.CheckTemplate) (*apiclient.CheckTemplate, error)
+	DeleteCheckTemplateFunc                 func(*apiclient.CheckTemplate) (bool, error)
+	DeleteCheckTemplateWithContextFunc      func(context.Context, *apiclient.CheckTemplate) (bool, error)
+	DeleteCheckTemplateByCIDFunc            func(apiclient.CIDType) (bool, error)
+	DeleteCheckTemplateByCIDWithContextFunc func(context.Context, apiclient.CIDType) (bool, error)
+	SearchCheckTemplatesFunc                func(*apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.CheckTemplate, error)
+	SearchCheckTemplatesWithContextFunc     func(context.Context, *apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.CheckTemplate, error)
+
+	// ContactGroupAPI
+
+	FetchContactGroupFunc                  func(apiclient.CIDType) (*apiclient.ContactGroup, error)
+	FetchContactGroupWithContextFunc       func(context.Context, apiclient.CIDType) (*apiclient.ContactGroup, error)
+	FetchContactGroupsFunc                 func() (*[]apiclient.ContactGroup, error)
+	FetchContactGroupsWithContextFunc      func(context.Context) (*[]apiclient.ContactGroup, error)
+	UpdateContactGroupFunc                 func(*apiclient.ContactGroup) (*apiclient.ContactGroup, error)
+	UpdateContactGroupWithContextFunc      func(context.Context, *apiclient.ContactGroup) (*apiclient.ContactGroup, error)
+	CreateContactGroupFunc                 func(*apiclient.ContactGroup) (*apiclient.ContactGroup, error)
+	CreateContactGroupWithContextFunc      func(context.Context, *apiclient.ContactGroup) (*apiclient.ContactGroup, error)
+	DeleteContactGroupFunc                 func(*apiclient.ContactGroup) (bool, error)
+	DeleteContactGroupWithContextFunc      func(context.Context, *apiclient.ContactGroup) (bool, error)
+	DeleteContactGroupByCIDFunc            func(apiclient.CIDType) (bool, error)
+	DeleteContactGroupByCIDWithContextFunc func(context.Context, apiclient.CIDType) (bool, error)
+	SearchContactGroupsFunc                func(*apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.ContactGroup, error)
+	SearchContactGroupsWithContextFunc     func(context.Context, *apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.ContactGroup, error)
+
+	// DashboardAPI
+
+	FetchDashboardFunc                  func(apiclient.CIDType) (*apiclient.Dashboard, error)
+	FetchDashboardWithContextFunc       func(context.Context, apiclient.CIDType) (*apiclient.Dashboard, error)
+	FetchDashboardsFunc                 func() (*[]apiclient.Dashboard, error)
+	FetchDashboardsWithContextFunc      func(context.Context) (*[]apiclient.Dashboard, error)
+	UpdateDashboardFunc                 func(*apiclient.Dashboard) (*apiclient.Dashboard, error)
+	UpdateDashboardWithContextFunc      func(context.Context, *apiclient.Dashboard) (*apiclient.Dashboard, error)
+	CreateDashboardFunc                 func(*apiclient.Dashboard) (*apiclient.Dashboard, error)
+	CreateDashboardWithContextFunc      func(context.Context, *apiclient.Dashboard) (*apiclient.Dashboard, error)
+	DeleteDashboardFunc                 func(*apiclient.Dashboard) (bool, error)
+	DeleteDashboardWithContextFunc      func(context.Context, *apiclient.Dashboard) (bool, error)
+	DeleteDashboardByCIDFunc            func(apiclient.CIDType) (bool, error)
+	DeleteDashboardByCIDWithContextFunc func(context.Context, apiclient.CIDType) (bool, error)
+	SearchDashboardsFunc                func(*apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Dashboard, error)
+	SearchDashboardsWithContextFunc     func(context.Context, *apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Dashboard, error)
+
+	// DataAPI
+
+	FetchDataFunc                                func(apiclient.CIDType, string, time.Time, time.Time, time.Duration) (*apiclient.Data, error)
+	FetchDataWithContextFunc                     func(context.Context, apiclient.CIDType, string, time.Time, time.Time, time.Duration) (*apiclient.Data, error)
+	FetchDataWithOptionsFunc                     func(apiclient.CIDType, string, *apiclient.DataOptions) (*apiclient.Data, error)
+	FetchDataWithOptionsWithContextFunc          func(context.Context, apiclient.CIDType, string, *apiclient.DataOptions) (*apiclient.Data, error)
+	FetchHistogramDataFunc                       func(apiclient.CIDType, string, time.Time, time.Time, time.Duration) (*apiclient.HistogramData, error)
+	FetchHistogramDataWithContextFunc            func(context.Context, apiclient.CIDType, string, time.Time, time.Time, time.Duration) (*apiclient.HistogramData, error)
+	FetchHistogramDataWithOptionsFunc            func(apiclient.CIDType, string, *apiclient.DataOptions) (*apiclient.HistogramData, error)
+	FetchHistogramDataWithOptionsWithContextFunc func(context.Context, apiclient.CIDType, string, *apiclient.DataOptions) (*apiclient.HistogramData, error)
+
+	// GraphAPI
+
+	FetchGraphFunc                  func(apiclient.CIDType) (*apiclient.Graph, error)
+	FetchGraphWithContextFunc       func(context.Context, apiclient.CIDType) (*apiclient.Graph, error)
+	FetchGraphsFunc                 func() (*[]apiclient.Graph, error)
+	FetchGraphsWithContextFunc      func(context.Context) (*[]apiclient.Graph, error)
+	UpdateGraphFunc                 func(*apiclient.Graph) (*apiclient.Graph, error)
+	UpdateGraphWithContextFunc      func(context.Context, *apiclient.Graph) (*apiclient.Graph, error)
+	CreateGraphFunc                 func(*apiclient.Graph) (*apiclient.Graph, error)
+	CreateGraphWithContextFunc      func(context.Context, *apiclient.Graph) (*apiclient.Graph, error)
+	DeleteGraphFunc                 func(*apiclient.Graph) (bool, error)
+	DeleteGraphWithContextFunc      func(context.Context, *apiclient.Graph) (bool, error)
+	DeleteGraphByCIDFunc            func(apiclient.CIDType) (bool, error)
+	DeleteGraphByCIDWithContextFunc func(context.Context, apiclient.CIDType) (bool, error)
+	SearchGraphsFunc                func(*apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Graph, error)
+	SearchGraphsWithContextFunc     func(context.Context, *apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Graph, error)
+
+	// MaintenanceAPI
+
+	FetchMaintenanceWindowFunc                  func(apiclient.CIDType) (*apiclient.Maintenance, error)
+	FetchMaintenanceWindowWithContextFunc       func(context.Context, apiclient.CIDType) (*apiclient.Maintenance, error)
+	FetchMaintenanceWindowsFunc                 func() (*[]apiclient.Maintenance, error)
+	FetchMaintenanceWindowsWithContextFunc      func(context.Context) (*[]apiclient.Maintenance, error)
+	UpdateMaintenanceWindowFunc                 func(*apiclient.Maintenance) (*apiclient.Maintenance, error)
+	UpdateMaintenanceWindowWithContextFunc      func(context.Context, *apiclient.Maintenance) (*apiclient.Maintenance, error)
+	CreateMaintenanceWindowFunc                 func(*apiclient.Maintenance) (*apiclient.Maintenance, error)
+	CreateMaintenanceWindowWithContextFunc      func(context.Context, *apiclient.Maintenance) (*apiclient.Maintenance, error)
+	DeleteMaintenanceWindowFunc                 func(*apiclient.Maintenance) (bool, error)
+	DeleteMaintenanceWindowWithContextFunc      func(context.Context, *apiclient.Maintenance) (bool, error)
+	DeleteMaintenanceWindowByCIDFunc            func(apiclient.CIDType) (bool, error)
+	DeleteMaintenanceWindowByCIDWithContextFunc func(context.Context, apiclient.CIDType) (bool, error)
+	SearchMaintenanceWindowsFunc                func(*apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Maintenance, error)
+	SearchMaintenanceWindowsWithContextFunc     func(context.Context, *apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Maintenance, error)
+
+	// MetricAPI
+
+	FetchMetricFunc              func(apiclient.CIDType) (*apiclient.Metric, error)
+	FetchMetricWithContextFunc   func(context.Context, apiclient.CIDType) (*apiclient.Metric, error)
+	FetchMetricsFunc             func() (*[]apiclient.Metric, error)
+	FetchMetricsWithContextFunc  func(context.Context) (*[]apiclient.Metric, error)
+	UpdateMetricFunc             func(*apiclient.Metric) (*apiclient.Metric, error)
+	UpdateMetricWithContextFunc  func(context.Context, *apiclient.Metric) (*apiclient.Metric, error)
+	SearchMetricsFunc            func(*apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Metric, error)
+	SearchMetricsWithContextFunc func(context.Context, *apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Metric, error)
+
+	// MetricClusterAPI
+
+	FetchMetricClusterFunc                  func(apiclient.CIDType, string) (*apiclient.MetricCluster, error)
+	FetchMetricClusterWithContextFunc       func(context.Context, apiclient.CIDType, string) (*apiclient.MetricCluster, error)
+	FetchMetricClustersFunc                 func(string) (*[]apiclient.MetricCluster, error)
+	FetchMetricClustersWithContextFunc      func(context.Context, string) (*[]apiclient.MetricCluster, error)
+	UpdateMetricClusterFunc                 func(*apiclient.MetricCluster) (*apiclient.MetricCluster, error)
+	UpdateMetricClusterWithContextFunc      func(context.Context, *apiclient.MetricCluster) (*apiclient.MetricCluster, error)
+	CreateMetricClusterFunc                 func(*apiclient.MetricCluster) (*apiclient.MetricCluster, error)
+	CreateMetricClusterWithContextFunc      func(context.Context, *apiclient.MetricCluster) (*apiclient.MetricCluster, error)
+	DeleteMetricClusterFunc                 func(*apiclient.MetricCluster) (bool, error)
+	DeleteMetricClusterWithContextFunc      func(context.Context, *apiclient.MetricCluster) (bool, error)
+	DeleteMetricClusterByCIDFunc            func(apiclient.CIDType) (bool, error)
+	DeleteMetricClusterByCIDWithContextFunc func(context.Context, apiclient.CIDType) (bool, error)
+	SearchMetricClustersFunc                func(*apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.MetricCluster, error)
+	SearchMetricClustersWithContextFunc     func(context.Context, *apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.MetricCluster, error)
+
+	// OutlierReportAPI
+
+	FetchOutlierReportFunc                      func(apiclient.CIDType) (*apiclient.OutlierReport, error)
+	FetchOutlierReportWithContextFunc           func(context.Context, apiclient.CIDType) (*apiclient.OutlierReport, error)
+	FetchOutlierReportsFunc                     func() (*[]apiclient.OutlierReport, error)
+	FetchOutlierReportsWithContextFunc          func(context.Context) (*[]apiclient.OutlierReport, error)
+	UpdateOutlierReportFunc                     func(*apiclient.OutlierReport) (*apiclient.OutlierReport, error)
+	UpdateOutlierReportWithContextFunc          func(context.Context, *apiclient.OutlierReport) (*apiclient.OutlierReport, error)
+	CreateOutlierReportFunc                     func(*apiclient.OutlierReport) (*apiclient.OutlierReport, error)
+	CreateOutlierReportWithContextFunc          func(context.Context, *apiclient.OutlierReport) (*apiclient.OutlierReport, error)
+	DeleteOutlierReportFunc                     func(*apiclient.OutlierReport) (bool, error)
+	DeleteOutlierReportWithContextFunc          func(context.Context, *apiclient.OutlierReport) (bool, error)
+	DeleteOutlierReportByCIDFunc                func(apiclient.CIDType) (bool, error)
+	DeleteOutlierReportByCIDWithContextFunc     func(context.Context, apiclient.CIDType) (bool, error)
+	SearchOutlierReportsFunc                    func(*apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.OutlierReport, error)
+	SearchOutlierReportsWithContextFunc         func(context.Context, *apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.OutlierReport, error)
+	SearchOutlierReportsByWindowFunc            func(*apiclient.OutlierReportWindowFilter) (*[]apiclient.OutlierReport, error)
+	SearchOutlierReportsByWindowWithContextFunc func(context.Context, *apiclient.OutlierReportWindowFilter) (*[]apiclient.OutlierReport, error)
+	PurgeOutlierReportsFunc                     func(*apiclient.OutlierReportRetention) (*[]apiclient.OutlierReport, error)
+
+	// ProvisionBrokerAPI
+
+	FetchProvisionBrokerFunc             func(apiclient.CIDType) (*apiclient.ProvisionBroker, error)
+	FetchProvisionBrokerWithContextFunc  func(context.Context, apiclient.CIDType) (*apiclient.ProvisionBroker, error)
+	UpdateProvisionBrokerFunc            func(apiclient.CIDType, *apiclient.ProvisionBroker) (*apiclient.ProvisionBroker, error)
+	UpdateProvisionBrokerWithContextFunc func(context.Context, apiclient.CIDType, *apiclient.ProvisionBroker) (*apiclient.ProvisionBroker, error)
+	CreateProvisionBrokerFunc            func(*apiclient.ProvisionBroker) (*apiclient.ProvisionBroker, error)
+	CreateProvisionBrokerWithContextFunc func(context.Context, *apiclient.ProvisionBroker) (*apiclient.ProvisionBroker, error)
+	RotateProvisionBrokerCertFunc        func(apiclient.CIDType, string) (*apiclient.ProvisionBrokerCert, error)
+	ProvisionBrokerAndWaitFunc           func(*apiclient.ProvisionBroker, time.Duration) (*apiclient.Broker, error)
+
+	// RuleSetAPI
+
+	FetchRuleSetFunc                  func(apiclient.CIDType) (*apiclient.RuleSet, error)
+	FetchRuleSetWithContextFunc       func(context.Context, apiclient.CIDType) (*apiclient.RuleSet, error)
+	FetchRuleSetsFunc                 func() (*[]apiclient.RuleSet, error)
+	FetchRuleSetsWithContextFunc      func(context.Context) (*[]apiclient.RuleSet, error)
+	UpdateRuleSetFunc                 func(*apiclient.RuleSet) (*apiclient.RuleSet, error)
+	UpdateRuleSetWithContextFunc      func(context.Context, *apiclient.RuleSet) (*apiclient.RuleSet, error)
+	CreateRuleSetFunc                 func(*apiclient.RuleSet) (*apiclient.RuleSet, error)
+	CreateRuleSetWithContextFunc      func(context.Context, *apiclient.RuleSet) (*apiclient.RuleSet, error)
+	DeleteRuleSetFunc                 func(*apiclient.RuleSet) (bool, error)
+	DeleteRuleSetWithContextFunc      func(context.Context, *apiclient.RuleSet) (bool, error)
+	DeleteRuleSetByCIDFunc            func(apiclient.CIDType) (bool, error)
+	DeleteRuleSetByCIDWithContextFunc func(context.Context, apiclient.CIDType) (bool, error)
+	SearchRuleSetsFunc                func(*apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.RuleSet, error)
+	SearchRuleSetsWithContextFunc     func(context.Context, *apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.RuleSet, error)
+	ValidateRuleSetFunc               func(*apiclient.RuleSet) error
+	CloneRuleSetFunc                  func(apiclient.CIDType, string, string) (*apiclient.RuleSet, error)
+	RuleSetsNotifyingFunc             func(apiclient.CIDType) (*[]apiclient.RuleSet, error)
+	RuleSetsWithSeverityFunc          func(uint) (*[]apiclient.RuleSet, error)
+	MuteRuleSetFunc                   func(apiclient.CIDType) (*apiclient.RuleSetMute, error)
+	UnmuteRuleSetFunc                 func(*apiclient.RuleSetMute) (*apiclient.RuleSet, error)
+
+	// RuleSetGroupAPI
+
+	FetchRuleSetGroupFunc                  func(apiclient.CIDType) (*apiclient.RuleSetGroup, error)
+	FetchRuleSetGroupWithContextFunc       func(context.Context, apiclient.CIDType) (*apiclient.RuleSetGroup, error)
+	FetchRuleSetGroupsFunc                 func() (*[]apiclient.RuleSetGroup, error)
+	FetchRuleSetGroupsWithContextFunc      func(context.Context) (*[]apiclient.RuleSetGroup, error)
+	UpdateRuleSetGroupFunc                 func(*apiclient.RuleSetGroup) (*apiclient.RuleSetGroup, error)
+	UpdateRuleSetGroupWithContextFunc      func(context.Context, *apiclient.RuleSetGroup) (*apiclient.RuleSetGroup, error)
+	CreateRuleSetGroupFunc                 func(*apiclient.RuleSetGroup) (*apiclient.RuleSetGroup, error)
+	CreateRuleSetGroupWithContextFunc      func(context.Context, *apiclient.RuleSetGroup) (*apiclient.RuleSetGroup, error)
+	DeleteRuleSetGroupFunc                 func(*apiclient.RuleSetGroup) (bool, error)
+	DeleteRuleSetGroupWithContextFunc      func(context.Context, *apiclient.RuleSetGroup) (bool, error)
+	DeleteRuleSetGroupByCIDFunc            func(apiclient.CIDType) (bool, error)
+	DeleteRuleSetGroupByCIDWithContextFunc func(context.Context, apiclient.CIDType) (bool, error)
+	SearchRuleSetGroupsFunc                func(*apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.RuleSetGroup, error)
+	SearchRuleSetGroupsWithContextFunc     func(context.Context, *apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.RuleSetGroup, error)
+	ValidateRuleSetGroupFunc               func(*apiclient.RuleSetGroup) error
+	CloneRuleSetGroupFunc                  func(apiclient.CIDType, map[string]string) (*apiclient.RuleSetGroup, error)
+
+	// TagAPI
+
+	FetchTagFunc                      func(apiclient.CIDType) (*apiclient.Tag, error)
+	FetchTagWithContextFunc           func(context.Context, apiclient.CIDType) (*apiclient.Tag, error)
+	FetchTagsFunc                     func() (*[]apiclient.Tag, error)
+	FetchTagsWithContextFunc          func(context.Context) (*[]apiclient.Tag, error)
+	SearchTagsFunc                    func(*apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Tag, error)
+	SearchTagsWithContextFunc         func(context.Context, *apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Tag, error)
+	FetchTagCategoriesFunc            func() (map[string][]string, error)
+	FetchTagCategoriesWithContextFunc func(context.Context) (map[string][]string, error)
+
+	// UserAPI
+
+	FetchUserFunc                      func(apiclient.CIDType) (*apiclient.User, error)
+	FetchUserWithContextFunc           func(context.Context, apiclient.CIDType) (*apiclient.User, error)
+	FetchUsersFunc                     func() (*[]apiclient.User, error)
+	FetchUsersWithContextFunc          func(context.Context) (*[]apiclient.User, error)
+	UpdateUserFunc                     func(*apiclient.User) (*apiclient.User, error)
+	UpdateUserWithContextFunc          func(context.Context, *apiclient.User) (*apiclient.User, error)
+	SearchUsersFunc                    func(*apiclient.SearchFilterType) (*[]apiclient.User, error)
+	SearchUsersWithContextFunc         func(context.Context, *apiclient.SearchFilterType) (*[]apiclient.User, error)
+	FetchCurrentUserFunc               func() (*apiclient.User, error)
+	FetchCurrentUserWithContextFunc    func(context.Context) (*apiclient.User, error)
+	WhoAmIFunc                         func() (*apiclient.WhoAmI, error)
+	SetUserContactInfoFunc             func(apiclient.CIDType, string, string) (*apiclient.User, error)
+	SearchUsersByEmailFunc             func(string) (*[]apiclient.User, error)
+	SearchUsersByEmailWithContextFunc  func(context.Context, string) (*[]apiclient.User, error)
+	FetchUsersInAccountFunc            func(string) (*[]apiclient.User, error)
+	FetchUsersInAccountWithContextFunc func(context.Context, string) (*[]apiclient.User, error)
+	FetchUserRolesFunc                 func(apiclient.CIDType) (*apiclient.User, error)
+	FetchUserRolesWithContextFunc      func(context.Context, apiclient.CIDType) (*apiclient.User, error)
+
+	// WorksheetAPI
+
+	FetchWorksheetFunc                            func(apiclient.CIDType) (*apiclient.Worksheet, error)
+	FetchWorksheetWithContextFunc                 func(context.Context, apiclient.CIDType) (*apiclient.Worksheet, error)
+	FetchWorksheetsFunc                           func() (*[]apiclient.Worksheet, error)
+	FetchWorksheetsWithContextFunc                func(context.Context) (*[]apiclient.Worksheet, error)
+	UpdateWorksheetFunc                           func(*apiclient.Worksheet) (*apiclient.Worksheet, error)
+	UpdateWorksheetWithContextFunc                func(context.Context, *apiclient.Worksheet) (*apiclient.Worksheet, error)
+	CreateWorksheetFunc                           func(*apiclient.Worksheet) (*apiclient.Worksheet, error)
+	CreateWorksheetWithContextFunc                func(context.Context, *apiclient.Worksheet) (*apiclient.Worksheet, error)
+	DeleteWorksheetFunc                           func(*apiclient.Worksheet) (bool, error)
+	DeleteWorksheetWithContextFunc                func(context.Context, *apiclient.Worksheet) (bool, error)
+	DeleteWorksheetByCIDFunc                      func(apiclient.CIDType) (bool, error)
+	DeleteWorksheetByCIDWithContextFunc           func(context.Context, apiclient.CIDType) (bool, error)
+	SearchWorksheetsFunc                          func(*apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Worksheet, error)
+	SearchWorksheetsWithContextFunc               func(context.Context, *apiclient.SearchQueryType, *apiclient.SearchFilterType) (*[]apiclient.Worksheet, error)
+	FetchWorksheetSmartQueryGraphsFunc            func(*apiclient.WorksheetSmartQuery) (*[]apiclient.Graph, error)
+	FetchWorksheetSmartQueryGraphsWithContextFunc func(context.Context, *apiclient.WorksheetSmartQuery) (*[]apiclient.Graph, error)
+	AddGraphToWorksheetFunc                       func(apiclient.CIDType, apiclient.CIDType) (*apiclient.Worksheet, error)
+	RemoveGraphFromWorksheetFunc                  func(apiclient.CIDType, apiclient.CIDType) (*apiclient.Worksheet, error)
+	CloneWorksheetFunc                            func(apiclient.CIDType, map[string]string) (*apiclient.Worksheet, error)
+	SetWorksheetFavoriteFunc                      func(apiclient.CIDType, bool) (*apiclient.Worksheet, error)
+	SetWorksheetDescriptionFunc                   func(apiclient.CIDType, string) (*apiclient.Worksheet, error)
+	FetchFavoriteWorksheetsFunc                   func() (*[]apiclient.Worksheet, error)
+	FetchFavoriteWorksheetsWithContextFunc        func(context.Context) (*[]apiclient.Worksheet, error)
+}
+
+var _ apiclient.CirconusAPI = (*Mock)(nil)
+
+// Get calls GetFunc.
+func (m *Mock) Get(reqPath string) (r0 []byte, err error) {
+	m.record("Get", reqPath)
+	if m.GetFunc == nil {
+		err = notMocked("Get")
+		return
+	}
+	return m.GetFunc(reqPath)
+}
+
+// Delete calls DeleteFunc.
+func (m *Mock) Delete(reqPath string) (r0 []byte, err error) {
+	m.record("Delete", reqPath)
+	if m.DeleteFunc == nil {
+		err = notMocked("Delete")
+		return
+	}
+	return m.DeleteFunc(reqPath)
+}
+
+// Post calls PostFunc.
+func (m *Mock) Post(reqPath string, data []byte) (r0 []byte, err error) {
+	m.record("Post", reqPath, data)
+	if m.PostFunc == nil {
+		err = notMocked("Post")
+		return
+	}
+	return m.PostFunc(reqPath, data)
+}
+
+// Put calls PutFunc.
+func (m *Mock) Put(reqPath string, data []byte) (r0 []byte, err error) {
+	m.record("Put", reqPath, data)
+	if m.PutFunc == nil {
+		err = notMocked("Put")
+		return
+	}
+	return m.PutFunc(reqPath, data)
+}
+
+// GetWithContext calls GetWithContextFunc.
+func (m *Mock) GetWithContext(ctx context.Context, reqPath string) (r0 []byte, err error) {
+	m.record("GetWithContext", ctx, reqPath)
+	if m.GetWithContextFunc == nil {
+		err = notMocked("GetWithContext")
+		return
+	}
+	return m.GetWithContextFunc(ctx, reqPath)
+}
+
+// DeleteWithContext calls DeleteWithContextFunc.
+func (m *Mock) DeleteWithContext(ctx context.Context, reqPath string) (r0 []byte, err error) {
+	m.record("DeleteWithContext", ctx, reqPath)
+	if m.DeleteWithContextFunc == nil {
+		err = notMocked("DeleteWithContext")
+		return
+	}
+	return m.DeleteWithContextFunc(ctx, reqPath)
+}
+
+// PostWithContext calls PostWithContextFunc.
+func (m *Mock) PostWithContext(ctx context.Context, reqPath string, data []byte) (r0 []byte, err error) {
+	m.record("PostWithContext", ctx, reqPath, data)
+	if m.PostWithContextFunc == nil {
+		err = notMocked("PostWithContext")
+		return
+	}
+	return m.PostWithContextFunc(ctx, reqPath, data)
+}
+
+// PutWithContext calls PutWithContextFunc.
+func (m *Mock) PutWithContext(ctx context.Context, reqPath string, data []byte) (r0 []byte, err error) {
+	m.record("PutWithContext", ctx, reqPath, data)
+	if m.PutWithContextFunc == nil {
+		err = notMocked("PutWithContext")
+		return
+	}
+	return m.PutWithContextFunc(ctx, reqPath, data)
+}
+
+// FetchAccount calls FetchAccountFunc.
+func (m *Mock) FetchAccount(cid apiclient.CIDType) (r0 *apiclient.Account, err error) {
+	m.record("FetchAccount", cid)
+	if m.FetchAccountFunc == nil {
+		err = notMocked("FetchAccount")
+		return
+	}
+	return m.FetchAccountFunc(cid)
+}
+
+// FetchAccountWithContext calls FetchAccountWithContextFunc.
+func (m *Mock) FetchAccountWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.Account, err error) {
+	m.record("FetchAccountWithContext", ctx, cid)
+	if m.FetchAccountWithContextFunc == nil {
+		err = notMocked("FetchAccountWithContext")
+		return
+	}
+	return m.FetchAccountWithContextFunc(ctx, cid)
+}
+
+// FetchCurrentAccount calls FetchCurrentAccountFunc.
+func (m *Mock) FetchCurrentAccount() (r0 *apiclient.Account, err error) {
+	m.record("FetchCurrentAccount")
+	if m.FetchCurrentAccountFunc == nil {
+		err = notMocked("FetchCurrentAccount")
+		return
+	}
+	return m.FetchCurrentAccountFunc()
+}
+
+// FetchCurrentAccountWithContext calls FetchCurrentAccountWithContextFunc.
+func (m *Mock) FetchCurrentAccountWithContext(ctx context.Context) (r0 *apiclient.Account, err error) {
+	m.record("FetchCurrentAccountWithContext", ctx)
+	if m.FetchCurrentAccountWithContextFunc == nil {
+		err = notMocked("FetchCurrentAccountWithContext")
+		return
+	}
+	return m.FetchCurrentAccountWithContextFunc(ctx)
+}
+
+// FetchAccounts calls FetchAccountsFunc.
+func (m *Mock) FetchAccounts() (r0 *[]apiclient.Account, err error) {
+	m.record("FetchAccounts")
+	if m.FetchAccountsFunc == nil {
+		err = notMocked("FetchAccounts")
+		return
+	}
+	return m.FetchAccountsFunc()
+}
+
+// FetchAccountsWithContext calls FetchAccountsWithContextFunc.
+func (m *Mock) FetchAccountsWithContext(ctx context.Context) (r0 *[]apiclient.Account, err error) {
+	m.record("FetchAccountsWithContext", ctx)
+	if m.FetchAccountsWithContextFunc == nil {
+		err = notMocked("FetchAccountsWithContext")
+		return
+	}
+	return m.FetchAccountsWithContextFunc(ctx)
+}
+
+// UpdateAccount calls UpdateAccountFunc.
+func (m *Mock) UpdateAccount(cfg *apiclient.Account) (r0 *apiclient.Account, err error) {
+	m.record("UpdateAccount", cfg)
+	if m.UpdateAccountFunc == nil {
+		err = notMocked("UpdateAccount")
+		return
+	}
+	return m.UpdateAccountFunc(cfg)
+}
+
+// UpdateAccountWithContext calls UpdateAccountWithContextFunc.
+func (m *Mock) UpdateAccountWithContext(ctx context.Context, cfg *apiclient.Account) (r0 *apiclient.Account, err error) {
+	m.record("UpdateAccountWithContext", ctx, cfg)
+	if m.UpdateAccountWithContextFunc == nil {
+		err = notMocked("UpdateAccountWithContext")
+		return
+	}
+	return m.UpdateAccountWithContextFunc(ctx, cfg)
+}
+
+// SearchAccounts calls SearchAccountsFunc.
+func (m *Mock) SearchAccounts(filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Account, err error) {
+	m.record("SearchAccounts", filterCriteria)
+	if m.SearchAccountsFunc == nil {
+		err = notMocked("SearchAccounts")
+		return
+	}
+	return m.SearchAccountsFunc(filterCriteria)
+}
+
+// SearchAccountsWithContext calls SearchAccountsWithContextFunc.
+func (m *Mock) SearchAccountsWithContext(ctx context.Context, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Account, err error) {
+	m.record("SearchAccountsWithContext", ctx, filterCriteria)
+	if m.SearchAccountsWithContextFunc == nil {
+		err = notMocked("SearchAccountsWithContext")
+		return
+	}
+	return m.SearchAccountsWithContextFunc(ctx, filterCriteria)
+}
+
+// InviteUser calls InviteUserFunc.
+func (m *Mock) InviteUser(email string, role apiclient.AccountRole) (r0 *apiclient.Account, err error) {
+	m.record("InviteUser", email, role)
+	if m.InviteUserFunc == nil {
+		err = notMocked("InviteUser")
+		return
+	}
+	return m.InviteUserFunc(email, role)
+}
+
+// ListPendingInvites calls ListPendingInvitesFunc.
+func (m *Mock) ListPendingInvites() (r0 *[]apiclient.AccountInvite, err error) {
+	m.record("ListPendingInvites")
+	if m.ListPendingInvitesFunc == nil {
+		err = notMocked("ListPendingInvites")
+		return
+	}
+	return m.ListPendingInvitesFunc()
+}
+
+// RevokeInvite calls RevokeInviteFunc.
+func (m *Mock) RevokeInvite(email string) (r0 bool, err error) {
+	m.record("RevokeInvite", email)
+	if m.RevokeInviteFunc == nil {
+		err = notMocked("RevokeInvite")
+		return
+	}
+	return m.RevokeInviteFunc(email)
+}
+
+// ForEachAccount calls ForEachAccountFunc.
+func (m *Mock) ForEachAccount(fn func(acct *apiclient.Account, api *apiclient.API) error) (err error) {
+	m.record("ForEachAccount", fn)
+	if m.ForEachAccountFunc == nil {
+		err = notMocked("ForEachAccount")
+		return
+	}
+	return m.ForEachAccountFunc(fn)
+}
+
+// UpdateAccountDefaults calls UpdateAccountDefaultsFunc.
+func (m *Mock) UpdateAccountDefaults(cid apiclient.CIDType, defaults apiclient.AccountDefaults) (r0 *apiclient.Account, err error) {
+	m.record("UpdateAccountDefaults", cid, defaults)
+	if m.UpdateAccountDefaultsFunc == nil {
+		err = notMocked("UpdateAccountDefaults")
+		return
+	}
+	return m.UpdateAccountDefaultsFunc(cid, defaults)
+}
+
+// UpdateAccountDefaultsWithContext calls UpdateAccountDefaultsWithContextFunc.
+func (m *Mock) UpdateAccountDefaultsWithContext(ctx context.Context, cid apiclient.CIDType, defaults apiclient.AccountDefaults) (r0 *apiclient.Account, err error) {
+	m.record("UpdateAccountDefaultsWithContext", ctx, cid, defaults)
+	if m.UpdateAccountDefaultsWithContextFunc == nil {
+		err = notMocked("UpdateAccountDefaultsWithContext")
+		return
+	}
+	return m.UpdateAccountDefaultsWithContextFunc(ctx, cid, defaults)
+}
+
+// FetchAcknowledgement calls FetchAcknowledgementFunc.
+func (m *Mock) FetchAcknowledgement(cid apiclient.CIDType) (r0 *apiclient.Acknowledgement, err error) {
+	m.record("FetchAcknowledgement", cid)
+	if m.FetchAcknowledgementFunc == nil {
+		err = notMocked("FetchAcknowledgement")
+		return
+	}
+	return m.FetchAcknowledgementFunc(cid)
+}
+
+// FetchAcknowledgementWithContext calls FetchAcknowledgementWithContextFunc.
+func (m *Mock) FetchAcknowledgementWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.Acknowledgement, err error) {
+	m.record("FetchAcknowledgementWithContext", ctx, cid)
+	if m.FetchAcknowledgementWithContextFunc == nil {
+		err = notMocked("FetchAcknowledgementWithContext")
+		return
+	}
+	return m.FetchAcknowledgementWithContextFunc(ctx, cid)
+}
+
+// FetchAcknowledgements calls FetchAcknowledgementsFunc.
+func (m *Mock) FetchAcknowledgements() (r0 *[]apiclient.Acknowledgement, err error) {
+	m.record("FetchAcknowledgements")
+	if m.FetchAcknowledgementsFunc == nil {
+		err = notMocked("FetchAcknowledgements")
+		return
+	}
+	return m.FetchAcknowledgementsFunc()
+}
+
+// FetchAcknowledgementsWithContext calls FetchAcknowledgementsWithContextFunc.
+func (m *Mock) FetchAcknowledgementsWithContext(ctx context.Context) (r0 *[]apiclient.Acknowledgement, err error) {
+	m.record("FetchAcknowledgementsWithContext", ctx)
+	if m.FetchAcknowledgementsWithContextFunc == nil {
+		err = notMocked("FetchAcknowledgementsWithContext")
+		return
+	}
+	return m.FetchAcknowledgementsWithContextFunc(ctx)
+}
+
+// UpdateAcknowledgement calls UpdateAcknowledgementFunc.
+func (m *Mock) UpdateAcknowledgement(cfg *apiclient.Acknowledgement) (r0 *apiclient.Acknowledgement, err error) {
+	m.record("UpdateAcknowledgement", cfg)
+	if m.UpdateAcknowledgementFunc == nil {
+		err = notMocked("UpdateAcknowledgement")
+		return
+	}
+	return m.UpdateAcknowledgementFunc(cfg)
+}
+
+// UpdateAcknowledgementWithContext calls UpdateAcknowledgementWithContextFunc.
+func (m *Mock) UpdateAcknowledgementWithContext(ctx context.Context, cfg *apiclient.Acknowledgement) (r0 *apiclient.Acknowledgement, err error) {
+	m.record("UpdateAcknowledgementWithContext", ctx, cfg)
+	if m.UpdateAcknowledgementWithContextFunc == nil {
+		err = notMocked("UpdateAcknowledgementWithContext")
+		return
+	}
+	return m.UpdateAcknowledgementWithContextFunc(ctx, cfg)
+}
+
+// CreateAcknowledgement calls CreateAcknowledgementFunc.
+func (m *Mock) CreateAcknowledgement(cfg *apiclient.Acknowledgement) (r0 *apiclient.Acknowledgement, err error) {
+	m.record("CreateAcknowledgement", cfg)
+	if m.CreateAcknowledgementFunc == nil {
+		err = notMocked("CreateAcknowledgement")
+		return
+	}
+	return m.CreateAcknowledgementFunc(cfg)
+}
+
+// CreateAcknowledgementWithContext calls CreateAcknowledgementWithContextFunc.
+func (m *Mock) CreateAcknowledgementWithContext(ctx context.Context, cfg *apiclient.Acknowledgement) (r0 *apiclient.Acknowledgement, err error) {
+	m.record("CreateAcknowledgementWithContext", ctx, cfg)
+	if m.CreateAcknowledgementWithContextFunc == nil {
+		err = notMocked("CreateAcknowledgementWithContext")
+		return
+	}
+	return m.CreateAcknowledgementWithContextFunc(ctx, cfg)
+}
+
+// SearchAcknowledgements calls SearchAcknowledgementsFunc.
+func (m *Mock) SearchAcknowledgements(searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Acknowledgement, err error) {
+	m.record("SearchAcknowledgements", searchCriteria, filterCriteria)
+	if m.SearchAcknowledgementsFunc == nil {
+		err = notMocked("SearchAcknowledgements")
+		return
+	}
+	return m.SearchAcknowledgementsFunc(searchCriteria, filterCriteria)
+}
+
+// SearchAcknowledgementsWithContext calls SearchAcknowledgementsWithContextFunc.
+func (m *Mock) SearchAcknowledgementsWithContext(ctx context.Context, searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Acknowledgement, err error) {
+	m.record("SearchAcknowledgementsWithContext", ctx, searchCriteria, filterCriteria)
+	if m.SearchAcknowledgementsWithContextFunc == nil {
+		err = notMocked("SearchAcknowledgementsWithContext")
+		return
+	}
+	return m.SearchAcknowledgementsWithContextFunc(ctx, searchCriteria, filterCriteria)
+}
+
+// FetchAlert calls FetchAlertFunc.
+func (m *Mock) FetchAlert(cid apiclient.CIDType) (r0 *apiclient.Alert, err error) {
+	m.record("FetchAlert", cid)
+	if m.FetchAlertFunc == nil {
+		err = notMocked("FetchAlert")
+		return
+	}
+	return m.FetchAlertFunc(cid)
+}
+
+// FetchAlertWithContext calls FetchAlertWithContextFunc.
+func (m *Mock) FetchAlertWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.Alert, err error) {
+	m.record("FetchAlertWithContext", ctx, cid)
+	if m.FetchAlertWithContextFunc == nil {
+		err = notMocked("FetchAlertWithContext")
+		return
+	}
+	return m.FetchAlertWithContextFunc(ctx, cid)
+}
+
+// FetchAlerts calls FetchAlertsFunc.
+func (m *Mock) FetchAlerts() (r0 *[]apiclient.Alert, err error) {
+	m.record("FetchAlerts")
+	if m.FetchAlertsFunc == nil {
+		err = notMocked("FetchAlerts")
+		return
+	}
+	return m.FetchAlertsFunc()
+}
+
+// FetchAlertsWithContext calls FetchAlertsWithContextFunc.
+func (m *Mock) FetchAlertsWithContext(ctx context.Context) (r0 *[]apiclient.Alert, err error) {
+	m.record("FetchAlertsWithContext", ctx)
+	if m.FetchAlertsWithContextFunc == nil {
+		err = notMocked("FetchAlertsWithContext")
+		return
+	}
+	return m.FetchAlertsWithContextFunc(ctx)
+}
+
+// SearchAlerts calls SearchAlertsFunc.
+func (m *Mock) SearchAlerts(searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Alert, err error) {
+	m.record("SearchAlerts", searchCriteria, filterCriteria)
+	if m.SearchAlertsFunc == nil {
+		err = notMocked("SearchAlerts")
+		return
+	}
+	return m.SearchAlertsFunc(searchCriteria, filterCriteria)
+}
+
+// SearchAlertsWithContext calls SearchAlertsWithContextFunc.
+func (m *Mock) SearchAlertsWithContext(ctx context.Context, searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Alert, err error) {
+	m.record("SearchAlertsWithContext", ctx, searchCriteria, filterCriteria)
+	if m.SearchAlertsWithContextFunc == nil {
+		err = notMocked("SearchAlertsWithContext")
+		return
+	}
+	return m.SearchAlertsWithContextFunc(ctx, searchCriteria, filterCriteria)
+}
+
+// FetchAnnotation calls FetchAnnotationFunc.
+func (m *Mock) FetchAnnotation(cid apiclient.CIDType) (r0 *apiclient.Annotation, err error) {
+	m.record("FetchAnnotation", cid)
+	if m.FetchAnnotationFunc == nil {
+		err = notMocked("FetchAnnotation")
+		return
+	}
+	return m.FetchAnnotationFunc(cid)
+}
+
+// FetchAnnotationWithContext calls FetchAnnotationWithContextFunc.
+func (m *Mock) FetchAnnotationWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.Annotation, err error) {
+	m.record("FetchAnnotationWithContext", ctx, cid)
+	if m.FetchAnnotationWithContextFunc == nil {
+		err = notMocked("FetchAnnotationWithContext")
+		return
+	}
+	return m.FetchAnnotationWithContextFunc(ctx, cid)
+}
+
+// FetchAnnotations calls FetchAnnotationsFunc.
+func (m *Mock) FetchAnnotations() (r0 *[]apiclient.Annotation, err error) {
+	m.record("FetchAnnotations")
+	if m.FetchAnnotationsFunc == nil {
+		err = notMocked("FetchAnnotations")
+		return
+	}
+	return m.FetchAnnotationsFunc()
+}
+
+// FetchAnnotationsWithContext calls FetchAnnotationsWithContextFunc.
+func (m *Mock) FetchAnnotationsWithContext(ctx context.Context) (r0 *[]apiclient.Annotation, err error) {
+	m.record("FetchAnnotationsWithContext", ctx)
+	if m.FetchAnnotationsWithContextFunc == nil {
+		err = notMocked("FetchAnnotationsWithContext")
+		return
+	}
+	return m.FetchAnnotationsWithContextFunc(ctx)
+}
+
+// UpdateAnnotation calls UpdateAnnotationFunc.
+func (m *Mock) UpdateAnnotation(cfg *apiclient.Annotation) (r0 *apiclient.Annotation, err error) {
+	m.record("UpdateAnnotation", cfg)
+	if m.UpdateAnnotationFunc == nil {
+		err = notMocked("UpdateAnnotation")
+		return
+	}
+	return m.UpdateAnnotationFunc(cfg)
+}
+
+// UpdateAnnotationWithContext calls UpdateAnnotationWithContextFunc.
+func (m *Mock) UpdateAnnotationWithContext(ctx context.Context, cfg *apiclient.Annotation) (r0 *apiclient.Annotation, err error) {
+	m.record("UpdateAnnotationWithContext", ctx, cfg)
+	if m.UpdateAnnotationWithContextFunc == nil {
+		err = notMocked("UpdateAnnotationWithContext")
+		return
+	}
+	return m.UpdateAnnotationWithContextFunc(ctx, cfg)
+}
+
+// CreateAnnotation calls CreateAnnotationFunc.
+func (m *Mock) CreateAnnotation(cfg *apiclient.Annotation) (r0 *apiclient.Annotation, err error) {
+	m.record("CreateAnnotation", cfg)
+	if m.CreateAnnotationFunc == nil {
+		err = notMocked("CreateAnnotation")
+		return
+	}
+	return m.CreateAnnotationFunc(cfg)
+}
+
+// CreateAnnotationWithContext calls CreateAnnotationWithContextFunc.
+func (m *Mock) CreateAnnotationWithContext(ctx context.Context, cfg *apiclient.Annotation) (r0 *apiclient.Annotation, err error) {
+	m.record("CreateAnnotationWithContext", ctx, cfg)
+	if m.CreateAnnotationWithContextFunc == nil {
+		err = notMocked("CreateAnnotationWithContext")
+		return
+	}
+	return m.CreateAnnotationWithContextFunc(ctx, cfg)
+}
+
+// DeleteAnnotation calls DeleteAnnotationFunc.
+func (m *Mock) DeleteAnnotation(cfg *apiclient.Annotation) (r0 bool, err error) {
+	m.record("DeleteAnnotation", cfg)
+	if m.DeleteAnnotationFunc == nil {
+		err = notMocked("DeleteAnnotation")
+		return
+	}
+	return m.DeleteAnnotationFunc(cfg)
+}
+
+// DeleteAnnotationWithContext calls DeleteAnnotationWithContextFunc.
+func (m *Mock) DeleteAnnotationWithContext(ctx context.Context, cfg *apiclient.Annotation) (r0 bool, err error) {
+	m.record("DeleteAnnotationWithContext", ctx, cfg)
+	if m.DeleteAnnotationWithContextFunc == nil {
+		err = notMocked("DeleteAnnotationWithContext")
+		return
+	}
+	return m.DeleteAnnotationWithContextFunc(ctx, cfg)
+}
+
+// DeleteAnnotationByCID calls DeleteAnnotationByCIDFunc.
+func (m *Mock) DeleteAnnotationByCID(cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteAnnotationByCID", cid)
+	if m.DeleteAnnotationByCIDFunc == nil {
+		err = notMocked("DeleteAnnotationByCID")
+		return
+	}
+	return m.DeleteAnnotationByCIDFunc(cid)
+}
+
+// DeleteAnnotationByCIDWithContext calls DeleteAnnotationByCIDWithContextFunc.
+func (m *Mock) DeleteAnnotationByCIDWithContext(ctx context.Context, cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteAnnotationByCIDWithContext", ctx, cid)
+	if m.DeleteAnnotationByCIDWithContextFunc == nil {
+		err = notMocked("DeleteAnnotationByCIDWithContext")
+		return
+	}
+	return m.DeleteAnnotationByCIDWithContextFunc(ctx, cid)
+}
+
+// SearchAnnotations calls SearchAnnotationsFunc.
+func (m *Mock) SearchAnnotations(searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Annotation, err error) {
+	m.record("SearchAnnotations", searchCriteria, filterCriteria)
+	if m.SearchAnnotationsFunc == nil {
+		err = notMocked("SearchAnnotations")
+		return
+	}
+	return m.SearchAnnotationsFunc(searchCriteria, filterCriteria)
+}
+
+// SearchAnnotationsWithContext calls SearchAnnotationsWithContextFunc.
+func (m *Mock) SearchAnnotationsWithContext(ctx context.Context, searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Annotation, err error) {
+	m.record("SearchAnnotationsWithContext", ctx, searchCriteria, filterCriteria)
+	if m.SearchAnnotationsWithContextFunc == nil {
+		err = notMocked("SearchAnnotationsWithContext")
+		return
+	}
+	return m.SearchAnnotationsWithContextFunc(ctx, searchCriteria, filterCriteria)
+}
+
+// FetchBroker calls FetchBrokerFunc.
+func (m *Mock) FetchBroker(cid apiclient.CIDType) (r0 *apiclient.Broker, err error) {
+	m.record("FetchBroker", cid)
+	if m.FetchBrokerFunc == nil {
+		err = notMocked("FetchBroker")
+		return
+	}
+	return m.FetchBrokerFunc(cid)
+}
+
+// FetchBrokerWithContext calls FetchBrokerWithContextFunc.
+func (m *Mock) FetchBrokerWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.Broker, err error) {
+	m.record("FetchBrokerWithContext", ctx, cid)
+	if m.FetchBrokerWithContextFunc == nil {
+		err = notMocked("FetchBrokerWithContext")
+		return
+	}
+	return m.FetchBrokerWithContextFunc(ctx, cid)
+}
+
+// FetchBrokers calls FetchBrokersFunc.
+func (m *Mock) FetchBrokers() (r0 *[]apiclient.Broker, err error) {
+	m.record("FetchBrokers")
+	if m.FetchBrokersFunc == nil {
+		err = notMocked("FetchBrokers")
+		return
+	}
+	return m.FetchBrokersFunc()
+}
+
+// FetchBrokersWithContext calls FetchBrokersWithContextFunc.
+func (m *Mock) FetchBrokersWithContext(ctx context.Context) (r0 *[]apiclient.Broker, err error) {
+	m.record("FetchBrokersWithContext", ctx)
+	if m.FetchBrokersWithContextFunc == nil {
+		err = notMocked("FetchBrokersWithContext")
+		return
+	}
+	return m.FetchBrokersWithContextFunc(ctx)
+}
+
+// SearchBrokers calls SearchBrokersFunc.
+func (m *Mock) SearchBrokers(searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Broker, err error) {
+	m.record("SearchBrokers", searchCriteria, filterCriteria)
+	if m.SearchBrokersFunc == nil {
+		err = notMocked("SearchBrokers")
+		return
+	}
+	return m.SearchBrokersFunc(searchCriteria, filterCriteria)
+}
+
+// SearchBrokersWithContext calls SearchBrokersWithContextFunc.
+func (m *Mock) SearchBrokersWithContext(ctx context.Context, searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Broker, err error) {
+	m.record("SearchBrokersWithContext", ctx, searchCriteria, filterCriteria)
+	if m.SearchBrokersWithContextFunc == nil {
+		err = notMocked("SearchBrokersWithContext")
+		return
+	}
+	return m.SearchBrokersWithContextFunc(ctx, searchCriteria, filterCriteria)
+}
+
+// FetchCheck calls FetchCheckFunc.
+func (m *Mock) FetchCheck(cid apiclient.CIDType) (r0 *apiclient.Check, err error) {
+	m.record("FetchCheck", cid)
+	if m.FetchCheckFunc == nil {
+		err = notMocked("FetchCheck")
+		return
+	}
+	return m.FetchCheckFunc(cid)
+}
+
+// FetchCheckWithContext calls FetchCheckWithContextFunc.
+func (m *Mock) FetchCheckWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.Check, err error) {
+	m.record("FetchCheckWithContext", ctx, cid)
+	if m.FetchCheckWithContextFunc == nil {
+		err = notMocked("FetchCheckWithContext")
+		return
+	}
+	return m.FetchCheckWithContextFunc(ctx, cid)
+}
+
+// FetchChecks calls FetchChecksFunc.
+func (m *Mock) FetchChecks() (r0 *[]apiclient.Check, err error) {
+	m.record("FetchChecks")
+	if m.FetchChecksFunc == nil {
+		err = notMocked("FetchChecks")
+		return
+	}
+	return m.FetchChecksFunc()
+}
+
+// FetchChecksWithContext calls FetchChecksWithContextFunc.
+func (m *Mock) FetchChecksWithContext(ctx context.Context) (r0 *[]apiclient.Check, err error) {
+	m.record("FetchChecksWithContext", ctx)
+	if m.FetchChecksWithContextFunc == nil {
+		err = notMocked("FetchChecksWithContext")
+		return
+	}
+	return m.FetchChecksWithContextFunc(ctx)
+}
+
+// SearchChecks calls SearchChecksFunc.
+func (m *Mock) SearchChecks(searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Check, err error) {
+	m.record("SearchChecks", searchCriteria, filterCriteria)
+	if m.SearchChecksFunc == nil {
+		err = notMocked("SearchChecks")
+		return
+	}
+	return m.SearchChecksFunc(searchCriteria, filterCriteria)
+}
+
+// SearchChecksWithContext calls SearchChecksWithContextFunc.
+func (m *Mock) SearchChecksWithContext(ctx context.Context, searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Check, err error) {
+	m.record("SearchChecksWithContext", ctx, searchCriteria, filterCriteria)
+	if m.SearchChecksWithContextFunc == nil {
+		err = notMocked("SearchChecksWithContext")
+		return
+	}
+	return m.SearchChecksWithContextFunc(ctx, searchCriteria, filterCriteria)
+}
+
+// FetchCheckBundle calls FetchCheckBundleFunc.
+func (m *Mock) FetchCheckBundle(cid apiclient.CIDType) (r0 *apiclient.CheckBundle, err error) {
+	m.record("FetchCheckBundle", cid)
+	if m.FetchCheckBundleFunc == nil {
+		err = notMocked("FetchCheckBundle")
+		return
+	}
+	return m.FetchCheckBundleFunc(cid)
+}
+
+// FetchCheckBundleWithContext calls FetchCheckBundleWithContextFunc.
+func (m *Mock) FetchCheckBundleWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.CheckBundle, err error) {
+	m.record("FetchCheckBundleWithContext", ctx, cid)
+	if m.FetchCheckBundleWithContextFunc == nil {
+		err = notMocked("FetchCheckBundleWithContext")
+		return
+	}
+	return m.FetchCheckBundleWithContextFunc(ctx, cid)
+}
+
+// FetchCheckBundles calls FetchCheckBundlesFunc.
+func (m *Mock) FetchCheckBundles() (r0 *[]apiclient.CheckBundle, err error) {
+	m.record("FetchCheckBundles")
+	if m.FetchCheckBundlesFunc == nil {
+		err = notMocked("FetchCheckBundles")
+		return
+	}
+	return m.FetchCheckBundlesFunc()
+}
+
+// FetchCheckBundlesWithContext calls FetchCheckBundlesWithContextFunc.
+func (m *Mock) FetchCheckBundlesWithContext(ctx context.Context) (r0 *[]apiclient.CheckBundle, err error) {
+	m.record("FetchCheckBundlesWithContext", ctx)
+	if m.FetchCheckBundlesWithContextFunc == nil {
+		err = notMocked("FetchCheckBundlesWithContext")
+		return
+	}
+	return m.FetchCheckBundlesWithContextFunc(ctx)
+}
+
+// UpdateCheckBundle calls UpdateCheckBundleFunc.
+func (m *Mock) UpdateCheckBundle(cfg *apiclient.CheckBundle) (r0 *apiclient.CheckBundle, err error) {
+	m.record("UpdateCheckBundle", cfg)
+	if m.UpdateCheckBundleFunc == nil {
+		err = notMocked("UpdateCheckBundle")
+		return
+	}
+	return m.UpdateCheckBundleFunc(cfg)
+}
+
+// UpdateCheckBundleWithContext calls UpdateCheckBundleWithContextFunc.
+func (m *Mock) UpdateCheckBundleWithContext(ctx context.Context, cfg *apiclient.CheckBundle) (r0 *apiclient.CheckBundle, err error) {
+	m.record("UpdateCheckBundleWithContext", ctx, cfg)
+	if m.UpdateCheckBundleWithContextFunc == nil {
+		err = notMocked("UpdateCheckBundleWithContext")
+		return
+	}
+	return m.UpdateCheckBundleWithContextFunc(ctx, cfg)
+}
+
+// CreateCheckBundle calls CreateCheckBundleFunc.
+func (m *Mock) CreateCheckBundle(cfg *apiclient.CheckBundle) (r0 *apiclient.CheckBundle, err error) {
+	m.record("CreateCheckBundle", cfg)
+	if m.CreateCheckBundleFunc == nil {
+		err = notMocked("CreateCheckBundle")
+		return
+	}
+	return m.CreateCheckBundleFunc(cfg)
+}
+
+// CreateCheckBundleWithContext calls CreateCheckBundleWithContextFunc.
+func (m *Mock) CreateCheckBundleWithContext(ctx context.Context, cfg *apiclient.CheckBundle) (r0 *apiclient.CheckBundle, err error) {
+	m.record("CreateCheckBundleWithContext", ctx, cfg)
+	if m.CreateCheckBundleWithContextFunc == nil {
+		err = notMocked("CreateCheckBundleWithContext")
+		return
+	}
+	return m.CreateCheckBundleWithContextFunc(ctx, cfg)
+}
+
+// DeleteCheckBundle calls DeleteCheckBundleFunc.
+func (m *Mock) DeleteCheckBundle(cfg *apiclient.CheckBundle) (r0 bool, err error) {
+	m.record("DeleteCheckBundle", cfg)
+	if m.DeleteCheckBundleFunc == nil {
+		err = notMocked("DeleteCheckBundle")
+		return
+	}
+	return m.DeleteCheckBundleFunc(cfg)
+}
+
+// DeleteCheckBundleWithContext calls DeleteCheckBundleWithContextFunc.
+func (m *Mock) DeleteCheckBundleWithContext(ctx context.Context, cfg *apiclient.CheckBundle) (r0 bool, err error) {
+	m.record("DeleteCheckBundleWithContext", ctx, cfg)
+	if m.DeleteCheckBundleWithContextFunc == nil {
+		err = notMocked("DeleteCheckBundleWithContext")
+		return
+	}
+	return m.DeleteCheckBundleWithContextFunc(ctx, cfg)
+}
+
+// DeleteCheckBundleByCID calls DeleteCheckBundleByCIDFunc.
+func (m *Mock) DeleteCheckBundleByCID(cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteCheckBundleByCID", cid)
+	if m.DeleteCheckBundleByCIDFunc == nil {
+		err = notMocked("DeleteCheckBundleByCID")
+		return
+	}
+	return m.DeleteCheckBundleByCIDFunc(cid)
+}
+
+// DeleteCheckBundleByCIDWithContext calls DeleteCheckBundleByCIDWithContextFunc.
+func (m *Mock) DeleteCheckBundleByCIDWithContext(ctx context.Context, cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteCheckBundleByCIDWithContext", ctx, cid)
+	if m.DeleteCheckBundleByCIDWithContextFunc == nil {
+		err = notMocked("DeleteCheckBundleByCIDWithContext")
+		return
+	}
+	return m.DeleteCheckBundleByCIDWithContextFunc(ctx, cid)
+}
+
+// SearchCheckBundles calls SearchCheckBundlesFunc.
+func (m *Mock) SearchCheckBundles(searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.CheckBundle, err error) {
+	m.record("SearchCheckBundles", searchCriteria, filterCriteria)
+	if m.SearchCheckBundlesFunc == nil {
+		err = notMocked("SearchCheckBundles")
+		return
+	}
+	return m.SearchCheckBundlesFunc(searchCriteria, filterCriteria)
+}
+
+// SearchCheckBundlesWithContext calls SearchCheckBundlesWithContextFunc.
+func (m *Mock) SearchCheckBundlesWithContext(ctx context.Context, searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.CheckBundle, err error) {
+	m.record("SearchCheckBundlesWithContext", ctx, searchCriteria, filterCriteria)
+	if m.SearchCheckBundlesWithContextFunc == nil {
+		err = notMocked("SearchCheckBundlesWithContext")
+		return
+	}
+	return m.SearchCheckBundlesWithContextFunc(ctx, searchCriteria, filterCriteria)
+}
+
+// UpsertCheckBundle calls UpsertCheckBundleFunc.
+func (m *Mock) UpsertCheckBundle(target *apiclient.CheckBundle) (r0 *apiclient.CheckBundle, r1 []apiclient.AuditChange, err error) {
+	m.record("UpsertCheckBundle", target)
+	if m.UpsertCheckBundleFunc == nil {
+		err = notMocked("UpsertCheckBundle")
+		return
+	}
+	return m.UpsertCheckBundleFunc(target)
+}
+
+// UpsertCheckBundleWithContext calls UpsertCheckBundleWithContextFunc.
+func (m *Mock) UpsertCheckBundleWithContext(ctx context.Context, target *apiclient.CheckBundle) (r0 *apiclient.CheckBundle, r1 []apiclient.AuditChange, err error) {
+	m.record("UpsertCheckBundleWithContext", ctx, target)
+	if m.UpsertCheckBundleWithContextFunc == nil {
+		err = notMocked("UpsertCheckBundleWithContext")
+		return
+	}
+	return m.UpsertCheckBundleWithContextFunc(ctx, target)
+}
+
+// FetchCheckBundleMetrics calls FetchCheckBundleMetricsFunc.
+func (m *Mock) FetchCheckBundleMetrics(cid apiclient.CIDType) (r0 *apiclient.CheckBundleMetrics, err error) {
+	m.record("FetchCheckBundleMetrics", cid)
+	if m.FetchCheckBundleMetricsFunc == nil {
+		err = notMocked("FetchCheckBundleMetrics")
+		return
+	}
+	return m.FetchCheckBundleMetricsFunc(cid)
+}
+
+// FetchCheckBundleMetricsWithContext calls FetchCheckBundleMetricsWithContextFunc.
+func (m *Mock) FetchCheckBundleMetricsWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.CheckBundleMetrics, err error) {
+	m.record("FetchCheckBundleMetricsWithContext", ctx, cid)
+	if m.FetchCheckBundleMetricsWithContextFunc == nil {
+		err = notMocked("FetchCheckBundleMetricsWithContext")
+		return
+	}
+	return m.FetchCheckBundleMetricsWithContextFunc(ctx, cid)
+}
+
+// UpdateCheckBundleMetrics calls UpdateCheckBundleMetricsFunc.
+func (m *Mock) UpdateCheckBundleMetrics(cfg *apiclient.CheckBundleMetrics) (r0 *apiclient.CheckBundleMetrics, err error) {
+	m.record("UpdateCheckBundleMetrics", cfg)
+	if m.UpdateCheckBundleMetricsFunc == nil {
+		err = notMocked("UpdateCheckBundleMetrics")
+		return
+	}
+	return m.UpdateCheckBundleMetricsFunc(cfg)
+}
+
+// UpdateCheckBundleMetricsWithContext calls UpdateCheckBundleMetricsWithContextFunc.
+func (m *Mock) UpdateCheckBundleMetricsWithContext(ctx context.Context, cfg *apiclient.CheckBundleMetrics) (r0 *apiclient.CheckBundleMetrics, err error) {
+	m.record("UpdateCheckBundleMetricsWithContext", ctx, cfg)
+	if m.UpdateCheckBundleMetricsWithContextFunc == nil {
+		err = notMocked("UpdateCheckBundleMetricsWithContext")
+		return
+	}
+	return m.UpdateCheckBundleMetricsWithContextFunc(ctx, cfg)
+}
+
+// FetchCheckMove calls FetchCheckMoveFunc.
+func (m *Mock) FetchCheckMove(cid apiclient.CIDType) (r0 *apiclient.CheckMove, err error) {
+	m.record("FetchCheckMove", cid)
+	if m.FetchCheckMoveFunc == nil {
+		err = notMocked("FetchCheckMove")
+		return
+	}
+	return m.FetchCheckMoveFunc(cid)
+}
+
+// FetchCheckMoveWithContext calls FetchCheckMoveWithContextFunc.
+func (m *Mock) FetchCheckMoveWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.CheckMove, err error) {
+	m.record("FetchCheckMoveWithContext", ctx, cid)
+	if m.FetchCheckMoveWithContextFunc == nil {
+		err = notMocked("FetchCheckMoveWithContext")
+		return
+	}
+	return m.FetchCheckMoveWithContextFunc(ctx, cid)
+}
+
+// FetchCheckMoves calls FetchCheckMovesFunc.
+func (m *Mock) FetchCheckMoves() (r0 *[]apiclient.CheckMove, err error) {
+	m.record("FetchCheckMoves")
+	if m.FetchCheckMovesFunc == nil {
+		err = notMocked("FetchCheckMoves")
+		return
+	}
+	return m.FetchCheckMovesFunc()
+}
+
+// FetchCheckMovesWithContext calls FetchCheckMovesWithContextFunc.
+func (m *Mock) FetchCheckMovesWithContext(ctx context.Context) (r0 *[]apiclient.CheckMove, err error) {
+	m.record("FetchCheckMovesWithContext", ctx)
+	if m.FetchCheckMovesWithContextFunc == nil {
+		err = notMocked("FetchCheckMovesWithContext")
+		return
+	}
+	return m.FetchCheckMovesWithContextFunc(ctx)
+}
+
+// CreateCheckMove calls CreateCheckMoveFunc.
+func (m *Mock) CreateCheckMove(cfg *apiclient.CheckMove) (r0 *apiclient.CheckMove, err error) {
+	m.record("CreateCheckMove", cfg)
+	if m.CreateCheckMoveFunc == nil {
+		err = notMocked("CreateCheckMove")
+		return
+	}
+	return m.CreateCheckMoveFunc(cfg)
+}
+
+// CreateCheckMoveWithContext calls CreateCheckMoveWithContextFunc.
+func (m *Mock) CreateCheckMoveWithContext(ctx context.Context, cfg *apiclient.CheckMove) (r0 *apiclient.CheckMove, err error) {
+	m.record("CreateCheckMoveWithContext", ctx, cfg)
+	if m.CreateCheckMoveWithContextFunc == nil {
+		err = notMocked("CreateCheckMoveWithContext")
+		return
+	}
+	return m.CreateCheckMoveWithContextFunc(ctx, cfg)
+}
+
+// DeleteCheckMove calls DeleteCheckMoveFunc.
+func (m *Mock) DeleteCheckMove(cfg *apiclient.CheckMove) (r0 bool, err error) {
+	m.record("DeleteCheckMove", cfg)
+	if m.DeleteCheckMoveFunc == nil {
+		err = notMocked("DeleteCheckMove")
+		return
+	}
+	return m.DeleteCheckMoveFunc(cfg)
+}
+
+// DeleteCheckMoveWithContext calls DeleteCheckMoveWithContextFunc.
+func (m *Mock) DeleteCheckMoveWithContext(ctx context.Context, cfg *apiclient.CheckMove) (r0 bool, err error) {
+	m.record("DeleteCheckMoveWithContext", ctx, cfg)
+	if m.DeleteCheckMoveWithContextFunc == nil {
+		err = notMocked("DeleteCheckMoveWithContext")
+		return
+	}
+	return m.DeleteCheckMoveWithContextFunc(ctx, cfg)
+}
+
+// DeleteCheckMoveByCID calls DeleteCheckMoveByCIDFunc.
+func (m *Mock) DeleteCheckMoveByCID(cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteCheckMoveByCID", cid)
+	if m.DeleteCheckMoveByCIDFunc == nil {
+		err = notMocked("DeleteCheckMoveByCID")
+		return
+	}
+	return m.DeleteCheckMoveByCIDFunc(cid)
+}
+
+// DeleteCheckMoveByCIDWithContext calls DeleteCheckMoveByCIDWithContextFunc.
+func (m *Mock) DeleteCheckMoveByCIDWithContext(ctx context.Context, cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteCheckMoveByCIDWithContext", ctx, cid)
+	if m.DeleteCheckMoveByCIDWithContextFunc == nil {
+		err = notMocked("DeleteCheckMoveByCIDWithContext")
+		return
+	}
+	return m.DeleteCheckMoveByCIDWithContextFunc(ctx, cid)
+}
+
+// SearchCheckMoves calls SearchCheckMovesFunc.
+func (m *Mock) SearchCheckMoves(filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.CheckMove, err error) {
+	m.record("SearchCheckMoves", filterCriteria)
+	if m.SearchCheckMovesFunc == nil {
+		err = notMocked("SearchCheckMoves")
+		return
+	}
+	return m.SearchCheckMovesFunc(filterCriteria)
+}
+
+// SearchCheckMovesWithContext calls SearchCheckMovesWithContextFunc.
+func (m *Mock) SearchCheckMovesWithContext(ctx context.Context, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.CheckMove, err error) {
+	m.record("SearchCheckMovesWithContext", ctx, filterCriteria)
+	if m.SearchCheckMovesWithContextFunc == nil {
+		err = notMocked("SearchCheckMovesWithContext")
+		return
+	}
+	return m.SearchCheckMovesWithContextFunc(ctx, filterCriteria)
+}
+
+// MoveCheck calls MoveCheckFunc.
+func (m *Mock) MoveCheck(checkCID apiclient.CIDType, brokerCID apiclient.CIDType) (r0 *apiclient.CheckMove, err error) {
+	m.record("MoveCheck", checkCID, brokerCID)
+	if m.MoveCheckFunc == nil {
+		err = notMocked("MoveCheck")
+		return
+	}
+	return m.MoveCheckFunc(checkCID, brokerCID)
+}
+
+// MoveCheckAndWait calls MoveCheckAndWaitFunc.
+func (m *Mock) MoveCheckAndWait(checkCID apiclient.CIDType, brokerCID apiclient.CIDType, timeout time.Duration) (r0 *apiclient.CheckMove, err error) {
+	m.record("MoveCheckAndWait", checkCID, brokerCID, timeout)
+	if m.MoveCheckAndWaitFunc == nil {
+		err = notMocked("MoveCheckAndWait")
+		return
+	}
+	return m.MoveCheckAndWaitFunc(checkCID, brokerCID, timeout)
+}
+
+// FetchCheckTemplate calls FetchCheckTemplateFunc.
+func (m *Mock) FetchCheckTemplate(cid apiclient.CIDType) (r0 *apiclient.CheckTemplate, err error) {
+	m.record("FetchCheckTemplate", cid)
+	if m.FetchCheckTemplateFunc == nil {
+		err = notMocked("FetchCheckTemplate")
+		return
+	}
+	return m.FetchCheckTemplateFunc(cid)
+}
+
+// FetchCheckTemplateWithContext calls FetchCheckTemplateWithContextFunc.
+func (m *Mock) FetchCheckTemplateWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.CheckTemplate, err error) {
+	m.record("FetchCheckTemplateWithContext", ctx, cid)
+	if m.FetchCheckTemplateWithContextFunc == nil {
+		err = notMocked("FetchCheckTemplateWithContext")
+		return
+	}
+	return m.FetchCheckTemplateWithContextFunc(ctx, cid)
+}
+
+// FetchCheckTemplates calls FetchCheckTemplatesFunc.
+func (m *Mock) FetchCheckTemplates() (r0 *[]apiclient.CheckTemplate, err error) {
+	m.record("FetchCheckTemplates")
+	if m.FetchCheckTemplatesFunc == nil {
+		err = notMocked("FetchCheckTemplates")
+		return
+	}
+	return m.FetchCheckTemplatesFunc()
+}
+
+// FetchCheckTemplatesWithContext calls FetchCheckTemplatesWithContextFunc.
+func (m *Mock) FetchCheckTemplatesWithContext(ctx context.Context) (r0 *[]apiclient.CheckTemplate, err error) {
+	m.record("FetchCheckTemplatesWithContext", ctx)
+	if m.FetchCheckTemplatesWithContextFunc == nil {
+		err = notMocked("FetchCheckTemplatesWithContext")
+		return
+	}
+	return m.FetchCheckTemplatesWithContextFunc(ctx)
+}
+
+// UpdateCheckTemplate calls UpdateCheckTemplateFunc.
+func (m *Mock) UpdateCheckTemplate(cfg *apiclient.CheckTemplate) (r0 *apiclient.CheckTemplate, err error) {
+	m.record("UpdateCheckTemplate", cfg)
+	if m.UpdateCheckTemplateFunc == nil {
+		err = notMocked("UpdateCheckTemplate")
+		return
+	}
+	return m.UpdateCheckTemplateFunc(cfg)
+}
+
+// UpdateCheckTemplateWithContext calls UpdateCheckTemplateWithContextFunc.
+func (m *Mock) UpdateCheckTemplateWithContext(ctx context.Context, cfg *apiclient.CheckTemplate) (r0 *apiclient.CheckTemplate, err error) {
+	m.record("UpdateCheckTemplateWithContext", ctx, cfg)
+	if m.UpdateCheckTemplateWithContextFunc == nil {
+		err = notMocked("UpdateCheckTemplateWithContext")
+		return
+	}
+	return m.UpdateCheckTemplateWithContextFunc(ctx, cfg)
+}
+
+// CreateCheckTemplate calls CreateCheckTemplateFunc.
+func (m *Mock) CreateCheckTemplate(cfg *apiclient.CheckTemplate) (r0 *apiclient.CheckTemplate, err error) {
+	m.record("CreateCheckTemplate", cfg)
+	if m.CreateCheckTemplateFunc == nil {
+		err = notMocked("CreateCheckTemplate")
+		return
+	}
+	return m.CreateCheckTemplateFunc(cfg)
+}
+
+// CreateCheckTemplateWithContext calls CreateCheckTemplateWithContextFunc.
+func (m *Mock) CreateCheckTemplateWithContext(ctx context.Context, cfg *apiclient.CheckTemplate) (r0 *apiclient.CheckTemplate, err error) {
+	m.record("CreateCheckTemplateWithContext", ctx, cfg)
+	if m.CreateCheckTemplateWithContextFunc == nil {
+		err = notMocked("CreateCheckTemplateWithContext")
+		return
+	}
+	return m.CreateCheckTemplateWithContextFunc(ctx, cfg)
+}
+
+// DeleteCheckTemplate calls DeleteCheckTemplateFunc.
+func (m *Mock) DeleteCheckTemplate(cfg *apiclient.CheckTemplate) (r0 bool, err error) {
+	m.record("DeleteCheckTemplate", cfg)
+	if m.DeleteCheckTemplateFunc == nil {
+		err = notMocked("DeleteCheckTemplate")
+		return
+	}
+	return m.DeleteCheckTemplateFunc(cfg)
+}
+
+// DeleteCheckTemplateWithContext calls DeleteCheckTemplateWithContextFunc.
+func (m *Mock) DeleteCheckTemplateWithContext(ctx context.Context, cfg *apiclient.CheckTemplate) (r0 bool, err error) {
+	m.record("DeleteCheckTemplateWithContext", ctx, cfg)
+	if m.DeleteCheckTemplateWithContextFunc == nil {
+		err = notMocked("DeleteCheckTemplateWithContext")
+		return
+	}
+	return m.DeleteCheckTemplateWithContextFunc(ctx, cfg)
+}
+
+// DeleteCheckTemplateByCID calls DeleteCheckTemplateByCIDFunc.
+func (m *Mock) DeleteCheckTemplateByCID(cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteCheckTemplateByCID", cid)
+	if m.DeleteCheckTemplateByCIDFunc == nil {
+		err = notMocked("DeleteCheckTemplateByCID")
+		return
+	}
+	return m.DeleteCheckTemplateByCIDFunc(cid)
+}
+
+// DeleteCheckTemplateByCIDWithContext calls DeleteCheckTemplateByCIDWithContextFunc.
+func (m *Mock) DeleteCheckTemplateByCIDWithContext(ctx context.Context, cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteCheckTemplateByCIDWithContext", ctx, cid)
+	if m.DeleteCheckTemplateByCIDWithContextFunc == nil {
+		err = notMocked("DeleteCheckTemplateByCIDWithContext")
+		return
+	}
+	return m.DeleteCheckTemplateByCIDWithContextFunc(ctx, cid)
+}
+
+// SearchCheckTemplates calls SearchCheckTemplatesFunc.
+func (m *Mock) SearchCheckTemplates(searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.CheckTemplate, err error) {
+	m.record("SearchCheckTemplates", searchCriteria, filterCriteria)
+	if m.SearchCheckTemplatesFunc == nil {
+		err = notMocked("SearchCheckTemplates")
+		return
+	}
+	return m.SearchCheckTemplatesFunc(searchCriteria, filterCriteria)
+}
+
+// SearchCheckTemplatesWithContext calls SearchCheckTemplatesWithContextFunc.
+func (m *Mock) SearchCheckTemplatesWithContext(ctx context.Context, searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.CheckTemplate, err error) {
+	m.record("SearchCheckTemplatesWithContext", ctx, searchCriteria, filterCriteria)
+	if m.SearchCheckTemplatesWithContextFunc == nil {
+		err = notMocked("SearchCheckTemplatesWithContext")
+		return
+	}
+	return m.SearchCheckTemplatesWithContextFunc(ctx, searchCriteria, filterCriteria)
+}
+
+// FetchContactGroup calls FetchContactGroupFunc.
+func (m *Mock) FetchContactGroup(cid apiclient.CIDType) (r0 *apiclient.ContactGroup, err error) {
+	m.record("FetchContactGroup", cid)
+	if m.FetchContactGroupFunc == nil {
+		err = notMocked("FetchContactGroup")
+		return
+	}
+	return m.FetchContactGroupFunc(cid)
+}
+
+// FetchContactGroupWithContext calls FetchContactGroupWithContextFunc.
+func (m *Mock) FetchContactGroupWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.ContactGroup, err error) {
+	m.record("FetchContactGroupWithContext", ctx, cid)
+	if m.FetchContactGroupWithContextFunc == nil {
+		err = notMocked("FetchContactGroupWithContext")
+		return
+	}
+	return m.FetchContactGroupWithContextFunc(ctx, cid)
+}
+
+// FetchContactGroups calls FetchContactGroupsFunc.
+func (m *Mock) FetchContactGroups() (r0 *[]apiclient.ContactGroup, err error) {
+	m.record("FetchContactGroups")
+	if m.FetchContactGroupsFunc == nil {
+		err = notMocked("FetchContactGroups")
+		return
+	}
+	return m.FetchContactGroupsFunc()
+}
+
+// FetchContactGroupsWithContext calls FetchContactGroupsWithContextFunc.
+func (m *Mock) FetchContactGroupsWithContext(ctx context.Context) (r0 *[]apiclient.ContactGroup, err error) {
+	m.record("FetchContactGroupsWithContext", ctx)
+	if m.FetchContactGroupsWithContextFunc == nil {
+		err = notMocked("FetchContactGroupsWithContext")
+		return
+	}
+	return m.FetchContactGroupsWithContextFunc(ctx)
+}
+
+// UpdateContactGroup calls UpdateContactGroupFunc.
+func (m *Mock) UpdateContactGroup(cfg *apiclient.ContactGroup) (r0 *apiclient.ContactGroup, err error) {
+	m.record("UpdateContactGroup", cfg)
+	if m.UpdateContactGroupFunc == nil {
+		err = notMocked("UpdateContactGroup")
+		return
+	}
+	return m.UpdateContactGroupFunc(cfg)
+}
+
+// UpdateContactGroupWithContext calls UpdateContactGroupWithContextFunc.
+func (m *Mock) UpdateContactGroupWithContext(ctx context.Context, cfg *apiclient.ContactGroup) (r0 *apiclient.ContactGroup, err error) {
+	m.record("UpdateContactGroupWithContext", ctx, cfg)
+	if m.UpdateContactGroupWithContextFunc == nil {
+		err = notMocked("UpdateContactGroupWithContext")
+		return
+	}
+	return m.UpdateContactGroupWithContextFunc(ctx, cfg)
+}
+
+// CreateContactGroup calls CreateContactGroupFunc.
+func (m *Mock) CreateContactGroup(cfg *apiclient.ContactGroup) (r0 *apiclient.ContactGroup, err error) {
+	m.record("CreateContactGroup", cfg)
+	if m.CreateContactGroupFunc == nil {
+		err = notMocked("CreateContactGroup")
+		return
+	}
+	return m.CreateContactGroupFunc(cfg)
+}
+
+// CreateContactGroupWithContext calls CreateContactGroupWithContextFunc.
+func (m *Mock) CreateContactGroupWithContext(ctx context.Context, cfg *apiclient.ContactGroup) (r0 *apiclient.ContactGroup, err error) {
+	m.record("CreateContactGroupWithContext", ctx, cfg)
+	if m.CreateContactGroupWithContextFunc == nil {
+		err = notMocked("CreateContactGroupWithContext")
+		return
+	}
+	return m.CreateContactGroupWithContextFunc(ctx, cfg)
+}
+
+// DeleteContactGroup calls DeleteContactGroupFunc.
+func (m *Mock) DeleteContactGroup(cfg *apiclient.ContactGroup) (r0 bool, err error) {
+	m.record("DeleteContactGroup", cfg)
+	if m.DeleteContactGroupFunc == nil {
+		err = notMocked("DeleteContactGroup")
+		return
+	}
+	return m.DeleteContactGroupFunc(cfg)
+}
+
+// DeleteContactGroupWithContext calls DeleteContactGroupWithContextFunc.
+func (m *Mock) DeleteContactGroupWithContext(ctx context.Context, cfg *apiclient.ContactGroup) (r0 bool, err error) {
+	m.record("DeleteContactGroupWithContext", ctx, cfg)
+	if m.DeleteContactGroupWithContextFunc == nil {
+		err = notMocked("DeleteContactGroupWithContext")
+		return
+	}
+	return m.DeleteContactGroupWithContextFunc(ctx, cfg)
+}
+
+// DeleteContactGroupByCID calls DeleteContactGroupByCIDFunc.
+func (m *Mock) DeleteContactGroupByCID(cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteContactGroupByCID", cid)
+	if m.DeleteContactGroupByCIDFunc == nil {
+		err = notMocked("DeleteContactGroupByCID")
+		return
+	}
+	return m.DeleteContactGroupByCIDFunc(cid)
+}
+
+// DeleteContactGroupByCIDWithContext calls DeleteContactGroupByCIDWithContextFunc.
+func (m *Mock) DeleteContactGroupByCIDWithContext(ctx context.Context, cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteContactGroupByCIDWithContext", ctx, cid)
+	if m.DeleteContactGroupByCIDWithContextFunc == nil {
+		err = notMocked("DeleteContactGroupByCIDWithContext")
+		return
+	}
+	return m.DeleteContactGroupByCIDWithContextFunc(ctx, cid)
+}
+
+// SearchContactGroups calls SearchContactGroupsFunc.
+func (m *Mock) SearchContactGroups(searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.ContactGroup, err error) {
+	m.record("SearchContactGroups", searchCriteria, filterCriteria)
+	if m.SearchContactGroupsFunc == nil {
+		err = notMocked("SearchContactGroups")
+		return
+	}
+	return m.SearchContactGroupsFunc(searchCriteria, filterCriteria)
+}
+
+// SearchContactGroupsWithContext calls SearchContactGroupsWithContextFunc.
+func (m *Mock) SearchContactGroupsWithContext(ctx context.Context, searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.ContactGroup, err error) {
+	m.record("SearchContactGroupsWithContext", ctx, searchCriteria, filterCriteria)
+	if m.SearchContactGroupsWithContextFunc == nil {
+		err = notMocked("SearchContactGroupsWithContext")
+		return
+	}
+	return m.SearchContactGroupsWithContextFunc(ctx, searchCriteria, filterCriteria)
+}
+
+// FetchDashboard calls FetchDashboardFunc.
+func (m *Mock) FetchDashboard(cid apiclient.CIDType) (r0 *apiclient.Dashboard, err error) {
+	m.record("FetchDashboard", cid)
+	if m.FetchDashboardFunc == nil {
+		err = notMocked("FetchDashboard")
+		return
+	}
+	return m.FetchDashboardFunc(cid)
+}
+
+// FetchDashboardWithContext calls FetchDashboardWithContextFunc.
+func (m *Mock) FetchDashboardWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.Dashboard, err error) {
+	m.record("FetchDashboardWithContext", ctx, cid)
+	if m.FetchDashboardWithContextFunc == nil {
+		err = notMocked("FetchDashboardWithContext")
+		return
+	}
+	return m.FetchDashboardWithContextFunc(ctx, cid)
+}
+
+// FetchDashboards calls FetchDashboardsFunc.
+func (m *Mock) FetchDashboards() (r0 *[]apiclient.Dashboard, err error) {
+	m.record("FetchDashboards")
+	if m.FetchDashboardsFunc == nil {
+		err = notMocked("FetchDashboards")
+		return
+	}
+	return m.FetchDashboardsFunc()
+}
+
+// FetchDashboardsWithContext calls FetchDashboardsWithContextFunc.
+func (m *Mock) FetchDashboardsWithContext(ctx context.Context) (r0 *[]apiclient.Dashboard, err error) {
+	m.record("FetchDashboardsWithContext", ctx)
+	if m.FetchDashboardsWithContextFunc == nil {
+		err = notMocked("FetchDashboardsWithContext")
+		return
+	}
+	return m.FetchDashboardsWithContextFunc(ctx)
+}
+
+// UpdateDashboard calls UpdateDashboardFunc.
+func (m *Mock) UpdateDashboard(cfg *apiclient.Dashboard) (r0 *apiclient.Dashboard, err error) {
+	m.record("UpdateDashboard", cfg)
+	if m.UpdateDashboardFunc == nil {
+		err = notMocked("UpdateDashboard")
+		return
+	}
+	return m.UpdateDashboardFunc(cfg)
+}
+
+// UpdateDashboardWithContext calls UpdateDashboardWithContextFunc.
+func (m *Mock) UpdateDashboardWithContext(ctx context.Context, cfg *apiclient.Dashboard) (r0 *apiclient.Dashboard, err error) {
+	m.record("UpdateDashboardWithContext", ctx, cfg)
+	if m.UpdateDashboardWithContextFunc == nil {
+		err = notMocked("UpdateDashboardWithContext")
+		return
+	}
+	return m.UpdateDashboardWithContextFunc(ctx, cfg)
+}
+
+// CreateDashboard calls CreateDashboardFunc.
+func (m *Mock) CreateDashboard(cfg *apiclient.Dashboard) (r0 *apiclient.Dashboard, err error) {
+	m.record("CreateDashboard", cfg)
+	if m.CreateDashboardFunc == nil {
+		err = notMocked("CreateDashboard")
+		return
+	}
+	return m.CreateDashboardFunc(cfg)
+}
+
+// CreateDashboardWithContext calls CreateDashboardWithContextFunc.
+func (m *Mock) CreateDashboardWithContext(ctx context.Context, cfg *apiclient.Dashboard) (r0 *apiclient.Dashboard, err error) {
+	m.record("CreateDashboardWithContext", ctx, cfg)
+	if m.CreateDashboardWithContextFunc == nil {
+		err = notMocked("CreateDashboardWithContext")
+		return
+	}
+	return m.CreateDashboardWithContextFunc(ctx, cfg)
+}
+
+// DeleteDashboard calls DeleteDashboardFunc.
+func (m *Mock) DeleteDashboard(cfg *apiclient.Dashboard) (r0 bool, err error) {
+	m.record("DeleteDashboard", cfg)
+	if m.DeleteDashboardFunc == nil {
+		err = notMocked("DeleteDashboard")
+		return
+	}
+	return m.DeleteDashboardFunc(cfg)
+}
+
+// DeleteDashboardWithContext calls DeleteDashboardWithContextFunc.
+func (m *Mock) DeleteDashboardWithContext(ctx context.Context, cfg *apiclient.Dashboard) (r0 bool, err error) {
+	m.record("DeleteDashboardWithContext", ctx, cfg)
+	if m.DeleteDashboardWithContextFunc == nil {
+		err = notMocked("DeleteDashboardWithContext")
+		return
+	}
+	return m.DeleteDashboardWithContextFunc(ctx, cfg)
+}
+
+// DeleteDashboardByCID calls DeleteDashboardByCIDFunc.
+func (m *Mock) DeleteDashboardByCID(cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteDashboardByCID", cid)
+	if m.DeleteDashboardByCIDFunc == nil {
+		err = notMocked("DeleteDashboardByCID")
+		return
+	}
+	return m.DeleteDashboardByCIDFunc(cid)
+}
+
+// DeleteDashboardByCIDWithContext calls DeleteDashboardByCIDWithContextFunc.
+func (m *Mock) DeleteDashboardByCIDWithContext(ctx context.Context, cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteDashboardByCIDWithContext", ctx, cid)
+	if m.DeleteDashboardByCIDWithContextFunc == nil {
+		err = notMocked("DeleteDashboardByCIDWithContext")
+		return
+	}
+	return m.DeleteDashboardByCIDWithContextFunc(ctx, cid)
+}
+
+// SearchDashboards calls SearchDashboardsFunc.
+func (m *Mock) SearchDashboards(searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Dashboard, err error) {
+	m.record("SearchDashboards", searchCriteria, filterCriteria)
+	if m.SearchDashboardsFunc == nil {
+		err = notMocked("SearchDashboards")
+		return
+	}
+	return m.SearchDashboardsFunc(searchCriteria, filterCriteria)
+}
+
+// SearchDashboardsWithContext calls SearchDashboardsWithContextFunc.
+func (m *Mock) SearchDashboardsWithContext(ctx context.Context, searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Dashboard, err error) {
+	m.record("SearchDashboardsWithContext", ctx, searchCriteria, filterCriteria)
+	if m.SearchDashboardsWithContextFunc == nil {
+		err = notMocked("SearchDashboardsWithContext")
+		return
+	}
+	return m.SearchDashboardsWithContextFunc(ctx, searchCriteria, filterCriteria)
+}
+
+// FetchData calls FetchDataFunc.
+func (m *Mock) FetchData(checkCID apiclient.CIDType, metricName string, start time.Time, end time.Time, period time.Duration) (r0 *apiclient.Data, err error) {
+	m.record("FetchData", checkCID, metricName, start, end, period)
+	if m.FetchDataFunc == nil {
+		err = notMocked("FetchData")
+		return
+	}
+	return m.FetchDataFunc(checkCID, metricName, start, end, period)
+}
+
+// FetchDataWithContext calls FetchDataWithContextFunc.
+func (m *Mock) FetchDataWithContext(ctx context.Context, checkCID apiclient.CIDType, metricName string, start time.Time, end time.Time, period time.Duration) (r0 *apiclient.Data, err error) {
+	m.record("FetchDataWithContext", ctx, checkCID, metricName, start, end, period)
+	if m.FetchDataWithContextFunc == nil {
+		err = notMocked("FetchDataWithContext")
+		return
+	}
+	return m.FetchDataWithContextFunc(ctx, checkCID, metricName, start, end, period)
+}
+
+// FetchDataWithOptions calls FetchDataWithOptionsFunc.
+func (m *Mock) FetchDataWithOptions(checkCID apiclient.CIDType, metricName string, opts *apiclient.DataOptions) (r0 *apiclient.Data, err error) {
+	m.record("FetchDataWithOptions", checkCID, metricName, opts)
+	if m.FetchDataWithOptionsFunc == nil {
+		err = notMocked("FetchDataWithOptions")
+		return
+	}
+	return m.FetchDataWithOptionsFunc(checkCID, metricName, opts)
+}
+
+// FetchDataWithOptionsWithContext calls FetchDataWithOptionsWithContextFunc.
+func (m *Mock) FetchDataWithOptionsWithContext(ctx context.Context, checkCID apiclient.CIDType, metricName string, opts *apiclient.DataOptions) (r0 *apiclient.Data, err error) {
+	m.record("FetchDataWithOptionsWithContext", ctx, checkCID, metricName, opts)
+	if m.FetchDataWithOptionsWithContextFunc == nil {
+		err = notMocked("FetchDataWithOptionsWithContext")
+		return
+	}
+	return m.FetchDataWithOptionsWithContextFunc(ctx, checkCID, metricName, opts)
+}
+
+// FetchHistogramData calls FetchHistogramDataFunc.
+func (m *Mock) FetchHistogramData(checkCID apiclient.CIDType, metricName string, start time.Time, end time.Time, period time.Duration) (r0 *apiclient.HistogramData, err error) {
+	m.record("FetchHistogramData", checkCID, metricName, start, end, period)
+	if m.FetchHistogramDataFunc == nil {
+		err = notMocked("FetchHistogramData")
+		return
+	}
+	return m.FetchHistogramDataFunc(checkCID, metricName, start, end, period)
+}
+
+// FetchHistogramDataWithContext calls FetchHistogramDataWithContextFunc.
+func (m *Mock) FetchHistogramDataWithContext(ctx context.Context, checkCID apiclient.CIDType, metricName string, start time.Time, end time.Time, period time.Duration) (r0 *apiclient.HistogramData, err error) {
+	m.record("FetchHistogramDataWithContext", ctx, checkCID, metricName, start, end, period)
+	if m.FetchHistogramDataWithContextFunc == nil {
+		err = notMocked("FetchHistogramDataWithContext")
+		return
+	}
+	return m.FetchHistogramDataWithContextFunc(ctx, checkCID, metricName, start, end, period)
+}
+
+// FetchHistogramDataWithOptions calls FetchHistogramDataWithOptionsFunc.
+func (m *Mock) FetchHistogramDataWithOptions(checkCID apiclient.CIDType, metricName string, opts *apiclient.DataOptions) (r0 *apiclient.HistogramData, err error) {
+	m.record("FetchHistogramDataWithOptions", checkCID, metricName, opts)
+	if m.FetchHistogramDataWithOptionsFunc == nil {
+		err = notMocked("FetchHistogramDataWithOptions")
+		return
+	}
+	return m.FetchHistogramDataWithOptionsFunc(checkCID, metricName, opts)
+}
+
+// FetchHistogramDataWithOptionsWithContext calls FetchHistogramDataWithOptionsWithContextFunc.
+func (m *Mock) FetchHistogramDataWithOptionsWithContext(ctx context.Context, checkCID apiclient.CIDType, metricName string, opts *apiclient.DataOptions) (r0 *apiclient.HistogramData, err error) {
+	m.record("FetchHistogramDataWithOptionsWithContext", ctx, checkCID, metricName, opts)
+	if m.FetchHistogramDataWithOptionsWithContextFunc == nil {
+		err = notMocked("FetchHistogramDataWithOptionsWithContext")
+		return
+	}
+	return m.FetchHistogramDataWithOptionsWithContextFunc(ctx, checkCID, metricName, opts)
+}
+
+// FetchGraph calls FetchGraphFunc.
+func (m *Mock) FetchGraph(cid apiclient.CIDType) (r0 *apiclient.Graph, err error) {
+	m.record("FetchGraph", cid)
+	if m.FetchGraphFunc == nil {
+		err = notMocked("FetchGraph")
+		return
+	}
+	return m.FetchGraphFunc(cid)
+}
+
+// FetchGraphWithContext calls FetchGraphWithContextFunc.
+func (m *Mock) FetchGraphWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.Graph, err error) {
+	m.record("FetchGraphWithContext", ctx, cid)
+	if m.FetchGraphWithContextFunc == nil {
+		err = notMocked("FetchGraphWithContext")
+		return
+	}
+	return m.FetchGraphWithContextFunc(ctx, cid)
+}
+
+// FetchGraphs calls FetchGraphsFunc.
+func (m *Mock) FetchGraphs() (r0 *[]apiclient.Graph, err error) {
+	m.record("FetchGraphs")
+	if m.FetchGraphsFunc == nil {
+		err = notMocked("FetchGraphs")
+		return
+	}
+	return m.FetchGraphsFunc()
+}
+
+// FetchGraphsWithContext calls FetchGraphsWithContextFunc.
+func (m *Mock) FetchGraphsWithContext(ctx context.Context) (r0 *[]apiclient.Graph, err error) {
+	m.record("FetchGraphsWithContext", ctx)
+	if m.FetchGraphsWithContextFunc == nil {
+		err = notMocked("FetchGraphsWithContext")
+		return
+	}
+	return m.FetchGraphsWithContextFunc(ctx)
+}
+
+// UpdateGraph calls UpdateGraphFunc.
+func (m *Mock) UpdateGraph(cfg *apiclient.Graph) (r0 *apiclient.Graph, err error) {
+	m.record("UpdateGraph", cfg)
+	if m.UpdateGraphFunc == nil {
+		err = notMocked("UpdateGraph")
+		return
+	}
+	return m.UpdateGraphFunc(cfg)
+}
+
+// UpdateGraphWithContext calls UpdateGraphWithContextFunc.
+func (m *Mock) UpdateGraphWithContext(ctx context.Context, cfg *apiclient.Graph) (r0 *apiclient.Graph, err error) {
+	m.record("UpdateGraphWithContext", ctx, cfg)
+	if m.UpdateGraphWithContextFunc == nil {
+		err = notMocked("UpdateGraphWithContext")
+		return
+	}
+	return m.UpdateGraphWithContextFunc(ctx, cfg)
+}
+
+// CreateGraph calls CreateGraphFunc.
+func (m *Mock) CreateGraph(cfg *apiclient.Graph) (r0 *apiclient.Graph, err error) {
+	m.record("CreateGraph", cfg)
+	if m.CreateGraphFunc == nil {
+		err = notMocked("CreateGraph")
+		return
+	}
+	return m.CreateGraphFunc(cfg)
+}
+
+// CreateGraphWithContext calls CreateGraphWithContextFunc.
+func (m *Mock) CreateGraphWithContext(ctx context.Context, cfg *apiclient.Graph) (r0 *apiclient.Graph, err error) {
+	m.record("CreateGraphWithContext", ctx, cfg)
+	if m.CreateGraphWithContextFunc == nil {
+		err = notMocked("CreateGraphWithContext")
+		return
+	}
+	return m.CreateGraphWithContextFunc(ctx, cfg)
+}
+
+// DeleteGraph calls DeleteGraphFunc.
+func (m *Mock) DeleteGraph(cfg *apiclient.Graph) (r0 bool, err error) {
+	m.record("DeleteGraph", cfg)
+	if m.DeleteGraphFunc == nil {
+		err = notMocked("DeleteGraph")
+		return
+	}
+	return m.DeleteGraphFunc(cfg)
+}
+
+// DeleteGraphWithContext calls DeleteGraphWithContextFunc.
+func (m *Mock) DeleteGraphWithContext(ctx context.Context, cfg *apiclient.Graph) (r0 bool, err error) {
+	m.record("DeleteGraphWithContext", ctx, cfg)
+	if m.DeleteGraphWithContextFunc == nil {
+		err = notMocked("DeleteGraphWithContext")
+		return
+	}
+	return m.DeleteGraphWithContextFunc(ctx, cfg)
+}
+
+// DeleteGraphByCID calls DeleteGraphByCIDFunc.
+func (m *Mock) DeleteGraphByCID(cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteGraphByCID", cid)
+	if m.DeleteGraphByCIDFunc == nil {
+		err = notMocked("DeleteGraphByCID")
+		return
+	}
+	return m.DeleteGraphByCIDFunc(cid)
+}
+
+// DeleteGraphByCIDWithContext calls DeleteGraphByCIDWithContextFunc.
+func (m *Mock) DeleteGraphByCIDWithContext(ctx context.Context, cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteGraphByCIDWithContext", ctx, cid)
+	if m.DeleteGraphByCIDWithContextFunc == nil {
+		err = notMocked("DeleteGraphByCIDWithContext")
+		return
+	}
+	return m.DeleteGraphByCIDWithContextFunc(ctx, cid)
+}
+
+// SearchGraphs calls SearchGraphsFunc.
+func (m *Mock) SearchGraphs(searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Graph, err error) {
+	m.record("SearchGraphs", searchCriteria, filterCriteria)
+	if m.SearchGraphsFunc == nil {
+		err = notMocked("SearchGraphs")
+		return
+	}
+	return m.SearchGraphsFunc(searchCriteria, filterCriteria)
+}
+
+// SearchGraphsWithContext calls SearchGraphsWithContextFunc.
+func (m *Mock) SearchGraphsWithContext(ctx context.Context, searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Graph, err error) {
+	m.record("SearchGraphsWithContext", ctx, searchCriteria, filterCriteria)
+	if m.SearchGraphsWithContextFunc == nil {
+		err = notMocked("SearchGraphsWithContext")
+		return
+	}
+	return m.SearchGraphsWithContextFunc(ctx, searchCriteria, filterCriteria)
+}
+
+// FetchMaintenanceWindow calls FetchMaintenanceWindowFunc.
+func (m *Mock) FetchMaintenanceWindow(cid apiclient.CIDType) (r0 *apiclient.Maintenance, err error) {
+	m.record("FetchMaintenanceWindow", cid)
+	if m.FetchMaintenanceWindowFunc == nil {
+		err = notMocked("FetchMaintenanceWindow")
+		return
+	}
+	return m.FetchMaintenanceWindowFunc(cid)
+}
+
+// FetchMaintenanceWindowWithContext calls FetchMaintenanceWindowWithContextFunc.
+func (m *Mock) FetchMaintenanceWindowWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.Maintenance, err error) {
+	m.record("FetchMaintenanceWindowWithContext", ctx, cid)
+	if m.FetchMaintenanceWindowWithContextFunc == nil {
+		err = notMocked("FetchMaintenanceWindowWithContext")
+		return
+	}
+	return m.FetchMaintenanceWindowWithContextFunc(ctx, cid)
+}
+
+// FetchMaintenanceWindows calls FetchMaintenanceWindowsFunc.
+func (m *Mock) FetchMaintenanceWindows() (r0 *[]apiclient.Maintenance, err error) {
+	m.record("FetchMaintenanceWindows")
+	if m.FetchMaintenanceWindowsFunc == nil {
+		err = notMocked("FetchMaintenanceWindows")
+		return
+	}
+	return m.FetchMaintenanceWindowsFunc()
+}
+
+// FetchMaintenanceWindowsWithContext calls FetchMaintenanceWindowsWithContextFunc.
+func (m *Mock) FetchMaintenanceWindowsWithContext(ctx context.Context) (r0 *[]apiclient.Maintenance, err error) {
+	m.record("FetchMaintenanceWindowsWithContext", ctx)
+	if m.FetchMaintenanceWindowsWithContextFunc == nil {
+		err = notMocked("FetchMaintenanceWindowsWithContext")
+		return
+	}
+	return m.FetchMaintenanceWindowsWithContextFunc(ctx)
+}
+
+// UpdateMaintenanceWindow calls UpdateMaintenanceWindowFunc.
+func (m *Mock) UpdateMaintenanceWindow(cfg *apiclient.Maintenance) (r0 *apiclient.Maintenance, err error) {
+	m.record("UpdateMaintenanceWindow", cfg)
+	if m.UpdateMaintenanceWindowFunc == nil {
+		err = notMocked("UpdateMaintenanceWindow")
+		return
+	}
+	return m.UpdateMaintenanceWindowFunc(cfg)
+}
+
+// UpdateMaintenanceWindowWithContext calls UpdateMaintenanceWindowWithContextFunc.
+func (m *Mock) UpdateMaintenanceWindowWithContext(ctx context.Context, cfg *apiclient.Maintenance) (r0 *apiclient.Maintenance, err error) {
+	m.record("UpdateMaintenanceWindowWithContext", ctx, cfg)
+	if m.UpdateMaintenanceWindowWithContextFunc == nil {
+		err = notMocked("UpdateMaintenanceWindowWithContext")
+		return
+	}
+	return m.UpdateMaintenanceWindowWithContextFunc(ctx, cfg)
+}
+
+// CreateMaintenanceWindow calls CreateMaintenanceWindowFunc.
+func (m *Mock) CreateMaintenanceWindow(cfg *apiclient.Maintenance) (r0 *apiclient.Maintenance, err error) {
+	m.record("CreateMaintenanceWindow", cfg)
+	if m.CreateMaintenanceWindowFunc == nil {
+		err = notMocked("CreateMaintenanceWindow")
+		return
+	}
+	return m.CreateMaintenanceWindowFunc(cfg)
+}
+
+// CreateMaintenanceWindowWithContext calls CreateMaintenanceWindowWithContextFunc.
+func (m *Mock) CreateMaintenanceWindowWithContext(ctx context.Context, cfg *apiclient.Maintenance) (r0 *apiclient.Maintenance, err error) {
+	m.record("CreateMaintenanceWindowWithContext", ctx, cfg)
+	if m.CreateMaintenanceWindowWithContextFunc == nil {
+		err = notMocked("CreateMaintenanceWindowWithContext")
+		return
+	}
+	return m.CreateMaintenanceWindowWithContextFunc(ctx, cfg)
+}
+
+// DeleteMaintenanceWindow calls DeleteMaintenanceWindowFunc.
+func (m *Mock) DeleteMaintenanceWindow(cfg *apiclient.Maintenance) (r0 bool, err error) {
+	m.record("DeleteMaintenanceWindow", cfg)
+	if m.DeleteMaintenanceWindowFunc == nil {
+		err = notMocked("DeleteMaintenanceWindow")
+		return
+	}
+	return m.DeleteMaintenanceWindowFunc(cfg)
+}
+
+// DeleteMaintenanceWindowWithContext calls DeleteMaintenanceWindowWithContextFunc.
+func (m *Mock) DeleteMaintenanceWindowWithContext(ctx context.Context, cfg *apiclient.Maintenance) (r0 bool, err error) {
+	m.record("DeleteMaintenanceWindowWithContext", ctx, cfg)
+	if m.DeleteMaintenanceWindowWithContextFunc == nil {
+		err = notMocked("DeleteMaintenanceWindowWithContext")
+		return
+	}
+	return m.DeleteMaintenanceWindowWithContextFunc(ctx, cfg)
+}
+
+// DeleteMaintenanceWindowByCID calls DeleteMaintenanceWindowByCIDFunc.
+func (m *Mock) DeleteMaintenanceWindowByCID(cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteMaintenanceWindowByCID", cid)
+	if m.DeleteMaintenanceWindowByCIDFunc == nil {
+		err = notMocked("DeleteMaintenanceWindowByCID")
+		return
+	}
+	return m.DeleteMaintenanceWindowByCIDFunc(cid)
+}
+
+// DeleteMaintenanceWindowByCIDWithContext calls DeleteMaintenanceWindowByCIDWithContextFunc.
+func (m *Mock) DeleteMaintenanceWindowByCIDWithContext(ctx context.Context, cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteMaintenanceWindowByCIDWithContext", ctx, cid)
+	if m.DeleteMaintenanceWindowByCIDWithContextFunc == nil {
+		err = notMocked("DeleteMaintenanceWindowByCIDWithContext")
+		return
+	}
+	return m.DeleteMaintenanceWindowByCIDWithContextFunc(ctx, cid)
+}
+
+// SearchMaintenanceWindows calls SearchMaintenanceWindowsFunc.
+func (m *Mock) SearchMaintenanceWindows(searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Maintenance, err error) {
+	m.record("SearchMaintenanceWindows", searchCriteria, filterCriteria)
+	if m.SearchMaintenanceWindowsFunc == nil {
+		err = notMocked("SearchMaintenanceWindows")
+		return
+	}
+	return m.SearchMaintenanceWindowsFunc(searchCriteria, filterCriteria)
+}
+
+// SearchMaintenanceWindowsWithContext calls SearchMaintenanceWindowsWithContextFunc.
+func (m *Mock) SearchMaintenanceWindowsWithContext(ctx context.Context, searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Maintenance, err error) {
+	m.record("SearchMaintenanceWindowsWithContext", ctx, searchCriteria, filterCriteria)
+	if m.SearchMaintenanceWindowsWithContextFunc == nil {
+		err = notMocked("SearchMaintenanceWindowsWithContext")
+		return
+	}
+	return m.SearchMaintenanceWindowsWithContextFunc(ctx, searchCriteria, filterCriteria)
+}
+
+// FetchMetric calls FetchMetricFunc.
+func (m *Mock) FetchMetric(cid apiclient.CIDType) (r0 *apiclient.Metric, err error) {
+	m.record("FetchMetric", cid)
+	if m.FetchMetricFunc == nil {
+		err = notMocked("FetchMetric")
+		return
+	}
+	return m.FetchMetricFunc(cid)
+}
+
+// FetchMetricWithContext calls FetchMetricWithContextFunc.
+func (m *Mock) FetchMetricWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.Metric, err error) {
+	m.record("FetchMetricWithContext", ctx, cid)
+	if m.FetchMetricWithContextFunc == nil {
+		err = notMocked("FetchMetricWithContext")
+		return
+	}
+	return m.FetchMetricWithContextFunc(ctx, cid)
+}
+
+// FetchMetrics calls FetchMetricsFunc.
+func (m *Mock) FetchMetrics() (r0 *[]apiclient.Metric, err error) {
+	m.record("FetchMetrics")
+	if m.FetchMetricsFunc == nil {
+		err = notMocked("FetchMetrics")
+		return
+	}
+	return m.FetchMetricsFunc()
+}
+
+// FetchMetricsWithContext calls FetchMetricsWithContextFunc.
+func (m *Mock) FetchMetricsWithContext(ctx context.Context) (r0 *[]apiclient.Metric, err error) {
+	m.record("FetchMetricsWithContext", ctx)
+	if m.FetchMetricsWithContextFunc == nil {
+		err = notMocked("FetchMetricsWithContext")
+		return
+	}
+	return m.FetchMetricsWithContextFunc(ctx)
+}
+
+// UpdateMetric calls UpdateMetricFunc.
+func (m *Mock) UpdateMetric(cfg *apiclient.Metric) (r0 *apiclient.Metric, err error) {
+	m.record("UpdateMetric", cfg)
+	if m.UpdateMetricFunc == nil {
+		err = notMocked("UpdateMetric")
+		return
+	}
+	return m.UpdateMetricFunc(cfg)
+}
+
+// UpdateMetricWithContext calls UpdateMetricWithContextFunc.
+func (m *Mock) UpdateMetricWithContext(ctx context.Context, cfg *apiclient.Metric) (r0 *apiclient.Metric, err error) {
+	m.record("UpdateMetricWithContext", ctx, cfg)
+	if m.UpdateMetricWithContextFunc == nil {
+		err = notMocked("UpdateMetricWithContext")
+		return
+	}
+	return m.UpdateMetricWithContextFunc(ctx, cfg)
+}
+
+// SearchMetrics calls SearchMetricsFunc.
+func (m *Mock) SearchMetrics(searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Metric, err error) {
+	m.record("SearchMetrics", searchCriteria, filterCriteria)
+	if m.SearchMetricsFunc == nil {
+		err = notMocked("SearchMetrics")
+		return
+	}
+	return m.SearchMetricsFunc(searchCriteria, filterCriteria)
+}
+
+// SearchMetricsWithContext calls SearchMetricsWithContextFunc.
+func (m *Mock) SearchMetricsWithContext(ctx context.Context, searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Metric, err error) {
+	m.record("SearchMetricsWithContext", ctx, searchCriteria, filterCriteria)
+	if m.SearchMetricsWithContextFunc == nil {
+		err = notMocked("SearchMetricsWithContext")
+		return
+	}
+	return m.SearchMetricsWithContextFunc(ctx, searchCriteria, filterCriteria)
+}
+
+// FetchMetricCluster calls FetchMetricClusterFunc.
+func (m *Mock) FetchMetricCluster(cid apiclient.CIDType, extras string) (r0 *apiclient.MetricCluster, err error) {
+	m.record("FetchMetricCluster", cid, extras)
+	if m.FetchMetricClusterFunc == nil {
+		err = notMocked("FetchMetricCluster")
+		return
+	}
+	return m.FetchMetricClusterFunc(cid, extras)
+}
+
+// FetchMetricClusterWithContext calls FetchMetricClusterWithContextFunc.
+func (m *Mock) FetchMetricClusterWithContext(ctx context.Context, cid apiclient.CIDType, extras string) (r0 *apiclient.MetricCluster, err error) {
+	m.record("FetchMetricClusterWithContext", ctx, cid, extras)
+	if m.FetchMetricClusterWithContextFunc == nil {
+		err = notMocked("FetchMetricClusterWithContext")
+		return
+	}
+	return m.FetchMetricClusterWithContextFunc(ctx, cid, extras)
+}
+
+// FetchMetricClusters calls FetchMetricClustersFunc.
+func (m *Mock) FetchMetricClusters(extras string) (r0 *[]apiclient.MetricCluster, err error) {
+	m.record("FetchMetricClusters", extras)
+	if m.FetchMetricClustersFunc == nil {
+		err = notMocked("FetchMetricClusters")
+		return
+	}
+	return m.FetchMetricClustersFunc(extras)
+}
+
+// FetchMetricClustersWithContext calls FetchMetricClustersWithContextFunc.
+func (m *Mock) FetchMetricClustersWithContext(ctx context.Context, extras string) (r0 *[]apiclient.MetricCluster, err error) {
+	m.record("FetchMetricClustersWithContext", ctx, extras)
+	if m.FetchMetricClustersWithContextFunc == nil {
+		err = notMocked("FetchMetricClustersWithContext")
+		return
+	}
+	return m.FetchMetricClustersWithContextFunc(ctx, extras)
+}
+
+// UpdateMetricCluster calls UpdateMetricClusterFunc.
+func (m *Mock) UpdateMetricCluster(cfg *apiclient.MetricCluster) (r0 *apiclient.MetricCluster, err error) {
+	m.record("UpdateMetricCluster", cfg)
+	if m.UpdateMetricClusterFunc == nil {
+		err = notMocked("UpdateMetricCluster")
+		return
+	}
+	return m.UpdateMetricClusterFunc(cfg)
+}
+
+// UpdateMetricClusterWithContext calls UpdateMetricClusterWithContextFunc.
+func (m *Mock) UpdateMetricClusterWithContext(ctx context.Context, cfg *apiclient.MetricCluster) (r0 *apiclient.MetricCluster, err error) {
+	m.record("UpdateMetricClusterWithContext", ctx, cfg)
+	if m.UpdateMetricClusterWithContextFunc == nil {
+		err = notMocked("UpdateMetricClusterWithContext")
+		return
+	}
+	return m.UpdateMetricClusterWithContextFunc(ctx, cfg)
+}
+
+// CreateMetricCluster calls CreateMetricClusterFunc.
+func (m *Mock) CreateMetricCluster(cfg *apiclient.MetricCluster) (r0 *apiclient.MetricCluster, err error) {
+	m.record("CreateMetricCluster", cfg)
+	if m.CreateMetricClusterFunc == nil {
+		err = notMocked("CreateMetricCluster")
+		return
+	}
+	return m.CreateMetricClusterFunc(cfg)
+}
+
+// CreateMetricClusterWithContext calls CreateMetricClusterWithContextFunc.
+func (m *Mock) CreateMetricClusterWithContext(ctx context.Context, cfg *apiclient.MetricCluster) (r0 *apiclient.MetricCluster, err error) {
+	m.record("CreateMetricClusterWithContext", ctx, cfg)
+	if m.CreateMetricClusterWithContextFunc == nil {
+		err = notMocked("CreateMetricClusterWithContext")
+		return
+	}
+	return m.CreateMetricClusterWithContextFunc(ctx, cfg)
+}
+
+// DeleteMetricCluster calls DeleteMetricClusterFunc.
+func (m *Mock) DeleteMetricCluster(cfg *apiclient.MetricCluster) (r0 bool, err error) {
+	m.record("DeleteMetricCluster", cfg)
+	if m.DeleteMetricClusterFunc == nil {
+		err = notMocked("DeleteMetricCluster")
+		return
+	}
+	return m.DeleteMetricClusterFunc(cfg)
+}
+
+// DeleteMetricClusterWithContext calls DeleteMetricClusterWithContextFunc.
+func (m *Mock) DeleteMetricClusterWithContext(ctx context.Context, cfg *apiclient.MetricCluster) (r0 bool, err error) {
+	m.record("DeleteMetricClusterWithContext", ctx, cfg)
+	if m.DeleteMetricClusterWithContextFunc == nil {
+		err = notMocked("DeleteMetricClusterWithContext")
+		return
+	}
+	return m.DeleteMetricClusterWithContextFunc(ctx, cfg)
+}
+
+// DeleteMetricClusterByCID calls DeleteMetricClusterByCIDFunc.
+func (m *Mock) DeleteMetricClusterByCID(cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteMetricClusterByCID", cid)
+	if m.DeleteMetricClusterByCIDFunc == nil {
+		err = notMocked("DeleteMetricClusterByCID")
+		return
+	}
+	return m.DeleteMetricClusterByCIDFunc(cid)
+}
+
+// DeleteMetricClusterByCIDWithContext calls DeleteMetricClusterByCIDWithContextFunc.
+func (m *Mock) DeleteMetricClusterByCIDWithContext(ctx context.Context, cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteMetricClusterByCIDWithContext", ctx, cid)
+	if m.DeleteMetricClusterByCIDWithContextFunc == nil {
+		err = notMocked("DeleteMetricClusterByCIDWithContext")
+		return
+	}
+	return m.DeleteMetricClusterByCIDWithContextFunc(ctx, cid)
+}
+
+// SearchMetricClusters calls SearchMetricClustersFunc.
+func (m *Mock) SearchMetricClusters(searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.MetricCluster, err error) {
+	m.record("SearchMetricClusters", searchCriteria, filterCriteria)
+	if m.SearchMetricClustersFunc == nil {
+		err = notMocked("SearchMetricClusters")
+		return
+	}
+	return m.SearchMetricClustersFunc(searchCriteria, filterCriteria)
+}
+
+// SearchMetricClustersWithContext calls SearchMetricClustersWithContextFunc.
+func (m *Mock) SearchMetricClustersWithContext(ctx context.Context, searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.MetricCluster, err error) {
+	m.record("SearchMetricClustersWithContext", ctx, searchCriteria, filterCriteria)
+	if m.SearchMetricClustersWithContextFunc == nil {
+		err = notMocked("SearchMetricClustersWithContext")
+		return
+	}
+	return m.SearchMetricClustersWithContextFunc(ctx, searchCriteria, filterCriteria)
+}
+
+// FetchOutlierReport calls FetchOutlierReportFunc.
+func (m *Mock) FetchOutlierReport(cid apiclient.CIDType) (r0 *apiclient.OutlierReport, err error) {
+	m.record("FetchOutlierReport", cid)
+	if m.FetchOutlierReportFunc == nil {
+		err = notMocked("FetchOutlierReport")
+		return
+	}
+	return m.FetchOutlierReportFunc(cid)
+}
+
+// FetchOutlierReportWithContext calls FetchOutlierReportWithContextFunc.
+func (m *Mock) FetchOutlierReportWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.OutlierReport, err error) {
+	m.record("FetchOutlierReportWithContext", ctx, cid)
+	if m.FetchOutlierReportWithContextFunc == nil {
+		err = notMocked("FetchOutlierReportWithContext")
+		return
+	}
+	return m.FetchOutlierReportWithContextFunc(ctx, cid)
+}
+
+// FetchOutlierReports calls FetchOutlierReportsFunc.
+func (m *Mock) FetchOutlierReports() (r0 *[]apiclient.OutlierReport, err error) {
+	m.record("FetchOutlierReports")
+	if m.FetchOutlierReportsFunc == nil {
+		err = notMocked("FetchOutlierReports")
+		return
+	}
+	return m.FetchOutlierReportsFunc()
+}
+
+// FetchOutlierReportsWithContext calls FetchOutlierReportsWithContextFunc.
+func (m *Mock) FetchOutlierReportsWithContext(ctx context.Context) (r0 *[]apiclient.OutlierReport, err error) {
+	m.record("FetchOutlierReportsWithContext", ctx)
+	if m.FetchOutlierReportsWithContextFunc == nil {
+		err = notMocked("FetchOutlierReportsWithContext")
+		return
+	}
+	return m.FetchOutlierReportsWithContextFunc(ctx)
+}
+
+// UpdateOutlierReport calls UpdateOutlierReportFunc.
+func (m *Mock) UpdateOutlierReport(cfg *apiclient.OutlierReport) (r0 *apiclient.OutlierReport, err error) {
+	m.record("UpdateOutlierReport", cfg)
+	if m.UpdateOutlierReportFunc == nil {
+		err = notMocked("UpdateOutlierReport")
+		return
+	}
+	return m.UpdateOutlierReportFunc(cfg)
+}
+
+// UpdateOutlierReportWithContext calls UpdateOutlierReportWithContextFunc.
+func (m *Mock) UpdateOutlierReportWithContext(ctx context.Context, cfg *apiclient.OutlierReport) (r0 *apiclient.OutlierReport, err error) {
+	m.record("UpdateOutlierReportWithContext", ctx, cfg)
+	if m.UpdateOutlierReportWithContextFunc == nil {
+		err = notMocked("UpdateOutlierReportWithContext")
+		return
+	}
+	return m.UpdateOutlierReportWithContextFunc(ctx, cfg)
+}
+
+// CreateOutlierReport calls CreateOutlierReportFunc.
+func (m *Mock) CreateOutlierReport(cfg *apiclient.OutlierReport) (r0 *apiclient.OutlierReport, err error) {
+	m.record("CreateOutlierReport", cfg)
+	if m.CreateOutlierReportFunc == nil {
+		err = notMocked("CreateOutlierReport")
+		return
+	}
+	return m.CreateOutlierReportFunc(cfg)
+}
+
+// CreateOutlierReportWithContext calls CreateOutlierReportWithContextFunc.
+func (m *Mock) CreateOutlierReportWithContext(ctx context.Context, cfg *apiclient.OutlierReport) (r0 *apiclient.OutlierReport, err error) {
+	m.record("CreateOutlierReportWithContext", ctx, cfg)
+	if m.CreateOutlierReportWithContextFunc == nil {
+		err = notMocked("CreateOutlierReportWithContext")
+		return
+	}
+	return m.CreateOutlierReportWithContextFunc(ctx, cfg)
+}
+
+// DeleteOutlierReport calls DeleteOutlierReportFunc.
+func (m *Mock) DeleteOutlierReport(cfg *apiclient.OutlierReport) (r0 bool, err error) {
+	m.record("DeleteOutlierReport", cfg)
+	if m.DeleteOutlierReportFunc == nil {
+		err = notMocked("DeleteOutlierReport")
+		return
+	}
+	return m.DeleteOutlierReportFunc(cfg)
+}
+
+// DeleteOutlierReportWithContext calls DeleteOutlierReportWithContextFunc.
+func (m *Mock) DeleteOutlierReportWithContext(ctx context.Context, cfg *apiclient.OutlierReport) (r0 bool, err error) {
+	m.record("DeleteOutlierReportWithContext", ctx, cfg)
+	if m.DeleteOutlierReportWithContextFunc == nil {
+		err = notMocked("DeleteOutlierReportWithContext")
+		return
+	}
+	return m.DeleteOutlierReportWithContextFunc(ctx, cfg)
+}
+
+// DeleteOutlierReportByCID calls DeleteOutlierReportByCIDFunc.
+func (m *Mock) DeleteOutlierReportByCID(cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteOutlierReportByCID", cid)
+	if m.DeleteOutlierReportByCIDFunc == nil {
+		err = notMocked("DeleteOutlierReportByCID")
+		return
+	}
+	return m.DeleteOutlierReportByCIDFunc(cid)
+}
+
+// DeleteOutlierReportByCIDWithContext calls DeleteOutlierReportByCIDWithContextFunc.
+func (m *Mock) DeleteOutlierReportByCIDWithContext(ctx context.Context, cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteOutlierReportByCIDWithContext", ctx, cid)
+	if m.DeleteOutlierReportByCIDWithContextFunc == nil {
+		err = notMocked("DeleteOutlierReportByCIDWithContext")
+		return
+	}
+	return m.DeleteOutlierReportByCIDWithContextFunc(ctx, cid)
+}
+
+// SearchOutlierReports calls SearchOutlierReportsFunc.
+func (m *Mock) SearchOutlierReports(searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.OutlierReport, err error) {
+	m.record("SearchOutlierReports", searchCriteria, filterCriteria)
+	if m.SearchOutlierReportsFunc == nil {
+		err = notMocked("SearchOutlierReports")
+		return
+	}
+	return m.SearchOutlierReportsFunc(searchCriteria, filterCriteria)
+}
+
+// SearchOutlierReportsWithContext calls SearchOutlierReportsWithContextFunc.
+func (m *Mock) SearchOutlierReportsWithContext(ctx context.Context, searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.OutlierReport, err error) {
+	m.record("SearchOutlierReportsWithContext", ctx, searchCriteria, filterCriteria)
+	if m.SearchOutlierReportsWithContextFunc == nil {
+		err = notMocked("SearchOutlierReportsWithContext")
+		return
+	}
+	return m.SearchOutlierReportsWithContextFunc(ctx, searchCriteria, filterCriteria)
+}
+
+// SearchOutlierReportsByWindow calls SearchOutlierReportsByWindowFunc.
+func (m *Mock) SearchOutlierReportsByWindow(filter *apiclient.OutlierReportWindowFilter) (r0 *[]apiclient.OutlierReport, err error) {
+	m.record("SearchOutlierReportsByWindow", filter)
+	if m.SearchOutlierReportsByWindowFunc == nil {
+		err = notMocked("SearchOutlierReportsByWindow")
+		return
+	}
+	return m.SearchOutlierReportsByWindowFunc(filter)
+}
+
+// SearchOutlierReportsByWindowWithContext calls SearchOutlierReportsByWindowWithContextFunc.
+func (m *Mock) SearchOutlierReportsByWindowWithContext(ctx context.Context, filter *apiclient.OutlierReportWindowFilter) (r0 *[]apiclient.OutlierReport, err error) {
+	m.record("SearchOutlierReportsByWindowWithContext", ctx, filter)
+	if m.SearchOutlierReportsByWindowWithContextFunc == nil {
+		err = notMocked("SearchOutlierReportsByWindowWithContext")
+		return
+	}
+	return m.SearchOutlierReportsByWindowWithContextFunc(ctx, filter)
+}
+
+// PurgeOutlierReports calls PurgeOutlierReportsFunc.
+func (m *Mock) PurgeOutlierReports(retention *apiclient.OutlierReportRetention) (r0 *[]apiclient.OutlierReport, err error) {
+	m.record("PurgeOutlierReports", retention)
+	if m.PurgeOutlierReportsFunc == nil {
+		err = notMocked("PurgeOutlierReports")
+		return
+	}
+	return m.PurgeOutlierReportsFunc(retention)
+}
+
+// FetchProvisionBroker calls FetchProvisionBrokerFunc.
+func (m *Mock) FetchProvisionBroker(cid apiclient.CIDType) (r0 *apiclient.ProvisionBroker, err error) {
+	m.record("FetchProvisionBroker", cid)
+	if m.FetchProvisionBrokerFunc == nil {
+		err = notMocked("FetchProvisionBroker")
+		return
+	}
+	return m.FetchProvisionBrokerFunc(cid)
+}
+
+// FetchProvisionBrokerWithContext calls FetchProvisionBrokerWithContextFunc.
+func (m *Mock) FetchProvisionBrokerWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.ProvisionBroker, err error) {
+	m.record("FetchProvisionBrokerWithContext", ctx, cid)
+	if m.FetchProvisionBrokerWithContextFunc == nil {
+		err = notMocked("FetchProvisionBrokerWithContext")
+		return
+	}
+	return m.FetchProvisionBrokerWithContextFunc(ctx, cid)
+}
+
+// UpdateProvisionBroker calls UpdateProvisionBrokerFunc.
+func (m *Mock) UpdateProvisionBroker(cid apiclient.CIDType, cfg *apiclient.ProvisionBroker) (r0 *apiclient.ProvisionBroker, err error) {
+	m.record("UpdateProvisionBroker", cid, cfg)
+	if m.UpdateProvisionBrokerFunc == nil {
+		err = notMocked("UpdateProvisionBroker")
+		return
+	}
+	return m.UpdateProvisionBrokerFunc(cid, cfg)
+}
+
+// UpdateProvisionBrokerWithContext calls UpdateProvisionBrokerWithContextFunc.
+func (m *Mock) UpdateProvisionBrokerWithContext(ctx context.Context, cid apiclient.CIDType, cfg *apiclient.ProvisionBroker) (r0 *apiclient.ProvisionBroker, err error) {
+	m.record("UpdateProvisionBrokerWithContext", ctx, cid, cfg)
+	if m.UpdateProvisionBrokerWithContextFunc == nil {
+		err = notMocked("UpdateProvisionBrokerWithContext")
+		return
+	}
+	return m.UpdateProvisionBrokerWithContextFunc(ctx, cid, cfg)
+}
+
+// CreateProvisionBroker calls CreateProvisionBrokerFunc.
+func (m *Mock) CreateProvisionBroker(cfg *apiclient.ProvisionBroker) (r0 *apiclient.ProvisionBroker, err error) {
+	m.record("CreateProvisionBroker", cfg)
+	if m.CreateProvisionBrokerFunc == nil {
+		err = notMocked("CreateProvisionBroker")
+		return
+	}
+	return m.CreateProvisionBrokerFunc(cfg)
+}
+
+// CreateProvisionBrokerWithContext calls CreateProvisionBrokerWithContextFunc.
+func (m *Mock) CreateProvisionBrokerWithContext(ctx context.Context, cfg *apiclient.ProvisionBroker) (r0 *apiclient.ProvisionBroker, err error) {
+	m.record("CreateProvisionBrokerWithContext", ctx, cfg)
+	if m.CreateProvisionBrokerWithContextFunc == nil {
+		err = notMocked("CreateProvisionBrokerWithContext")
+		return
+	}
+	return m.CreateProvisionBrokerWithContextFunc(ctx, cfg)
+}
+
+// RotateProvisionBrokerCert calls RotateProvisionBrokerCertFunc.
+func (m *Mock) RotateProvisionBrokerCert(cid apiclient.CIDType, csr string) (r0 *apiclient.ProvisionBrokerCert, err error) {
+	m.record("RotateProvisionBrokerCert", cid, csr)
+	if m.RotateProvisionBrokerCertFunc == nil {
+		err = notMocked("RotateProvisionBrokerCert")
+		return
+	}
+	return m.RotateProvisionBrokerCertFunc(cid, csr)
+}
+
+// ProvisionBrokerAndWait calls ProvisionBrokerAndWaitFunc.
+func (m *Mock) ProvisionBrokerAndWait(cfg *apiclient.ProvisionBroker, timeout time.Duration) (r0 *apiclient.Broker, err error) {
+	m.record("ProvisionBrokerAndWait", cfg, timeout)
+	if m.ProvisionBrokerAndWaitFunc == nil {
+		err = notMocked("ProvisionBrokerAndWait")
+		return
+	}
+	return m.ProvisionBrokerAndWaitFunc(cfg, timeout)
+}
+
+// FetchRuleSet calls FetchRuleSetFunc.
+func (m *Mock) FetchRuleSet(cid apiclient.CIDType) (r0 *apiclient.RuleSet, err error) {
+	m.record("FetchRuleSet", cid)
+	if m.FetchRuleSetFunc == nil {
+		err = notMocked("FetchRuleSet")
+		return
+	}
+	return m.FetchRuleSetFunc(cid)
+}
+
+// FetchRuleSetWithContext calls FetchRuleSetWithContextFunc.
+func (m *Mock) FetchRuleSetWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.RuleSet, err error) {
+	m.record("FetchRuleSetWithContext", ctx, cid)
+	if m.FetchRuleSetWithContextFunc == nil {
+		err = notMocked("FetchRuleSetWithContext")
+		return
+	}
+	return m.FetchRuleSetWithContextFunc(ctx, cid)
+}
+
+// FetchRuleSets calls FetchRuleSetsFunc.
+func (m *Mock) FetchRuleSets() (r0 *[]apiclient.RuleSet, err error) {
+	m.record("FetchRuleSets")
+	if m.FetchRuleSetsFunc == nil {
+		err = notMocked("FetchRuleSets")
+		return
+	}
+	return m.FetchRuleSetsFunc()
+}
+
+// FetchRuleSetsWithContext calls FetchRuleSetsWithContextFunc.
+func (m *Mock) FetchRuleSetsWithContext(ctx context.Context) (r0 *[]apiclient.RuleSet, err error) {
+	m.record("FetchRuleSetsWithContext", ctx)
+	if m.FetchRuleSetsWithContextFunc == nil {
+		err = notMocked("FetchRuleSetsWithContext")
+		return
+	}
+	return m.FetchRuleSetsWithContextFunc(ctx)
+}
+
+// UpdateRuleSet calls UpdateRuleSetFunc.
+func (m *Mock) UpdateRuleSet(cfg *apiclient.RuleSet) (r0 *apiclient.RuleSet, err error) {
+	m.record("UpdateRuleSet", cfg)
+	if m.UpdateRuleSetFunc == nil {
+		err = notMocked("UpdateRuleSet")
+		return
+	}
+	return m.UpdateRuleSetFunc(cfg)
+}
+
+// UpdateRuleSetWithContext calls UpdateRuleSetWithContextFunc.
+func (m *Mock) UpdateRuleSetWithContext(ctx context.Context, cfg *apiclient.RuleSet) (r0 *apiclient.RuleSet, err error) {
+	m.record("UpdateRuleSetWithContext", ctx, cfg)
+	if m.UpdateRuleSetWithContextFunc == nil {
+		err = notMocked("UpdateRuleSetWithContext")
+		return
+	}
+	return m.UpdateRuleSetWithContextFunc(ctx, cfg)
+}
+
+// CreateRuleSet calls CreateRuleSetFunc.
+func (m *Mock) CreateRuleSet(cfg *apiclient.RuleSet) (r0 *apiclient.RuleSet, err error) {
+	m.record("CreateRuleSet", cfg)
+	if m.CreateRuleSetFunc == nil {
+		err = notMocked("CreateRuleSet")
+		return
+	}
+	return m.CreateRuleSetFunc(cfg)
+}
+
+// CreateRuleSetWithContext calls CreateRuleSetWithContextFunc.
+func (m *Mock) CreateRuleSetWithContext(ctx context.Context, cfg *apiclient.RuleSet) (r0 *apiclient.RuleSet, err error) {
+	m.record("CreateRuleSetWithContext", ctx, cfg)
+	if m.CreateRuleSetWithContextFunc == nil {
+		err = notMocked("CreateRuleSetWithContext")
+		return
+	}
+	return m.CreateRuleSetWithContextFunc(ctx, cfg)
+}
+
+// DeleteRuleSet calls DeleteRuleSetFunc.
+func (m *Mock) DeleteRuleSet(cfg *apiclient.RuleSet) (r0 bool, err error) {
+	m.record("DeleteRuleSet", cfg)
+	if m.DeleteRuleSetFunc == nil {
+		err = notMocked("DeleteRuleSet")
+		return
+	}
+	return m.DeleteRuleSetFunc(cfg)
+}
+
+// DeleteRuleSetWithContext calls DeleteRuleSetWithContextFunc.
+func (m *Mock) DeleteRuleSetWithContext(ctx context.Context, cfg *apiclient.RuleSet) (r0 bool, err error) {
+	m.record("DeleteRuleSetWithContext", ctx, cfg)
+	if m.DeleteRuleSetWithContextFunc == nil {
+		err = notMocked("DeleteRuleSetWithContext")
+		return
+	}
+	return m.DeleteRuleSetWithContextFunc(ctx, cfg)
+}
+
+// DeleteRuleSetByCID calls DeleteRuleSetByCIDFunc.
+func (m *Mock) DeleteRuleSetByCID(cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteRuleSetByCID", cid)
+	if m.DeleteRuleSetByCIDFunc == nil {
+		err = notMocked("DeleteRuleSetByCID")
+		return
+	}
+	return m.DeleteRuleSetByCIDFunc(cid)
+}
+
+// DeleteRuleSetByCIDWithContext calls DeleteRuleSetByCIDWithContextFunc.
+func (m *Mock) DeleteRuleSetByCIDWithContext(ctx context.Context, cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteRuleSetByCIDWithContext", ctx, cid)
+	if m.DeleteRuleSetByCIDWithContextFunc == nil {
+		err = notMocked("DeleteRuleSetByCIDWithContext")
+		return
+	}
+	return m.DeleteRuleSetByCIDWithContextFunc(ctx, cid)
+}
+
+// SearchRuleSets calls SearchRuleSetsFunc.
+func (m *Mock) SearchRuleSets(searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.RuleSet, err error) {
+	m.record("SearchRuleSets", searchCriteria, filterCriteria)
+	if m.SearchRuleSetsFunc == nil {
+		err = notMocked("SearchRuleSets")
+		return
+	}
+	return m.SearchRuleSetsFunc(searchCriteria, filterCriteria)
+}
+
+// SearchRuleSetsWithContext calls SearchRuleSetsWithContextFunc.
+func (m *Mock) SearchRuleSetsWithContext(ctx context.Context, searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.RuleSet, err error) {
+	m.record("SearchRuleSetsWithContext", ctx, searchCriteria, filterCriteria)
+	if m.SearchRuleSetsWithContextFunc == nil {
+		err = notMocked("SearchRuleSetsWithContext")
+		return
+	}
+	return m.SearchRuleSetsWithContextFunc(ctx, searchCriteria, filterCriteria)
+}
+
+// ValidateRuleSet calls ValidateRuleSetFunc.
+func (m *Mock) ValidateRuleSet(cfg *apiclient.RuleSet) (err error) {
+	m.record("ValidateRuleSet", cfg)
+	if m.ValidateRuleSetFunc == nil {
+		err = notMocked("ValidateRuleSet")
+		return
+	}
+	return m.ValidateRuleSetFunc(cfg)
+}
+
+// CloneRuleSet calls CloneRuleSetFunc.
+func (m *Mock) CloneRuleSet(cid apiclient.CIDType, newCheckCID string, newMetricName string) (r0 *apiclient.RuleSet, err error) {
+	m.record("CloneRuleSet", cid, newCheckCID, newMetricName)
+	if m.CloneRuleSetFunc == nil {
+		err = notMocked("CloneRuleSet")
+		return
+	}
+	return m.CloneRuleSetFunc(cid, newCheckCID, newMetricName)
+}
+
+// RuleSetsNotifying calls RuleSetsNotifyingFunc.
+func (m *Mock) RuleSetsNotifying(contactGroupCID apiclient.CIDType) (r0 *[]apiclient.RuleSet, err error) {
+	m.record("RuleSetsNotifying", contactGroupCID)
+	if m.RuleSetsNotifyingFunc == nil {
+		err = notMocked("RuleSetsNotifying")
+		return
+	}
+	return m.RuleSetsNotifyingFunc(contactGroupCID)
+}
+
+// RuleSetsWithSeverity calls RuleSetsWithSeverityFunc.
+func (m *Mock) RuleSetsWithSeverity(severity uint) (r0 *[]apiclient.RuleSet, err error) {
+	m.record("RuleSetsWithSeverity", severity)
+	if m.RuleSetsWithSeverityFunc == nil {
+		err = notMocked("RuleSetsWithSeverity")
+		return
+	}
+	return m.RuleSetsWithSeverityFunc(severity)
+}
+
+// MuteRuleSet calls MuteRuleSetFunc.
+func (m *Mock) MuteRuleSet(cid apiclient.CIDType) (r0 *apiclient.RuleSetMute, err error) {
+	m.record("MuteRuleSet", cid)
+	if m.MuteRuleSetFunc == nil {
+		err = notMocked("MuteRuleSet")
+		return
+	}
+	return m.MuteRuleSetFunc(cid)
+}
+
+// UnmuteRuleSet calls UnmuteRuleSetFunc.
+func (m *Mock) UnmuteRuleSet(mute *apiclient.RuleSetMute) (r0 *apiclient.RuleSet, err error) {
+	m.record("UnmuteRuleSet", mute)
+	if m.UnmuteRuleSetFunc == nil {
+		err = notMocked("UnmuteRuleSet")
+		return
+	}
+	return m.UnmuteRuleSetFunc(mute)
+}
+
+// FetchRuleSetGroup calls FetchRuleSetGroupFunc.
+func (m *Mock) FetchRuleSetGroup(cid apiclient.CIDType) (r0 *apiclient.RuleSetGroup, err error) {
+	m.record("FetchRuleSetGroup", cid)
+	if m.FetchRuleSetGroupFunc == nil {
+		err = notMocked("FetchRuleSetGroup")
+		return
+	}
+	return m.FetchRuleSetGroupFunc(cid)
+}
+
+// FetchRuleSetGroupWithContext calls FetchRuleSetGroupWithContextFunc.
+func (m *Mock) FetchRuleSetGroupWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.RuleSetGroup, err error) {
+	m.record("FetchRuleSetGroupWithContext", ctx, cid)
+	if m.FetchRuleSetGroupWithContextFunc == nil {
+		err = notMocked("FetchRuleSetGroupWithContext")
+		return
+	}
+	return m.FetchRuleSetGroupWithContextFunc(ctx, cid)
+}
+
+// FetchRuleSetGroups calls FetchRuleSetGroupsFunc.
+func (m *Mock) FetchRuleSetGroups() (r0 *[]apiclient.RuleSetGroup, err error) {
+	m.record("FetchRuleSetGroups")
+	if m.FetchRuleSetGroupsFunc == nil {
+		err = notMocked("FetchRuleSetGroups")
+		return
+	}
+	return m.FetchRuleSetGroupsFunc()
+}
+
+// FetchRuleSetGroupsWithContext calls FetchRuleSetGroupsWithContextFunc.
+func (m *Mock) FetchRuleSetGroupsWithContext(ctx context.Context) (r0 *[]apiclient.RuleSetGroup, err error) {
+	m.record("FetchRuleSetGroupsWithContext", ctx)
+	if m.FetchRuleSetGroupsWithContextFunc == nil {
+		err = notMocked("FetchRuleSetGroupsWithContext")
+		return
+	}
+	return m.FetchRuleSetGroupsWithContextFunc(ctx)
+}
+
+// UpdateRuleSetGroup calls UpdateRuleSetGroupFunc.
+func (m *Mock) UpdateRuleSetGroup(cfg *apiclient.RuleSetGroup) (r0 *apiclient.RuleSetGroup, err error) {
+	m.record("UpdateRuleSetGroup", cfg)
+	if m.UpdateRuleSetGroupFunc == nil {
+		err = notMocked("UpdateRuleSetGroup")
+		return
+	}
+	return m.UpdateRuleSetGroupFunc(cfg)
+}
+
+// UpdateRuleSetGroupWithContext calls UpdateRuleSetGroupWithContextFunc.
+func (m *Mock) UpdateRuleSetGroupWithContext(ctx context.Context, cfg *apiclient.RuleSetGroup) (r0 *apiclient.RuleSetGroup, err error) {
+	m.record("UpdateRuleSetGroupWithContext", ctx, cfg)
+	if m.UpdateRuleSetGroupWithContextFunc == nil {
+		err = notMocked("UpdateRuleSetGroupWithContext")
+		return
+	}
+	return m.UpdateRuleSetGroupWithContextFunc(ctx, cfg)
+}
+
+// CreateRuleSetGroup calls CreateRuleSetGroupFunc.
+func (m *Mock) CreateRuleSetGroup(cfg *apiclient.RuleSetGroup) (r0 *apiclient.RuleSetGroup, err error) {
+	m.record("CreateRuleSetGroup", cfg)
+	if m.CreateRuleSetGroupFunc == nil {
+		err = notMocked("CreateRuleSetGroup")
+		return
+	}
+	return m.CreateRuleSetGroupFunc(cfg)
+}
+
+// CreateRuleSetGroupWithContext calls CreateRuleSetGroupWithContextFunc.
+func (m *Mock) CreateRuleSetGroupWithContext(ctx context.Context, cfg *apiclient.RuleSetGroup) (r0 *apiclient.RuleSetGroup, err error) {
+	m.record("CreateRuleSetGroupWithContext", ctx, cfg)
+	if m.CreateRuleSetGroupWithContextFunc == nil {
+		err = notMocked("CreateRuleSetGroupWithContext")
+		return
+	}
+	return m.CreateRuleSetGroupWithContextFunc(ctx, cfg)
+}
+
+// DeleteRuleSetGroup calls DeleteRuleSetGroupFunc.
+func (m *Mock) DeleteRuleSetGroup(cfg *apiclient.RuleSetGroup) (r0 bool, err error) {
+	m.record("DeleteRuleSetGroup", cfg)
+	if m.DeleteRuleSetGroupFunc == nil {
+		err = notMocked("DeleteRuleSetGroup")
+		return
+	}
+	return m.DeleteRuleSetGroupFunc(cfg)
+}
+
+// DeleteRuleSetGroupWithContext calls DeleteRuleSetGroupWithContextFunc.
+func (m *Mock) DeleteRuleSetGroupWithContext(ctx context.Context, cfg *apiclient.RuleSetGroup) (r0 bool, err error) {
+	m.record("DeleteRuleSetGroupWithContext", ctx, cfg)
+	if m.DeleteRuleSetGroupWithContextFunc == nil {
+		err = notMocked("DeleteRuleSetGroupWithContext")
+		return
+	}
+	return m.DeleteRuleSetGroupWithContextFunc(ctx, cfg)
+}
+
+// DeleteRuleSetGroupByCID calls DeleteRuleSetGroupByCIDFunc.
+func (m *Mock) DeleteRuleSetGroupByCID(cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteRuleSetGroupByCID", cid)
+	if m.DeleteRuleSetGroupByCIDFunc == nil {
+		err = notMocked("DeleteRuleSetGroupByCID")
+		return
+	}
+	return m.DeleteRuleSetGroupByCIDFunc(cid)
+}
+
+// DeleteRuleSetGroupByCIDWithContext calls DeleteRuleSetGroupByCIDWithContextFunc.
+func (m *Mock) DeleteRuleSetGroupByCIDWithContext(ctx context.Context, cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteRuleSetGroupByCIDWithContext", ctx, cid)
+	if m.DeleteRuleSetGroupByCIDWithContextFunc == nil {
+		err = notMocked("DeleteRuleSetGroupByCIDWithContext")
+		return
+	}
+	return m.DeleteRuleSetGroupByCIDWithContextFunc(ctx, cid)
+}
+
+// SearchRuleSetGroups calls SearchRuleSetGroupsFunc.
+func (m *Mock) SearchRuleSetGroups(searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.RuleSetGroup, err error) {
+	m.record("SearchRuleSetGroups", searchCriteria, filterCriteria)
+	if m.SearchRuleSetGroupsFunc == nil {
+		err = notMocked("SearchRuleSetGroups")
+		return
+	}
+	return m.SearchRuleSetGroupsFunc(searchCriteria, filterCriteria)
+}
+
+// SearchRuleSetGroupsWithContext calls SearchRuleSetGroupsWithContextFunc.
+func (m *Mock) SearchRuleSetGroupsWithContext(ctx context.Context, searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.RuleSetGroup, err error) {
+	m.record("SearchRuleSetGroupsWithContext", ctx, searchCriteria, filterCriteria)
+	if m.SearchRuleSetGroupsWithContextFunc == nil {
+		err = notMocked("SearchRuleSetGroupsWithContext")
+		return
+	}
+	return m.SearchRuleSetGroupsWithContextFunc(ctx, searchCriteria, filterCriteria)
+}
+
+// ValidateRuleSetGroup calls ValidateRuleSetGroupFunc.
+func (m *Mock) ValidateRuleSetGroup(cfg *apiclient.RuleSetGroup) (err error) {
+	m.record("ValidateRuleSetGroup", cfg)
+	if m.ValidateRuleSetGroupFunc == nil {
+		err = notMocked("ValidateRuleSetGroup")
+		return
+	}
+	return m.ValidateRuleSetGroupFunc(cfg)
+}
+
+// CloneRuleSetGroup calls CloneRuleSetGroupFunc.
+func (m *Mock) CloneRuleSetGroup(cid apiclient.CIDType, memberMap map[string]string) (r0 *apiclient.RuleSetGroup, err error) {
+	m.record("CloneRuleSetGroup", cid, memberMap)
+	if m.CloneRuleSetGroupFunc == nil {
+		err = notMocked("CloneRuleSetGroup")
+		return
+	}
+	return m.CloneRuleSetGroupFunc(cid, memberMap)
+}
+
+// FetchTag calls FetchTagFunc.
+func (m *Mock) FetchTag(cid apiclient.CIDType) (r0 *apiclient.Tag, err error) {
+	m.record("FetchTag", cid)
+	if m.FetchTagFunc == nil {
+		err = notMocked("FetchTag")
+		return
+	}
+	return m.FetchTagFunc(cid)
+}
+
+// FetchTagWithContext calls FetchTagWithContextFunc.
+func (m *Mock) FetchTagWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.Tag, err error) {
+	m.record("FetchTagWithContext", ctx, cid)
+	if m.FetchTagWithContextFunc == nil {
+		err = notMocked("FetchTagWithContext")
+		return
+	}
+	return m.FetchTagWithContextFunc(ctx, cid)
+}
+
+// FetchTags calls FetchTagsFunc.
+func (m *Mock) FetchTags() (r0 *[]apiclient.Tag, err error) {
+	m.record("FetchTags")
+	if m.FetchTagsFunc == nil {
+		err = notMocked("FetchTags")
+		return
+	}
+	return m.FetchTagsFunc()
+}
+
+// FetchTagsWithContext calls FetchTagsWithContextFunc.
+func (m *Mock) FetchTagsWithContext(ctx context.Context) (r0 *[]apiclient.Tag, err error) {
+	m.record("FetchTagsWithContext", ctx)
+	if m.FetchTagsWithContextFunc == nil {
+		err = notMocked("FetchTagsWithContext")
+		return
+	}
+	return m.FetchTagsWithContextFunc(ctx)
+}
+
+// SearchTags calls SearchTagsFunc.
+func (m *Mock) SearchTags(searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Tag, err error) {
+	m.record("SearchTags", searchCriteria, filterCriteria)
+	if m.SearchTagsFunc == nil {
+		err = notMocked("SearchTags")
+		return
+	}
+	return m.SearchTagsFunc(searchCriteria, filterCriteria)
+}
+
+// SearchTagsWithContext calls SearchTagsWithContextFunc.
+func (m *Mock) SearchTagsWithContext(ctx context.Context, searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Tag, err error) {
+	m.record("SearchTagsWithContext", ctx, searchCriteria, filterCriteria)
+	if m.SearchTagsWithContextFunc == nil {
+		err = notMocked("SearchTagsWithContext")
+		return
+	}
+	return m.SearchTagsWithContextFunc(ctx, searchCriteria, filterCriteria)
+}
+
+// FetchTagCategories calls FetchTagCategoriesFunc.
+func (m *Mock) FetchTagCategories() (r0 map[string][]string, err error) {
+	m.record("FetchTagCategories")
+	if m.FetchTagCategoriesFunc == nil {
+		err = notMocked("FetchTagCategories")
+		return
+	}
+	return m.FetchTagCategoriesFunc()
+}
+
+// FetchTagCategoriesWithContext calls FetchTagCategoriesWithContextFunc.
+func (m *Mock) FetchTagCategoriesWithContext(ctx context.Context) (r0 map[string][]string, err error) {
+	m.record("FetchTagCategoriesWithContext", ctx)
+	if m.FetchTagCategoriesWithContextFunc == nil {
+		err = notMocked("FetchTagCategoriesWithContext")
+		return
+	}
+	return m.FetchTagCategoriesWithContextFunc(ctx)
+}
+
+// FetchUser calls FetchUserFunc.
+func (m *Mock) FetchUser(cid apiclient.CIDType) (r0 *apiclient.User, err error) {
+	m.record("FetchUser", cid)
+	if m.FetchUserFunc == nil {
+		err = notMocked("FetchUser")
+		return
+	}
+	return m.FetchUserFunc(cid)
+}
+
+// FetchUserWithContext calls FetchUserWithContextFunc.
+func (m *Mock) FetchUserWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.User, err error) {
+	m.record("FetchUserWithContext", ctx, cid)
+	if m.FetchUserWithContextFunc == nil {
+		err = notMocked("FetchUserWithContext")
+		return
+	}
+	return m.FetchUserWithContextFunc(ctx, cid)
+}
+
+// FetchUsers calls FetchUsersFunc.
+func (m *Mock) FetchUsers() (r0 *[]apiclient.User, err error) {
+	m.record("FetchUsers")
+	if m.FetchUsersFunc == nil {
+		err = notMocked("FetchUsers")
+		return
+	}
+	return m.FetchUsersFunc()
+}
+
+// FetchUsersWithContext calls FetchUsersWithContextFunc.
+func (m *Mock) FetchUsersWithContext(ctx context.Context) (r0 *[]apiclient.User, err error) {
+	m.record("FetchUsersWithContext", ctx)
+	if m.FetchUsersWithContextFunc == nil {
+		err = notMocked("FetchUsersWithContext")
+		return
+	}
+	return m.FetchUsersWithContextFunc(ctx)
+}
+
+// UpdateUser calls UpdateUserFunc.
+func (m *Mock) UpdateUser(cfg *apiclient.User) (r0 *apiclient.User, err error) {
+	m.record("UpdateUser", cfg)
+	if m.UpdateUserFunc == nil {
+		err = notMocked("UpdateUser")
+		return
+	}
+	return m.UpdateUserFunc(cfg)
+}
+
+// UpdateUserWithContext calls UpdateUserWithContextFunc.
+func (m *Mock) UpdateUserWithContext(ctx context.Context, cfg *apiclient.User) (r0 *apiclient.User, err error) {
+	m.record("UpdateUserWithContext", ctx, cfg)
+	if m.UpdateUserWithContextFunc == nil {
+		err = notMocked("UpdateUserWithContext")
+		return
+	}
+	return m.UpdateUserWithContextFunc(ctx, cfg)
+}
+
+// SearchUsers calls SearchUsersFunc.
+func (m *Mock) SearchUsers(filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.User, err error) {
+	m.record("SearchUsers", filterCriteria)
+	if m.SearchUsersFunc == nil {
+		err = notMocked("SearchUsers")
+		return
+	}
+	return m.SearchUsersFunc(filterCriteria)
+}
+
+// SearchUsersWithContext calls SearchUsersWithContextFunc.
+func (m *Mock) SearchUsersWithContext(ctx context.Context, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.User, err error) {
+	m.record("SearchUsersWithContext", ctx, filterCriteria)
+	if m.SearchUsersWithContextFunc == nil {
+		err = notMocked("SearchUsersWithContext")
+		return
+	}
+	return m.SearchUsersWithContextFunc(ctx, filterCriteria)
+}
+
+// FetchCurrentUser calls FetchCurrentUserFunc.
+func (m *Mock) FetchCurrentUser() (r0 *apiclient.User, err error) {
+	m.record("FetchCurrentUser")
+	if m.FetchCurrentUserFunc == nil {
+		err = notMocked("FetchCurrentUser")
+		return
+	}
+	return m.FetchCurrentUserFunc()
+}
+
+// FetchCurrentUserWithContext calls FetchCurrentUserWithContextFunc.
+func (m *Mock) FetchCurrentUserWithContext(ctx context.Context) (r0 *apiclient.User, err error) {
+	m.record("FetchCurrentUserWithContext", ctx)
+	if m.FetchCurrentUserWithContextFunc == nil {
+		err = notMocked("FetchCurrentUserWithContext")
+		return
+	}
+	return m.FetchCurrentUserWithContextFunc(ctx)
+}
+
+// WhoAmI calls WhoAmIFunc.
+func (m *Mock) WhoAmI() (r0 *apiclient.WhoAmI, err error) {
+	m.record("WhoAmI")
+	if m.WhoAmIFunc == nil {
+		err = notMocked("WhoAmI")
+		return
+	}
+	return m.WhoAmIFunc()
+}
+
+// SetUserContactInfo calls SetUserContactInfoFunc.
+func (m *Mock) SetUserContactInfo(cid apiclient.CIDType, method string, value string) (r0 *apiclient.User, err error) {
+	m.record("SetUserContactInfo", cid, method, value)
+	if m.SetUserContactInfoFunc == nil {
+		err = notMocked("SetUserContactInfo")
+		return
+	}
+	return m.SetUserContactInfoFunc(cid, method, value)
+}
+
+// SearchUsersByEmail calls SearchUsersByEmailFunc.
+func (m *Mock) SearchUsersByEmail(addr string) (r0 *[]apiclient.User, err error) {
+	m.record("SearchUsersByEmail", addr)
+	if m.SearchUsersByEmailFunc == nil {
+		err = notMocked("SearchUsersByEmail")
+		return
+	}
+	return m.SearchUsersByEmailFunc(addr)
+}
+
+// SearchUsersByEmailWithContext calls SearchUsersByEmailWithContextFunc.
+func (m *Mock) SearchUsersByEmailWithContext(ctx context.Context, addr string) (r0 *[]apiclient.User, err error) {
+	m.record("SearchUsersByEmailWithContext", ctx, addr)
+	if m.SearchUsersByEmailWithContextFunc == nil {
+		err = notMocked("SearchUsersByEmailWithContext")
+		return
+	}
+	return m.SearchUsersByEmailWithContextFunc(ctx, addr)
+}
+
+// FetchUsersInAccount calls FetchUsersInAccountFunc.
+func (m *Mock) FetchUsersInAccount(role string) (r0 *[]apiclient.User, err error) {
+	m.record("FetchUsersInAccount", role)
+	if m.FetchUsersInAccountFunc == nil {
+		err = notMocked("FetchUsersInAccount")
+		return
+	}
+	return m.FetchUsersInAccountFunc(role)
+}
+
+// FetchUsersInAccountWithContext calls FetchUsersInAccountWithContextFunc.
+func (m *Mock) FetchUsersInAccountWithContext(ctx context.Context, role string) (r0 *[]apiclient.User, err error) {
+	m.record("FetchUsersInAccountWithContext", ctx, role)
+	if m.FetchUsersInAccountWithContextFunc == nil {
+		err = notMocked("FetchUsersInAccountWithContext")
+		return
+	}
+	return m.FetchUsersInAccountWithContextFunc(ctx, role)
+}
+
+// FetchUserRoles calls FetchUserRolesFunc.
+func (m *Mock) FetchUserRoles(cid apiclient.CIDType) (r0 *apiclient.User, err error) {
+	m.record("FetchUserRoles", cid)
+	if m.FetchUserRolesFunc == nil {
+		err = notMocked("FetchUserRoles")
+		return
+	}
+	return m.FetchUserRolesFunc(cid)
+}
+
+// FetchUserRolesWithContext calls FetchUserRolesWithContextFunc.
+func (m *Mock) FetchUserRolesWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.User, err error) {
+	m.record("FetchUserRolesWithContext", ctx, cid)
+	if m.FetchUserRolesWithContextFunc == nil {
+		err = notMocked("FetchUserRolesWithContext")
+		return
+	}
+	return m.FetchUserRolesWithContextFunc(ctx, cid)
+}
+
+// FetchWorksheet calls FetchWorksheetFunc.
+func (m *Mock) FetchWorksheet(cid apiclient.CIDType) (r0 *apiclient.Worksheet, err error) {
+	m.record("FetchWorksheet", cid)
+	if m.FetchWorksheetFunc == nil {
+		err = notMocked("FetchWorksheet")
+		return
+	}
+	return m.FetchWorksheetFunc(cid)
+}
+
+// FetchWorksheetWithContext calls FetchWorksheetWithContextFunc.
+func (m *Mock) FetchWorksheetWithContext(ctx context.Context, cid apiclient.CIDType) (r0 *apiclient.Worksheet, err error) {
+	m.record("FetchWorksheetWithContext", ctx, cid)
+	if m.FetchWorksheetWithContextFunc == nil {
+		err = notMocked("FetchWorksheetWithContext")
+		return
+	}
+	return m.FetchWorksheetWithContextFunc(ctx, cid)
+}
+
+// FetchWorksheets calls FetchWorksheetsFunc.
+func (m *Mock) FetchWorksheets() (r0 *[]apiclient.Worksheet, err error) {
+	m.record("FetchWorksheets")
+	if m.FetchWorksheetsFunc == nil {
+		err = notMocked("FetchWorksheets")
+		return
+	}
+	return m.FetchWorksheetsFunc()
+}
+
+// FetchWorksheetsWithContext calls FetchWorksheetsWithContextFunc.
+func (m *Mock) FetchWorksheetsWithContext(ctx context.Context) (r0 *[]apiclient.Worksheet, err error) {
+	m.record("FetchWorksheetsWithContext", ctx)
+	if m.FetchWorksheetsWithContextFunc == nil {
+		err = notMocked("FetchWorksheetsWithContext")
+		return
+	}
+	return m.FetchWorksheetsWithContextFunc(ctx)
+}
+
+// UpdateWorksheet calls UpdateWorksheetFunc.
+func (m *Mock) UpdateWorksheet(cfg *apiclient.Worksheet) (r0 *apiclient.Worksheet, err error) {
+	m.record("UpdateWorksheet", cfg)
+	if m.UpdateWorksheetFunc == nil {
+		err = notMocked("UpdateWorksheet")
+		return
+	}
+	return m.UpdateWorksheetFunc(cfg)
+}
+
+// UpdateWorksheetWithContext calls UpdateWorksheetWithContextFunc.
+func (m *Mock) UpdateWorksheetWithContext(ctx context.Context, cfg *apiclient.Worksheet) (r0 *apiclient.Worksheet, err error) {
+	m.record("UpdateWorksheetWithContext", ctx, cfg)
+	if m.UpdateWorksheetWithContextFunc == nil {
+		err = notMocked("UpdateWorksheetWithContext")
+		return
+	}
+	return m.UpdateWorksheetWithContextFunc(ctx, cfg)
+}
+
+// CreateWorksheet calls CreateWorksheetFunc.
+func (m *Mock) CreateWorksheet(cfg *apiclient.Worksheet) (r0 *apiclient.Worksheet, err error) {
+	m.record("CreateWorksheet", cfg)
+	if m.CreateWorksheetFunc == nil {
+		err = notMocked("CreateWorksheet")
+		return
+	}
+	return m.CreateWorksheetFunc(cfg)
+}
+
+// CreateWorksheetWithContext calls CreateWorksheetWithContextFunc.
+func (m *Mock) CreateWorksheetWithContext(ctx context.Context, cfg *apiclient.Worksheet) (r0 *apiclient.Worksheet, err error) {
+	m.record("CreateWorksheetWithContext", ctx, cfg)
+	if m.CreateWorksheetWithContextFunc == nil {
+		err = notMocked("CreateWorksheetWithContext")
+		return
+	}
+	return m.CreateWorksheetWithContextFunc(ctx, cfg)
+}
+
+// DeleteWorksheet calls DeleteWorksheetFunc.
+func (m *Mock) DeleteWorksheet(cfg *apiclient.Worksheet) (r0 bool, err error) {
+	m.record("DeleteWorksheet", cfg)
+	if m.DeleteWorksheetFunc == nil {
+		err = notMocked("DeleteWorksheet")
+		return
+	}
+	return m.DeleteWorksheetFunc(cfg)
+}
+
+// DeleteWorksheetWithContext calls DeleteWorksheetWithContextFunc.
+func (m *Mock) DeleteWorksheetWithContext(ctx context.Context, cfg *apiclient.Worksheet) (r0 bool, err error) {
+	m.record("DeleteWorksheetWithContext", ctx, cfg)
+	if m.DeleteWorksheetWithContextFunc == nil {
+		err = notMocked("DeleteWorksheetWithContext")
+		return
+	}
+	return m.DeleteWorksheetWithContextFunc(ctx, cfg)
+}
+
+// DeleteWorksheetByCID calls DeleteWorksheetByCIDFunc.
+func (m *Mock) DeleteWorksheetByCID(cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteWorksheetByCID", cid)
+	if m.DeleteWorksheetByCIDFunc == nil {
+		err = notMocked("DeleteWorksheetByCID")
+		return
+	}
+	return m.DeleteWorksheetByCIDFunc(cid)
+}
+
+// DeleteWorksheetByCIDWithContext calls DeleteWorksheetByCIDWithContextFunc.
+func (m *Mock) DeleteWorksheetByCIDWithContext(ctx context.Context, cid apiclient.CIDType) (r0 bool, err error) {
+	m.record("DeleteWorksheetByCIDWithContext", ctx, cid)
+	if m.DeleteWorksheetByCIDWithContextFunc == nil {
+		err = notMocked("DeleteWorksheetByCIDWithContext")
+		return
+	}
+	return m.DeleteWorksheetByCIDWithContextFunc(ctx, cid)
+}
+
+// SearchWorksheets calls SearchWorksheetsFunc.
+func (m *Mock) SearchWorksheets(searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Worksheet, err error) {
+	m.record("SearchWorksheets", searchCriteria, filterCriteria)
+	if m.SearchWorksheetsFunc == nil {
+		err = notMocked("SearchWorksheets")
+		return
+	}
+	return m.SearchWorksheetsFunc(searchCriteria, filterCriteria)
+}
+
+// SearchWorksheetsWithContext calls SearchWorksheetsWithContextFunc.
+func (m *Mock) SearchWorksheetsWithContext(ctx context.Context, searchCriteria *apiclient.SearchQueryType, filterCriteria *apiclient.SearchFilterType) (r0 *[]apiclient.Worksheet, err error) {
+	m.record("SearchWorksheetsWithContext", ctx, searchCriteria, filterCriteria)
+	if m.SearchWorksheetsWithContextFunc == nil {
+		err = notMocked("SearchWorksheetsWithContext")
+		return
+	}
+	return m.SearchWorksheetsWithContextFunc(ctx, searchCriteria, filterCriteria)
+}
+
+// FetchWorksheetSmartQueryGraphs calls FetchWorksheetSmartQueryGraphsFunc.
+func (m *Mock) FetchWorksheetSmartQueryGraphs(sq *apiclient.WorksheetSmartQuery) (r0 *[]apiclient.Graph, err error) {
+	m.record("FetchWorksheetSmartQueryGraphs", sq)
+	if m.FetchWorksheetSmartQueryGraphsFunc == nil {
+		err = notMocked("FetchWorksheetSmartQueryGraphs")
+		return
+	}
+	return m.FetchWorksheetSmartQueryGraphsFunc(sq)
+}
+
+// FetchWorksheetSmartQueryGraphsWithContext calls FetchWorksheetSmartQueryGraphsWithContextFunc.
+func (m *Mock) FetchWorksheetSmartQueryGraphsWithContext(ctx context.Context, sq *apiclient.WorksheetSmartQuery) (r0 *[]apiclient.Graph, err error) {
+	m.record("FetchWorksheetSmartQueryGraphsWithContext", ctx, sq)
+	if m.FetchWorksheetSmartQueryGraphsWithContextFunc == nil {
+		err = notMocked("FetchWorksheetSmartQueryGraphsWithContext")
+		return
+	}
+	return m.FetchWorksheetSmartQueryGraphsWithContextFunc(ctx, sq)
+}
+
+// AddGraphToWorksheet calls AddGraphToWorksheetFunc.
+func (m *Mock) AddGraphToWorksheet(worksheetCID apiclient.CIDType, graphCID apiclient.CIDType) (r0 *apiclient.Worksheet, err error) {
+	m.record("AddGraphToWorksheet", worksheetCID, graphCID)
+	if m.AddGraphToWorksheetFunc == nil {
+		err = notMocked("AddGraphToWorksheet")
+		return
+	}
+	return m.AddGraphToWorksheetFunc(worksheetCID, graphCID)
+}
+
+// RemoveGraphFromWorksheet calls RemoveGraphFromWorksheetFunc.
+func (m *Mock) RemoveGraphFromWorksheet(worksheetCID apiclient.CIDType, graphCID apiclient.CIDType) (r0 *apiclient.Worksheet, err error) {
+	m.record("RemoveGraphFromWorksheet", worksheetCID, graphCID)
+	if m.RemoveGraphFromWorksheetFunc == nil {
+		err = notMocked("RemoveGraphFromWorksheet")
+		return
+	}
+	return m.RemoveGraphFromWorksheetFunc(worksheetCID, graphCID)
+}
+
+// CloneWorksheet calls CloneWorksheetFunc.
+func (m *Mock) CloneWorksheet(cid apiclient.CIDType, graphMap map[string]string) (r0 *apiclient.Worksheet, err error) {
+	m.record("CloneWorksheet", cid, graphMap)
+	if m.CloneWorksheetFunc == nil {
+		err = notMocked("CloneWorksheet")
+		return
+	}
+	return m.CloneWorksheetFunc(cid, graphMap)
+}
+
+// SetWorksheetFavorite calls SetWorksheetFavoriteFunc.
+func (m *Mock) SetWorksheetFavorite(cid apiclient.CIDType, favorite bool) (r0 *apiclient.Worksheet, err error) {
+	m.record("SetWorksheetFavorite", cid, favorite)
+	if m.SetWorksheetFavoriteFunc == nil {
+		err = notMocked("SetWorksheetFavorite")
+		return
+	}
+	return m.SetWorksheetFavoriteFunc(cid, favorite)
+}
+
+// SetWorksheetDescription calls SetWorksheetDescriptionFunc.
+func (m *Mock) SetWorksheetDescription(cid apiclient.CIDType, description string) (r0 *apiclient.Worksheet, err error) {
+	m.record("SetWorksheetDescription", cid, description)
+	if m.SetWorksheetDescriptionFunc == nil {
+		err = notMocked("SetWorksheetDescription")
+		return
+	}
+	return m.SetWorksheetDescriptionFunc(cid, description)
+}
+
+// FetchFavoriteWorksheets calls FetchFavoriteWorksheetsFunc.
+func (m *Mock) FetchFavoriteWorksheets() (r0 *[]apiclient.Worksheet, err error) {
+	m.record("FetchFavoriteWorksheets")
+	if m.FetchFavoriteWorksheetsFunc == nil {
+		err = notMocked("FetchFavoriteWorksheets")
+		return
+	}
+	return m.FetchFavoriteWorksheetsFunc()
+}
+
+// FetchFavoriteWorksheetsWithContext calls FetchFavoriteWorksheetsWithContextFunc.
+func (m *Mock) FetchFavoriteWorksheetsWithContext(ctx context.Context) (r0 *[]apiclient.Worksheet, err error) {
+	m.record("FetchFavoriteWorksheetsWithContext", ctx)
+	if m.FetchFavoriteWorksheetsWithContextFunc == nil {
+		err = notMocked("FetchFavoriteWorksheetsWithContext")
+		return
+	}
+	return m.FetchFavoriteWorksheetsWithContextFunc(ctx)
+}
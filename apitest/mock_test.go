@@ -0,0 +1,80 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apitest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/circonus-labs/go-apiclient"
+	"github.com/pkg/errors"
+)
+
+// graphTitle is code under test, accepting any implementation of the API
+func graphTitle(api apiclient.GraphAPI, cid string) (string, error) {
+	g, err := api.FetchGraph(apiclient.CIDType(&cid))
+	if err != nil {
+		return "", err
+	}
+	return g.Title, nil
+}
+
+func TestMock(t *testing.T) {
+	mock := &Mock{
+		FetchGraphFunc: func(cid apiclient.CIDType) (*apiclient.Graph, error) {
+			return &apiclient.Graph{CID: *cid, Title: "web"}, nil
+		},
+	}
+
+	title, err := graphTitle(mock, "/graph/1")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if title != "web" {
+		t.Fatalf("unexpected title (%s)", title)
+	}
+
+	t.Log("not mocked")
+	{
+		alerts, err := mock.SearchAlertsWithContext(context.Background(), nil, nil)
+		if alerts != nil || err == nil || errors.Cause(err) != ErrNotMocked {
+			t.Fatalf("unexpected result (%v, %v)", alerts, err)
+		}
+		if err.Error() != "SearchAlertsWithContext: method not mocked" {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+	}
+
+	calls := mock.Calls()
+	methods := []string{}
+	for _, c := range calls {
+		methods = append(methods, c.Method)
+	}
+	if !reflect.DeepEqual(methods, []string{"FetchGraph", "SearchAlertsWithContext"}) {
+		t.Fatalf("unexpected calls (%v)", methods)
+	}
+	if cid, ok := calls[0].Args[0].(apiclient.CIDType); !ok || *cid != "/graph/1" {
+		t.Fatalf("unexpected args (%v)", calls[0].Args)
+	}
+
+	mock.Reset()
+	if len(mock.Calls()) != 0 {
+		t.Fatalf("unexpected calls (%v)", mock.Calls())
+	}
+}
+
+func TestMockAPI(t *testing.T) {
+	// the API and the mock are interchangeable
+	apih, err := apiclient.New(&apiclient.Config{TokenKey: "abc123", URL: "http://localhost"})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	for _, api := range []apiclient.CirconusAPI{apih, &Mock{}} {
+		if _, ok := api.(apiclient.CheckBundleAPI); !ok {
+			t.Fatalf("expected %T to implement CheckBundleAPI", api)
+		}
+	}
+}
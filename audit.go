@@ -0,0 +1,155 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Audit - records of the objects created, updated, and deleted by the client
+
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AuditSink receives a record of each object the client creates, updates,
+// or deletes (see Config Audit). Record is called, once the API call
+// succeeds, from the goroutine making the call; it must be safe for
+// concurrent use, and should not block.
+type AuditSink interface {
+	Record(rec AuditRecord)
+}
+
+// AuditSinkFunc adapts a function to an AuditSink
+type AuditSinkFunc func(rec AuditRecord)
+
+// Record calls f(rec).
+func (f AuditSinkFunc) Record(rec AuditRecord) {
+	f(rec)
+}
+
+// AuditRecord describes a change made by the client
+type AuditRecord struct {
+	Time      time.Time     // when the change was made
+	App       string        // API Token app name
+	AccountID string        // account acted on, if not the token's default
+	Method    string        // POST (create), PUT (update), or DELETE
+	Path      string        // request path (e.g. /graph for a create)
+	CID       string        // cid of the object changed
+	Changes   []AuditChange // fields changed, in order of field
+}
+
+// AuditChange is a field changed by a create, update, or delete
+type AuditChange struct {
+	Field string      // path of the field (e.g. "tags", "config.url")
+	Old   interface{} // value before the change, nil if the field was added
+	New   interface{} // value after the change, nil if the field was removed
+}
+
+// auditedMethod returns true for requests which change objects
+func auditedMethod(reqMethod string) bool {
+	return reqMethod == "POST" || reqMethod == "PUT" || reqMethod == "DELETE"
+}
+
+// auditing returns true if the changes made by requests with the method are
+// recorded, by the audit sink or as annotations
+func (a *API) auditing(reqMethod string) bool {
+	return (a.audit != nil || a.changeAnnotations != nil) && auditedMethod(reqMethod)
+}
+
+// auditedRequest makes a request, recording the change it made. The object
+// updated or deleted is fetched first (not from the cache), for the fields
+// changed.
+func (a *API) auditedRequest(ctx context.Context, reqMethod string, reqPath string, data []byte) ([]byte, error) {
+	var before interface{}
+	if reqMethod != "POST" {
+		// without it (e.g. the endpoint cannot be fetched) every field
+		// sent is recorded as added
+		if data, err := a.request(ctx, "GET", reqPath, nil); err != nil || a.unmarshalJSON(data, &before) != nil {
+			before = nil
+		}
+	}
+
+	result, err := a.request(ctx, reqMethod, reqPath, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var after interface{}
+	switch reqMethod {
+	case "DELETE":
+	case "POST", "PUT":
+		if a.unmarshalJSON(result, &after) != nil {
+			// not an object, record what was sent
+			_ = a.unmarshalJSON(data, &after)
+		}
+	}
+
+	cid := reqPath
+	if idx := strings.Index(cid, "?"); idx >= 0 {
+		cid = cid[:idx]
+	}
+	if obj, ok := after.(map[string]interface{}); ok {
+		if c, ok := obj["_cid"].(string); ok && c != "" {
+			cid = c
+		}
+	}
+
+	rec := AuditRecord{
+		Time:      time.Now(),
+		App:       string(a.app),
+		AccountID: string(a.accountID),
+		Method:    reqMethod,
+		Path:      reqPath,
+		CID:       cid,
+		Changes:   auditDiff("", before, after, nil),
+	}
+	if a.audit != nil {
+		a.audit.Record(rec)
+	}
+	if a.changeAnnotations != nil {
+		a.annotateChange(ctx, rec)
+	}
+
+	return result, nil
+}
+
+// auditDiff appends the changes from old to new, decoded json values, to
+// changes. Objects are compared field by field, other values as a whole.
+func auditDiff(field string, old, new interface{}, changes []AuditChange) []AuditChange {
+	oldObj, oldIsObj := old.(map[string]interface{})
+	newObj, newIsObj := new.(map[string]interface{})
+	if !oldIsObj && !newIsObj {
+		if !reflect.DeepEqual(old, new) {
+			changes = append(changes, AuditChange{Field: field, Old: old, New: new})
+		}
+		return changes
+	}
+	if (old != nil && !oldIsObj) || (new != nil && !newIsObj) {
+		// an object replaced by another type of value
+		return append(changes, AuditChange{Field: field, Old: old, New: new})
+	}
+
+	keys := make([]string, 0, len(oldObj)+len(newObj))
+	for k := range oldObj {
+		keys = append(keys, k)
+	}
+	for k := range newObj {
+		if _, ok := oldObj[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		name := k
+		if field != "" {
+			name = fmt.Sprintf("%s.%s", field, k)
+		}
+		changes = auditDiff(name, oldObj[k], newObj[k], changes)
+	}
+	return changes
+}
@@ -0,0 +1,168 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
+)
+
+func TestAudit(t *testing.T) {
+	fake, err := fakecirconus.New(&fakecirconus.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+
+	var mu sync.Mutex
+	var records []AuditRecord
+	apih, err := New(&Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      fake.URL,
+		Audit: AuditSinkFunc(func(rec AuditRecord) {
+			mu.Lock()
+			records = append(records, rec)
+			mu.Unlock()
+		}),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	acct, err := apih.WithAccount("/account/2")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	g, err := apih.CreateGraph(&Graph{Title: "web", Tags: []string{"service:web"}, Style: &[]string{"line"}[0]})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if _, err := apih.FetchGraph(CIDType(&g.CID)); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	g.Title = "web servers"
+	g.Tags = append(g.Tags, "env:prod")
+	if _, err := acct.UpdateGraph(g); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if _, err := apih.DeleteGraph(g); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("unexpected records (%#v)", records)
+	}
+	for i, rec := range records {
+		if rec.CID != g.CID || rec.App != "test" || rec.Time.IsZero() {
+			t.Fatalf("record %d: unexpected record (%#v)", i, rec)
+		}
+	}
+
+	create := records[0]
+	if create.Method != "POST" || create.Path != "/graph" || create.AccountID != "" {
+		t.Fatalf("unexpected create (%#v)", create)
+	}
+	added := map[string]interface{}{}
+	for _, c := range create.Changes {
+		if c.Old != nil {
+			t.Fatalf("unexpected create change (%#v)", c)
+		}
+		added[c.Field] = c.New
+	}
+	if added["title"] != "web" || added["_cid"] != g.CID {
+		t.Fatalf("unexpected create changes (%#v)", create.Changes)
+	}
+
+	update := records[1]
+	expected := []AuditChange{
+		{Field: "tags", Old: []interface{}{"service:web"}, New: []interface{}{"service:web", "env:prod"}},
+		{Field: "title", Old: "web", New: "web servers"},
+	}
+	if update.Method != "PUT" || update.AccountID != "2" || !reflect.DeepEqual(update.Changes, expected) {
+		t.Fatalf("unexpected update (%#v)", update)
+	}
+
+	del := records[2]
+	removed := map[string]interface{}{}
+	for _, c := range del.Changes {
+		if c.New != nil {
+			t.Fatalf("unexpected delete change (%#v)", c)
+		}
+		removed[c.Field] = c.Old
+	}
+	if del.Method != "DELETE" || removed["title"] != "web servers" {
+		t.Fatalf("unexpected delete (%#v)", del)
+	}
+
+	// failed changes are not recorded
+	if _, err := apih.UpdateGraph(&Graph{CID: "/graph/missing"}); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(records) != 3 {
+		t.Fatalf("unexpected records (%d)", len(records))
+	}
+}
+
+func TestAuditDiff(t *testing.T) {
+	tests := []struct {
+		id       string
+		old, new interface{}
+		expected []AuditChange
+	}{
+		{"unchanged", map[string]interface{}{"a": 1.0}, map[string]interface{}{"a": 1.0}, nil},
+		{"nested", map[string]interface{}{"config": map[string]interface{}{"url": "a", "port": "80"}}, map[string]interface{}{"config": map[string]interface{}{"url": "b", "port": "80"}}, []AuditChange{{Field: "config.url", Old: "a", New: "b"}}},
+		{"added and removed", map[string]interface{}{"a": 1.0}, map[string]interface{}{"b": 2.0}, []AuditChange{{Field: "a", Old: 1.0}, {Field: "b", New: 2.0}}},
+		{"replaced object", map[string]interface{}{"a": map[string]interface{}{"b": 1.0}}, map[string]interface{}{"a": "x"}, []AuditChange{{Field: "a", Old: map[string]interface{}{"b": 1.0}, New: "x"}}},
+	}
+
+	for _, test := range tests {
+		if changes := auditDiff("", test.old, test.new, nil); !reflect.DeepEqual(changes, test.expected) {
+			t.Errorf("%s: unexpected changes (%#v)", test.id, changes)
+		}
+	}
+}
+
+func TestAuditCodec(t *testing.T) {
+	fake, err := fakecirconus.New(&fakecirconus.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+
+	codec := &testCodec{}
+	var records []AuditRecord
+	apih, err := New(&Config{
+		TokenKey: "abc123",
+		URL:      fake.URL,
+		Codec:    codec,
+		Audit:    AuditSinkFunc(func(rec AuditRecord) { records = append(records, rec) }),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	// the snapshot before (fetched), and after, are decoded with the codec,
+	// as is the result
+	g, err := apih.CreateGraph(&Graph{Title: "web"})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	g.Title = "web servers"
+	codec.decoded = 0
+	if _, err := apih.UpdateGraph(g); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if n := atomic.LoadInt32(&codec.decoded); n != 3 {
+		t.Fatalf("unexpected decodes (%d)", n)
+	}
+	if len(records) != 2 || !reflect.DeepEqual(records[1].Changes, []AuditChange{{Field: "title", Old: "web", New: "web servers"}}) {
+		t.Fatalf("unexpected records (%#v)", records)
+	}
+}
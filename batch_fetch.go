@@ -0,0 +1,112 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Batch fetch - concurrent fetches of objects of different types
+
+package apiclient
+
+import (
+	"context"
+	"strings"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// BatchRequest defines an object to fetch with BatchFetch
+type BatchRequest struct {
+	// Key identifies the result - default the CID
+	Key string
+
+	// CID of the object to fetch (e.g. /alert/1234)
+	CID string
+
+	// Into is where the object is decoded, a pointer (e.g. *Alert) - default
+	// a new object of the type for the CID's prefix (required for CIDs of
+	// other endpoints)
+	Into interface{}
+}
+
+// BatchResults holds the objects fetched by BatchFetch keyed by request,
+// each a pointer to the type for the CID's prefix (e.g. *Alert for
+// /alert/1234), or the request's Into.
+type BatchResults map[string]interface{}
+
+// batchTypes returns a new object to decode into for each supported prefix
+var batchTypes = map[string]func() interface{}{
+	config.AccountPrefix:            func() interface{} { return &Account{} },
+	config.AcknowledgementPrefix:    func() interface{} { return &Acknowledgement{} },
+	config.AlertPrefix:              func() interface{} { return &Alert{} },
+	config.AnnotationPrefix:         func() interface{} { return &Annotation{} },
+	config.BrokerPrefix:             func() interface{} { return &Broker{} },
+	config.CheckBundleMetricsPrefix: func() interface{} { return &CheckBundleMetrics{} },
+	config.CheckBundlePrefix:        func() interface{} { return &CheckBundle{} },
+	config.CheckPrefix:              func() interface{} { return &Check{} },
+	config.CheckTemplatePrefix:      func() interface{} { return &CheckTemplate{} },
+	config.ContactGroupPrefix:       func() interface{} { return &ContactGroup{} },
+	config.DashboardPrefix:          func() interface{} { return &Dashboard{} },
+	config.GraphPrefix:              func() interface{} { return &Graph{} },
+	config.MaintenancePrefix:        func() interface{} { return &Maintenance{} },
+	config.MetricClusterPrefix:      func() interface{} { return &MetricCluster{} },
+	config.MetricPrefix:             func() interface{} { return &Metric{} },
+	config.OutlierReportPrefix:      func() interface{} { return &OutlierReport{} },
+	config.ProvisionBrokerPrefix:    func() interface{} { return &ProvisionBroker{} },
+	config.RuleSetGroupPrefix:       func() interface{} { return &RuleSetGroup{} },
+	config.RuleSetPrefix:            func() interface{} { return &RuleSet{} },
+	config.UserPrefix:               func() interface{} { return &User{} },
+	config.WorksheetPrefix:          func() interface{} { return &Worksheet{} },
+}
+
+// BatchFetch fetches the objects of the requests, which may be of different
+// types (e.g. an alert, its check and the check's broker), concurrently
+// (see FetchEach). The first failure stops the remaining fetches and is
+// returned.
+func (a *API) BatchFetch(requests []BatchRequest) (BatchResults, error) {
+	keys := make([]string, len(requests))
+	objs := make([]interface{}, len(requests))
+	seen := make(map[string]bool, len(requests))
+	for i, req := range requests {
+		if req.CID == "" {
+			return nil, errors.Errorf("invalid batch request %d CID (none)", i)
+		}
+		key := req.Key
+		if key == "" {
+			key = req.CID
+		}
+		if seen[key] {
+			return nil, errors.Errorf("invalid batch request %d, duplicate key (%s)", i, key)
+		}
+		seen[key] = true
+		keys[i] = key
+
+		obj := req.Into
+		if obj == nil {
+			var newObj func() interface{}
+			if idx := strings.LastIndex(req.CID, "/"); idx > 0 {
+				newObj = batchTypes[req.CID[:idx]]
+			}
+			if newObj == nil {
+				return nil, errors.Errorf("invalid batch request %d, unsupported CID (%s) without Into", i, req.CID)
+			}
+			obj = newObj()
+		}
+		objs[i] = obj
+	}
+
+	err := a.FetchEach(context.Background(), len(requests), nil, func(ctx context.Context, i int) error {
+		if err := a.getJSONContext(ctx, requests[i].CID, objs[i]); err != nil {
+			return errors.Wrapf(err, "fetching %s", requests[i].CID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(BatchResults, len(requests))
+	for i, key := range keys {
+		results[key] = objs[i]
+	}
+	return results, nil
+}
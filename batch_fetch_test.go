@@ -0,0 +1,140 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testBatchFetchServer() *httptest.Server {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if r.Method != "GET" {
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
+			return
+		}
+
+		var ret interface{}
+		switch path {
+		case "/alert/1":
+			ret = Alert{CID: "/alert/1", CheckCID: "/check/2"}
+		case "/check/2":
+			ret = Check{CID: "/check/2", BrokerCID: "/broker/3"}
+		case "/broker/3":
+			ret = Broker{CID: "/broker/3", Name: "broker"}
+		case "/metric/2_cpu":
+			ret = Metric{CID: "/metric/2_cpu", MetricName: "cpu"}
+		case "/snapshot/4":
+			ret = map[string]string{"_cid": "/snapshot/4"}
+		default:
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
+			return
+		}
+
+		b, err := json.Marshal(ret)
+		if err != nil {
+			panic(err)
+		}
+		w.WriteHeader(200)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, string(b))
+	}
+
+	return httptest.NewServer(http.HandlerFunc(f))
+}
+
+func batchFetchTestBootstrap(t *testing.T) (*API, *httptest.Server) {
+	server := testBatchFetchServer()
+
+	ac := &Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      server.URL,
+	}
+	apih, err := NewAPI(ac)
+	if err != nil {
+		server.Close()
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	return apih, server
+}
+
+func TestBatchFetch(t *testing.T) {
+	apih, server := batchFetchTestBootstrap(t)
+	defer server.Close()
+
+	t.Run("typed", func(t *testing.T) {
+		snapshot := map[string]string{}
+		results, err := apih.BatchFetch([]BatchRequest{
+			{CID: "/alert/1"},
+			{Key: "check", CID: "/check/2"},
+			{Key: "broker", CID: "/broker/3"},
+			{CID: "/metric/2_cpu"},
+			{CID: "/snapshot/4", Into: &snapshot},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if len(results) != 5 {
+			t.Fatalf("unexpected results (%v)", results)
+		}
+		if alert, ok := results["/alert/1"].(*Alert); !ok || alert.CheckCID != "/check/2" {
+			t.Fatalf("unexpected alert (%#v)", results["/alert/1"])
+		}
+		if check, ok := results["check"].(*Check); !ok || check.BrokerCID != "/broker/3" {
+			t.Fatalf("unexpected check (%#v)", results["check"])
+		}
+		if broker, ok := results["broker"].(*Broker); !ok || broker.Name != "broker" {
+			t.Fatalf("unexpected broker (%#v)", results["broker"])
+		}
+		if metric, ok := results["/metric/2_cpu"].(*Metric); !ok || metric.MetricName != "cpu" {
+			t.Fatalf("unexpected metric (%#v)", results["/metric/2_cpu"])
+		}
+		if results["/snapshot/4"] != &snapshot || snapshot["_cid"] != "/snapshot/4" {
+			t.Fatalf("unexpected snapshot (%#v)", results["/snapshot/4"])
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		results, err := apih.BatchFetch(nil)
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if len(results) != 0 {
+			t.Fatalf("unexpected results (%v)", results)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			id          string
+			requests    []BatchRequest
+			expectedErr string
+		}{
+			{"no cid", []BatchRequest{{Key: "a"}}, "invalid batch request 0 CID (none)"},
+			{"duplicate", []BatchRequest{{CID: "/alert/1"}, {Key: "/alert/1", CID: "/check/2"}}, "invalid batch request 1, duplicate key (/alert/1)"},
+			{"unsupported", []BatchRequest{{CID: "/snapshot/4"}}, "invalid batch request 0, unsupported CID (/snapshot/4) without Into"},
+			{"no prefix", []BatchRequest{{CID: "alert"}}, "invalid batch request 0, unsupported CID (alert) without Into"},
+			{"not found", []BatchRequest{{CID: "/alert/1"}, {CID: "/graph/2"}}, "fetching /graph/2: "},
+		}
+		for _, test := range tests {
+			_, err := apih.BatchFetch(test.requests)
+			if err == nil {
+				t.Fatalf("%s: expected error", test.id)
+			}
+			if !strings.HasPrefix(err.Error(), test.expectedErr) {
+				t.Fatalf("%s: unexpected error (%s)", test.id, err)
+			}
+		}
+	})
+}
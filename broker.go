@@ -8,10 +8,8 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
-	"regexp"
 	"strings"
 
 	"github.com/circonus-labs/go-apiclient/config"
@@ -46,6 +44,12 @@ type Broker struct {
 
 // FetchBroker retrieves broker with passed cid.
 func (a *API) FetchBroker(cid CIDType) (*Broker, error) {
+	return a.FetchBrokerWithContext(context.Background(), cid)
+}
+
+// FetchBrokerWithContext retrieves broker with passed cid, stopping when ctx
+// is done.
+func (a *API) FetchBrokerWithContext(ctx context.Context, cid CIDType) (*Broker, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.Errorf("invalid broker CID (none)")
 	}
@@ -57,25 +61,21 @@ func (a *API) FetchBroker(cid CIDType) (*Broker, error) {
 		brokerCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.BrokerCIDRegex, brokerCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.BrokerPrefix, brokerCID) {
 		return nil, errors.Errorf("invalid broker CID (%s)", brokerCID)
 	}
 
-	result, err := a.Get(brokerCID)
+	result, err := a.GetWithContext(ctx, brokerCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching broker")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch broker, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch broker, received JSON: %s", string(result))
 	}
 
 	response := new(Broker)
-	if err := json.Unmarshal(result, &response); err != nil {
+	if err := a.unmarshalJSON(result, &response); err != nil {
 		return nil, errors.Wrap(err, "parsing broker")
 	}
 
@@ -85,14 +85,15 @@ func (a *API) FetchBroker(cid CIDType) (*Broker, error) {
 
 // FetchBrokers returns all brokers available to the API Token.
 func (a *API) FetchBrokers() (*[]Broker, error) {
-	result, err := a.Get(config.BrokerPrefix)
-	if err != nil {
-		return nil, errors.Wrap(err, "fetching brokers")
-	}
+	return a.FetchBrokersWithContext(context.Background())
+}
 
+// FetchBrokersWithContext returns all brokers available to the API Token,
+// stopping when ctx is done.
+func (a *API) FetchBrokersWithContext(ctx context.Context) (*[]Broker, error) {
 	var response []Broker
-	if err := json.Unmarshal(result, &response); err != nil {
-		return nil, errors.Wrap(err, "parsing brokers")
+	if err := a.getJSONContext(ctx, config.BrokerPrefix, &response); err != nil {
+		return nil, errors.Wrap(err, "fetching brokers")
 	}
 
 	return &response, nil
@@ -102,37 +103,19 @@ func (a *API) FetchBrokers() (*[]Broker, error) {
 // query and/or filter. If nil is passed for both parameters
 // all brokers will be returned.
 func (a *API) SearchBrokers(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Broker, error) {
-	q := url.Values{}
-
-	if searchCriteria != nil && *searchCriteria != "" {
-		q.Set("search", string(*searchCriteria))
-	}
-
-	if filterCriteria != nil && len(*filterCriteria) > 0 {
-		for filter, criteria := range *filterCriteria {
-			for _, val := range criteria {
-				q.Add(filter, val)
-			}
-		}
-	}
-
-	if q.Encode() == "" {
-		return a.FetchBrokers()
-	}
-
-	reqURL := url.URL{
-		Path:     config.BrokerPrefix,
-		RawQuery: q.Encode(),
-	}
+	return a.SearchBrokersWithContext(context.Background(), searchCriteria, filterCriteria)
+}
 
-	result, err := a.Get(reqURL.String())
-	if err != nil {
-		return nil, errors.Wrap(err, "searching brokers")
+// SearchBrokersWithContext returns brokers matching the specified search
+// query and/or filter, stopping when ctx is done.
+func (a *API) SearchBrokersWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Broker, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchBrokersWithContext(ctx)
 	}
 
 	var brokers []Broker
-	if err := json.Unmarshal(result, &brokers); err != nil {
-		return nil, errors.Wrap(err, "parsing brokers")
+	if err := a.searchJSONContext(ctx, config.BrokerPrefix, searchCriteria, filterCriteria, &brokers); err != nil {
+		return nil, errors.Wrap(err, "searching brokers")
 	}
 
 	return &brokers, nil
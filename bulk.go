@@ -0,0 +1,297 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Bulk operations - create, update, and delete many objects concurrently
+
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// BulkOptions defines how bulk operations make their requests
+type BulkOptions struct {
+	// Workers defines the number of concurrent requests - default
+	// DefaultFetchWorkers
+	Workers int
+
+	// StopOnError stops at the first failure, items not yet attempted fail
+	// as not attempted - by default every item is attempted
+	StopOnError bool
+}
+
+// BulkResult defines the outcome of a bulk operation, by item (in the order
+// of the items passed)
+type BulkResult struct {
+	CIDs []string // of the objects, "" for those which failed to be created
+	Errs []error  // nil for the items which succeeded
+}
+
+// Failed returns the indexes of the items which failed.
+func (r *BulkResult) Failed() []int {
+	failed := []int{}
+	for i, err := range r.Errs {
+		if err != nil {
+			failed = append(failed, i)
+		}
+	}
+	return failed
+}
+
+// Err returns an error summarizing the items which failed, nil if none
+// failed.
+func (r *BulkResult) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(failed))
+	for i, idx := range failed {
+		item := fmt.Sprintf("item %d", idx)
+		if r.CIDs[idx] != "" {
+			item = r.CIDs[idx]
+		}
+		msgs[i] = fmt.Sprintf("%s: %s", item, r.Errs[idx])
+	}
+	return errors.Errorf("bulk operation, %d of %d failed (%s)", len(failed), len(r.Errs), strings.Join(msgs, "; "))
+}
+
+// bulk calls op for each of n items using a pool of workers, op returning
+// the cid of the item's object
+func (a *API) bulk(ctx context.Context, n int, opts *BulkOptions, op func(ctx context.Context, i int) (string, error)) *BulkResult {
+	result := &BulkResult{CIDs: make([]string, n), Errs: make([]error, n)}
+	fetchOpts := &FetchAllOptions{}
+	stop := false
+	if opts != nil {
+		fetchOpts.Workers = opts.Workers
+		stop = opts.StopOnError
+	}
+
+	done := make([]bool, n)
+	err := a.FetchEach(ctx, n, fetchOpts, func(ctx context.Context, i int) error {
+		cid, err := op(ctx, i)
+		result.CIDs[i], result.Errs[i], done[i] = cid, err, true
+		if stop {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		for i := range done {
+			if !done[i] {
+				result.Errs[i] = errors.Wrap(err, "not attempted")
+			}
+		}
+	}
+
+	return result
+}
+
+// bulkDelete deletes the objects with the passed cids with del
+func (a *API) bulkDelete(ctx context.Context, cids []string, opts *BulkOptions, del func(ctx context.Context, cid CIDType) (bool, error)) *BulkResult {
+	return a.bulk(ctx, len(cids), opts, func(ctx context.Context, i int) (string, error) {
+		cid := cids[i]
+		_, err := del(ctx, CIDType(&cid))
+		return cid, err
+	})
+}
+
+// BulkCreateCheckBundles creates the check bundles, returning those created
+// (nil for failures) and the result of each.
+func (a *API) BulkCreateCheckBundles(ctx context.Context, cfgs []*CheckBundle, opts *BulkOptions) ([]*CheckBundle, *BulkResult) {
+	created := make([]*CheckBundle, len(cfgs))
+	result := a.bulk(ctx, len(cfgs), opts, func(ctx context.Context, i int) (string, error) {
+		b, err := a.CreateCheckBundleWithContext(ctx, cfgs[i])
+		if err != nil {
+			return "", err
+		}
+		created[i] = b
+		return b.CID, nil
+	})
+	return created, result
+}
+
+// BulkUpdateCheckBundles updates the check bundles, returning those updated
+// (nil for failures) and the result of each.
+func (a *API) BulkUpdateCheckBundles(ctx context.Context, cfgs []*CheckBundle, opts *BulkOptions) ([]*CheckBundle, *BulkResult) {
+	updated := make([]*CheckBundle, len(cfgs))
+	result := a.bulk(ctx, len(cfgs), opts, func(ctx context.Context, i int) (string, error) {
+		if cfgs[i] == nil {
+			return "", errors.New("invalid check bundle config (nil)")
+		}
+		b, err := a.UpdateCheckBundleWithContext(ctx, cfgs[i])
+		if err != nil {
+			return cfgs[i].CID, err
+		}
+		updated[i] = b
+		return b.CID, nil
+	})
+	return updated, result
+}
+
+// BulkDeleteCheckBundles deletes the check bundles with the passed cids,
+// returning the result of each.
+func (a *API) BulkDeleteCheckBundles(ctx context.Context, cids []string, opts *BulkOptions) *BulkResult {
+	return a.bulkDelete(ctx, cids, opts, a.DeleteCheckBundleByCIDWithContext)
+}
+
+// BulkCreateRuleSets creates the rule sets, returning those created (nil
+// for failures) and the result of each.
+func (a *API) BulkCreateRuleSets(ctx context.Context, cfgs []*RuleSet, opts *BulkOptions) ([]*RuleSet, *BulkResult) {
+	created := make([]*RuleSet, len(cfgs))
+	result := a.bulk(ctx, len(cfgs), opts, func(ctx context.Context, i int) (string, error) {
+		rs, err := a.CreateRuleSetWithContext(ctx, cfgs[i])
+		if err != nil {
+			return "", err
+		}
+		created[i] = rs
+		return rs.CID, nil
+	})
+	return created, result
+}
+
+// BulkUpdateRuleSets updates the rule sets, returning those updated (nil
+// for failures) and the result of each.
+func (a *API) BulkUpdateRuleSets(ctx context.Context, cfgs []*RuleSet, opts *BulkOptions) ([]*RuleSet, *BulkResult) {
+	updated := make([]*RuleSet, len(cfgs))
+	result := a.bulk(ctx, len(cfgs), opts, func(ctx context.Context, i int) (string, error) {
+		if cfgs[i] == nil {
+			return "", errors.New("invalid rule set config (nil)")
+		}
+		rs, err := a.UpdateRuleSetWithContext(ctx, cfgs[i])
+		if err != nil {
+			return cfgs[i].CID, err
+		}
+		updated[i] = rs
+		return rs.CID, nil
+	})
+	return updated, result
+}
+
+// BulkDeleteRuleSets deletes the rule sets with the passed cids, returning
+// the result of each.
+func (a *API) BulkDeleteRuleSets(ctx context.Context, cids []string, opts *BulkOptions) *BulkResult {
+	return a.bulkDelete(ctx, cids, opts, a.DeleteRuleSetByCIDWithContext)
+}
+
+// BulkCreateAnnotations creates the annotations, returning those created
+// (nil for failures) and the result of each.
+func (a *API) BulkCreateAnnotations(ctx context.Context, cfgs []*Annotation, opts *BulkOptions) ([]*Annotation, *BulkResult) {
+	created := make([]*Annotation, len(cfgs))
+	result := a.bulk(ctx, len(cfgs), opts, func(ctx context.Context, i int) (string, error) {
+		an, err := a.CreateAnnotationWithContext(ctx, cfgs[i])
+		if err != nil {
+			return "", err
+		}
+		created[i] = an
+		return an.CID, nil
+	})
+	return created, result
+}
+
+// BulkUpdateAnnotations updates the annotations, returning those updated
+// (nil for failures) and the result of each.
+func (a *API) BulkUpdateAnnotations(ctx context.Context, cfgs []*Annotation, opts *BulkOptions) ([]*Annotation, *BulkResult) {
+	updated := make([]*Annotation, len(cfgs))
+	result := a.bulk(ctx, len(cfgs), opts, func(ctx context.Context, i int) (string, error) {
+		if cfgs[i] == nil {
+			return "", errors.New("invalid annotation config (nil)")
+		}
+		an, err := a.UpdateAnnotationWithContext(ctx, cfgs[i])
+		if err != nil {
+			return cfgs[i].CID, err
+		}
+		updated[i] = an
+		return an.CID, nil
+	})
+	return updated, result
+}
+
+// BulkDeleteAnnotations deletes the annotations with the passed cids,
+// returning the result of each.
+func (a *API) BulkDeleteAnnotations(ctx context.Context, cids []string, opts *BulkOptions) *BulkResult {
+	return a.bulkDelete(ctx, cids, opts, a.DeleteAnnotationByCIDWithContext)
+}
+
+// BulkCreateGraphs creates the graphs, returning those created (nil for
+// failures) and the result of each.
+func (a *API) BulkCreateGraphs(ctx context.Context, cfgs []*Graph, opts *BulkOptions) ([]*Graph, *BulkResult) {
+	created := make([]*Graph, len(cfgs))
+	result := a.bulk(ctx, len(cfgs), opts, func(ctx context.Context, i int) (string, error) {
+		g, err := a.CreateGraphWithContext(ctx, cfgs[i])
+		if err != nil {
+			return "", err
+		}
+		created[i] = g
+		return g.CID, nil
+	})
+	return created, result
+}
+
+// BulkUpdateGraphs updates the graphs, returning those updated (nil for
+// failures) and the result of each.
+func (a *API) BulkUpdateGraphs(ctx context.Context, cfgs []*Graph, opts *BulkOptions) ([]*Graph, *BulkResult) {
+	updated := make([]*Graph, len(cfgs))
+	result := a.bulk(ctx, len(cfgs), opts, func(ctx context.Context, i int) (string, error) {
+		if cfgs[i] == nil {
+			return "", errors.New("invalid graph config (nil)")
+		}
+		g, err := a.UpdateGraphWithContext(ctx, cfgs[i])
+		if err != nil {
+			return cfgs[i].CID, err
+		}
+		updated[i] = g
+		return g.CID, nil
+	})
+	return updated, result
+}
+
+// BulkDeleteGraphs deletes the graphs with the passed cids, returning the
+// result of each.
+func (a *API) BulkDeleteGraphs(ctx context.Context, cids []string, opts *BulkOptions) *BulkResult {
+	return a.bulkDelete(ctx, cids, opts, a.DeleteGraphByCIDWithContext)
+}
+
+// BulkCreateMaintenanceWindows creates the maintenance windows, returning
+// those created (nil for failures) and the result of each.
+func (a *API) BulkCreateMaintenanceWindows(ctx context.Context, cfgs []*Maintenance, opts *BulkOptions) ([]*Maintenance, *BulkResult) {
+	created := make([]*Maintenance, len(cfgs))
+	result := a.bulk(ctx, len(cfgs), opts, func(ctx context.Context, i int) (string, error) {
+		m, err := a.CreateMaintenanceWindowWithContext(ctx, cfgs[i])
+		if err != nil {
+			return "", err
+		}
+		created[i] = m
+		return m.CID, nil
+	})
+	return created, result
+}
+
+// BulkUpdateMaintenanceWindows updates the maintenance windows, returning
+// those updated (nil for failures) and the result of each.
+func (a *API) BulkUpdateMaintenanceWindows(ctx context.Context, cfgs []*Maintenance, opts *BulkOptions) ([]*Maintenance, *BulkResult) {
+	updated := make([]*Maintenance, len(cfgs))
+	result := a.bulk(ctx, len(cfgs), opts, func(ctx context.Context, i int) (string, error) {
+		if cfgs[i] == nil {
+			return "", errors.New("invalid maintenance window config (nil)")
+		}
+		m, err := a.UpdateMaintenanceWindowWithContext(ctx, cfgs[i])
+		if err != nil {
+			return cfgs[i].CID, err
+		}
+		updated[i] = m
+		return m.CID, nil
+	})
+	return updated, result
+}
+
+// BulkDeleteMaintenanceWindows deletes the maintenance windows with the
+// passed cids, returning the result of each.
+func (a *API) BulkDeleteMaintenanceWindows(ctx context.Context, cids []string, opts *BulkOptions) *BulkResult {
+	return a.bulkDelete(ctx, cids, opts, a.DeleteMaintenanceWindowByCIDWithContext)
+}
@@ -0,0 +1,374 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Bulk delete - guarded deletion of many objects
+
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// bulkDeleteOrder defines the order objects are deleted in, objects which
+// reference others first. Only these types can be bulk deleted.
+var bulkDeleteOrder = []string{
+	config.DashboardPrefix,
+	config.WorksheetPrefix,
+	config.MaintenancePrefix,
+	config.RuleSetGroupPrefix,
+	config.RuleSetPrefix,
+	config.GraphPrefix,
+	config.MetricClusterPrefix,
+	config.CheckBundlePrefix,
+	config.ContactGroupPrefix,
+	config.AnnotationPrefix,
+}
+
+// bulkDeleteReferrers defines the types of objects which may reference an
+// object of a type
+var bulkDeleteReferrers = map[string][]string{
+	config.CheckBundlePrefix:   {config.RuleSetPrefix, config.GraphPrefix, config.MaintenancePrefix},
+	config.ContactGroupPrefix:  {config.RuleSetPrefix},
+	config.GraphPrefix:         {config.DashboardPrefix, config.WorksheetPrefix},
+	config.MetricClusterPrefix: {config.GraphPrefix, config.DashboardPrefix},
+	config.RuleSetPrefix:       {config.RuleSetGroupPrefix, config.MaintenancePrefix},
+}
+
+// BulkDeleteConfig defines the objects to delete and how
+type BulkDeleteConfig struct {
+	// CIDs of the objects to delete
+	CIDs []string
+
+	// Type (cid prefix, e.g. config.GraphPrefix) of objects to search for,
+	// matching objects are deleted along with CIDs
+	Type   string
+	Search *SearchQueryType
+	Filter *SearchFilterType
+
+	// DryRun reports what would be deleted, without deleting anything
+	DryRun bool
+	// Force deletes objects still referenced by objects not being deleted,
+	// by default they are skipped
+	Force bool
+	// MaxDeletes refuses to delete more objects, 0 for no limit
+	MaxDeletes int
+	// Interval is the minimum time between deletes
+	Interval time.Duration
+	// FetchWorkers defines the number of concurrent reads made to find the
+	// objects referencing those being deleted - default DefaultFetchWorkers
+	FetchWorkers int
+	// Progress, if set, is called after each object is handled
+	Progress func(BulkDeleteProgress)
+}
+
+// BulkDeleteProgress defines the progress of a bulk delete
+type BulkDeleteProgress struct {
+	CID   string
+	Done  int   // objects handled, including CID
+	Total int   // objects to handle
+	Err   error // nil if deleted (or would be, for a dry run)
+}
+
+// BulkDeleteSkip defines an object not deleted, and why
+type BulkDeleteSkip struct {
+	CID    string
+	Reason string
+}
+
+// BulkDeleteFailure defines an object which failed to delete
+type BulkDeleteFailure struct {
+	CID string
+	Err error
+}
+
+// BulkDeleteResult defines the outcome of a bulk delete
+type BulkDeleteResult struct {
+	DryRun  bool
+	Deleted []string // deleted, or would be deleted for a dry run
+	Skipped []BulkDeleteSkip
+	Failed  []BulkDeleteFailure
+}
+
+// Err returns an error summarizing the failed deletes, nil if none failed.
+func (r *BulkDeleteResult) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(r.Failed))
+	for i, f := range r.Failed {
+		msgs[i] = fmt.Sprintf("%s: %s", f.CID, f.Err)
+	}
+	return errors.Errorf("bulk delete, %d of %d failed (%s)", len(r.Failed), len(r.Failed)+len(r.Deleted), strings.Join(msgs, "; "))
+}
+
+// BulkDelete deletes the objects in the passed config, referencing objects
+// first. Objects still referenced by other objects (e.g. a graph on a
+// dashboard) are skipped unless Force is set. Deletion continues past
+// failures, which are reported in the result. An error is returned only if
+// the objects to delete could not be determined.
+func (a *API) BulkDelete(ctx context.Context, cfg *BulkDeleteConfig) (*BulkDeleteResult, error) {
+	if cfg == nil {
+		return nil, errors.New("invalid bulk delete config (nil)")
+	}
+
+	targets, err := a.bulkDeleteTargets(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxDeletes > 0 && len(targets) > cfg.MaxDeletes {
+		return nil, errors.Errorf("refusing to delete %d objects (MaxDeletes %d)", len(targets), cfg.MaxDeletes)
+	}
+
+	result := &BulkDeleteResult{DryRun: cfg.DryRun}
+
+	blocked := map[string]string{}
+	if !cfg.Force {
+		if blocked, err = a.bulkDeleteDependents(ctx, targets, &FetchAllOptions{Workers: cfg.FetchWorkers}); err != nil {
+			return nil, err
+		}
+	}
+
+	var last time.Time
+	for i, cid := range targets {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		var delErr error
+		switch {
+		case blocked[cid] != "":
+			result.Skipped = append(result.Skipped, BulkDeleteSkip{CID: cid, Reason: "referenced by " + blocked[cid]})
+			delErr = errors.Errorf("skipped, referenced by %s", blocked[cid])
+		case cfg.DryRun:
+			result.Deleted = append(result.Deleted, cid)
+		default:
+			if wait := cfg.Interval - time.Since(last); cfg.Interval > 0 && wait > 0 {
+				select {
+				case <-ctx.Done():
+					return result, ctx.Err()
+				case <-time.After(wait):
+				}
+			}
+			last = time.Now()
+			if _, delErr = a.DeleteWithContext(ctx, cid); delErr != nil {
+				delErr = errors.Wrapf(delErr, "deleting %s", cid)
+				result.Failed = append(result.Failed, BulkDeleteFailure{CID: cid, Err: delErr})
+			} else {
+				result.Deleted = append(result.Deleted, cid)
+			}
+		}
+
+		if a.debugEnabled() {
+			a.apiLog().Printf("bulk delete, %d/%d %s (dry run %t): %v", i+1, len(targets), cid, cfg.DryRun, delErr)
+		}
+		if cfg.Progress != nil {
+			cfg.Progress(BulkDeleteProgress{CID: cid, Done: i + 1, Total: len(targets), Err: delErr})
+		}
+	}
+
+	return result, nil
+}
+
+// bulkDeleteTargets returns the unique cids to delete, in delete order
+func (a *API) bulkDeleteTargets(cfg *BulkDeleteConfig) ([]string, error) {
+	cids := append([]string{}, cfg.CIDs...)
+
+	if cfg.Type != "" {
+		if bulkDeleteRank(cfg.Type) < 0 {
+			return nil, errors.Errorf("invalid bulk delete type (%s)", cfg.Type)
+		}
+		found, err := a.searchCIDs(cfg.Type, cfg.Search, cfg.Filter)
+		if err != nil {
+			return nil, err
+		}
+		cids = append(cids, found...)
+	} else if cfg.Search != nil || cfg.Filter != nil {
+		return nil, errors.New("invalid bulk delete config, Type required to search")
+	}
+
+	seen := make(map[string]bool, len(cids))
+	targets := make([]string, 0, len(cids))
+	for _, cid := range cids {
+		if cid == "" {
+			return nil, errors.New("invalid bulk delete CID (none)")
+		}
+		if bulkDeleteRank(cidPrefix(cid)) < 0 {
+			return nil, errors.Errorf("invalid bulk delete CID (%s)", cid)
+		}
+		if !seen[cid] {
+			seen[cid] = true
+			targets = append(targets, cid)
+		}
+	}
+
+	sort.SliceStable(targets, func(i, j int) bool {
+		return bulkDeleteRank(cidPrefix(targets[i])) < bulkDeleteRank(cidPrefix(targets[j]))
+	})
+
+	return targets, nil
+}
+
+// searchCIDs returns the cids of the objects of a type matching the search and filter
+func (a *API) searchCIDs(prefix string, search *SearchQueryType, filter *SearchFilterType) ([]string, error) {
+	var objs []struct {
+		CID string `json:"_cid"`
+	}
+	if err := a.searchJSON(prefix, search, filter, &objs); err != nil {
+		return nil, errors.Wrapf(err, "searching %s", prefix)
+	}
+
+	cids := make([]string, 0, len(objs))
+	for _, o := range objs {
+		cids = append(cids, o.CID)
+	}
+	return cids, nil
+}
+
+// bulkDeleteDependents returns the targets referenced by objects which are
+// not deleted (not targets, or targets which are blocked themselves), with
+// the cid of (one of) the referencing objects
+func (a *API) bulkDeleteDependents(ctx context.Context, targets []string, opts *FetchAllOptions) (map[string]string, error) {
+	isTarget := make(map[string]bool, len(targets))
+	for _, cid := range targets {
+		isTarget[cid] = true
+	}
+
+	// references to the checks of a bundle are references to the bundle
+	refs := make(map[string]string) // referenced cid => target
+	referrerTypes := make(map[string]bool)
+	var bundles []string
+	for _, cid := range targets {
+		prefix := cidPrefix(cid)
+		for _, t := range bulkDeleteReferrers[prefix] {
+			referrerTypes[t] = true
+		}
+		if _, ok := bulkDeleteReferrers[prefix]; !ok {
+			continue
+		}
+		refs[cid] = cid
+		if prefix == config.CheckBundlePrefix {
+			bundles = append(bundles, cid)
+		}
+	}
+
+	types := make([]string, 0, len(referrerTypes))
+	for t := range referrerTypes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	// the bundles and the objects of each referrer type, fetched together
+	results, err := a.FetchAll(ctx, append(append([]string{}, bundles...), types...), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, cid := range bundles {
+		var bundle CheckBundle
+		if err := a.unmarshalJSON(results[i], &bundle); err != nil {
+			return nil, errors.Wrapf(err, "parsing %s", cid)
+		}
+		for _, check := range bundle.Checks {
+			refs[check] = cid
+		}
+	}
+
+	type reference struct {
+		referrer string
+		targets  []string
+	}
+	var references []reference
+	for i, t := range types {
+		var referrers []map[string]interface{}
+		if err := a.unmarshalJSON(results[len(bundles)+i], &referrers); err != nil {
+			return nil, errors.Wrapf(err, "parsing %s", t)
+		}
+		for _, r := range referrers {
+			cid, _ := r["_cid"].(string)
+			if targets := referencedTargets(r, refs); len(targets) > 0 {
+				references = append(references, reference{referrer: cid, targets: targets})
+			}
+		}
+	}
+
+	// targets referenced by objects which are kept (not targets, or targets
+	// which are themselves blocked) are blocked, until none are added
+	blocked := make(map[string]string)
+	for added := true; added; {
+		added = false
+		for _, r := range references {
+			if isTarget[r.referrer] && blocked[r.referrer] == "" {
+				continue
+			}
+			for _, target := range r.targets {
+				if _, ok := blocked[target]; !ok && target != r.referrer {
+					blocked[target] = r.referrer
+					added = true
+				}
+			}
+		}
+	}
+
+	return blocked, nil
+}
+
+// referencedTargets returns the targets referenced by an object
+func referencedTargets(v interface{}, refs map[string]string) []string {
+	var found []string
+	var walk func(key string, v interface{})
+	walk = func(key string, v interface{}) {
+		switch t := v.(type) {
+		case string:
+			if prefix, ok := config.BareIDFields[key]; ok {
+				t = prefix + "/" + t
+			}
+			if target, ok := refs[t]; ok {
+				found = append(found, target)
+			}
+		case float64:
+			if prefix, ok := config.BareIDFields[key]; ok {
+				if target, ok := refs[fmt.Sprintf("%s/%d", prefix, int64(t))]; ok {
+					found = append(found, target)
+				}
+			}
+		case map[string]interface{}:
+			for k, val := range t {
+				if k != "_cid" {
+					walk(k, val)
+				}
+			}
+		case []interface{}:
+			for _, val := range t {
+				walk(key, val)
+			}
+		}
+	}
+	walk("", v)
+	return found
+}
+
+// bulkDeleteRank returns the delete order of a type, -1 if it cannot be bulk deleted
+func bulkDeleteRank(prefix string) int {
+	for i, p := range bulkDeleteOrder {
+		if p == prefix {
+			return i
+		}
+	}
+	return -1
+}
+
+// cidPrefix returns the type prefix of a cid (e.g. /graph for /graph/1234)
+func cidPrefix(cid string) string {
+	if idx := strings.Index(strings.TrimPrefix(cid, "/"), "/"); idx >= 0 {
+		return cid[:idx+1]
+	}
+	return cid
+}
@@ -0,0 +1,217 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// testBulkDeleteServer serves objects referencing the objects deleted, and
+// accepts deletes of /check_bundle/1, /contact_group/1, /graph/1, /graph/2 and
+// /rule_set/2
+func testBulkDeleteServer(ruleSetGroups []RuleSetGroup) *httptest.Server {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		reqURL := r.URL.String()
+		switch r.Method {
+		case "GET":
+			var obj interface{}
+			switch reqURL {
+			case "/check_bundle/1":
+				obj = CheckBundle{CID: "/check_bundle/1", Checks: []string{"/check/11"}}
+			case "/graph?search=old":
+				obj = []Graph{{CID: "/graph/2"}}
+			case "/dashboard":
+				obj = []Dashboard{
+					{CID: "/dashboard/1", Widgets: []DashboardWidget{{Settings: DashboardWidgetSettings{GraphUUID: "1"}}}},
+				}
+			case "/graph":
+				obj = []Graph{{CID: "/graph/1"}, {CID: "/graph/2"}}
+			case "/maintenance":
+				obj = []Maintenance{}
+			case "/rule_set_group":
+				obj = ruleSetGroups
+			case "/rule_set":
+				obj = []RuleSet{
+					{CID: "/rule_set/1", ContactGroups: map[uint8][]string{1: {"/contact_group/1"}}},
+					{CID: "/rule_set/2", CheckCID: "/check/11"},
+				}
+			case "/worksheet":
+				obj = []Worksheet{}
+			default:
+				w.WriteHeader(404)
+				fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, reqURL))
+				return
+			}
+			ret, err := json.Marshal(obj)
+			if err != nil {
+				panic(err)
+			}
+			w.WriteHeader(200)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintln(w, string(ret))
+		case "DELETE":
+			switch path {
+			case "/check_bundle/1", "/contact_group/1", "/graph/1", "/graph/2", "/rule_set/2":
+				w.WriteHeader(200)
+				w.Header().Set("Content-Type", "application/json")
+			default:
+				w.WriteHeader(404)
+				fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
+			}
+		default:
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
+		}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(f))
+}
+
+func bulkDeleteTestBootstrap(t *testing.T, ruleSetGroups []RuleSetGroup) (*API, *httptest.Server) {
+	server := testBulkDeleteServer(ruleSetGroups)
+
+	ac := &Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      server.URL,
+	}
+	apih, err := NewAPI(ac)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+		server.Close()
+		return nil, nil
+	}
+
+	return apih, server
+}
+
+func TestBulkDelete(t *testing.T) {
+	apih, server := bulkDeleteTestBootstrap(t, []RuleSetGroup{})
+	defer server.Close()
+
+	search := SearchQueryType("old")
+	cfg := &BulkDeleteConfig{
+		CIDs:   []string{"/check_bundle/1", "/contact_group/1", "/graph/1", "/rule_set/2", "/graph/9"},
+		Type:   "/graph",
+		Search: &search,
+		DryRun: true,
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		result, err := apih.BulkDelete(context.Background(), cfg)
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		expectedDeleted := []string{"/rule_set/2", "/graph/9", "/graph/2", "/check_bundle/1"}
+		if !reflect.DeepEqual(result.Deleted, expectedDeleted) {
+			t.Fatalf("unexpected deleted (%v)", result.Deleted)
+		}
+		expectedSkipped := []BulkDeleteSkip{
+			{CID: "/graph/1", Reason: "referenced by /dashboard/1"},
+			{CID: "/contact_group/1", Reason: "referenced by /rule_set/1"},
+		}
+		if !reflect.DeepEqual(result.Skipped, expectedSkipped) {
+			t.Fatalf("unexpected skipped (%v)", result.Skipped)
+		}
+		if result.Err() != nil {
+			t.Fatalf("unexpected error (%s)", result.Err())
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		cfg.DryRun = false
+		var progress []BulkDeleteProgress
+		cfg.Progress = func(p BulkDeleteProgress) { progress = append(progress, p) }
+
+		result, err := apih.BulkDelete(context.Background(), cfg)
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		expectedDeleted := []string{"/rule_set/2", "/graph/2", "/check_bundle/1"}
+		if !reflect.DeepEqual(result.Deleted, expectedDeleted) {
+			t.Fatalf("unexpected deleted (%v)", result.Deleted)
+		}
+		if len(result.Failed) != 1 || result.Failed[0].CID != "/graph/9" {
+			t.Fatalf("unexpected failed (%v)", result.Failed)
+		}
+		if result.Err() == nil {
+			t.Fatal("expected error")
+		}
+		if len(progress) != 6 || progress[5].Done != 6 || progress[5].Total != 6 {
+			t.Fatalf("unexpected progress (%v)", progress)
+		}
+	})
+
+	t.Run("force", func(t *testing.T) {
+		result, err := apih.BulkDelete(context.Background(), &BulkDeleteConfig{CIDs: []string{"/graph/1"}, Force: true})
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if !reflect.DeepEqual(result.Deleted, []string{"/graph/1"}) {
+			t.Fatalf("unexpected deleted (%v)", result.Deleted)
+		}
+	})
+}
+
+func TestBulkDeleteBlockedReferrer(t *testing.T) {
+	apih, server := bulkDeleteTestBootstrap(t, []RuleSetGroup{
+		{CID: "/rule_set_group/1", RuleSetConditions: []RuleSetGroupCondition{{RuleSetCID: "/rule_set/2"}}},
+	})
+	defer server.Close()
+
+	// the rule set is skipped, so the check bundle it references is kept
+	result, err := apih.BulkDelete(context.Background(), &BulkDeleteConfig{
+		CIDs:   []string{"/check_bundle/1", "/rule_set/2"},
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	expectedSkipped := []BulkDeleteSkip{
+		{CID: "/rule_set/2", Reason: "referenced by /rule_set_group/1"},
+		{CID: "/check_bundle/1", Reason: "referenced by /rule_set/2"},
+	}
+	if len(result.Deleted) != 0 || !reflect.DeepEqual(result.Skipped, expectedSkipped) {
+		t.Fatalf("unexpected result (%+v)", result)
+	}
+}
+
+func TestBulkDeleteErrors(t *testing.T) {
+	apih, server := bulkDeleteTestBootstrap(t, []RuleSetGroup{})
+	defer server.Close()
+
+	search := SearchQueryType("old")
+	tests := []struct {
+		id          string
+		cfg         *BulkDeleteConfig
+		expectedErr string
+	}{
+		{"invalid (nil)", nil, "invalid bulk delete config (nil)"},
+		{"invalid (cid)", &BulkDeleteConfig{CIDs: []string{""}}, "invalid bulk delete CID (none)"},
+		{"invalid (type of cid)", &BulkDeleteConfig{CIDs: []string{"/broker/1"}}, "invalid bulk delete CID (/broker/1)"},
+		{"invalid (type)", &BulkDeleteConfig{Type: "/user"}, "invalid bulk delete type (/user)"},
+		{"invalid (search)", &BulkDeleteConfig{Search: &search}, "invalid bulk delete config, Type required to search"},
+		{"invalid (max)", &BulkDeleteConfig{CIDs: []string{"/graph/1", "/graph/2"}, MaxDeletes: 1}, "refusing to delete 2 objects (MaxDeletes 1)"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			_, err := apih.BulkDelete(context.Background(), test.cfg)
+			if err == nil {
+				t.Fatal("expected error")
+			} else if err.Error() != test.expectedErr {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+		})
+	}
+}
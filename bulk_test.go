@@ -0,0 +1,100 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
+)
+
+func TestBulk(t *testing.T) {
+	fake, err := fakecirconus.New(&fakecirconus.Config{Fixtures: map[string]interface{}{
+		"/annotation/1": Annotation{CID: "/annotation/1", Title: "deploy"},
+		"/annotation/2": Annotation{CID: "/annotation/2", Title: "rollback"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", URL: fake.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	ctx := context.Background()
+
+	t.Log("create, one failing")
+	{
+		cfgs := []*Annotation{{Title: "a"}, nil, {Title: "c"}}
+		created, result := apih.BulkCreateAnnotations(ctx, cfgs, &BulkOptions{Workers: 2})
+		if created[0] == nil || created[1] != nil || created[2] == nil {
+			t.Fatalf("unexpected created (%v)", created)
+		}
+		if result.CIDs[0] != created[0].CID || result.CIDs[1] != "" {
+			t.Fatalf("unexpected cids (%v)", result.CIDs)
+		}
+		if failed := result.Failed(); !reflect.DeepEqual(failed, []int{1}) {
+			t.Fatalf("unexpected failed (%v)", failed)
+		}
+		if err := result.Err(); err == nil || err.Error() != "bulk operation, 1 of 3 failed (item 1: invalid annotation config (nil))" {
+			t.Fatalf("unexpected error (%v)", err)
+		}
+	}
+
+	t.Log("update")
+	{
+		cfgs := []*Annotation{{CID: "/annotation/1", Title: "deploy (updated)"}, {CID: "/annotation/2", Title: "rollback (updated)"}}
+		updated, result := apih.BulkUpdateAnnotations(ctx, cfgs, nil)
+		if err := result.Err(); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if updated[1].Title != "rollback (updated)" {
+			t.Fatalf("unexpected updated (%v)", updated[1])
+		}
+	}
+
+	t.Log("delete, one missing")
+	{
+		result := apih.BulkDeleteAnnotations(ctx, []string{"/annotation/1", "/annotation/9", "/annotation/2"}, nil)
+		if failed := result.Failed(); !reflect.DeepEqual(failed, []int{1}) {
+			t.Fatalf("unexpected failed (%v)", failed)
+		}
+		if err := result.Err(); err == nil || !strings.Contains(err.Error(), "/annotation/9: ") {
+			t.Fatalf("unexpected error (%v)", err)
+		}
+		for _, cid := range []string{"/annotation/1", "/annotation/2"} {
+			if _, ok := fake.Object(cid); ok {
+				t.Fatalf("expected %s deleted", cid)
+			}
+		}
+	}
+
+	t.Log("stop on error")
+	{
+		cfgs := []*Graph{nil, {Title: "b"}, {Title: "c"}}
+		created, result := apih.BulkCreateGraphs(ctx, cfgs, &BulkOptions{Workers: 1, StopOnError: true})
+		if created[1] != nil || created[2] != nil {
+			t.Fatalf("unexpected created (%v)", created)
+		}
+		if failed := result.Failed(); !reflect.DeepEqual(failed, []int{0, 1, 2}) {
+			t.Fatalf("unexpected failed (%v)", failed)
+		}
+		if err := result.Errs[2]; err == nil || !strings.HasPrefix(err.Error(), "not attempted") {
+			t.Fatalf("unexpected error (%v)", err)
+		}
+	}
+
+	t.Log("none")
+	{
+		result := apih.BulkDeleteCheckBundles(ctx, nil, nil)
+		if len(result.Errs) != 0 || result.Err() != nil {
+			t.Fatalf("unexpected result (%+v)", result)
+		}
+	}
+}
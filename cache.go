@@ -0,0 +1,155 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Cache - responses of GET requests stored for reuse
+
+package apiclient
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Cache stores the responses of GET requests (see Config Cache), keyed by
+// request URL (with the account, for API copies acting on another account,
+// see WithAccount). Its methods are called from the goroutines making calls
+// and must be safe for concurrent use. How long responses are kept is up to
+// the Cache; changes made through the client invalidate the responses of
+// the endpoint changed.
+type Cache interface {
+	// Get returns the stored response for the key, false if there is none
+	// (or it is no longer valid)
+	Get(key string) ([]byte, bool)
+
+	// Set stores the response for the key
+	Set(key string, data []byte)
+
+	// Invalidate removes the responses with keys for which match returns
+	// true
+	Invalidate(match func(key string) bool)
+}
+
+// ValidatingCache is a Cache which also stores the validators of responses
+// (their ETag and Last-Modified headers). Responses it no longer serves
+// from Get are revalidated with a conditional request, and are not
+// transferred again when they have not changed. See lrucache for an
+// implementation.
+type ValidatingCache interface {
+	Cache
+
+	// Lookup returns the stored entry for the key, including one Get no
+	// longer serves, false if there is none
+	Lookup(key string) (*CacheEntry, bool)
+
+	// Store stores the entry for the key, as Set does, restarting how long
+	// Get serves it
+	Store(key string, e *CacheEntry)
+}
+
+// CacheEntry is a response stored by a ValidatingCache
+type CacheEntry struct {
+	Data         []byte
+	ETag         string
+	LastModified string
+}
+
+// cacheKey returns the cache key of a request path
+func (a *API) cacheKey(reqPath string) string {
+	key := a.requestURL(reqPath)
+	if a.accountID != "" {
+		key += "#account=" + string(a.accountID)
+	}
+	return key
+}
+
+// cachedGet gets reqPath from the cache, or from the API storing the
+// response in the cache
+func (a *API) cachedGet(ctx context.Context, reqPath string) ([]byte, error) {
+	key := a.cacheKey(reqPath)
+	if data, ok := a.cache.Get(key); ok {
+		if a.debugEnabled() {
+			a.apiLog().Printf("[DEBUG] cached response (%s)\n", key)
+		}
+		return data, nil
+	}
+
+	if vc, ok := a.cache.(ValidatingCache); ok {
+		prev, _ := vc.Lookup(key)
+		e, err := a.validatedGet(ctx, reqPath, prev)
+		if err != nil {
+			return nil, err
+		}
+		if a.debugEnabled() && e == prev {
+			a.apiLog().Printf("[DEBUG] cached response not modified (%s)\n", key)
+		}
+		vc.Store(key, e)
+		return e.Data, nil
+	}
+
+	data, err := a.request(ctx, "GET", reqPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	a.cache.Set(key, data)
+	return data, nil
+}
+
+// validatedGet gets reqPath, conditionally when prev has validators,
+// returning prev if it was not modified
+func (a *API) validatedGet(ctx context.Context, reqPath string, prev *CacheEntry) (*CacheEntry, error) {
+	header := http.Header{}
+	if prev != nil && prev.ETag != "" {
+		header.Set("If-None-Match", prev.ETag)
+	}
+	if prev != nil && prev.LastModified != "" {
+		header.Set("If-Modified-Since", prev.LastModified)
+	}
+
+	var e *CacheEntry
+	err := a.withBackoff(ctx, func(ctx context.Context) error {
+		return a.apiDo(ctx, "GET", reqPath, nil, header, func(resp *http.Response) error {
+			if resp.StatusCode == http.StatusNotModified {
+				if prev == nil {
+					return errors.New("Circonus API response not modified, with no cached response")
+				}
+				e = prev
+				return nil
+			}
+			data, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				return errors.Wrap(err, "reading Circonus API response")
+			}
+			e = &CacheEntry{Data: data, ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+			return nil
+		})
+	})
+	return e, err
+}
+
+// invalidateCache removes the cached responses of the endpoint of reqPath,
+// its objects, lists, and searches, as a change to an object can change any
+// of them
+func (a *API) invalidateCache(reqPath string) {
+	endpoint := a.cacheKey(endpointPrefix(reqPath))
+	endpointURL, account := endpoint, ""
+	if idx := strings.Index(endpoint, "#"); idx >= 0 {
+		endpointURL, account = endpoint[:idx], endpoint[idx:]
+	}
+
+	a.cache.Invalidate(func(key string) bool {
+		keyURL, keyAccount := key, ""
+		if idx := strings.Index(key, "#"); idx >= 0 {
+			keyURL, keyAccount = key[:idx], key[idx:]
+		}
+		if keyAccount != account || !strings.HasPrefix(keyURL, endpointURL) {
+			return false
+		}
+		rest := keyURL[len(endpointURL):]
+		return rest == "" || rest[0] == '/' || rest[0] == '?'
+	})
+}
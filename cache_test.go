@@ -0,0 +1,126 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/circonus-labs/go-apiclient/diskcache"
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
+)
+
+func TestCache(t *testing.T) {
+	fake, err := fakecirconus.New(&fakecirconus.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache, err := diskcache.New(&diskcache.Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	apih, err := New(&Config{TokenKey: "abc123", URL: fake.URL, Cache: cache})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	acct, err := apih.WithAccount("/account/2")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	gets := func() int {
+		n := 0
+		for _, r := range fake.Requests() {
+			if r.Method == "GET" {
+				n++
+			}
+		}
+		return n
+	}
+
+	g, err := apih.CreateGraph(&Graph{Title: "web"})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := apih.FetchGraph(CIDType(&g.CID)); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if _, err := apih.FetchGraphs(); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+	}
+	if n := gets(); n != 2 {
+		t.Fatalf("expected 2 requests, got %d", n)
+	}
+
+	// cached per account
+	if _, err := acct.FetchGraph(CIDType(&g.CID)); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if n := gets(); n != 3 {
+		t.Fatalf("expected 3 requests, got %d", n)
+	}
+
+	// a change invalidates the endpoint's objects and lists
+	g.Title = "web servers"
+	if _, err := apih.UpdateGraph(g); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	fetched, err := apih.FetchGraph(CIDType(&g.CID))
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if fetched.Title != "web servers" {
+		t.Fatalf("stale graph (%s)", fetched.Title)
+	}
+	if _, err := apih.FetchGraphs(); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if _, err := acct.FetchGraph(CIDType(&g.CID)); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if n := gets(); n != 5 {
+		t.Fatalf("expected 5 requests, got %d", n)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	apih, err := New(&Config{TokenKey: "abc123", URL: "https://api.example.com/v2"})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	acct, err := apih.WithAccount("/account/2")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	tests := []struct {
+		id       string
+		api      *API
+		path     string
+		expected string
+	}{
+		{"object", apih, "/graph/1", "https://api.example.com/v2/graph/1"},
+		{"versioned", apih, "/v2/graph/1", "https://api.example.com/v2/graph/1"},
+		{"search", apih, "/graph?search=web", "https://api.example.com/v2/graph?search=web"},
+		{"account", acct, "/graph/1", "https://api.example.com/v2/graph/1#account=2"},
+	}
+
+	for _, test := range tests {
+		if key := test.api.cacheKey(test.path); key != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.id, test.expected, key)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// CAQL API support - execute Circonus Analytics Query Language queries
+// See: https://login.circonus.com/resources/api/calls/caql
+
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// CAQLSeriesMeta describes an output series of a CAQL query
+type CAQLSeriesMeta struct {
+	Kind  string `json:"kind"`  // string (numeric, histogram, text)
+	Label string `json:"label"` // string
+}
+
+// CAQLRow defines the values of all output series at a timestamp. Values
+// are kept in their raw encoding, null if the series has no value at the
+// timestamp.
+type CAQLRow struct {
+	Timestamp time.Time
+	Values    []json.RawMessage
+}
+
+// UnmarshalJSON decodes a CAQL row, encoded as [timestamp, [values]] by the API.
+func (r *CAQLRow) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return errors.Wrap(err, "parsing caql row")
+	}
+	if len(raw) != 2 {
+		return errors.Errorf("invalid caql row, expected [timestamp, [values]] (%s)", string(b))
+	}
+
+	var ts float64
+	if err := json.Unmarshal(raw[0], &ts); err != nil {
+		return errors.Wrap(err, "parsing caql row timestamp")
+	}
+	r.Timestamp = time.Unix(int64(ts), 0).UTC()
+
+	r.Values = nil
+	if err := json.Unmarshal(raw[1], &r.Values); err != nil {
+		return errors.Wrap(err, "parsing caql row values")
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes a CAQL row as [timestamp, [values]].
+func (r CAQLRow) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{r.Timestamp.Unix(), r.Values})
+}
+
+// CAQLResult defines the result of a CAQL query
+type CAQLResult struct {
+	Query  string           `json:"_query"`  // string
+	Start  uint             `json:"_start"`  // uint
+	End    uint             `json:"_end"`    // uint
+	Period uint             `json:"_period"` // uint
+	Meta   []CAQLSeriesMeta `json:"_meta"`   // [] len >= 0
+	Rows   []CAQLRow        `json:"_data"`   // [] len >= 0
+}
+
+// CAQLPoint defines a numeric value of a CAQL output series
+type CAQLPoint struct {
+	Timestamp time.Time
+	Value     *float64 // nil if null or not numeric
+}
+
+// CAQLSeries defines a single output series of a CAQL query
+type CAQLSeries struct {
+	CAQLSeriesMeta
+	Points []CAQLPoint
+}
+
+// NumSeries returns the number of output series in the result.
+func (r *CAQLResult) NumSeries() int {
+	n := len(r.Meta)
+	for _, row := range r.Rows {
+		if len(row.Values) > n {
+			n = len(row.Values)
+		}
+	}
+	return n
+}
+
+// Series returns the output series of the result, with the numeric value of
+// each row. Null and non-numeric values are returned as nil.
+func (r *CAQLResult) Series() []CAQLSeries {
+	series := make([]CAQLSeries, r.NumSeries())
+	for i := range series {
+		if i < len(r.Meta) {
+			series[i].CAQLSeriesMeta = r.Meta[i]
+		}
+		series[i].Points = make([]CAQLPoint, len(r.Rows))
+		for j, row := range r.Rows {
+			series[i].Points[j] = CAQLPoint{Timestamp: row.Timestamp}
+			if i >= len(row.Values) {
+				continue
+			}
+			var v float64
+			if err := json.Unmarshal(row.Values[i], &v); err == nil && !bytes.Equal(bytes.TrimSpace(row.Values[i]), []byte("null")) {
+				series[i].Points[j].Value = &v
+			}
+		}
+	}
+	return series
+}
+
+// CAQL executes the passed query between start and end, with results rolled up
+// into periods of the passed duration.
+func (a *API) CAQL(query string, start, end time.Time, period time.Duration) (*CAQLResult, error) {
+	return a.CAQLWithContext(context.Background(), query, start, end, period)
+}
+
+// CAQLWithContext executes the passed query between start and end, see
+// CAQL, stopping when ctx is done.
+func (a *API) CAQLWithContext(ctx context.Context, query string, start, end time.Time, period time.Duration) (*CAQLResult, error) {
+	if query == "" {
+		return nil, errors.New("invalid caql query (none)")
+	}
+	if !end.After(start) {
+		return nil, errors.Errorf("invalid caql range (%s - %s)", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+	if period < time.Second {
+		return nil, errors.Errorf("invalid caql period (%s)", period)
+	}
+
+	q := url.Values{}
+	q.Set("query", query)
+	q.Set("start", strconv.FormatInt(start.Unix(), 10))
+	q.Set("end", strconv.FormatInt(end.Unix(), 10))
+	q.Set("period", strconv.FormatInt(int64(period/time.Second), 10))
+
+	reqURL := url.URL{
+		Path:     config.CAQLPrefix,
+		RawQuery: q.Encode(),
+	}
+
+	result, err := a.GetWithContext(ctx, reqURL.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "executing caql query")
+	}
+
+	if a.debugEnabled() {
+		a.apiLog().Printf("caql query, received JSON: %s", string(result))
+	}
+
+	caql := &CAQLResult{}
+	if err := a.unmarshalJSON(result, caql); err != nil {
+		return nil, errors.Wrap(err, "parsing caql result")
+	}
+
+	return caql, nil
+}
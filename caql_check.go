@@ -0,0 +1,112 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// CAQL checks - check bundles of CAQL queries, validated before creation
+
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// CAQL check bundle settings
+const (
+	CAQLCheckType   = "caql"
+	CAQLCheckTarget = "q._caql"
+	CAQLBrokerCID   = "/broker/1490" // the broker running CAQL checks
+)
+
+// CAQLQueryError defines an error in a CAQL query reported by the API (e.g.
+// an unknown function, or invalid arguments), see ValidateCAQLQuery
+type CAQLQueryError struct {
+	Query   string
+	Message string    // the explanation of the API, or its response body
+	Err     *APIError // the response of the API
+}
+
+// Error returns the message of the API.
+func (e *CAQLQueryError) Error() string {
+	return fmt.Sprintf("invalid caql query: %s", e.Message)
+}
+
+// Unwrap returns the APIError of the response.
+func (e *CAQLQueryError) Unwrap() error {
+	return e.Err
+}
+
+// NewCAQLCheckBundle returns a new CAQL check bundle of the passed query,
+// with an output[1] metric (the first output series of the query).
+func NewCAQLCheckBundle(name, query string) *CheckBundle {
+	b := NewCheckBundle()
+	b.DisplayName = name
+	b.Type = CAQLCheckType
+	b.Target = CAQLCheckTarget
+	b.Brokers = []string{CAQLBrokerCID}
+	b.Config[config.Query] = query
+	b.Metrics = []CheckBundleMetric{{Name: "output[1]", Type: "numeric", Status: "active", Tags: []string{}}}
+	return b
+}
+
+// ValidateCAQLQuery validates the passed CAQL query: its syntax (see
+// ValidateCAQL), then by the API, executing it over the last few minutes.
+// It returns a *CAQLError for a syntax error, a *CAQLQueryError for a query
+// the API rejects, nil if the query is valid.
+func (a *API) ValidateCAQLQuery(query string) error {
+	return a.ValidateCAQLQueryWithContext(context.Background(), query)
+}
+
+// ValidateCAQLQueryWithContext validates the passed CAQL query, see
+// ValidateCAQLQuery, stopping when ctx is done.
+func (a *API) ValidateCAQLQueryWithContext(ctx context.Context, query string) error {
+	if err := ValidateCAQL(query); err != nil {
+		return err
+	}
+
+	end := time.Now().Truncate(time.Minute)
+	_, err := a.CAQLWithContext(ctx, query, end.Add(-5*time.Minute), end, time.Minute)
+	var apiErr *APIError
+	if err != nil && errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+		msg := apiErr.Explanation
+		if msg == "" {
+			msg = apiErr.Message
+		}
+		if msg == "" {
+			msg = apiErr.Body
+		}
+		return &CAQLQueryError{Query: query, Message: msg, Err: apiErr}
+	}
+	return errors.Wrap(err, "validating caql query")
+}
+
+// CreateCAQLCheckBundle creates the passed CAQL check bundle (see
+// NewCAQLCheckBundle), once its query is validated (see ValidateCAQLQuery).
+func (a *API) CreateCAQLCheckBundle(cfg *CheckBundle) (*CheckBundle, error) {
+	return a.CreateCAQLCheckBundleWithContext(context.Background(), cfg)
+}
+
+// CreateCAQLCheckBundleWithContext creates the passed CAQL check bundle,
+// see CreateCAQLCheckBundle, stopping when ctx is done.
+func (a *API) CreateCAQLCheckBundleWithContext(ctx context.Context, cfg *CheckBundle) (*CheckBundle, error) {
+	if cfg == nil {
+		return nil, errors.New("invalid check bundle config (nil)")
+	}
+	if cfg.Type != CAQLCheckType {
+		return nil, errors.Errorf("invalid check bundle type (%s), must be %s", cfg.Type, CAQLCheckType)
+	}
+	query := cfg.Config[config.Query]
+	if query == "" {
+		return nil, errors.New("invalid caql query (none)")
+	}
+
+	if err := a.ValidateCAQLQueryWithContext(ctx, query); err != nil {
+		return nil, err
+	}
+	return a.CreateCheckBundleWithContext(ctx, cfg)
+}
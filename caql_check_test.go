@@ -0,0 +1,101 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/circonus-labs/go-apiclient/config"
+)
+
+func TestCAQLCheckBundle(t *testing.T) {
+	var created int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == config.CAQLPrefix:
+			if strings.Contains(r.URL.Query().Get("query"), "bogus") {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"code":"Bad Request","message":"Invalid query","explanation":"unknown function bogus"}`)
+				return
+			}
+			fmt.Fprint(w, `{"_meta":[],"_data":[]}`)
+		case r.URL.Path == config.CheckBundlePrefix && r.Method == "POST":
+			atomic.AddInt32(&created, 1)
+			var b CheckBundle
+			body, _ := ioutil.ReadAll(r.Body)
+			if err := json.Unmarshal(body, &b); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			b.CID = "/check_bundle/1"
+			json.NewEncoder(w).Encode(b) // nolint: errcheck
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	t.Run("validate", func(t *testing.T) {
+		if err := apih.ValidateCAQLQuery(`metric:average("uuid", "cpu")`); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+
+		var syntaxErr *CAQLError
+		if err := apih.ValidateCAQLQuery(`metric:average("uuid"`); !errors.As(err, &syntaxErr) {
+			t.Fatalf("expected syntax error (%v)", err)
+		}
+
+		err := apih.ValidateCAQLQuery(`bogus(1)`)
+		var queryErr *CAQLQueryError
+		if !errors.As(err, &queryErr) || err.Error() != "invalid caql query: unknown function bogus" {
+			t.Fatalf("unexpected error (%v)", err)
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected APIError (%v)", err)
+		}
+	})
+
+	t.Run("create", func(t *testing.T) {
+		b, err := apih.CreateCAQLCheckBundle(NewCAQLCheckBundle("cpu", `metric:average("uuid", "cpu")`))
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if b.CID != "/check_bundle/1" || b.Type != CAQLCheckType || b.Target != CAQLCheckTarget || b.Config[config.Query] == "" {
+			t.Fatalf("unexpected check bundle (%+v)", b)
+		}
+
+		invalid := []struct {
+			cfg         *CheckBundle
+			expectedErr string
+		}{
+			{nil, "invalid check bundle config (nil)"},
+			{NewCheckBundle(), "invalid check bundle type (), must be caql"},
+			{NewCAQLCheckBundle("none", ""), "invalid caql query (none)"},
+			{NewCAQLCheckBundle("bogus", "bogus(1)"), "invalid caql query: unknown function bogus"},
+		}
+		for _, test := range invalid {
+			if _, err := apih.CreateCAQLCheckBundle(test.cfg); err == nil || err.Error() != test.expectedErr {
+				t.Errorf("unexpected error (%v)", err)
+			}
+		}
+		if n := atomic.LoadInt32(&created); n != 1 {
+			t.Fatalf("expected invalid check bundles not created (%d)", n)
+		}
+	})
+}
@@ -0,0 +1,130 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var testCAQLJSON = json.RawMessage(`{
+	"_query": "search:metric:average(\"foo\")",
+	"_start": 1483033000,
+	"_end": 1483033180,
+	"_period": 60,
+	"_meta": [{"kind": "numeric", "label": "foo"}, {"kind": "numeric", "label": "bar"}],
+	"_data": [
+		[1483033000, [1.5, null]],
+		[1483033060, [2.5, 3]],
+		[1483033120, [null, "text"]]
+	]
+}`)
+
+func testCAQLServer() *httptest.Server {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		reqURL := r.URL.String()
+		switch reqURL {
+		case "/caql?end=1483033180&period=60&query=search%3Ametric%3Aaverage%28%22foo%22%29&start=1483033000":
+			switch r.Method {
+			case "GET":
+				w.WriteHeader(200)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintln(w, string(testCAQLJSON))
+			default:
+				w.WriteHeader(404)
+				fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, reqURL))
+			}
+		default:
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, reqURL))
+		}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(f))
+}
+
+func caqlTestBootstrap(t *testing.T) (*API, *httptest.Server) {
+	server := testCAQLServer()
+
+	ac := &Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      server.URL,
+	}
+	apih, err := NewAPI(ac)
+	if err != nil {
+		server.Close()
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	return apih, server
+}
+
+func TestCAQL(t *testing.T) {
+	query := `search:metric:average("foo")`
+	apih, server := caqlTestBootstrap(t)
+	defer server.Close()
+
+	start := time.Unix(1483033000, 0)
+	end := start.Add(3 * time.Minute)
+
+	tests := []struct {
+		id          string
+		query       string
+		start       time.Time
+		end         time.Time
+		period      time.Duration
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (query)", "", start, end, time.Minute, true, "invalid caql query (none)"},
+		{"invalid (range)", query, start, start, time.Minute, true, "invalid caql range (" + start.Format(time.RFC3339) + " - " + start.Format(time.RFC3339) + ")"},
+		{"invalid (period)", query, start, end, time.Millisecond, true, "invalid caql period (1ms)"},
+		{"valid", query, start, end, time.Minute, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			result, err := apih.CAQL(test.query, test.start, test.end, test.period)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if result.Period != 60 || len(result.Rows) != 3 {
+				t.Fatalf("unexpected result (%#v)", result)
+			}
+
+			series := result.Series()
+			if len(series) != 2 {
+				t.Fatalf("expected 2 series, got %d", len(series))
+			}
+			if series[1].Label != "bar" {
+				t.Fatalf("unexpected label (%s)", series[1].Label)
+			}
+			foo, bar := series[0].Points, series[1].Points
+			if foo[0].Value == nil || *foo[0].Value != 1.5 || foo[2].Value != nil {
+				t.Fatalf("unexpected foo points (%#v)", foo)
+			}
+			if bar[0].Value != nil || bar[1].Value == nil || *bar[1].Value != 3 || bar[2].Value != nil {
+				t.Fatalf("unexpected bar points (%#v)", bar)
+			}
+			if !foo[1].Timestamp.Equal(start.Add(time.Minute)) {
+				t.Fatalf("unexpected timestamp (%s)", foo[1].Timestamp)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// CAQL syntax validation - local check of CAQL query syntax, without
+// executing the query
+
+package apiclient
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CAQLError defines a syntax error in a CAQL query. Line and Column are 1
+// based, Column counts characters (not bytes).
+type CAQLError struct {
+	Offset  int    // byte offset in the query
+	Line    int    // line number
+	Column  int    // column number
+	Message string // description of the error
+}
+
+// Error returns the error message with its position.
+func (e *CAQLError) Error() string {
+	return fmt.Sprintf("caql syntax error at line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// ValidateCAQL checks the syntax of the passed CAQL query, returning a
+// *CAQLError for the first error found, nil if the query is well formed.
+// Only syntax is checked (balanced parentheses, terminated strings, complete
+// pipelines and expressions), function names and arguments are not; the
+// query can still fail when executed.
+//
+// The accepted syntax: leading #directive[=value] lines, then a pipeline of
+// expressions separated by |. Expressions are numbers, durations (5m),
+// strings, names (metric:average, op:sum) optionally called with a list of
+// pipelines as arguments, parenthesized pipelines, and the unary - and binary
+// + - * / % < <= > >= == != operators.
+func ValidateCAQL(query string) error {
+	p := &caqlParser{query: query}
+	if err := p.tokenize(); err != nil {
+		return err
+	}
+	if p.peek().kind == caqlTokenEOF {
+		return p.errorAt(len(query), "empty query")
+	}
+	if err := p.pipeline(); err != nil {
+		return err
+	}
+	if t := p.peek(); t.kind != caqlTokenEOF {
+		return p.errorAt(t.offset, fmt.Sprintf("unexpected %s", t))
+	}
+	return nil
+}
+
+type caqlTokenKind int
+
+const (
+	caqlTokenEOF caqlTokenKind = iota
+	caqlTokenNumber
+	caqlTokenString
+	caqlTokenName
+	caqlTokenOperator
+	caqlTokenPipe
+	caqlTokenComma
+	caqlTokenOpen
+	caqlTokenClose
+)
+
+type caqlToken struct {
+	kind   caqlTokenKind
+	text   string
+	offset int
+}
+
+func (t caqlToken) String() string {
+	if t.kind == caqlTokenEOF {
+		return "end of query"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// caqlOperators are the binary operators, longest first
+var caqlOperators = []string{"==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "%"}
+
+type caqlParser struct {
+	query  string
+	tokens []caqlToken
+	pos    int
+}
+
+// tokenize splits the query into tokens, skipping directive lines
+func (p *caqlParser) tokenize() error {
+	q := p.query
+	directives := true
+	for i := 0; i < len(q); {
+		r, size := utf8.DecodeRuneInString(q[i:])
+
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+			continue
+		case r == '#':
+			if !directives {
+				return p.errorAt(i, "directive after query")
+			}
+			end := strings.IndexByte(q[i:], '\n')
+			if end < 0 {
+				end = len(q) - i
+			}
+			line := strings.TrimSpace(q[i+1 : i+end])
+			name := line
+			if eq := strings.IndexByte(line, '='); eq >= 0 {
+				name = line[:eq]
+			}
+			if !isCAQLName(name) {
+				return p.errorAt(i, fmt.Sprintf("invalid directive (%s)", line))
+			}
+			i += end
+			continue
+		}
+		directives = false
+
+		start := i
+		switch {
+		case r == '"' || r == '\'':
+			i++
+			for {
+				if i >= len(q) || q[i] == '\n' {
+					return p.errorAt(start, "unterminated string")
+				}
+				if q[i] == '\\' {
+					i += 2
+					continue
+				}
+				if rune(q[i]) == r {
+					i++
+					break
+				}
+				i++
+			}
+			p.tokens = append(p.tokens, caqlToken{kind: caqlTokenString, text: q[start:i], offset: start})
+		case r >= '0' && r <= '9' || r == '.' && i+1 < len(q) && q[i+1] >= '0' && q[i+1] <= '9':
+			for i < len(q) && (isCAQLNameByte(q[i]) || q[i] == '.' || (q[i] == '+' || q[i] == '-') && (q[i-1] == 'e' || q[i-1] == 'E')) {
+				i++
+			}
+			p.tokens = append(p.tokens, caqlToken{kind: caqlTokenNumber, text: q[start:i], offset: start})
+		case isCAQLNameStart(r):
+			for i < len(q) {
+				c, n := utf8.DecodeRuneInString(q[i:])
+				if !isCAQLNameStart(c) && !(c >= '0' && c <= '9') && c != ':' && c != '.' {
+					break
+				}
+				i += n
+			}
+			p.tokens = append(p.tokens, caqlToken{kind: caqlTokenName, text: q[start:i], offset: start})
+		case r == '|':
+			i++
+			p.tokens = append(p.tokens, caqlToken{kind: caqlTokenPipe, text: "|", offset: start})
+		case r == ',':
+			i++
+			p.tokens = append(p.tokens, caqlToken{kind: caqlTokenComma, text: ",", offset: start})
+		case r == '(':
+			i++
+			p.tokens = append(p.tokens, caqlToken{kind: caqlTokenOpen, text: "(", offset: start})
+		case r == ')':
+			i++
+			p.tokens = append(p.tokens, caqlToken{kind: caqlTokenClose, text: ")", offset: start})
+		default:
+			op := ""
+			for _, o := range caqlOperators {
+				if strings.HasPrefix(q[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return p.errorAt(i, fmt.Sprintf("unexpected character %q", r))
+			}
+			i += len(op)
+			p.tokens = append(p.tokens, caqlToken{kind: caqlTokenOperator, text: op, offset: start})
+		}
+	}
+	p.tokens = append(p.tokens, caqlToken{kind: caqlTokenEOF, offset: len(q)})
+	return nil
+}
+
+func (p *caqlParser) peek() caqlToken {
+	return p.tokens[p.pos]
+}
+
+func (p *caqlParser) next() caqlToken {
+	t := p.tokens[p.pos]
+	if t.kind != caqlTokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// pipeline parses expr { "|" expr }
+func (p *caqlParser) pipeline() error {
+	if err := p.binary(); err != nil {
+		return err
+	}
+	for p.peek().kind == caqlTokenPipe {
+		p.next()
+		if err := p.binary(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// binary parses unary { operator unary }
+func (p *caqlParser) binary() error {
+	if err := p.unary(); err != nil {
+		return err
+	}
+	for p.peek().kind == caqlTokenOperator {
+		p.next()
+		if err := p.unary(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unary parses [ "-" ] primary
+func (p *caqlParser) unary() error {
+	if t := p.peek(); t.kind == caqlTokenOperator && t.text == "-" {
+		p.next()
+	}
+	return p.primary()
+}
+
+// primary parses a number, string, name [ "(" [ pipeline { "," pipeline } ] ")" ]
+// or "(" pipeline ")"
+func (p *caqlParser) primary() error {
+	t := p.next()
+	switch t.kind {
+	case caqlTokenNumber, caqlTokenString:
+		return nil
+	case caqlTokenName:
+		if p.peek().kind != caqlTokenOpen {
+			return nil
+		}
+		open := p.next()
+		if p.peek().kind == caqlTokenClose {
+			p.next()
+			return nil
+		}
+		for {
+			if err := p.pipeline(); err != nil {
+				return err
+			}
+			switch c := p.next(); c.kind {
+			case caqlTokenComma:
+				continue
+			case caqlTokenClose:
+				return nil
+			case caqlTokenEOF:
+				return p.errorAt(open.offset, fmt.Sprintf("unclosed \"(\" in call of %s", t.text))
+			default:
+				return p.errorAt(c.offset, fmt.Sprintf("unexpected %s in arguments of %s", c, t.text))
+			}
+		}
+	case caqlTokenOpen:
+		if err := p.pipeline(); err != nil {
+			return err
+		}
+		switch c := p.next(); c.kind {
+		case caqlTokenClose:
+			return nil
+		case caqlTokenEOF:
+			return p.errorAt(t.offset, "unclosed \"(\"")
+		default:
+			return p.errorAt(c.offset, fmt.Sprintf("unexpected %s, expected \")\"", c))
+		}
+	default:
+		return p.errorAt(t.offset, fmt.Sprintf("unexpected %s, expected expression", t))
+	}
+}
+
+// errorAt returns a CAQLError at the byte offset in the query
+func (p *caqlParser) errorAt(offset int, msg string) *CAQLError {
+	before := p.query[:offset]
+	line := strings.Count(before, "\n") + 1
+	col := utf8.RuneCountInString(before[strings.LastIndexByte(before, '\n')+1:]) + 1
+	return &CAQLError{Offset: offset, Line: line, Column: col, Message: msg}
+}
+
+func isCAQLNameStart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+func isCAQLNameByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isCAQLName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isCAQLNameByte(s[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"testing"
+)
+
+func TestValidateCAQL(t *testing.T) {
+	tests := []struct {
+		id          string
+		query       string
+		shouldFail  bool
+		expectedErr string
+		offset      int
+	}{
+		{"valid (metric)", `metric:average("8f2b6f3a-1c2d-4e5f-8a9b-0c1d2e3f4a5b", "duration")`, false, "", 0},
+		{"valid (pipeline)", `search:metric("duration") | histogram:percentile(99, 99.9) | op:sum() | window:mean(5m)`, false, "", 0},
+		{"valid (directives)", "#min_period=60\n#strict\nfind('duration', 'and(service:web)') | label(\"%cn\")", false, "", 0},
+		{"valid (expressions)", `(A + B) * -2 / 1e-3 >= .5 | vector(A, (B | op:sum()))`, false, "", 0},
+		{"valid (escaped string)", `find("du\"ra'tion")`, false, "", 0},
+		{"invalid (empty)", "  \n", true, "caql syntax error at line 2, column 1: empty query", 3},
+		{"invalid (directive)", "#min period\nfind('x')", true, "caql syntax error at line 1, column 1: invalid directive (min period)", 0},
+		{"invalid (directive after query)", "find('x')\n#min_period=60", true, "caql syntax error at line 2, column 1: directive after query", 10},
+		{"invalid (unterminated string)", `find("duration)`, true, "caql syntax error at line 1, column 6: unterminated string", 5},
+		{"invalid (unclosed call)", `metric:average("x", "y"`, true, `caql syntax error at line 1, column 15: unclosed "(" in call of metric:average`, 14},
+		{"invalid (unclosed paren)", `(A + B`, true, `caql syntax error at line 1, column 1: unclosed "("`, 0},
+		{"invalid (extra close)", `find("x"))`, true, `caql syntax error at line 1, column 10: unexpected ")"`, 9},
+		{"invalid (trailing pipe)", "find(\"x\") |\n", true, "caql syntax error at line 2, column 1: unexpected end of query, expected expression", 12},
+		{"invalid (missing argument)", `vector(A, )`, true, `caql syntax error at line 1, column 11: unexpected ")", expected expression`, 10},
+		{"invalid (missing comma)", `vector(A B)`, true, `caql syntax error at line 1, column 10: unexpected "B" in arguments of vector`, 9},
+		{"invalid (character)", "find('é') ; op:sum()", true, `caql syntax error at line 1, column 11: unexpected character ';'`, 11},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			err := ValidateCAQL(test.query)
+			if !test.shouldFail {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if err.Error() != test.expectedErr {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if cerr, ok := err.(*CAQLError); !ok || cerr.Offset != test.offset {
+				t.Fatalf("unexpected error offset (%#v)", err)
+			}
+		})
+	}
+}
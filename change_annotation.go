@@ -0,0 +1,221 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Change annotations - record the changes made by the client as annotations
+
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/circonus-labs/go-apiclient/config"
+)
+
+const (
+	// DefaultChangeAnnotationCategory is the category of change annotations
+	DefaultChangeAnnotationCategory = "change"
+	// DefaultChangeAnnotationFields is the number of fields changed listed
+	// in the description of a change annotation
+	DefaultChangeAnnotationFields = 10
+
+	// changeAnnotationValueLen truncates the values listed in descriptions
+	changeAnnotationValueLen = 60
+)
+
+// DefaultSecretPattern matches the keys of fields treated as secrets (e.g.
+// the passwords and tokens of check bundle configs)
+var DefaultSecretPattern = regexp.MustCompile(`(?i)(pass|secret|token|auth|api_?key|private)`)
+
+// ChangeAnnotationConfig defines the changes recorded as annotations (see
+// Config ChangeAnnotations)
+type ChangeAnnotationConfig struct {
+	// Category of the annotations - default DefaultChangeAnnotationCategory
+	Category string
+
+	// Types (cid prefixes, e.g. config.CheckBundlePrefix) of objects whose
+	// changes are recorded - default all, other than annotations
+	Types []string
+
+	// MaxFields limits the fields changed listed in the description -
+	// default DefaultChangeAnnotationFields
+	MaxFields int
+
+	// SecretPattern matches the keys of fields whose values are not listed
+	// in the description - default DefaultSecretPattern
+	SecretPattern *regexp.Regexp
+}
+
+// newChangeAnnotationConfig returns the config with defaults set, nil if
+// changes are not recorded
+func newChangeAnnotationConfig(cfg *ChangeAnnotationConfig) *ChangeAnnotationConfig {
+	if cfg == nil {
+		return nil
+	}
+	c := *cfg
+	if c.Category == "" {
+		c.Category = DefaultChangeAnnotationCategory
+	}
+	if c.MaxFields <= 0 {
+		c.MaxFields = DefaultChangeAnnotationFields
+	}
+	c.Types = append([]string{}, cfg.Types...)
+	return &c
+}
+
+// annotated returns true if changes to the object with the passed cid are
+// recorded
+func (c *ChangeAnnotationConfig) annotated(cid string) bool {
+	prefix := cidPrefix(cid)
+	if prefix == config.AnnotationPrefix {
+		return false
+	}
+	if len(c.Types) == 0 {
+		return true
+	}
+	for _, t := range c.Types {
+		if t == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangeAnnotation returns the annotation recording a change: titled with
+// the change (e.g. "updated /check_bundle/1234"), and describing who made
+// it and the fields changed, the values of secrets redacted.
+func (c *ChangeAnnotationConfig) ChangeAnnotation(rec AuditRecord) *Annotation {
+	secrets := c.SecretPattern
+	if secrets == nil {
+		secrets = DefaultSecretPattern
+	}
+	verb := map[string]string{"POST": "created", "PUT": "updated", "DELETE": "deleted"}[rec.Method]
+	if verb == "" {
+		verb = strings.ToLower(rec.Method)
+	}
+
+	desc := fmt.Sprintf("%s %s by %s", verb, rec.CID, rec.App)
+	if rec.AccountID != "" {
+		desc += fmt.Sprintf(" (account %s)", rec.AccountID)
+	}
+	var fields []string
+	if rec.Method != "DELETE" {
+		for _, ch := range rec.Changes {
+			if len(fields) == c.MaxFields {
+				fields = append(fields, fmt.Sprintf("and %d more", len(rec.Changes)-c.MaxFields))
+				break
+			}
+			old, new := ch.Old, ch.New
+			if secretField(ch.Field, secrets) {
+				old, new = redactedValue(old), redactedValue(new)
+			} else {
+				old, new = redactSecrets(old, secrets), redactSecrets(new, secrets)
+			}
+			fields = append(fields, fmt.Sprintf("%s: %s => %s", ch.Field, changeAnnotationValue(old), changeAnnotationValue(new)))
+		}
+	}
+	if len(fields) > 0 {
+		desc += "\n" + strings.Join(fields, "\n")
+	}
+
+	start := uint(rec.Time.Unix())
+	return &Annotation{
+		Category:       c.Category,
+		Title:          fmt.Sprintf("%s %s", verb, rec.CID),
+		Description:    desc,
+		RelatedMetrics: []string{},
+		Start:          start,
+		Stop:           start,
+	}
+}
+
+// changeAnnotationValue returns a value changed as listed in descriptions
+func changeAnnotationValue(v interface{}) string {
+	if v == nil {
+		return "(none)"
+	}
+	s, ok := v.(string)
+	if !ok {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		s = string(data)
+	}
+	if len(s) > changeAnnotationValueLen {
+		n := changeAnnotationValueLen - 3
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		s = s[:n] + "..."
+	}
+	return s
+}
+
+// secretField returns true if any key of the (dotted) field name matches
+// the secret pattern
+func secretField(field string, secrets *regexp.Regexp) bool {
+	for _, k := range strings.Split(field, ".") {
+		if secrets.MatchString(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactedValue returns the value listed for a secret, nil if none was set
+func redactedValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return redacted
+}
+
+// redactSecrets returns a copy of v with the values of the object keys
+// matching the secret pattern redacted
+func redactSecrets(v interface{}, secrets *regexp.Regexp) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			if secrets.MatchString(k) {
+				out[k] = redactedValue(e)
+			} else {
+				out[k] = redactSecrets(e, secrets)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = redactSecrets(e, secrets)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// annotateChange creates the annotation recording a change, failures are
+// logged, the change having been made
+func (a *API) annotateChange(ctx context.Context, rec AuditRecord) {
+	if !a.changeAnnotations.annotated(rec.CID) {
+		return
+	}
+	data, err := a.marshalJSON(a.changeAnnotations.ChangeAnnotation(rec))
+	if err != nil {
+		a.logger.Warn("encoding change annotation", "cid", rec.CID, "error", err)
+		return
+	}
+	if _, err := a.request(ctx, "POST", config.AnnotationPrefix, data.Bytes()); err != nil {
+		a.logger.Warn("creating change annotation", "cid", rec.CID, "error", err)
+	}
+	if a.cache != nil {
+		a.invalidateCache(config.AnnotationPrefix)
+	}
+}
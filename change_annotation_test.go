@@ -0,0 +1,125 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
+)
+
+func TestChangeAnnotations(t *testing.T) {
+	fake, err := fakecirconus.New(&fakecirconus.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+
+	apih, err := New(&Config{
+		TokenKey:          "abc123",
+		TokenApp:          "deployer",
+		URL:               fake.URL,
+		ChangeAnnotations: &ChangeAnnotationConfig{Types: []string{config.GraphPrefix}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	g, err := apih.CreateGraph(&Graph{Title: "web"})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	g.Title = "web servers"
+	if _, err := apih.UpdateGraph(g); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if _, err := apih.DeleteGraph(g); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	// not a type annotated
+	if _, err := apih.CreateWorksheet(&Worksheet{Title: "ops"}); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	annotations, err := apih.FetchAnnotations()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(*annotations) != 3 {
+		t.Fatalf("unexpected annotations (%+v)", *annotations)
+	}
+	expected := []string{"created " + g.CID, "updated " + g.CID, "deleted " + g.CID}
+	for i, a := range *annotations {
+		if a.Title != expected[i] || a.Category != DefaultChangeAnnotationCategory {
+			t.Fatalf("unexpected annotation (%+v), expected %s", a, expected[i])
+		}
+	}
+	if desc := (*annotations)[1].Description; !strings.Contains(desc, "by deployer") || !strings.Contains(desc, "title: web => web servers") {
+		t.Fatalf("unexpected description (%s)", desc)
+	}
+}
+
+func TestChangeAnnotation(t *testing.T) {
+	cfg := newChangeAnnotationConfig(&ChangeAnnotationConfig{Category: "deploy", MaxFields: 2})
+	rec := AuditRecord{
+		Time:      time.Unix(1000, 0),
+		App:       "test",
+		AccountID: "2",
+		Method:    "PUT",
+		CID:       "/check_bundle/1234",
+		Changes: []AuditChange{
+			{Field: "config.url", Old: "http://a", New: strings.Repeat("x", 80)},
+			{Field: "period", Old: float64(60), New: float64(30)},
+			{Field: "tags", Old: nil, New: []interface{}{"env:prod"}},
+		},
+	}
+
+	a := cfg.ChangeAnnotation(rec)
+	expected := "updated /check_bundle/1234 by test (account 2)\n" +
+		"config.url: http://a => " + strings.Repeat("x", 57) + "...\n" +
+		"period: 60 => 30\n" +
+		"and 1 more"
+	if a.Title != "updated /check_bundle/1234" || a.Category != "deploy" || a.Description != expected || a.Start != 1000 || a.Stop != 1000 {
+		t.Fatalf("unexpected annotation (%+v)", a)
+	}
+
+	if cfg.annotated("/annotation/1") || !cfg.annotated("/rule_set/1_cpu") {
+		t.Fatal("unexpected annotated types")
+	}
+}
+
+func TestChangeAnnotationSecrets(t *testing.T) {
+	cfg := newChangeAnnotationConfig(&ChangeAnnotationConfig{})
+	rec := AuditRecord{
+		App:    "test",
+		Method: "PUT",
+		CID:    "/check_bundle/1234",
+		Changes: []AuditChange{
+			{Field: "config.auth_password", Old: "hunter2", New: "hunter3"},
+			{Field: "config.api_key", Old: nil, New: "abc123"},
+			{Field: "config.url", Old: "http://a", New: "http://b"},
+			{Field: "notes", Old: nil, New: map[string]interface{}{"header_X-Token": "abc123", "port": "80"}},
+			{Field: "display_name", Old: "web", New: strings.Repeat("é", 40)},
+		},
+	}
+
+	a := cfg.ChangeAnnotation(rec)
+	expected := "updated /check_bundle/1234 by test\n" +
+		"config.auth_password: [redacted] => [redacted]\n" +
+		"config.api_key: (none) => [redacted]\n" +
+		"config.url: http://a => http://b\n" +
+		`notes: (none) => {"header_X-Token":"[redacted]","port":"80"}` + "\n" +
+		"display_name: web => " + strings.Repeat("é", 28) + "..."
+	if a.Description != expected {
+		t.Fatalf("unexpected description (%q)", a.Description)
+	}
+	if !utf8.ValidString(a.Description) {
+		t.Fatal("expected valid UTF-8")
+	}
+}
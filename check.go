@@ -9,10 +9,8 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
-	"regexp"
 	"strings"
 
 	"github.com/circonus-labs/go-apiclient/config"
@@ -35,6 +33,12 @@ type Check struct {
 
 // FetchCheck retrieves check with passed cid.
 func (a *API) FetchCheck(cid CIDType) (*Check, error) {
+	return a.FetchCheckWithContext(context.Background(), cid)
+}
+
+// FetchCheckWithContext retrieves check with passed cid, stopping when ctx is
+// done.
+func (a *API) FetchCheckWithContext(ctx context.Context, cid CIDType) (*Check, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.New("invalid check CID (none)")
 	}
@@ -46,25 +50,21 @@ func (a *API) FetchCheck(cid CIDType) (*Check, error) {
 		checkCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.CheckCIDRegex, checkCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.CheckPrefix, checkCID) {
 		return nil, errors.Errorf("invalid check CID (%s)", checkCID)
 	}
 
-	result, err := a.Get(checkCID)
+	result, err := a.GetWithContext(ctx, checkCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching check")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch check, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch check, received JSON: %s", string(result))
 	}
 
 	check := new(Check)
-	if err := json.Unmarshal(result, check); err != nil {
+	if err := a.unmarshalJSON(result, check); err != nil {
 		return nil, errors.Wrap(err, "parsing check")
 	}
 
@@ -73,14 +73,15 @@ func (a *API) FetchCheck(cid CIDType) (*Check, error) {
 
 // FetchChecks retrieves all checks available to the API Token.
 func (a *API) FetchChecks() (*[]Check, error) {
-	result, err := a.Get(config.CheckPrefix)
-	if err != nil {
-		return nil, errors.Wrap(err, "fetching checks")
-	}
+	return a.FetchChecksWithContext(context.Background())
+}
 
+// FetchChecksWithContext retrieves all checks available to the API Token,
+// stopping when ctx is done.
+func (a *API) FetchChecksWithContext(ctx context.Context) (*[]Check, error) {
 	var checks []Check
-	if err := json.Unmarshal(result, &checks); err != nil {
-		return nil, errors.Wrap(err, "parsing checks")
+	if err := a.getJSONContext(ctx, config.CheckPrefix, &checks); err != nil {
+		return nil, errors.Wrap(err, "fetching checks")
 	}
 
 	return &checks, nil
@@ -90,37 +91,19 @@ func (a *API) FetchChecks() (*[]Check, error) {
 // and/or filter. If nil is passed for both parameters all checks
 // will be returned.
 func (a *API) SearchChecks(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Check, error) {
-	q := url.Values{}
-
-	if searchCriteria != nil && *searchCriteria != "" {
-		q.Set("search", string(*searchCriteria))
-	}
-
-	if filterCriteria != nil && len(*filterCriteria) > 0 {
-		for filter, criteria := range *filterCriteria {
-			for _, val := range criteria {
-				q.Add(filter, val)
-			}
-		}
-	}
-
-	if q.Encode() == "" {
-		return a.FetchChecks()
-	}
-
-	reqURL := url.URL{
-		Path:     config.CheckPrefix,
-		RawQuery: q.Encode(),
-	}
+	return a.SearchChecksWithContext(context.Background(), searchCriteria, filterCriteria)
+}
 
-	result, err := a.Get(reqURL.String())
-	if err != nil {
-		return nil, errors.Wrap(err, "searching checks")
+// SearchChecksWithContext returns checks matching the specified search query
+// and/or filter, stopping when ctx is done.
+func (a *API) SearchChecksWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Check, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchChecksWithContext(ctx)
 	}
 
 	var checks []Check
-	if err := json.Unmarshal(result, &checks); err != nil {
-		return nil, errors.Wrap(err, "parsing checks")
+	if err := a.searchJSONContext(ctx, config.CheckPrefix, searchCriteria, filterCriteria, &checks); err != nil {
+		return nil, errors.Wrap(err, "searching checks")
 	}
 
 	return &checks, nil
@@ -2,16 +2,17 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// Check bundle API support - Fetch, Create, Update, Delete, and Search
+// Check bundle API support - Fetch, Create, Update, Upsert, Delete, and Search
 // See: https://login.circonus.com/resources/api/calls/check_bundle
 
 package apiclient
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/url"
-	"regexp"
+	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/circonus-labs/go-apiclient/config"
@@ -71,6 +72,12 @@ func NewCheckBundle() *CheckBundle {
 
 // FetchCheckBundle retrieves check bundle with passed cid.
 func (a *API) FetchCheckBundle(cid CIDType) (*CheckBundle, error) {
+	return a.FetchCheckBundleWithContext(context.Background(), cid)
+}
+
+// FetchCheckBundleWithContext retrieves check bundle with passed cid,
+// stopping when ctx is done.
+func (a *API) FetchCheckBundleWithContext(ctx context.Context, cid CIDType) (*CheckBundle, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.New("invalid check bundle CID (none)")
 	}
@@ -82,25 +89,21 @@ func (a *API) FetchCheckBundle(cid CIDType) (*CheckBundle, error) {
 		bundleCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.CheckBundleCIDRegex, bundleCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.CheckBundlePrefix, bundleCID) {
 		return nil, errors.Errorf("invalid check bundle CID (%v)", bundleCID)
 	}
 
-	result, err := a.Get(bundleCID)
+	result, err := a.GetWithContext(ctx, bundleCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching check bundle")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch check bundle, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch check bundle, received JSON: %s", string(result))
 	}
 
 	checkBundle := &CheckBundle{}
-	if err := json.Unmarshal(result, checkBundle); err != nil {
+	if err := a.unmarshalJSON(result, checkBundle); err != nil {
 		return nil, errors.Wrap(err, "parsing check bundle")
 	}
 
@@ -109,14 +112,15 @@ func (a *API) FetchCheckBundle(cid CIDType) (*CheckBundle, error) {
 
 // FetchCheckBundles retrieves all check bundles available to the API Token.
 func (a *API) FetchCheckBundles() (*[]CheckBundle, error) {
-	result, err := a.Get(config.CheckBundlePrefix)
-	if err != nil {
-		return nil, errors.Wrap(err, "fetching check bundles")
-	}
+	return a.FetchCheckBundlesWithContext(context.Background())
+}
 
+// FetchCheckBundlesWithContext retrieves all check bundles available to the
+// API Token, stopping when ctx is done.
+func (a *API) FetchCheckBundlesWithContext(ctx context.Context) (*[]CheckBundle, error) {
 	var checkBundles []CheckBundle
-	if err := json.Unmarshal(result, &checkBundles); err != nil {
-		return nil, errors.Wrap(err, "parsing check bundles")
+	if err := a.getJSONContext(ctx, config.CheckBundlePrefix, &checkBundles); err != nil {
+		return nil, errors.Wrap(err, "fetching check bundles")
 	}
 
 	return &checkBundles, nil
@@ -124,36 +128,38 @@ func (a *API) FetchCheckBundles() (*[]CheckBundle, error) {
 
 // UpdateCheckBundle updates passed check bundle.
 func (a *API) UpdateCheckBundle(cfg *CheckBundle) (*CheckBundle, error) {
+	return a.UpdateCheckBundleWithContext(context.Background(), cfg)
+}
+
+// UpdateCheckBundleWithContext updates passed check bundle, stopping when ctx
+// is done.
+func (a *API) UpdateCheckBundleWithContext(ctx context.Context, cfg *CheckBundle) (*CheckBundle, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid check bundle config (nil)")
 	}
 
 	bundleCID := cfg.CID
 
-	matched, err := regexp.MatchString(config.CheckBundleCIDRegex, bundleCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.CheckBundlePrefix, bundleCID) {
 		return nil, errors.Errorf("invalid check bundle CID (%s)", bundleCID)
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("update check bundle, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("update check bundle, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Put(bundleCID, jsonCfg)
+	result, err := a.PutWithContext(ctx, bundleCID, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "updating check bundle")
 	}
 
 	checkBundle := &CheckBundle{}
-	if err := json.Unmarshal(result, checkBundle); err != nil {
+	if err := a.unmarshalJSON(result, checkBundle); err != nil {
 		return nil, errors.Wrap(err, "parsing check bundle")
 	}
 
@@ -162,26 +168,32 @@ func (a *API) UpdateCheckBundle(cfg *CheckBundle) (*CheckBundle, error) {
 
 // CreateCheckBundle creates a new check bundle (check).
 func (a *API) CreateCheckBundle(cfg *CheckBundle) (*CheckBundle, error) {
+	return a.CreateCheckBundleWithContext(context.Background(), cfg)
+}
+
+// CreateCheckBundleWithContext creates a new check bundle (check), stopping
+// when ctx is done.
+func (a *API) CreateCheckBundleWithContext(ctx context.Context, cfg *CheckBundle) (*CheckBundle, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid check bundle config (nil)")
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("create check bundle, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("create check bundle, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Post(config.CheckBundlePrefix, jsonCfg)
+	result, err := a.PostWithContext(ctx, config.CheckBundlePrefix, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "creating check bundle")
 	}
 
 	checkBundle := &CheckBundle{}
-	if err := json.Unmarshal(result, checkBundle); err != nil {
+	if err := a.unmarshalJSON(result, checkBundle); err != nil {
 		return nil, errors.Wrap(err, "parsing check bundle")
 	}
 
@@ -190,14 +202,26 @@ func (a *API) CreateCheckBundle(cfg *CheckBundle) (*CheckBundle, error) {
 
 // DeleteCheckBundle deletes passed check bundle.
 func (a *API) DeleteCheckBundle(cfg *CheckBundle) (bool, error) {
+	return a.DeleteCheckBundleWithContext(context.Background(), cfg)
+}
+
+// DeleteCheckBundleWithContext deletes passed check bundle, stopping when ctx
+// is done.
+func (a *API) DeleteCheckBundleWithContext(ctx context.Context, cfg *CheckBundle) (bool, error) {
 	if cfg == nil {
 		return false, errors.New("invalid check bundle config (nil)")
 	}
-	return a.DeleteCheckBundleByCID(CIDType(&cfg.CID))
+	return a.DeleteCheckBundleByCIDWithContext(ctx, CIDType(&cfg.CID))
 }
 
 // DeleteCheckBundleByCID deletes check bundle with passed cid.
 func (a *API) DeleteCheckBundleByCID(cid CIDType) (bool, error) {
+	return a.DeleteCheckBundleByCIDWithContext(context.Background(), cid)
+}
+
+// DeleteCheckBundleByCIDWithContext deletes check bundle with passed cid,
+// stopping when ctx is done.
+func (a *API) DeleteCheckBundleByCIDWithContext(ctx context.Context, cid CIDType) (bool, error) {
 
 	if cid == nil || *cid == "" {
 		return false, errors.New("invalid check bundle CID (none)")
@@ -210,15 +234,11 @@ func (a *API) DeleteCheckBundleByCID(cid CIDType) (bool, error) {
 		bundleCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.CheckBundleCIDRegex, bundleCID)
-	if err != nil {
-		return false, err
-	}
-	if !matched {
+	if !validCID(config.CheckBundlePrefix, bundleCID) {
 		return false, errors.Errorf("invalid check bundle CID (%v)", bundleCID)
 	}
 
-	_, err = a.Delete(bundleCID)
+	_, err := a.DeleteWithContext(ctx, bundleCID)
 	if err != nil {
 		return false, errors.Wrap(err, "deleting check bundle")
 	}
@@ -230,39 +250,259 @@ func (a *API) DeleteCheckBundleByCID(cid CIDType) (bool, error) {
 // search query and/or filter. If nil is passed for both parameters
 // all check bundles will be returned.
 func (a *API) SearchCheckBundles(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]CheckBundle, error) {
+	return a.SearchCheckBundlesWithContext(context.Background(), searchCriteria, filterCriteria)
+}
 
-	q := url.Values{}
+// SearchCheckBundlesWithContext returns check bundles matching the specified
+// search query and/or filter, stopping when ctx is done.
+func (a *API) SearchCheckBundlesWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]CheckBundle, error) {
 
-	if searchCriteria != nil && *searchCriteria != "" {
-		q.Set("search", string(*searchCriteria))
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchCheckBundlesWithContext(ctx)
 	}
 
-	if filterCriteria != nil && len(*filterCriteria) > 0 {
-		for filter, criteria := range *filterCriteria {
-			for _, val := range criteria {
-				q.Add(filter, val)
-			}
+	var results []CheckBundle
+	if err := a.searchJSONContext(ctx, config.CheckBundlePrefix, searchCriteria, filterCriteria, &results); err != nil {
+		return nil, errors.Wrap(err, "searching check bundles")
+	}
+
+	return &results, nil
+}
+
+// UpsertCheckBundle creates the passed check bundle, or updates the existing
+// check bundle of its cid (if set), otherwise of its display name, target,
+// and type - only if a field differs, so unchanged check bundles are not
+// updated. It returns the check bundle, and the fields created or changed,
+// none if it was unchanged. Only the fields the passed check bundle sets are
+// compared (not e.g. defaults the API fills in), metrics by name, and
+// brokers and tags in any order; fields set by the API (e.g. _cid, _checks)
+// and metric results are not compared, null and empty values are equal.
+func (a *API) UpsertCheckBundle(target *CheckBundle) (*CheckBundle, []AuditChange, error) {
+	return a.UpsertCheckBundleWithContext(context.Background(), target)
+}
+
+// UpsertCheckBundleWithContext creates or updates the passed check bundle,
+// see UpsertCheckBundle, stopping when ctx is done.
+func (a *API) UpsertCheckBundleWithContext(ctx context.Context, target *CheckBundle) (*CheckBundle, []AuditChange, error) {
+	if target == nil {
+		return nil, nil, errors.New("invalid check bundle config (nil)")
+	}
+
+	live, err := a.findCheckBundle(ctx, target)
+	if err != nil {
+		return nil, nil, err
+	}
+	desired, err := checkBundleFields(target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if live == nil {
+		changes := diffFields("", map[string]interface{}{}, desired, nil)
+		checkBundle, err := a.CreateCheckBundleWithContext(ctx, target)
+		if err != nil {
+			return nil, nil, err
 		}
+		return checkBundle, changes, nil
+	}
+
+	current, err := checkBundleFields(live)
+	if err != nil {
+		return nil, nil, err
+	}
+	changes := diffFields("", current, desired, nil)
+	if len(changes) == 0 {
+		return live, nil, nil
 	}
 
-	if q.Encode() == "" {
-		return a.FetchCheckBundles()
+	cfg := *target
+	cfg.CID = live.CID
+	checkBundle, err := a.UpdateCheckBundleWithContext(ctx, &cfg)
+	if err != nil {
+		return nil, nil, err
 	}
+	return checkBundle, changes, nil
+}
 
-	reqURL := url.URL{
-		Path:     config.CheckBundlePrefix,
-		RawQuery: q.Encode(),
+// findCheckBundle returns the check bundle of the target's cid, if set,
+// otherwise of its display name, target, and type - nil if there is none
+func (a *API) findCheckBundle(ctx context.Context, target *CheckBundle) (*CheckBundle, error) {
+	if target.CID != "" {
+		cid := target.CID
+		return a.FetchCheckBundleWithContext(ctx, CIDType(&cid))
+	}
+	if target.DisplayName == "" || target.Target == "" || target.Type == "" {
+		return nil, errors.New("invalid check bundle, display name, target, and type required")
 	}
 
-	resp, err := a.Get(reqURL.String())
+	filter := SearchFilterType{
+		"f_display_name": []string{target.DisplayName},
+		"f_target":       []string{target.Target},
+		"f_type":         []string{target.Type},
+	}
+	bundles, err := a.SearchCheckBundlesWithContext(ctx, nil, &filter)
 	if err != nil {
-		return nil, errors.Wrap(err, "searching check bundles")
+		return nil, err
 	}
 
-	var results []CheckBundle
-	if err := json.Unmarshal(resp, &results); err != nil {
-		return nil, errors.Wrap(err, "parsing check bundles")
+	var found *CheckBundle
+	for i := range *bundles {
+		b := &(*bundles)[i]
+		if b.DisplayName != target.DisplayName || b.Target != target.Target || b.Type != target.Type {
+			continue
+		}
+		if found != nil {
+			return nil, errors.Errorf("check bundles %s and %s both match %s %s (%s)", found.CID, b.CID, target.Type, target.Target, target.DisplayName)
+		}
+		found = b
 	}
+	return found, nil
+}
 
-	return &results, nil
+// checkBundleFields returns the fields of a check bundle compared by
+// UpsertCheckBundle, its decoded json without the metric results
+func checkBundleFields(b *CheckBundle) (map[string]interface{}, error) {
+	cfg := *b
+	cfg.Metrics = make([]CheckBundleMetric, len(b.Metrics))
+	for i, m := range b.Metrics {
+		m.Result = nil
+		cfg.Metrics[i] = m
+	}
+
+	data, err := json.Marshal(&cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding check bundle")
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, errors.Wrap(err, "decoding check bundle")
+	}
+	return fields, nil
+}
+
+// diffFields appends the changes from live to desired, decoded json values,
+// to changes. Objects are compared by the fields of desired (fields it does
+// not set, e.g. defaults filled in by the API, keep their live values),
+// arrays of objects with names (e.g. metrics) by name, and other arrays
+// (e.g. brokers) as sets; other values as a whole. Fields set by the API
+// (prefixed with _) are ignored, null and empty values are equal.
+func diffFields(field string, live, desired interface{}, changes []AuditChange) []AuditChange {
+	if emptyValue(live) && emptyValue(desired) {
+		return changes
+	}
+
+	liveObj, liveIsObj := live.(map[string]interface{})
+	desiredObj, desiredIsObj := desired.(map[string]interface{})
+	if liveIsObj && desiredIsObj {
+		keys := make([]string, 0, len(desiredObj))
+		for k := range desiredObj {
+			if !strings.HasPrefix(k, "_") {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			name := k
+			if field != "" {
+				name = fmt.Sprintf("%s.%s", field, k)
+			}
+			changes = diffFields(name, liveObj[k], desiredObj[k], changes)
+		}
+		return changes
+	}
+
+	liveArr, liveIsArr := live.([]interface{})
+	desiredArr, desiredIsArr := desired.([]interface{})
+	if liveIsArr && desiredIsArr {
+		liveNamed, liveOK := namedElements(liveArr)
+		desiredNamed, desiredOK := namedElements(desiredArr)
+		if liveOK && desiredOK {
+			names := make([]string, 0, len(liveNamed)+len(desiredNamed))
+			for name := range desiredNamed {
+				names = append(names, name)
+			}
+			for name := range liveNamed {
+				if _, ok := desiredNamed[name]; !ok {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				elemField := fmt.Sprintf("%s[%s]", field, name)
+				if _, ok := desiredNamed[name]; !ok {
+					// not desired, removed
+					changes = append(changes, AuditChange{Field: elemField, Old: liveNamed[name]})
+					continue
+				}
+				changes = diffFields(elemField, liveNamed[name], desiredNamed[name], changes)
+			}
+			return changes
+		}
+		if sameElements(liveArr, desiredArr) {
+			return changes
+		}
+	}
+
+	if !reflect.DeepEqual(live, desired) {
+		changes = append(changes, AuditChange{Field: field, Old: live, New: desired})
+	}
+	return changes
+}
+
+// namedElements returns the objects of an array by their (unique) name,
+// false if any element is not an object with a name
+func namedElements(arr []interface{}) (map[string]interface{}, bool) {
+	named := make(map[string]interface{}, len(arr))
+	for _, e := range arr {
+		obj, ok := e.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, ok := obj["name"].(string)
+		if !ok || name == "" {
+			return nil, false
+		}
+		if _, dup := named[name]; dup {
+			return nil, false
+		}
+		named[name] = obj
+	}
+	return named, true
+}
+
+// sameElements returns true if the arrays hold the same elements, in any
+// order
+func sameElements(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	used := make([]bool, len(b))
+	for _, x := range a {
+		found := false
+		for j, y := range b {
+			if !used[j] && reflect.DeepEqual(x, y) {
+				used[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// emptyValue returns true for null, and empty arrays and objects
+func emptyValue(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case []interface{}:
+		return len(t) == 0
+	case map[string]interface{}:
+		return len(t) == 0
+	}
+	return false
 }
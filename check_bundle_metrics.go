@@ -10,9 +10,8 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"regexp"
 	"strings"
 
 	"github.com/circonus-labs/go-apiclient/config"
@@ -27,6 +26,12 @@ type CheckBundleMetrics struct {
 
 // FetchCheckBundleMetrics retrieves metrics for the check bundle with passed cid.
 func (a *API) FetchCheckBundleMetrics(cid CIDType) (*CheckBundleMetrics, error) {
+	return a.FetchCheckBundleMetricsWithContext(context.Background(), cid)
+}
+
+// FetchCheckBundleMetricsWithContext retrieves metrics for the check bundle
+// with passed cid, stopping when ctx is done.
+func (a *API) FetchCheckBundleMetricsWithContext(ctx context.Context, cid CIDType) (*CheckBundleMetrics, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.New("invalid check bundle metrics CID (none)")
 	}
@@ -38,25 +43,21 @@ func (a *API) FetchCheckBundleMetrics(cid CIDType) (*CheckBundleMetrics, error)
 		metricsCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.CheckBundleMetricsCIDRegex, metricsCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.CheckBundleMetricsPrefix, metricsCID) {
 		return nil, errors.Errorf("invalid check bundle metrics CID (%s)", metricsCID)
 	}
 
-	result, err := a.Get(metricsCID)
+	result, err := a.GetWithContext(ctx, metricsCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching check bundle metrics")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch check bundle metrics, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch check bundle metrics, received JSON: %s", string(result))
 	}
 
 	metrics := &CheckBundleMetrics{}
-	if err := json.Unmarshal(result, metrics); err != nil {
+	if err := a.unmarshalJSON(result, metrics); err != nil {
 		return nil, errors.Wrap(err, "parsing check bundle metrics")
 	}
 
@@ -65,36 +66,38 @@ func (a *API) FetchCheckBundleMetrics(cid CIDType) (*CheckBundleMetrics, error)
 
 // UpdateCheckBundleMetrics updates passed metrics.
 func (a *API) UpdateCheckBundleMetrics(cfg *CheckBundleMetrics) (*CheckBundleMetrics, error) {
+	return a.UpdateCheckBundleMetricsWithContext(context.Background(), cfg)
+}
+
+// UpdateCheckBundleMetricsWithContext updates passed metrics, stopping when
+// ctx is done.
+func (a *API) UpdateCheckBundleMetricsWithContext(ctx context.Context, cfg *CheckBundleMetrics) (*CheckBundleMetrics, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid check bundle metrics config (nil)")
 	}
 
 	metricsCID := cfg.CID
 
-	matched, err := regexp.MatchString(config.CheckBundleMetricsCIDRegex, metricsCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.CheckBundleMetricsPrefix, metricsCID) {
 		return nil, errors.Errorf("invalid check bundle metrics CID (%s)", metricsCID)
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("update check bundle metrics, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("update check bundle metrics, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Put(metricsCID, jsonCfg)
+	result, err := a.PutWithContext(ctx, metricsCID, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "updating check bundle metrics")
 	}
 
 	metrics := &CheckBundleMetrics{}
-	if err := json.Unmarshal(result, metrics); err != nil {
+	if err := a.unmarshalJSON(result, metrics); err != nil {
 		return nil, errors.Wrap(err, "parsing check bundle metrics")
 	}
 
@@ -14,6 +14,7 @@ import (
 	"testing"
 
 	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
 )
 
 var (
@@ -380,3 +381,179 @@ func TestSearchCheckBundles(t *testing.T) {
 		})
 	}
 }
+
+func TestUpsertCheckBundle(t *testing.T) {
+	result := "0.5"
+	notes := ""
+	fake, err := fakecirconus.New(&fakecirconus.Config{
+		Fixtures: map[string]interface{}{
+			"/check_bundle/1": CheckBundle{
+				CID:         "/check_bundle/1",
+				Checks:      []string{"/check/1"},
+				DisplayName: "web1 http",
+				Target:      "web1",
+				Type:        "http",
+				Brokers:     []string{"/broker/2", "/broker/1"},
+				Config:      CheckBundleConfig{config.URL: "http://web1/", config.HTTPVersion: "1.1"},
+				Metrics: []CheckBundleMetric{
+					{Name: "code", Type: "text", Status: "active"},
+					{Name: "duration", Type: "numeric", Status: "active", Result: &result},
+				},
+				Period: 60,
+				// defaults of the API, not set by the target
+				MetricLimit: -1,
+				Notes:       &notes,
+				Status:      "active",
+				Tags:        []string{"service:web"},
+				Timeout:     10,
+			},
+			"/check_bundle/2": CheckBundle{CID: "/check_bundle/2", DisplayName: "web2 http", Target: "web2", Type: "http"},
+			"/check_bundle/3": CheckBundle{CID: "/check_bundle/3", DisplayName: "web2 http", Target: "web2", Type: "http"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: fake.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	desired := func() *CheckBundle {
+		return &CheckBundle{
+			DisplayName: "web1 http",
+			Target:      "web1",
+			Type:        "http",
+			Brokers:     []string{"/broker/1", "/broker/2"},
+			Config:      CheckBundleConfig{config.URL: "http://web1/"},
+			Metrics: []CheckBundleMetric{
+				{Name: "duration", Type: "numeric", Status: "active", Tags: []string{}},
+				{Name: "code", Type: "text", Status: "active"},
+			},
+			Period: 60,
+		}
+	}
+	puts := func() int {
+		n := 0
+		for _, r := range fake.Requests() {
+			if r.Method == "PUT" {
+				n++
+			}
+		}
+		return n
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		b, changes, err := apih.UpsertCheckBundle(desired())
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if b.CID != "/check_bundle/1" || len(changes) != 0 {
+			t.Fatalf("unexpected upsert (%s %v)", b.CID, changes)
+		}
+		if n := puts(); n != 0 {
+			t.Fatalf("expected no update (%d)", n)
+		}
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		cfg := desired()
+		cfg.Period = 30
+		cfg.Config[config.URL] = "https://web1/"
+		b, changes, err := apih.UpsertCheckBundle(cfg)
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		expected := []AuditChange{
+			{Field: "config.url", Old: "http://web1/", New: "https://web1/"},
+			{Field: "period", Old: float64(60), New: float64(30)},
+		}
+		if b.CID != "/check_bundle/1" || b.Period != 30 || !reflect.DeepEqual(changes, expected) {
+			t.Fatalf("unexpected upsert (%s %d %v)", b.CID, b.Period, changes)
+		}
+		if cfg.CID != "" {
+			t.Fatal("expected the passed check bundle unchanged")
+		}
+		if n := puts(); n != 1 {
+			t.Fatalf("expected an update (%d)", n)
+		}
+
+		// by cid, unchanged since
+		cfg.CID = "/check_bundle/1"
+		if _, changes, err := apih.UpsertCheckBundle(cfg); err != nil || len(changes) != 0 {
+			t.Fatalf("unexpected upsert (%v %v)", changes, err)
+		}
+	})
+
+	t.Run("created", func(t *testing.T) {
+		cfg := desired()
+		cfg.DisplayName = "web1 https"
+		b, changes, err := apih.UpsertCheckBundle(cfg)
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if b.CID == "" || b.CID == "/check_bundle/1" || len(changes) == 0 {
+			t.Fatalf("unexpected upsert (%s %v)", b.CID, changes)
+		}
+		for _, c := range changes {
+			if c.Old != nil {
+				t.Fatalf("unexpected change (%v)", c)
+			}
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			cfg         *CheckBundle
+			expectedErr string
+		}{
+			{nil, "invalid check bundle config (nil)"},
+			{&CheckBundle{DisplayName: "web1 http"}, "invalid check bundle, display name, target, and type required"},
+			{&CheckBundle{DisplayName: "web2 http", Target: "web2", Type: "http"}, "check bundles /check_bundle/2 and /check_bundle/3 both match http web2 (web2 http)"},
+		}
+		for _, test := range tests {
+			if _, _, err := apih.UpsertCheckBundle(test.cfg); err == nil || err.Error() != test.expectedErr {
+				t.Errorf("unexpected error (%v)", err)
+			}
+		}
+	})
+}
+
+func TestDiffFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		live     interface{}
+		desired  interface{}
+		expected []AuditChange
+	}{
+		{"equal", map[string]interface{}{"a": "x"}, map[string]interface{}{"a": "x"}, nil},
+		{"null and empty", map[string]interface{}{"tags": []interface{}{}}, map[string]interface{}{"tags": nil, "config": map[string]interface{}{}}, nil},
+		{"read-only", map[string]interface{}{"_cid": "/check_bundle/1"}, map[string]interface{}{}, nil},
+		{"changed", map[string]interface{}{"a": "x"}, map[string]interface{}{"a": "y"}, []AuditChange{{Field: "a", Old: "x", New: "y"}}},
+		{"added", map[string]interface{}{}, map[string]interface{}{"a": "y"}, []AuditChange{{Field: "a", New: "y"}}},
+		{"not desired", map[string]interface{}{"a": "x", "period": 60.0, "config": map[string]interface{}{"port": "80"}}, map[string]interface{}{"a": "x", "config": map[string]interface{}{}}, nil},
+		{"element", map[string]interface{}{"b": []interface{}{"1", "2"}}, map[string]interface{}{"b": []interface{}{"1", "3"}}, []AuditChange{{Field: "b", Old: []interface{}{"1", "2"}, New: []interface{}{"1", "3"}}}},
+		{"reordered", map[string]interface{}{"b": []interface{}{"1", "2"}}, map[string]interface{}{"b": []interface{}{"2", "1"}}, nil},
+		{"length", map[string]interface{}{"b": []interface{}{"1"}}, map[string]interface{}{"b": []interface{}{"1", "2"}}, []AuditChange{{Field: "b", Old: []interface{}{"1"}, New: []interface{}{"1", "2"}}}},
+		{"named", map[string]interface{}{"m": []interface{}{
+			map[string]interface{}{"name": "a", "status": "active"},
+			map[string]interface{}{"name": "b", "status": "active"},
+			map[string]interface{}{"name": "c"},
+		}}, map[string]interface{}{"m": []interface{}{
+			map[string]interface{}{"name": "d"},
+			map[string]interface{}{"name": "b", "status": "available"},
+			map[string]interface{}{"name": "a"},
+		}}, []AuditChange{
+			{Field: "m[b].status", Old: "active", New: "available"},
+			{Field: "m[c]", Old: map[string]interface{}{"name": "c"}},
+			{Field: "m[d]", New: map[string]interface{}{"name": "d"}},
+		}},
+	}
+	for _, test := range tests {
+		if changes := diffFields("", test.live, test.desired, nil); !reflect.DeepEqual(changes, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, changes)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Check move API support - Fetch, Create, Delete, and Search
+// See: https://login.circonus.com/resources/api/calls/check_move
+// Note: check moves cannot be updated, delete and create a new move instead
+
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// Check move statuses
+const (
+	CheckMoveStatusNew      = "new"
+	CheckMoveStatusComplete = "complete"
+)
+
+// CheckMove defines a request to move a check to another broker. See https://login.circonus.com/resources/api/calls/check_move for more information.
+type CheckMove struct {
+	BrokerCID    string  `json:"new_broker"`           // string
+	CheckCID     string  `json:"check_id"`             // string
+	CID          string  `json:"_cid,omitempty"`       // string
+	Error        *string `json:"_error,omitempty"`     // string or null
+	OldBrokerCID string  `json:"old_broker,omitempty"` // string
+	Status       string  `json:"status,omitempty"`     // string
+}
+
+// NewCheckMove returns a new CheckMove (with defaults, if applicable)
+func NewCheckMove() *CheckMove {
+	return &CheckMove{}
+}
+
+// FetchCheckMove retrieves check move with passed cid.
+func (a *API) FetchCheckMove(cid CIDType) (*CheckMove, error) {
+	return a.FetchCheckMoveWithContext(context.Background(), cid)
+}
+
+// FetchCheckMoveWithContext retrieves check move with passed cid, stopping
+// when ctx is done.
+func (a *API) FetchCheckMoveWithContext(ctx context.Context, cid CIDType) (*CheckMove, error) {
+	if cid == nil || *cid == "" {
+		return nil, errors.New("invalid check move CID (none)")
+	}
+
+	var moveCID string
+	if !strings.HasPrefix(*cid, config.CheckMovePrefix) {
+		moveCID = fmt.Sprintf("%s/%s", config.CheckMovePrefix, *cid)
+	} else {
+		moveCID = *cid
+	}
+
+	if !validCID(config.CheckMovePrefix, moveCID) {
+		return nil, errors.Errorf("invalid check move CID (%s)", moveCID)
+	}
+
+	result, err := a.GetWithContext(ctx, moveCID)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching check move")
+	}
+
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch check move, received JSON: %s", string(result))
+	}
+
+	move := &CheckMove{}
+	if err := a.unmarshalJSON(result, move); err != nil {
+		return nil, errors.Wrap(err, "parsing check move")
+	}
+
+	return move, nil
+}
+
+// FetchCheckMoves retrieves all check moves available to API Token.
+func (a *API) FetchCheckMoves() (*[]CheckMove, error) {
+	return a.FetchCheckMovesWithContext(context.Background())
+}
+
+// FetchCheckMovesWithContext retrieves all check moves available to API
+// Token, stopping when ctx is done.
+func (a *API) FetchCheckMovesWithContext(ctx context.Context) (*[]CheckMove, error) {
+	var moves []CheckMove
+	if err := a.getJSONContext(ctx, config.CheckMovePrefix, &moves); err != nil {
+		return nil, errors.Wrap(err, "fetching check moves")
+	}
+
+	return &moves, nil
+}
+
+// CreateCheckMove creates a new check move.
+func (a *API) CreateCheckMove(cfg *CheckMove) (*CheckMove, error) {
+	return a.CreateCheckMoveWithContext(context.Background(), cfg)
+}
+
+// CreateCheckMoveWithContext creates a new check move, stopping when ctx is
+// done.
+func (a *API) CreateCheckMoveWithContext(ctx context.Context, cfg *CheckMove) (*CheckMove, error) {
+	if cfg == nil {
+		return nil, errors.New("invalid check move config (nil)")
+	}
+
+	jsonCfg, err := a.marshalJSON(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.debugEnabled() {
+		a.apiLog().Printf("create check move, sending JSON: %s", jsonCfg.String())
+	}
+
+	result, err := a.PostWithContext(ctx, config.CheckMovePrefix, jsonCfg.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "creating check move")
+	}
+
+	move := &CheckMove{}
+	if err := a.unmarshalJSON(result, move); err != nil {
+		return nil, errors.Wrap(err, "parsing check move")
+	}
+
+	return move, nil
+}
+
+// DeleteCheckMove deletes passed check move.
+func (a *API) DeleteCheckMove(cfg *CheckMove) (bool, error) {
+	return a.DeleteCheckMoveWithContext(context.Background(), cfg)
+}
+
+// DeleteCheckMoveWithContext deletes passed check move, stopping when ctx is
+// done.
+func (a *API) DeleteCheckMoveWithContext(ctx context.Context, cfg *CheckMove) (bool, error) {
+	if cfg == nil {
+		return false, errors.New("invalid check move config (nil)")
+	}
+	return a.DeleteCheckMoveByCIDWithContext(ctx, CIDType(&cfg.CID))
+}
+
+// DeleteCheckMoveByCID deletes check move with passed cid.
+func (a *API) DeleteCheckMoveByCID(cid CIDType) (bool, error) {
+	return a.DeleteCheckMoveByCIDWithContext(context.Background(), cid)
+}
+
+// DeleteCheckMoveByCIDWithContext deletes check move with passed cid,
+// stopping when ctx is done.
+func (a *API) DeleteCheckMoveByCIDWithContext(ctx context.Context, cid CIDType) (bool, error) {
+	if cid == nil || *cid == "" {
+		return false, errors.New("invalid check move CID (none)")
+	}
+
+	var moveCID string
+	if !strings.HasPrefix(*cid, config.CheckMovePrefix) {
+		moveCID = fmt.Sprintf("%s/%s", config.CheckMovePrefix, *cid)
+	} else {
+		moveCID = *cid
+	}
+
+	if !validCID(config.CheckMovePrefix, moveCID) {
+		return false, errors.Errorf("invalid check move CID (%s)", moveCID)
+	}
+
+	_, err := a.DeleteWithContext(ctx, moveCID)
+	if err != nil {
+		return false, errors.Wrap(err, "deleting check move")
+	}
+
+	return true, nil
+}
+
+// SearchCheckMoves returns check moves matching a filter (search queries
+// are not supported by the check move endpoint). Pass nil as filter for all
+// check moves available to the API Token.
+func (a *API) SearchCheckMoves(filterCriteria *SearchFilterType) (*[]CheckMove, error) {
+	return a.SearchCheckMovesWithContext(context.Background(), filterCriteria)
+}
+
+// SearchCheckMovesWithContext returns check moves matching a filter (search
+// queries are not supported by the check move endpoint), stopping when ctx is
+// done.
+func (a *API) SearchCheckMovesWithContext(ctx context.Context, filterCriteria *SearchFilterType) (*[]CheckMove, error) {
+	if emptySearch(nil, filterCriteria) {
+		return a.FetchCheckMovesWithContext(ctx)
+	}
+
+	var moves []CheckMove
+	if err := a.searchJSONContext(ctx, config.CheckMovePrefix, nil, filterCriteria, &moves); err != nil {
+		return nil, errors.Wrap(err, "searching check moves")
+	}
+
+	return &moves, nil
+}
+
+// MoveCheck requests the check with passed cid be moved to the broker with passed cid.
+func (a *API) MoveCheck(checkCID, brokerCID CIDType) (*CheckMove, error) {
+	if checkCID == nil || *checkCID == "" {
+		return nil, errors.New("invalid check CID (none)")
+	}
+	if brokerCID == nil || *brokerCID == "" {
+		return nil, errors.New("invalid broker CID (none)")
+	}
+
+	move := &CheckMove{
+		BrokerCID: *brokerCID,
+		CheckCID:  *checkCID,
+	}
+	if !strings.HasPrefix(move.BrokerCID, config.BrokerPrefix) {
+		move.BrokerCID = fmt.Sprintf("%s/%s", config.BrokerPrefix, move.BrokerCID)
+	}
+	if !strings.HasPrefix(move.CheckCID, config.CheckPrefix) {
+		move.CheckCID = fmt.Sprintf("%s/%s", config.CheckPrefix, move.CheckCID)
+	}
+
+	return a.CreateCheckMove(move)
+}
+
+// checkMovePollInterval is the delay between check move status checks in MoveCheckAndWait
+var checkMovePollInterval = 5 * time.Second
+
+// MoveCheckAndWait requests the check be moved to the broker and waits, up to
+// timeout, for the move to complete. The completed check move is returned.
+func (a *API) MoveCheckAndWait(checkCID, brokerCID CIDType, timeout time.Duration) (*CheckMove, error) {
+	if timeout <= 0 {
+		return nil, errors.Errorf("invalid check move timeout (%s)", timeout)
+	}
+
+	move, err := a.MoveCheck(checkCID, brokerCID)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		if move.Error != nil && *move.Error != "" {
+			return nil, errors.Errorf("moving check %s to %s: %s", move.CheckCID, move.BrokerCID, *move.Error)
+		}
+		if move.Status == CheckMoveStatusComplete {
+			return move, nil
+		}
+
+		if time.Now().Add(checkMovePollInterval).After(deadline) {
+			return nil, errors.Errorf("timed out after %s waiting for check move %s (%s) to complete", timeout, move.CID, move.Status)
+		}
+
+		if a.debugEnabled() {
+			a.apiLog().Printf("check move, waiting for %s to complete (%s)", move.CID, move.Status)
+		}
+
+		time.Sleep(checkMovePollInterval)
+
+		moveCID := move.CID
+		move, err = a.FetchCheckMove(CIDType(&moveCID))
+		if err != nil {
+			return nil, errors.Wrap(err, "waiting for check move")
+		}
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var (
+	testCheckMove = CheckMove{
+		BrokerCID:    "/broker/2",
+		CheckCID:     "/check/1234",
+		CID:          "/check_move/1234",
+		OldBrokerCID: "/broker/1",
+		Status:       CheckMoveStatusNew,
+	}
+)
+
+// testCheckMoveServer completes a move after polls GETs, moves of check
+// /check/9999 fail
+func testCheckMoveServer(polls int) *httptest.Server {
+	gets := 0
+	f := func(w http.ResponseWriter, r *http.Request) {
+		move := testCheckMove
+		switch {
+		case r.URL.Path == "/check_move" && r.Method == "POST":
+			defer r.Body.Close()
+			b, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				panic(err)
+			}
+			var req CheckMove
+			if err := json.Unmarshal(b, &req); err != nil {
+				panic(err)
+			}
+			move.BrokerCID = req.BrokerCID
+			move.CheckCID = req.CheckCID
+			if req.CheckCID == "/check/9999" {
+				move.CID = "/check_move/9999"
+			}
+		case r.URL.Path == "/check_move" && r.Method == "GET":
+			ret, _ := json.Marshal([]CheckMove{move})
+			w.WriteHeader(200)
+			fmt.Fprintln(w, string(ret))
+			return
+		case r.URL.Path == "/check_move/1234" && r.Method == "GET":
+			gets++
+			if gets >= polls {
+				move.Status = CheckMoveStatusComplete
+			}
+		case r.URL.Path == "/check_move/9999" && r.Method == "GET":
+			move.CID = "/check_move/9999"
+			move.CheckCID = "/check/9999"
+			move.Error = &[]string{"broker unreachable"}[0]
+		case r.URL.Path == "/check_move/1234" && r.Method == "DELETE":
+		default:
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, r.URL.Path))
+			return
+		}
+		ret, err := json.Marshal(move)
+		if err != nil {
+			panic(err)
+		}
+		w.WriteHeader(200)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, string(ret))
+	}
+
+	return httptest.NewServer(http.HandlerFunc(f))
+}
+
+func checkMoveTestBootstrap(t *testing.T, polls int) (*API, *httptest.Server) {
+	server := testCheckMoveServer(polls)
+
+	ac := &Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      server.URL,
+	}
+	apih, err := NewAPI(ac)
+	if err != nil {
+		server.Close()
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	return apih, server
+}
+
+func TestFetchCheckMove(t *testing.T) {
+	apih, server := checkMoveTestBootstrap(t, 1)
+	defer server.Close()
+
+	tests := []struct {
+		id          string
+		cid         string
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"empty cid", "", true, "invalid check move CID (none)"},
+		{"short cid", "1234", false, ""},
+		{"long cid", "/check_move/1234", false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			move, err := apih.FetchCheckMove(CIDType(&test.cid))
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if move.CheckCID != testCheckMove.CheckCID {
+				t.Fatalf("unexpected check (%s)", move.CheckCID)
+			}
+		})
+	}
+}
+
+func TestFetchCheckMoves(t *testing.T) {
+	apih, server := checkMoveTestBootstrap(t, 1)
+	defer server.Close()
+
+	moves, err := apih.FetchCheckMoves()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(*moves) != 1 {
+		t.Fatalf("expected 1 move, got %d", len(*moves))
+	}
+}
+
+func TestDeleteCheckMoveByCID(t *testing.T) {
+	apih, server := checkMoveTestBootstrap(t, 1)
+	defer server.Close()
+
+	empty := ""
+	if _, err := apih.DeleteCheckMoveByCID(CIDType(&empty)); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := apih.DeleteCheckMove(nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if ok, err := apih.DeleteCheckMove(&testCheckMove); err != nil || !ok {
+		t.Fatalf("expected true (deleted), got error (%v)", err)
+	}
+}
+
+func TestMoveCheck(t *testing.T) {
+	apih, server := checkMoveTestBootstrap(t, 1)
+	defer server.Close()
+
+	check, broker, empty := "1234", "5", ""
+
+	tests := []struct {
+		id          string
+		check       *string
+		broker      *string
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (check)", &empty, &broker, true, "invalid check CID (none)"},
+		{"invalid (broker)", &check, nil, true, "invalid broker CID (none)"},
+		{"valid", &check, &broker, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			move, err := apih.MoveCheck(CIDType(test.check), CIDType(test.broker))
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if move.CheckCID != "/check/1234" || move.BrokerCID != "/broker/5" {
+				t.Fatalf("unexpected move (%#v)", move)
+			}
+		})
+	}
+}
+
+func TestMoveCheckAndWait(t *testing.T) {
+	checkMovePollInterval = 10 * time.Millisecond
+
+	broker := "/broker/2"
+
+	tests := []struct {
+		id          string
+		check       string
+		polls       int
+		timeout     time.Duration
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (timeout)", "1234", 1, 0, true, "invalid check move timeout (0s)"},
+		{"timeout", "1234", 100, 50 * time.Millisecond, true, "timed out after 50ms waiting for check move /check_move/1234 (new) to complete"},
+		{"error", "9999", 1, time.Second, true, "moving check /check/9999 to /broker/2: broker unreachable"},
+		{"complete", "1234", 3, time.Second, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			apih, server := checkMoveTestBootstrap(t, test.polls)
+			defer server.Close()
+
+			move, err := apih.MoveCheckAndWait(CIDType(&test.check), CIDType(&broker), test.timeout)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if move.Status != CheckMoveStatusComplete {
+				t.Fatalf("unexpected status (%s)", move.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Check template API support - Fetch, Create, Update, Delete, and Search
+// See: https://login.circonus.com/resources/api/calls/template
+
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// CheckTemplateBundle defines a check bundle included in a check template
+type CheckTemplateBundle struct {
+	BundleCID string `json:"bundle_id"` // string
+	Name      string `json:"name"`      // string
+}
+
+// CheckTemplate defines a check template. Check bundles on the master host are
+// replicated to all hosts in the template. See https://login.circonus.com/resources/api/calls/template
+// for more information.
+type CheckTemplate struct {
+	CheckBundles   []CheckTemplateBundle `json:"check_bundles"`               // [] len >= 1
+	CID            string                `json:"_cid,omitempty"`              // string
+	Hosts          []string              `json:"hosts"`                       // [] len >= 0
+	LastModified   uint                  `json:"_last_modified,omitempty"`    // uint
+	LastModifiedBy string                `json:"_last_modified_by,omitempty"` // string
+	MasterHost     string                `json:"master_host"`                 // string
+	Name           string                `json:"name"`                        // string
+	Notes          *string               `json:"notes"`                       // string or null
+	Status         string                `json:"status,omitempty"`            // string
+	SyncRules      bool                  `json:"sync_rules"`                  // boolean, apply rule sets of the master host checks to all hosts
+	Tags           []string              `json:"tags"`                        // [] len >= 0
+}
+
+// NewCheckTemplate returns a new CheckTemplate (with defaults, if applicable)
+func NewCheckTemplate() *CheckTemplate {
+	return &CheckTemplate{
+		CheckBundles: []CheckTemplateBundle{},
+		Hosts:        []string{},
+		SyncRules:    true,
+		Tags:         []string{},
+	}
+}
+
+// FetchCheckTemplate retrieves check template with passed cid.
+func (a *API) FetchCheckTemplate(cid CIDType) (*CheckTemplate, error) {
+	return a.FetchCheckTemplateWithContext(context.Background(), cid)
+}
+
+// FetchCheckTemplateWithContext retrieves check template with passed cid,
+// stopping when ctx is done.
+func (a *API) FetchCheckTemplateWithContext(ctx context.Context, cid CIDType) (*CheckTemplate, error) {
+	if cid == nil || *cid == "" {
+		return nil, errors.New("invalid check template CID (none)")
+	}
+
+	var templateCID string
+	if !strings.HasPrefix(*cid, config.CheckTemplatePrefix) {
+		templateCID = fmt.Sprintf("%s/%s", config.CheckTemplatePrefix, *cid)
+	} else {
+		templateCID = *cid
+	}
+
+	if !validCID(config.CheckTemplatePrefix, templateCID) {
+		return nil, errors.Errorf("invalid check template CID (%s)", templateCID)
+	}
+
+	result, err := a.GetWithContext(ctx, templateCID)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching check template")
+	}
+
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch check template, received JSON: %s", string(result))
+	}
+
+	template := new(CheckTemplate)
+	if err := a.unmarshalJSON(result, template); err != nil {
+		return nil, errors.Wrap(err, "parsing check template")
+	}
+
+	return template, nil
+}
+
+// FetchCheckTemplates retrieves all check templates available to API Token.
+func (a *API) FetchCheckTemplates() (*[]CheckTemplate, error) {
+	return a.FetchCheckTemplatesWithContext(context.Background())
+}
+
+// FetchCheckTemplatesWithContext retrieves all check templates available to
+// API Token, stopping when ctx is done.
+func (a *API) FetchCheckTemplatesWithContext(ctx context.Context) (*[]CheckTemplate, error) {
+	var templates []CheckTemplate
+	if err := a.getJSONContext(ctx, config.CheckTemplatePrefix, &templates); err != nil {
+		return nil, errors.Wrap(err, "fetching check templates")
+	}
+
+	return &templates, nil
+}
+
+// UpdateCheckTemplate updates passed check template.
+func (a *API) UpdateCheckTemplate(cfg *CheckTemplate) (*CheckTemplate, error) {
+	return a.UpdateCheckTemplateWithContext(context.Background(), cfg)
+}
+
+// UpdateCheckTemplateWithContext updates passed check template, stopping when
+// ctx is done.
+func (a *API) UpdateCheckTemplateWithContext(ctx context.Context, cfg *CheckTemplate) (*CheckTemplate, error) {
+	if cfg == nil {
+		return nil, errors.Errorf("invalid check template config (nil)")
+	}
+
+	templateCID := cfg.CID
+
+	if !validCID(config.CheckTemplatePrefix, templateCID) {
+		return nil, errors.Errorf("invalid check template CID (%s)", templateCID)
+	}
+
+	jsonCfg, err := a.marshalJSON(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.debugEnabled() {
+		a.apiLog().Printf("update check template, sending JSON: %s", jsonCfg.String())
+	}
+
+	result, err := a.PutWithContext(ctx, templateCID, jsonCfg.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "updating check template")
+	}
+
+	template := &CheckTemplate{}
+	if err := a.unmarshalJSON(result, template); err != nil {
+		return nil, errors.Wrap(err, "parsing check template")
+	}
+
+	return template, nil
+}
+
+// CreateCheckTemplate creates a new check template.
+func (a *API) CreateCheckTemplate(cfg *CheckTemplate) (*CheckTemplate, error) {
+	return a.CreateCheckTemplateWithContext(context.Background(), cfg)
+}
+
+// CreateCheckTemplateWithContext creates a new check template, stopping when
+// ctx is done.
+func (a *API) CreateCheckTemplateWithContext(ctx context.Context, cfg *CheckTemplate) (*CheckTemplate, error) {
+	if cfg == nil {
+		return nil, errors.New("invalid check template config (nil)")
+	}
+
+	jsonCfg, err := a.marshalJSON(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.debugEnabled() {
+		a.apiLog().Printf("create check template, sending JSON: %s", jsonCfg.String())
+	}
+
+	result, err := a.PostWithContext(ctx, config.CheckTemplatePrefix, jsonCfg.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "creating check template")
+	}
+
+	template := &CheckTemplate{}
+	if err := a.unmarshalJSON(result, template); err != nil {
+		return nil, errors.Wrap(err, "parsing check template")
+	}
+
+	return template, nil
+}
+
+// DeleteCheckTemplate deletes passed check template.
+func (a *API) DeleteCheckTemplate(cfg *CheckTemplate) (bool, error) {
+	return a.DeleteCheckTemplateWithContext(context.Background(), cfg)
+}
+
+// DeleteCheckTemplateWithContext deletes passed check template, stopping when
+// ctx is done.
+func (a *API) DeleteCheckTemplateWithContext(ctx context.Context, cfg *CheckTemplate) (bool, error) {
+	if cfg == nil {
+		return false, errors.New("invalid check template config (nil)")
+	}
+	return a.DeleteCheckTemplateByCIDWithContext(ctx, CIDType(&cfg.CID))
+}
+
+// DeleteCheckTemplateByCID deletes check template with passed cid.
+func (a *API) DeleteCheckTemplateByCID(cid CIDType) (bool, error) {
+	return a.DeleteCheckTemplateByCIDWithContext(context.Background(), cid)
+}
+
+// DeleteCheckTemplateByCIDWithContext deletes check template with passed cid,
+// stopping when ctx is done.
+func (a *API) DeleteCheckTemplateByCIDWithContext(ctx context.Context, cid CIDType) (bool, error) {
+	if cid == nil || *cid == "" {
+		return false, errors.New("invalid check template CID (none)")
+	}
+
+	var templateCID string
+	if !strings.HasPrefix(*cid, config.CheckTemplatePrefix) {
+		templateCID = fmt.Sprintf("%s/%s", config.CheckTemplatePrefix, *cid)
+	} else {
+		templateCID = *cid
+	}
+
+	if !validCID(config.CheckTemplatePrefix, templateCID) {
+		return false, errors.Errorf("invalid check template CID (%s)", templateCID)
+	}
+
+	_, err := a.DeleteWithContext(ctx, templateCID)
+	if err != nil {
+		return false, errors.Wrap(err, "deleting check template")
+	}
+
+	return true, nil
+}
+
+// SearchCheckTemplates returns check templates matching the specified search
+// query and/or filter. If nil is passed for both parameters all
+// check templates will be returned.
+func (a *API) SearchCheckTemplates(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]CheckTemplate, error) {
+	return a.SearchCheckTemplatesWithContext(context.Background(), searchCriteria, filterCriteria)
+}
+
+// SearchCheckTemplatesWithContext returns check templates matching the
+// specified search query and/or filter, stopping when ctx is done.
+func (a *API) SearchCheckTemplatesWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]CheckTemplate, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchCheckTemplatesWithContext(ctx)
+	}
+
+	var templates []CheckTemplate
+	if err := a.searchJSONContext(ctx, config.CheckTemplatePrefix, searchCriteria, filterCriteria, &templates); err != nil {
+		return nil, errors.Wrap(err, "searching check templates")
+	}
+
+	return &templates, nil
+}
+
+// AddHost adds the passed host to the template, returns false if the host is
+// already included.
+func (t *CheckTemplate) AddHost(host string) bool {
+	for _, h := range t.Hosts {
+		if h == host {
+			return false
+		}
+	}
+	t.Hosts = append(t.Hosts, host)
+	return true
+}
+
+// RemoveHost removes the passed host from the template, returns false if the
+// host is not included. The master host cannot be removed.
+func (t *CheckTemplate) RemoveHost(host string) (bool, error) {
+	if host == t.MasterHost {
+		return false, errors.Errorf("invalid check template host (%s), cannot remove master host", host)
+	}
+	for i, h := range t.Hosts {
+		if h == host {
+			t.Hosts = append(t.Hosts[:i], t.Hosts[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,359 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+var (
+	testCheckTemplate = CheckTemplate{
+		CheckBundles: []CheckTemplateBundle{
+			{BundleCID: "/check_bundle/1234", Name: "web http"},
+		},
+		CID:        "/template/1234",
+		Hosts:      []string{"10.0.0.1", "10.0.0.2"},
+		MasterHost: "10.0.0.1",
+		Name:       "web servers",
+		Status:     "active",
+		SyncRules:  true,
+		Tags:       []string{"service:web"},
+	}
+)
+
+func testCheckTemplateServer() *httptest.Server {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		switch path {
+		case "/template/1234":
+			switch r.Method {
+			case "GET":
+				ret, err := json.Marshal(testCheckTemplate)
+				if err != nil {
+					panic(err)
+				}
+				w.WriteHeader(200)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintln(w, string(ret))
+			case "PUT":
+				defer r.Body.Close()
+				b, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					panic(err)
+				}
+				w.WriteHeader(200)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintln(w, string(b))
+			case "DELETE":
+				w.WriteHeader(200)
+				w.Header().Set("Content-Type", "application/json")
+			default:
+				w.WriteHeader(404)
+				fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
+			}
+		case "/template":
+			switch r.Method {
+			case "GET":
+				reqURL := r.URL.String()
+				var c []CheckTemplate
+				switch reqURL {
+				case "/template", "/template?search=web":
+					c = []CheckTemplate{testCheckTemplate}
+				case "/template?f_status=test":
+					c = []CheckTemplate{}
+				default:
+					w.WriteHeader(404)
+					fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, reqURL))
+					return
+				}
+				ret, err := json.Marshal(c)
+				if err != nil {
+					panic(err)
+				}
+				w.WriteHeader(200)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintln(w, string(ret))
+			case "POST":
+				defer r.Body.Close()
+				b, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					panic(err)
+				}
+				w.WriteHeader(200)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintln(w, string(b))
+			default:
+				w.WriteHeader(404)
+				fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
+			}
+		default:
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
+		}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(f))
+}
+
+func checkTemplateTestBootstrap(t *testing.T) (*API, func()) {
+	server := testCheckTemplateServer()
+
+	ac := &Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      server.URL,
+	}
+	apih, err := NewAPI(ac)
+	if err != nil {
+		server.Close()
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	return apih, server.Close
+}
+
+func TestNewCheckTemplate(t *testing.T) {
+	template := NewCheckTemplate()
+	if reflect.TypeOf(template).String() != "*apiclient.CheckTemplate" {
+		t.Fatalf("unexpected type (%s)", reflect.TypeOf(template).String())
+	}
+	if !template.SyncRules {
+		t.Fatal("expected sync rules to default to true")
+	}
+}
+
+func TestFetchCheckTemplate(t *testing.T) {
+	apih, done := checkTemplateTestBootstrap(t)
+	defer done()
+
+	tests := []struct {
+		id          string
+		cid         string
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"empty cid", "", true, "invalid check template CID (none)"},
+		{"short cid", "1234", false, ""},
+		{"long cid", "/template/1234", false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			template, err := apih.FetchCheckTemplate(CIDType(&test.cid))
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if !reflect.DeepEqual(*template, testCheckTemplate) {
+				t.Fatalf("unexpected template (%#v)", template)
+			}
+		})
+	}
+}
+
+func TestFetchCheckTemplates(t *testing.T) {
+	apih, done := checkTemplateTestBootstrap(t)
+	defer done()
+
+	templates, err := apih.FetchCheckTemplates()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(*templates) != 1 {
+		t.Fatalf("expected 1 template, got %d", len(*templates))
+	}
+}
+
+func TestUpdateCheckTemplate(t *testing.T) {
+	apih, done := checkTemplateTestBootstrap(t)
+	defer done()
+
+	tests := []struct {
+		id          string
+		cfg         *CheckTemplate
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (nil)", nil, true, "invalid check template config (nil)"},
+		{"invalid (cid)", &CheckTemplate{CID: "/invalid"}, true, "invalid check template CID (/invalid)"},
+		{"valid", &testCheckTemplate, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			_, err := apih.UpdateCheckTemplate(test.cfg)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+		})
+	}
+}
+
+func TestCreateCheckTemplate(t *testing.T) {
+	apih, done := checkTemplateTestBootstrap(t)
+	defer done()
+
+	if _, err := apih.CreateCheckTemplate(nil); err == nil {
+		t.Fatal("expected error")
+	} else if err.Error() != "invalid check template config (nil)" {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	template, err := apih.CreateCheckTemplate(&testCheckTemplate)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if template.Name != testCheckTemplate.Name {
+		t.Fatalf("unexpected name (%s)", template.Name)
+	}
+}
+
+func TestDeleteCheckTemplate(t *testing.T) {
+	apih, done := checkTemplateTestBootstrap(t)
+	defer done()
+
+	tests := []struct {
+		id          string
+		cfg         *CheckTemplate
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (nil)", nil, true, "invalid check template config (nil)"},
+		{"valid", &testCheckTemplate, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			wasDeleted, err := apih.DeleteCheckTemplate(test.cfg)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				} else if !wasDeleted {
+					t.Fatal("expected true (deleted)")
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteCheckTemplateByCID(t *testing.T) {
+	apih, done := checkTemplateTestBootstrap(t)
+	defer done()
+
+	tests := []struct {
+		id          string
+		cid         string
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"empty cid", "", true, "invalid check template CID (none)"},
+		{"short cid", "1234", false, ""},
+		{"long cid", "/template/1234", false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			wasDeleted, err := apih.DeleteCheckTemplateByCID(CIDType(&test.cid))
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				} else if !wasDeleted {
+					t.Fatal("expected true (deleted)")
+				}
+			}
+		})
+	}
+}
+
+func TestSearchCheckTemplates(t *testing.T) {
+	apih, done := checkTemplateTestBootstrap(t)
+	defer done()
+
+	search := SearchQueryType("web")
+	filter := SearchFilterType(map[string][]string{"f_status": {"test"}})
+
+	tests := []struct {
+		id       string
+		search   *SearchQueryType
+		filter   *SearchFilterType
+		expected int
+	}{
+		{"no search, no filter", nil, nil, 1},
+		{"search no filter", &search, nil, 1},
+		{"filter no search", nil, &filter, 0},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			templates, err := apih.SearchCheckTemplates(test.search, test.filter)
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if len(*templates) != test.expected {
+				t.Fatalf("expected %d templates, got %d", test.expected, len(*templates))
+			}
+		})
+	}
+}
+
+func TestCheckTemplateHosts(t *testing.T) {
+	template := testCheckTemplate
+	template.Hosts = append([]string{}, testCheckTemplate.Hosts...)
+
+	if template.AddHost("10.0.0.2") {
+		t.Fatal("expected duplicate host to be ignored")
+	}
+	if !template.AddHost("10.0.0.3") {
+		t.Fatal("expected host to be added")
+	}
+	if _, err := template.RemoveHost("10.0.0.1"); err == nil {
+		t.Fatal("expected error removing master host")
+	}
+	if removed, err := template.RemoveHost("10.0.0.2"); err != nil || !removed {
+		t.Fatal("expected host to be removed")
+	}
+	if removed, err := template.RemoveHost("10.0.0.9"); err != nil || removed {
+		t.Fatal("expected false (not found)")
+	}
+	if !reflect.DeepEqual(template.Hosts, []string{"10.0.0.1", "10.0.0.3"}) {
+		t.Fatalf("unexpected hosts (%v)", template.Hosts)
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// CID - validation of object cids
+
+package apiclient
+
+import "strings"
+
+// validCID returns true if cid is a cid of the endpoint with the passed
+// prefix (e.g. config.GraphPrefix), the prefix followed by "/" and an
+// opaque id. It is equivalent to matching the endpoint's CIDRegex (e.g.
+// config.GraphCIDRegex), without a regular expression, as CIDs are
+// validated by every call with one.
+func validCID(prefix string, cid string) bool {
+	if len(cid) < len(prefix)+2 || cid[len(prefix)] != '/' || cid[:len(prefix)] != prefix {
+		return false
+	}
+	// the id is opaque, but is a single line
+	return !strings.Contains(cid[len(prefix)+1:], "\n")
+}
@@ -0,0 +1,80 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/circonus-labs/go-apiclient/config"
+)
+
+// cidPatterns are the endpoint prefixes and the cid regular expressions
+// validCID replaces
+var cidPatterns = []struct {
+	prefix string
+	regex  string
+}{
+	{config.AccountPrefix, config.AccountCIDRegex},
+	{config.AcknowledgementPrefix, config.AcknowledgementCIDRegex},
+	{config.AlertPrefix, config.AlertCIDRegex},
+	{config.AnnotationPrefix, config.AnnotationCIDRegex},
+	{config.BrokerPrefix, config.BrokerCIDRegex},
+	{config.CheckBundleMetricsPrefix, config.CheckBundleMetricsCIDRegex},
+	{config.CheckBundlePrefix, config.CheckBundleCIDRegex},
+	{config.CheckPrefix, config.CheckCIDRegex},
+	{config.CheckMovePrefix, config.CheckMoveCIDRegex},
+	{config.CheckTemplatePrefix, config.CheckTemplateCIDRegex},
+	{config.ContactGroupPrefix, config.ContactGroupCIDRegex},
+	{config.DashboardPrefix, config.DashboardCIDRegex},
+	{config.DataPrefix, config.DataCIDRegex},
+	{config.GraphPrefix, config.GraphCIDRegex},
+	{config.MaintenancePrefix, config.MaintenanceCIDRegex},
+	{config.MetricClusterPrefix, config.MetricClusterCIDRegex},
+	{config.MetricPrefix, config.MetricCIDRegex},
+	{config.OutlierReportPrefix, config.OutlierReportCIDRegex},
+	{config.ProvisionBrokerPrefix, config.ProvisionBrokerCIDRegex},
+	{config.RuleSetGroupPrefix, config.RuleSetGroupCIDRegex},
+	{config.RuleSetPrefix, config.RuleSetCIDRegex},
+	{config.TagPrefix, config.TagCIDRegex},
+	{config.UserPrefix, config.UserCIDRegex},
+	{config.WorksheetPrefix, config.WorksheetCIDRegex},
+}
+
+func TestValidCID(t *testing.T) {
+	ids := []string{"", "/", "1234", "current", "1234_cpu`idle", "a/b", "12 34", "1\n2", "\n", "é"}
+
+	for _, p := range cidPatterns {
+		re := regexp.MustCompile(p.regex)
+		cids := []string{"", p.prefix, p.prefix + "s/1", p.prefix[1:] + "/1", "/v2" + p.prefix + "/1", p.prefix + "\n/1"}
+		for _, id := range ids {
+			cids = append(cids, p.prefix+"/"+id)
+		}
+		for _, cid := range cids {
+			if valid, expected := validCID(p.prefix, cid), re.MatchString(cid); valid != expected {
+				t.Errorf("%s %q: validCID %t, regex %t", p.prefix, cid, valid, expected)
+			}
+		}
+	}
+}
+
+// BenchmarkValidCID compares validCID to matching the cid regular expression
+func BenchmarkValidCID(b *testing.B) {
+	cid := "/check_bundle/1234"
+
+	b.Run("regexp.MatchString", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = regexp.MatchString(config.CheckBundleCIDRegex, cid)
+		}
+	})
+
+	b.Run("validCID", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = validCID(config.CheckBundlePrefix, cid)
+		}
+	})
+}
@@ -0,0 +1,424 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/circonus-labs/go-apiclient/apisync"
+	"github.com/pkg/errors"
+)
+
+// fetchCmd fetches an object by cid
+func fetchCmd(c *cli, args []string) (int, error) {
+	fs := c.flags("fetch")
+	if err := fs.Parse(args); err != nil {
+		return exitError, nil
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError, nil
+	}
+	cid, err := objectCID(fs.Arg(0))
+	if err != nil {
+		return exitError, err
+	}
+
+	result, err := c.api.Get(cid)
+	if err != nil {
+		return exitError, err
+	}
+	return exitOK, c.output(result)
+}
+
+// searchCmd searches the objects of a type, listing all without criteria
+func searchCmd(c *cli, args []string) (int, error) {
+	var (
+		search  string
+		filters multiFlag
+	)
+	fs := c.flags("search")
+	fs.StringVar(&search, "search", "", "search query (e.g. (active:1)web)")
+	fs.Var(&filters, "filter", "filter name=value (e.g. f__tags_has=service:web), may be repeated")
+	if err := fs.Parse(args); err != nil {
+		return exitError, nil
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError, nil
+	}
+	prefix, err := typePrefix(fs.Arg(0))
+	if err != nil {
+		return exitError, err
+	}
+	criteria, err := filters.pairs()
+	if err != nil {
+		return exitError, err
+	}
+
+	q := url.Values(criteria)
+	if search != "" {
+		q.Set("search", search)
+	}
+	reqURL := url.URL{
+		Path:     prefix,
+		RawQuery: q.Encode(),
+	}
+
+	result, err := c.api.Get(reqURL.String())
+	if err != nil {
+		return exitError, err
+	}
+	return exitOK, c.output(result)
+}
+
+// createCmd creates an object of a type from a file
+func createCmd(c *cli, args []string) (int, error) {
+	var file string
+	fs := c.flags("create")
+	fs.StringVar(&file, "f", "-", "JSON or YAML file defining the object, - for stdin")
+	if err := fs.Parse(args); err != nil {
+		return exitError, nil
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError, nil
+	}
+	prefix, err := typePrefix(fs.Arg(0))
+	if err != nil {
+		return exitError, err
+	}
+	data, err := c.readInput(file)
+	if err != nil {
+		return exitError, err
+	}
+
+	result, err := c.api.Post(prefix, data)
+	if err != nil {
+		return exitError, err
+	}
+	return exitOK, c.output(result)
+}
+
+// updateCmd updates an object from a file
+func updateCmd(c *cli, args []string) (int, error) {
+	var file string
+	fs := c.flags("update")
+	fs.StringVar(&file, "f", "-", "JSON or YAML file defining the object, - for stdin")
+	if err := fs.Parse(args); err != nil {
+		return exitError, nil
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError, nil
+	}
+	cid, err := objectCID(fs.Arg(0))
+	if err != nil {
+		return exitError, err
+	}
+	data, err := c.readInput(file)
+	if err != nil {
+		return exitError, err
+	}
+
+	result, err := c.api.Put(cid, data)
+	if err != nil {
+		return exitError, err
+	}
+	return exitOK, c.output(result)
+}
+
+// deleteCmd deletes objects with BulkDelete, so objects still referenced
+// are skipped unless forced
+func deleteCmd(c *cli, args []string) (int, error) {
+	cfg := &apiclient.BulkDeleteConfig{}
+	fs := c.flags("delete")
+	fs.BoolVar(&cfg.DryRun, "dry-run", false, "report what would be deleted")
+	fs.BoolVar(&cfg.Force, "force", false, "delete objects still referenced by others")
+	fs.IntVar(&cfg.MaxDeletes, "max", 0, "refuse to delete more objects, 0 for no limit")
+	if err := fs.Parse(args); err != nil {
+		return exitError, nil
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitError, nil
+	}
+	cfg.CIDs = fs.Args()
+
+	result, err := c.api.BulkDelete(c.ctx, cfg)
+	if err != nil {
+		return exitError, err
+	}
+
+	type row struct {
+		CID    string `json:"cid"`
+		Status string `json:"status"`
+		Reason string `json:"reason,omitempty"`
+	}
+	deleted := "deleted"
+	if result.DryRun {
+		deleted = "would delete"
+	}
+	rows := []row{}
+	for _, cid := range result.Deleted {
+		rows = append(rows, row{CID: cid, Status: deleted})
+	}
+	for _, s := range result.Skipped {
+		rows = append(rows, row{CID: s.CID, Status: "skipped", Reason: s.Reason})
+	}
+	for _, f := range result.Failed {
+		rows = append(rows, row{CID: f.CID, Status: "failed", Reason: f.Err.Error()})
+	}
+	if err := c.output(rows, "cid", "status", "reason"); err != nil {
+		return exitError, err
+	}
+	if len(result.Failed) > 0 || len(result.Skipped) > 0 {
+		return exitError, nil
+	}
+	return exitOK, nil
+}
+
+// exportCmd exports the account to a directory
+func exportCmd(c *cli, args []string) (int, error) {
+	var (
+		format  string
+		kinds   string
+		secrets bool
+		workers int
+	)
+	fs := c.flags("export")
+	fs.StringVar(&format, "format", string(apisync.FormatJSON), "file format (json, yaml)")
+	fs.StringVar(&kinds, "kinds", "", "kinds of objects to export, comma separated - default all")
+	fs.BoolVar(&secrets, "include-secrets", false, "export secrets (e.g. passwords) instead of redacting them")
+	fs.IntVar(&workers, "workers", apiclient.DefaultFetchWorkers, "kinds of objects listed concurrently")
+	if err := fs.Parse(args); err != nil {
+		return exitError, nil
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError, nil
+	}
+	r, err := apisync.New(&apisync.Config{API: c.api})
+	if err != nil {
+		return exitError, err
+	}
+
+	manifest, err := r.ExportAccount(c.ctx, fs.Arg(0), &apisync.ExportOptions{
+		Format:         apisync.Format(format),
+		Kinds:          parseKinds(kinds),
+		IncludeSecrets: secrets,
+		Workers:        workers,
+	})
+	if err != nil {
+		return exitError, err
+	}
+	return exitOK, c.output(manifest, "format", "counts")
+}
+
+// importCmd imports an exported account from a directory
+func importCmd(c *cli, args []string) (int, error) {
+	var (
+		kinds       string
+		brokers     multiFlag
+		secretsFile string
+	)
+	fs := c.flags("import")
+	fs.StringVar(&kinds, "kinds", "", "kinds of objects to import, comma separated - default all")
+	fs.Var(&brokers, "broker", "map an exported broker to a broker of the account, exported=cid, may be repeated")
+	fs.StringVar(&secretsFile, "secrets", "", "JSON or YAML file listing redacted secrets, [{cid, path, value}]")
+	if err := fs.Parse(args); err != nil {
+		return exitError, nil
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError, nil
+	}
+	brokerPairs, err := brokers.pairs()
+	if err != nil {
+		return exitError, err
+	}
+
+	opts := &apisync.ImportOptions{
+		Kinds:     parseKinds(kinds),
+		BrokerMap: make(map[string]string, len(brokerPairs)),
+	}
+	for exported, cids := range brokerPairs {
+		opts.BrokerMap[exported] = cids[len(cids)-1]
+	}
+	if secretsFile != "" {
+		if opts.Secrets, err = c.readSecrets(secretsFile); err != nil {
+			return exitError, err
+		}
+	}
+
+	r, err := apisync.New(&apisync.Config{API: c.api})
+	if err != nil {
+		return exitError, err
+	}
+	result, err := r.ImportAccount(c.ctx, fs.Arg(0), opts)
+	if err != nil {
+		return exitError, err
+	}
+	return exitOK, c.output(map[string]interface{}{"cids": result.CIDs, "counts": result.Counts}, "counts")
+}
+
+// driftCmd reports the differences between the live objects and a state file
+func driftCmd(c *cli, args []string) (int, error) {
+	cfg := &apisync.Config{API: c.api}
+	fs := c.flags("drift")
+	fs.StringVar(&cfg.Tag, "tag", "", "tag marking managed objects")
+	fs.BoolVar(&cfg.Prune, "prune", false, "report managed objects (carrying tag) not in the state file")
+	if err := fs.Parse(args); err != nil {
+		return exitError, nil
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError, nil
+	}
+	state, err := apisync.LoadState(fs.Arg(0))
+	if err != nil {
+		return exitError, err
+	}
+	r, err := apisync.New(cfg)
+	if err != nil {
+		return exitError, err
+	}
+
+	report, err := r.DriftReport(c.ctx, state)
+	if err != nil {
+		return exitError, err
+	}
+
+	type row struct {
+		Status string   `json:"status"`
+		Kind   string   `json:"kind"`
+		Key    string   `json:"key"`
+		CID    string   `json:"cid,omitempty"`
+		Diffs  []string `json:"diffs,omitempty"`
+	}
+	rows := []row{}
+	for _, d := range report.Drifts {
+		dr := row{Status: string(d.Status), Kind: string(d.Kind), Key: d.Key, CID: d.CID}
+		for _, diff := range d.Diffs {
+			dr.Diffs = append(dr.Diffs, diff.String())
+		}
+		rows = append(rows, dr)
+	}
+	if err := c.output(rows, "status", "kind", "key", "cid"); err != nil {
+		return exitError, err
+	}
+	return report.ExitCode(), nil
+}
+
+// coverageCmd reports the objects and fields of the API the library does
+// not model
+func coverageCmd(c *cli, args []string) (int, error) {
+	var types string
+	cfg := &apiclient.ModelCoverageConfig{}
+	fs := c.flags("coverage")
+	fs.StringVar(&types, "types", "", "types to check, comma separated - default all")
+	fs.IntVar(&cfg.SampleSize, "sample", apiclient.DefaultCoverageSampleSize, "objects of each type to check")
+	if err := fs.Parse(args); err != nil {
+		return exitError, nil
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return exitError, nil
+	}
+	if types != "" {
+		for _, t := range strings.Split(types, ",") {
+			prefix, err := typePrefix(strings.TrimSpace(t))
+			if err != nil {
+				return exitError, err
+			}
+			cfg.Types = append(cfg.Types, prefix)
+		}
+	}
+
+	report, err := c.api.ModelCoverage(c.ctx, cfg)
+	if err != nil {
+		return exitError, err
+	}
+
+	type row struct {
+		Type    string   `json:"type"`
+		Sampled int      `json:"sampled"`
+		Covered bool     `json:"covered"`
+		Unknown []string `json:"unknown_fields,omitempty"`
+		Errors  []string `json:"errors,omitempty"`
+	}
+	rows := []row{}
+	for _, tc := range report.Types {
+		rows = append(rows, row{Type: tc.Type, Sampled: tc.Sampled, Covered: tc.Covered(), Unknown: tc.UnknownFields, Errors: tc.Errors})
+	}
+	if err := c.output(rows, "type", "sampled", "covered", "unknown_fields"); err != nil {
+		return exitError, err
+	}
+	if len(report.Gaps()) > 0 {
+		return exitGaps, nil
+	}
+	return exitOK, nil
+}
+
+// readSecrets returns a lookup of the secrets listed in a file
+func (c *cli) readSecrets(file string) (func(apisync.SecretRef) (string, bool), error) {
+	data, err := c.readInput(file)
+	if err != nil {
+		return nil, err
+	}
+	var secrets []struct {
+		apisync.SecretRef
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, errors.Wrap(err, "parsing secrets")
+	}
+
+	values := make(map[apisync.SecretRef]string, len(secrets))
+	for _, s := range secrets {
+		values[s.SecretRef] = s.Value
+	}
+	return func(ref apisync.SecretRef) (string, bool) {
+		v, ok := values[ref]
+		return v, ok
+	}, nil
+}
+
+// typePrefix returns the endpoint for a type, e.g. /check_bundle for
+// check_bundle or /check_bundle
+func typePrefix(t string) (string, error) {
+	t = strings.Trim(t, "/")
+	if t == "" || strings.Contains(t, "/") {
+		return "", errors.Errorf("invalid type (%s)", t)
+	}
+	return "/" + t, nil
+}
+
+// objectCID validates a cid, e.g. /check_bundle/1234
+func objectCID(cid string) (string, error) {
+	parts := strings.Split(strings.TrimPrefix(cid, "/"), "/")
+	if !strings.HasPrefix(cid, "/") || len(parts) < 2 || parts[0] == "" || parts[len(parts)-1] == "" {
+		return "", errors.Errorf("invalid cid (%s)", cid)
+	}
+	return cid, nil
+}
+
+// parseKinds splits a comma separated list of kinds
+func parseKinds(s string) []apisync.Kind {
+	if s == "" {
+		return nil
+	}
+	names := strings.Split(s, ",")
+	kinds := make([]apisync.Kind, 0, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			kinds = append(kinds, apisync.Kind(name))
+		}
+	}
+	return kinds
+}
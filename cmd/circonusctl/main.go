@@ -0,0 +1,234 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Circonusctl is a command line interface to the Circonus API.
+//
+// Usage:
+//
+//	circonusctl [flags] <command> [command flags] [arguments]
+//
+// Commands:
+//
+//	fetch <cid>                     fetch an object
+//	search <type>                   search objects of a type (e.g. graph)
+//	create <type>                   create an object from a JSON or YAML file
+//	update <cid>                    update an object from a JSON or YAML file
+//	delete <cid>...                 delete objects, referenced objects are skipped
+//	export <dir>                    export the account to a directory tree
+//	import <dir>                    import an exported account
+//	drift <state file>              report live objects drifting from their definitions
+//	coverage                        report API objects and fields the library does not model
+//
+// The API token and app default to the CIRCONUS_API_TOKEN and
+// CIRCONUS_API_APP environment variables, the API URL to CIRCONUS_API_URL.
+// Results are written as JSON (default), YAML, or a table (-o table).
+//
+// The exit status is 0 on success, 1 on error, and 2 if drift found any
+// drift, or coverage any gaps.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/pkg/errors"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitGaps  = 2
+)
+
+// cli holds the state shared by the commands
+type cli struct {
+	api     *apiclient.API
+	ctx     context.Context
+	format  string
+	columns []string
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+// command defines a subcommand, run returns the exit status
+type command struct {
+	usage string
+	run   func(c *cli, args []string) (int, error)
+}
+
+// commands by name, set in init as the commands refer to it for their usage
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"fetch":    {"fetch <cid>", fetchCmd},
+		"search":   {"search [-search query] [-filter name=value]... <type>", searchCmd},
+		"create":   {"create [-f file] <type>", createCmd},
+		"update":   {"update [-f file] <cid>", updateCmd},
+		"delete":   {"delete [-dry-run] [-force] [-max n] <cid>...", deleteCmd},
+		"export":   {"export [-format json|yaml] [-kinds kind,...] [-include-secrets] <dir>", exportCmd},
+		"import":   {"import [-kinds kind,...] [-broker exported=cid]... [-secrets file] <dir>", importCmd},
+		"drift":    {"drift [-tag tag] [-prune] <state file>", driftCmd},
+		"coverage": {"coverage [-types type,...] [-sample n]", coverageCmd},
+	}
+}
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		cancel()
+	}()
+
+	status := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	cancel()
+	os.Exit(status)
+}
+
+// run parses the global flags and runs the command, returning the exit status
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var (
+		apiKey   string
+		apiApp   string
+		apiURL   string
+		apiDebug bool
+		format   string
+		columns  string
+	)
+
+	fs := flag.NewFlagSet("circonusctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&apiKey, "key", os.Getenv("CIRCONUS_API_TOKEN"), "api token key (CIRCONUS_API_TOKEN)")
+	fs.StringVar(&apiApp, "app", os.Getenv("CIRCONUS_API_APP"), "api token app name (CIRCONUS_API_APP)")
+	fs.StringVar(&apiURL, "url", os.Getenv("CIRCONUS_API_URL"), "api url (CIRCONUS_API_URL)")
+	fs.BoolVar(&apiDebug, "debug", false, "turn on debug messages")
+	fs.StringVar(&format, "o", formatJSON, "output format (json, yaml, table)")
+	fs.StringVar(&columns, "columns", "", "table columns, comma separated (e.g. _cid,display_name)")
+	fs.Usage = func() { usage(fs) }
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitError
+	}
+
+	switch format {
+	case formatJSON, formatYAML, formatTable:
+	default:
+		fmt.Fprintf(stderr, "circonusctl: invalid output format (%s)\n", format)
+		return exitError
+	}
+
+	if fs.NArg() == 0 {
+		usage(fs)
+		return exitError
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "circonusctl: unknown command (%s)\n", fs.Arg(0))
+		return exitError
+	}
+
+	logger := log.New(ioutil.Discard, "", 0)
+	if apiDebug {
+		logger = log.New(stderr, "", log.LstdFlags)
+	}
+	client, err := apiclient.New(&apiclient.Config{
+		URL:      apiURL,
+		TokenKey: apiKey,
+		TokenApp: apiApp,
+		Debug:    apiDebug,
+		Log:      logger,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "circonusctl: %s\n", err)
+		return exitError
+	}
+
+	c := &cli{
+		api:    client,
+		ctx:    ctx,
+		format: format,
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}
+	if columns != "" {
+		c.columns = strings.Split(columns, ",")
+	}
+
+	status, err := cmd.run(c, fs.Args()[1:])
+	if err != nil {
+		fmt.Fprintf(stderr, "circonusctl %s: %s\n", fs.Arg(0), err)
+		return exitError
+	}
+	return status
+}
+
+// usage writes the global flags and commands
+func usage(fs *flag.FlagSet) {
+	out := fs.Output()
+	fmt.Fprintln(out, "usage: circonusctl [flags] <command> [command flags] [arguments]")
+	fmt.Fprintln(out, "\nflags:")
+	fs.PrintDefaults()
+	fmt.Fprintln(out, "\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %s\n", commands[name].usage)
+	}
+}
+
+// flags returns a flag set for a command, writing errors to stderr
+func (c *cli) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "usage: circonusctl %s\n", commands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// multiFlag collects the values of a flag which may be repeated
+type multiFlag []string
+
+func (m *multiFlag) String() string {
+	return strings.Join(*m, ",")
+}
+
+func (m *multiFlag) Set(v string) error {
+	*m = append(*m, v)
+	return nil
+}
+
+// pairs splits name=value flag values into a map
+func (m multiFlag) pairs() (map[string][]string, error) {
+	p := make(map[string][]string, len(m))
+	for _, v := range m {
+		idx := strings.Index(v, "=")
+		if idx <= 0 {
+			return nil, errors.Errorf("invalid name=value (%s)", v)
+		}
+		p[v[:idx]] = append(p[v[:idx]], v[idx+1:])
+	}
+	return p, nil
+}
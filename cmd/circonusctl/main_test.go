@@ -0,0 +1,88 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testServer() *httptest.Server {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/graph/1":
+			fmt.Fprintln(w, `{"_cid":"/graph/1","title":"Web","datapoints":[]}`)
+		case r.Method == "GET" && r.URL.Path == "/graph":
+			if r.URL.Query().Get("f_title") != "Web" {
+				fmt.Fprintln(w, `[]`)
+				return
+			}
+			fmt.Fprintln(w, `[{"_cid":"/graph/1","title":"Web"},{"_cid":"/graph/2","title":"Web"}]`)
+		case r.Method == "GET" && (r.URL.Path == "/dashboard" || r.URL.Path == "/worksheet"):
+			fmt.Fprintln(w, `[]`)
+		case r.Method == "POST" && r.URL.Path == "/contact_group":
+			b, _ := ioutil.ReadAll(r.Body)
+			fmt.Fprintln(w, string(b))
+		default:
+			w.WriteHeader(404)
+			fmt.Fprintln(w, `{"code":404,"error":"Not found","explanation":""}`)
+		}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(f))
+}
+
+func TestRun(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		id             string
+		args           []string
+		stdin          string
+		expectedStatus int
+		expectedOut    string
+		expectedErr    string
+	}{
+		{"fetch", []string{"fetch", "/graph/1"}, "", exitOK, "{\n  \"_cid\": \"/graph/1\",\n  \"datapoints\": [],\n  \"title\": \"Web\"\n}\n", ""},
+		{"fetch (yaml)", []string{"-o", "yaml", "fetch", "/graph/1"}, "", exitOK, "_cid: /graph/1\ndatapoints: []\ntitle: Web\n", ""},
+		{"search (table)", []string{"-o", "table", "search", "-filter", "f_title=Web", "graph"}, "", exitOK, "CID       TITLE\n/graph/1  Web\n/graph/2  Web\n", ""},
+		{"search (columns)", []string{"-o", "table", "-columns", "title", "search", "-filter", "f_title=Web", "/graph"}, "", exitOK, "TITLE\nWeb\nWeb\n", ""},
+		{"create (yaml)", []string{"-o", "table", "-columns", "name,reminders", "create", "contact_group"}, "name: ops\nreminders: [5, 10]\n", exitOK, "NAME  REMINDERS\nops   [5,10]\n", ""},
+		{"delete (dry run)", []string{"-o", "table", "delete", "-dry-run", "/graph/1"}, "", exitOK, "CID       STATUS        REASON\n/graph/1  would delete  -\n", ""},
+		{"coverage", []string{"-o", "table", "coverage", "-types", "graph,dashboard"}, "", exitOK, "TYPE        SAMPLED  COVERED  UNKNOWN_FIELDS\n/dashboard  0        true     -\n/graph      0        true     -\n", ""},
+		{"coverage (gaps)", []string{"-o", "table", "coverage", "-types", "rule_set"}, "", exitGaps, "TYPE       SAMPLED  COVERED  UNKNOWN_FIELDS\n/rule_set  0        false    -\n", ""},
+		{"invalid (command)", []string{"list"}, "", exitError, "", "circonusctl: unknown command (list)\n"},
+		{"invalid (format)", []string{"-o", "xml", "fetch", "/graph/1"}, "", exitError, "", "circonusctl: invalid output format (xml)\n"},
+		{"invalid (cid)", []string{"fetch", "graph"}, "", exitError, "", "circonusctl fetch: invalid cid (graph)\n"},
+		{"invalid (filter)", []string{"search", "-filter", "f_title", "graph"}, "", exitError, "", "circonusctl search: invalid name=value (f_title)\n"},
+		{"invalid (input)", []string{"create", "contact_group"}, "", exitError, "", "circonusctl create: invalid input (empty)\n"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			args := append([]string{"-key", "foo", "-app", "bar", "-url", server.URL}, test.args...)
+			status := run(context.Background(), args, strings.NewReader(test.stdin), &stdout, &stderr)
+			if status != test.expectedStatus {
+				t.Fatalf("unexpected status (%d), stderr: %s", status, stderr.String())
+			}
+			if stdout.String() != test.expectedOut {
+				t.Fatalf("unexpected output (%q)", stdout.String())
+			}
+			if stderr.String() != test.expectedErr {
+				t.Fatalf("unexpected error output (%q)", stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Output formats
+const (
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+	formatTable = "table"
+)
+
+// nameFields are the fields used, in order, for the name column of tables
+var nameFields = []string{"name", "display_name", "title", "description", "email", "metric_name"}
+
+// output writes v in the selected format. Tables have a row per object (or
+// one row for a single object), with the selected columns, or else cols.
+// When cols is empty the columns are the cid and name of the objects.
+func (c *cli) output(v interface{}, cols ...string) error {
+	data, err := toGeneric(v)
+	if err != nil {
+		return err
+	}
+
+	switch c.format {
+	case formatYAML:
+		out, err := yaml.Marshal(yamlCompatible(data))
+		if err != nil {
+			return errors.Wrap(err, "encoding yaml")
+		}
+		_, err = c.stdout.Write(out)
+		return err
+	case formatTable:
+		if len(c.columns) > 0 {
+			cols = c.columns
+		}
+		return writeTable(c.stdout, data, cols)
+	default:
+		out, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "encoding json")
+		}
+		_, err = fmt.Fprintf(c.stdout, "%s\n", out)
+		return err
+	}
+}
+
+// toGeneric converts v (raw json or a value) to maps, slices, and json.Numbers
+func toGeneric(v interface{}) (interface{}, error) {
+	raw, ok := v.([]byte)
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(v); err != nil {
+			return nil, errors.Wrap(err, "encoding result")
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var data interface{}
+	if err := dec.Decode(&data); err != nil {
+		return nil, errors.Wrap(err, "parsing result")
+	}
+	return data, nil
+}
+
+// yamlCompatible converts json.Numbers to numbers so they are not quoted
+func yamlCompatible(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		if f, err := t.Float64(); err == nil {
+			return f
+		}
+		return t.String()
+	case map[string]interface{}:
+		for k, val := range t {
+			t[k] = yamlCompatible(val)
+		}
+	case []interface{}:
+		for i, val := range t {
+			t[i] = yamlCompatible(val)
+		}
+	}
+	return v
+}
+
+// writeTable writes the objects in data as a table
+func writeTable(w io.Writer, data interface{}, cols []string) error {
+	var rows []map[string]interface{}
+	switch t := data.(type) {
+	case map[string]interface{}:
+		rows = append(rows, t)
+	case []interface{}:
+		for _, item := range t {
+			row, ok := item.(map[string]interface{})
+			if !ok {
+				return errors.New("table output requires objects, use -o json or yaml")
+			}
+			rows = append(rows, row)
+		}
+	default:
+		return errors.New("table output requires objects, use -o json or yaml")
+	}
+
+	if len(cols) == 0 {
+		cols = []string{"_cid"}
+		if len(rows) > 0 {
+			for _, f := range nameFields {
+				if _, ok := rows[0][f]; ok {
+					cols = append(cols, f)
+					break
+				}
+			}
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	header := make([]string, len(cols))
+	for i, col := range cols {
+		header[i] = strings.ToUpper(strings.TrimPrefix(col, "_"))
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		vals := make([]string, len(cols))
+		for i, col := range cols {
+			vals[i] = tableValue(row[col])
+		}
+		fmt.Fprintln(tw, strings.Join(vals, "\t"))
+	}
+	return tw.Flush()
+}
+
+// tableValue returns a single line representation of a value
+func tableValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "-"
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(out)
+}
+
+// readInput reads a JSON or YAML document from file ("-" for stdin) and
+// returns it as JSON
+func (c *cli) readInput(file string) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if file == "-" {
+		data, err = ioutil.ReadAll(c.stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "reading input")
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, errors.New("invalid input (empty)")
+	}
+
+	// json is valid yaml, pass it through as is so numbers are not reformatted
+	if json.Valid(data) {
+		return data, nil
+	}
+
+	var v interface{}
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, errors.Wrap(err, "parsing input")
+	}
+	out, err := json.Marshal(jsonCompatible(v))
+	if err != nil {
+		return nil, errors.Wrap(err, "converting input")
+	}
+	return out, nil
+}
+
+// jsonCompatible converts the map[interface{}]interface{} values produced
+// by yaml to map[string]interface{}
+func jsonCompatible(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			m[fmt.Sprintf("%v", k)] = jsonCompatible(val)
+		}
+		return m
+	case []interface{}:
+		for i, val := range t {
+			t[i] = jsonCompatible(val)
+		}
+	}
+	return v
+}
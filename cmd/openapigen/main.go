@@ -0,0 +1,44 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Openapigen writes the OpenAPI document of the endpoints the client
+// supports (see package openapi).
+//
+// Usage:
+//
+//	openapigen [-o file]
+//
+// The document is written to stdout unless -o is set.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/circonus-labs/go-apiclient/openapi"
+)
+
+func main() {
+	out := flag.String("o", "", "output file (default stdout)")
+	flag.Parse()
+
+	data, err := json.MarshalIndent(openapi.Generate(), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "openapigen: %s\n", err)
+		os.Exit(1)
+	}
+	data = append(data, '\n')
+
+	if *out == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := ioutil.WriteFile(*out, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "openapigen: %s\n", err)
+		os.Exit(1)
+	}
+}
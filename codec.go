@@ -0,0 +1,84 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Codec - encoding of the objects sent to, and decoding of the responses of, the API
+
+package apiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// Codec encodes the objects sent to the API and decodes its responses (see
+// Config Codec), e.g. to use another JSON implementation, or to validate
+// objects against a schema. Its methods are called from the goroutines
+// making calls and must be safe for concurrent use.
+type Codec interface {
+	// Encode writes the encoding of v to w
+	Encode(w io.Writer, v interface{}) error
+
+	// Decode reads a response from r into v, r may be the response body
+	// (read as it is received) or a response already read - unless the
+	// Codec is an Unmarshaler
+	Decode(r io.Reader, v interface{}) error
+}
+
+// Unmarshaler is implemented by Codecs decoding responses already read
+// other than through Decode (e.g. rejecting data after the value, as
+// json.Unmarshal does)
+type Unmarshaler interface {
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec is the default Codec, encoding/json
+type JSONCodec struct{}
+
+// Encode writes the json encoding of v to w, as json.Marshal does.
+func (JSONCodec) Encode(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// Decode decodes the json value read from r into v.
+func (JSONCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// Unmarshal decodes the json value in data into v, as json.Unmarshal does.
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// marshalJSON encodes v with the API's codec. The buffer is not pooled, the
+// bytes of a request body may be read by the transport after the call
+// returns.
+func (a *API) marshalJSON(v interface{}) (*bytes.Buffer, error) {
+	buf := new(bytes.Buffer)
+	if err := a.apiCodec().Encode(buf, v); err != nil {
+		return nil, err
+	}
+	// json.Encoder appends a newline
+	if n := buf.Len(); n > 0 && buf.Bytes()[n-1] == '\n' {
+		buf.Truncate(n - 1)
+	}
+	return buf, nil
+}
+
+// unmarshalJSON decodes a response already read into v with the API's codec
+func (a *API) unmarshalJSON(data []byte, v interface{}) error {
+	codec := a.apiCodec()
+	if u, ok := codec.(Unmarshaler); ok {
+		return u.Unmarshal(data, v)
+	}
+	return codec.Decode(bytes.NewReader(data), v)
+}
+
+// apiCodec returns the API's codec, JSONCodec if none is set
+func (a *API) apiCodec() Codec {
+	if a.codec == nil {
+		return JSONCodec{}
+	}
+	return a.codec
+}
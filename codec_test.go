@@ -0,0 +1,88 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
+	"github.com/pkg/errors"
+)
+
+// testCodec counts the values it encodes and decodes, and rejects graphs
+// without a title
+type testCodec struct {
+	json             JSONCodec
+	encoded, decoded int32
+}
+
+func (c *testCodec) Encode(w io.Writer, v interface{}) error {
+	atomic.AddInt32(&c.encoded, 1)
+	if g, ok := v.(*Graph); ok && g.Title == "" {
+		return errors.New("graph title required")
+	}
+	return c.json.Encode(w, v)
+}
+
+func (c *testCodec) Decode(r io.Reader, v interface{}) error {
+	atomic.AddInt32(&c.decoded, 1)
+	return c.json.Decode(r, v)
+}
+
+func TestCodec(t *testing.T) {
+	fake, err := fakecirconus.New(&fakecirconus.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+
+	codec := &testCodec{}
+	apih, err := New(&Config{TokenKey: "abc123", URL: fake.URL, Codec: codec})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	g, err := apih.CreateGraph(&Graph{Title: "web"})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if _, err := apih.FetchGraph(CIDType(&g.CID)); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	graphs, err := apih.FetchGraphs()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(*graphs) != 1 || (*graphs)[0].Title != "web" {
+		t.Fatalf("unexpected graphs (%v)", *graphs)
+	}
+	if e, d := atomic.LoadInt32(&codec.encoded), atomic.LoadInt32(&codec.decoded); e != 1 || d != 3 {
+		t.Fatalf("expected 1 encoded and 3 decoded, got %d and %d", e, d)
+	}
+
+	if _, err := apih.CreateGraph(&Graph{}); err == nil || !strings.Contains(err.Error(), "graph title required") {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+	for _, r := range fake.Requests() {
+		if r.Method == "POST" && !strings.Contains(string(r.Body), "web") {
+			t.Fatalf("unexpected request (%s)", r.Body)
+		}
+	}
+
+	// copies share the codec
+	acct, err := apih.WithAccount("/account/2")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if _, err := acct.FetchGraph(CIDType(&g.CID)); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if d := atomic.LoadInt32(&codec.decoded); d != 4 {
+		t.Fatalf("expected 4 decoded, got %d", d)
+	}
+}
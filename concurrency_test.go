@@ -0,0 +1,235 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
+)
+
+// TestConcurrentUse exercises a single API (and an account copy) from many
+// goroutines, run with -race to detect unsynchronized shared state
+func TestConcurrentUse(t *testing.T) {
+	fake, err := fakecirconus.New(&fakecirconus.Config{
+		Fixtures: map[string]interface{}{
+			"/check_bundle/1":         CheckBundle{CID: "/check_bundle/1", DisplayName: "web", Tags: []string{"service:web"}},
+			"/check_bundle_metrics/1": CheckBundleMetrics{CID: "/check_bundle_metrics/1", Metrics: []CheckBundleMetric{{Name: "duration", Type: "numeric", Status: "active"}}},
+			"/dashboard/1":            Dashboard{CID: "/dashboard/1", Title: "web", Widgets: []DashboardWidget{{Name: "Graph", Type: "graph", WidgetID: "w1"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: fake.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	acct, err := apih.WithAccount("/account/2")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	bundles, err := apih.FetchLazyCheckBundles()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	bundle := (*bundles)[0]
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*10)
+	for w := 0; w < workers; w++ {
+		w := w
+		api := apih
+		if w%2 == 1 {
+			api = acct
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fail := func(op string, err error) {
+				errs <- fmt.Errorf("worker %d %s: %s", w, op, err)
+			}
+
+			if w%3 == 0 {
+				api.EnableExponentialBackoff()
+			} else {
+				api.DisableExponentialBackoff()
+			}
+
+			g, err := api.CreateGraph(&Graph{Title: fmt.Sprintf("graph %d", w), Tags: []string{"worker"}})
+			if err != nil {
+				fail("create graph", err)
+				return
+			}
+			g.Description = "updated"
+			if _, err := api.UpdateGraph(g); err != nil {
+				fail("update graph", err)
+			}
+			if _, err := api.RefreshIfChanged(g); err != nil {
+				fail("refresh graph", err)
+			}
+			if _, err := api.FetchGraph(CIDType(&g.CID)); err != nil {
+				fail("fetch graph", err)
+			}
+			search := SearchFilterType{"f_tags_has": {"worker"}}
+			if _, err := api.SearchGraphs(nil, &search); err != nil {
+				fail("search graphs", err)
+			}
+
+			d, err := api.FetchDashboard(CIDType(&[]string{"/dashboard/1"}[0]))
+			if err != nil {
+				fail("fetch dashboard", err)
+			} else if _, err := api.UpdateDashboard(d); err != nil {
+				fail("update dashboard", err)
+			}
+
+			if _, err := api.FetchAll(context.Background(), []string{"/check_bundle/1", "/dashboard/1"}, nil); err != nil {
+				fail("fetch all", err)
+			}
+			// the lazy bundle is shared by every worker
+			if _, err := bundle.Metrics(); err != nil {
+				fail("bundle metrics", err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	graphs, err := apih.FetchGraphs()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(*graphs) != workers {
+		t.Fatalf("unexpected graphs (%d)", len(*graphs))
+	}
+}
+
+// earlyTransport replies to each request before reading its body, which it
+// reads after RoundTrip returns (as a transport may, e.g. http2), checking
+// the description received is the graph's title repeated
+type earlyTransport struct {
+	wg      sync.WaitGroup
+	corrupt int32
+}
+
+func (t *earlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		defer req.Body.Close() // nolint: errcheck
+		time.Sleep(time.Millisecond)
+		var g Graph
+		body, _ := ioutil.ReadAll(req.Body)
+		if json.Unmarshal(body, &g) != nil || g.Description != strings.Repeat(g.Title, 1024) {
+			atomic.AddInt32(&t.corrupt, 1)
+		}
+	}()
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(`{"_cid":"/graph/1"}`)),
+		Request:    req,
+	}, nil
+}
+
+// TestConcurrentRequestBodies creates graphs from many goroutines with a
+// transport reading the request bodies after the calls return. Run with -race
+// to detect request bodies reused (e.g. pooled) while being sent.
+func TestConcurrentRequestBodies(t *testing.T) {
+	transport := &earlyTransport{}
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: "http://127.0.0.1", Transport: transport})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*20)
+	for w := 0; w < workers; w++ {
+		w := w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				title := fmt.Sprintf("w%d-%d.", w, i)
+				if _, err := apih.CreateGraph(&Graph{Title: title, Description: strings.Repeat(title, 1024)}); err != nil {
+					errs <- fmt.Errorf("worker %d create: %s", w, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	transport.wg.Wait()
+	if n := atomic.LoadInt32(&transport.corrupt); n > 0 {
+		t.Fatalf("request bodies changed while sent (%d)", n)
+	}
+}
+
+// TestConcurrentDebug enables debug messages, and sets the Log, while the
+// API is in use, run with -race to detect unsynchronized reads of them
+func TestConcurrentDebug(t *testing.T) {
+	fake, err := fakecirconus.New(&fakecirconus.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: fake.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				if _, err := apih.FetchGraphs(); err != nil {
+					t.Errorf("unexpected error (%s)", err)
+				}
+			}
+		}()
+	}
+	var logged int32
+	for i := 0; i < 10; i++ {
+		apih.SetDebug(i%2 == 0)
+		apih.SetLog(logFunc(func(format string, v ...interface{}) { atomic.AddInt32(&logged, 1) }))
+	}
+	wg.Wait()
+
+	// the deprecated fields are not read
+	apih.Debug = false
+	apih.SetDebug(true)
+	atomic.StoreInt32(&logged, 0)
+	if _, err := apih.FetchGraphs(); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if atomic.LoadInt32(&logged) == 0 {
+		t.Fatal("expected debug messages logged")
+	}
+}
@@ -510,16 +510,23 @@ const (
 	AnnotationCIDRegex         = "^(" + AnnotationPrefix + "/(" + OpaqueCIDRegex + "))$"
 	BrokerPrefix               = "/broker"
 	BrokerCIDRegex             = "^(" + BrokerPrefix + "/(" + OpaqueCIDRegex + "))$"
+	CAQLPrefix                 = "/caql"
 	CheckBundleMetricsPrefix   = "/check_bundle_metrics"
 	CheckBundleMetricsCIDRegex = "^(" + CheckBundleMetricsPrefix + "/(" + OpaqueCIDRegex + "))$"
 	CheckBundlePrefix          = "/check_bundle"
 	CheckBundleCIDRegex        = "^(" + CheckBundlePrefix + "/(" + OpaqueCIDRegex + "))$"
 	CheckPrefix                = "/check"
 	CheckCIDRegex              = "^(" + CheckPrefix + "/(" + OpaqueCIDRegex + "))$"
+	CheckMovePrefix            = "/check_move"
+	CheckMoveCIDRegex          = "^(" + CheckMovePrefix + "/(" + OpaqueCIDRegex + "))$"
+	CheckTemplatePrefix        = "/template"
+	CheckTemplateCIDRegex      = "^(" + CheckTemplatePrefix + "/(" + OpaqueCIDRegex + "))$"
 	ContactGroupPrefix         = "/contact_group"
 	ContactGroupCIDRegex       = "^(" + ContactGroupPrefix + "/(" + OpaqueCIDRegex + "))$"
 	DashboardPrefix            = "/dashboard"
 	DashboardCIDRegex          = "^(" + DashboardPrefix + "/(" + OpaqueCIDRegex + "))$"
+	DataPrefix                 = "/data"
+	DataCIDRegex               = "^(" + DataPrefix + "/(" + OpaqueCIDRegex + "))$"
 	GraphPrefix                = "/graph"
 	GraphCIDRegex              = "^(" + GraphPrefix + "/(" + OpaqueCIDRegex + "))$"
 	MaintenancePrefix          = "/maintenance"
@@ -536,6 +543,8 @@ const (
 	RuleSetGroupCIDRegex       = "^(" + RuleSetGroupPrefix + "/(" + OpaqueCIDRegex + "))$"
 	RuleSetPrefix              = "/rule_set"
 	RuleSetCIDRegex            = "^(" + RuleSetPrefix + "/(" + OpaqueCIDRegex + "))$"
+	TagPrefix                  = "/tag"
+	TagCIDRegex                = "^(" + TagPrefix + "/(" + OpaqueCIDRegex + "))$"
 	UserPrefix                 = "/user"
 	UserCIDRegex               = "^(" + UserPrefix + "/(" + OpaqueCIDRegex + "))$"
 	WorksheetPrefix            = "/worksheet"
@@ -565,3 +574,12 @@ const (
 	// UserCIDRegex               = "^(" + UserPrefix + "/(" + DefaultCIDRegex + "|current))$"
 	// WorksheetCIDRegex          = "^(" + WorksheetPrefix + "/(" + DefaultUUIDRegex + "))$"
 )
+
+// BareIDFields are the fields of objects holding the id of a referenced
+// object without its cid prefix, mapped to the prefix
+var BareIDFields = map[string]string{
+	"_check_id":  CheckPrefix,
+	"check_id":   CheckPrefix,
+	"cluster_id": MetricClusterPrefix,
+	"graph_id":   GraphPrefix,
+}
@@ -8,10 +8,8 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
-	"regexp"
 	"strings"
 
 	"github.com/circonus-labs/go-apiclient/config"
@@ -82,6 +80,12 @@ func NewContactGroup() *ContactGroup {
 
 // FetchContactGroup retrieves contact group with passed cid.
 func (a *API) FetchContactGroup(cid CIDType) (*ContactGroup, error) {
+	return a.FetchContactGroupWithContext(context.Background(), cid)
+}
+
+// FetchContactGroupWithContext retrieves contact group with passed cid,
+// stopping when ctx is done.
+func (a *API) FetchContactGroupWithContext(ctx context.Context, cid CIDType) (*ContactGroup, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.New("invalid contact group CID (none)")
 	}
@@ -93,25 +97,21 @@ func (a *API) FetchContactGroup(cid CIDType) (*ContactGroup, error) {
 		groupCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.ContactGroupCIDRegex, groupCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.ContactGroupPrefix, groupCID) {
 		return nil, errors.Errorf("invalid contact group CID (%s)", groupCID)
 	}
 
-	result, err := a.Get(groupCID)
+	result, err := a.GetWithContext(ctx, groupCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching contact group")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch contact group, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch contact group, received JSON: %s", string(result))
 	}
 
 	group := new(ContactGroup)
-	if err := json.Unmarshal(result, group); err != nil {
+	if err := a.unmarshalJSON(result, group); err != nil {
 		return nil, errors.Wrap(err, "parsing contact group")
 	}
 
@@ -120,14 +120,15 @@ func (a *API) FetchContactGroup(cid CIDType) (*ContactGroup, error) {
 
 // FetchContactGroups retrieves all contact groups available to the API Token.
 func (a *API) FetchContactGroups() (*[]ContactGroup, error) {
-	result, err := a.Get(config.ContactGroupPrefix)
-	if err != nil {
-		return nil, errors.Wrap(err, "fetching contact groups")
-	}
+	return a.FetchContactGroupsWithContext(context.Background())
+}
 
+// FetchContactGroupsWithContext retrieves all contact groups available to the
+// API Token, stopping when ctx is done.
+func (a *API) FetchContactGroupsWithContext(ctx context.Context) (*[]ContactGroup, error) {
 	var groups []ContactGroup
-	if err := json.Unmarshal(result, &groups); err != nil {
-		return nil, errors.Wrap(err, "parsing contact groups")
+	if err := a.getJSONContext(ctx, config.ContactGroupPrefix, &groups); err != nil {
+		return nil, errors.Wrap(err, "fetching contact groups")
 	}
 
 	return &groups, nil
@@ -135,36 +136,38 @@ func (a *API) FetchContactGroups() (*[]ContactGroup, error) {
 
 // UpdateContactGroup updates passed contact group.
 func (a *API) UpdateContactGroup(cfg *ContactGroup) (*ContactGroup, error) {
+	return a.UpdateContactGroupWithContext(context.Background(), cfg)
+}
+
+// UpdateContactGroupWithContext updates passed contact group, stopping when
+// ctx is done.
+func (a *API) UpdateContactGroupWithContext(ctx context.Context, cfg *ContactGroup) (*ContactGroup, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid contact group config (nil)")
 	}
 
 	groupCID := cfg.CID
 
-	matched, err := regexp.MatchString(config.ContactGroupCIDRegex, groupCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.ContactGroupPrefix, groupCID) {
 		return nil, errors.Errorf("invalid contact group CID (%s)", groupCID)
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("update contact group, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("update contact group, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Put(groupCID, jsonCfg)
+	result, err := a.PutWithContext(ctx, groupCID, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "updating contact group")
 	}
 
 	group := &ContactGroup{}
-	if err := json.Unmarshal(result, group); err != nil {
+	if err := a.unmarshalJSON(result, group); err != nil {
 		return nil, errors.Wrap(err, "parsing contact group")
 	}
 
@@ -173,26 +176,32 @@ func (a *API) UpdateContactGroup(cfg *ContactGroup) (*ContactGroup, error) {
 
 // CreateContactGroup creates a new contact group.
 func (a *API) CreateContactGroup(cfg *ContactGroup) (*ContactGroup, error) {
+	return a.CreateContactGroupWithContext(context.Background(), cfg)
+}
+
+// CreateContactGroupWithContext creates a new contact group, stopping when
+// ctx is done.
+func (a *API) CreateContactGroupWithContext(ctx context.Context, cfg *ContactGroup) (*ContactGroup, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid contact group config (nil)")
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("create contact group, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("create contact group, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Post(config.ContactGroupPrefix, jsonCfg)
+	result, err := a.PostWithContext(ctx, config.ContactGroupPrefix, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "creating contact group")
 	}
 
 	group := &ContactGroup{}
-	if err := json.Unmarshal(result, group); err != nil {
+	if err := a.unmarshalJSON(result, group); err != nil {
 		return nil, errors.Wrap(err, "parsing contact group")
 	}
 
@@ -201,14 +210,26 @@ func (a *API) CreateContactGroup(cfg *ContactGroup) (*ContactGroup, error) {
 
 // DeleteContactGroup deletes passed contact group.
 func (a *API) DeleteContactGroup(cfg *ContactGroup) (bool, error) {
+	return a.DeleteContactGroupWithContext(context.Background(), cfg)
+}
+
+// DeleteContactGroupWithContext deletes passed contact group, stopping when
+// ctx is done.
+func (a *API) DeleteContactGroupWithContext(ctx context.Context, cfg *ContactGroup) (bool, error) {
 	if cfg == nil {
 		return false, errors.New("invalid contact group config (nil)")
 	}
-	return a.DeleteContactGroupByCID(CIDType(&cfg.CID))
+	return a.DeleteContactGroupByCIDWithContext(ctx, CIDType(&cfg.CID))
 }
 
 // DeleteContactGroupByCID deletes contact group with passed cid.
 func (a *API) DeleteContactGroupByCID(cid CIDType) (bool, error) {
+	return a.DeleteContactGroupByCIDWithContext(context.Background(), cid)
+}
+
+// DeleteContactGroupByCIDWithContext deletes contact group with passed cid,
+// stopping when ctx is done.
+func (a *API) DeleteContactGroupByCIDWithContext(ctx context.Context, cid CIDType) (bool, error) {
 	if cid == nil || *cid == "" {
 		return false, errors.New("invalid contact group CID (none)")
 	}
@@ -220,15 +241,11 @@ func (a *API) DeleteContactGroupByCID(cid CIDType) (bool, error) {
 		groupCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.ContactGroupCIDRegex, groupCID)
-	if err != nil {
-		return false, err
-	}
-	if !matched {
+	if !validCID(config.ContactGroupPrefix, groupCID) {
 		return false, errors.Errorf("invalid contact group CID (%s)", groupCID)
 	}
 
-	_, err = a.Delete(groupCID)
+	_, err := a.DeleteWithContext(ctx, groupCID)
 	if err != nil {
 		return false, errors.Wrap(err, "deleting contact group")
 	}
@@ -240,37 +257,19 @@ func (a *API) DeleteContactGroupByCID(cid CIDType) (bool, error) {
 // search query and/or filter. If nil is passed for both parameters
 // all contact groups will be returned.
 func (a *API) SearchContactGroups(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]ContactGroup, error) {
-	q := url.Values{}
-
-	if searchCriteria != nil && *searchCriteria != "" {
-		q.Set("search", string(*searchCriteria))
-	}
-
-	if filterCriteria != nil && len(*filterCriteria) > 0 {
-		for filter, criteria := range *filterCriteria {
-			for _, val := range criteria {
-				q.Add(filter, val)
-			}
-		}
-	}
-
-	if q.Encode() == "" {
-		return a.FetchContactGroups()
-	}
-
-	reqURL := url.URL{
-		Path:     config.ContactGroupPrefix,
-		RawQuery: q.Encode(),
-	}
+	return a.SearchContactGroupsWithContext(context.Background(), searchCriteria, filterCriteria)
+}
 
-	result, err := a.Get(reqURL.String())
-	if err != nil {
-		return nil, errors.Wrap(err, "searching contact groups")
+// SearchContactGroupsWithContext returns contact groups matching the
+// specified search query and/or filter, stopping when ctx is done.
+func (a *API) SearchContactGroupsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]ContactGroup, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchContactGroupsWithContext(ctx)
 	}
 
 	var groups []ContactGroup
-	if err := json.Unmarshal(result, &groups); err != nil {
-		return nil, errors.Wrap(err, "parsing contact groups")
+	if err := a.searchJSONContext(ctx, config.ContactGroupPrefix, searchCriteria, filterCriteria, &groups); err != nil {
+		return nil, errors.Wrap(err, "searching contact groups")
 	}
 
 	return &groups, nil
@@ -8,10 +8,8 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
-	"regexp"
 	"strings"
 
 	"github.com/circonus-labs/go-apiclient/config"
@@ -217,6 +215,12 @@ func NewDashboard() *Dashboard {
 
 // FetchDashboard retrieves dashboard with passed cid.
 func (a *API) FetchDashboard(cid CIDType) (*Dashboard, error) {
+	return a.FetchDashboardWithContext(context.Background(), cid)
+}
+
+// FetchDashboardWithContext retrieves dashboard with passed cid, stopping
+// when ctx is done.
+func (a *API) FetchDashboardWithContext(ctx context.Context, cid CIDType) (*Dashboard, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.New("invalid dashboard CID (none)")
 	}
@@ -228,25 +232,21 @@ func (a *API) FetchDashboard(cid CIDType) (*Dashboard, error) {
 		dashboardCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.DashboardCIDRegex, dashboardCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.DashboardPrefix, dashboardCID) {
 		return nil, errors.Errorf("invalid dashboard CID (%s)", dashboardCID)
 	}
 
-	result, err := a.Get(dashboardCID)
+	result, err := a.GetWithContext(ctx, dashboardCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching dashobard")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch dashboard, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch dashboard, received JSON: %s", string(result))
 	}
 
 	dashboard := new(Dashboard)
-	if err := json.Unmarshal(result, dashboard); err != nil {
+	if err := a.unmarshalJSON(result, dashboard); err != nil {
 		return nil, errors.Wrap(err, "parsing dashboard")
 	}
 
@@ -255,14 +255,15 @@ func (a *API) FetchDashboard(cid CIDType) (*Dashboard, error) {
 
 // FetchDashboards retrieves all dashboards available to the API Token.
 func (a *API) FetchDashboards() (*[]Dashboard, error) {
-	result, err := a.Get(config.DashboardPrefix)
-	if err != nil {
-		return nil, errors.Wrap(err, "fetching dashboards")
-	}
+	return a.FetchDashboardsWithContext(context.Background())
+}
 
+// FetchDashboardsWithContext retrieves all dashboards available to the API
+// Token, stopping when ctx is done.
+func (a *API) FetchDashboardsWithContext(ctx context.Context) (*[]Dashboard, error) {
 	var dashboards []Dashboard
-	if err := json.Unmarshal(result, &dashboards); err != nil {
-		return nil, errors.Wrap(err, "parsing dashboards")
+	if err := a.getJSONContext(ctx, config.DashboardPrefix, &dashboards); err != nil {
+		return nil, errors.Wrap(err, "fetching dashboards")
 	}
 
 	return &dashboards, nil
@@ -270,36 +271,38 @@ func (a *API) FetchDashboards() (*[]Dashboard, error) {
 
 // UpdateDashboard updates passed dashboard.
 func (a *API) UpdateDashboard(cfg *Dashboard) (*Dashboard, error) {
+	return a.UpdateDashboardWithContext(context.Background(), cfg)
+}
+
+// UpdateDashboardWithContext updates passed dashboard, stopping when ctx is
+// done.
+func (a *API) UpdateDashboardWithContext(ctx context.Context, cfg *Dashboard) (*Dashboard, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid dashboard config (nil)")
 	}
 
 	dashboardCID := cfg.CID
 
-	matched, err := regexp.MatchString(config.DashboardCIDRegex, dashboardCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.DashboardPrefix, dashboardCID) {
 		return nil, errors.Errorf("invalid dashboard CID (%s)", dashboardCID)
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("update dashboard, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("update dashboard, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Put(dashboardCID, jsonCfg)
+	result, err := a.PutWithContext(ctx, dashboardCID, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "updating dashobard")
 	}
 
 	dashboard := &Dashboard{}
-	if err := json.Unmarshal(result, dashboard); err != nil {
+	if err := a.unmarshalJSON(result, dashboard); err != nil {
 		return nil, errors.Wrap(err, "parsing dashboard")
 	}
 
@@ -308,26 +311,32 @@ func (a *API) UpdateDashboard(cfg *Dashboard) (*Dashboard, error) {
 
 // CreateDashboard creates a new dashboard.
 func (a *API) CreateDashboard(cfg *Dashboard) (*Dashboard, error) {
+	return a.CreateDashboardWithContext(context.Background(), cfg)
+}
+
+// CreateDashboardWithContext creates a new dashboard, stopping when ctx is
+// done.
+func (a *API) CreateDashboardWithContext(ctx context.Context, cfg *Dashboard) (*Dashboard, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid dashboard config (nil)")
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("create dashboard, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("create dashboard, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Post(config.DashboardPrefix, jsonCfg)
+	result, err := a.PostWithContext(ctx, config.DashboardPrefix, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "creating dashboard")
 	}
 
 	dashboard := &Dashboard{}
-	if err := json.Unmarshal(result, dashboard); err != nil {
+	if err := a.unmarshalJSON(result, dashboard); err != nil {
 		return nil, errors.Wrap(err, "parsing dashboard")
 	}
 
@@ -336,14 +345,26 @@ func (a *API) CreateDashboard(cfg *Dashboard) (*Dashboard, error) {
 
 // DeleteDashboard deletes passed dashboard.
 func (a *API) DeleteDashboard(cfg *Dashboard) (bool, error) {
+	return a.DeleteDashboardWithContext(context.Background(), cfg)
+}
+
+// DeleteDashboardWithContext deletes passed dashboard, stopping when ctx is
+// done.
+func (a *API) DeleteDashboardWithContext(ctx context.Context, cfg *Dashboard) (bool, error) {
 	if cfg == nil {
 		return false, errors.New("invalid dashboard config (nil)")
 	}
-	return a.DeleteDashboardByCID(CIDType(&cfg.CID))
+	return a.DeleteDashboardByCIDWithContext(ctx, CIDType(&cfg.CID))
 }
 
 // DeleteDashboardByCID deletes dashboard with passed cid.
 func (a *API) DeleteDashboardByCID(cid CIDType) (bool, error) {
+	return a.DeleteDashboardByCIDWithContext(context.Background(), cid)
+}
+
+// DeleteDashboardByCIDWithContext deletes dashboard with passed cid, stopping
+// when ctx is done.
+func (a *API) DeleteDashboardByCIDWithContext(ctx context.Context, cid CIDType) (bool, error) {
 	if cid == nil || *cid == "" {
 		return false, errors.New("invalid dashboard CID (none)")
 	}
@@ -355,15 +376,11 @@ func (a *API) DeleteDashboardByCID(cid CIDType) (bool, error) {
 		dashboardCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.DashboardCIDRegex, dashboardCID)
-	if err != nil {
-		return false, err
-	}
-	if !matched {
+	if !validCID(config.DashboardPrefix, dashboardCID) {
 		return false, errors.Errorf("invalid dashboard CID (%s)", dashboardCID)
 	}
 
-	_, err = a.Delete(dashboardCID)
+	_, err := a.DeleteWithContext(ctx, dashboardCID)
 	if err != nil {
 		return false, errors.Wrap(err, "deleting dashboard")
 	}
@@ -375,37 +392,19 @@ func (a *API) DeleteDashboardByCID(cid CIDType) (bool, error) {
 // search query and/or filter. If nil is passed for both parameters
 // all dashboards will be returned.
 func (a *API) SearchDashboards(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Dashboard, error) {
-	q := url.Values{}
-
-	if searchCriteria != nil && *searchCriteria != "" {
-		q.Set("search", string(*searchCriteria))
-	}
-
-	if filterCriteria != nil && len(*filterCriteria) > 0 {
-		for filter, criteria := range *filterCriteria {
-			for _, val := range criteria {
-				q.Add(filter, val)
-			}
-		}
-	}
-
-	if q.Encode() == "" {
-		return a.FetchDashboards()
-	}
-
-	reqURL := url.URL{
-		Path:     config.DashboardPrefix,
-		RawQuery: q.Encode(),
-	}
+	return a.SearchDashboardsWithContext(context.Background(), searchCriteria, filterCriteria)
+}
 
-	result, err := a.Get(reqURL.String())
-	if err != nil {
-		return nil, errors.Wrap(err, "searching dashboards")
+// SearchDashboardsWithContext returns dashboards matching the specified
+// search query and/or filter, stopping when ctx is done.
+func (a *API) SearchDashboardsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Dashboard, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchDashboardsWithContext(ctx)
 	}
 
 	var dashboards []Dashboard
-	if err := json.Unmarshal(result, &dashboards); err != nil {
-		return nil, errors.Wrap(err, "parsing dashobards")
+	if err := a.searchJSONContext(ctx, config.DashboardPrefix, searchCriteria, filterCriteria, &dashboards); err != nil {
+		return nil, errors.Wrap(err, "searching dashboards")
 	}
 
 	return &dashboards, nil
@@ -0,0 +1,449 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Data API support - Fetch
+// See: https://login.circonus.com/resources/api/calls/data
+// Note: data is read-only, it is submitted via checks
+
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// DataValues defines the rolled up numeric values for a period. Values are nil
+// if no data was collected during the period.
+type DataValues struct {
+	Count            *uint64  `json:"count"`             // uint or null
+	Counter          *float64 `json:"counter"`           // number or null
+	CounterStddev    *float64 `json:"counter_stddev"`    // number or null
+	Derivative       *float64 `json:"derivative"`        // number or null
+	DerivativeStddev *float64 `json:"derivative_stddev"` // number or null
+	Stddev           *float64 `json:"stddev"`            // number or null
+	Value            *float64 `json:"value"`             // number or null
+}
+
+// DataPoint defines the values for a single period of a data series
+type DataPoint struct {
+	Timestamp time.Time
+	DataValues
+}
+
+// UnmarshalJSON decodes a data point, encoded as [timestamp, {values}] by the API.
+func (dp *DataPoint) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return errors.Wrap(err, "parsing data point")
+	}
+	if len(raw) != 2 {
+		return errors.Errorf("invalid data point, expected [timestamp, values] (%s)", string(b))
+	}
+
+	var ts float64
+	if err := json.Unmarshal(raw[0], &ts); err != nil {
+		return errors.Wrap(err, "parsing data point timestamp")
+	}
+	dp.Timestamp = time.Unix(int64(ts), 0).UTC()
+
+	dp.DataValues = DataValues{}
+	if err := json.Unmarshal(raw[1], &dp.DataValues); err != nil {
+		return errors.Wrap(err, "parsing data point values")
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes a data point as [timestamp, {values}].
+func (dp DataPoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{dp.Timestamp.Unix(), dp.DataValues})
+}
+
+// Get returns the rolled up value of the point for the passed function, nil
+// if there is none.
+func (dv DataValues) Get(function DataFunction) *float64 {
+	switch function {
+	case DataFunctionCount:
+		if dv.Count == nil {
+			return nil
+		}
+		v := float64(*dv.Count)
+		return &v
+	case DataFunctionStddev:
+		return dv.Stddev
+	case DataFunctionDerivative:
+		return dv.Derivative
+	case DataFunctionDerivativeStddev:
+		return dv.DerivativeStddev
+	case DataFunctionCounter:
+		return dv.Counter
+	case DataFunctionCounterStddev:
+		return dv.CounterStddev
+	default:
+		return dv.Value
+	}
+}
+
+// Data defines a series of stored numeric data for a check metric.
+type Data struct {
+	CID    string      `json:"_cid,omitempty"` // string
+	Points []DataPoint `json:"data"`           // [] len >= 0
+
+	// ValueFunction selects the rolled up value returned by Values, of
+	// those the API returns for each period - default average
+	ValueFunction DataFunction `json:"-"`
+}
+
+// Values returns the (non-nil) values of the series, skipping periods without data.
+func (d *Data) Values() []float64 {
+	values := make([]float64, 0, len(d.Points))
+	for _, p := range d.Points {
+		if v := p.Get(d.ValueFunction); v != nil {
+			values = append(values, *v)
+		}
+	}
+	return values
+}
+
+// DataFunction defines a rolled up value of numeric data in a period (the API
+// returns them all, see DataValues)
+type DataFunction string
+
+// Data rollup functions, see DataOptions.ValueFunction
+const (
+	DataFunctionAverage          DataFunction = "average"
+	DataFunctionCount            DataFunction = "count"
+	DataFunctionStddev           DataFunction = "stddev"
+	DataFunctionDerivative       DataFunction = "derivative"
+	DataFunctionDerivativeStddev DataFunction = "derivative_stddev"
+	DataFunctionCounter          DataFunction = "counter"
+	DataFunctionCounterStddev    DataFunction = "counter_stddev"
+)
+
+// DataRollupPeriods are the periods data is stored rolled up at, from which
+// DataOptions selects a period when none is set
+var DataRollupPeriods = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+	3 * time.Hour,
+	24 * time.Hour,
+}
+
+// DefaultDataMaxPoints is the default maximum number of periods fetched, see DataOptions.MaxPoints
+const DefaultDataMaxPoints = 2880
+
+// DataOptions defines the range and rollup of fetched data
+type DataOptions struct {
+	// Start and End of the range, required
+	Start time.Time
+	End   time.Time
+
+	// Period defines the rollup period, a multiple of the minimum rollup
+	// period (1m). When not set, the shortest of DataRollupPeriods which
+	// keeps the range within MaxPoints is used.
+	Period time.Duration
+
+	// ValueFunction selects the rolled up value returned by Data.Values -
+	// default average. It is applied by the client, to the values the API
+	// returns for each period, and is not sent to the API. Not valid for
+	// histogram data.
+	ValueFunction DataFunction
+
+	// Align extends the range to whole periods, start rounded down and end
+	// rounded up to a multiple of the period
+	Align bool
+
+	// MaxPoints defines the maximum number of periods in the range - default
+	// DefaultDataMaxPoints
+	MaxPoints int
+}
+
+// rollup returns the validated range and period of the options for the
+// passed data type
+func (o *DataOptions) rollup(dataType string) (time.Time, time.Time, time.Duration, error) {
+	if o == nil {
+		return time.Time{}, time.Time{}, 0, errors.New("invalid data options (nil)")
+	}
+
+	start, end := o.Start, o.End
+	if !end.After(start) {
+		return start, end, 0, errors.Errorf("invalid data range (%s - %s)", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+
+	switch o.ValueFunction {
+	case "", DataFunctionAverage:
+	case DataFunctionCount, DataFunctionStddev, DataFunctionDerivative, DataFunctionDerivativeStddev, DataFunctionCounter, DataFunctionCounterStddev:
+		if dataType == "histogram" {
+			return start, end, 0, errors.Errorf("invalid data function (%s) for histogram data", o.ValueFunction)
+		}
+	default:
+		return start, end, 0, errors.Errorf("invalid data function (%s)", o.ValueFunction)
+	}
+
+	maxPoints := o.MaxPoints
+	if maxPoints <= 0 {
+		maxPoints = DefaultDataMaxPoints
+	}
+
+	period := o.Period
+	if period == 0 {
+		for _, p := range DataRollupPeriods {
+			if dataPoints(start, end, p, o.Align) <= maxPoints {
+				period = p
+				break
+			}
+		}
+		if period == 0 {
+			return start, end, 0, errors.Errorf("invalid data range (%s - %s), exceeds %d points at every rollup period", start.Format(time.RFC3339), end.Format(time.RFC3339), maxPoints)
+		}
+	}
+	if period < DataRollupPeriods[0] || period%DataRollupPeriods[0] != 0 {
+		return start, end, 0, errors.Errorf("invalid data period (%s), must be a multiple of %s", period, DataRollupPeriods[0])
+	}
+	if n := dataPoints(start, end, period, o.Align); n > maxPoints {
+		return start, end, 0, errors.Errorf("invalid data period (%s), %d points exceeds maximum (%d)", period, n, maxPoints)
+	}
+
+	if o.Align {
+		start, end = alignRange(start, end, period)
+	}
+
+	return start, end, period, nil
+}
+
+// alignRange returns the range extended to whole periods
+func alignRange(start, end time.Time, period time.Duration) (time.Time, time.Time) {
+	p := int64(period / time.Second)
+	s := start.Unix() - start.Unix()%p
+	e := end.Unix()
+	if r := e % p; r != 0 || end.Nanosecond() != 0 {
+		e += p - r
+	}
+	return time.Unix(s, 0).UTC(), time.Unix(e, 0).UTC()
+}
+
+// dataPoints returns the number of periods in the range
+func dataPoints(start, end time.Time, period time.Duration, align bool) int {
+	if align {
+		start, end = alignRange(start, end, period)
+	}
+	d := end.Sub(start)
+	n := d / period
+	if d%period != 0 {
+		n++
+	}
+	return int(n)
+}
+
+// FetchData retrieves the stored numeric data for the passed check metric between
+// start and end, rolled up into periods of the passed duration.
+func (a *API) FetchData(checkCID CIDType, metricName string, start, end time.Time, period time.Duration) (*Data, error) {
+	return a.FetchDataWithContext(context.Background(), checkCID, metricName, start, end, period)
+}
+
+// FetchDataWithContext retrieves the stored numeric data for the passed check
+// metric between start and end, rolled up into periods of the passed
+// duration, stopping when ctx is done.
+func (a *API) FetchDataWithContext(ctx context.Context, checkCID CIDType, metricName string, start, end time.Time, period time.Duration) (*Data, error) {
+	result, err := a.fetchData(ctx, checkCID, metricName, "numeric", start, end, period)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &Data{}
+	if err := a.unmarshalJSON(result, data); err != nil {
+		return nil, errors.Wrap(err, "parsing data")
+	}
+
+	return data, nil
+}
+
+// FetchDataWithOptions retrieves the stored numeric data for the passed check
+// metric over the range and rollup in the passed options.
+func (a *API) FetchDataWithOptions(checkCID CIDType, metricName string, opts *DataOptions) (*Data, error) {
+	return a.FetchDataWithOptionsWithContext(context.Background(), checkCID, metricName, opts)
+}
+
+// FetchDataWithOptionsWithContext retrieves the stored numeric data for the
+// passed check metric over the range and rollup in the passed options,
+// stopping when ctx is done.
+func (a *API) FetchDataWithOptionsWithContext(ctx context.Context, checkCID CIDType, metricName string, opts *DataOptions) (*Data, error) {
+	start, end, period, err := opts.rollup("numeric")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := a.FetchDataWithContext(ctx, checkCID, metricName, start, end, period)
+	if err != nil {
+		return nil, err
+	}
+	data.ValueFunction = opts.ValueFunction
+
+	return data, nil
+}
+
+// HistogramDataPoint defines the histogram for a single period of a histogram data series
+type HistogramDataPoint struct {
+	Timestamp time.Time
+	Histogram *Histogram // nil if no data was collected during the period
+}
+
+// UnmarshalJSON decodes a histogram data point, encoded as [timestamp, {bins}]
+// or [timestamp, period, {bins}] by the API.
+func (dp *HistogramDataPoint) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return errors.Wrap(err, "parsing histogram data point")
+	}
+	if len(raw) != 2 && len(raw) != 3 {
+		return errors.Errorf("invalid histogram data point, expected [timestamp, histogram] (%s)", string(b))
+	}
+
+	var ts float64
+	if err := json.Unmarshal(raw[0], &ts); err != nil {
+		return errors.Wrap(err, "parsing histogram data point timestamp")
+	}
+	dp.Timestamp = time.Unix(int64(ts), 0).UTC()
+
+	h, err := DecodeHistogram(raw[len(raw)-1])
+	if err != nil {
+		return err
+	}
+	dp.Histogram = h
+
+	return nil
+}
+
+// MarshalJSON encodes a histogram data point as [timestamp, {bins}].
+func (dp HistogramDataPoint) MarshalJSON() ([]byte, error) {
+	var bins map[string]uint64
+	if dp.Histogram != nil {
+		bins = make(map[string]uint64)
+		for _, b := range dp.Histogram.Bins() {
+			bins[b.String()] = dp.Histogram.BinCount(b)
+		}
+	}
+	return json.Marshal([]interface{}{dp.Timestamp.Unix(), bins})
+}
+
+// HistogramData defines a series of stored histogram data for a check metric.
+type HistogramData struct {
+	CID    string               `json:"_cid,omitempty"` // string
+	Points []HistogramDataPoint `json:"data"`           // [] len >= 0
+}
+
+// Merged returns a single histogram with the samples of all periods.
+func (d *HistogramData) Merged() *Histogram {
+	h := NewHistogram()
+	for _, p := range d.Points {
+		h.Merge(p.Histogram)
+	}
+	return h
+}
+
+// FetchHistogramData retrieves the stored histogram data for the passed check
+// metric between start and end, rolled up into periods of the passed duration.
+func (a *API) FetchHistogramData(checkCID CIDType, metricName string, start, end time.Time, period time.Duration) (*HistogramData, error) {
+	return a.FetchHistogramDataWithContext(context.Background(), checkCID, metricName, start, end, period)
+}
+
+// FetchHistogramDataWithContext retrieves the stored histogram data for the
+// passed check metric between start and end, rolled up into periods of the
+// passed duration, stopping when ctx is done.
+func (a *API) FetchHistogramDataWithContext(ctx context.Context, checkCID CIDType, metricName string, start, end time.Time, period time.Duration) (*HistogramData, error) {
+	result, err := a.fetchData(ctx, checkCID, metricName, "histogram", start, end, period)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &HistogramData{}
+	if err := a.unmarshalJSON(result, data); err != nil {
+		return nil, errors.Wrap(err, "parsing histogram data")
+	}
+
+	return data, nil
+}
+
+// FetchHistogramDataWithOptions retrieves the stored histogram data for the
+// passed check metric over the range and rollup in the passed options.
+func (a *API) FetchHistogramDataWithOptions(checkCID CIDType, metricName string, opts *DataOptions) (*HistogramData, error) {
+	return a.FetchHistogramDataWithOptionsWithContext(context.Background(), checkCID, metricName, opts)
+}
+
+// FetchHistogramDataWithOptionsWithContext retrieves the stored histogram
+// data for the passed check metric over the range and rollup in the passed
+// options, stopping when ctx is done.
+func (a *API) FetchHistogramDataWithOptionsWithContext(ctx context.Context, checkCID CIDType, metricName string, opts *DataOptions) (*HistogramData, error) {
+	start, end, period, err := opts.rollup("histogram")
+	if err != nil {
+		return nil, err
+	}
+
+	return a.FetchHistogramDataWithContext(ctx, checkCID, metricName, start, end, period)
+}
+
+// fetchData retrieves the raw data of the passed type for a check metric,
+// stopping when ctx is done
+func (a *API) fetchData(ctx context.Context, checkCID CIDType, metricName, dataType string, start, end time.Time, period time.Duration) ([]byte, error) {
+	dataCID, err := dataCID(checkCID, metricName)
+	if err != nil {
+		return nil, err
+	}
+	if !end.After(start) {
+		return nil, errors.Errorf("invalid data range (%s - %s)", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+	if period < time.Second {
+		return nil, errors.Errorf("invalid data period (%s)", period)
+	}
+
+	q := url.Values{}
+	q.Set("type", dataType)
+	q.Set("start", strconv.FormatInt(start.Unix(), 10))
+	q.Set("end", strconv.FormatInt(end.Unix(), 10))
+	q.Set("period", strconv.FormatInt(int64(period/time.Second), 10))
+
+	reqURL := url.URL{
+		Path:     dataCID,
+		RawQuery: q.Encode(),
+	}
+
+	result, err := a.GetWithContext(ctx, reqURL.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching data")
+	}
+
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch data, received JSON: %s", string(result))
+	}
+
+	return result, nil
+}
+
+// dataCID returns the data cid (/data/<check id>_<metric name>) for a check metric
+func dataCID(checkCID CIDType, metricName string) (string, error) {
+	if checkCID == nil || *checkCID == "" {
+		return "", errors.New("invalid check CID (none)")
+	}
+	if metricName == "" {
+		return "", errors.New("invalid metric name (none)")
+	}
+
+	checkID := strings.TrimPrefix(*checkCID, config.CheckPrefix+"/")
+	cid := fmt.Sprintf("%s/%s_%s", config.DataPrefix, checkID, metricName)
+
+	if !validCID(config.DataPrefix, cid) {
+		return "", errors.Errorf("invalid data CID (%s)", cid)
+	}
+
+	return cid, nil
+}
@@ -0,0 +1,287 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testDataJSON = json.RawMessage(`{
+	"_cid": "/data/1234_foo",
+	"data": [
+		[1483033000, {"count": 5, "value": 1.5, "stddev": 0.5, "derivative": 0.1, "derivative_stddev": 0.01, "counter": 0.1, "counter_stddev": 0.01}],
+		[1483033300, {"count": 0, "value": null, "stddev": null, "derivative": null, "derivative_stddev": null, "counter": null, "counter_stddev": null}],
+		[1483033600, {"count": 3, "value": 2.5}]
+	]
+}`)
+
+func testDataServer() *httptest.Server {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		reqURL := r.URL.String()
+		if r.Method != "GET" {
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, reqURL))
+			return
+		}
+
+		var ret json.RawMessage
+		switch reqURL {
+		case "/data/1234_foo?end=1483033900&period=300&start=1483033000&type=numeric",
+			"/data/1234_foo?end=1483034100&period=300&start=1483032900&type=numeric",
+			"/data/1234_foo?end=1483033900&period=60&start=1483033000&type=numeric":
+			ret = testDataJSON
+		case "/data/1234_lat?end=1483033600&period=300&start=1483033000&type=histogram":
+			ret = testHistogramDataJSON
+		default:
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, reqURL))
+			return
+		}
+		w.WriteHeader(200)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, string(ret))
+	}
+
+	return httptest.NewServer(http.HandlerFunc(f))
+}
+
+func dataTestBootstrap(t *testing.T) (*API, *httptest.Server) {
+	server := testDataServer()
+
+	ac := &Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      server.URL,
+	}
+	apih, err := NewAPI(ac)
+	if err != nil {
+		server.Close()
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	return apih, server
+}
+
+func TestFetchData(t *testing.T) {
+	apih, server := dataTestBootstrap(t)
+	defer server.Close()
+
+	start := time.Unix(1483033000, 0)
+	end := start.Add(15 * time.Minute)
+	checkCID := "/check/1234"
+	empty := ""
+
+	tests := []struct {
+		id          string
+		cid         *string
+		metric      string
+		start       time.Time
+		end         time.Time
+		period      time.Duration
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (cid)", &empty, "foo", start, end, 5 * time.Minute, true, "invalid check CID (none)"},
+		{"invalid (metric)", &checkCID, "", start, end, 5 * time.Minute, true, "invalid metric name (none)"},
+		{"invalid (range)", &checkCID, "foo", end, start, 5 * time.Minute, true, "invalid data range (" + end.Format(time.RFC3339) + " - " + start.Format(time.RFC3339) + ")"},
+		{"invalid (period)", &checkCID, "foo", start, end, 0, true, "invalid data period (0s)"},
+		{"valid", &checkCID, "foo", start, end, 5 * time.Minute, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			data, err := apih.FetchData(CIDType(test.cid), test.metric, test.start, test.end, test.period)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if len(data.Points) != 3 {
+				t.Fatalf("expected 3 points, got %d", len(data.Points))
+			}
+			if !data.Points[0].Timestamp.Equal(start) {
+				t.Fatalf("unexpected timestamp (%s)", data.Points[0].Timestamp)
+			}
+			if data.Points[1].Value != nil {
+				t.Fatalf("expected nil value, got %f", *data.Points[1].Value)
+			}
+			if *data.Points[0].Derivative != 0.1 {
+				t.Fatalf("unexpected derivative (%f)", *data.Points[0].Derivative)
+			}
+			if !reflect.DeepEqual(data.Values(), []float64{1.5, 2.5}) {
+				t.Fatalf("unexpected values (%v)", data.Values())
+			}
+		})
+	}
+}
+
+func TestFetchDataEscapedMetric(t *testing.T) {
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.EscapedPath()
+		_, _ = w.Write(testDataJSON)
+	}))
+	defer server.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	tests := []struct {
+		metric   string
+		expected string
+	}{
+		{"foo", "/data/1234_foo"},
+		{"cpu`idle", "/data/1234_cpu%60idle"},
+		{"latency 99%", "/data/1234_latency%2099%25"},
+	}
+
+	checkCID := "/check/1234"
+	start := time.Unix(1483033000, 0)
+	for _, test := range tests {
+		if _, err := apih.FetchData(CIDType(&checkCID), test.metric, start, start.Add(15*time.Minute), 5*time.Minute); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if requested != test.expected {
+			t.Errorf("%s: unexpected path (%s)", test.metric, requested)
+		}
+	}
+}
+
+func TestDataPointJSON(t *testing.T) {
+	var dp DataPoint
+	if err := json.Unmarshal([]byte(`[1483033000]`), &dp); err == nil {
+		t.Fatal("expected error")
+	}
+	if err := json.Unmarshal([]byte(`[1483033000, {"value": 1}]`), &dp); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	b, err := json.Marshal(dp)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	var rt DataPoint
+	if err := json.Unmarshal(b, &rt); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if !reflect.DeepEqual(dp, rt) {
+		t.Fatalf("round trip mismatch (%s)", string(b))
+	}
+}
+
+func TestHistogramDataPointJSON(t *testing.T) {
+	var dp HistogramDataPoint
+	if err := json.Unmarshal([]byte(`[1483033000, 60, {"H[1.2e+00]": 3, "H[4.5e-03]": 2}]`), &dp); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	b, err := json.Marshal(dp)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if string(b) != `[1483033000,{"H[1.2e+00]":3,"H[4.5e-03]":2}]` {
+		t.Fatalf("unexpected encoding (%s)", string(b))
+	}
+	var rt HistogramDataPoint
+	if err := json.Unmarshal(b, &rt); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if !reflect.DeepEqual(dp, rt) {
+		t.Fatalf("round trip mismatch (%s)", string(b))
+	}
+
+	if b, err := json.Marshal(HistogramDataPoint{Timestamp: dp.Timestamp}); err != nil || string(b) != `[1483033000,null]` {
+		t.Fatalf("unexpected encoding (%s, %v)", string(b), err)
+	}
+}
+
+func TestFetchDataWithOptions(t *testing.T) {
+	apih, server := dataTestBootstrap(t)
+	defer server.Close()
+
+	start := time.Unix(1483033000, 0)
+	end := start.Add(15 * time.Minute)
+	checkCID := "/check/1234"
+
+	tests := []struct {
+		id          string
+		opts        *DataOptions
+		shouldFail  bool
+		expectedErr string
+		expected    []float64
+	}{
+		{"invalid (nil)", nil, true, "invalid data options (nil)", nil},
+		{"invalid (range)", &DataOptions{Start: end, End: start}, true, "invalid data range (" + end.Format(time.RFC3339) + " - " + start.Format(time.RFC3339) + ")", nil},
+		{"invalid (function)", &DataOptions{Start: start, End: end, ValueFunction: "median"}, true, "invalid data function (median)", nil},
+		{"invalid (period)", &DataOptions{Start: start, End: end, Period: 90 * time.Second}, true, "invalid data period (1m30s), must be a multiple of 1m0s", nil},
+		{"invalid (max points)", &DataOptions{Start: start, End: end, Period: time.Minute, MaxPoints: 10}, true, "invalid data period (1m0s), 15 points exceeds maximum (10)", nil},
+		{"invalid (auto period)", &DataOptions{Start: start, End: start.Add(30 * 24 * time.Hour), MaxPoints: 10}, true, "invalid data range (" + start.Format(time.RFC3339) + " - " + start.Add(30*24*time.Hour).Format(time.RFC3339) + "), exceeds 10 points at every rollup period", nil},
+		{"valid (auto period)", &DataOptions{Start: start, End: end}, false, "", []float64{1.5, 2.5}},
+		{"valid (aligned auto period)", &DataOptions{Start: start, End: end, Align: true, MaxPoints: 10, ValueFunction: DataFunctionCount}, false, "", []float64{5, 0, 3}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			data, err := apih.FetchDataWithOptions(CIDType(&checkCID), "foo", test.opts)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if !reflect.DeepEqual(data.Values(), test.expected) {
+				t.Fatalf("unexpected values (%v)", data.Values())
+			}
+		})
+	}
+}
+
+func TestFetchHistogramDataWithOptions(t *testing.T) {
+	apih, server := dataTestBootstrap(t)
+	defer server.Close()
+
+	start := time.Unix(1483033000, 0)
+	checkCID := "/check/1234"
+	opts := &DataOptions{Start: start, End: start.Add(time.Hour), ValueFunction: DataFunctionCounter}
+
+	_, err := apih.FetchHistogramDataWithOptions(CIDType(&checkCID), "foo", opts)
+	if err == nil {
+		t.Fatal("expected error")
+	} else if err.Error() != "invalid data function (counter) for histogram data" {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+}
+
+func TestAlignRange(t *testing.T) {
+	start, end := alignRange(time.Unix(1483033000, 0), time.Unix(1483033900, 0), 5*time.Minute)
+	if start.Unix() != 1483032900 || end.Unix() != 1483034100 {
+		t.Fatalf("unexpected range (%d - %d)", start.Unix(), end.Unix())
+	}
+	start, end = alignRange(time.Unix(1483032900, 0), time.Unix(1483034100, 0), 5*time.Minute)
+	if start.Unix() != 1483032900 || end.Unix() != 1483034100 {
+		t.Fatalf("unexpected aligned range (%d - %d)", start.Unix(), end.Unix())
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package diskcache is an apiclient Cache storing responses in files, so
+// tools making a call per invocation (e.g. CLIs) keep a warm cache across
+// invocations, and recently fetched objects can be inspected offline.
+//
+//	cache, _ := diskcache.New(&diskcache.Config{Dir: dir, TTL: 10 * time.Minute})
+//	apih, _ := apiclient.New(&apiclient.Config{TokenKey: key, Cache: cache})
+//
+// Each entry is a file holding the response with a checksum, an entry which
+// fails its check (e.g. a partial write, or a file changed by hand) is
+// removed and treated as missing.
+package diskcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultTTL is how long entries are served, see Config TTL
+	DefaultTTL = 5 * time.Minute
+
+	entrySuffix = ".json"
+)
+
+// Config defines the cache directory and entry lifetime
+type Config struct {
+	// Dir is the directory holding the entries, created if missing, required
+	Dir string
+
+	// TTL is how long after being stored an entry is served - default
+	// DefaultTTL. Expired entries are kept (see Peek) until replaced or
+	// removed by Prune.
+	TTL time.Duration
+}
+
+// Cache stores responses in files of a directory, it is safe for concurrent
+// use, including by several processes sharing the directory.
+type Cache struct {
+	dir string
+	ttl time.Duration
+}
+
+// Entry is a stored response
+type Entry struct {
+	Key    string    `json:"key"`
+	Stored time.Time `json:"stored"`
+	Data   []byte    `json:"data"`
+}
+
+// entry is the file contents of an Entry
+type entry struct {
+	Entry
+	Checksum string `json:"checksum"` // sha256 of key and data
+}
+
+// New returns a cache in the configured directory
+func New(cfg *Config) (*Cache, error) {
+	if cfg == nil {
+		return nil, errors.New("invalid disk cache config (nil)")
+	}
+	if cfg.Dir == "" {
+		return nil, errors.New("invalid disk cache config, directory required")
+	}
+	if cfg.TTL < 0 {
+		return nil, errors.Errorf("invalid disk cache TTL (%s), must not be negative", cfg.TTL)
+	}
+	if err := os.MkdirAll(cfg.Dir, 0700); err != nil {
+		return nil, errors.Wrap(err, "creating disk cache directory")
+	}
+
+	c := &Cache{dir: cfg.Dir, ttl: cfg.TTL}
+	if c.ttl == 0 {
+		c.ttl = DefaultTTL
+	}
+	return c, nil
+}
+
+// Get returns the response stored for the key, if it has not expired.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	e, ok := c.Peek(key)
+	if !ok || time.Since(e.Stored) > c.ttl {
+		return nil, false
+	}
+	return e.Data, true
+}
+
+// Set stores the response for the key. Failures to write are ignored, the
+// response is simply not cached.
+func (c *Cache) Set(key string, data []byte) {
+	e := entry{Entry: Entry{Key: key, Stored: time.Now(), Data: data}}
+	e.Checksum = checksum(key, data)
+	buf, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+
+	// written to a temporary file and renamed, so readers never see a
+	// partial entry
+	tmp, err := ioutil.TempFile(c.dir, ".tmp-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(buf)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// Invalidate removes the entries with keys for which match returns true.
+func (c *Cache) Invalidate(match func(key string) bool) {
+	c.each(func(path string, e *Entry) {
+		if match(e.Key) {
+			os.Remove(path)
+		}
+	})
+}
+
+// Peek returns the entry stored for the key, expired or not, e.g. to
+// inspect recently fetched objects offline.
+func (c *Cache) Peek(key string) (*Entry, bool) {
+	e, err := c.read(c.path(key))
+	if err != nil || e.Key != key {
+		return nil, false
+	}
+	return e, true
+}
+
+// Keys returns the keys of the stored entries, expired or not, sorted.
+func (c *Cache) Keys() []string {
+	var keys []string
+	c.each(func(_ string, e *Entry) {
+		keys = append(keys, e.Key)
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+// Prune removes the expired entries.
+func (c *Cache) Prune() {
+	c.each(func(path string, e *Entry) {
+		if time.Since(e.Stored) > c.ttl {
+			os.Remove(path)
+		}
+	})
+}
+
+// each calls fn with the valid entries
+func (c *Cache) each(fn func(path string, e *Entry)) {
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), entrySuffix) {
+			continue
+		}
+		path := filepath.Join(c.dir, f.Name())
+		if e, err := c.read(path); err == nil {
+			fn(path, e)
+		}
+	}
+}
+
+// read returns the entry in a file, removing the file if it fails the
+// integrity check
+func (c *Cache) read(path string) (*Entry, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var e entry
+	if err := json.Unmarshal(buf, &e); err != nil || e.Checksum != checksum(e.Key, e.Data) {
+		os.Remove(path)
+		return nil, errors.Errorf("invalid disk cache entry (%s)", path)
+	}
+	return &e.Entry, nil
+}
+
+// path returns the file of the entry for the key
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+entrySuffix)
+}
+
+// checksum returns the checksum of an entry's key and data
+func checksum(key string, data []byte) string {
+	h := sha256.New()
+	h.Write([]byte(key))
+	h.Write([]byte{0})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,166 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diskcache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testDir returns a temporary directory, remove it when done
+func testDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "diskcache")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	return dir
+}
+
+func TestNew(t *testing.T) {
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		id     string
+		cfg    *Config
+		errStr string
+	}{
+		{"nil config", nil, "invalid disk cache config (nil)"},
+		{"no dir", &Config{}, "invalid disk cache config, directory required"},
+		{"negative ttl", &Config{Dir: dir, TTL: -time.Second}, "invalid disk cache TTL (-1s), must not be negative"},
+		{"created dir", &Config{Dir: filepath.Join(dir, "a", "b")}, ""},
+	}
+
+	for _, test := range tests {
+		_, err := New(test.cfg)
+		if test.errStr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error (%s)", test.id, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != test.errStr {
+			t.Errorf("%s: expected error (%s) got (%v)", test.id, test.errStr, err)
+		}
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+
+	c, err := New(&Config{Dir: dir, TTL: time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if _, ok := c.Get("https://api/graph/1"); ok {
+		t.Fatal("expected miss")
+	}
+	c.Set("https://api/graph/1", []byte(`{"_cid":"/graph/1"}`))
+	c.Set("https://api/graph/2", []byte(`{"_cid":"/graph/2"}`))
+	c.Set("https://api/user/1", []byte(`{"_cid":"/user/1"}`))
+
+	// warm across instances
+	c2, err := New(&Config{Dir: dir, TTL: time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	data, ok := c2.Get("https://api/graph/1")
+	if !ok || string(data) != `{"_cid":"/graph/1"}` {
+		t.Fatalf("unexpected entry (%s, %t)", data, ok)
+	}
+	expected := []string{"https://api/graph/1", "https://api/graph/2", "https://api/user/1"}
+	if keys := c2.Keys(); !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("unexpected keys (%v)", keys)
+	}
+
+	c2.Invalidate(func(key string) bool { return strings.Contains(key, "/graph/") })
+	if keys := c.Keys(); !reflect.DeepEqual(keys, []string{"https://api/user/1"}) {
+		t.Fatalf("unexpected keys (%v)", keys)
+	}
+
+	// no temporary files left behind
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("unexpected files (%d)", len(files))
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+
+	c, err := New(&Config{Dir: dir, TTL: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	c.Set("key", []byte("data"))
+	if _, ok := c.Get("key"); !ok {
+		t.Fatal("expected hit")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, ok := c.Get("key"); ok {
+		t.Fatal("expected expired entry to miss")
+	}
+
+	// expired entries can still be inspected until pruned
+	e, ok := c.Peek("key")
+	if !ok || string(e.Data) != "data" || e.Key != "key" {
+		t.Fatalf("unexpected entry (%#v, %t)", e, ok)
+	}
+	c.Prune()
+	if _, ok := c.Peek("key"); ok {
+		t.Fatal("expected pruned entry to miss")
+	}
+}
+
+func TestCacheIntegrity(t *testing.T) {
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+
+	c, err := New(&Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	tests := []struct {
+		id      string
+		corrupt func(buf []byte) []byte
+	}{
+		{"truncated", func(buf []byte) []byte { return buf[:len(buf)/2] }},
+		{"changed data", func(buf []byte) []byte {
+			return []byte(strings.Replace(string(buf), `"key":"key"`, `"key":"other"`, 1))
+		}},
+		{"empty", func(buf []byte) []byte { return nil }},
+	}
+
+	for _, test := range tests {
+		c.Set("key", []byte("data"))
+		path := c.path("key")
+		buf, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("%s: unexpected error (%s)", test.id, err)
+		}
+		if err := ioutil.WriteFile(path, test.corrupt(buf), 0600); err != nil {
+			t.Fatalf("%s: unexpected error (%s)", test.id, err)
+		}
+		if _, ok := c.Get("key"); ok {
+			t.Fatalf("%s: expected corrupt entry to miss", test.id)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("%s: expected corrupt entry removed (%v)", test.id, err)
+		}
+	}
+}
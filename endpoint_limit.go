@@ -0,0 +1,183 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Endpoint limit - per endpoint caps on concurrent and per second requests
+
+package apiclient
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// EndpointLimit caps the requests to an endpoint (see Config EndpointLimits)
+type EndpointLimit struct {
+	// MaxConcurrent limits the requests in progress (until their response
+	// is read) - default 0, no limit
+	MaxConcurrent int
+
+	// RequestsPerSecond limits the rate of requests - default 0, no limit
+	RequestsPerSecond float64
+
+	// Burst is the number of requests which can be made at once, before
+	// the rate limit applies - default 1
+	Burst int
+}
+
+// endpointLimiter applies an EndpointLimit (or the RateLimit) to the
+// requests of every copy of an API
+type endpointLimiter struct {
+	slots chan struct{} // nil without a concurrency limit
+
+	mu       sync.Mutex
+	rate     float64 // requests per second, 0 without a rate limit
+	burst    float64
+	tokens   float64
+	last     time.Time
+	until    time.Time // requests wait until, when the API allows none
+	maxRate  float64   // rate adapted to is no higher
+	adaptive bool      // adapt the rate to the responses (see RateLimit)
+}
+
+// newEndpointLimiters returns the limiters for the endpoint limits, keyed
+// by endpoint prefix
+func newEndpointLimiters(limits map[string]EndpointLimit) (map[string]*endpointLimiter, error) {
+	if len(limits) == 0 {
+		return nil, nil
+	}
+	limiters := make(map[string]*endpointLimiter, len(limits))
+	for prefix, limit := range limits {
+		if !strings.HasPrefix(prefix, "/") || strings.Count(prefix, "/") > 1 {
+			return nil, errors.Errorf("invalid endpoint limit (%s), must be keyed by endpoint prefix (e.g. /metric)", prefix)
+		}
+		if limit.MaxConcurrent < 0 || limit.RequestsPerSecond < 0 || limit.Burst < 0 {
+			return nil, errors.Errorf("invalid endpoint limit (%s), limits must not be negative", prefix)
+		}
+		l := &endpointLimiter{rate: limit.RequestsPerSecond, burst: float64(limit.Burst)}
+		if l.burst == 0 {
+			l.burst = 1
+		}
+		l.tokens = l.burst
+		if limit.MaxConcurrent > 0 {
+			l.slots = make(chan struct{}, limit.MaxConcurrent)
+		}
+		limiters[prefix] = l
+	}
+	return limiters, nil
+}
+
+// endpointLimiter returns the limiter for the endpoint of reqPath (e.g.
+// /metric for /metric?search=cpu), nil if it is not limited
+func (a *API) endpointLimiter(reqPath string) *endpointLimiter {
+	if len(a.endpointLimits) == 0 {
+		return nil
+	}
+	return a.endpointLimits[endpointPrefix(reqPath)]
+}
+
+// endpointPrefix returns the prefix of the endpoint of reqPath (e.g. /metric
+// for /metric?search=cpu or /v2/metric/1234_cpu)
+func endpointPrefix(reqPath string) string {
+	if strings.HasPrefix(reqPath, "/v2/") {
+		reqPath = reqPath[3:]
+	}
+	if !strings.HasPrefix(reqPath, "/") {
+		reqPath = "/" + reqPath
+	}
+	if idx := strings.IndexAny(reqPath[1:], "/?"); idx >= 0 {
+		reqPath = reqPath[:idx+1]
+	}
+	return reqPath
+}
+
+// acquire waits for a concurrency slot and a rate token, returns a func
+// releasing the slot
+func (l *endpointLimiter) acquire(req *http.Request) (func(), error) {
+	ctx := req.Context()
+	for {
+		// the rate may be adapted while waiting, read under the lock
+		l.mu.Lock()
+		if l.rate <= 0 {
+			l.mu.Unlock()
+			break
+		}
+		now := time.Now()
+		if pause := l.until.Sub(now); pause > 0 {
+			l.mu.Unlock()
+			if err := sleepContext(ctx, pause); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !l.last.IsZero() {
+			l.tokens += now.Sub(l.last).Seconds() * l.rate
+			if l.tokens > l.burst {
+				l.tokens = l.burst
+			}
+		}
+		l.last = now
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			break
+		}
+		wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+	if l.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-l.slots }) }, nil
+}
+
+// limitedTransport holds requests to the limits of an endpoint
+type limitedTransport struct {
+	next    http.RoundTripper
+	limiter *endpointLimiter
+}
+
+// RoundTrip waits for the limiter, the concurrency slot is held until the
+// response body is closed
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, err := t.limiter.acquire(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if resp != nil && t.limiter.adaptive {
+		t.limiter.adapt(resp)
+	}
+	if err != nil || resp == nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releaseBody releases a concurrency slot when the body is closed
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,146 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/go-apiclient/config"
+)
+
+func TestEndpointLimits(t *testing.T) {
+	var mu sync.Mutex
+	active := map[string]int{}
+	peak := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		family := "/" + strings.SplitN(r.URL.Path[1:], "/", 2)[0]
+		mu.Lock()
+		active[family]++
+		if active[family] > peak[family] {
+			peak[family] = active[family]
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		active[family]--
+		mu.Unlock()
+		fmt.Fprintln(w, `[]`)
+	}))
+	defer server.Close()
+
+	apih, err := New(&Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      server.URL,
+		EndpointLimits: map[string]EndpointLimit{
+			config.MetricPrefix: {MaxConcurrent: 1},
+			config.GraphPrefix:  {RequestsPerSecond: 20},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	acct, err := apih.WithAccount("/account/2")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	t.Run("concurrency", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			// the limit is shared with the account copy
+			for _, api := range []*API{apih, acct} {
+				api := api
+				wg.Add(2)
+				go func() {
+					defer wg.Done()
+					search := SearchQueryType("cpu")
+					if _, err := api.SearchMetrics(&search, nil); err != nil {
+						t.Errorf("unexpected error (%s)", err)
+					}
+				}()
+				go func() {
+					defer wg.Done()
+					if _, err := api.FetchAlerts(); err != nil {
+						t.Errorf("unexpected error (%s)", err)
+					}
+				}()
+			}
+		}
+		wg.Wait()
+
+		mu.Lock()
+		defer mu.Unlock()
+		if peak["/metric"] != 1 {
+			t.Fatalf("unexpected concurrent metric requests (%d)", peak["/metric"])
+		}
+		if peak["/alert"] < 2 {
+			t.Fatalf("alert requests limited (%d)", peak["/alert"])
+		}
+	})
+
+	t.Run("rate", func(t *testing.T) {
+		start := time.Now()
+		for i := 0; i < 5; i++ {
+			if _, err := apih.Get("/graph/1"); err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+		}
+		// the first request is made at once, then one every 50ms
+		if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+			t.Fatalf("requests not rate limited (%s)", elapsed)
+		}
+	})
+}
+
+func TestEndpointLimiter(t *testing.T) {
+	apih, err := New(&Config{
+		TokenKey:       "abc123",
+		URL:            "http://localhost",
+		EndpointLimits: map[string]EndpointLimit{config.MetricPrefix: {MaxConcurrent: 1}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	tests := []struct {
+		path    string
+		limited bool
+	}{
+		{"/metric", true},
+		{"/metric/1234_cpu", true},
+		{"/metric?search=cpu", true},
+		{"/v2/metric/1234_cpu", true},
+		{"metric/1234_cpu", true},
+		{"/metric_cluster/1", false},
+		{"/alert/1", false},
+	}
+	for _, test := range tests {
+		if limited := apih.endpointLimiter(test.path) != nil; limited != test.limited {
+			t.Errorf("%s: unexpected limited (%t)", test.path, limited)
+		}
+	}
+
+	invalid := []struct {
+		limits      map[string]EndpointLimit
+		expectedErr string
+	}{
+		{map[string]EndpointLimit{"metric": {}}, "invalid endpoint limit (metric), must be keyed by endpoint prefix (e.g. /metric)"},
+		{map[string]EndpointLimit{"/metric/1": {}}, "invalid endpoint limit (/metric/1), must be keyed by endpoint prefix (e.g. /metric)"},
+		{map[string]EndpointLimit{"/metric": {MaxConcurrent: -1}}, "invalid endpoint limit (/metric), limits must not be negative"},
+	}
+	for _, test := range invalid {
+		_, err := New(&Config{TokenKey: "abc123", URL: "http://localhost", EndpointLimits: test.limits})
+		if err == nil || err.Error() != test.expectedErr {
+			t.Errorf("unexpected error (%v)", err)
+		}
+	}
+}
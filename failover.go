@@ -0,0 +1,207 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Failover - calls to other API URLs when the active one is unhealthy (see
+// Config FailoverURLs)
+
+package apiclient
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultFailoverCooldown is how long an API URL which failed is skipped
+// for, unless every URL has failed
+const DefaultFailoverCooldown = 30 * time.Second
+
+// failoverSet tracks the health of the API URLs of an API, shared by its
+// copies (see WithAccount)
+type failoverSet struct {
+	primary  string     // URL requests are made to, replaced by the URL tried
+	urls     []*url.URL // the API URL then the failover URLs, in order
+	cooldown time.Duration
+
+	mu        sync.Mutex
+	active    int         // the URL calls are made to until it fails
+	unhealthy []time.Time // when each URL is tried again, after it failed
+}
+
+// newFailoverSet returns the failover set for the API URL and failover
+// URLs, nil without failover URLs
+func newFailoverSet(apiURL *url.URL, failoverURLs []string, cooldown time.Duration) (*failoverSet, error) {
+	if len(failoverURLs) == 0 {
+		return nil, nil
+	}
+	if cooldown < 0 {
+		return nil, errors.New("invalid failover cooldown, must not be negative")
+	}
+	if cooldown == 0 {
+		cooldown = DefaultFailoverCooldown
+	}
+	f := &failoverSet{
+		primary:   apiURL.String(),
+		urls:      []*url.URL{apiURL},
+		cooldown:  cooldown,
+		unhealthy: make([]time.Time, len(failoverURLs)+1),
+	}
+	for _, fu := range failoverURLs {
+		u, err := parseAPIURL(fu)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing Circonus API failover URL")
+		}
+		f.urls = append(f.urls, u)
+	}
+	return f, nil
+}
+
+// https returns true if any of the URLs uses https
+func (f *failoverSet) https() bool {
+	for _, u := range f.urls {
+		if u.Scheme == "https" {
+			return true
+		}
+	}
+	return false
+}
+
+// activeURL returns the URL calls are made to
+func (f *failoverSet) activeURL() *url.URL {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.urls[f.active]
+}
+
+// order returns the URLs to try, by index: the active URL, the other
+// healthy URLs in order, then those which failed, the soonest to be tried
+// again first
+func (f *failoverSet) order() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	order := make([]int, 0, len(f.urls))
+	var failed []int
+	for n := range f.urls {
+		i := (f.active + n) % len(f.urls)
+		if n > 0 && now.Before(f.unhealthy[i]) {
+			failed = append(failed, i)
+			continue
+		}
+		order = append(order, i)
+	}
+	for len(failed) > 0 {
+		next := 0
+		for n := range failed {
+			if f.unhealthy[failed[n]].Before(f.unhealthy[failed[next]]) {
+				next = n
+			}
+		}
+		order = append(order, failed[next])
+		failed = append(failed[:next], failed[next+1:]...)
+	}
+	return order
+}
+
+// failed marks URL i unhealthy, skipped for the cooldown
+func (f *failoverSet) failed(i int) {
+	f.mu.Lock()
+	f.unhealthy[i] = time.Now().Add(f.cooldown)
+	f.mu.Unlock()
+}
+
+// succeeded marks URL i healthy, the URL calls are made to until it fails
+func (f *failoverSet) succeeded(i int) {
+	f.mu.Lock()
+	f.unhealthy[i] = time.Time{}
+	f.active = i
+	f.mu.Unlock()
+}
+
+// unhealthyResponse returns true if the response (or failure) of a URL
+// means it should not be used: it could not be reached, or its response
+// came from a gateway which could not reach the API
+func unhealthyResponse(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// failoverTransport makes each attempt of an API call to the active URL,
+// trying the others when it is unhealthy
+type failoverTransport struct {
+	next http.RoundTripper
+	set  *failoverSet
+}
+
+// RoundTrip makes the request to the URLs in turn, until one is healthy
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqURL := req.URL.String()
+	if !strings.HasPrefix(reqURL, t.set.primary) {
+		// e.g. following a redirect to another host
+		return t.next.RoundTrip(req)
+	}
+	reqPath := reqURL[len(t.set.primary):]
+
+	var resp *http.Response
+	var err error
+	for n, i := range t.set.order() {
+		if n > 0 {
+			if req.Context().Err() != nil || (req.Body != nil && req.GetBody == nil) {
+				break
+			}
+			if resp != nil {
+				io.Copy(ioutil.Discard, resp.Body) // nolint: errcheck
+				resp.Body.Close()                  // nolint: errcheck
+			}
+		}
+		u, perr := url.Parse(t.set.urls[i].String() + reqPath)
+		if perr != nil {
+			return nil, errors.Wrap(perr, "Circonus API failover URL")
+		}
+		r := req.Clone(req.Context())
+		r.URL = u
+		r.Host = ""
+		if n > 0 && req.GetBody != nil {
+			if r.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		resp, err = t.next.RoundTrip(r)
+		if req.Context().Err() != nil {
+			// the call was canceled, not a failure of the URL
+			return resp, err
+		}
+		if _, ok := err.(*hookError); ok {
+			// a request or response hook failed the call, not the URL
+			return resp, err
+		}
+		if !unhealthyResponse(resp, err) {
+			t.set.succeeded(i)
+			return resp, err
+		}
+		t.set.failed(i)
+	}
+	return resp, err
+}
+
+// ActiveURL returns the API URL calls are made to, which changes when it
+// fails if the API has failover URLs (see Config FailoverURLs)
+func (a *API) ActiveURL() string {
+	if a.failover == nil {
+		return a.apiURL.String()
+	}
+	return a.failover.activeURL().String()
+}
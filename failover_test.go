@@ -0,0 +1,190 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFailover(t *testing.T) {
+	var primaryDown int32 = 1
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&primaryDown) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, `{"from":"primary"}`)
+	}))
+	defer primary.Close()
+
+	inside := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Fprintf(w, `{"from":"inside","path":%q,"body":%q}`+"\n", r.URL.Path, body)
+	}))
+
+	apih, err := New(&Config{
+		TokenKey:         "abc123",
+		URL:              primary.URL + "/v2",
+		FailoverURLs:     []string{inside.URL + "/v2"},
+		FailoverCooldown: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if u := apih.ActiveURL(); u != primary.URL+"/v2" {
+		t.Fatalf("unexpected active URL (%s)", u)
+	}
+
+	t.Log("primary unhealthy, calls fail over")
+	{
+		result, err := apih.Post("/graph", []byte(`{"title":"web"}`))
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		expected := `{"from":"inside","path":"/v2/graph","body":"{\"title\":\"web\"}"}`
+		if strings.TrimSpace(string(result)) != expected {
+			t.Fatalf("unexpected result (%s)", result)
+		}
+		if u := apih.ActiveURL(); u != inside.URL+"/v2" {
+			t.Fatalf("unexpected active URL (%s)", u)
+		}
+	}
+
+	t.Log("primary healthy, calls stick to the failover URL")
+	{
+		atomic.StoreInt32(&primaryDown, 0)
+		time.Sleep(5 * time.Millisecond)
+		result, err := apih.Get("/graph/1")
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if !strings.Contains(string(result), `"inside"`) {
+			t.Fatalf("unexpected result (%s)", result)
+		}
+	}
+
+	t.Log("failover URL unreachable, calls return to the primary")
+	{
+		inside.Close()
+		acct, err := apih.WithAccount("/account/2")
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		result, err := acct.Get("/graph/1")
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if !strings.Contains(string(result), `"primary"`) {
+			t.Fatalf("unexpected result (%s)", result)
+		}
+		// copies share the active URL
+		if u := apih.ActiveURL(); u != primary.URL+"/v2" {
+			t.Fatalf("unexpected active URL (%s)", u)
+		}
+	}
+}
+
+func TestFailoverHookError(t *testing.T) {
+	var primaryCalls, insideCalls int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&primaryCalls, 1)
+		fmt.Fprintln(w, `[]`)
+	}))
+	defer primary.Close()
+	inside := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&insideCalls, 1)
+		fmt.Fprintln(w, `[]`)
+	}))
+	defer inside.Close()
+
+	errUnsigned := errors.New("unsigned response")
+	apih, err := New(&Config{
+		TokenKey:     "abc123",
+		URL:          primary.URL,
+		FailoverURLs: []string{inside.URL},
+		ResponseHooks: []ResponseHook{
+			func(resp *http.Response) error { return errUnsigned },
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if _, err := apih.FetchGraphs(); !errors.Is(err, errUnsigned) {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+	// the hook failed the call, the primary URL did not
+	if n := atomic.LoadInt32(&primaryCalls); n != 1 {
+		t.Fatalf("unexpected primary calls (%d)", n)
+	}
+	if n := atomic.LoadInt32(&insideCalls); n != 0 {
+		t.Fatalf("unexpected failover calls (%d)", n)
+	}
+	if u := apih.ActiveURL(); u != primary.URL {
+		t.Fatalf("unexpected active URL (%s)", u)
+	}
+}
+
+func TestFailoverOrder(t *testing.T) {
+	apiURL, _ := parseAPIURL("https://a.example.com/v2")
+	f, err := newFailoverSet(apiURL, []string{"b.example.com", "https://c.example.com/v2/"}, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if u := f.urls[1].String(); u != "https://b.example.com/v2" {
+		t.Fatalf("unexpected URL (%s)", u)
+	}
+
+	tests := []struct {
+		id       string
+		change   func()
+		expected string
+	}{
+		{"all healthy", func() {}, "[0 1 2]"},
+		{"active failed", func() { f.failed(0) }, "[0 1 2]"},
+		{"failed skipped", func() { f.succeeded(1) }, "[1 2 0]"},
+		{"all failed", func() { f.failed(2); f.failed(1) }, "[1 0 2]"},
+		{"recovered", func() { f.succeeded(0) }, "[0 2 1]"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			test.change()
+			if order := fmt.Sprint(f.order()); order != test.expected {
+				t.Fatalf("unexpected order (%s), expected %s", order, test.expected)
+			}
+		})
+	}
+}
+
+func TestFailoverConfig(t *testing.T) {
+	tests := []struct {
+		id          string
+		cfg         *Config
+		expectedErr string
+	}{
+		{"invalid url", &Config{TokenKey: "abc123", FailoverURLs: []string{"http://[::1"}}, "parsing Circonus API failover URL"},
+		{"negative cooldown", &Config{TokenKey: "abc123", FailoverURLs: []string{"b.example.com"}, FailoverCooldown: -time.Second}, "invalid failover cooldown, must not be negative"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			_, err := New(test.cfg)
+			if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+				t.Fatalf("unexpected error (%v)", err)
+			}
+		})
+	}
+}
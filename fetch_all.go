@@ -0,0 +1,179 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Fetch all - parallel API reads with a bounded pool of workers
+
+package apiclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultFetchWorkers is the number of concurrent requests made by
+// FetchAll and FetchEach when none is configured
+const DefaultFetchWorkers = 4
+
+// FetchAllOptions defines how FetchAll and FetchEach fetch
+type FetchAllOptions struct {
+	// Workers defines the number of concurrent requests - default DefaultFetchWorkers
+	Workers int
+}
+
+// workers returns the number of workers for n fetches
+func (o *FetchAllOptions) workers(n int) int {
+	w := DefaultFetchWorkers
+	if o != nil && o.Workers > 0 {
+		w = o.Workers
+	}
+	if w > n {
+		w = n
+	}
+	return w
+}
+
+// FetchAll gets each of the passed paths (e.g. "/graph/123") using a pool of
+// workers and returns the responses, in the order of the paths. The first
+// failure stops the remaining fetches and is returned. While the API is
+// rate limiting requests, workers wait before starting further fetches.
+func (a *API) FetchAll(ctx context.Context, paths []string, opts *FetchAllOptions) ([][]byte, error) {
+	results := make([][]byte, len(paths))
+	err := a.FetchEach(ctx, len(paths), opts, func(ctx context.Context, i int) error {
+		data, err := a.apiRequestContext(ctx, "GET", paths[i], nil)
+		if err != nil {
+			return errors.Wrapf(err, "fetching %s", paths[i])
+		}
+		results[i] = data
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// FetchEach calls fetch for each index from 0 to n-1 using a pool of
+// workers, for fetches FetchAll cannot make (e.g. with the typed Fetch
+// methods). fetch is called concurrently, it should only write to results
+// by index. The first error stops the remaining fetches (the context passed
+// to fetch is canceled) and is returned. While the API is rate limiting
+// requests, workers wait before starting further fetches.
+func (a *API) FetchEach(ctx context.Context, n int, opts *FetchAllOptions, fetch func(ctx context.Context, i int) error) error {
+	if fetch == nil {
+		return errors.New("invalid fetch function (nil)")
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	indexes := make(chan int)
+	for w := opts.workers(n); w > 0; w-- {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := a.rateLimit.wait(ctx); err != nil {
+					fail(err)
+					continue
+				}
+				if err := fetch(ctx, i); err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	// canceled by the caller, before any fetch failed
+	return ctx.Err()
+}
+
+// rateLimitGate holds back fetches while the API is rate limiting requests,
+// it is shared by every copy of an API (see WithAccount)
+type rateLimitGate struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+// limited records a rate limited response, fetches wait until the time in
+// its Retry-After header (or minRetryWait if there is none) has passed
+func (g *rateLimitGate) limited(resp *http.Response) {
+	if g == nil {
+		return
+	}
+	wait := minRetryWait
+	if ra := resp.Header.Get("Retry-After"); ra != "" {
+		if secs, err := strconv.Atoi(ra); err == nil && secs >= 0 {
+			wait = time.Duration(secs) * time.Second
+		} else if t, err := http.ParseTime(ra); err == nil {
+			wait = time.Until(t)
+		}
+	}
+	if wait > maxRetryWait {
+		wait = maxRetryWait
+	}
+
+	until := time.Now().Add(wait)
+	g.mu.Lock()
+	if until.After(g.until) {
+		g.until = until
+	}
+	g.mu.Unlock()
+}
+
+// wait returns once the API is no longer rate limiting requests, or the
+// context is done
+func (g *rateLimitGate) wait(ctx context.Context) error {
+	if g == nil {
+		return ctx.Err()
+	}
+	for {
+		g.mu.Lock()
+		d := time.Until(g.until)
+		g.mu.Unlock()
+		if d <= 0 {
+			return ctx.Err()
+		}
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
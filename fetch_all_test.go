@@ -0,0 +1,205 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestFetchAll(t *testing.T) {
+	var active, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if r.URL.Path == "/graph/missing" {
+			w.WriteHeader(404)
+			fmt.Fprintln(w, `{"code":"404","message":"not found"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"_cid":%q}`, r.URL.Path)
+	}))
+	defer server.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	t.Run("in order", func(t *testing.T) {
+		atomic.StoreInt32(&peak, 0)
+		paths := []string{}
+		expected := [][]byte{}
+		for i := 1; i <= 12; i++ {
+			p := fmt.Sprintf("/graph/%d", i)
+			paths = append(paths, p)
+			expected = append(expected, []byte(fmt.Sprintf(`{"_cid":%q}`, p)))
+		}
+
+		results, err := apih.FetchAll(context.Background(), paths, &FetchAllOptions{Workers: 3})
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if !reflect.DeepEqual(results, expected) {
+			t.Fatalf("unexpected results (%q)", results)
+		}
+		if p := atomic.LoadInt32(&peak); p > 3 {
+			t.Fatalf("unexpected concurrent requests (%d)", p)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		_, err := apih.FetchAll(context.Background(), []string{"/graph/1", "/graph/missing", "/graph/3"}, nil)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if !strings.HasPrefix(err.Error(), "fetching /graph/missing: API response code 404") {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		results, err := apih.FetchAll(context.Background(), nil, nil)
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if len(results) != 0 {
+			t.Fatalf("unexpected results (%q)", results)
+		}
+	})
+}
+
+func TestFetchEach(t *testing.T) {
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: "http://localhost"})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	t.Run("stops on error", func(t *testing.T) {
+		var mu sync.Mutex
+		done := map[int]bool{}
+		err := apih.FetchEach(context.Background(), 100, &FetchAllOptions{Workers: 1}, func(_ context.Context, i int) error {
+			mu.Lock()
+			done[i] = true
+			mu.Unlock()
+			if i == 2 {
+				return errors.New("fetch failed")
+			}
+			return nil
+		})
+		if err == nil || err.Error() != "fetch failed" {
+			t.Fatalf("unexpected error (%v)", err)
+		}
+		if len(done) > 4 {
+			t.Fatalf("fetches not stopped (%d done)", len(done))
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := apih.FetchEach(ctx, 10, nil, func(_ context.Context, i int) error { return nil })
+		if err != context.Canceled {
+			t.Fatalf("unexpected error (%v)", err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if err := apih.FetchEach(context.Background(), 1, nil, nil); err == nil || err.Error() != "invalid fetch function (nil)" {
+			t.Fatalf("unexpected error (%v)", err)
+		}
+	})
+}
+
+func TestFetchAllRateLimit(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(429)
+			return
+		}
+		fmt.Fprintln(w, `{}`)
+	}))
+	defer server.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	acct, err := apih.WithAccount("/account/2")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if _, err := apih.Get("/graph/1"); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	// the rate limit is shared with the account copy, its fetches wait
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	apih.rateLimit.mu.Lock()
+	apih.rateLimit.until = time.Now().Add(time.Minute)
+	apih.rateLimit.mu.Unlock()
+	if _, err := acct.FetchAll(ctx, []string{"/graph/1"}, nil); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("unexpected calls (%d)", n)
+	}
+}
+
+func TestRateLimitGate(t *testing.T) {
+	tests := []struct {
+		id         string
+		retryAfter string
+		min, max   time.Duration
+	}{
+		{"default", "", minRetryWait - time.Second, minRetryWait},
+		{"seconds", "5", 4 * time.Second, 5 * time.Second},
+		{"date", time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat), 8 * time.Second, 10 * time.Second},
+		{"capped", "3600", maxRetryWait - time.Second, maxRetryWait},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			g := &rateLimitGate{}
+			resp := &http.Response{Header: http.Header{}}
+			if test.retryAfter != "" {
+				resp.Header.Set("Retry-After", test.retryAfter)
+			}
+			g.limited(resp)
+			wait := time.Until(g.until)
+			if wait < test.min || wait > test.max {
+				t.Fatalf("unexpected wait (%s)", wait)
+			}
+		})
+	}
+
+	var g *rateLimitGate
+	g.limited(&http.Response{Header: http.Header{}})
+	if err := g.wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package apiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// fuzzSeeds adds the test fixtures, as the client encodes them, the json
+// files in testdata (only a graph with overlay sets), and truncated copies of
+// them (as served by proxies cutting bodies short) to the corpus. Objects are
+// added alone and in a list, as returned by fetches and searches.
+//
+// Responses in the form the API returns them - with the fields only the API
+// sets, nulls, and fields the client does not decode - are in
+// testdata/fuzz/FuzzDecodeObject, one of each resource (alone, in a list,
+// and truncated). They are sanitized examples following the API reference,
+// with example.com hosts, documentation addresses, and placeholder ids, not
+// payloads captured from an account.
+func fuzzSeeds(f *testing.F, fixtures ...interface{}) {
+	f.Helper()
+	var payloads [][]byte
+	for _, v := range fixtures {
+		data, err := json.Marshal(v)
+		if err != nil {
+			f.Fatalf("unexpected error (%s)", err)
+		}
+		payloads = append(payloads, data, append(append([]byte("["), data...), ']'))
+	}
+	files, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	if err != nil {
+		f.Fatalf("unexpected error (%s)", err)
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			f.Fatalf("unexpected error (%s)", err)
+		}
+		payloads = append(payloads, data)
+	}
+
+	for _, data := range payloads {
+		f.Add(data)
+		f.Add(data[:len(data)/2])
+		f.Add(data[:len(data)-1])
+	}
+}
+
+// fuzzAPI returns the API responses are decoded with
+func fuzzAPI(f *testing.F) *API {
+	f.Helper()
+	a, err := New(&Config{TokenKey: "abc123", TokenApp: "test"})
+	if err != nil {
+		f.Fatalf("unexpected error (%s)", err)
+	}
+	return a
+}
+
+// fuzzDecode decodes data into v as API responses are decoded, and checks
+// that what decodes encodes again, and decodes to the same encoding
+func fuzzDecode(t *testing.T, a *API, data []byte, newV func() interface{}) {
+	v := newV()
+	if err := a.decodeJSON(bytes.NewReader(data), v); err != nil {
+		return
+	}
+	enc, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("decoded %T does not encode (%s)", v, err)
+	}
+	v2 := newV()
+	if err := json.Unmarshal(enc, v2); err != nil {
+		t.Fatalf("encoded %T does not decode (%s): %s", v, err, enc)
+	}
+	enc2, err := json.Marshal(v2)
+	if err != nil {
+		t.Fatalf("decoded %T does not encode (%s)", v2, err)
+	}
+	if !bytes.Equal(enc, enc2) {
+		t.Fatalf("%T does not round trip\n%s\n%s", v, enc, enc2)
+	}
+}
+
+func FuzzDecodeObject(f *testing.F) {
+	fuzzSeeds(f, testAccount, testAcknowledgement, testAlert, testAnnotation, testBroker,
+		testCheck, testCheckBundle, testCheckBundleMetrics, testCheckMove, testCheckTemplate,
+		testContactGroup, testDashboard, testGraph, testMaintenance, testMetric,
+		testMetricCluster, testOutlierReport, testProvisionBroker, testRuleSet,
+		testRuleSetGroup, testUser, testWorksheet)
+
+	prefixes := make([]string, 0, len(batchTypes))
+	for prefix := range batchTypes {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	a := fuzzAPI(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, prefix := range prefixes {
+			newObj := batchTypes[prefix]
+			fuzzDecode(t, a, data, newObj)
+			fuzzDecode(t, a, data, func() interface{} {
+				// a list of the objects, as returned by fetches and searches
+				return reflect.New(reflect.SliceOf(reflect.TypeOf(newObj()).Elem())).Interface()
+			})
+		}
+	})
+}
+
+func FuzzDecodeData(f *testing.F) {
+	fuzzSeeds(f)
+	f.Add([]byte(`{"_cid":"/data/1","data":[[1500000000,{"count":1,"value":1.5}],[1500000060,{"count":2}]]}`))
+	f.Add([]byte(`{"_cid":"/data/1","data":[[1500000000,{"H[1.0e+00]":3,"H[2.0e+01]":1}],[1500000060,300,"AAEKAAAB"]]}`))
+	f.Add([]byte(`{"_query":"metric:average(\"cpu\")","_start":1,"_end":2,"_period":60,"_meta":[],"_data":[[1500000000,[1.5,null]]]}`))
+
+	a := fuzzAPI(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fuzzDecode(t, a, data, func() interface{} { return &Data{} })
+		fuzzDecode(t, a, data, func() interface{} { return &HistogramData{} })
+		fuzzDecode(t, a, data, func() interface{} { return &CAQLResult{} })
+	})
+}
+
+func FuzzDecodeHistogram(f *testing.F) {
+	f.Add([]byte(`{"H[1.0e+00]":3,"H[2.0e+01]":1}`))
+	f.Add([]byte(`"AAEKAAAB"`))
+	f.Add([]byte(`null`))
+	f.Add([]byte(`{"H[1.0e+00]":3,`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		h, err := DecodeHistogram(data)
+		if err != nil || h == nil {
+			return
+		}
+		var buf bytes.Buffer
+		if err := h.Serialize(&buf); err != nil {
+			t.Fatalf("decoded histogram does not serialize (%s)", err)
+		}
+		if _, err := DeserializeHistogram(&buf); err != nil {
+			t.Fatalf("serialized histogram does not deserialize (%s)", err)
+		}
+	})
+}
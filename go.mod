@@ -3,7 +3,8 @@ module github.com/circonus-labs/go-apiclient
 require (
 	github.com/hashicorp/go-cleanhttp v0.5.1 // indirect
 	github.com/hashicorp/go-retryablehttp v0.5.4
-	github.com/pkg/errors v0.8.1
+	github.com/pkg/errors v0.9.1
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 go 1.13
@@ -0,0 +1,461 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package grafana converts Circonus graphs and dashboards to Grafana dashboard
+// JSON, with panels querying a Circonus (IRONdb) datasource with CAQL.
+//
+//	c, _ := grafana.New(&grafana.Config{API: apih, DatasourceUID: "circonus"})
+//	dash, _ := apih.FetchDashboard(&cid)
+//	g, warnings, _ := c.Dashboard(dash)
+//	out, _ := json.MarshalIndent(g, "", "  ")
+//
+// The conversion is best effort. Graph datapoints become CAQL queries, metric
+// datapoints found by check id and metric name. Graph widgets, gauges, charts,
+// and html widgets become panels; other widgets, metric clusters, guides, and
+// composites are not converted, the warnings returned describe what was
+// left out.
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultDatasourceType is the plugin id of the Circonus IRONdb datasource
+	DefaultDatasourceType = "circonus-irondb-datasource"
+	// DefaultDatasourceUID is the datasource uid used when none is configured
+	DefaultDatasourceUID = "circonus"
+
+	schemaVersion = 36
+	gridColumns   = 24 // width of the grafana grid
+	cellHeight    = 8  // height, in grafana grid units, of a circonus dashboard cell
+	queryTypeCAQL = "caql"
+)
+
+// dateWindow matches graph widget date windows grafana understands as a panel time range
+var dateWindow = regexp.MustCompile(`^\d+[mhdw]$`)
+
+// Config defines the converter configuration
+type Config struct {
+	// API is used to fetch the graphs shown by dashboard graph widgets,
+	// without it graph widgets are not converted
+	API *apiclient.API
+
+	// DatasourceType is the plugin id of the datasource queried - default DefaultDatasourceType
+	DatasourceType string
+
+	// DatasourceUID is the uid of the datasource queried - default DefaultDatasourceUID
+	DatasourceUID string
+
+	// Workers defines the number of graphs fetched concurrently - default
+	// apiclient.DefaultFetchWorkers
+	Workers int
+}
+
+// Converter converts graphs and dashboards
+type Converter struct {
+	api        *apiclient.API
+	datasource *DatasourceRef
+	graphs     map[string]*apiclient.Graph
+	workers    int
+}
+
+// New returns a Converter for the passed config.
+func New(cfg *Config) (*Converter, error) {
+	if cfg == nil {
+		return nil, errors.New("invalid grafana config (nil)")
+	}
+
+	ds := &DatasourceRef{Type: cfg.DatasourceType, UID: cfg.DatasourceUID}
+	if ds.Type == "" {
+		ds.Type = DefaultDatasourceType
+	}
+	if ds.UID == "" {
+		ds.UID = DefaultDatasourceUID
+	}
+
+	return &Converter{api: cfg.API, datasource: ds, graphs: make(map[string]*apiclient.Graph), workers: cfg.Workers}, nil
+}
+
+// Graph returns a dashboard with a single panel showing the graph, and
+// warnings describing what was not converted.
+func (c *Converter) Graph(g *apiclient.Graph) (*Dashboard, []string, error) {
+	if g == nil {
+		return nil, nil, errors.New("invalid graph (nil)")
+	}
+
+	var warnings []string
+	p := c.graphPanel(g, &warnings)
+	p.ID = 1
+	p.GridPos = GridPos{W: gridColumns, H: 2 * cellHeight}
+
+	d := c.dashboard(g.Title, g.Tags)
+	d.Description = g.Description
+	d.Panels = []Panel{p}
+	return d, warnings, nil
+}
+
+// Dashboard returns the dashboard converted, and warnings describing what
+// was not converted. The graphs of graph widgets are fetched with the API,
+// concurrently (see Config.Workers).
+func (c *Converter) Dashboard(cd *apiclient.Dashboard) (*Dashboard, []string, error) {
+	if cd == nil {
+		return nil, nil, errors.New("invalid dashboard (nil)")
+	}
+
+	columns := int(cd.GridLayout.Width)
+	if columns == 0 {
+		columns = 1
+	}
+
+	if err := c.fetchGraphs(cd.Widgets); err != nil {
+		return nil, nil, err
+	}
+
+	var warnings []string
+	d := c.dashboard(cd.Title, nil)
+	d.UID = cd.UUID
+	for _, w := range cd.Widgets {
+		p, err := c.widgetPanel(&w, &warnings)
+		if err != nil {
+			return nil, warnings, err
+		}
+		col, row, err := parseOrigin(w.Origin)
+		if err != nil {
+			return nil, warnings, errors.Wrapf(err, "widget %s", w.WidgetID)
+		}
+		width := int(w.Width)
+		if width == 0 {
+			width = 1
+		}
+		p.GridPos = GridPos{
+			X: col * gridColumns / columns,
+			Y: row * cellHeight,
+			W: width * gridColumns / columns,
+			H: int(w.Height) * cellHeight,
+		}
+		if p.GridPos.W == 0 {
+			p.GridPos.W = 1
+		}
+		if p.GridPos.H == 0 {
+			p.GridPos.H = cellHeight
+		}
+		d.Panels = append(d.Panels, *p)
+	}
+
+	sort.SliceStable(d.Panels, func(i, j int) bool {
+		a, b := d.Panels[i].GridPos, d.Panels[j].GridPos
+		return a.Y < b.Y || (a.Y == b.Y && a.X < b.X)
+	})
+	for i := range d.Panels {
+		d.Panels[i].ID = i + 1
+	}
+
+	return d, warnings, nil
+}
+
+// dashboard returns an empty dashboard
+func (c *Converter) dashboard(title string, tags []string) *Dashboard {
+	return &Dashboard{
+		Title:         title,
+		Tags:          append([]string{"circonus"}, tags...),
+		Timezone:      "browser",
+		SchemaVersion: schemaVersion,
+		Editable:      true,
+		Time:          TimeRange{From: "now-24h", To: "now"},
+		Panels:        []Panel{},
+	}
+}
+
+// widgetPanel returns the panel for a dashboard widget
+func (c *Converter) widgetPanel(w *apiclient.DashboardWidget, warnings *[]string) (*Panel, error) {
+	s := &w.Settings
+	title := s.Title
+	if title == "" {
+		title = w.Name
+	}
+
+	switch w.Type {
+	case "graph":
+		g, err := c.graph(s.GraphUUID)
+		if err != nil {
+			return nil, err
+		}
+		if g == nil {
+			*warnings = append(*warnings, fmt.Sprintf("widget %s: graph %s not converted (no API)", w.WidgetID, s.GraphUUID))
+			return c.placeholder(w), nil
+		}
+		p := c.graphPanel(g, warnings)
+		if s.Label != "" {
+			p.Title = s.Label
+		}
+		if dateWindow.MatchString(s.DateWindow) {
+			p.TimeFrom = s.DateWindow
+		}
+		return &p, nil
+	case "gauge", "text":
+		if s.CheckUUID == "" || s.MetricName == "" {
+			break
+		}
+		p := c.panel("stat", title)
+		if w.Type == "gauge" {
+			p.Type = "gauge"
+		}
+		query := fmt.Sprintf("metric:average(%s, %s)", strconv.Quote(s.CheckUUID), strconv.Quote(s.MetricName))
+		p.Targets = []Target{c.target("A", query, false)}
+		if s.RangeLow != nil || s.RangeHigh != nil {
+			p.FieldConfig = &FieldConfig{Overrides: []FieldOverride{}}
+			if s.RangeLow != nil {
+				min := float64(*s.RangeLow)
+				p.FieldConfig.Defaults.Min = &min
+			}
+			if s.RangeHigh != nil {
+				max := float64(*s.RangeHigh)
+				p.FieldConfig.Defaults.Max = &max
+			}
+		}
+		return p, nil
+	case "chart":
+		p := c.panel("bargauge", title)
+		if s.ChartType == "pie" {
+			p.Type = "piechart"
+		}
+		for _, dp := range s.Datapoints {
+			if dp.CheckID == 0 || dp.Metric == "" {
+				*warnings = append(*warnings, fmt.Sprintf("widget %s: metric cluster datapoint %q not converted", w.WidgetID, dp.ClusterTitle))
+				continue
+			}
+			query := metricQuery(dp.CheckID, dp.Metric, dp.MetricType, nil, dp.Label)
+			p.Targets = append(p.Targets, c.target(refID(len(p.Targets)), query, false))
+		}
+		return p, nil
+	case "html":
+		p := &Panel{
+			Type:    "text",
+			Title:   title,
+			Options: map[string]interface{}{"mode": "html", "content": s.Markup},
+		}
+		return p, nil
+	}
+
+	*warnings = append(*warnings, fmt.Sprintf("widget %s: %s widget not converted", w.WidgetID, w.Type))
+	return c.placeholder(w), nil
+}
+
+// fetchGraphs fetches the graphs of the graph widgets not already fetched, in parallel
+func (c *Converter) fetchGraphs(widgets []apiclient.DashboardWidget) error {
+	if c.api == nil {
+		return nil
+	}
+	var uuids []string
+	seen := map[string]bool{}
+	for _, w := range widgets {
+		uuid := w.Settings.GraphUUID
+		if w.Type != "graph" || uuid == "" || seen[uuid] || c.graphs[uuid] != nil {
+			continue
+		}
+		seen[uuid] = true
+		uuids = append(uuids, uuid)
+	}
+
+	graphs := make([]*apiclient.Graph, len(uuids))
+	err := c.api.FetchEach(context.Background(), len(uuids), &apiclient.FetchAllOptions{Workers: c.workers}, func(_ context.Context, i int) error {
+		cid := config.GraphPrefix + "/" + uuids[i]
+		g, err := c.api.FetchGraph(apiclient.CIDType(&cid))
+		if err != nil {
+			return err
+		}
+		graphs[i] = g
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for i, uuid := range uuids {
+		c.graphs[uuid] = graphs[i]
+	}
+	return nil
+}
+
+// graph returns the graph with the passed uuid, nil if there is no API to fetch it
+func (c *Converter) graph(uuid string) (*apiclient.Graph, error) {
+	if g, ok := c.graphs[uuid]; ok {
+		return g, nil
+	}
+	if c.api == nil {
+		return nil, nil
+	}
+	cid := config.GraphPrefix + "/" + uuid
+	g, err := c.api.FetchGraph(apiclient.CIDType(&cid))
+	if err != nil {
+		return nil, err
+	}
+	c.graphs[uuid] = g
+	return g, nil
+}
+
+// graphPanel returns a time series panel showing a graph
+func (c *Converter) graphPanel(g *apiclient.Graph, warnings *[]string) Panel {
+	p := c.panel("timeseries", g.Title)
+	p.Description = g.Description
+
+	fc := &FieldConfig{
+		Defaults: FieldDefaults{
+			Min:    g.MinLeftY,
+			Max:    g.MaxLeftY,
+			Custom: map[string]interface{}{"fillOpacity": 0, "lineInterpolation": "linear"},
+		},
+		Overrides: []FieldOverride{},
+	}
+	if g.Style != nil && *g.Style == "area" {
+		fc.Defaults.Custom["fillOpacity"] = 30
+	}
+	if g.LineStyle != nil && *g.LineStyle == "stepped" {
+		fc.Defaults.Custom["lineInterpolation"] = "stepAfter"
+	}
+	if g.LogLeftY != nil && *g.LogLeftY > 1 {
+		fc.Defaults.Custom["scaleDistribution"] = map[string]interface{}{"type": "log", "log": *g.LogLeftY}
+	}
+	if g.MinRightY != nil || g.MaxRightY != nil || g.LogRightY != nil {
+		*warnings = append(*warnings, fmt.Sprintf("graph %s: right axis scale not converted", g.CID))
+	}
+
+	for i, dp := range g.Datapoints {
+		var query string
+		switch {
+		case dp.CAQL != nil && *dp.CAQL != "":
+			query = *dp.CAQL
+			if dp.Name != "" {
+				query += fmt.Sprintf(" | label(%s)", strconv.Quote(dp.Name))
+			}
+		case dp.CheckID != 0 && dp.MetricName != "":
+			query = metricQuery(dp.CheckID, dp.MetricName, dp.MetricType, dp.Derive, dp.Name)
+		default:
+			*warnings = append(*warnings, fmt.Sprintf("graph %s: datapoint %q not converted", g.CID, dp.Name))
+			continue
+		}
+		if dp.DataFormula != nil && *dp.DataFormula != "" {
+			*warnings = append(*warnings, fmt.Sprintf("graph %s: data formula of datapoint %q not converted", g.CID, dp.Name))
+		}
+		p.Targets = append(p.Targets, c.target(refID(i), query, dp.Hidden))
+
+		var props []FieldProperty
+		if dp.Axis == "r" {
+			props = append(props, FieldProperty{ID: "custom.axisPlacement", Value: "right"})
+		}
+		if dp.Color != nil && *dp.Color != "" {
+			props = append(props, FieldProperty{ID: "color", Value: map[string]interface{}{"mode": "fixed", "fixedColor": *dp.Color}})
+		}
+		if dp.Stack != nil {
+			props = append(props, FieldProperty{ID: "custom.stacking", Value: map[string]interface{}{"mode": "normal", "group": fmt.Sprintf("stack%d", *dp.Stack)}})
+		}
+		if len(props) > 0 && dp.Name != "" {
+			fc.Overrides = append(fc.Overrides, FieldOverride{
+				Matcher:    FieldMatcher{ID: "byName", Options: dp.Name},
+				Properties: props,
+			})
+		}
+	}
+
+	for _, mc := range g.MetricClusters {
+		*warnings = append(*warnings, fmt.Sprintf("graph %s: metric cluster %q not converted", g.CID, mc.Name))
+	}
+	for _, gd := range g.Guides {
+		*warnings = append(*warnings, fmt.Sprintf("graph %s: guide %q not converted", g.CID, gd.Name))
+	}
+	for _, cp := range g.Composites {
+		*warnings = append(*warnings, fmt.Sprintf("graph %s: composite %q not converted", g.CID, cp.Name))
+	}
+
+	p.FieldConfig = fc
+	return *p
+}
+
+// panel returns a panel querying the datasource
+func (c *Converter) panel(panelType, title string) *Panel {
+	return &Panel{
+		Type:       panelType,
+		Title:      title,
+		Datasource: c.datasource,
+		Targets:    []Target{},
+	}
+}
+
+// placeholder returns a text panel standing in for a widget which was not converted
+func (c *Converter) placeholder(w *apiclient.DashboardWidget) *Panel {
+	return &Panel{
+		Type:    "text",
+		Title:   w.Name,
+		Options: map[string]interface{}{"mode": "markdown", "content": fmt.Sprintf("Circonus %s widget (not converted)", w.Type)},
+	}
+}
+
+// target returns a CAQL query
+func (c *Converter) target(ref, query string, hide bool) Target {
+	return Target{
+		RefID:      ref,
+		Datasource: c.datasource,
+		QueryType:  queryTypeCAQL,
+		Query:      query,
+		Hide:       hide,
+	}
+}
+
+// metricQuery returns a CAQL query finding a metric of a check. Counter and
+// derive datapoints query the rate of the metric.
+func metricQuery(checkID uint, metric, metricType string, derive interface{}, label string) string {
+	fn := "find"
+	switch {
+	case metricType == "histogram":
+		fn = "find:histogram"
+	case derive == "counter":
+		fn = "find:counter"
+	case derive == "derive":
+		fn = "find:derive"
+	}
+
+	query := fmt.Sprintf("%s(%s, %s)", fn, strconv.Quote(metric), strconv.Quote(fmt.Sprintf("and(__check_id:%d)", checkID)))
+	if label != "" {
+		query += fmt.Sprintf(" | label(%s)", strconv.Quote(label))
+	}
+	return query
+}
+
+// refID returns the query reference of the i'th query, A-Z then AA, AB...
+func refID(i int) string {
+	if i < 26 {
+		return string(rune('A' + i))
+	}
+	return refID(i/26-1) + refID(i%26)
+}
+
+// parseOrigin returns the column and row of a widget origin, e.g. 1 and 2 for b2
+func parseOrigin(origin string) (int, int, error) {
+	idx := strings.IndexFunc(origin, unicode.IsDigit)
+	if idx < 1 {
+		return 0, 0, errors.Errorf("invalid widget origin (%s)", origin)
+	}
+	col := 0
+	for _, r := range origin[:idx] {
+		if r < 'a' || r > 'z' {
+			return 0, 0, errors.Errorf("invalid widget origin (%s)", origin)
+		}
+		col = col*26 + int(r-'a') + 1
+	}
+	row, err := strconv.Atoi(origin[idx:])
+	if err != nil {
+		return 0, 0, errors.Errorf("invalid widget origin (%s)", origin)
+	}
+	return col - 1, row, nil
+}
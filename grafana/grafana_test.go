@@ -0,0 +1,187 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grafana
+
+import (
+	"reflect"
+	"testing"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
+)
+
+func testGraph() *apiclient.Graph {
+	caql := `metric:average("8f2b6f3a", "duration")`
+	color := "#33aa33"
+	style := "area"
+	stack := uint(0)
+	min := 0.0
+	return &apiclient.Graph{
+		CID:   "/graph/abc-123",
+		Title: "Web",
+		Style: &style,
+		Datapoints: []apiclient.GraphDatapoint{
+			{CAQL: &caql, Name: "latency", Axis: "l"},
+			{CheckID: 1234, MetricName: "requests", MetricType: "numeric", Derive: "counter", Name: "requests", Axis: "r", Color: &color, Stack: &stack},
+			{Name: "nothing"},
+		},
+		MetricClusters: []apiclient.GraphMetricCluster{{Name: "web cluster"}},
+		MinLeftY:       &min,
+		Tags:           []string{"service:web"},
+	}
+}
+
+func TestGraph(t *testing.T) {
+	c, err := New(&Config{DatasourceUID: "irondb"})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	d, warnings, err := c.Graph(testGraph())
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	expectedWarnings := []string{
+		`graph /graph/abc-123: datapoint "nothing" not converted`,
+		`graph /graph/abc-123: metric cluster "web cluster" not converted`,
+	}
+	if !reflect.DeepEqual(warnings, expectedWarnings) {
+		t.Fatalf("unexpected warnings (%v)", warnings)
+	}
+
+	if d.Title != "Web" || !reflect.DeepEqual(d.Tags, []string{"circonus", "service:web"}) || len(d.Panels) != 1 {
+		t.Fatalf("unexpected dashboard (%#v)", d)
+	}
+
+	p := d.Panels[0]
+	ds := &DatasourceRef{Type: DefaultDatasourceType, UID: "irondb"}
+	expectedTargets := []Target{
+		{RefID: "A", Datasource: ds, QueryType: "caql", Query: `metric:average("8f2b6f3a", "duration") | label("latency")`},
+		{RefID: "B", Datasource: ds, QueryType: "caql", Query: `find:counter("requests", "and(__check_id:1234)") | label("requests")`},
+	}
+	if !reflect.DeepEqual(p.Targets, expectedTargets) {
+		t.Fatalf("unexpected targets (%#v)", p.Targets)
+	}
+	if p.FieldConfig.Defaults.Custom["fillOpacity"] != 30 || *p.FieldConfig.Defaults.Min != 0 {
+		t.Fatalf("unexpected defaults (%#v)", p.FieldConfig.Defaults)
+	}
+	if len(p.FieldConfig.Overrides) != 1 || len(p.FieldConfig.Overrides[0].Properties) != 3 {
+		t.Fatalf("unexpected overrides (%#v)", p.FieldConfig.Overrides)
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	fake, err := fakecirconus.New(&fakecirconus.Config{
+		Fixtures: map[string]interface{}{"/graph/abc-123": testGraph()},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+	apih, err := apiclient.New(&apiclient.Config{TokenKey: "abc123", TokenApp: "test", URL: fake.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	high := 100
+	dash := &apiclient.Dashboard{
+		Title:      "Web",
+		UUID:       "0c5a1d2e-0000-4000-8000-000000000001",
+		GridLayout: apiclient.DashboardGridLayout{Width: 4, Height: 2},
+		Widgets: []apiclient.DashboardWidget{
+			{WidgetID: "w1", Type: "alerts", Name: "Alerts", Origin: "d1", Width: 1, Height: 1},
+			{WidgetID: "w2", Type: "graph", Name: "Graph", Origin: "a0", Width: 4, Height: 1, Settings: apiclient.DashboardWidgetSettings{GraphUUID: "abc-123", DateWindow: "2d"}},
+			{WidgetID: "w3", Type: "gauge", Name: "Gauge", Origin: "a1", Width: 1, Height: 1, Settings: apiclient.DashboardWidgetSettings{CheckUUID: "8f2b6f3a", MetricName: "duration", RangeHigh: &high}},
+			{WidgetID: "w4", Type: "html", Name: "HTML", Origin: "b1", Width: 2, Height: 1, Settings: apiclient.DashboardWidgetSettings{Markup: "<b>hi</b>"}},
+		},
+	}
+
+	c, err := New(&Config{API: apih})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	d, warnings, err := c.Dashboard(dash)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(warnings) != 3 || warnings[0] != "widget w1: alerts widget not converted" {
+		t.Fatalf("unexpected warnings (%v)", warnings)
+	}
+	if d.UID != dash.UUID {
+		t.Fatalf("unexpected uid (%s)", d.UID)
+	}
+
+	expected := []struct {
+		id    int
+		typ   string
+		title string
+		pos   GridPos
+	}{
+		{1, "timeseries", "Web", GridPos{X: 0, Y: 0, W: 24, H: 8}},
+		{2, "gauge", "Gauge", GridPos{X: 0, Y: 8, W: 6, H: 8}},
+		{3, "text", "HTML", GridPos{X: 6, Y: 8, W: 12, H: 8}},
+		{4, "text", "Alerts", GridPos{X: 18, Y: 8, W: 6, H: 8}},
+	}
+	if len(d.Panels) != len(expected) {
+		t.Fatalf("unexpected panels (%#v)", d.Panels)
+	}
+	for i, e := range expected {
+		p := d.Panels[i]
+		if p.ID != e.id || p.Type != e.typ || p.Title != e.title || p.GridPos != e.pos {
+			t.Fatalf("unexpected panel %d (%#v)", i, p)
+		}
+	}
+	if d.Panels[0].TimeFrom != "2d" {
+		t.Fatalf("unexpected time from (%s)", d.Panels[0].TimeFrom)
+	}
+	if q := d.Panels[1].Targets[0].Query; q != `metric:average("8f2b6f3a", "duration")` {
+		t.Fatalf("unexpected query (%s)", q)
+	}
+	if *d.Panels[1].FieldConfig.Defaults.Max != 100 {
+		t.Fatalf("unexpected max (%v)", d.Panels[1].FieldConfig.Defaults.Max)
+	}
+}
+
+func TestDashboardErrors(t *testing.T) {
+	c, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	tests := []struct {
+		id          string
+		dash        *apiclient.Dashboard
+		expectedErr string
+	}{
+		{"invalid (nil)", nil, "invalid dashboard (nil)"},
+		{"invalid (origin)", &apiclient.Dashboard{Widgets: []apiclient.DashboardWidget{{WidgetID: "w1", Type: "html", Origin: "1a"}}}, "widget w1: invalid widget origin (1a)"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			_, _, err := c.Dashboard(test.dash)
+			if err == nil {
+				t.Fatal("expected error")
+			} else if err.Error() != test.expectedErr {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+		})
+	}
+
+	if _, err := New(nil); err == nil || err.Error() != "invalid grafana config (nil)" {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+}
+
+func TestRefID(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 52: "BA"}
+	for i, expected := range tests {
+		if id := refID(i); id != expected {
+			t.Fatalf("%d: unexpected ref id (%s)", i, id)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grafana
+
+// Dashboard defines a Grafana dashboard, as imported (Dashboards > Import)
+// or posted to the Grafana dashboard API
+type Dashboard struct {
+	ID            *int      `json:"id"` // null, assigned by Grafana
+	UID           string    `json:"uid,omitempty"`
+	Title         string    `json:"title"`
+	Description   string    `json:"description,omitempty"`
+	Tags          []string  `json:"tags"`
+	Timezone      string    `json:"timezone"`
+	SchemaVersion int       `json:"schemaVersion"`
+	Editable      bool      `json:"editable"`
+	Time          TimeRange `json:"time"`
+	Panels        []Panel   `json:"panels"`
+}
+
+// TimeRange defines the time range of a dashboard, e.g. now-24h to now
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// GridPos defines the position and size of a panel, in grid units (24 wide)
+type GridPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// DatasourceRef refers to a Grafana datasource
+type DatasourceRef struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+// Panel defines a dashboard panel
+type Panel struct {
+	ID          int                    `json:"id"`
+	Type        string                 `json:"type"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description,omitempty"`
+	GridPos     GridPos                `json:"gridPos"`
+	Datasource  *DatasourceRef         `json:"datasource,omitempty"`
+	Targets     []Target               `json:"targets,omitempty"`
+	TimeFrom    string                 `json:"timeFrom,omitempty"` // e.g. 1d, overrides the dashboard time range
+	FieldConfig *FieldConfig           `json:"fieldConfig,omitempty"`
+	Options     map[string]interface{} `json:"options,omitempty"`
+}
+
+// Target defines a panel query, a CAQL statement for the Circonus datasource
+type Target struct {
+	RefID      string         `json:"refId"`
+	Datasource *DatasourceRef `json:"datasource,omitempty"`
+	QueryType  string         `json:"querytype"`
+	Query      string         `json:"query"`
+	Hide       bool           `json:"hide,omitempty"`
+}
+
+// FieldConfig defines how the fields (series) of a panel are displayed
+type FieldConfig struct {
+	Defaults  FieldDefaults   `json:"defaults"`
+	Overrides []FieldOverride `json:"overrides"`
+}
+
+// FieldDefaults defines the display of all fields
+type FieldDefaults struct {
+	Min    *float64               `json:"min,omitempty"`
+	Max    *float64               `json:"max,omitempty"`
+	Custom map[string]interface{} `json:"custom,omitempty"`
+}
+
+// FieldOverride changes the display of the fields matched
+type FieldOverride struct {
+	Matcher    FieldMatcher    `json:"matcher"`
+	Properties []FieldProperty `json:"properties"`
+}
+
+// FieldMatcher selects fields, e.g. byName
+type FieldMatcher struct {
+	ID      string      `json:"id"`
+	Options interface{} `json:"options"`
+}
+
+// FieldProperty defines a display property, e.g. custom.axisPlacement
+type FieldProperty struct {
+	ID    string      `json:"id"`
+	Value interface{} `json:"value"`
+}
@@ -8,10 +8,8 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
-	"regexp"
 	"strings"
 
 	"github.com/circonus-labs/go-apiclient/config"
@@ -174,6 +172,12 @@ func NewGraph() *Graph {
 
 // FetchGraph retrieves graph with passed cid.
 func (a *API) FetchGraph(cid CIDType) (*Graph, error) {
+	return a.FetchGraphWithContext(context.Background(), cid)
+}
+
+// FetchGraphWithContext retrieves graph with passed cid, stopping when ctx is
+// done.
+func (a *API) FetchGraphWithContext(ctx context.Context, cid CIDType) (*Graph, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.New("invalid graph CID (none)")
 	}
@@ -185,24 +189,20 @@ func (a *API) FetchGraph(cid CIDType) (*Graph, error) {
 		graphCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.GraphCIDRegex, graphCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.GraphPrefix, graphCID) {
 		return nil, errors.Errorf("invalid graph CID (%s)", graphCID)
 	}
 
-	result, err := a.Get(graphCID)
+	result, err := a.GetWithContext(ctx, graphCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching graph")
 	}
-	if a.Debug {
-		a.Log.Printf("fetch graph, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch graph, received JSON: %s", string(result))
 	}
 
 	graph := new(Graph)
-	if err := json.Unmarshal(result, graph); err != nil {
+	if err := a.unmarshalJSON(result, graph); err != nil {
 		return nil, errors.Wrap(err, "parsing graph")
 	}
 
@@ -211,14 +211,15 @@ func (a *API) FetchGraph(cid CIDType) (*Graph, error) {
 
 // FetchGraphs retrieves all graphs available to the API Token.
 func (a *API) FetchGraphs() (*[]Graph, error) {
-	result, err := a.Get(config.GraphPrefix)
-	if err != nil {
-		return nil, errors.Wrap(err, "fetching graphs")
-	}
+	return a.FetchGraphsWithContext(context.Background())
+}
 
+// FetchGraphsWithContext retrieves all graphs available to the API Token,
+// stopping when ctx is done.
+func (a *API) FetchGraphsWithContext(ctx context.Context) (*[]Graph, error) {
 	var graphs []Graph
-	if err := json.Unmarshal(result, &graphs); err != nil {
-		return nil, errors.Wrap(err, "parsing graphs")
+	if err := a.getJSONContext(ctx, config.GraphPrefix, &graphs); err != nil {
+		return nil, errors.Wrap(err, "fetching graphs")
 	}
 
 	return &graphs, nil
@@ -226,36 +227,37 @@ func (a *API) FetchGraphs() (*[]Graph, error) {
 
 // UpdateGraph updates passed graph.
 func (a *API) UpdateGraph(cfg *Graph) (*Graph, error) {
+	return a.UpdateGraphWithContext(context.Background(), cfg)
+}
+
+// UpdateGraphWithContext updates passed graph, stopping when ctx is done.
+func (a *API) UpdateGraphWithContext(ctx context.Context, cfg *Graph) (*Graph, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid graph config (nil)")
 	}
 
 	graphCID := cfg.CID
 
-	matched, err := regexp.MatchString(config.GraphCIDRegex, graphCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.GraphPrefix, graphCID) {
 		return nil, errors.Errorf("invalid graph CID (%s)", graphCID)
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("update graph, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("update graph, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Put(graphCID, jsonCfg)
+	result, err := a.PutWithContext(ctx, graphCID, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "updating graph")
 	}
 
 	graph := &Graph{}
-	if err := json.Unmarshal(result, graph); err != nil {
+	if err := a.unmarshalJSON(result, graph); err != nil {
 		return nil, errors.Wrap(err, "parsing graph")
 	}
 
@@ -264,26 +266,31 @@ func (a *API) UpdateGraph(cfg *Graph) (*Graph, error) {
 
 // CreateGraph creates a new graph.
 func (a *API) CreateGraph(cfg *Graph) (*Graph, error) {
+	return a.CreateGraphWithContext(context.Background(), cfg)
+}
+
+// CreateGraphWithContext creates a new graph, stopping when ctx is done.
+func (a *API) CreateGraphWithContext(ctx context.Context, cfg *Graph) (*Graph, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid graph config (nil)")
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("update graph, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("update graph, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Post(config.GraphPrefix, jsonCfg)
+	result, err := a.PostWithContext(ctx, config.GraphPrefix, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "creating graph")
 	}
 
 	graph := &Graph{}
-	if err := json.Unmarshal(result, graph); err != nil {
+	if err := a.unmarshalJSON(result, graph); err != nil {
 		return nil, errors.Wrap(err, "parsing graph")
 	}
 
@@ -292,14 +299,25 @@ func (a *API) CreateGraph(cfg *Graph) (*Graph, error) {
 
 // DeleteGraph deletes passed graph.
 func (a *API) DeleteGraph(cfg *Graph) (bool, error) {
+	return a.DeleteGraphWithContext(context.Background(), cfg)
+}
+
+// DeleteGraphWithContext deletes passed graph, stopping when ctx is done.
+func (a *API) DeleteGraphWithContext(ctx context.Context, cfg *Graph) (bool, error) {
 	if cfg == nil {
 		return false, errors.New("invalid graph config (nil)")
 	}
-	return a.DeleteGraphByCID(CIDType(&cfg.CID))
+	return a.DeleteGraphByCIDWithContext(ctx, CIDType(&cfg.CID))
 }
 
 // DeleteGraphByCID deletes graph with passed cid.
 func (a *API) DeleteGraphByCID(cid CIDType) (bool, error) {
+	return a.DeleteGraphByCIDWithContext(context.Background(), cid)
+}
+
+// DeleteGraphByCIDWithContext deletes graph with passed cid, stopping when
+// ctx is done.
+func (a *API) DeleteGraphByCIDWithContext(ctx context.Context, cid CIDType) (bool, error) {
 	if cid == nil || *cid == "" {
 		return false, errors.New("invalid graph CID (none)")
 	}
@@ -311,15 +329,11 @@ func (a *API) DeleteGraphByCID(cid CIDType) (bool, error) {
 		graphCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.GraphCIDRegex, graphCID)
-	if err != nil {
-		return false, err
-	}
-	if !matched {
+	if !validCID(config.GraphPrefix, graphCID) {
 		return false, errors.Errorf("invalid graph CID (%s)", graphCID)
 	}
 
-	_, err = a.Delete(graphCID)
+	_, err := a.DeleteWithContext(ctx, graphCID)
 	if err != nil {
 		return false, errors.Wrap(err, "deleting graph")
 	}
@@ -331,37 +345,19 @@ func (a *API) DeleteGraphByCID(cid CIDType) (bool, error) {
 // and/or filter. If nil is passed for both parameters all graphs
 // will be returned.
 func (a *API) SearchGraphs(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Graph, error) {
-	q := url.Values{}
-
-	if searchCriteria != nil && *searchCriteria != "" {
-		q.Set("search", string(*searchCriteria))
-	}
-
-	if filterCriteria != nil && len(*filterCriteria) > 0 {
-		for filter, criteria := range *filterCriteria {
-			for _, val := range criteria {
-				q.Add(filter, val)
-			}
-		}
-	}
-
-	if q.Encode() == "" {
-		return a.FetchGraphs()
-	}
-
-	reqURL := url.URL{
-		Path:     config.GraphPrefix,
-		RawQuery: q.Encode(),
-	}
+	return a.SearchGraphsWithContext(context.Background(), searchCriteria, filterCriteria)
+}
 
-	result, err := a.Get(reqURL.String())
-	if err != nil {
-		return nil, errors.Wrap(err, "searching graphs")
+// SearchGraphsWithContext returns graphs matching the specified search query
+// and/or filter, stopping when ctx is done.
+func (a *API) SearchGraphsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Graph, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchGraphsWithContext(ctx)
 	}
 
 	var graphs []Graph
-	if err := json.Unmarshal(result, &graphs); err != nil {
-		return nil, errors.Wrap(err, "parsing graphs")
+	if err := a.searchJSONContext(ctx, config.GraphPrefix, searchCriteria, filterCriteria, &graphs); err != nil {
+		return nil, errors.Wrap(err, "searching graphs")
 	}
 
 	return &graphs, nil
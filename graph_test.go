@@ -5,13 +5,18 @@
 package apiclient
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
 )
 
 var (
@@ -224,6 +229,48 @@ func TestFetchGraphs(t *testing.T) {
 	}
 }
 
+func TestFetchGraphWithContext(t *testing.T) {
+	fake, err := fakecirconus.New(&fakecirconus.Config{Fixtures: map[string]interface{}{
+		"/graph/01234567-89ab-cdef-0123-456789abcdef": testGraph,
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", URL: fake.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	cid := "01234567-89ab-cdef-0123-456789abcdef"
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := apih.FetchGraphWithContext(cancelled, CIDType(&cid)); err == nil || !strings.Contains(err.Error(), "context canceled") {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+
+	fake.SetLatency(200 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := apih.FetchGraphWithContext(ctx, CIDType(&cid)); err == nil || !strings.Contains(err.Error(), "context deadline exceeded") {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Fatalf("expected fetch stopped when ctx done (%s)", elapsed)
+	}
+
+	fake.SetLatency(0)
+	graph, err := apih.FetchGraph(CIDType(&cid))
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if graph.CID != testGraph.CID {
+		t.Fatalf("unexpected graph (%s)", graph.CID)
+	}
+}
+
 func TestUpdateGraph(t *testing.T) {
 	apih, server := graphTestBootstrap(t)
 	defer server.Close()
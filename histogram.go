@@ -0,0 +1,346 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Histogram support - decode log-linear (circllhist) histograms in data and caql results
+
+package apiclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// HistogramBin defines a log-linear histogram bin. The bin covers the values
+// from val/10 * 10^exp (inclusive) to (val+1)/10 * 10^exp (exclusive), where val
+// is in [10,99] (or [-99,-10] for negative values). A val of 0 is the zero bin.
+type HistogramBin struct {
+	Val int8
+	Exp int8
+}
+
+// Lower returns the bound of the bin closest to zero.
+func (b HistogramBin) Lower() float64 {
+	if b.Val == 0 {
+		return 0
+	}
+	return float64(b.Val) / 10 * math.Pow10(int(b.Exp))
+}
+
+// Width returns the width of the bin.
+func (b HistogramBin) Width() float64 {
+	if b.Val == 0 {
+		return 0
+	}
+	return math.Pow10(int(b.Exp)) / 10
+}
+
+// Midpoint returns the value in the middle of the bin.
+func (b HistogramBin) Midpoint() float64 {
+	if b.Val < 0 {
+		return b.Lower() - b.Width()/2
+	}
+	return b.Lower() + b.Width()/2
+}
+
+// bounds returns the bin range as (left, right)
+func (b HistogramBin) bounds() (float64, float64) {
+	if b.Val < 0 {
+		return b.Lower() - b.Width(), b.Lower()
+	}
+	return b.Lower(), b.Lower() + b.Width()
+}
+
+// String returns the bin in circllhist text form (e.g. "H[1.2e+00]").
+func (b HistogramBin) String() string {
+	if b.Val == 0 {
+		return "H[0]"
+	}
+	return "H[" + strconv.FormatFloat(float64(b.Val)/10, 'f', 1, 64) + "e" + expString(int(b.Exp)) + "]"
+}
+
+func expString(exp int) string {
+	sign := "+"
+	if exp < 0 {
+		sign = "-"
+		exp = -exp
+	}
+	s := strconv.Itoa(exp)
+	if len(s) < 2 {
+		s = "0" + s
+	}
+	return sign + s
+}
+
+// histogramBinFor returns the bin containing the passed value
+func histogramBinFor(v float64) (HistogramBin, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return HistogramBin{}, errors.Errorf("invalid histogram value (%v)", v)
+	}
+	if v == 0 {
+		return HistogramBin{}, nil
+	}
+
+	sign := 1
+	if v < 0 {
+		sign = -1
+		v = -v
+	}
+	exp := int(math.Floor(math.Log10(v)))
+	val := int(math.Floor(v/math.Pow10(exp)*10 + 1e-9))
+	if val >= 100 { // floating point rounding at a power of ten
+		val /= 10
+		exp++
+	}
+	if exp < -128 || exp > 127 {
+		return HistogramBin{}, errors.Errorf("invalid histogram value (%v), out of range", v)
+	}
+
+	return HistogramBin{Val: int8(sign * val), Exp: int8(exp)}, nil
+}
+
+// Histogram defines a log-linear histogram, as found in histogram metric data
+// and caql results.
+type Histogram struct {
+	bins map[HistogramBin]uint64
+}
+
+// NewHistogram returns an empty histogram.
+func NewHistogram() *Histogram {
+	return &Histogram{bins: make(map[HistogramBin]uint64)}
+}
+
+// Record adds count samples of the passed value to the histogram.
+func (h *Histogram) Record(v float64, count uint64) error {
+	bin, err := histogramBinFor(v)
+	if err != nil {
+		return err
+	}
+	h.add(bin, count)
+	return nil
+}
+
+func (h *Histogram) add(bin HistogramBin, count uint64) {
+	if h.bins == nil {
+		h.bins = make(map[HistogramBin]uint64)
+	}
+	if count > 0 {
+		h.bins[bin] += count
+	}
+}
+
+// Bins returns the non-empty bins of the histogram, ordered by value.
+func (h *Histogram) Bins() []HistogramBin {
+	bins := make([]HistogramBin, 0, len(h.bins))
+	for b := range h.bins {
+		bins = append(bins, b)
+	}
+	sort.Slice(bins, func(i, j int) bool {
+		li, _ := bins[i].bounds()
+		lj, _ := bins[j].bounds()
+		return li < lj
+	})
+	return bins
+}
+
+// BinCount returns the number of samples in the passed bin.
+func (h *Histogram) BinCount(b HistogramBin) uint64 {
+	return h.bins[b]
+}
+
+// Count returns the total number of samples in the histogram.
+func (h *Histogram) Count() uint64 {
+	var n uint64
+	for _, c := range h.bins {
+		n += c
+	}
+	return n
+}
+
+// Merge adds the samples of the passed histograms to the histogram.
+func (h *Histogram) Merge(others ...*Histogram) {
+	for _, o := range others {
+		if o == nil {
+			continue
+		}
+		for b, c := range o.bins {
+			h.add(b, c)
+		}
+	}
+}
+
+// Mean returns the approximate mean of the samples, using bin midpoints.
+func (h *Histogram) Mean() float64 {
+	var sum float64
+	var n uint64
+	for b, c := range h.bins {
+		sum += b.Midpoint() * float64(c)
+		n += c
+	}
+	if n == 0 {
+		return math.NaN()
+	}
+	return sum / float64(n)
+}
+
+// Quantile returns the approximate value at quantile q (0 <= q <= 1), assuming
+// samples are uniformly distributed within each bin.
+func (h *Histogram) Quantile(q float64) (float64, error) {
+	if q < 0 || q > 1 || math.IsNaN(q) {
+		return 0, errors.Errorf("invalid quantile (%v)", q)
+	}
+	total := h.Count()
+	if total == 0 {
+		return 0, errors.New("invalid histogram (empty)")
+	}
+
+	target := q * float64(total)
+	var seen float64
+	bins := h.Bins()
+	for _, b := range bins {
+		c := float64(h.bins[b])
+		if seen+c >= target {
+			left, right := b.bounds()
+			return left + (right-left)*(target-seen)/c, nil
+		}
+		seen += c
+	}
+
+	_, right := bins[len(bins)-1].bounds()
+	return right, nil
+}
+
+// DecodeHistogram decodes a histogram in any of the encodings used by the API:
+// a JSON object of bin => count (bins in circllhist text form "H[1.2e+00]" or as
+// numbers), or a JSON string holding a base64 encoded binary circllhist.
+func DecodeHistogram(raw json.RawMessage) (*Histogram, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+
+	switch raw[0] {
+	case '{':
+		var bins map[string]uint64
+		if err := json.Unmarshal(raw, &bins); err != nil {
+			return nil, errors.Wrap(err, "parsing histogram")
+		}
+		h := NewHistogram()
+		for k, c := range bins {
+			s := strings.TrimSuffix(strings.TrimPrefix(k, "H["), "]")
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, errors.Errorf("invalid histogram bin (%s)", k)
+			}
+			if err := h.Record(v, c); err != nil {
+				return nil, err
+			}
+		}
+		return h, nil
+	case '"':
+		var enc string
+		if err := json.Unmarshal(raw, &enc); err != nil {
+			return nil, errors.Wrap(err, "parsing histogram")
+		}
+		b, err := base64.StdEncoding.DecodeString(enc)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding histogram")
+		}
+		return DeserializeHistogram(bytes.NewReader(b))
+	}
+
+	return nil, errors.Errorf("invalid histogram encoding (%s)", string(raw))
+}
+
+// DeserializeHistogram reads a binary circllhist: a big-endian uint16 bin count,
+// then for each bin the int8 val, int8 exp, a uint8 holding the count width
+// minus one, and the big-endian count.
+func DeserializeHistogram(r io.Reader) (*Histogram, error) {
+	var nbins uint16
+	if err := binary.Read(r, binary.BigEndian, &nbins); err != nil {
+		return nil, errors.Wrap(err, "reading histogram bin count")
+	}
+
+	h := NewHistogram()
+	for i := 0; i < int(nbins); i++ {
+		var hdr [3]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return nil, errors.Wrapf(err, "reading histogram bin %d", i)
+		}
+		width := int(hdr[2]) + 1
+		if width > 8 {
+			return nil, errors.Errorf("invalid histogram bin %d, count width (%d)", i, width)
+		}
+		cb := make([]byte, width)
+		if _, err := io.ReadFull(r, cb); err != nil {
+			return nil, errors.Wrapf(err, "reading histogram bin %d count", i)
+		}
+		var count uint64
+		for _, b := range cb {
+			count = count<<8 | uint64(b)
+		}
+		bin := HistogramBin{Val: int8(hdr[0]), Exp: int8(hdr[1])}
+		if bin.Val == 0 {
+			bin.Exp = 0 // the zero bin has no exponent
+		} else if bin.Val > -10 && bin.Val < 10 || bin.Val > 99 || bin.Val < -99 {
+			return nil, errors.Errorf("invalid histogram bin %d, val (%d)", i, bin.Val)
+		}
+		h.add(bin, count)
+	}
+
+	return h, nil
+}
+
+// Serialize writes the histogram in binary circllhist form, see DeserializeHistogram.
+func (h *Histogram) Serialize(w io.Writer) error {
+	bins := h.Bins()
+	if err := binary.Write(w, binary.BigEndian, uint16(len(bins))); err != nil {
+		return err
+	}
+	for _, b := range bins {
+		count := h.bins[b]
+		width := 1
+		for c := count >> 8; c > 0; c >>= 8 {
+			width++
+		}
+		buf := []byte{byte(b.Val), byte(b.Exp), byte(width - 1)}
+		for i := width - 1; i >= 0; i-- {
+			buf = append(buf, byte(count>>(8*uint(i))))
+		}
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Histograms decodes the values of the passed output series of a caql result as
+// histograms. Rows without a value for the series are returned as nil.
+func (r *CAQLResult) Histograms(series int) ([]*Histogram, error) {
+	if series < 0 || series >= r.NumSeries() {
+		return nil, errors.Errorf("invalid caql series (%d)", series)
+	}
+
+	hists := make([]*Histogram, len(r.Rows))
+	for i, row := range r.Rows {
+		if series >= len(row.Values) {
+			continue
+		}
+		h, err := DecodeHistogram(row.Values[series])
+		if err != nil {
+			return nil, errors.Wrapf(err, "caql series %d, row %d", series, i)
+		}
+		hists[i] = h
+	}
+
+	return hists, nil
+}
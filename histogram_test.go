@@ -0,0 +1,209 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHistogramBin(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{0, "H[0]"},
+		{1, "H[1.0e+00]"},
+		{1.23, "H[1.2e+00]"},
+		{100, "H[1.0e+02]"},
+		{0.0045, "H[4.5e-03]"},
+		{-27, "H[-2.7e+01]"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.expected, func(t *testing.T) {
+			b, err := histogramBinFor(test.value)
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if b.String() != test.expected {
+				t.Fatalf("expected %s, got %s", test.expected, b.String())
+			}
+		})
+	}
+
+	if _, err := histogramBinFor(math.NaN()); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	h := NewHistogram()
+	if _, err := h.Quantile(0.5); err == nil {
+		t.Fatal("expected error (empty)")
+	}
+
+	for v := 1; v <= 100; v++ {
+		if err := h.Record(float64(v), 1); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+	}
+	if h.Count() != 100 {
+		t.Fatalf("expected 100, got %d", h.Count())
+	}
+
+	tests := []struct {
+		q        float64
+		min, max float64
+	}{
+		{0, 1, 1},
+		{0.5, 49, 51},
+		{0.99, 98, 100},
+		{1, 100, 110},
+	}
+
+	for _, test := range tests {
+		v, err := h.Quantile(test.q)
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if v < test.min || v > test.max {
+			t.Fatalf("q%v: expected [%v, %v], got %v", test.q, test.min, test.max, v)
+		}
+	}
+
+	if _, err := h.Quantile(1.5); err == nil {
+		t.Fatal("expected error")
+	}
+	if m := h.Mean(); m < 50 || m > 52 {
+		t.Fatalf("unexpected mean (%v)", m)
+	}
+}
+
+func TestHistogramMergeAndSerialize(t *testing.T) {
+	a, b := NewHistogram(), NewHistogram()
+	a.Record(1.2, 3)
+	b.Record(1.25, 2)
+	b.Record(300, 70000)
+	a.Merge(b, nil)
+
+	if a.Count() != 70005 {
+		t.Fatalf("expected 70005, got %d", a.Count())
+	}
+	if c := a.BinCount(HistogramBin{Val: 12, Exp: 0}); c != 5 {
+		t.Fatalf("expected 5 in H[1.2e+00], got %d", c)
+	}
+
+	var buf bytes.Buffer
+	if err := a.Serialize(&buf); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	enc, _ := json.Marshal(base64.StdEncoding.EncodeToString(buf.Bytes()))
+
+	h, err := DecodeHistogram(enc)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if h.Count() != a.Count() || h.BinCount(HistogramBin{Val: 30, Exp: 2}) != 70000 {
+		t.Fatalf("unexpected round trip (%v)", h.bins)
+	}
+}
+
+func TestDecodeHistogram(t *testing.T) {
+	tests := []struct {
+		id         string
+		raw        string
+		count      uint64
+		shouldFail bool
+	}{
+		{"null", `null`, 0, false},
+		{"text bins", `{"H[1.2e+00]": 3, "H[4.5e-03]": 2}`, 5, false},
+		{"numeric bins", `{"+12e-001": 1, "0": 4}`, 5, false},
+		{"invalid bin", `{"H[foo]": 1}`, 0, true},
+		{"invalid encoding", `12`, 0, true},
+		{"invalid base64", `"!!"`, 0, true},
+		{"binary zero bin", `"AAEABQAC"`, 2, false},
+		{"invalid binary bin", `"AAEFAAAB"`, 0, true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			h, err := DecodeHistogram(json.RawMessage(test.raw))
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if h == nil {
+				if test.count != 0 {
+					t.Fatal("expected histogram")
+				}
+				return
+			}
+			if h.Count() != test.count {
+				t.Fatalf("expected %d, got %d", test.count, h.Count())
+			}
+		})
+	}
+}
+
+var testHistogramDataJSON = json.RawMessage(`{
+	"_cid": "/data/1234_lat",
+	"data": [
+		[1483033000, 300, {"H[1.2e+00]": 3}],
+		[1483033300, null],
+		[1483033600, {"H[2.0e+01]": 1}]
+	]
+}`)
+
+func TestFetchHistogramData(t *testing.T) {
+	apih, server := dataTestBootstrap(t)
+	defer server.Close()
+
+	cid := "1234"
+	start := time.Unix(1483033000, 0)
+	data, err := apih.FetchHistogramData(CIDType(&cid), "lat", start, start.Add(10*time.Minute), 5*time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(data.Points) != 3 || data.Points[1].Histogram != nil {
+		t.Fatalf("unexpected points (%#v)", data.Points)
+	}
+	if n := data.Merged().Count(); n != 4 {
+		t.Fatalf("expected 4 samples, got %d", n)
+	}
+}
+
+func TestCAQLHistograms(t *testing.T) {
+	result := &CAQLResult{}
+	if err := json.Unmarshal([]byte(`{
+		"_meta": [{"kind": "histogram", "label": "lat"}],
+		"_data": [[1483033000, [{"H[1.2e+00]": 3}]], [1483033060, [null]]]
+	}`), result); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if _, err := result.Histograms(1); err == nil {
+		t.Fatal("expected error")
+	}
+
+	hists, err := result.Histograms(0)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(hists) != 2 || hists[0].Count() != 3 || hists[1] != nil {
+		t.Fatalf("unexpected histograms (%#v)", hists)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Hooks - request lifecycle callbacks (see Config OnRequest, OnRetry,
+// OnResponse, and OnCall)
+
+package apiclient
+
+import (
+	"net/http"
+	"time"
+)
+
+// CallInfo describes a completed API call, see Config OnCall
+type CallInfo struct {
+	Method      string
+	Path        string // e.g. /graph/1234
+	Status      int    // of the last response, 0 if none was received
+	Attempts    int    // requests made, retries included
+	RateLimited int    // responses refusing a request for the rate limit (429)
+	Elapsed     time.Duration
+	Err         error // the error of the call, nil if it succeeded
+}
+
+// requestHooks holds the lifecycle callbacks configured for an API
+type requestHooks struct {
+	onRequest  func(method, path string)
+	onRetry    func(attempt int, err error, delay time.Duration)
+	onResponse func(method, path string, status int, elapsed time.Duration, err error)
+}
+
+// observing returns true if requests or responses are observed
+func (h requestHooks) observing() bool {
+	return h.onRequest != nil || h.onResponse != nil
+}
+
+// retry reports the failure of attempt, retried after delay
+func (h requestHooks) retry(attempt int, err error, delay time.Duration) {
+	if h.onRetry != nil {
+		h.onRetry(attempt, err, delay)
+	}
+}
+
+// hookedTransport calls the request and response hooks around each attempt
+// of an API call, with the path of the call (e.g. /graph/1234)
+type hookedTransport struct {
+	next  http.RoundTripper
+	hooks requestHooks
+	path  string
+}
+
+// RoundTrip calls the hooks around the next transport's RoundTrip
+func (t *hookedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.hooks.onRequest != nil {
+		t.hooks.onRequest(req.Method, t.path)
+	}
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if t.hooks.onResponse != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		t.hooks.onResponse(req.Method, t.path, status, time.Since(start), err)
+	}
+	return resp, err
+}
@@ -0,0 +1,169 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequestHooks(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// every other request fails
+		if atomic.AddInt32(&calls, 1)%2 == 1 {
+			w.WriteHeader(500)
+			fmt.Fprintln(w, "unavailable")
+			return
+		}
+		fmt.Fprintln(w, `{}`)
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var events []string
+	record := func(format string, v ...interface{}) {
+		mu.Lock()
+		events = append(events, fmt.Sprintf(format, v...))
+		mu.Unlock()
+	}
+	apih, err := New(&Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      server.URL,
+		OnRequest: func(method, path string) {
+			record("request %s %s", method, path)
+		},
+		OnRetry: func(attempt int, err error, delay time.Duration) {
+			record("retry %d %t %t", attempt, strings.Contains(err.Error(), "500 unavailable"), delay >= minRetryWait)
+		},
+		OnResponse: func(method, path string, status int, elapsed time.Duration, err error) {
+			record("response %s %s %d %v", method, path, status, err)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	t.Run("retried", func(t *testing.T) {
+		events = nil
+		atomic.StoreInt32(&calls, 0)
+		if _, err := apih.Get("/graph/1"); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		expected := []string{
+			"request GET /graph/1",
+			"response GET /graph/1 500 <nil>",
+			"retry 1 true true",
+			"request GET /graph/1",
+			"response GET /graph/1 200 <nil>",
+		}
+		if !reflect.DeepEqual(events, expected) {
+			t.Fatalf("unexpected events (%q)", events)
+		}
+	})
+
+	t.Run("exponential backoff", func(t *testing.T) {
+		apih.EnableExponentialBackoff()
+		defer apih.DisableExponentialBackoff()
+		events = nil
+		atomic.StoreInt32(&calls, 0)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		if _, err := apih.apiRequestContext(ctx, "PUT", "/graph/1", []byte(`{}`)); err == nil {
+			t.Fatal("expected error")
+		}
+		expected := []string{
+			"request PUT /graph/1",
+			"response PUT /graph/1 500 <nil>",
+			"retry 1 true true",
+		}
+		if !reflect.DeepEqual(events, expected) {
+			t.Fatalf("unexpected events (%q)", events)
+		}
+	})
+
+	t.Run("account copy", func(t *testing.T) {
+		acct, err := apih.WithAccount("/account/2")
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		events = nil
+		atomic.StoreInt32(&calls, 1)
+		if _, err := acct.Delete("/graph/1"); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if !reflect.DeepEqual(events, []string{"request DELETE /graph/1", "response DELETE /graph/1 200 <nil>"}) {
+			t.Fatalf("unexpected events (%q)", events)
+		}
+	})
+}
+
+func TestOnCall(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first request is refused for the rate limit
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if r.Context().Value(testCallKey{}) != nil {
+			t.Error("unexpected server context")
+		}
+		fmt.Fprintln(w, `{"_cid":"/graph/1"}`)
+	}))
+	defer server.Close()
+
+	var infos []CallInfo
+	var hookCtx context.Context
+	apih, err := New(&Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      server.URL,
+		OnCall: func(ctx context.Context, method, path string) (context.Context, func(CallInfo)) {
+			return context.WithValue(ctx, testCallKey{}, path), func(info CallInfo) {
+				infos = append(infos, info)
+			}
+		},
+		RequestHooks: []RequestHook{
+			func(req *http.Request) error {
+				hookCtx = req.Context()
+				return nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	cid := "/graph/1"
+	if _, err := apih.FetchGraph(CIDType(&cid)); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(infos) != 1 {
+		t.Fatalf("unexpected calls (%v)", infos)
+	}
+	info := infos[0]
+	info.Elapsed = 0
+	expected := CallInfo{Method: "GET", Path: "/graph/1", Status: 200, Attempts: 2, RateLimited: 1}
+	if !reflect.DeepEqual(info, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, info)
+	}
+
+	// the call is made with the context of the hook
+	if hookCtx == nil || hookCtx.Value(testCallKey{}) != "/graph/1" {
+		t.Fatal("expected the call made with the context of OnCall")
+	}
+}
+
+type testCallKey struct{}
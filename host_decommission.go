@@ -0,0 +1,251 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Host decommission - remove (or archive) a host's check bundles and the
+// rule sets, graphs, worksheet entries, and maintenance windows referencing them
+
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// Host decommission actions, see HostDecommissionAction.Action
+const (
+	HostActionDelete  = "delete"
+	HostActionUpdate  = "update"
+	HostActionDisable = "disable"
+)
+
+// checkBundleStatusDisabled is the status of archived check bundles
+const checkBundleStatusDisabled = "disabled"
+
+// HostTarget defines a host to decommission with DecommissionHost
+type HostTarget struct {
+	// Host defines the target of the host's check bundles, required
+	Host string
+
+	// DryRun reports what would be done, without changing anything
+	DryRun bool
+
+	// Archive disables the check bundles, keeping their data, graphs, and
+	// worksheet entries, instead of deleting them. Rule sets and
+	// maintenance windows are deleted either way.
+	Archive bool
+}
+
+// HostDecommissionAction defines a change made (or planned, for a dry run)
+type HostDecommissionAction struct {
+	CID    string
+	Action string // delete, update, or disable
+	Detail string
+	Err    error // nil if done (or would be, for a dry run)
+}
+
+// HostDecommissionReport defines the outcome of a host decommission
+type HostDecommissionReport struct {
+	Host    string
+	DryRun  bool
+	Actions []HostDecommissionAction // in the order taken
+}
+
+// Err returns an error summarizing the failed actions, nil if none failed.
+func (r *HostDecommissionReport) Err() error {
+	msgs := []string{}
+	for _, a := range r.Actions {
+		if a.Err != nil {
+			msgs = append(msgs, fmt.Sprintf("%s %s: %s", a.Action, a.CID, a.Err))
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.Errorf("decommission host %s, %d of %d failed (%s)", r.Host, len(msgs), len(r.Actions), strings.Join(msgs, "; "))
+}
+
+// DecommissionHost finds the check bundles targeting the host and the
+// objects referencing their checks, and removes them in dependency order:
+// maintenance windows, rule sets, worksheet entries, graphs (graphs with
+// datapoints of other checks are updated instead), then check bundles (or
+// disables the check bundles, see HostTarget.Archive). Processing continues
+// past failures, which are reported in the report. An error is returned
+// only if the objects could not be determined.
+func (a *API) DecommissionHost(ctx context.Context, target *HostTarget) (*HostDecommissionReport, error) {
+	if target == nil {
+		return nil, errors.New("invalid host target (nil)")
+	}
+	if target.Host == "" {
+		return nil, errors.New("invalid host target, host (none)")
+	}
+
+	filter := SearchFilterType{"f_target": []string{target.Host}}
+	bundles, err := a.SearchCheckBundlesWithContext(ctx, nil, &filter)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := map[string]bool{} // cids of the bundles, their checks, and rule sets
+	checkIDs := map[uint]bool{}
+	for _, b := range *bundles {
+		refs[b.CID] = true
+		for _, c := range b.Checks {
+			refs[c] = true
+			var id uint
+			if _, err := fmt.Sscanf(strings.TrimPrefix(c, config.CheckPrefix+"/"), "%d", &id); err == nil {
+				checkIDs[id] = true
+			}
+		}
+	}
+
+	report := &HostDecommissionReport{Host: target.Host, DryRun: target.DryRun}
+	if len(*bundles) == 0 {
+		return report, nil
+	}
+
+	ruleSets, err := a.FetchRuleSetsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hostRuleSets := []RuleSet{}
+	for _, rs := range *ruleSets {
+		if refs[rs.CheckCID] {
+			hostRuleSets = append(hostRuleSets, rs)
+			refs[rs.CID] = true
+		}
+	}
+
+	windows, err := a.FetchMaintenanceWindowsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var graphs []*Graph
+	var worksheets []*Worksheet
+	deletedGraphs := map[string]bool{}
+	if !target.Archive {
+		allGraphs, err := a.FetchGraphsWithContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range *allGraphs {
+			g := g
+			kept := g.Datapoints[:0:0]
+			for _, dp := range g.Datapoints {
+				if !checkIDs[dp.CheckID] {
+					kept = append(kept, dp)
+				}
+			}
+			switch {
+			case len(kept) == len(g.Datapoints):
+				continue
+			case len(kept) == 0 && len(g.Composites) == 0 && len(g.MetricClusters) == 0:
+				deletedGraphs[g.CID] = true
+			default:
+				g.Datapoints = kept
+			}
+			graphs = append(graphs, &g)
+		}
+
+		allWorksheets, err := a.FetchWorksheetsWithContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range *allWorksheets {
+			w := w
+			kept := []WorksheetGraph{}
+			for _, wg := range w.Graphs {
+				if !deletedGraphs[wg.GraphCID] {
+					kept = append(kept, wg)
+				}
+			}
+			if len(kept) != len(w.Graphs) {
+				w.Graphs = kept
+				worksheets = append(worksheets, &w)
+			}
+		}
+	}
+
+	do := func(cid, action, detail string, fn func() error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		act := HostDecommissionAction{CID: cid, Action: action, Detail: detail}
+		if !target.DryRun {
+			act.Err = fn()
+		}
+		report.Actions = append(report.Actions, act)
+		return nil
+	}
+	del := func(cid string) func() error {
+		return func() error {
+			_, err := a.DeleteWithContext(ctx, cid)
+			return err
+		}
+	}
+
+	for _, m := range *windows {
+		if refs[m.Item] || (m.Type == "host" && m.Item == target.Host) {
+			if err := do(m.CID, HostActionDelete, "maintenance window on "+m.Item, del(m.CID)); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	for _, rs := range hostRuleSets {
+		if err := do(rs.CID, HostActionDelete, fmt.Sprintf("rule set on %s %s", rs.CheckCID, rs.MetricName), del(rs.CID)); err != nil {
+			return report, err
+		}
+	}
+
+	for _, w := range worksheets {
+		w := w
+		err := do(w.CID, HostActionUpdate, fmt.Sprintf("remove graphs from worksheet %q", w.Title), func() error {
+			_, err := a.UpdateWorksheetWithContext(ctx, w)
+			return err
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+
+	for _, g := range graphs {
+		g := g
+		var err error
+		if deletedGraphs[g.CID] {
+			err = do(g.CID, HostActionDelete, fmt.Sprintf("graph %q", g.Title), del(g.CID))
+		} else {
+			err = do(g.CID, HostActionUpdate, fmt.Sprintf("remove host datapoints from graph %q", g.Title), func() error {
+				_, err := a.UpdateGraphWithContext(ctx, g)
+				return err
+			})
+		}
+		if err != nil {
+			return report, err
+		}
+	}
+
+	for _, b := range *bundles {
+		b := b
+		var err error
+		if target.Archive {
+			err = do(b.CID, HostActionDisable, fmt.Sprintf("check bundle %q", b.DisplayName), func() error {
+				b.Status = checkBundleStatusDisabled
+				_, err := a.UpdateCheckBundleWithContext(ctx, &b)
+				return err
+			})
+		} else {
+			err = do(b.CID, HostActionDelete, fmt.Sprintf("check bundle %q", b.DisplayName), del(b.CID))
+		}
+		if err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
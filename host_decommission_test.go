@@ -0,0 +1,156 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
+)
+
+func testHostDecommissionServer(t *testing.T) (*API, *fakecirconus.Server) {
+	fake, err := fakecirconus.New(&fakecirconus.Config{
+		Fixtures: map[string]interface{}{
+			"/check_bundle/1": CheckBundle{CID: "/check_bundle/1", DisplayName: "web1 http", Target: "web1", Checks: []string{"/check/11"}},
+			"/check_bundle/2": CheckBundle{CID: "/check_bundle/2", DisplayName: "web2 http", Target: "web2", Checks: []string{"/check/12"}},
+			"/rule_set/1":     RuleSet{CID: "/rule_set/1", CheckCID: "/check/11", MetricName: "duration"},
+			"/rule_set/2":     RuleSet{CID: "/rule_set/2", CheckCID: "/check/12", MetricName: "duration"},
+			"/graph/1":        Graph{CID: "/graph/1", Title: "web1", Datapoints: []GraphDatapoint{{CheckID: 11, MetricName: "duration"}}},
+			"/graph/2":        Graph{CID: "/graph/2", Title: "web", Datapoints: []GraphDatapoint{{CheckID: 11, MetricName: "duration"}, {CheckID: 12, MetricName: "duration"}}},
+			"/graph/3":        Graph{CID: "/graph/3", Title: "web2", Datapoints: []GraphDatapoint{{CheckID: 12, MetricName: "duration"}}},
+			"/worksheet/1":    Worksheet{CID: "/worksheet/1", Title: "web", Graphs: []WorksheetGraph{{GraphCID: "/graph/1"}, {GraphCID: "/graph/2"}, {GraphCID: "/graph/3"}}},
+			"/maintenance/1":  Maintenance{CID: "/maintenance/1", Item: "/check_bundle/1", Type: "check_bundle"},
+			"/maintenance/2":  Maintenance{CID: "/maintenance/2", Item: "/rule_set/1", Type: "rule_set"},
+			"/maintenance/3":  Maintenance{CID: "/maintenance/3", Item: "web1", Type: "host"},
+			"/maintenance/4":  Maintenance{CID: "/maintenance/4", Item: "web2", Type: "host"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: fake.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	return apih, fake
+}
+
+func hostActions(report *HostDecommissionReport) []string {
+	actions := []string{}
+	for _, a := range report.Actions {
+		actions = append(actions, a.Action+" "+a.CID)
+	}
+	return actions
+}
+
+func TestDecommissionHost(t *testing.T) {
+	apih, fake := testHostDecommissionServer(t)
+	defer fake.Close()
+
+	report, err := apih.DecommissionHost(context.Background(), &HostTarget{Host: "web1"})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if err := report.Err(); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	expected := []string{
+		"delete /maintenance/1",
+		"delete /maintenance/2",
+		"delete /maintenance/3",
+		"delete /rule_set/1",
+		"update /worksheet/1",
+		"delete /graph/1",
+		"update /graph/2",
+		"delete /check_bundle/1",
+	}
+	if !reflect.DeepEqual(hostActions(report), expected) {
+		t.Fatalf("unexpected actions (%v)", hostActions(report))
+	}
+
+	for _, cid := range []string{"/maintenance/1", "/maintenance/2", "/maintenance/3", "/rule_set/1", "/graph/1", "/check_bundle/1"} {
+		if _, ok := fake.Object(cid); ok {
+			t.Fatalf("%s not deleted", cid)
+		}
+	}
+	for _, cid := range []string{"/maintenance/4", "/rule_set/2", "/graph/3", "/check_bundle/2"} {
+		if _, ok := fake.Object(cid); !ok {
+			t.Fatalf("%s deleted", cid)
+		}
+	}
+
+	graphCID := "/graph/2"
+	g, err := apih.FetchGraph(CIDType(&graphCID))
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(g.Datapoints) != 1 || g.Datapoints[0].CheckID != 12 {
+		t.Fatalf("unexpected graph datapoints (%#v)", g.Datapoints)
+	}
+	worksheetCID := "/worksheet/1"
+	w, err := apih.FetchWorksheet(CIDType(&worksheetCID))
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if !reflect.DeepEqual(w.Graphs, []WorksheetGraph{{GraphCID: "/graph/2"}, {GraphCID: "/graph/3"}}) {
+		t.Fatalf("unexpected worksheet graphs (%#v)", w.Graphs)
+	}
+}
+
+func TestDecommissionHostDryRunArchive(t *testing.T) {
+	apih, fake := testHostDecommissionServer(t)
+	defer fake.Close()
+
+	report, err := apih.DecommissionHost(context.Background(), &HostTarget{Host: "web1", DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if !report.DryRun || len(report.Actions) != 8 {
+		t.Fatalf("unexpected report (%#v)", report)
+	}
+	for _, r := range fake.Requests() {
+		if r.Method != "GET" {
+			t.Fatalf("unexpected dry run request (%s %s)", r.Method, r.URL)
+		}
+	}
+
+	report, err = apih.DecommissionHost(context.Background(), &HostTarget{Host: "web1", Archive: true})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	expected := []string{
+		"delete /maintenance/1",
+		"delete /maintenance/2",
+		"delete /maintenance/3",
+		"delete /rule_set/1",
+		"disable /check_bundle/1",
+	}
+	if !reflect.DeepEqual(hostActions(report), expected) {
+		t.Fatalf("unexpected actions (%v)", hostActions(report))
+	}
+	bundleCID := "/check_bundle/1"
+	b, err := apih.FetchCheckBundle(CIDType(&bundleCID))
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if b.Status != "disabled" {
+		t.Fatalf("unexpected status (%s)", b.Status)
+	}
+
+	report, err = apih.DecommissionHost(context.Background(), &HostTarget{Host: "web3"})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(report.Actions) != 0 {
+		t.Fatalf("unexpected actions (%v)", hostActions(report))
+	}
+
+	if _, err := apih.DecommissionHost(context.Background(), &HostTarget{}); err == nil || err.Error() != "invalid host target, host (none)" {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+}
@@ -0,0 +1,322 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Host onboarding - create the check bundles, graphs, worksheet, and rule
+// sets for a host in one call, rolling back on failure
+
+package apiclient
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// brokerStatusActive is the status of broker instances accepting checks
+const brokerStatusActive = "active"
+
+// HostSpec defines a host to onboard with OnboardHost. The placeholders
+// {{host}} and {{name}} are substituted in check bundle display names,
+// targets, config values, and tags, and in rule set templates (see
+// InstantiateRuleSetTemplate).
+type HostSpec struct {
+	// Host defines the host name or address, the default check bundle target, required
+	Host string
+
+	// Name defines the name used in object titles - default Host
+	Name string
+
+	// Tags are added to every object created
+	Tags []string
+
+	// BrokerCID defines the broker of the check bundles, by default the
+	// first active broker (by name) with the modules of every check bundle
+	// type, tagged with BrokerTag if set, is selected. Check bundle
+	// templates with brokers set keep them.
+	BrokerCID string
+	BrokerTag string
+
+	// CheckBundles are the check bundle templates, one check bundle is
+	// created from each, required
+	CheckBundles []CheckBundle
+
+	// Graphs, when set, creates a graph of the numeric metrics of each
+	// check bundle and a worksheet of the graphs
+	Graphs bool
+
+	// RuleSets are rule set templates, instantiated for each check bundle
+	// with a metric named as the template's MetricName
+	RuleSets []RuleSet
+}
+
+// HostOnboarding describes the objects created by OnboardHost
+type HostOnboarding struct {
+	Broker       *Broker
+	CheckBundles []CheckBundle
+	Graphs       []Graph
+	Worksheet    *Worksheet
+	RuleSets     []RuleSet
+}
+
+// OnboardHost selects a broker and creates the check bundles, graphs,
+// worksheet, and rule sets for the host in the passed spec. If any step
+// fails, the objects already created are deleted (in reverse order) and the
+// error is returned; when the rollback itself fails the error lists the
+// objects left behind.
+func (a *API) OnboardHost(spec *HostSpec) (*HostOnboarding, error) {
+	if spec == nil {
+		return nil, errors.New("invalid host spec (nil)")
+	}
+	if spec.Host == "" {
+		return nil, errors.New("invalid host spec, host (none)")
+	}
+	if len(spec.CheckBundles) == 0 {
+		return nil, errors.Errorf("invalid host spec %s, check bundles (none)", spec.Host)
+	}
+
+	name := spec.Name
+	if name == "" {
+		name = spec.Host
+	}
+	r := strings.NewReplacer("{{host}}", spec.Host, "{{name}}", name)
+
+	result := &HostOnboarding{}
+	var created []string // cids, in creation order
+	fail := func(err error) (*HostOnboarding, error) {
+		err = errors.Wrapf(err, "onboarding host %s", spec.Host)
+		if remaining := a.rollback(created); len(remaining) > 0 {
+			return result, errors.Wrapf(err, "rollback incomplete, remaining objects (%s)", strings.Join(remaining, ", "))
+		}
+		return result, err
+	}
+
+	broker, err := a.selectHostBroker(spec)
+	if err != nil {
+		return fail(err)
+	}
+	result.Broker = broker
+
+	for _, tmpl := range spec.CheckBundles {
+		bundle := hostCheckBundle(&tmpl, spec, broker, r)
+		b, err := a.CreateCheckBundle(bundle)
+		if err != nil {
+			return fail(errors.Wrapf(err, "creating check bundle %s", bundle.DisplayName))
+		}
+		created = append(created, b.CID)
+		result.CheckBundles = append(result.CheckBundles, *b)
+	}
+
+	if spec.Graphs {
+		ws := NewWorksheet()
+		ws.Title = name
+		ws.Tags = append([]string{}, spec.Tags...)
+		for _, b := range result.CheckBundles {
+			graph := hostGraph(&b, name, spec.Tags)
+			if graph == nil {
+				continue
+			}
+			g, err := a.CreateGraph(graph)
+			if err != nil {
+				return fail(errors.Wrapf(err, "creating graph %s", graph.Title))
+			}
+			created = append(created, g.CID)
+			result.Graphs = append(result.Graphs, *g)
+			ws.Graphs = append(ws.Graphs, WorksheetGraph{GraphCID: g.CID})
+		}
+
+		w, err := a.CreateWorksheet(ws)
+		if err != nil {
+			return fail(errors.Wrapf(err, "creating worksheet %s", ws.Title))
+		}
+		created = append(created, w.CID)
+		result.Worksheet = w
+	}
+
+	for _, tmpl := range spec.RuleSets {
+		tmpl := tmpl
+		for _, b := range result.CheckBundles {
+			if len(b.Checks) == 0 || !hasBundleMetric(&b, tmpl.MetricName) {
+				continue
+			}
+			rs, err := InstantiateRuleSetTemplate(&tmpl, RuleSetTemplateTarget{
+				CheckCID:   b.Checks[0],
+				MetricName: tmpl.MetricName,
+				Vars:       map[string]string{"host": spec.Host, "name": name},
+			})
+			if err != nil {
+				return fail(err)
+			}
+			rs.Tags = append(rs.Tags, spec.Tags...)
+			ruleSet, err := a.CreateRuleSet(rs)
+			if err != nil {
+				return fail(errors.Wrapf(err, "creating rule set %s %s", rs.CheckCID, rs.MetricName))
+			}
+			created = append(created, ruleSet.CID)
+			result.RuleSets = append(result.RuleSets, *ruleSet)
+		}
+	}
+
+	return result, nil
+}
+
+// rollback deletes the objects with the passed cids in reverse order,
+// returning the cids which could not be deleted
+func (a *API) rollback(cids []string) []string {
+	var remaining []string
+	for i := len(cids) - 1; i >= 0; i-- {
+		if cids[i] == "" {
+			continue
+		}
+		if _, err := a.Delete(cids[i]); err != nil {
+			remaining = append(remaining, cids[i])
+		}
+	}
+	return remaining
+}
+
+// selectHostBroker returns the broker in the spec, or the first active broker
+// supporting every check bundle type
+func (a *API) selectHostBroker(spec *HostSpec) (*Broker, error) {
+	if spec.BrokerCID != "" {
+		cid := spec.BrokerCID
+		if !strings.HasPrefix(cid, config.BrokerPrefix+"/") {
+			cid = config.BrokerPrefix + "/" + cid
+		}
+		return a.FetchBroker(CIDType(&cid))
+	}
+
+	types := []string{}
+	for _, b := range spec.CheckBundles {
+		if len(b.Brokers) == 0 {
+			types = append(types, b.Type)
+		}
+	}
+
+	brokers, err := a.FetchBrokers()
+	if err != nil {
+		return nil, err
+	}
+	candidates := []Broker{}
+	for _, b := range *brokers {
+		if spec.BrokerTag != "" && !hasString(b.Tags, spec.BrokerTag) {
+			continue
+		}
+		if brokerSupports(&b, types) {
+			candidates = append(candidates, b)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, errors.Errorf("no active broker supports check types (%s)", strings.Join(types, ", "))
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Name < candidates[j].Name })
+
+	return &candidates[0], nil
+}
+
+// brokerSupports returns true if an active instance of the broker has the
+// modules of every check type
+func brokerSupports(b *Broker, types []string) bool {
+	for _, d := range b.Details {
+		if d.Status != brokerStatusActive {
+			continue
+		}
+		ok := true
+		for _, t := range types {
+			if !hasString(d.Modules, t) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hostCheckBundle returns the check bundle template for the host
+func hostCheckBundle(tmpl *CheckBundle, spec *HostSpec, broker *Broker, r *strings.Replacer) *CheckBundle {
+	b := *tmpl
+	b.CID = ""
+	b.Checks = nil
+	b.CheckUUIDs = nil
+	b.DisplayName = r.Replace(tmpl.DisplayName)
+	if b.Target == "" {
+		b.Target = spec.Host
+	} else {
+		b.Target = r.Replace(tmpl.Target)
+	}
+	if len(b.Brokers) == 0 {
+		b.Brokers = []string{broker.CID}
+	}
+
+	b.Config = make(CheckBundleConfig, len(tmpl.Config))
+	for k, v := range tmpl.Config {
+		b.Config[k] = r.Replace(v)
+	}
+
+	b.Tags = make([]string, 0, len(tmpl.Tags)+len(spec.Tags))
+	for _, tag := range tmpl.Tags {
+		b.Tags = append(b.Tags, r.Replace(tag))
+	}
+	b.Tags = append(b.Tags, spec.Tags...)
+
+	b.Metrics = append([]CheckBundleMetric{}, tmpl.Metrics...)
+
+	return &b
+}
+
+// hostGraph returns a graph of the numeric metrics of the check bundle, nil
+// if it has none
+func hostGraph(b *CheckBundle, name string, tags []string) *Graph {
+	if len(b.Checks) == 0 {
+		return nil
+	}
+	var checkID uint
+	if _, err := fmt.Sscanf(strings.TrimPrefix(b.Checks[0], config.CheckPrefix+"/"), "%d", &checkID); err != nil {
+		return nil
+	}
+
+	g := NewGraph()
+	g.Title = fmt.Sprintf("%s %s", name, b.DisplayName)
+	g.Tags = append([]string{}, tags...)
+	for _, m := range b.Metrics {
+		if m.Type != "numeric" {
+			continue
+		}
+		g.Datapoints = append(g.Datapoints, GraphDatapoint{
+			Axis:       "l",
+			CheckID:    checkID,
+			MetricName: m.Name,
+			MetricType: m.Type,
+			Name:       m.Name,
+			Derive:     "gauge",
+		})
+	}
+	if len(g.Datapoints) == 0 {
+		return nil
+	}
+	return g
+}
+
+func hasBundleMetric(b *CheckBundle, metricName string) bool {
+	for _, m := range b.Metrics {
+		if m.Name == metricName {
+			return true
+		}
+	}
+	return false
+}
+
+func hasString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
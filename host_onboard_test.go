@@ -0,0 +1,193 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
+)
+
+func testHostBrokers() map[string]interface{} {
+	return map[string]interface{}{
+		"/broker/1": Broker{CID: "/broker/1", Name: "b-inactive", Details: []BrokerDetail{{Status: "unprovisioned", Modules: []string{"http", "ping_icmp"}}}},
+		"/broker/2": Broker{CID: "/broker/2", Name: "c-public", Tags: []string{"public"}, Details: []BrokerDetail{{Status: "active", Modules: []string{"http", "ping_icmp"}}}},
+		"/broker/3": Broker{CID: "/broker/3", Name: "d-http", Details: []BrokerDetail{{Status: "active", Modules: []string{"http"}}}},
+		"/broker/4": Broker{CID: "/broker/4", Name: "e-private", Details: []BrokerDetail{{Status: "active", Modules: []string{"http", "ping_icmp", "snmp"}}}},
+	}
+}
+
+func testHostSpec() *HostSpec {
+	return &HostSpec{
+		Host: "web1.example.com",
+		Name: "web1",
+		Tags: []string{"service:web"},
+		CheckBundles: []CheckBundle{
+			{
+				DisplayName: "{{name}} http",
+				Type:        "http",
+				Config:      CheckBundleConfig{"url": "https://{{host}}/health"},
+				Metrics:     []CheckBundleMetric{{Name: "duration", Type: "numeric"}, {Name: "body", Type: "text"}},
+			},
+			{
+				DisplayName: "{{name}} ping",
+				Type:        "ping_icmp",
+				Metrics:     []CheckBundleMetric{{Name: "available", Type: "numeric"}},
+			},
+		},
+		Graphs: true,
+		RuleSets: []RuleSet{
+			{
+				Name:       "{{name}} slow",
+				MetricName: "duration",
+				MetricType: "numeric",
+				Rules:      []RuleSetRule{{Criteria: "max value", Severity: 2, Value: "500"}},
+			},
+		},
+	}
+}
+
+func TestOnboardHost(t *testing.T) {
+	fake, err := fakecirconus.New(&fakecirconus.Config{Fixtures: testHostBrokers()})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: fake.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	result, err := apih.OnboardHost(testHostSpec())
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if result.Broker.CID != "/broker/2" {
+		t.Fatalf("unexpected broker (%s)", result.Broker.CID)
+	}
+	if len(result.CheckBundles) != 2 {
+		t.Fatalf("unexpected check bundles (%#v)", result.CheckBundles)
+	}
+	bundle := result.CheckBundles[0]
+	if bundle.DisplayName != "web1 http" || bundle.Target != "web1.example.com" || bundle.Config["url"] != "https://web1.example.com/health" {
+		t.Fatalf("unexpected check bundle (%#v)", bundle)
+	}
+	if !reflect.DeepEqual(bundle.Brokers, []string{"/broker/2"}) || !reflect.DeepEqual(bundle.Tags, []string{"service:web"}) {
+		t.Fatalf("unexpected check bundle brokers/tags (%#v)", bundle)
+	}
+
+	if len(result.Graphs) != 2 || result.Graphs[0].Title != "web1 web1 http" || len(result.Graphs[0].Datapoints) != 1 || result.Graphs[0].Datapoints[0].CheckID != 1001 {
+		t.Fatalf("unexpected graphs (%#v)", result.Graphs)
+	}
+	if result.Worksheet == nil || result.Worksheet.Title != "web1" || len(result.Worksheet.Graphs) != 2 {
+		t.Fatalf("unexpected worksheet (%#v)", result.Worksheet)
+	}
+
+	if len(result.RuleSets) != 1 {
+		t.Fatalf("unexpected rule sets (%#v)", result.RuleSets)
+	}
+	rs := result.RuleSets[0]
+	if rs.CheckCID != bundle.Checks[0] || rs.Name != "web1 slow" || !reflect.DeepEqual(rs.Tags, []string{"service:web"}) {
+		t.Fatalf("unexpected rule set (%#v)", rs)
+	}
+}
+
+func TestOnboardHostBroker(t *testing.T) {
+	fake, err := fakecirconus.New(&fakecirconus.Config{Fixtures: testHostBrokers()})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: fake.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	tests := []struct {
+		id          string
+		brokerCID   string
+		brokerTag   string
+		types       []string
+		expected    string
+		expectedErr string
+	}{
+		{"by cid", "3", "", []string{"ping_icmp"}, "/broker/3", ""},
+		{"by modules", "", "", []string{"snmp"}, "/broker/4", ""},
+		{"by tag", "", "public", []string{"http"}, "/broker/2", ""},
+		{"none", "", "public", []string{"snmp"}, "", "no active broker supports check types (snmp)"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			spec := &HostSpec{Host: "web1", BrokerCID: test.brokerCID, BrokerTag: test.brokerTag}
+			for _, typ := range test.types {
+				spec.CheckBundles = append(spec.CheckBundles, CheckBundle{Type: typ})
+			}
+			b, err := apih.selectHostBroker(spec)
+			if test.expectedErr != "" {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if b.CID != test.expected {
+				t.Fatalf("unexpected broker (%s)", b.CID)
+			}
+		})
+	}
+}
+
+func TestOnboardHostRollback(t *testing.T) {
+	fake, err := fakecirconus.New(&fakecirconus.Config{
+		Fixtures: testHostBrokers(),
+		Failures: []fakecirconus.Failure{{Method: "POST", Path: "/rule_set", Status: 400, Body: `{"code":"400","message":"invalid rule set"}`}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: fake.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	_, err = apih.OnboardHost(testHostSpec())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.HasPrefix(err.Error(), "onboarding host web1.example.com: creating rule set /check/1001 duration") {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	// the two check bundles, two graphs and the worksheet are deleted, newest first
+	deleted := []string{}
+	for _, r := range fake.Requests() {
+		if r.Method == "DELETE" {
+			deleted = append(deleted, r.URL)
+		}
+	}
+	expected := []string{"/worksheet/1005", "/graph/1004", "/graph/1003", "/check_bundle/1002", "/check_bundle/1001"}
+	if !reflect.DeepEqual(deleted, expected) {
+		t.Fatalf("unexpected deletes (%v)", deleted)
+	}
+	for _, cid := range expected {
+		if _, ok := fake.Object(cid); ok {
+			t.Fatalf("%s not deleted", cid)
+		}
+	}
+
+	if _, err := apih.OnboardHost(&HostSpec{Host: "web1"}); err == nil || err.Error() != "invalid host spec web1, check bundles (none)" {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+}
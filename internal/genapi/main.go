@@ -0,0 +1,393 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Genapi generates the interfaces of the API - one per resource (e.g.
+// GraphAPI for the methods in graph.go) and CirconusAPI, all of them - and
+// the apitest Mock implementing them, from the methods of *API in the
+// resource files. Run it after adding or changing a resource method:
+//
+//	go generate ./apitest
+//
+// which writes api_gen.go and apitest/mock_gen.go. Both are rewritten on
+// every run, do not edit them. Add the files of new resources (including
+// those written by genresource) to resourceFiles.
+//
+// Usage:
+//
+//	genapi [-dir .]
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// importPath is the import path of the API package, as the mock imports it
+const importPath = "github.com/circonus-labs/go-apiclient"
+
+// resourceFiles are the files (stems) whose methods make up the interfaces,
+// each interface named for its file (e.g. check_bundle, CheckBundleAPI)
+var resourceFiles = []string{
+	"account",
+	"acknowledgement",
+	"alert",
+	"annotation",
+	"broker",
+	"check",
+	"check_bundle",
+	"check_bundle_metrics",
+	"check_move",
+	"check_template",
+	"contact_group",
+	"dashboard",
+	"data",
+	"graph",
+	"maintenance",
+	"metric",
+	"metric_cluster",
+	"outlier_report",
+	"provision_broker",
+	"rule_set",
+	"rule_set_group",
+	"tag",
+	"user",
+	"worksheet",
+}
+
+// requestMethods are the raw request methods (in main.go) of RequestAPI
+var requestMethods = map[string]bool{
+	"Get": true, "GetWithContext": true,
+	"Post": true, "PostWithContext": true,
+	"Put": true, "PutWithContext": true,
+	"Delete": true, "DeleteWithContext": true,
+}
+
+// param is a parameter of a method
+type param struct {
+	Name     string
+	Type     string // as the mock declares it, qualified
+	Variadic bool
+}
+
+// method is a method of *API
+type method struct {
+	Name    string
+	Sig     string // parameters and results, as the API declares them
+	Params  []param
+	Results []string // as the mock declares them, qualified
+	Error   bool     // the last result is an error
+}
+
+// ParamList returns the parameters as the mock declares them
+func (m method) ParamList() string {
+	list := make([]string, len(m.Params))
+	for i, p := range m.Params {
+		list[i] = p.Name + " " + p.Type
+	}
+	return strings.Join(list, ", ")
+}
+
+// FuncType returns the type of the func field of the mock
+func (m method) FuncType() string {
+	params := make([]string, len(m.Params))
+	for i, p := range m.Params {
+		params[i] = p.Type
+	}
+	return "func(" + strings.Join(params, ", ") + ") (" + strings.Join(m.Results, ", ") + ")"
+}
+
+// NamedResults returns the results as the mock declares them, named r0...
+// and err for an error
+func (m method) NamedResults() string {
+	list := make([]string, len(m.Results))
+	for i, r := range m.Results {
+		name := fmt.Sprintf("r%d", i)
+		if m.Error && i == len(m.Results)-1 {
+			name = "err"
+		}
+		list[i] = name + " " + r
+	}
+	return strings.Join(list, ", ")
+}
+
+// Args returns the arguments the mock records
+func (m method) Args() string {
+	list := make([]string, len(m.Params))
+	for i, p := range m.Params {
+		list[i] = p.Name
+	}
+	return strings.Join(list, ", ")
+}
+
+// CallArgs returns the arguments the mock calls its func field with
+func (m method) CallArgs() string {
+	list := make([]string, len(m.Params))
+	for i, p := range m.Params {
+		list[i] = p.Name
+		if p.Variadic {
+			list[i] += "..."
+		}
+	}
+	return strings.Join(list, ", ")
+}
+
+// iface is an interface generated, with the methods of a file
+type iface struct {
+	Name    string
+	File    string
+	Methods []method
+}
+
+func main() {
+	dir := flag.String("dir", ".", "API package directory")
+	flag.Parse()
+
+	if err := generate(*dir); err != nil {
+		fmt.Fprintf(os.Stderr, "genapi: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// generate writes the interfaces and the mock for the API package in dir
+func generate(dir string) error {
+	ifaces, imports, err := parseAPI(dir)
+	if err != nil {
+		return err
+	}
+	data := struct {
+		Ifaces     []iface
+		Std        []string // standard library imports
+		Imports    []string // other imports
+		ImportPath string
+	}{Ifaces: ifaces, ImportPath: importPath}
+	for _, path := range imports {
+		if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			data.Imports = append(data.Imports, path)
+		} else {
+			data.Std = append(data.Std, path)
+		}
+	}
+
+	src, err := render(interfacesTemplate, data)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "api_gen.go"), src, 0644); err != nil {
+		return errors.Wrap(err, "writing interfaces")
+	}
+
+	src, err = render(mockTemplate, data)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(ioutil.WriteFile(filepath.Join(dir, "apitest", "mock_gen.go"), src, 0644), "writing mock")
+}
+
+// parseAPI returns the interfaces of the API package in dir, and the
+// packages their methods import
+func parseAPI(dir string) ([]iface, []string, error) {
+	fset := token.NewFileSet()
+	imports := map[string]bool{}
+
+	var ifaces []iface
+	files := append([]string{"main"}, resourceFiles...)
+	for _, stem := range files {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, stem+".go"), nil, parser.ParseComments)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "parsing %s.go", stem)
+		}
+		paths := map[string]string{}
+		for _, imp := range f.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			name := filepath.Base(path)
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			paths[name] = path
+		}
+
+		it := iface{Name: camelCase(stem) + "API", File: stem + ".go"}
+		if stem == "main" {
+			it.Name = "RequestAPI"
+		}
+		for _, d := range f.Decls {
+			fn, ok := d.(*ast.FuncDecl)
+			if !ok || !fn.Name.IsExported() || !apiReceiver(fn) {
+				continue
+			}
+			if stem == "main" && !requestMethods[fn.Name.Name] {
+				continue
+			}
+			m, used, err := newMethod(fset, fn)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "%s.go %s", stem, fn.Name.Name)
+			}
+			for _, pkg := range used {
+				path, ok := paths[pkg]
+				if !ok {
+					return nil, nil, errors.Errorf("%s.go %s, unknown package %s", stem, fn.Name.Name, pkg)
+				}
+				imports[path] = true
+			}
+			it.Methods = append(it.Methods, m)
+		}
+		if len(it.Methods) == 0 {
+			return nil, nil, errors.Errorf("%s.go has no API methods", stem)
+		}
+		ifaces = append(ifaces, it)
+	}
+
+	var paths []string
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return ifaces, paths, nil
+}
+
+// apiReceiver returns true if the function is a method of *API
+func apiReceiver(fn *ast.FuncDecl) bool {
+	if fn.Recv == nil || len(fn.Recv.List) != 1 {
+		return false
+	}
+	star, ok := fn.Recv.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	id, ok := star.X.(*ast.Ident)
+	return ok && id.Name == "API"
+}
+
+// newMethod returns the method of a function, and the packages (other
+// than the API package) its signature uses
+func newMethod(fset *token.FileSet, fn *ast.FuncDecl) (method, []string, error) {
+	m := method{Name: fn.Name.Name}
+
+	// the signature as declared, before the types are qualified
+	sig, err := nodeString(fset, fn.Type)
+	if err != nil {
+		return m, nil, err
+	}
+	m.Sig = strings.TrimPrefix(sig, "func")
+
+	var used []string
+	for _, field := range fn.Type.Params.List {
+		_, variadic := field.Type.(*ast.Ellipsis)
+		typ, pkgs, err := qualifiedType(fset, field.Type)
+		if err != nil {
+			return m, nil, err
+		}
+		used = append(used, pkgs...)
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{{Name: "_"}}
+		}
+		for _, n := range names {
+			m.Params = append(m.Params, param{Name: n.Name, Type: typ, Variadic: variadic})
+		}
+	}
+	for i := range m.Params {
+		// the mock names its receiver m, and its results r0... and err
+		if n := m.Params[i].Name; n == "_" || n == "m" || n == "err" || strings.HasPrefix(n, "r") && len(n) > 1 && n[1] >= '0' && n[1] <= '9' {
+			m.Params[i].Name = fmt.Sprintf("a%d", i)
+		}
+	}
+
+	if fn.Type.Results != nil {
+		for _, field := range fn.Type.Results.List {
+			typ, pkgs, err := qualifiedType(fset, field.Type)
+			if err != nil {
+				return m, nil, err
+			}
+			used = append(used, pkgs...)
+			n := len(field.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				m.Results = append(m.Results, typ)
+			}
+		}
+	}
+	m.Error = len(m.Results) > 0 && m.Results[len(m.Results)-1] == "error"
+
+	return m, used, nil
+}
+
+// qualifiedType returns a type as declared outside the API package (e.g.
+// *apiclient.Graph for *Graph), and the other packages it uses
+func qualifiedType(fset *token.FileSet, expr ast.Expr) (string, []string, error) {
+	var used []string
+	var qualify func(n ast.Node) bool
+	qualify = func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.SelectorExpr:
+			if id, ok := x.X.(*ast.Ident); ok {
+				used = append(used, id.Name)
+			}
+			return false
+		case *ast.Ident:
+			if x.IsExported() {
+				x.Name = "apiclient." + x.Name
+			}
+		case *ast.Field:
+			// only the types of fields (e.g. of func parameters), not names
+			ast.Inspect(x.Type, qualify)
+			return false
+		}
+		return true
+	}
+	ast.Inspect(expr, qualify)
+	s, err := nodeString(fset, expr)
+	return s, used, err
+}
+
+// nodeString returns the source of a node
+func nodeString(fset *token.FileSet, n ast.Node) (string, error) {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, n); err != nil {
+		return "", errors.Wrap(err, "printing")
+	}
+	return buf.String(), nil
+}
+
+// camelCase returns a file stem in camel case, e.g. CheckBundle for
+// check_bundle
+func camelCase(s string) string {
+	var b strings.Builder
+	for _, w := range strings.Split(s, "_") {
+		if w != "" {
+			b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+		}
+	}
+	return b.String()
+}
+
+// render executes a template, formatting the result
+func render(tmpl *template.Template, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, errors.Wrapf(err, "generating %s", tmpl.Name())
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(err, "formatting %s", tmpl.Name())
+	}
+	return src, nil
+}
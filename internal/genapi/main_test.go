@@ -0,0 +1,102 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCamelCase(t *testing.T) {
+	tests := map[string]string{
+		"graph":                "Graph",
+		"check_bundle":         "CheckBundle",
+		"check_bundle_metrics": "CheckBundleMetrics",
+	}
+	for in, expected := range tests {
+		if out := camelCase(in); out != expected {
+			t.Errorf("%s: unexpected name (%s)", in, out)
+		}
+	}
+}
+
+func TestQualifiedType(t *testing.T) {
+	tests := []struct {
+		typ          string
+		expected     string
+		expectedPkgs int
+	}{
+		{"error", "error", 0},
+		{"*[]Graph", "*[]apiclient.Graph", 0},
+		{"map[string]CIDType", "map[string]apiclient.CIDType", 0},
+		{"context.Context", "context.Context", 1},
+		{"func(acct *Account, api *API) error", "func(acct *apiclient.Account, api *apiclient.API) error", 0},
+		{"...TagType", "...apiclient.TagType", 0},
+	}
+	for _, test := range tests {
+		fset := token.NewFileSet()
+		expr, err := parser.ParseExprFrom(fset, "", "func(x "+test.typ+")", 0)
+		if err != nil {
+			t.Fatalf("%s: unexpected error (%s)", test.typ, err)
+		}
+		fields := expr.(*ast.FuncType).Params.List
+		typ, pkgs, err := qualifiedType(fset, fields[0].Type)
+		if err != nil {
+			t.Fatalf("%s: unexpected error (%s)", test.typ, err)
+		}
+		if typ != test.expected || len(pkgs) != test.expectedPkgs {
+			t.Errorf("%s: unexpected type (%s) packages (%v)", test.typ, typ, pkgs)
+		}
+	}
+}
+
+// TestGenerated fails if the interfaces or the mock are out of date with
+// the API methods - run go generate ./apitest
+func TestGenerated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "genapi")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join("..", "..")
+	files := append([]string{"main"}, resourceFiles...)
+	for _, stem := range files {
+		data, err := ioutil.ReadFile(filepath.Join(src, stem+".go"))
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, stem+".go"), data, 0644); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "apitest"), 0755); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if err := generate(dir); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	for _, file := range []string{"api_gen.go", filepath.Join("apitest", "mock_gen.go")} {
+		generated, err := ioutil.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		committed, err := ioutil.ReadFile(filepath.Join(src, file))
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if !bytes.Equal(generated, committed) {
+			t.Errorf("%s is out of date, run go generate ./apitest", file)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "text/template"
+
+// interfacesTemplate is the interfaces of the API, implemented by *API
+var interfacesTemplate = template.Must(template.New("interfaces").Parse(`// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Code generated by genapi. DO NOT EDIT.
+
+package apiclient
+
+import (
+{{- range .Std}}
+	"{{.}}"
+{{- end}}
+{{range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+{{range .Ifaces}}
+// {{.Name}} is the API of the methods in {{.File}}, implemented by *API
+type {{.Name}} interface {
+{{- range .Methods}}
+	{{.Name}}{{.Sig}}
+{{- end}}
+}
+{{end}}
+// CirconusAPI is the API of every resource, implemented by *API and, to
+// test code using the API, by apitest.Mock
+type CirconusAPI interface {
+{{- range .Ifaces}}
+	{{.Name}}
+{{- end}}
+}
+
+var _ CirconusAPI = (*API)(nil)
+`))
+
+// mockTemplate is the mock of the API, calling a func field per method
+var mockTemplate = template.Must(template.New("mock").Parse(`// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Code generated by genapi. DO NOT EDIT.
+
+package apitest
+
+import (
+{{- range .Std}}
+	"{{.}}"
+{{- end}}
+	"sync"
+{{range .Imports}}
+	"{{.}}"
+{{- end}}
+	"{{.ImportPath}}"
+)
+
+// Mock implements apiclient.CirconusAPI, each method calling its func field
+// (e.g. FetchGraphFunc for FetchGraph), or returning zero values and
+// ErrNotMocked when it is nil. Calls are recorded, see Calls.
+type Mock struct {
+	mu    sync.Mutex
+	calls []Call
+{{range .Ifaces}}
+	// {{.Name}}
+{{range .Methods}}
+	{{.Name}}Func {{.FuncType}}
+{{- end}}
+{{end}}
+}
+
+var _ apiclient.CirconusAPI = (*Mock)(nil)
+{{range .Ifaces}}{{range .Methods}}
+// {{.Name}} calls {{.Name}}Func.
+func (m *Mock) {{.Name}}({{.ParamList}}) ({{.NamedResults}}) {
+	m.record("{{.Name}}"{{if .Params}}, {{.Args}}{{end}})
+	if m.{{.Name}}Func == nil {
+{{- if .Error}}
+		err = notMocked("{{.Name}}")
+{{- end}}
+		return
+	}
+	return m.{{.Name}}Func({{.CallArgs}})
+}
+{{end}}{{end}}`))
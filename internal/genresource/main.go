@@ -0,0 +1,160 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Genresource generates the standard API wiring of a resource endpoint -
+// Fetch, FetchAll (plural), Search, Create, Update, Delete, and DeleteByCID
+// - and scaffolding for its fixture server tests, so a new endpoint only
+// needs its struct written by hand.
+//
+// Define the struct (with a CID field tagged _cid) and the endpoint prefix
+// (e.g. config.WidgetPrefix, with its CIDRegex) and add, next to the struct:
+//
+//	//go:generate go run ./internal/genresource -type Widget
+//
+// go generate then writes widget_gen.go and, if it does not exist yet,
+// widget_test.go, a fixture server test to fill in with realistic values.
+// The generated wiring is rewritten on every run, do not edit it.
+//
+// Usage:
+//
+//	genresource -type Name [-prefix config.NamePrefix] [-ops fetch,list,search,create,update,delete] [-dir .]
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// allOps are the operations generated by default
+var allOps = []string{"fetch", "list", "search", "create", "update", "delete"}
+
+// resource describes the resource generated
+type resource struct {
+	Type   string // struct name, e.g. ContactGroup
+	Plural string // e.g. ContactGroups
+	Noun   string // for messages, e.g. contact group
+	Nouns  string // e.g. contact groups
+	Var    string // variable name, e.g. contactGroup
+	Prefix string // endpoint prefix constant, e.g. config.ContactGroupPrefix
+	Path   string // endpoint prefix value in tests, e.g. /contact_group
+	File   string // file name stem, e.g. contact_group
+	// ConfigPrefix is set for a Prefix in the config package, imported
+	ConfigPrefix bool
+	Ops          map[string]bool
+}
+
+func main() {
+	typ := flag.String("type", "", "resource struct name (e.g. Widget), required")
+	prefix := flag.String("prefix", "", "endpoint prefix constant (default config.<type>Prefix)")
+	ops := flag.String("ops", strings.Join(allOps, ","), "operations to generate")
+	dir := flag.String("dir", ".", "output directory")
+	flag.Parse()
+
+	res, err := newResource(*typ, *prefix, *ops)
+	if err == nil {
+		err = generate(res, *dir)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "genresource: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// newResource returns the resource for the flags
+func newResource(typ, prefix, ops string) (*resource, error) {
+	if typ == "" || !unicode.IsUpper([]rune(typ)[0]) {
+		return nil, errors.Errorf("invalid type (%s), must be an exported struct name", typ)
+	}
+	words := splitWords(typ)
+	res := &resource{
+		Type:   typ,
+		Plural: typ + "s",
+		Noun:   strings.Join(words, " "),
+		Nouns:  strings.Join(words, " ") + "s",
+		Var:    words[0] + typ[len(words[0]):],
+		Prefix: prefix,
+		Path:   "/" + strings.Join(words, "_"),
+		File:   strings.Join(words, "_"),
+		Ops:    make(map[string]bool),
+	}
+	if res.Prefix == "" {
+		res.Prefix = "config." + typ + "Prefix"
+	}
+	res.ConfigPrefix = strings.HasPrefix(res.Prefix, "config.")
+	for _, op := range strings.Split(ops, ",") {
+		op = strings.TrimSpace(op)
+		valid := false
+		for _, o := range allOps {
+			valid = valid || o == op
+		}
+		if !valid {
+			return nil, errors.Errorf("invalid operation (%s), must be one of %s", op, strings.Join(allOps, ","))
+		}
+		res.Ops[op] = true
+	}
+	if res.Ops["search"] && !res.Ops["list"] {
+		return nil, errors.New("invalid operations, search requires list")
+	}
+	return res, nil
+}
+
+// splitWords splits a struct name into lower case words, e.g. ContactGroup
+// into contact, group
+func splitWords(s string) []string {
+	var words []string
+	start := 0
+	runes := []rune(s)
+	for i := 1; i < len(runes); i++ {
+		if unicode.IsUpper(runes[i]) && (!unicode.IsUpper(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			words = append(words, strings.ToLower(string(runes[start:i])))
+			start = i
+		}
+	}
+	return append(words, strings.ToLower(string(runes[start:])))
+}
+
+// generate writes the wiring of the resource, and its test scaffolding if
+// there is no test file yet
+func generate(res *resource, dir string) error {
+	src, err := render(wiringTemplate, res)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, res.File+"_gen.go"), src, 0644); err != nil {
+		return errors.Wrap(err, "writing wiring")
+	}
+
+	testFile := filepath.Join(dir, res.File+"_test.go")
+	if _, err := os.Stat(testFile); err == nil {
+		return nil
+	}
+	src, err = render(testTemplate, res)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(ioutil.WriteFile(testFile, src, 0644), "writing test scaffolding")
+}
+
+// render executes a template for the resource, formatting the result
+func render(tmpl *template.Template, res *resource) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, res); err != nil {
+		return nil, errors.Wrapf(err, "generating %s", tmpl.Name())
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(err, "formatting %s", tmpl.Name())
+	}
+	return src, nil
+}
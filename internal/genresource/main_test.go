@@ -0,0 +1,148 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected []string
+	}{
+		{"Graph", []string{"graph"}},
+		{"ContactGroup", []string{"contact", "group"}},
+		{"CAQLCheck", []string{"caql", "check"}},
+		{"RuleSetGroup", []string{"rule", "set", "group"}},
+	}
+
+	for _, test := range tests {
+		if words := splitWords(test.in); !reflect.DeepEqual(words, test.expected) {
+			t.Errorf("%s: unexpected words (%v)", test.in, words)
+		}
+	}
+}
+
+func TestNewResource(t *testing.T) {
+	tests := []struct {
+		id     string
+		typ    string
+		ops    string
+		errStr string
+	}{
+		{"no type", "", "fetch", "invalid type (), must be an exported struct name"},
+		{"unexported type", "widget", "fetch", "invalid type (widget), must be an exported struct name"},
+		{"invalid op", "Widget", "fetch,patch", "invalid operation (patch), must be one of fetch,list,search,create,update,delete"},
+		{"search without list", "Widget", "search", "invalid operations, search requires list"},
+		{"valid", "ContactWidget", "fetch,list", ""},
+	}
+
+	for _, test := range tests {
+		res, err := newResource(test.typ, "", test.ops)
+		if test.errStr != "" {
+			if err == nil || err.Error() != test.errStr {
+				t.Errorf("%s: expected error (%s) got (%v)", test.id, test.errStr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error (%s)", test.id, err)
+		}
+		expected := &resource{
+			Type: "ContactWidget", Plural: "ContactWidgets", Noun: "contact widget", Nouns: "contact widgets",
+			Var: "contactWidget", Prefix: "config.ContactWidgetPrefix", Path: "/contact_widget", File: "contact_widget",
+			ConfigPrefix: true, Ops: map[string]bool{"fetch": true, "list": true},
+		}
+		if !reflect.DeepEqual(res, expected) {
+			t.Errorf("%s: unexpected resource (%#v)", test.id, res)
+		}
+	}
+}
+
+// parseFuncs returns the functions and imports of generated source
+func parseFuncs(t *testing.T, file string) ([]string, []string) {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	var imports []string
+	for _, imp := range f.Imports {
+		imports = append(imports, strings.Trim(imp.Path.Value, `"`))
+	}
+
+	f, err = parser.ParseFile(token.NewFileSet(), file, nil, 0)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	var funcs []string
+	for _, d := range f.Decls {
+		if fn, ok := d.(*ast.FuncDecl); ok {
+			funcs = append(funcs, fn.Name.Name)
+		}
+	}
+	sort.Strings(funcs)
+	return funcs, imports
+}
+
+func TestGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "genresource")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer os.RemoveAll(dir)
+
+	res, err := newResource("Widget", `"/widget"`, "fetch,list,search")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if err := generate(res, dir); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	funcs, imports := parseFuncs(t, filepath.Join(dir, "widget_gen.go"))
+	if expected := []string{"FetchWidget", "FetchWidgetWithContext", "FetchWidgets", "FetchWidgetsWithContext", "SearchWidgets", "SearchWidgetsWithContext"}; !reflect.DeepEqual(funcs, expected) {
+		t.Fatalf("unexpected functions (%v)", funcs)
+	}
+	if expected := []string{"context", "fmt", "strings", "github.com/pkg/errors"}; !reflect.DeepEqual(imports, expected) {
+		t.Fatalf("unexpected imports (%v)", imports)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "widget_gen.go"))
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if !strings.Contains(string(data), "// Code generated by genresource. DO NOT EDIT.") {
+		t.Fatal("expected generated code header")
+	}
+
+	funcs, _ = parseFuncs(t, filepath.Join(dir, "widget_test.go"))
+	if expected := []string{"TestFetchWidget", "TestFetchWidgets", "TestSearchWidgets", "testWidgetServer", "widgetTestBootstrap"}; !reflect.DeepEqual(funcs, expected) {
+		t.Fatalf("unexpected test functions (%v)", funcs)
+	}
+
+	// the test scaffolding, once edited, is not overwritten
+	if err := ioutil.WriteFile(filepath.Join(dir, "widget_test.go"), []byte("package apiclient\n"), 0644); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	res.Ops["create"] = true
+	if err := generate(res, dir); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if funcs, _ := parseFuncs(t, filepath.Join(dir, "widget_gen.go")); len(funcs) != 8 {
+		t.Fatalf("unexpected functions (%v)", funcs)
+	}
+	if funcs, _ := parseFuncs(t, filepath.Join(dir, "widget_test.go")); len(funcs) != 0 {
+		t.Fatalf("expected test scaffolding kept (%v)", funcs)
+	}
+}
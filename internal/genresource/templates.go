@@ -0,0 +1,585 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "text/template"
+
+// wiringTemplate is the API support of a resource, following the hand
+// written resources (e.g. worksheet.go)
+var wiringTemplate = template.Must(template.New("wiring").Parse(`// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Code generated by genresource. DO NOT EDIT.
+
+package apiclient
+
+import (
+	"context"
+{{- if or .Ops.fetch .Ops.delete}}
+	"fmt"
+	"strings"
+{{- end}}
+{{if .ConfigPrefix}}
+	"github.com/circonus-labs/go-apiclient/config"
+{{- end}}
+	"github.com/pkg/errors"
+)
+{{- if .Ops.fetch}}
+
+// Fetch{{.Type}} retrieves {{.Noun}} with passed cid.
+func (a *API) Fetch{{.Type}}(cid CIDType) (*{{.Type}}, error) {
+	return a.Fetch{{.Type}}WithContext(context.Background(), cid)
+}
+
+// Fetch{{.Type}}WithContext retrieves {{.Noun}} with passed cid,
+// stopping when ctx is done.
+func (a *API) Fetch{{.Type}}WithContext(ctx context.Context, cid CIDType) (*{{.Type}}, error) {
+	if cid == nil || *cid == "" {
+		return nil, errors.New("invalid {{.Noun}} CID (none)")
+	}
+
+	var {{.Var}}CID string
+	if !strings.HasPrefix(*cid, {{.Prefix}}) {
+		{{.Var}}CID = fmt.Sprintf("%s/%s", {{.Prefix}}, *cid)
+	} else {
+		{{.Var}}CID = *cid
+	}
+
+	if !validCID({{.Prefix}}, {{.Var}}CID) {
+		return nil, errors.Errorf("invalid {{.Noun}} CID (%s)", {{.Var}}CID)
+	}
+
+	result, err := a.GetWithContext(ctx, {{.Var}}CID)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching {{.Noun}}")
+	}
+
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch {{.Noun}}, received JSON: %s", string(result))
+	}
+
+	{{.Var}} := new({{.Type}})
+	if err := a.unmarshalJSON(result, {{.Var}}); err != nil {
+		return nil, errors.Wrap(err, "parsing {{.Noun}}")
+	}
+
+	return {{.Var}}, nil
+}
+{{- end}}
+{{- if .Ops.list}}
+
+// Fetch{{.Plural}} retrieves all {{.Nouns}} available to API Token.
+func (a *API) Fetch{{.Plural}}() (*[]{{.Type}}, error) {
+	return a.Fetch{{.Plural}}WithContext(context.Background())
+}
+
+// Fetch{{.Plural}}WithContext retrieves all {{.Nouns}} available to API
+// Token, stopping when ctx is done.
+func (a *API) Fetch{{.Plural}}WithContext(ctx context.Context) (*[]{{.Type}}, error) {
+	var {{.Var}}s []{{.Type}}
+	if err := a.getJSONContext(ctx, {{.Prefix}}, &{{.Var}}s); err != nil {
+		return nil, errors.Wrap(err, "fetching {{.Nouns}}")
+	}
+
+	return &{{.Var}}s, nil
+}
+{{- end}}
+{{- if .Ops.update}}
+
+// Update{{.Type}} updates passed {{.Noun}}.
+func (a *API) Update{{.Type}}(cfg *{{.Type}}) (*{{.Type}}, error) {
+	return a.Update{{.Type}}WithContext(context.Background(), cfg)
+}
+
+// Update{{.Type}}WithContext updates passed {{.Noun}}, stopping when ctx
+// is done.
+func (a *API) Update{{.Type}}WithContext(ctx context.Context, cfg *{{.Type}}) (*{{.Type}}, error) {
+	if cfg == nil {
+		return nil, errors.New("invalid {{.Noun}} config (nil)")
+	}
+
+	{{.Var}}CID := cfg.CID
+
+	if !validCID({{.Prefix}}, {{.Var}}CID) {
+		return nil, errors.Errorf("invalid {{.Noun}} CID (%s)", {{.Var}}CID)
+	}
+
+	jsonCfg, err := a.marshalJSON(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.debugEnabled() {
+		a.apiLog().Printf("update {{.Noun}}, sending JSON: %s", jsonCfg.String())
+	}
+
+	result, err := a.PutWithContext(ctx, {{.Var}}CID, jsonCfg.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "updating {{.Noun}}")
+	}
+
+	{{.Var}} := &{{.Type}}{}
+	if err := a.unmarshalJSON(result, {{.Var}}); err != nil {
+		return nil, errors.Wrap(err, "parsing {{.Noun}}")
+	}
+
+	return {{.Var}}, nil
+}
+{{- end}}
+{{- if .Ops.create}}
+
+// Create{{.Type}} creates a new {{.Noun}}.
+func (a *API) Create{{.Type}}(cfg *{{.Type}}) (*{{.Type}}, error) {
+	return a.Create{{.Type}}WithContext(context.Background(), cfg)
+}
+
+// Create{{.Type}}WithContext creates a new {{.Noun}}, stopping when ctx
+// is done.
+func (a *API) Create{{.Type}}WithContext(ctx context.Context, cfg *{{.Type}}) (*{{.Type}}, error) {
+	if cfg == nil {
+		return nil, errors.New("invalid {{.Noun}} config (nil)")
+	}
+
+	jsonCfg, err := a.marshalJSON(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.debugEnabled() {
+		a.apiLog().Printf("create {{.Noun}}, sending JSON: %s", jsonCfg.String())
+	}
+
+	result, err := a.PostWithContext(ctx, {{.Prefix}}, jsonCfg.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "creating {{.Noun}}")
+	}
+
+	{{.Var}} := &{{.Type}}{}
+	if err := a.unmarshalJSON(result, {{.Var}}); err != nil {
+		return nil, errors.Wrap(err, "parsing {{.Noun}}")
+	}
+
+	return {{.Var}}, nil
+}
+{{- end}}
+{{- if .Ops.delete}}
+
+// Delete{{.Type}} deletes passed {{.Noun}}.
+func (a *API) Delete{{.Type}}(cfg *{{.Type}}) (bool, error) {
+	return a.Delete{{.Type}}WithContext(context.Background(), cfg)
+}
+
+// Delete{{.Type}}WithContext deletes passed {{.Noun}}, stopping when ctx
+// is done.
+func (a *API) Delete{{.Type}}WithContext(ctx context.Context, cfg *{{.Type}}) (bool, error) {
+	if cfg == nil {
+		return false, errors.New("invalid {{.Noun}} config (nil)")
+	}
+	return a.Delete{{.Type}}ByCIDWithContext(ctx, CIDType(&cfg.CID))
+}
+
+// Delete{{.Type}}ByCID deletes {{.Noun}} with passed cid.
+func (a *API) Delete{{.Type}}ByCID(cid CIDType) (bool, error) {
+	return a.Delete{{.Type}}ByCIDWithContext(context.Background(), cid)
+}
+
+// Delete{{.Type}}ByCIDWithContext deletes {{.Noun}} with passed cid,
+// stopping when ctx is done.
+func (a *API) Delete{{.Type}}ByCIDWithContext(ctx context.Context, cid CIDType) (bool, error) {
+	if cid == nil || *cid == "" {
+		return false, errors.New("invalid {{.Noun}} CID (none)")
+	}
+
+	var {{.Var}}CID string
+	if !strings.HasPrefix(*cid, {{.Prefix}}) {
+		{{.Var}}CID = fmt.Sprintf("%s/%s", {{.Prefix}}, *cid)
+	} else {
+		{{.Var}}CID = *cid
+	}
+
+	if !validCID({{.Prefix}}, {{.Var}}CID) {
+		return false, errors.Errorf("invalid {{.Noun}} CID (%s)", {{.Var}}CID)
+	}
+
+	_, err := a.DeleteWithContext(ctx, {{.Var}}CID)
+	if err != nil {
+		return false, errors.Wrap(err, "deleting {{.Noun}}")
+	}
+
+	return true, nil
+}
+{{- end}}
+{{- if .Ops.search}}
+
+// Search{{.Plural}} returns {{.Nouns}} matching the specified search
+// query and/or filter. If nil is passed for both parameters all
+// {{.Nouns}} will be returned.
+func (a *API) Search{{.Plural}}(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]{{.Type}}, error) {
+	return a.Search{{.Plural}}WithContext(context.Background(), searchCriteria, filterCriteria)
+}
+
+// Search{{.Plural}}WithContext returns {{.Nouns}} matching the specified
+// search query and/or filter, stopping when ctx is done.
+func (a *API) Search{{.Plural}}WithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]{{.Type}}, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.Fetch{{.Plural}}WithContext(ctx)
+	}
+
+	var {{.Var}}s []{{.Type}}
+	if err := a.searchJSONContext(ctx, {{.Prefix}}, searchCriteria, filterCriteria, &{{.Var}}s); err != nil {
+		return nil, errors.Wrap(err, "searching {{.Nouns}}")
+	}
+
+	return &{{.Var}}s, nil
+}
+{{- end}}
+`))
+
+// testTemplate is the fixture server test scaffolding of a resource,
+// written once and then maintained by hand
+var testTemplate = template.Must(template.New("test").Parse(`// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+var (
+	// TODO: fill in a realistic {{.Noun}}
+	test{{.Type}} = {{.Type}}{
+		CID: "{{.Path}}/1234",
+	}
+)
+
+func test{{.Type}}Server() *httptest.Server {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		switch path {
+		case "{{.Path}}/1234":
+			switch r.Method {
+			case "GET":
+				ret, err := json.Marshal(test{{.Type}})
+				if err != nil {
+					panic(err)
+				}
+				w.WriteHeader(200)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintln(w, string(ret))
+			case "PUT":
+				defer r.Body.Close()
+				b, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					panic(err)
+				}
+				w.WriteHeader(200)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintln(w, string(b))
+			case "DELETE":
+				w.WriteHeader(200)
+				w.Header().Set("Content-Type", "application/json")
+			default:
+				w.WriteHeader(404)
+				fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
+			}
+		case "{{.Path}}":
+			switch r.Method {
+			case "GET":
+				var c []{{.Type}}
+				reqURL := r.URL.String()
+				switch reqURL {
+				case "{{.Path}}?search=test":
+					c = []{{.Type}}{test{{.Type}}}
+				case "{{.Path}}":
+					c = []{{.Type}}{test{{.Type}}}
+				default:
+					c = []{{.Type}}{}
+				}
+				if len(c) > 0 {
+					ret, err := json.Marshal(c)
+					if err != nil {
+						panic(err)
+					}
+					w.WriteHeader(200)
+					w.Header().Set("Content-Type", "application/json")
+					fmt.Fprintln(w, string(ret))
+				} else {
+					w.WriteHeader(404)
+					fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, reqURL))
+				}
+			case "POST":
+				defer r.Body.Close()
+				_, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					panic(err)
+				}
+				ret, err := json.Marshal(test{{.Type}})
+				if err != nil {
+					panic(err)
+				}
+				w.WriteHeader(200)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintln(w, string(ret))
+			default:
+				w.WriteHeader(404)
+				fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
+			}
+		default:
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
+		}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(f))
+}
+
+func {{.Var}}TestBootstrap(t *testing.T) (*API, *httptest.Server) {
+	server := test{{.Type}}Server()
+
+	ac := &Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      server.URL,
+	}
+	apih, err := NewAPI(ac)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+		server.Close()
+		return nil, nil
+	}
+
+	return apih, server
+}
+{{- if .Ops.fetch}}
+
+func TestFetch{{.Type}}(t *testing.T) {
+	apih, server := {{.Var}}TestBootstrap(t)
+	defer server.Close()
+
+	tests := []struct {
+		id           string
+		cid          string
+		expectedType string
+		shouldFail   bool
+		expectedErr  string
+	}{
+		{"empty cid", "", "", true, "invalid {{.Noun}} CID (none)"},
+		{"short cid", "1234", "*apiclient.{{.Type}}", false, ""},
+		{"long cid", "{{.Path}}/1234", "*apiclient.{{.Type}}", false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			{{.Var}}, err := apih.Fetch{{.Type}}(CIDType(&test.cid))
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				} else if reflect.TypeOf({{.Var}}).String() != test.expectedType {
+					t.Fatalf("unexpected type (%s)", reflect.TypeOf({{.Var}}).String())
+				}
+			}
+		})
+	}
+}
+{{- end}}
+{{- if .Ops.list}}
+
+func TestFetch{{.Plural}}(t *testing.T) {
+	apih, server := {{.Var}}TestBootstrap(t)
+	defer server.Close()
+
+	{{.Var}}s, err := apih.Fetch{{.Plural}}()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if reflect.TypeOf({{.Var}}s).String() != "*[]apiclient.{{.Type}}" {
+		t.Fatalf("unexpected type (%s)", reflect.TypeOf({{.Var}}s).String())
+	}
+}
+{{- end}}
+{{- if .Ops.update}}
+
+func TestUpdate{{.Type}}(t *testing.T) {
+	apih, server := {{.Var}}TestBootstrap(t)
+	defer server.Close()
+
+	tests := []struct {
+		id           string
+		cfg          *{{.Type}}
+		expectedType string
+		shouldFail   bool
+		expectedErr  string
+	}{
+		{"invalid (nil)", nil, "", true, "invalid {{.Noun}} config (nil)"},
+		{"invalid (cid)", &{{.Type}}{CID: "/invalid"}, "", true, "invalid {{.Noun}} CID (/invalid)"},
+		{"valid", &test{{.Type}}, "*apiclient.{{.Type}}", false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			{{.Var}}, err := apih.Update{{.Type}}(test.cfg)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				} else if reflect.TypeOf({{.Var}}).String() != test.expectedType {
+					t.Fatalf("unexpected type (%s)", reflect.TypeOf({{.Var}}).String())
+				}
+			}
+		})
+	}
+}
+{{- end}}
+{{- if .Ops.create}}
+
+func TestCreate{{.Type}}(t *testing.T) {
+	apih, server := {{.Var}}TestBootstrap(t)
+	defer server.Close()
+
+	tests := []struct {
+		id           string
+		cfg          *{{.Type}}
+		expectedType string
+		shouldFail   bool
+		expectedErr  string
+	}{
+		{"invalid (nil)", nil, "", true, "invalid {{.Noun}} config (nil)"},
+		{"valid", &test{{.Type}}, "*apiclient.{{.Type}}", false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			{{.Var}}, err := apih.Create{{.Type}}(test.cfg)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				} else if reflect.TypeOf({{.Var}}).String() != test.expectedType {
+					t.Fatalf("unexpected type (%s)", reflect.TypeOf({{.Var}}).String())
+				}
+			}
+		})
+	}
+}
+{{- end}}
+{{- if .Ops.delete}}
+
+func TestDelete{{.Type}}(t *testing.T) {
+	apih, server := {{.Var}}TestBootstrap(t)
+	defer server.Close()
+
+	tests := []struct {
+		id          string
+		cfg         *{{.Type}}
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (nil)", nil, true, "invalid {{.Noun}} config (nil)"},
+		{"valid", &test{{.Type}}, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			wasDeleted, err := apih.Delete{{.Type}}(test.cfg)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				} else if !wasDeleted {
+					t.Fatal("expected true (deleted)")
+				}
+			}
+		})
+	}
+}
+
+func TestDelete{{.Type}}ByCID(t *testing.T) {
+	apih, server := {{.Var}}TestBootstrap(t)
+	defer server.Close()
+
+	tests := []struct {
+		id          string
+		cid         string
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"empty cid", "", true, "invalid {{.Noun}} CID (none)"},
+		{"short cid", "1234", false, ""},
+		{"long cid", "{{.Path}}/1234", false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			wasDeleted, err := apih.Delete{{.Type}}ByCID(CIDType(&test.cid))
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				} else if !wasDeleted {
+					t.Fatal("expected true (deleted)")
+				}
+			}
+		})
+	}
+}
+{{- end}}
+{{- if .Ops.search}}
+
+func TestSearch{{.Plural}}(t *testing.T) {
+	apih, server := {{.Var}}TestBootstrap(t)
+	defer server.Close()
+
+	search := SearchQueryType("test")
+	{{.Var}}s, err := apih.Search{{.Plural}}(&search, nil)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(*{{.Var}}s) != 1 {
+		t.Fatalf("unexpected {{.Nouns}} (%v)", *{{.Var}}s)
+	}
+}
+{{- end}}
+`))
@@ -0,0 +1,524 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package irondb reads stored data directly from a Circonus IRONdb node, via
+// its raw, rollup, histogram and graphite endpoints, for deployments where
+// heavy data reads should bypass the main API. Requests carry the same token
+// headers as API requests. When an API is configured, FetchData and
+// FetchHistogramData fall back to the API's data endpoint if the node fails.
+//
+//	c, _ := irondb.New(&irondb.Config{URL: "http://irondb:8112", TokenKey: key, API: apih})
+//	data, err := c.FetchData(check, "duration", start, end, time.Minute)
+package irondb
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+
+	// rollupTypes are requested in the order rollupPoint decodes them
+	rollupTypes = "count,average,stddev,derive,derive_stddev,counter,counter_stddev"
+)
+
+// Config defines the IRONdb node configuration
+type Config struct {
+	// URL defines the node URL (e.g. http://irondb:8112), required
+	URL string
+
+	// TokenKey, TokenApp and TokenAccountID are sent with each request, as
+	// with API requests, for nodes behind an authenticating proxy
+	TokenKey       string
+	TokenApp       string
+	TokenAccountID string
+
+	// AccountID defines the IRONdb account used for graphite requests - default 1
+	AccountID int
+
+	// GraphitePrefix defines the optional query prefix of graphite requests
+	GraphitePrefix string
+
+	// API is used as a fallback for FetchData and FetchHistogramData when the
+	// node request fails, optional
+	API *apiclient.API
+
+	// TLSConfig defines a custom tls configuration for https node URLs
+	TLSConfig *tls.Config
+
+	// Timeout defines the request timeout - default 30s
+	Timeout time.Duration
+}
+
+// Client reads data from an IRONdb node
+type Client struct {
+	client         *http.Client
+	url            string
+	key            string
+	app            string
+	tokenAccountID string
+	accountID      int
+	graphitePrefix string
+	api            *apiclient.API
+}
+
+// RawPoint defines a single raw (unrolled) value of a metric. Value is set for
+// numeric metrics, Text for text metrics.
+type RawPoint struct {
+	Timestamp time.Time
+	Value     *float64
+	Text      *string
+}
+
+// UnmarshalJSON decodes a raw point, encoded as [timestamp (ms), value] by IRONdb.
+func (p *RawPoint) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return errors.Wrap(err, "parsing raw point")
+	}
+	if len(raw) != 2 {
+		return errors.Errorf("invalid raw point, expected [timestamp, value] (%s)", string(b))
+	}
+
+	var ms float64
+	if err := json.Unmarshal(raw[0], &ms); err != nil {
+		return errors.Wrap(err, "parsing raw point timestamp")
+	}
+	p.Timestamp = time.Unix(0, int64(ms)*int64(time.Millisecond)).UTC()
+
+	p.Value, p.Text = nil, nil
+	var v interface{}
+	if err := json.Unmarshal(raw[1], &v); err != nil {
+		return errors.Wrap(err, "parsing raw point value")
+	}
+	switch v := v.(type) {
+	case float64:
+		p.Value = &v
+	case string:
+		p.Text = &v
+	}
+
+	return nil
+}
+
+// GraphiteMetric defines a metric (or branch) found by a graphite query
+type GraphiteMetric struct {
+	Leaf bool   `json:"leaf"` // bool
+	Name string `json:"name"` // string
+}
+
+// GraphiteSeries defines the values of a graphite metric, one per step
+// between From and Until. Values are nil for steps without data.
+type GraphiteSeries struct {
+	Name   string
+	From   time.Time
+	Until  time.Time
+	Step   time.Duration
+	Values []*float64
+}
+
+// New returns a Client for the IRONdb node in the passed config.
+func New(cfg *Config) (*Client, error) {
+	if cfg == nil {
+		return nil, errors.New("invalid irondb config (nil)")
+	}
+	if cfg.URL == "" {
+		return nil, errors.New("invalid irondb URL (none)")
+	}
+	u, err := url.Parse(strings.TrimSuffix(cfg.URL, "/"))
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing irondb URL")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Errorf("invalid irondb URL (%s)", cfg.URL)
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	accountID := cfg.AccountID
+	if accountID <= 0 {
+		accountID = 1
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   timeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout: timeout,
+		TLSClientConfig:     cfg.TLSConfig,
+	}
+
+	return &Client{
+		client:         &http.Client{Transport: transport, Timeout: timeout},
+		url:            u.String(),
+		key:            cfg.TokenKey,
+		app:            cfg.TokenApp,
+		tokenAccountID: cfg.TokenAccountID,
+		accountID:      accountID,
+		graphitePrefix: strings.Trim(cfg.GraphitePrefix, "/"),
+		api:            cfg.API,
+	}, nil
+}
+
+// FetchRaw retrieves the raw values of a check metric between start and end.
+func (c *Client) FetchRaw(checkUUID, metricName string, start, end time.Time) ([]RawPoint, error) {
+	if err := validateMetric(checkUUID, metricName); err != nil {
+		return nil, err
+	}
+	if err := validateRange(start, end); err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	q.Set("start_ts", formatSeconds(start))
+	q.Set("end_ts", formatSeconds(end))
+
+	result, err := c.request("GET", metricPath("/raw", checkUUID, metricName), q, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	points := []RawPoint{}
+	if err := json.Unmarshal(result, &points); err != nil {
+		return nil, errors.Wrap(err, "parsing raw data")
+	}
+	return points, nil
+}
+
+// FetchRollup retrieves the numeric data of a check metric between start and
+// end, rolled up into periods of the passed duration, from the node.
+func (c *Client) FetchRollup(checkUUID, metricName string, start, end time.Time, period time.Duration) ([]apiclient.DataPoint, error) {
+	if err := validateMetric(checkUUID, metricName); err != nil {
+		return nil, err
+	}
+	if err := validateRange(start, end); err != nil {
+		return nil, err
+	}
+	if err := validatePeriod(period); err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	q.Set("start_ts", formatSeconds(start))
+	q.Set("end_ts", formatSeconds(end))
+	q.Set("rollup_span", fmt.Sprintf("%ds", int64(period/time.Second)))
+	q.Set("type", rollupTypes)
+
+	result, err := c.request("GET", metricPath("/rollup", checkUUID, metricName), q, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows [][]json.RawMessage
+	if err := json.Unmarshal(result, &rows); err != nil {
+		return nil, errors.Wrap(err, "parsing rollup data")
+	}
+
+	points := make([]apiclient.DataPoint, 0, len(rows))
+	for _, row := range rows {
+		p, err := rollupPoint(row)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+// FetchHistogram retrieves the histogram data of a check metric between start
+// and end, rolled up into periods of the passed duration, from the node.
+func (c *Client) FetchHistogram(checkUUID, metricName string, start, end time.Time, period time.Duration) ([]apiclient.HistogramDataPoint, error) {
+	if err := validateMetric(checkUUID, metricName); err != nil {
+		return nil, err
+	}
+	if err := validateRange(start, end); err != nil {
+		return nil, err
+	}
+	if err := validatePeriod(period); err != nil {
+		return nil, err
+	}
+
+	reqPath := fmt.Sprintf("/histogram/%d/%d/%d", start.Unix(), end.Unix(), int64(period/time.Second))
+	result, err := c.request("GET", metricPath(reqPath, checkUUID, metricName), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	points := []apiclient.HistogramDataPoint{}
+	if err := json.Unmarshal(result, &points); err != nil {
+		return nil, errors.Wrap(err, "parsing histogram data")
+	}
+	return points, nil
+}
+
+// FetchData retrieves the numeric data of a check metric, as the API's
+// FetchData does, from the node. If the node request fails and an API is
+// configured, the data is retrieved from the API instead.
+func (c *Client) FetchData(check *apiclient.Check, metricName string, start, end time.Time, period time.Duration) (*apiclient.Data, error) {
+	if check == nil {
+		return nil, errors.New("invalid check (nil)")
+	}
+
+	points, err := c.FetchRollup(check.CheckUUID, metricName, start, end, period)
+	if err == nil {
+		return &apiclient.Data{CID: dataCID(check, metricName), Points: points}, nil
+	}
+	if c.api == nil || check.CID == "" {
+		return nil, err
+	}
+
+	cid := check.CID
+	data, apiErr := c.api.FetchData(&cid, metricName, start, end, period)
+	if apiErr != nil {
+		return nil, errors.Wrapf(apiErr, "irondb fetch failed (%s), api fallback", err)
+	}
+	return data, nil
+}
+
+// FetchHistogramData retrieves the histogram data of a check metric, as the
+// API's FetchHistogramData does, from the node. If the node request fails and
+// an API is configured, the data is retrieved from the API instead.
+func (c *Client) FetchHistogramData(check *apiclient.Check, metricName string, start, end time.Time, period time.Duration) (*apiclient.HistogramData, error) {
+	if check == nil {
+		return nil, errors.New("invalid check (nil)")
+	}
+
+	points, err := c.FetchHistogram(check.CheckUUID, metricName, start, end, period)
+	if err == nil {
+		return &apiclient.HistogramData{CID: dataCID(check, metricName), Points: points}, nil
+	}
+	if c.api == nil || check.CID == "" {
+		return nil, err
+	}
+
+	cid := check.CID
+	data, apiErr := c.api.FetchHistogramData(&cid, metricName, start, end, period)
+	if apiErr != nil {
+		return nil, errors.Wrapf(apiErr, "irondb fetch failed (%s), api fallback", err)
+	}
+	return data, nil
+}
+
+// FindGraphite returns the graphite metrics matching the passed query
+// (e.g. "web.*.requests").
+func (c *Client) FindGraphite(query string) ([]GraphiteMetric, error) {
+	if query == "" {
+		return nil, errors.New("invalid graphite query (none)")
+	}
+
+	q := url.Values{}
+	q.Set("query", query)
+
+	result, err := c.request("GET", c.graphitePath("/metrics/find"), q, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := []GraphiteMetric{}
+	if err := json.Unmarshal(result, &metrics); err != nil {
+		return nil, errors.Wrap(err, "parsing graphite metrics")
+	}
+	return metrics, nil
+}
+
+// FetchGraphite retrieves the values of the passed graphite metric names
+// between start and end.
+func (c *Client) FetchGraphite(names []string, start, end time.Time) ([]GraphiteSeries, error) {
+	if len(names) == 0 {
+		return nil, errors.New("invalid graphite names (none)")
+	}
+	if err := validateRange(start, end); err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"start": start.Unix(),
+		"end":   end.Unix(),
+		"names": names,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding graphite request")
+	}
+
+	result, err := c.request("POST", c.graphitePath("/series_multi"), nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		From   int64                 `json:"from"`
+		Until  int64                 `json:"until"`
+		Step   int64                 `json:"step"`
+		Series map[string][]*float64 `json:"series"`
+	}
+	if err := json.Unmarshal(result, &resp); err != nil {
+		return nil, errors.Wrap(err, "parsing graphite series")
+	}
+
+	series := make([]GraphiteSeries, 0, len(names))
+	for _, name := range names {
+		values, ok := resp.Series[name]
+		if !ok {
+			continue
+		}
+		series = append(series, GraphiteSeries{
+			Name:   name,
+			From:   time.Unix(resp.From, 0).UTC(),
+			Until:  time.Unix(resp.Until, 0).UTC(),
+			Step:   time.Duration(resp.Step) * time.Second,
+			Values: values,
+		})
+	}
+	return series, nil
+}
+
+// request sends a request to the node, returning the response body
+func (c *Client) request(method, reqPath string, q url.Values, data []byte) ([]byte, error) {
+	reqURL := c.url + reqPath
+	if len(q) > 0 {
+		reqURL += "?" + q.Encode()
+	}
+
+	req, err := http.NewRequest(method, reqURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "creating irondb request")
+	}
+	req.Header.Set("Accept", "application/json")
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.key != "" {
+		req.Header.Set("X-Circonus-Auth-Token", c.key)
+	}
+	if c.app != "" {
+		req.Header.Set("X-Circonus-App-Name", c.app)
+	}
+	if c.tokenAccountID != "" {
+		req.Header.Set("X-Circonus-Account-ID", c.tokenAccountID)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "irondb request")
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading irondb response")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, errors.Errorf("IRONdb response code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return body, nil
+}
+
+// graphitePath returns the path of a graphite endpoint for the account and prefix
+func (c *Client) graphitePath(endpoint string) string {
+	p := fmt.Sprintf("/graphite/%d", c.accountID)
+	if c.graphitePrefix != "" {
+		p += "/" + c.graphitePrefix
+	}
+	return p + endpoint
+}
+
+// rollupPoint decodes a rollup row, [timestamp, [values in rollupTypes order]]
+func rollupPoint(row []json.RawMessage) (apiclient.DataPoint, error) {
+	p := apiclient.DataPoint{}
+	if len(row) != 2 {
+		return p, errors.Errorf("invalid rollup point, expected [timestamp, values] (%d elements)", len(row))
+	}
+
+	var ts float64
+	if err := json.Unmarshal(row[0], &ts); err != nil {
+		return p, errors.Wrap(err, "parsing rollup point timestamp")
+	}
+	p.Timestamp = time.Unix(int64(ts), 0).UTC()
+
+	var values []*float64
+	if err := json.Unmarshal(row[1], &values); err != nil {
+		return p, errors.Wrap(err, "parsing rollup point values")
+	}
+	if len(values) != len(strings.Split(rollupTypes, ",")) {
+		return p, errors.Errorf("invalid rollup point, expected %d values (%d)", len(strings.Split(rollupTypes, ",")), len(values))
+	}
+
+	if values[0] != nil {
+		count := uint64(*values[0])
+		p.Count = &count
+	}
+	p.Value = values[1]
+	p.Stddev = values[2]
+	p.Derivative = values[3]
+	p.DerivativeStddev = values[4]
+	p.Counter = values[5]
+	p.CounterStddev = values[6]
+
+	return p, nil
+}
+
+// metricPath returns the path of a check metric below the passed endpoint
+func metricPath(endpoint, checkUUID, metricName string) string {
+	return fmt.Sprintf("%s/%s/%s", endpoint, checkUUID, url.PathEscape(metricName))
+}
+
+// dataCID returns the API data cid of a check metric
+func dataCID(check *apiclient.Check, metricName string) string {
+	if check.CID == "" {
+		return ""
+	}
+	checkID := strings.TrimPrefix(check.CID, config.CheckPrefix+"/")
+	return fmt.Sprintf("%s/%s_%s", config.DataPrefix, checkID, metricName)
+}
+
+func validateMetric(checkUUID, metricName string) error {
+	if checkUUID == "" {
+		return errors.New("invalid check UUID (none)")
+	}
+	if strings.Contains(checkUUID, "/") {
+		return errors.Errorf("invalid check UUID (%s)", checkUUID)
+	}
+	if metricName == "" {
+		return errors.New("invalid metric name (none)")
+	}
+	return nil
+}
+
+func validateRange(start, end time.Time) error {
+	if !end.After(start) {
+		return errors.Errorf("invalid data range (%s - %s)", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func validatePeriod(period time.Duration) error {
+	if period < time.Second {
+		return errors.Errorf("invalid data period (%s)", period)
+	}
+	return nil
+}
+
+// formatSeconds returns the time as (fractional) seconds since the epoch
+func formatSeconds(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/float64(time.Second), 'f', -1, 64)
+}
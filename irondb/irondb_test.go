@@ -0,0 +1,251 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package irondb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
+)
+
+const testUUID = "8f2b6f3a-1c2d-4e5f-8a9b-0c1d2e3f4a5b"
+
+var (
+	testStart = time.Unix(1483033000, 0)
+	testEnd   = time.Unix(1483033120, 0)
+)
+
+func testNodeServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Circonus-Auth-Token") != "abc123" {
+			w.WriteHeader(403)
+			fmt.Fprintln(w, "forbidden")
+			return
+		}
+
+		switch r.URL.Path {
+		case "/raw/" + testUUID + "/duration":
+			if r.URL.Query().Get("start_ts") != "1483033000" || r.URL.Query().Get("end_ts") != "1483033120" {
+				w.WriteHeader(400)
+				return
+			}
+			fmt.Fprint(w, `[[1483033000500, 1.5], [1483033010000, "up"]]`)
+		case "/rollup/" + testUUID + "/duration":
+			if r.URL.Query().Get("rollup_span") != "60s" || r.URL.Query().Get("type") != rollupTypes {
+				w.WriteHeader(400)
+				return
+			}
+			fmt.Fprint(w, `[[1483033000, [3, 1.5, 0.1, 0.2, 0.01, 0.3, 0.02]], [1483033060, [0, null, null, null, null, null, null]]]`)
+		case "/histogram/1483033000/1483033120/60/" + testUUID + "/latency":
+			fmt.Fprint(w, `[[1483033000, 60, {"+10e-001": 2}]]`)
+		case "/graphite/1/circonus/metrics/find":
+			fmt.Fprintf(w, `[{"leaf": true, "name": "%s"}]`, r.URL.Query().Get("query"))
+		case "/graphite/1/circonus/series_multi":
+			b, _ := ioutil.ReadAll(r.Body)
+			var req struct {
+				Start int64    `json:"start"`
+				Names []string `json:"names"`
+			}
+			if err := json.Unmarshal(b, &req); err != nil || r.Method != "POST" {
+				w.WriteHeader(400)
+				return
+			}
+			fmt.Fprintf(w, `{"from": %d, "until": %d, "step": 60, "series": {"%s": [1, null]}}`, req.Start, req.Start+120, req.Names[0])
+		default:
+			w.WriteHeader(500)
+			fmt.Fprintln(w, "unavailable")
+		}
+	}))
+}
+
+func testClient(t *testing.T, nodeURL string, api *apiclient.API) *Client {
+	c, err := New(&Config{URL: nodeURL, TokenKey: "abc123", GraphitePrefix: "/circonus/", API: api})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	return c
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		id          string
+		cfg         *Config
+		expectedErr string
+	}{
+		{"invalid (nil)", nil, "invalid irondb config (nil)"},
+		{"invalid (url none)", &Config{}, "invalid irondb URL (none)"},
+		{"invalid (url scheme)", &Config{URL: "irondb:8112"}, "invalid irondb URL (irondb:8112)"},
+		{"valid", &Config{URL: "http://irondb:8112/"}, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			c, err := New(test.cfg)
+			if test.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				if c.url != "http://irondb:8112" || c.accountID != 1 {
+					t.Fatalf("unexpected client (%#v)", c)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			} else if err.Error() != test.expectedErr {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+		})
+	}
+}
+
+func TestFetchRaw(t *testing.T) {
+	server := testNodeServer()
+	defer server.Close()
+	c := testClient(t, server.URL, nil)
+
+	points, err := c.FetchRaw(testUUID, "duration", testStart, testEnd)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("unexpected points (%#v)", points)
+	}
+	if !points[0].Timestamp.Equal(time.Unix(1483033000, 500000000)) || *points[0].Value != 1.5 || points[0].Text != nil {
+		t.Fatalf("unexpected numeric point (%#v)", points[0])
+	}
+	if points[1].Value != nil || *points[1].Text != "up" {
+		t.Fatalf("unexpected text point (%#v)", points[1])
+	}
+
+	if _, err := c.FetchRaw("", "duration", testStart, testEnd); err == nil || err.Error() != "invalid check UUID (none)" {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+	if _, err := c.FetchRaw(testUUID, "duration", testEnd, testStart); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestFetchData(t *testing.T) {
+	server := testNodeServer()
+	defer server.Close()
+	c := testClient(t, server.URL, nil)
+
+	check := &apiclient.Check{CID: "/check/1234", CheckUUID: testUUID}
+	data, err := c.FetchData(check, "duration", testStart, testEnd, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if data.CID != "/data/1234_duration" || len(data.Points) != 2 {
+		t.Fatalf("unexpected data (%#v)", data)
+	}
+	p := data.Points[0]
+	if *p.Count != 3 || *p.Value != 1.5 || *p.Stddev != 0.1 || *p.Derivative != 0.2 || *p.CounterStddev != 0.02 {
+		t.Fatalf("unexpected point (%#v)", p)
+	}
+	if !reflect.DeepEqual(data.Values(), []float64{1.5}) {
+		t.Fatalf("unexpected values (%v)", data.Values())
+	}
+
+	if _, err := c.FetchData(check, "duration", testStart, testEnd, time.Millisecond); err == nil || err.Error() != "invalid data period (1ms)" {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+	if _, err := c.FetchData(check, "missing", testStart, testEnd, time.Minute); err == nil || err.Error() != "IRONdb response code 500: unavailable" {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+}
+
+func TestFetchHistogramData(t *testing.T) {
+	server := testNodeServer()
+	defer server.Close()
+	c := testClient(t, server.URL, nil)
+
+	data, err := c.FetchHistogramData(&apiclient.Check{CID: "/check/1234", CheckUUID: testUUID}, "latency", testStart, testEnd, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(data.Points) != 1 || data.Merged().Count() != 2 {
+		t.Fatalf("unexpected data (%#v)", data)
+	}
+}
+
+func TestFetchDataFallback(t *testing.T) {
+	server := testNodeServer()
+	defer server.Close()
+
+	v := 42.0
+	fake, err := fakecirconus.New(&fakecirconus.Config{
+		Fixtures: map[string]interface{}{
+			"/data/1234_missing?end=1483033120&period=60&start=1483033000&type=numeric": apiclient.Data{
+				Points: []apiclient.DataPoint{{Timestamp: testStart, DataValues: apiclient.DataValues{Value: &v}}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+	apih, err := apiclient.New(&apiclient.Config{TokenKey: "abc123", TokenApp: "test", URL: fake.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	c := testClient(t, server.URL, apih)
+	check := &apiclient.Check{CID: "/check/1234", CheckUUID: testUUID}
+
+	data, err := c.FetchData(check, "missing", testStart, testEnd, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if !reflect.DeepEqual(data.Values(), []float64{42}) {
+		t.Fatalf("unexpected values (%v)", data.Values())
+	}
+
+	if _, err := c.FetchHistogramData(check, "missing", testStart, testEnd, time.Minute); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestGraphite(t *testing.T) {
+	server := testNodeServer()
+	defer server.Close()
+	c := testClient(t, server.URL, nil)
+
+	metrics, err := c.FindGraphite("web.*.requests")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if !reflect.DeepEqual(metrics, []GraphiteMetric{{Leaf: true, Name: "web.*.requests"}}) {
+		t.Fatalf("unexpected metrics (%#v)", metrics)
+	}
+
+	series, err := c.FetchGraphite([]string{"web.a.requests", "web.b.requests"}, testStart, testEnd)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(series) != 1 {
+		t.Fatalf("unexpected series (%#v)", series)
+	}
+	s := series[0]
+	if s.Name != "web.a.requests" || !s.From.Equal(testStart) || !s.Until.Equal(testEnd) || s.Step != time.Minute || len(s.Values) != 2 || *s.Values[0] != 1 || s.Values[1] != nil {
+		t.Fatalf("unexpected series (%#v)", s)
+	}
+
+	if _, err := c.FindGraphite(""); err == nil || err.Error() != "invalid graphite query (none)" {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+	if _, err := c.FetchGraphite(nil, testStart, testEnd); err == nil || err.Error() != "invalid graphite names (none)" {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Lazy - listings which defer heavyweight fields until they are needed
+
+package apiclient
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// skipJSON discards the JSON value decoded into it
+type skipJSON struct{}
+
+// UnmarshalJSON discards the value
+func (skipJSON) UnmarshalJSON([]byte) error {
+	return nil
+}
+
+// lazyField holds a heavyweight field loaded on demand, shared by copies of
+// the lazy object
+type lazyField struct {
+	mu     sync.Mutex
+	loaded bool
+	value  interface{}
+}
+
+// get returns the field's value, calling load the first time (and again
+// after a failed load)
+func (f *lazyField) get(load func() (interface{}, error)) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.loaded {
+		return f.value, nil
+	}
+	v, err := load()
+	if err != nil {
+		return nil, err
+	}
+	f.value = v
+	f.loaded = true
+	return v, nil
+}
+
+// LazyCheckBundle is a check bundle fetched without its metrics (the
+// Metrics field is nil), they are loaded when first requested with the
+// Metrics method.
+type LazyCheckBundle struct {
+	CheckBundle
+	api     *API
+	metrics *lazyField
+}
+
+// Metrics returns the check bundle's metrics, fetching them the first time.
+func (b *LazyCheckBundle) Metrics() ([]CheckBundleMetric, error) {
+	if b.api == nil || b.metrics == nil {
+		return nil, errors.New("invalid lazy check bundle, not fetched with FetchLazyCheckBundles or SearchLazyCheckBundles")
+	}
+	v, err := b.metrics.get(func() (interface{}, error) {
+		metricsCID := strings.Replace(b.CID, config.CheckBundlePrefix, config.CheckBundleMetricsPrefix, 1)
+		m, err := b.api.FetchCheckBundleMetrics(CIDType(&metricsCID))
+		if err != nil {
+			return nil, err
+		}
+		return m.Metrics, nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading metrics of %s", b.CID)
+	}
+	return v.([]CheckBundleMetric), nil
+}
+
+// lazyCheckBundle decodes a check bundle, skipping its metrics
+type lazyCheckBundle struct {
+	CheckBundle
+	Metrics skipJSON `json:"metrics"`
+}
+
+// FetchLazyCheckBundles retrieves all check bundles available to the API
+// Token without their metrics, see LazyCheckBundle.
+func (a *API) FetchLazyCheckBundles() (*[]LazyCheckBundle, error) {
+	return a.FetchLazyCheckBundlesWithContext(context.Background())
+}
+
+// FetchLazyCheckBundlesWithContext retrieves all check bundles available to
+// the API Token without their metrics, see LazyCheckBundle, stopping when ctx
+// is done.
+func (a *API) FetchLazyCheckBundlesWithContext(ctx context.Context) (*[]LazyCheckBundle, error) {
+	var bundles []lazyCheckBundle
+	if err := a.getJSONContext(ctx, config.CheckBundlePrefix, &bundles); err != nil {
+		return nil, errors.Wrap(err, "fetching check bundles")
+	}
+
+	return a.lazyCheckBundles(bundles), nil
+}
+
+// SearchLazyCheckBundles returns check bundles matching the specified
+// search query and/or filter without their metrics, see LazyCheckBundle.
+// If nil is passed for both parameters all check bundles will be returned.
+func (a *API) SearchLazyCheckBundles(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]LazyCheckBundle, error) {
+	return a.SearchLazyCheckBundlesWithContext(context.Background(), searchCriteria, filterCriteria)
+}
+
+// SearchLazyCheckBundlesWithContext returns check bundles matching the
+// specified search query and/or filter without their metrics, see
+// LazyCheckBundle, stopping when ctx is done.
+func (a *API) SearchLazyCheckBundlesWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]LazyCheckBundle, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchLazyCheckBundlesWithContext(ctx)
+	}
+
+	var bundles []lazyCheckBundle
+	if err := a.searchJSONContext(ctx, config.CheckBundlePrefix, searchCriteria, filterCriteria, &bundles); err != nil {
+		return nil, errors.Wrap(err, "searching check bundles")
+	}
+
+	return a.lazyCheckBundles(bundles), nil
+}
+
+func (a *API) lazyCheckBundles(bundles []lazyCheckBundle) *[]LazyCheckBundle {
+	lazy := make([]LazyCheckBundle, len(bundles))
+	for i := range bundles {
+		lazy[i] = LazyCheckBundle{CheckBundle: bundles[i].CheckBundle, api: a, metrics: &lazyField{}}
+	}
+	return &lazy
+}
+
+// LazyDashboard is a dashboard fetched without its widgets (the Widgets
+// field is nil), they are loaded when first requested with the Widgets
+// method.
+type LazyDashboard struct {
+	Dashboard
+	api     *API
+	widgets *lazyField
+}
+
+// Widgets returns the dashboard's widgets, fetching the dashboard the first
+// time.
+func (d *LazyDashboard) Widgets() ([]DashboardWidget, error) {
+	if d.api == nil || d.widgets == nil {
+		return nil, errors.New("invalid lazy dashboard, not fetched with FetchLazyDashboards or SearchLazyDashboards")
+	}
+	v, err := d.widgets.get(func() (interface{}, error) {
+		cid := d.CID
+		dash, err := d.api.FetchDashboard(CIDType(&cid))
+		if err != nil {
+			return nil, err
+		}
+		return dash.Widgets, nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading widgets of %s", d.CID)
+	}
+	return v.([]DashboardWidget), nil
+}
+
+// lazyDashboard decodes a dashboard, skipping its widgets
+type lazyDashboard struct {
+	Dashboard
+	Widgets skipJSON `json:"widgets"`
+}
+
+// FetchLazyDashboards retrieves all dashboards available to the API Token
+// without their widgets, see LazyDashboard.
+func (a *API) FetchLazyDashboards() (*[]LazyDashboard, error) {
+	return a.FetchLazyDashboardsWithContext(context.Background())
+}
+
+// FetchLazyDashboardsWithContext retrieves all dashboards available to the
+// API Token without their widgets, see LazyDashboard, stopping when ctx is
+// done.
+func (a *API) FetchLazyDashboardsWithContext(ctx context.Context) (*[]LazyDashboard, error) {
+	var dashboards []lazyDashboard
+	if err := a.getJSONContext(ctx, config.DashboardPrefix, &dashboards); err != nil {
+		return nil, errors.Wrap(err, "fetching dashboards")
+	}
+
+	return a.lazyDashboards(dashboards), nil
+}
+
+// SearchLazyDashboards returns dashboards matching the specified search
+// query and/or filter without their widgets, see LazyDashboard. If nil is
+// passed for both parameters all dashboards will be returned.
+func (a *API) SearchLazyDashboards(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]LazyDashboard, error) {
+	return a.SearchLazyDashboardsWithContext(context.Background(), searchCriteria, filterCriteria)
+}
+
+// SearchLazyDashboardsWithContext returns dashboards matching the specified
+// search query and/or filter without their widgets, see LazyDashboard,
+// stopping when ctx is done.
+func (a *API) SearchLazyDashboardsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]LazyDashboard, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchLazyDashboardsWithContext(ctx)
+	}
+
+	var dashboards []lazyDashboard
+	if err := a.searchJSONContext(ctx, config.DashboardPrefix, searchCriteria, filterCriteria, &dashboards); err != nil {
+		return nil, errors.Wrap(err, "searching dashboards")
+	}
+
+	return a.lazyDashboards(dashboards), nil
+}
+
+func (a *API) lazyDashboards(dashboards []lazyDashboard) *[]LazyDashboard {
+	lazy := make([]LazyDashboard, len(dashboards))
+	for i := range dashboards {
+		lazy[i] = LazyDashboard{Dashboard: dashboards[i].Dashboard, api: a, widgets: &lazyField{}}
+	}
+	return &lazy
+}
@@ -0,0 +1,137 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestLazyCheckBundles(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]int{}
+	bundles := []CheckBundle{
+		{CID: "/check_bundle/1", DisplayName: "one", Tags: []string{"env:prod"}, Metrics: []CheckBundleMetric{{Name: "a", Type: "numeric", Status: "active"}}},
+		{CID: "/check_bundle/2", DisplayName: "two", Metrics: []CheckBundleMetric{{Name: "b", Type: "text", Status: "active"}}},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/check_bundle":
+			_ = json.NewEncoder(w).Encode(bundles)
+		case "/check_bundle_metrics/1":
+			_ = json.NewEncoder(w).Encode(CheckBundleMetrics{CID: "/check_bundle_metrics/1", Metrics: bundles[0].Metrics})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	lazy, err := apih.FetchLazyCheckBundles()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(*lazy) != 2 {
+		t.Fatalf("unexpected bundles (%d)", len(*lazy))
+	}
+	b := (*lazy)[0]
+	if b.DisplayName != "one" || len(b.Tags) != 1 || b.CheckBundle.Metrics != nil {
+		t.Fatalf("unexpected bundle (%#v)", b.CheckBundle)
+	}
+
+	for i := 0; i < 2; i++ {
+		metrics, err := b.Metrics()
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if len(metrics) != 1 || metrics[0].Name != "a" {
+			t.Fatalf("unexpected metrics (%#v)", metrics)
+		}
+	}
+	if n := requests["/check_bundle_metrics/1"]; n != 1 {
+		t.Fatalf("unexpected metrics requests (%d)", n)
+	}
+
+	if _, err := (*lazy)[1].Metrics(); err == nil {
+		t.Fatal("expected error")
+	} else if err.Error() != "loading metrics of /check_bundle/2: fetching check bundle metrics: API response code 404: " {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	var invalid LazyCheckBundle
+	if _, err := invalid.Metrics(); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestLazyDashboards(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]int{}
+	dashboards := []Dashboard{
+		{CID: "/dashboard/1", Title: "one", Widgets: []DashboardWidget{{Name: "Graph", Type: "graph", WidgetID: "w1"}}},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/dashboard":
+			if r.URL.Query().Get("f_title") != "one" {
+				t.Errorf("unexpected query (%s)", r.URL.RawQuery)
+			}
+			_ = json.NewEncoder(w).Encode(dashboards)
+		case "/dashboard/1":
+			_ = json.NewEncoder(w).Encode(dashboards[0])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	filter := SearchFilterType{"f_title": {"one"}}
+	lazy, err := apih.SearchLazyDashboards(nil, &filter)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(*lazy) != 1 {
+		t.Fatalf("unexpected dashboards (%d)", len(*lazy))
+	}
+	d := (*lazy)[0]
+	if d.Title != "one" || d.Dashboard.Widgets != nil {
+		t.Fatalf("unexpected dashboard (%#v)", d.Dashboard)
+	}
+
+	// copies share the loaded widgets
+	c := d
+	for _, dash := range []*LazyDashboard{&d, &c} {
+		widgets, err := dash.Widgets()
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if len(widgets) != 1 || widgets[0].WidgetID != "w1" {
+			t.Fatalf("unexpected widgets (%#v)", widgets)
+		}
+	}
+	if n := requests["/dashboard/1"]; n != 1 {
+		t.Fatalf("unexpected dashboard requests (%d)", n)
+	}
+}
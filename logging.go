@@ -0,0 +1,165 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Logging - leveled, structured logging of API calls (see Config Logger),
+// with the API Token redacted
+
+package apiclient
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LeveledLogger facilitates use of leveled, structured loggers (e.g.
+// *slog.Logger, or hclog.Logger), logging messages with alternating keys and
+// values of fields (e.g. "method", "GET", "status", 200)
+type LeveledLogger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// redacted replaces the API Token in logged messages and fields
+const redacted = "[redacted]"
+
+// redact returns s with any occurrence of secret replaced
+func redact(s, secret string) string {
+	if secret == "" {
+		return s
+	}
+	return strings.Replace(s, secret, redacted, -1)
+}
+
+// redactingLogger redacts the API Token from the messages and fields logged
+// to the next LeveledLogger
+type redactingLogger struct {
+	next   LeveledLogger
+	secret string
+}
+
+func (l *redactingLogger) Debug(msg string, kv ...interface{}) {
+	l.next.Debug(redact(msg, l.secret), l.fields(kv)...)
+}
+
+func (l *redactingLogger) Info(msg string, kv ...interface{}) {
+	l.next.Info(redact(msg, l.secret), l.fields(kv)...)
+}
+
+func (l *redactingLogger) Warn(msg string, kv ...interface{}) {
+	l.next.Warn(redact(msg, l.secret), l.fields(kv)...)
+}
+
+func (l *redactingLogger) Error(msg string, kv ...interface{}) {
+	l.next.Error(redact(msg, l.secret), l.fields(kv)...)
+}
+
+// fields returns the keys and values with the API Token redacted from
+// strings, errors, and Stringers (which are logged as strings)
+func (l *redactingLogger) fields(kv []interface{}) []interface{} {
+	out := make([]interface{}, len(kv))
+	for i, v := range kv {
+		switch v := v.(type) {
+		case string:
+			out[i] = redact(v, l.secret)
+		case error:
+			out[i] = redact(v.Error(), l.secret)
+		case fmt.Stringer:
+			out[i] = redact(v.String(), l.secret)
+		default:
+			out[i] = v
+		}
+	}
+	return out
+}
+
+// redactingLog redacts the API Token from the messages of the next Logger
+type redactingLog struct {
+	next   Logger
+	secret string
+}
+
+func (l *redactingLog) Printf(format string, v ...interface{}) {
+	l.next.Printf("%s", redact(fmt.Sprintf(format, v...), l.secret))
+}
+
+// debugLog logs the messages of the Logger interface at debug level to a
+// LeveledLogger (e.g. the request and response bodies, with Debug enabled)
+type debugLog struct {
+	next LeveledLogger
+}
+
+func (l *debugLog) Printf(format string, v ...interface{}) {
+	l.next.Debug(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+// printfLogger logs to the API's Log, with the fields as key=value pairs;
+// debug and info messages only when Debug is enabled
+type printfLogger struct {
+	api *API
+}
+
+func (l *printfLogger) Debug(msg string, kv ...interface{}) {
+	if l.api.debugEnabled() {
+		l.log("DEBUG", msg, kv)
+	}
+}
+
+func (l *printfLogger) Info(msg string, kv ...interface{}) {
+	if l.api.debugEnabled() {
+		l.log("INFO", msg, kv)
+	}
+}
+
+func (l *printfLogger) Warn(msg string, kv ...interface{}) {
+	l.log("WARN", msg, kv)
+}
+
+func (l *printfLogger) Error(msg string, kv ...interface{}) {
+	l.log("ERR", msg, kv)
+}
+
+func (l *printfLogger) log(level, msg string, kv []interface{}) {
+	var b strings.Builder
+	b.WriteString("[" + level + "] " + msg)
+	for i := 0; i < len(kv); i += 2 {
+		var v interface{} = "(missing)"
+		if i+1 < len(kv) {
+			v = kv[i+1]
+		}
+		s := fmt.Sprint(v)
+		if strings.ContainsAny(s, " \t\n\"=") {
+			s = strconv.Quote(s)
+		}
+		fmt.Fprintf(&b, " %v=%s", kv[i], s)
+	}
+	l.api.apiLog().Printf("%s\n", b.String())
+}
+
+// logRetry logs the retry of a call, at warn level to the configured Logger,
+// at debug level to the Log (only with Debug enabled)
+func (a *API) logRetry(kv ...interface{}) {
+	if a.leveledLogger {
+		a.logger.Warn("Circonus API call failed, retrying", kv...)
+		return
+	}
+	a.logger.Debug("Circonus API call failed, retrying", kv...)
+}
+
+// logCall logs a completed API call, at debug level
+func (a *API) logCall(info *CallInfo) {
+	kv := []interface{}{
+		"method", info.Method,
+		"url", a.requestURL(info.Path),
+		"status", info.Status,
+		"duration", info.Elapsed,
+		"attempts", info.Attempts,
+	}
+	if info.Err != nil {
+		kv = append(kv, "error", info.Err)
+	}
+	a.logger.Debug("Circonus API call", kv...)
+}
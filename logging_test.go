@@ -0,0 +1,238 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testLogEntry is a message logged to a testLogger
+type testLogEntry struct {
+	level  string
+	msg    string
+	fields map[string]interface{}
+}
+
+// testLogger records the messages logged to it
+type testLogger struct {
+	mu      sync.Mutex
+	entries []testLogEntry
+}
+
+func (l *testLogger) Debug(msg string, kv ...interface{}) { l.log("debug", msg, kv) }
+func (l *testLogger) Info(msg string, kv ...interface{})  { l.log("info", msg, kv) }
+func (l *testLogger) Warn(msg string, kv ...interface{})  { l.log("warn", msg, kv) }
+func (l *testLogger) Error(msg string, kv ...interface{}) { l.log("error", msg, kv) }
+
+func (l *testLogger) log(level, msg string, kv []interface{}) {
+	e := testLogEntry{level: level, msg: msg, fields: map[string]interface{}{}}
+	for i := 0; i+1 < len(kv); i += 2 {
+		e.fields[fmt.Sprint(kv[i])] = kv[i+1]
+	}
+	l.mu.Lock()
+	l.entries = append(l.entries, e)
+	l.mu.Unlock()
+}
+
+func (l *testLogger) find(level, msg string) []testLogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var found []testLogEntry
+	for _, e := range l.entries {
+		if e.level == level && e.msg == msg {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+func TestLogger(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/graph/1":
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprint(w, `{"_cid":"/graph/1"}`)
+		default:
+			// echoes the token, which must not be logged
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"code":"404","message":"not found (%s)"}`, r.Header.Get("X-Circonus-Auth-Token"))
+		}
+	}))
+	defer server.Close()
+
+	logger := &testLogger{}
+	apih, err := New(&Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      server.URL,
+		Logger:   logger,
+		Debug:    true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	cid := "/graph/1"
+	if _, err := apih.FetchGraph(CIDType(&cid)); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if _, err := apih.Get("/graph/2"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	calls := logger.find("debug", "Circonus API call")
+	if len(calls) != 2 {
+		t.Fatalf("unexpected calls logged (%+v)", logger.entries)
+	}
+	expected := map[string]interface{}{
+		"method":   "GET",
+		"url":      server.URL + "/graph/1",
+		"status":   200,
+		"attempts": 2,
+	}
+	for k, v := range expected {
+		if calls[0].fields[k] != v {
+			t.Errorf("expected %s=%v, got %v", k, v, calls[0].fields[k])
+		}
+	}
+	if _, ok := calls[0].fields["duration"]; !ok {
+		t.Error("expected duration logged")
+	}
+	if calls[1].fields["status"] != 404 || calls[1].fields["error"] == nil {
+		t.Errorf("unexpected failed call log (%+v)", calls[1].fields)
+	}
+
+	retries := logger.find("warn", "Circonus API call failed, retrying")
+	if len(retries) != 1 || retries[0].fields["attempt"] != 1 {
+		t.Fatalf("unexpected retries logged (%+v)", retries)
+	}
+
+	// without a Log, the Log messages (e.g. the response bodies) are logged
+	// at debug level
+	if len(logger.find("debug", `fetch graph, received JSON: {"_cid":"/graph/1"}`)) != 1 {
+		t.Errorf("expected response body logged (%+v)", logger.entries)
+	}
+
+	for _, e := range logger.entries {
+		if strings.Contains(e.msg, "abc123") || strings.Contains(fmt.Sprint(e.fields), "abc123") {
+			t.Fatalf("API Token logged (%s %v)", e.msg, e.fields)
+		}
+	}
+	if !strings.Contains(fmt.Sprint(calls[1].fields["error"]), "not found ([redacted])") {
+		t.Errorf("expected redacted error (%v)", calls[1].fields["error"])
+	}
+}
+
+func TestPrintfLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"message":"unknown token %s"}`, r.Header.Get("X-Circonus-Auth-Token"))
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var logged []string
+	log := logFunc(func(format string, v ...interface{}) {
+		mu.Lock()
+		logged = append(logged, fmt.Sprintf(format, v...))
+		mu.Unlock()
+	})
+
+	tests := []struct {
+		debug    bool
+		expected string
+	}{
+		{false, ""},
+		{true, `[DEBUG] Circonus API call method=GET url=` + server.URL + `/graph/1 status=404 duration=`},
+	}
+	for _, test := range tests {
+		logged = nil
+		apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL, Log: log, Debug: test.debug})
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if _, err := apih.Get("/graph/1"); err == nil {
+			t.Fatal("expected error")
+		}
+		if test.expected == "" {
+			if len(logged) != 0 {
+				t.Fatalf("unexpected log (%q)", logged)
+			}
+			continue
+		}
+		call := ""
+		for _, l := range logged {
+			if strings.HasPrefix(l, test.expected) {
+				call = l
+			}
+		}
+		if !strings.Contains(call, ` attempts=1 error="API response code 404: {\"message\":\"unknown token [redacted]\"}"`) {
+			t.Fatalf("expected call logged, token redacted (%q)", logged)
+		}
+	}
+}
+
+func TestPrintfLoggerRetries(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var logged []string
+	log := logFunc(func(format string, v ...interface{}) {
+		mu.Lock()
+		logged = append(logged, fmt.Sprintf(format, v...))
+		mu.Unlock()
+	})
+
+	// retries are logged to the Log with Debug enabled only
+	for _, debug := range []bool{false, true} {
+		logged = nil
+		apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL, Log: log, Debug: debug})
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if _, err := apih.Get("/graph"); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		retried := false
+		for _, l := range logged {
+			if strings.HasPrefix(l, "[DEBUG] Circonus API call failed, retrying method=GET") {
+				retried = true
+			}
+		}
+		if retried != debug || (!debug && len(logged) != 0) {
+			t.Fatalf("unexpected log, debug %t (%q)", debug, logged)
+		}
+	}
+}
+
+func TestRedactingLogger(t *testing.T) {
+	logger := &testLogger{}
+	l := &redactingLogger{next: logger, secret: "abc123"}
+	l.Warn("token abc123", "key", "abc123", "error", fmt.Errorf("bad abc123"), "wait", time.Second, "n", 1)
+
+	e := logger.entries[0]
+	if e.level != "warn" || e.msg != "token [redacted]" || e.fields["key"] != "[redacted]" || e.fields["error"] != "bad [redacted]" || e.fields["wait"] != "1s" || e.fields["n"] != 1 {
+		t.Fatalf("unexpected entry (%+v)", e)
+	}
+}
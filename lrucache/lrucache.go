@@ -0,0 +1,171 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lrucache is an apiclient Cache holding the most recently used
+// responses in memory. It stores their validators (ETag and Last-Modified
+// headers), so once a response is no longer served it is revalidated with a
+// conditional request, rather than fetched again, e.g. for objects which
+// rarely change (dashboards, brokers) fetched repeatedly.
+//
+//	cache, _ := lrucache.New(&lrucache.Config{Size: 500, TTL: time.Minute})
+//	apih, _ := apiclient.New(&apiclient.Config{TokenKey: key, Cache: cache})
+package lrucache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/pkg/errors"
+)
+
+// DefaultSize is the number of responses held, see Config Size
+const DefaultSize = 1000
+
+// Config defines the cache size and entry lifetime
+type Config struct {
+	// Size is the number of responses held, the least recently used is
+	// removed to hold another - default DefaultSize
+	Size int
+
+	// TTL is how long after being stored (or revalidated) a response is
+	// served without a request - default 0, every use of a response is
+	// revalidated. Responses without validators are removed once expired.
+	TTL time.Duration
+}
+
+// Cache holds responses in memory, it is safe for concurrent use.
+type Cache struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	order   *list.List // of *item, most recently used first
+	entries map[string]*list.Element
+}
+
+// item is an entry held by the cache
+type item struct {
+	key    string
+	entry  apiclient.CacheEntry
+	stored time.Time
+}
+
+var _ apiclient.ValidatingCache = (*Cache)(nil)
+
+// New returns a cache of the configured size
+func New(cfg *Config) (*Cache, error) {
+	if cfg == nil {
+		return nil, errors.New("invalid LRU cache config (nil)")
+	}
+	if cfg.Size < 0 {
+		return nil, errors.Errorf("invalid LRU cache size (%d), must not be negative", cfg.Size)
+	}
+	if cfg.TTL < 0 {
+		return nil, errors.Errorf("invalid LRU cache TTL (%s), must not be negative", cfg.TTL)
+	}
+
+	c := &Cache{size: cfg.Size, ttl: cfg.TTL, order: list.New(), entries: make(map[string]*list.Element)}
+	if c.size == 0 {
+		c.size = DefaultSize
+	}
+	return c, nil
+}
+
+// Get returns the response stored for the key, if it has not expired.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	it, ok := c.use(key)
+	if !ok || time.Since(it.stored) >= c.ttl {
+		return nil, false
+	}
+	return it.entry.Data, true
+}
+
+// Set stores the response for the key, without validators.
+func (c *Cache) Set(key string, data []byte) {
+	c.Store(key, &apiclient.CacheEntry{Data: data})
+}
+
+// Lookup returns the entry stored for the key, expired or not, as long as
+// it can be revalidated.
+func (c *Cache) Lookup(key string) (*apiclient.CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	it, ok := c.use(key)
+	if !ok {
+		return nil, false
+	}
+	e := it.entry
+	return &e, true
+}
+
+// Store stores the entry for the key, removing the least recently used
+// entry when the cache is full.
+func (c *Cache) Store(key string, e *apiclient.CacheEntry) {
+	if e == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	it := &item{key: key, entry: *e, stored: time.Now()}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = it
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(it)
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// Invalidate removes the entries with keys for which match returns true.
+func (c *Cache) Invalidate(match func(key string) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for elem := c.order.Front(); elem != nil; {
+		next := elem.Next()
+		if match(elem.Value.(*item).key) {
+			c.remove(elem)
+		}
+		elem = next
+	}
+}
+
+// Len returns the number of entries held.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// use returns the item of the key, marking it most recently used. Expired
+// items which cannot be revalidated are removed.
+func (c *Cache) use(key string) (*item, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	it := elem.Value.(*item)
+	if it.entry.ETag == "" && it.entry.LastModified == "" && time.Since(it.stored) >= c.ttl {
+		c.remove(elem)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return it, true
+}
+
+// remove removes an entry, the caller holds mu
+func (c *Cache) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*item).key)
+}
@@ -0,0 +1,148 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lrucache
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		id     string
+		cfg    *Config
+		errStr string
+	}{
+		{"nil config", nil, "invalid LRU cache config (nil)"},
+		{"negative size", &Config{Size: -1}, "invalid LRU cache size (-1), must not be negative"},
+		{"negative ttl", &Config{TTL: -time.Second}, "invalid LRU cache TTL (-1s), must not be negative"},
+		{"defaults", &Config{}, ""},
+	}
+
+	for _, test := range tests {
+		_, err := New(test.cfg)
+		if test.errStr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error (%s)", test.id, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != test.errStr {
+			t.Errorf("%s: expected error (%s) got (%v)", test.id, test.errStr, err)
+		}
+	}
+}
+
+func TestCache(t *testing.T) {
+	c, err := New(&Config{Size: 2, TTL: time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	c.Set("/graph/1", []byte("1"))
+	c.Store("/graph/2", &apiclient.CacheEntry{Data: []byte("2"), ETag: `"v2"`})
+	if data, ok := c.Get("/graph/1"); !ok || string(data) != "1" {
+		t.Fatalf("unexpected get (%q %t)", data, ok)
+	}
+
+	// /graph/1 was used more recently, /graph/2 is removed
+	c.Set("/graph/3", []byte("3"))
+	if _, ok := c.Lookup("/graph/2"); ok {
+		t.Fatal("expected /graph/2 removed")
+	}
+	if n := c.Len(); n != 2 {
+		t.Fatalf("unexpected len (%d)", n)
+	}
+
+	c.Invalidate(func(key string) bool { return strings.HasSuffix(key, "3") })
+	if _, ok := c.Get("/graph/3"); ok {
+		t.Fatal("expected /graph/3 invalidated")
+	}
+	if _, ok := c.Get("/graph/1"); !ok {
+		t.Fatal("expected /graph/1 kept")
+	}
+}
+
+func TestCacheExpired(t *testing.T) {
+	c, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	// with no TTL, responses are never served by Get
+	c.Set("/graph/1", []byte("1"))
+	c.Store("/graph/2", &apiclient.CacheEntry{Data: []byte("2"), LastModified: "Mon, 02 Jan 2006 15:04:05 GMT"})
+	if _, ok := c.Get("/graph/2"); ok {
+		t.Fatal("expected /graph/2 expired")
+	}
+
+	// those without validators cannot be revalidated, and are removed
+	if _, ok := c.Lookup("/graph/1"); ok {
+		t.Fatal("expected /graph/1 removed")
+	}
+	if e, ok := c.Lookup("/graph/2"); !ok || string(e.Data) != "2" {
+		t.Fatalf("unexpected lookup (%v %t)", e, ok)
+	}
+}
+
+func TestRevalidation(t *testing.T) {
+	var version, gets, notModified int32 = 1, 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&gets, 1)
+		v := atomic.LoadInt32(&version)
+		etag := fmt.Sprintf(`"v%d"`, v)
+		if r.Header.Get("If-None-Match") == etag {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		fmt.Fprintf(w, `{"_cid":"/broker/1","_name":"broker v%d"}`, v)
+	}))
+	defer server.Close()
+
+	c, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	apih, err := apiclient.New(&apiclient.Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL, Cache: c})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	fetch := func() string {
+		cid := "/broker/1"
+		b, err := apih.FetchBroker(apiclient.CIDType(&cid))
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		return b.Name
+	}
+
+	for i := 0; i < 3; i++ {
+		if name := fetch(); name != "broker v1" {
+			t.Fatalf("unexpected name (%s)", name)
+		}
+	}
+	if g, nm := atomic.LoadInt32(&gets), atomic.LoadInt32(&notModified); g != 3 || nm != 2 {
+		t.Fatalf("expected 3 requests, 2 not modified, got %d %d", g, nm)
+	}
+
+	// changed, fetched again
+	atomic.StoreInt32(&version, 2)
+	if name := fetch(); name != "broker v2" {
+		t.Fatalf("unexpected name (%s)", name)
+	}
+	if nm := atomic.LoadInt32(&notModified); nm != 2 {
+		t.Fatalf("unexpected not modified (%d)", nm)
+	}
+}
@@ -8,11 +8,13 @@ package apiclient
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	crand "crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"math"
@@ -24,8 +26,10 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/circonus-labs/go-apiclient/config"
 	retryablehttp "github.com/hashicorp/go-retryablehttp"
 	"github.com/pkg/errors"
 )
@@ -86,6 +90,15 @@ type Config struct {
 	// URL defines the API URL - default https://api.circonus.com/v2/
 	URL string
 
+	// FailoverURLs defines other URLs of the API (e.g. an inside
+	// deployment), tried in order when the URL calls are made to cannot be
+	// reached or responds 502, 503, or 504. Calls are made to the URL
+	// which last responded until it fails; a URL which failed is skipped
+	// for the FailoverCooldown - default DefaultFailoverCooldown - unless
+	// every URL has failed (see ActiveURL).
+	FailoverURLs     []string
+	FailoverCooldown time.Duration
+
 	// TokenKey defines the key to use when communicating with the API
 	TokenKey string
 
@@ -100,11 +113,104 @@ type Config struct {
 	// TLSConfig defines a custom tls configuration to use when communicating with the API
 	TLSConfig *tls.Config
 
+	// Transport, if set, makes the HTTP requests instead of the API's own
+	// transport (CACert and TLSConfig are then ignored) - e.g. to serve
+	// calls from recorded responses or a snapshot, see apisync.OfflineAPI
+	Transport http.RoundTripper
+
+	// CompressThreshold defines the size, in bytes, from which PUT and POST
+	// bodies are sent gzip compressed - default 0, not compressed. If the
+	// API does not accept compressed bodies, they are sent uncompressed.
+	CompressThreshold int
+
+	// AttemptTimeout limits each attempt of an API call, including reading
+	// the response - default 0, no limit. Attempts which time out are
+	// retried like other failures, only the context of the call limits the
+	// time spent on all of its attempts and the backoffs between them.
+	AttemptTimeout time.Duration
+
+	// EndpointLimits caps the concurrent and per second requests to
+	// endpoints, keyed by endpoint prefix (e.g. config.MetricPrefix) - so
+	// expensive calls (e.g. metric searches) are limited without limiting
+	// others. The limits apply to the API and all its copies (see
+	// WithAccount).
+	EndpointLimits map[string]EndpointLimit
+
+	// RateLimit, if set, throttles every request of the API and all its
+	// copies (see WithAccount), in addition to any EndpointLimits - so the
+	// API's own rate limit is not reached.
+	RateLimit *RateLimit
+
+	// Cache, if set, stores the responses of GET requests, which are served
+	// from it until it no longer holds them (see Cache), or revalidated
+	// with conditional requests (see ValidatingCache, and lrucache)
+	Cache Cache
+
+	// Codec encodes the objects sent and decodes the responses received -
+	// default JSONCodec
+	Codec Codec
+
+	// Audit, if set, receives a record of every object created, updated, or
+	// deleted (see AuditSink)
+	Audit AuditSink
+
+	// ChangeAnnotations, if set, records every object created, updated, or
+	// deleted as an annotation, a change log alongside the graphs of the
+	// account (see ChangeAnnotationConfig). As for Audit, objects are
+	// fetched before they are updated or deleted, for the fields changed.
+	ChangeAnnotations *ChangeAnnotationConfig
+
+	// Redirects defines which redirects are followed - default up to
+	// DefaultMaxRedirects, the API Token is not sent to other hosts (see
+	// RedirectPolicy)
+	Redirects *RedirectPolicy
+
+	// OnRequest, OnRetry, and OnResponse, if set, are called for each
+	// attempt of an API call, before each retry, and for the response (or
+	// failure) of each attempt - to emit metrics or log retries without
+	// enabling Debug. They are called from the goroutines making calls, and
+	// must be safe for concurrent use.
+	OnRequest  func(method, path string)
+	OnRetry    func(attempt int, err error, delay time.Duration)
+	OnResponse func(method, path string, status int, elapsed time.Duration, err error)
+
+	// RequestHooks and ResponseHooks, if set, are called in order with
+	// the request of each attempt of an API call, before it is sent, and
+	// with its response - to add headers, sign requests, or check
+	// responses. They see each attempt as sent (e.g. to a failover URL).
+	// An error returned by a hook fails the call, without retrying it.
+	// They are called from the goroutines making calls, and must be safe
+	// for concurrent use.
+	RequestHooks  []RequestHook
+	ResponseHooks []ResponseHook
+
+	// OnCall, if set, is called as each API call starts, returning the
+	// context to make the call with (e.g. with a span of the call, see
+	// apiotel) and a func called with the outcome of the call (see
+	// CallInfo) once it completes, including its retries. It is called
+	// from the goroutines making calls, and must be safe for concurrent
+	// use.
+	OnCall func(ctx context.Context, method, path string) (context.Context, func(CallInfo))
+
+	// Logger, if set, logs each API call (its method, URL, status,
+	// duration, and attempts) and retry as structured fields, at debug and
+	// warn level (retries are logged to Log at debug level, with Debug
+	// enabled) (see LeveledLogger, e.g. a *slog.Logger). Without a Log,
+	// the messages logged to Log (e.g. the request and response bodies,
+	// with Debug enabled) are logged to it at debug level. The API Token is
+	// redacted from what is logged.
+	Logger LeveledLogger
+
 	Log   Logger
 	Debug bool
 }
 
 // API Circonus API
+//
+// An API is safe for concurrent use by multiple goroutines, as are the
+// copies returned by WithAccount (which share its transport and rate limit
+// state). Debug and Log are set with SetDebug and SetLog while it is in use;
+// the Log must itself be safe for concurrent use (log.Logger is).
 type API struct {
 	apiURL                  *url.URL
 	key                     TokenKeyType
@@ -112,10 +218,39 @@ type API struct {
 	accountID               TokenAccountIDType
 	caCert                  *x509.CertPool
 	tlsConfig               *tls.Config
-	Debug                   bool
-	Log                     Logger
+	debug                   bool          // see SetDebug
+	log                     Logger        // see SetLog, the API Token redacted
+	logmu                   *sync.RWMutex // a pointer, so the API can be copied (see WithAccount)
 	useExponentialBackoff   bool
-	useExponentialBackoffmu sync.Mutex
+	useExponentialBackoffmu *sync.Mutex
+	rateLimit               *rateLimitGate
+	compressThreshold       int
+	compressUnsupported     *int32 // set (atomically) when the API rejects compressed bodies, shared by copies of the API
+	attemptTimeout          time.Duration
+	hooks                   requestHooks
+	requestHooks            []RequestHook
+	responseHooks           []ResponseHook
+	onCall                  func(ctx context.Context, method, path string) (context.Context, func(CallInfo))
+	logger                  LeveledLogger
+	leveledLogger           bool // logger is the configured Logger, not the Log
+	redirects               RedirectPolicy
+	audit                   AuditSink
+	changeAnnotations       *ChangeAnnotationConfig
+	cache                   Cache
+	codec                   Codec
+	failover                *failoverSet                // shared by copies of the API
+	endpointLimits          map[string]*endpointLimiter // shared by copies of the API
+	rateLimiter             *endpointLimiter            // shared by copies of the API
+	transport               *http.Transport             // shared by every call, and copies of the API
+	roundTripper            http.RoundTripper           // Config Transport, used instead of transport
+	validators              *objectValidators           // shared by copies of the API
+
+	// Deprecated: Debug is the Config Debug, it is not read after New, use
+	// SetDebug
+	Debug bool
+	// Deprecated: Log is the Config Log, it is not read after New, use
+	// SetLog
+	Log Logger
 }
 
 // NewClient returns a new Circonus API (alias for New)
@@ -151,43 +286,87 @@ func New(ac *Config) (*API, error) {
 	if au == "" {
 		au = defaultAPIURL
 	}
-	if !strings.Contains(au, "/") {
-		// if just a hostname is passed, ASSume "https" and a path prefix of "/v2"
-		au = fmt.Sprintf("https://%s/v2", ac.URL)
-	}
-	if last := len(au) - 1; last >= 0 && au[last] == '/' {
-		// strip off trailing '/'
-		au = au[:last]
-	}
-	apiURL, err := url.Parse(au)
+	apiURL, err := parseAPIURL(au)
 	if err != nil {
 		return nil, errors.Wrap(err, "parsing Circonus API URL")
 	}
 
 	a := &API{
-		apiURL:                apiURL,
-		key:                   key,
-		app:                   app,
-		accountID:             acctID,
-		caCert:                ac.CACert,
-		tlsConfig:             ac.TLSConfig,
-		Debug:                 ac.Debug,
-		Log:                   ac.Log,
-		useExponentialBackoff: false,
+		apiURL:                  apiURL,
+		key:                     key,
+		app:                     app,
+		accountID:               acctID,
+		caCert:                  ac.CACert,
+		tlsConfig:               ac.TLSConfig,
+		logmu:                   new(sync.RWMutex),
+		useExponentialBackoff:   false,
+		useExponentialBackoffmu: new(sync.Mutex),
+		rateLimit:               &rateLimitGate{},
+		compressThreshold:       ac.CompressThreshold,
+		compressUnsupported:     new(int32),
+		attemptTimeout:          ac.AttemptTimeout,
+		hooks:                   requestHooks{onRequest: ac.OnRequest, onRetry: ac.OnRetry, onResponse: ac.OnResponse},
+		requestHooks:            ac.RequestHooks,
+		responseHooks:           ac.ResponseHooks,
+		onCall:                  ac.OnCall,
+		audit:                   ac.Audit,
+		changeAnnotations:       newChangeAnnotationConfig(ac.ChangeAnnotations),
+		cache:                   ac.Cache,
+		codec:                   ac.Codec,
+		roundTripper:            ac.Transport,
+		validators:              &objectValidators{},
+	}
+	if ac.Redirects != nil {
+		a.redirects = *ac.Redirects
+	}
+	if a.endpointLimits, err = newEndpointLimiters(ac.EndpointLimits); err != nil {
+		return nil, err
+	}
+	if a.rateLimiter, err = newRateLimiter(ac.RateLimit); err != nil {
+		return nil, err
 	}
+	if a.failover, err = newFailoverSet(apiURL, ac.FailoverURLs, ac.FailoverCooldown); err != nil {
+		return nil, err
+	}
+	a.transport = a.newTransport()
 
-	a.Debug = ac.Debug
-	a.Log = ac.Log
-	if a.Debug && a.Log == nil {
-		a.Log = log.New(os.Stdout, "", log.LstdFlags)
+	a.debug = ac.Debug
+	a.log = ac.Log
+	if a.log == nil && ac.Logger != nil {
+		a.log = &debugLog{next: ac.Logger}
+	}
+	if a.debug && a.log == nil {
+		a.log = log.New(os.Stdout, "", log.LstdFlags)
 	}
-	if a.Log == nil {
-		a.Log = log.New(ioutil.Discard, "", log.LstdFlags)
+	if a.log == nil {
+		a.log = log.New(ioutil.Discard, "", log.LstdFlags)
+	}
+	a.log = &redactingLog{next: a.log, secret: string(key)}
+	a.Debug, a.Log = a.debug, a.log // deprecated, not read
+
+	var logger LeveledLogger = &printfLogger{api: a}
+	if ac.Logger != nil {
+		logger = ac.Logger
+		a.leveledLogger = true
 	}
+	a.logger = &redactingLogger{next: logger, secret: string(key)}
 
 	return a, nil
 }
 
+// parseAPIURL parses an API URL, or hostname
+func parseAPIURL(au string) (*url.URL, error) {
+	if !strings.Contains(au, "/") {
+		// if just a hostname is passed, ASSume "https" and a path prefix of "/v2"
+		au = fmt.Sprintf("https://%s/v2", au)
+	}
+	if last := len(au) - 1; last >= 0 && au[last] == '/' {
+		// strip off trailing '/'
+		au = au[:last]
+	}
+	return url.Parse(au)
+}
+
 // EnableExponentialBackoff enables use of exponential backoff for next API call(s)
 // and use exponential backoff for all API calls until exponential backoff is disabled.
 func (a *API) EnableExponentialBackoff() {
@@ -205,6 +384,76 @@ func (a *API) DisableExponentialBackoff() {
 	a.useExponentialBackoffmu.Unlock()
 }
 
+// SetDebug enables or disables the debug messages (e.g. the request and
+// response bodies) logged to the Log, while the API is in use.
+func (a *API) SetDebug(enabled bool) {
+	a.logmu.Lock()
+	a.debug = enabled
+	a.logmu.Unlock()
+}
+
+// SetLog sets the Log debug messages are logged to, with the API Token
+// redacted, while the API is in use. A nil Log discards them.
+func (a *API) SetLog(l Logger) {
+	if l == nil {
+		l = log.New(ioutil.Discard, "", log.LstdFlags)
+	}
+	l = &redactingLog{next: l, secret: string(a.key)}
+	a.logmu.Lock()
+	a.log = l
+	a.logmu.Unlock()
+}
+
+// Debugf logs a message to the Log when debug messages are enabled (see
+// SetDebug), for packages built on the API.
+func (a *API) Debugf(format string, v ...interface{}) {
+	if a.debugEnabled() {
+		a.apiLog().Printf(format, v...)
+	}
+}
+
+// debugEnabled reports whether debug messages are logged, see SetDebug
+func (a *API) debugEnabled() bool {
+	a.logmu.RLock()
+	defer a.logmu.RUnlock()
+	return a.debug
+}
+
+// apiLog returns the Log, see SetLog
+func (a *API) apiLog() Logger {
+	a.logmu.RLock()
+	defer a.logmu.RUnlock()
+	return a.log
+}
+
+// WithAccount returns a copy of the API which acts on the account with the passed
+// cid or id (sent as the X-Circonus-Account-ID header), for tokens with access to
+// multiple accounts. The copy shares configuration and rate limit state, but not
+// backoff state.
+func (a *API) WithAccount(accountCID string) (*API, error) {
+	id := strings.TrimPrefix(accountCID, config.AccountPrefix+"/")
+	if id == "" {
+		return nil, errors.New("invalid account CID (none)")
+	}
+	if strings.Contains(id, "/") {
+		return nil, errors.Errorf("invalid account CID (%s)", accountCID)
+	}
+
+	// the settings which may be changed while the API is in use are copied
+	// under their locks, the copy has its own
+	a.logmu.RLock()
+	a.useExponentialBackoffmu.Lock()
+	c := *a
+	a.useExponentialBackoffmu.Unlock()
+	a.logmu.RUnlock()
+
+	c.accountID = TokenAccountIDType(id)
+	c.logmu = new(sync.RWMutex)
+	c.useExponentialBackoffmu = new(sync.Mutex)
+	c.transport = a.httpTransport()
+	return &c, nil
+}
+
 // Get API request
 func (a *API) Get(reqPath string) ([]byte, error) {
 	return a.apiRequest("GET", reqPath, nil)
@@ -225,59 +474,268 @@ func (a *API) Put(reqPath string, data []byte) ([]byte, error) {
 	return a.apiRequest("PUT", reqPath, data)
 }
 
+// GetWithContext API request, stopping when ctx is done
+func (a *API) GetWithContext(ctx context.Context, reqPath string) ([]byte, error) {
+	return a.apiRequestContext(ctx, "GET", reqPath, nil)
+}
+
+// DeleteWithContext API request, stopping when ctx is done
+func (a *API) DeleteWithContext(ctx context.Context, reqPath string) ([]byte, error) {
+	return a.apiRequestContext(ctx, "DELETE", reqPath, nil)
+}
+
+// PostWithContext API request, stopping when ctx is done
+func (a *API) PostWithContext(ctx context.Context, reqPath string, data []byte) ([]byte, error) {
+	return a.apiRequestContext(ctx, "POST", reqPath, data)
+}
+
+// PutWithContext API request, stopping when ctx is done
+func (a *API) PutWithContext(ctx context.Context, reqPath string, data []byte) ([]byte, error) {
+	return a.apiRequestContext(ctx, "PUT", reqPath, data)
+}
+
 func backoff(interval uint) float64 {
 	return math.Floor(((float64(interval) * (1 + rand.Float64())) / 2) + .5)
 }
 
 // apiRequest manages retry strategy for exponential backoffs
 func (a *API) apiRequest(reqMethod string, reqPath string, data []byte) ([]byte, error) {
-	backoffs := []uint{2, 4, 8, 16, 32}
-	attempts := 0
-	success := false
+	return a.apiRequestContext(context.Background(), reqMethod, reqPath, data)
+}
 
+// apiRequestContext makes an API request, see apiRequest, which stops
+// (including while waiting to retry) when ctx is done
+func (a *API) apiRequestContext(ctx context.Context, reqMethod string, reqPath string, data []byte) ([]byte, error) {
+	if a.cache != nil {
+		if reqMethod == "GET" {
+			return a.cachedGet(ctx, reqPath)
+		}
+		if auditedMethod(reqMethod) {
+			// even a failed change may have been made
+			defer a.invalidateCache(reqPath)
+		}
+	}
+	if a.auditing(reqMethod) {
+		return a.auditedRequest(ctx, reqMethod, reqPath, data)
+	}
+	return a.request(ctx, reqMethod, reqPath, data)
+}
+
+// request makes an API request, see apiRequestContext
+func (a *API) request(ctx context.Context, reqMethod string, reqPath string, data []byte) ([]byte, error) {
 	var result []byte
-	var err error
+	err := a.withBackoff(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = a.apiCall(ctx, reqMethod, reqPath, data)
+		return err
+	})
+	return result, err
+}
+
+// apiRequestJSON makes an API request, decoding the JSON response body into
+// v as it is read rather than buffering it, see apiRequest
+func (a *API) apiRequestJSON(reqMethod string, reqPath string, data []byte, v interface{}) error {
+	return a.apiRequestJSONContext(context.Background(), reqMethod, reqPath, data, v)
+}
+
+// apiRequestJSONContext makes an API request decoding the response into v,
+// see apiRequestJSON, which stops when ctx is done
+func (a *API) apiRequestJSONContext(ctx context.Context, reqMethod string, reqPath string, data []byte, v interface{}) error {
+	if a.cache != nil || a.auditing(reqMethod) {
+		result, err := a.apiRequestContext(ctx, reqMethod, reqPath, data)
+		if err != nil {
+			return err
+		}
+		return a.decodeJSON(bytes.NewReader(result), v)
+	}
+	var decodeErr error
+	err := a.withBackoff(ctx, func(ctx context.Context) error {
+		decodeErr = nil
+		err := a.apiDo(ctx, reqMethod, reqPath, data, nil, func(resp *http.Response) error {
+			decodeErr = a.decodeJSON(resp.Body, v)
+			return decodeErr
+		})
+		if decodeErr != nil {
+			// the response was received, it is not requested again
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return decodeErr
+}
+
+// getJSON gets reqPath, decoding the JSON response body into v (see apiRequestJSON)
+func (a *API) getJSON(reqPath string, v interface{}) error {
+	return a.apiRequestJSONContext(context.Background(), "GET", reqPath, nil, v)
+}
 
-	for !success {
-		result, err = a.apiCall(reqMethod, reqPath, data)
+// getJSONContext gets reqPath decoding the response into v, see getJSON,
+// which stops when ctx is done
+func (a *API) getJSONContext(ctx context.Context, reqPath string, v interface{}) error {
+	return a.apiRequestJSONContext(ctx, "GET", reqPath, nil, v)
+}
+
+// withBackoff calls fn, retrying failures with exponential backoff when
+// enabled. Once ctx is done no further attempts are made, and a backoff in
+// progress is cut short.
+func (a *API) withBackoff(ctx context.Context, fn func(ctx context.Context) error) error {
+	backoffs := []uint{2, 4, 8, 16, 32}
+
+	for attempts := 0; ; attempts++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return errors.Wrap(ctxErr, "Circonus API call")
+		}
+
+		err := fn(ctx)
 		if err == nil {
-			success = true
+			return nil
 		}
 
-		// break and return error if not using exponential backoff
-		if err != nil {
-			if !a.useExponentialBackoff {
-				break
-			}
-			if strings.Contains(err.Error(), "code 403") {
-				break
-			}
+		// return error if not using exponential backoff
+		a.useExponentialBackoffmu.Lock()
+		eb := a.useExponentialBackoff
+		a.useExponentialBackoffmu.Unlock()
+		if !eb || isStatus(err, http.StatusForbidden) {
+			return err
+		}
+		if ctx.Err() != nil {
+			// the attempt failed because ctx is done, return why it failed
+			return err
 		}
 
-		if !success {
-			var wait float64
-			if attempts >= len(backoffs) {
-				wait = backoff(backoffs[len(backoffs)-1])
-			} else {
-				wait = backoff(backoffs[attempts])
-			}
-			attempts++
-			a.Log.Printf("Circonus API call failed %s, retrying in %d seconds.\n", err.Error(), uint(wait))
-			time.Sleep(time.Duration(wait) * time.Second)
+		var wait float64
+		if attempts >= len(backoffs) {
+			wait = backoff(backoffs[len(backoffs)-1])
+		} else {
+			wait = backoff(backoffs[attempts])
+		}
+		a.logRetry("attempt", attempts+1, "wait", time.Duration(wait)*time.Second, "error", err)
+		a.hooks.retry(attempts+1, err, time.Duration(wait)*time.Second)
+		if ctxErr := sleepContext(ctx, time.Duration(wait)*time.Second); ctxErr != nil {
+			return errors.Wrapf(ctxErr, "Circonus API call, not retried after: %s", err)
 		}
 	}
+}
 
-	return result, err
+// sleepContext waits for d, returning early with ctx's error once ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
 }
 
-// apiCall call Circonus API
-func (a *API) apiCall(reqMethod string, reqPath string, data []byte) ([]byte, error) {
-	reqURL := a.apiURL.String()
+// bufferPool holds the buffers response bodies are read into when they
+// cannot be decoded as they are read (e.g. to log them)
+var bufferPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
 
-	if reqPath == "" {
-		return nil, errors.New("invalid Circonus API URL path (empty)")
+// decodeJSON decodes the JSON response body into v
+func (a *API) decodeJSON(body io.Reader, v interface{}) error {
+	if !a.debugEnabled() {
+		if err := a.apiCodec().Decode(body, v); err != nil {
+			return errors.Wrap(err, "parsing Circonus API response")
+		}
+		return nil
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufferPool.Put(buf)
+	if _, err := buf.ReadFrom(body); err != nil {
+		return errors.Wrap(err, "reading Circonus API response")
 	}
-	if reqPath[:1] != "/" {
+	a.apiLog().Printf("[DEBUG] received json (%s)\n", buf.String())
+	if err := a.unmarshalJSON(buf.Bytes(), v); err != nil {
+		return errors.Wrap(err, "parsing Circonus API response")
+	}
+	return nil
+}
+
+// compressBody returns true if the body of the request should be compressed
+func (a *API) compressBody(reqMethod string, data []byte) bool {
+	if reqMethod != "PUT" && reqMethod != "POST" {
+		return false
+	}
+	if a.compressThreshold <= 0 || len(data) < a.compressThreshold {
+		return false
+	}
+	return atomic.LoadInt32(a.compressUnsupported) == 0
+}
+
+// gzipWriterPool holds the writers request bodies are compressed with
+var gzipWriterPool = sync.Pool{New: func() interface{} { return gzip.NewWriter(nil) }}
+
+// gzipJSON compresses data. The buffer is not pooled, the transport may
+// still read a request body after the call returns.
+func gzipJSON(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzipWriterPool.Get().(*gzip.Writer)
+	defer gzipWriterPool.Put(zw)
+	zw.Reset(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// newTransport returns the transport for calls to the API
+func (a *API) newTransport() *http.Transport {
+	if a.apiURL.Scheme == "https" || (a.failover != nil && a.failover.https()) {
+		var tlscfg *tls.Config
+		if a.tlsConfig != nil { // preference full custom tls config
+			tlscfg = a.tlsConfig
+		} else if a.caCert != nil {
+			tlscfg = &tls.Config{RootCAs: a.caCert}
+		}
+		return &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			Dial: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).Dial,
+			TLSHandshakeTimeout: 10 * time.Second,
+			TLSClientConfig:     tlscfg,
+			DisableKeepAlives:   true,
+			MaxIdleConnsPerHost: -1,
+			DisableCompression:  true,
+		}
+	}
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		Dial: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).Dial,
+		TLSHandshakeTimeout: 10 * time.Second,
+		DisableKeepAlives:   true,
+		MaxIdleConnsPerHost: -1,
+		DisableCompression:  true,
+	}
+}
+
+// httpTransport returns the API's transport, a new one for an API not
+// created with New
+func (a *API) httpTransport() *http.Transport {
+	if a.transport == nil {
+		return a.newTransport()
+	}
+	return a.transport
+}
+
+// requestURL returns the URL of the API request path
+func (a *API) requestURL(reqPath string) string {
+	reqURL := a.apiURL.String()
+	if reqPath == "" || reqPath[:1] != "/" {
 		reqURL += "/"
 	}
 	if len(reqPath) >= 3 && reqPath[:3] == "/v2" {
@@ -285,16 +743,73 @@ func (a *API) apiCall(reqMethod string, reqPath string, data []byte) ([]byte, er
 	} else {
 		reqURL += reqPath
 	}
+	return reqURL
+}
+
+// apiCall call Circonus API
+func (a *API) apiCall(ctx context.Context, reqMethod string, reqPath string, data []byte) ([]byte, error) {
+	var result []byte
+	err := a.apiDo(ctx, reqMethod, reqPath, data, nil, func(resp *http.Response) error {
+		var err error
+		if result, err = ioutil.ReadAll(resp.Body); err != nil {
+			return errors.Wrap(err, "reading Circonus API response")
+		}
+		return nil
+	})
+	return result, err
+}
+
+// apiDo calls the Circonus API, with the passed headers added to the
+// request, passing a successful (or, for conditional requests, not
+// modified) response to read. The call, including the waits between its
+// attempts, stops when ctx is done; each attempt is limited to the
+// configured AttemptTimeout.
+func (a *API) apiDo(ctx context.Context, reqMethod string, reqPath string, data []byte, header http.Header, read func(resp *http.Response) error) error {
+	if reqPath == "" {
+		return errors.New("invalid Circonus API URL path (empty)")
+	}
+
+	var done func(CallInfo)
+	if a.onCall != nil {
+		ctx, done = a.onCall(ctx, reqMethod, reqPath)
+	}
+	info := &CallInfo{Method: reqMethod, Path: reqPath}
+	start := time.Now()
+	err := a.doCall(ctx, reqMethod, reqPath, data, header, read, info)
+	info.Elapsed = time.Since(start)
+	info.Err = err
+	if done != nil {
+		done(*info)
+	}
+	a.logCall(info)
+	return err
+}
+
+// doCall makes the call of apiDo, recording its attempts in info
+func (a *API) doCall(ctx context.Context, reqMethod string, reqPath string, data []byte, header http.Header, read func(resp *http.Response) error, info *CallInfo) error {
+	reqURL := a.requestURL(reqPath)
 
 	// keep last HTTP error in the event of retry failure
 	var lastHTTPError error
 	retryPolicy := func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		info.Attempts++
+		if resp != nil {
+			info.Status = resp.StatusCode
+			if resp.StatusCode == http.StatusTooManyRequests {
+				info.RateLimited++
+			}
+		}
 		if ctxErr := ctx.Err(); ctxErr != nil {
 			return false, errors.Wrap(ctxErr, "Circonus API call")
 		}
 
 		if err != nil {
 			lastHTTPError = err
+			if isRedirectError(err) || isHookError(err) {
+				// refused by the redirect policy or a hook, retrying will
+				// not help
+				return false, errors.Wrap(err, "Circonus API call")
+			}
 			return true, errors.Wrap(err, "Circonus API call")
 		}
 		// Check the response code. We retry on 500-range responses to allow
@@ -305,24 +820,45 @@ func (a *API) apiCall(reqMethod string, reqPath string, data []byte) ([]byte, er
 		if resp.StatusCode == 0 || // wtf?!
 			resp.StatusCode >= 500 || // rutroh
 			resp.StatusCode == 429 { // rate limit
+			if resp.StatusCode == 429 {
+				a.rateLimit.limited(resp)
+			}
 			body, readErr := ioutil.ReadAll(resp.Body)
 			if readErr != nil {
-				lastHTTPError = errors.Errorf("- response: %d %s", resp.StatusCode, readErr.Error())
-			} else {
-				lastHTTPError = errors.Errorf("- response: %d %s", resp.StatusCode, strings.TrimSpace(string(body)))
+				body = []byte(readErr.Error())
 			}
+			lastHTTPError = &retryError{err: newAPIError(resp.Request, resp, bytes.TrimSpace(body))}
 			return true, nil
 		}
 		return false, nil
 	}
 
-	a.Log.Printf("[DEBUG] sending json (%s)\n", string(data))
+	if a.debugEnabled() {
+		a.apiLog().Printf("[DEBUG] sending json (%s)\n", string(data))
+	}
+
+	reqData := data
+	compressed := false
+	if a.compressBody(reqMethod, data) {
+		gz, err := gzipJSON(data)
+		if err != nil {
+			return errors.Wrap(err, "compressing Circonus API request")
+		}
+		reqData = gz
+		compressed = true
+	}
 
-	dataReader := bytes.NewReader(data)
+	// the body is read from reqData for each attempt, rather than copied
+	body := func() (io.Reader, error) { return bytes.NewReader(reqData), nil }
 
-	req, err := retryablehttp.NewRequest(reqMethod, reqURL, dataReader)
+	req, err := retryablehttp.NewRequest(reqMethod, reqURL, retryablehttp.ReaderFunc(body))
 	if err != nil {
-		return nil, errors.Errorf("creating Circonus API request: %s %+v", reqURL, err)
+		return errors.Errorf("creating Circonus API request: %s %+v", reqURL, err)
+	}
+	req = req.WithContext(ctx)
+	req.GetBody = func() (io.ReadCloser, error) { return ioutil.NopCloser(bytes.NewReader(reqData)), nil }
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
 	}
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("X-Circonus-Auth-Token", string(a.key))
@@ -330,39 +866,22 @@ func (a *API) apiCall(reqMethod string, reqPath string, data []byte) ([]byte, er
 	if string(a.accountID) != "" {
 		req.Header.Add("X-Circonus-Account-ID", string(a.accountID))
 	}
+	for k, vals := range header {
+		for _, v := range vals {
+			req.Header.Add(k, v)
+		}
+	}
 
 	client := retryablehttp.NewClient()
-	if a.apiURL.Scheme == "https" {
-		var tlscfg *tls.Config
-		if a.tlsConfig != nil { // preference full custom tls config
-			tlscfg = a.tlsConfig
-		} else if a.caCert != nil {
-			tlscfg = &tls.Config{RootCAs: a.caCert}
-		}
-		client.HTTPClient.Transport = &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			Dial: (&net.Dialer{
-				Timeout:   30 * time.Second,
-				KeepAlive: 30 * time.Second,
-			}).Dial,
-			TLSHandshakeTimeout: 10 * time.Second,
-			TLSClientConfig:     tlscfg,
-			DisableKeepAlives:   true,
-			MaxIdleConnsPerHost: -1,
-			DisableCompression:  true,
-		}
-	} else {
-		client.HTTPClient.Transport = &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			Dial: (&net.Dialer{
-				Timeout:   30 * time.Second,
-				KeepAlive: 30 * time.Second,
-			}).Dial,
-			TLSHandshakeTimeout: 10 * time.Second,
-			DisableKeepAlives:   true,
-			MaxIdleConnsPerHost: -1,
-			DisableCompression:  true,
-		}
+	client.HTTPClient.Transport = a.httpTransport()
+	if a.roundTripper != nil {
+		client.HTTPClient.Transport = a.roundTripper
+	}
+	if len(a.requestHooks) > 0 || len(a.responseHooks) > 0 {
+		client.HTTPClient.Transport = &middlewareTransport{next: client.HTTPClient.Transport, request: a.requestHooks, response: a.responseHooks}
+	}
+	if a.failover != nil {
+		client.HTTPClient.Transport = &failoverTransport{next: client.HTTPClient.Transport, set: a.failover}
 	}
 
 	a.useExponentialBackoffmu.Lock()
@@ -381,36 +900,60 @@ func (a *API) apiCall(reqMethod string, reqPath string, data []byte) ([]byte, er
 	}
 
 	// retryablehttp only groks log or no log
-	if a.Debug {
-		client.Logger = a.Log
+	if a.debugEnabled() {
+		client.Logger = a.apiLog()
 	} else {
 		client.Logger = log.New(ioutil.Discard, "", log.LstdFlags)
 	}
 
 	client.CheckRetry = retryPolicy
+	client.HTTPClient.CheckRedirect = a.redirects.check
+	client.Backoff = func(min, max time.Duration, attempt int, resp *http.Response) time.Duration {
+		wait := retryablehttp.DefaultBackoff(min, max, attempt, resp)
+		a.logRetry("method", reqMethod, "url", reqURL, "attempt", attempt+1, "wait", wait, "error", lastHTTPError)
+		a.hooks.retry(attempt+1, lastHTTPError, wait)
+		return wait
+	}
+	if a.hooks.observing() {
+		client.HTTPClient.Transport = &hookedTransport{next: client.HTTPClient.Transport, hooks: a.hooks, path: reqPath}
+	}
+	if a.rateLimiter != nil {
+		client.HTTPClient.Transport = &limitedTransport{next: client.HTTPClient.Transport, limiter: a.rateLimiter}
+	}
+	if limiter := a.endpointLimiter(reqPath); limiter != nil {
+		client.HTTPClient.Transport = &limitedTransport{next: client.HTTPClient.Transport, limiter: limiter}
+	}
+	// a timeout per attempt, ctx is the deadline of the whole call
+	client.HTTPClient.Timeout = a.attemptTimeout
 
 	resp, err := client.Do(req)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return errors.Wrap(ctxErr, "Circonus API call")
+		}
 		if lastHTTPError != nil {
-			return nil, lastHTTPError
+			return lastHTTPError
 		}
-		return nil, errors.Errorf("Circonus API call - %s: %+v", reqURL, err)
+		return errors.Errorf("Circonus API call - %s: %+v", reqURL, err)
 	}
 
 	defer resp.Body.Close() // nolint: errcheck
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, errors.Wrap(err, "reading Circonus API response")
+
+	if compressed && resp.StatusCode == http.StatusUnsupportedMediaType {
+		// compressed bodies not accepted, send it (and any others) uncompressed
+		atomic.StoreInt32(a.compressUnsupported, 1)
+		resp.Body.Close() // nolint: errcheck
+		return a.doCall(ctx, reqMethod, reqPath, data, header, read, info)
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		msg := fmt.Sprintf("API response code %d: %s", resp.StatusCode, string(body))
-		if a.Debug {
-			a.Log.Printf("%s\n", msg)
+	notModified := resp.StatusCode == http.StatusNotModified && header != nil
+	if (resp.StatusCode < 200 || resp.StatusCode >= 300) && !notModified {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return errors.Wrap(err, "reading Circonus API response")
 		}
-
-		return nil, errors.New(msg)
+		return newAPIError(resp.Request, resp, body)
 	}
 
-	return body, nil
+	return read(resp)
 }
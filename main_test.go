@@ -5,14 +5,26 @@
 package apiclient
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	retryablehttp "github.com/hashicorp/go-retryablehttp"
 )
 
 func callServer() *httptest.Server {
@@ -158,7 +170,7 @@ func TestApiCall(t *testing.T) {
 
 	t.Log("invalid URL path")
 	{
-		_, err := apih.apiCall("GET", "", nil)
+		_, err := apih.apiCall(context.Background(), "GET", "", nil)
 		expectedError := errors.New("invalid Circonus API URL path (empty)")
 		if err == nil {
 			t.Errorf("Expected error")
@@ -171,7 +183,7 @@ func TestApiCall(t *testing.T) {
 	t.Log("URL path fixup, prefix '/'")
 	{
 		call := "GET"
-		resp, err := apih.apiCall(call, "nothing", nil)
+		resp, err := apih.apiCall(context.Background(), call, "nothing", nil)
 		if err != nil {
 			t.Errorf("Expected no error, got '%+v'", resp)
 		}
@@ -184,7 +196,7 @@ func TestApiCall(t *testing.T) {
 	t.Log("URL path fixup, remove '/v2' prefix")
 	{
 		call := "GET"
-		resp, err := apih.apiCall(call, "/v2/nothing", nil)
+		resp, err := apih.apiCall(context.Background(), call, "/v2/nothing", nil)
 		if err != nil {
 			t.Errorf("Expected no error, got '%+v'", resp)
 		}
@@ -197,7 +209,7 @@ func TestApiCall(t *testing.T) {
 	calls := []string{"GET", "PUT", "POST", "DELETE"}
 	for _, call := range calls {
 		t.Logf("Testing %s call", call)
-		resp, err := apih.apiCall(call, "/", nil)
+		resp, err := apih.apiCall(context.Background(), call, "/", nil)
 		if err != nil {
 			t.Errorf("Expected no error, got '%+v'", resp)
 		}
@@ -235,7 +247,7 @@ func TestSSLApiCall(t *testing.T) {
 		calls := []string{"GET", "PUT", "POST", "DELETE"}
 		for _, call := range calls {
 			t.Logf("Testing %s call", call)
-			resp, err := apih.apiCall(call, "/", nil)
+			resp, err := apih.apiCall(context.Background(), call, "/", nil)
 			if err != nil {
 				t.Errorf("Expected no error, got '%+v'", resp)
 			}
@@ -269,7 +281,7 @@ func TestSSLApiCall(t *testing.T) {
 		calls := []string{"GET", "PUT", "POST", "DELETE"}
 		for _, call := range calls {
 			t.Logf("Testing %s call", call)
-			resp, err := apih.apiCall(call, "/", nil)
+			resp, err := apih.apiCall(context.Background(), call, "/", nil)
 			if err != nil {
 				t.Errorf("Expected no error, got '%+v'", resp)
 			}
@@ -496,3 +508,448 @@ func TestApiRequest(t *testing.T) {
 	}
 
 }
+
+func TestApiRequestContext(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/slow":
+			// the first attempt outlasts the attempt timeout
+			if n == 1 {
+				time.Sleep(500 * time.Millisecond)
+			}
+			fmt.Fprintln(w, `{"slow":true}`)
+		default:
+			w.WriteHeader(500)
+			fmt.Fprintln(w, "unavailable")
+		}
+	}))
+	defer server.Close()
+
+	callCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls
+	}
+
+	apih, err := New(&Config{TokenKey: "foo", TokenApp: "bar", URL: server.URL, AttemptTimeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	for _, eb := range []bool{false, true} {
+		if eb {
+			apih.EnableExponentialBackoff()
+		} else {
+			apih.DisableExponentialBackoff()
+		}
+		t.Run(fmt.Sprintf("canceled backoff (exponential %t)", eb), func(t *testing.T) {
+			mu.Lock()
+			calls = 0
+			mu.Unlock()
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			_, err := apih.apiRequestContext(ctx, "GET", "/unavailable", nil)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			// both backoffs wait at least a second before retrying
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("backoff not interrupted (%s)", elapsed)
+			}
+			if n := callCount(); n != 1 {
+				t.Fatalf("unexpected calls (%d)", n)
+			}
+		})
+	}
+
+	t.Run("attempt timeout", func(t *testing.T) {
+		apih.DisableExponentialBackoff()
+		mu.Lock()
+		calls = 0
+		mu.Unlock()
+		var v map[string]bool
+		if err := apih.getJSONContext(context.Background(), "/slow", &v); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if n := callCount(); !v["slow"] || n != 2 {
+			t.Fatalf("unexpected result (%v, %d calls)", v, n)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := apih.apiRequestContext(ctx, "GET", "/slow", nil)
+		if err == nil || err.Error() != "Circonus API call: context canceled" {
+			t.Fatalf("unexpected error (%v)", err)
+		}
+	})
+}
+
+func TestApiGetJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/graph":
+			fmt.Fprintln(w, `[{"_cid":"/graph/1","title":"one"},{"_cid":"/graph/2","title":"two"}]`)
+		case "/graph/truncated":
+			fmt.Fprint(w, `[{"_cid":"/graph/1"`)
+		default:
+			w.WriteHeader(404)
+			fmt.Fprint(w, `{"code":"404"}`)
+		}
+	}))
+	defer server.Close()
+
+	var logged []string
+
+	tests := []struct {
+		id          string
+		path        string
+		debug       bool
+		expectedErr string
+	}{
+		{"streamed", "/graph", false, ""},
+		{"debug", "/graph", true, ""},
+		{"truncated", "/graph/truncated", false, "parsing Circonus API response: unexpected EOF"},
+		{"truncated (debug)", "/graph/truncated", true, "parsing Circonus API response: unexpected end of JSON input"},
+		{"not found", "/graph/3", false, `API response code 404: {"code":"404"}`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			logged = nil
+			apih, err := New(&Config{
+				TokenKey: "foo",
+				TokenApp: "bar",
+				URL:      server.URL,
+				Debug:    test.debug,
+				Log:      logFunc(func(format string, v ...interface{}) { logged = append(logged, fmt.Sprintf(format, v...)) }),
+			})
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+
+			var graphs []Graph
+			err = apih.getJSON(test.path, &graphs)
+			if test.expectedErr != "" {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if len(graphs) != 2 || graphs[1].CID != "/graph/2" || graphs[1].Title != "two" {
+				t.Fatalf("unexpected graphs (%#v)", graphs)
+			}
+
+			received := false
+			for _, l := range logged {
+				if l == "[DEBUG] received json ("+`[{"_cid":"/graph/1","title":"one"},{"_cid":"/graph/2","title":"two"}]`+"\n)\n" {
+					received = true
+				}
+			}
+			if received != test.debug {
+				t.Fatalf("unexpected debug log (%q)", logged)
+			}
+		})
+	}
+}
+
+func TestApiGetJSONBackoff(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		fmt.Fprint(w, `[{"_cid":"/graph/1"`)
+	}))
+	defer server.Close()
+
+	apih, err := New(&Config{TokenKey: "foo", TokenApp: "bar", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	apih.EnableExponentialBackoff()
+
+	// a response which does not decode is not requested again
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var graphs []Graph
+	if err := apih.getJSONContext(ctx, "/graph", &graphs); err == nil || err.Error() != "parsing Circonus API response: unexpected EOF" {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("unexpected requests (%d)", n)
+	}
+}
+
+// logFunc adapts a function to the Logger interface
+type logFunc func(string, ...interface{})
+
+func (f logFunc) Printf(format string, v ...interface{}) { f(format, v...) }
+
+func TestMarshalJSON(t *testing.T) {
+	cfg := &CheckBundle{
+		DisplayName: "<web> & co",
+		Config:      CheckBundleConfig{"url": "https://example.com/?a=1&b=2"},
+		Metrics:     []CheckBundleMetric{{Name: "duration", Type: "numeric"}},
+	}
+	expected, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	a := &API{}
+	buf, err := a.marshalJSON(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if buf.String() != string(expected) {
+		t.Fatalf("unexpected json\n%s\nexpected\n%s", buf.String(), expected)
+	}
+
+	if _, err := a.marshalJSON(func() {}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestApiCallRetryBody(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(500)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	apih, err := New(&Config{TokenKey: "foo", TokenApp: "bar", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if _, err := apih.Post("/graph", []byte(`{"title":"foo"}`)); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(bodies) != 2 || bodies[0] != `{"title":"foo"}` || bodies[1] != bodies[0] {
+		t.Fatalf("unexpected request bodies (%q)", bodies)
+	}
+}
+
+func benchmarkCheckBundle() *CheckBundle {
+	cfg := &CheckBundle{
+		DisplayName: "web1 http",
+		Type:        "http",
+		Target:      "web1.example.com",
+		Brokers:     []string{"/broker/1"},
+		Config:      CheckBundleConfig{"url": "https://web1.example.com/health", "http_version": "1.1"},
+		Tags:        []string{"service:web", "env:prod"},
+	}
+	for i := 0; i < 50; i++ {
+		cfg.Metrics = append(cfg.Metrics, CheckBundleMetric{Name: fmt.Sprintf("metric%d", i), Type: "numeric", Status: "active"})
+	}
+	return cfg
+}
+
+// BenchmarkMarshalJSON compares marshaling request bodies with json.Marshal
+// to marshalJSON, through the API's codec
+func BenchmarkMarshalJSON(b *testing.B) {
+	cfg := benchmarkCheckBundle()
+
+	b.Run("json.Marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := json.Marshal(cfg); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("marshalJSON", func(b *testing.B) {
+		a := &API{}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := a.marshalJSON(cfg); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkRequestBody compares passing request bodies to retryablehttp as a
+// reader, which it copies, to passing a function reading the body in place
+func BenchmarkRequestBody(b *testing.B) {
+	data, err := json.Marshal(benchmarkCheckBundle())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("reader", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := retryablehttp.NewRequest("PUT", "http://localhost/check_bundle/1", bytes.NewReader(data)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("reader func", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			body := func() (io.Reader, error) { return bytes.NewReader(data), nil }
+			if _, err := retryablehttp.NewRequest("PUT", "http://localhost/check_bundle/1", retryablehttp.ReaderFunc(body)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkUpdateCheckBundle measures a complete update, as made in
+// reconciliation loops
+func BenchmarkUpdateCheckBundle(b *testing.B) {
+	cfg := benchmarkCheckBundle()
+	cfg.CID = "/check_bundle/1"
+	resp, err := json.Marshal(cfg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = ioutil.ReadAll(r.Body)
+		_, _ = w.Write(resp)
+	}))
+	defer server.Close()
+
+	apih, err := New(&Config{TokenKey: "foo", TokenApp: "bar", URL: server.URL})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := apih.UpdateCheckBundle(cfg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestApiCompressedBody(t *testing.T) {
+	var mu sync.Mutex
+	var encodings []string
+	accept := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		enc := r.Header.Get("Content-Encoding")
+		encodings = append(encodings, enc)
+		var body io.Reader = r.Body
+		if enc == "gzip" {
+			if !accept {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(400)
+				return
+			}
+			body = zr
+		}
+		data, _ := ioutil.ReadAll(body)
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	large := []byte(`{"title":"` + strings.Repeat("x", 2048) + `"}`)
+	small := []byte(`{"title":"x"}`)
+
+	tests := []struct {
+		id        string
+		threshold int
+		accept    bool
+		method    string
+		data      []byte
+		expected  []string
+	}{
+		{"disabled", 0, true, "PUT", large, []string{""}},
+		{"small", 1024, true, "PUT", small, []string{""}},
+		{"large put", 1024, true, "PUT", large, []string{"gzip"}},
+		{"large post", 1024, true, "POST", large, []string{"gzip"}},
+		{"large delete", 1024, true, "DELETE", large, []string{""}},
+		{"not accepted", 1024, false, "POST", large, []string{"gzip", "", ""}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			apih, err := New(&Config{TokenKey: "foo", TokenApp: "bar", URL: server.URL, CompressThreshold: test.threshold})
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			mu.Lock()
+			encodings = nil
+			accept = test.accept
+			mu.Unlock()
+
+			// a second request, after compression was not accepted, is not compressed
+			calls := 1
+			if !test.accept {
+				calls = 2
+			}
+			for i := 0; i < calls; i++ {
+				resp, err := apih.apiCall(context.Background(), test.method, "/graph", test.data)
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				if string(resp) != string(test.data) {
+					t.Fatalf("unexpected response (%s)", resp)
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if !reflect.DeepEqual(encodings, test.expected) {
+				t.Fatalf("unexpected encodings (%q)", encodings)
+			}
+		})
+	}
+
+	// compression not accepted is shared with account copies, made before
+	apih, err := New(&Config{TokenKey: "foo", TokenApp: "bar", URL: server.URL, CompressThreshold: 1024})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	acct, err := apih.WithAccount("/account/2")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	mu.Lock()
+	encodings = nil
+	accept = false
+	mu.Unlock()
+	for _, api := range []*API{apih, acct} {
+		if _, err := api.apiCall(context.Background(), "POST", "/graph", large); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if expected := []string{"gzip", "", ""}; !reflect.DeepEqual(encodings, expected) {
+		t.Fatalf("unexpected account encodings (%q)", encodings)
+	}
+}
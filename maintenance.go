@@ -8,10 +8,8 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
-	"regexp"
 	"strings"
 
 	"github.com/circonus-labs/go-apiclient/config"
@@ -37,6 +35,12 @@ func NewMaintenanceWindow() *Maintenance {
 
 // FetchMaintenanceWindow retrieves maintenance [window] with passed cid.
 func (a *API) FetchMaintenanceWindow(cid CIDType) (*Maintenance, error) {
+	return a.FetchMaintenanceWindowWithContext(context.Background(), cid)
+}
+
+// FetchMaintenanceWindowWithContext retrieves maintenance [window] with
+// passed cid, stopping when ctx is done.
+func (a *API) FetchMaintenanceWindowWithContext(ctx context.Context, cid CIDType) (*Maintenance, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.New("invalid maintenance window CID (none)")
 	}
@@ -48,25 +52,21 @@ func (a *API) FetchMaintenanceWindow(cid CIDType) (*Maintenance, error) {
 		maintenanceCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.MaintenanceCIDRegex, maintenanceCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.MaintenancePrefix, maintenanceCID) {
 		return nil, errors.Errorf("invalid maintenance window CID (%s)", maintenanceCID)
 	}
 
-	result, err := a.Get(maintenanceCID)
+	result, err := a.GetWithContext(ctx, maintenanceCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching maitenance window")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch maintenance window, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch maintenance window, received JSON: %s", string(result))
 	}
 
 	window := &Maintenance{}
-	if err := json.Unmarshal(result, window); err != nil {
+	if err := a.unmarshalJSON(result, window); err != nil {
 		return nil, errors.Wrap(err, "parsing maintenance window")
 	}
 
@@ -75,14 +75,15 @@ func (a *API) FetchMaintenanceWindow(cid CIDType) (*Maintenance, error) {
 
 // FetchMaintenanceWindows retrieves all maintenance [windows] available to API Token.
 func (a *API) FetchMaintenanceWindows() (*[]Maintenance, error) {
-	result, err := a.Get(config.MaintenancePrefix)
-	if err != nil {
-		return nil, errors.Wrap(err, "fetching maintenance windows")
-	}
+	return a.FetchMaintenanceWindowsWithContext(context.Background())
+}
 
+// FetchMaintenanceWindowsWithContext retrieves all maintenance [windows]
+// available to API Token, stopping when ctx is done.
+func (a *API) FetchMaintenanceWindowsWithContext(ctx context.Context) (*[]Maintenance, error) {
 	var windows []Maintenance
-	if err := json.Unmarshal(result, &windows); err != nil {
-		return nil, errors.Wrap(err, "parsing maintenance windows")
+	if err := a.getJSONContext(ctx, config.MaintenancePrefix, &windows); err != nil {
+		return nil, errors.Wrap(err, "fetching maintenance windows")
 	}
 
 	return &windows, nil
@@ -90,36 +91,38 @@ func (a *API) FetchMaintenanceWindows() (*[]Maintenance, error) {
 
 // UpdateMaintenanceWindow updates passed maintenance [window].
 func (a *API) UpdateMaintenanceWindow(cfg *Maintenance) (*Maintenance, error) {
+	return a.UpdateMaintenanceWindowWithContext(context.Background(), cfg)
+}
+
+// UpdateMaintenanceWindowWithContext updates passed maintenance [window],
+// stopping when ctx is done.
+func (a *API) UpdateMaintenanceWindowWithContext(ctx context.Context, cfg *Maintenance) (*Maintenance, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid maintenance window config (nil)")
 	}
 
 	maintenanceCID := cfg.CID
 
-	matched, err := regexp.MatchString(config.MaintenanceCIDRegex, maintenanceCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.MaintenancePrefix, maintenanceCID) {
 		return nil, errors.Errorf("invalid maintenance window CID (%s)", maintenanceCID)
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("update maintenance window, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("update maintenance window, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Put(maintenanceCID, jsonCfg)
+	result, err := a.PutWithContext(ctx, maintenanceCID, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "parsing maintenance window")
 	}
 
 	window := &Maintenance{}
-	if err := json.Unmarshal(result, window); err != nil {
+	if err := a.unmarshalJSON(result, window); err != nil {
 		return nil, err
 	}
 
@@ -128,26 +131,32 @@ func (a *API) UpdateMaintenanceWindow(cfg *Maintenance) (*Maintenance, error) {
 
 // CreateMaintenanceWindow creates a new maintenance [window].
 func (a *API) CreateMaintenanceWindow(cfg *Maintenance) (*Maintenance, error) {
+	return a.CreateMaintenanceWindowWithContext(context.Background(), cfg)
+}
+
+// CreateMaintenanceWindowWithContext creates a new maintenance [window],
+// stopping when ctx is done.
+func (a *API) CreateMaintenanceWindowWithContext(ctx context.Context, cfg *Maintenance) (*Maintenance, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid maintenance window config (nil)")
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("create maintenance window, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("create maintenance window, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Post(config.MaintenancePrefix, jsonCfg)
+	result, err := a.PostWithContext(ctx, config.MaintenancePrefix, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "creating maintenance window")
 	}
 
 	window := &Maintenance{}
-	if err := json.Unmarshal(result, window); err != nil {
+	if err := a.unmarshalJSON(result, window); err != nil {
 		return nil, errors.Wrap(err, "parsing maintenance window")
 	}
 
@@ -156,14 +165,26 @@ func (a *API) CreateMaintenanceWindow(cfg *Maintenance) (*Maintenance, error) {
 
 // DeleteMaintenanceWindow deletes passed maintenance [window].
 func (a *API) DeleteMaintenanceWindow(cfg *Maintenance) (bool, error) {
+	return a.DeleteMaintenanceWindowWithContext(context.Background(), cfg)
+}
+
+// DeleteMaintenanceWindowWithContext deletes passed maintenance [window],
+// stopping when ctx is done.
+func (a *API) DeleteMaintenanceWindowWithContext(ctx context.Context, cfg *Maintenance) (bool, error) {
 	if cfg == nil {
 		return false, errors.New("invalid maintenance window config (nil)")
 	}
-	return a.DeleteMaintenanceWindowByCID(CIDType(&cfg.CID))
+	return a.DeleteMaintenanceWindowByCIDWithContext(ctx, CIDType(&cfg.CID))
 }
 
 // DeleteMaintenanceWindowByCID deletes maintenance [window] with passed cid.
 func (a *API) DeleteMaintenanceWindowByCID(cid CIDType) (bool, error) {
+	return a.DeleteMaintenanceWindowByCIDWithContext(context.Background(), cid)
+}
+
+// DeleteMaintenanceWindowByCIDWithContext deletes maintenance [window] with
+// passed cid, stopping when ctx is done.
+func (a *API) DeleteMaintenanceWindowByCIDWithContext(ctx context.Context, cid CIDType) (bool, error) {
 	if cid == nil || *cid == "" {
 		return false, errors.New("invalid maintenance window CID (none)")
 	}
@@ -175,15 +196,11 @@ func (a *API) DeleteMaintenanceWindowByCID(cid CIDType) (bool, error) {
 		maintenanceCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.MaintenanceCIDRegex, maintenanceCID)
-	if err != nil {
-		return false, err
-	}
-	if !matched {
+	if !validCID(config.MaintenancePrefix, maintenanceCID) {
 		return false, errors.Errorf("invalid maintenance window CID (%s)", maintenanceCID)
 	}
 
-	_, err = a.Delete(maintenanceCID)
+	_, err := a.DeleteWithContext(ctx, maintenanceCID)
 	if err != nil {
 		return false, errors.Wrap(err, "deleting maintenance window")
 	}
@@ -195,37 +212,19 @@ func (a *API) DeleteMaintenanceWindowByCID(cid CIDType) (bool, error) {
 // the specified search query and/or filter. If nil is passed for
 // both parameters all maintenance [windows] will be returned.
 func (a *API) SearchMaintenanceWindows(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Maintenance, error) {
-	q := url.Values{}
-
-	if searchCriteria != nil && *searchCriteria != "" {
-		q.Set("search", string(*searchCriteria))
-	}
-
-	if filterCriteria != nil && len(*filterCriteria) > 0 {
-		for filter, criteria := range *filterCriteria {
-			for _, val := range criteria {
-				q.Add(filter, val)
-			}
-		}
-	}
-
-	if q.Encode() == "" {
-		return a.FetchMaintenanceWindows()
-	}
-
-	reqURL := url.URL{
-		Path:     config.MaintenancePrefix,
-		RawQuery: q.Encode(),
-	}
+	return a.SearchMaintenanceWindowsWithContext(context.Background(), searchCriteria, filterCriteria)
+}
 
-	result, err := a.Get(reqURL.String())
-	if err != nil {
-		return nil, errors.Wrap(err, "searching maintenance windows")
+// SearchMaintenanceWindowsWithContext returns maintenance [windows] matching
+// the specified search query and/or filter, stopping when ctx is done.
+func (a *API) SearchMaintenanceWindowsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Maintenance, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchMaintenanceWindowsWithContext(ctx)
 	}
 
 	var windows []Maintenance
-	if err := json.Unmarshal(result, &windows); err != nil {
-		return nil, errors.Wrap(err, "parsing maintenance windows")
+	if err := a.searchJSONContext(ctx, config.MaintenancePrefix, searchCriteria, filterCriteria, &windows); err != nil {
+		return nil, errors.Wrap(err, "searching maintenance windows")
 	}
 
 	return &windows, nil
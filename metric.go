@@ -9,10 +9,8 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
-	"regexp"
 	"strings"
 
 	"github.com/circonus-labs/go-apiclient/config"
@@ -39,6 +37,12 @@ type Metric struct {
 
 // FetchMetric retrieves metric with passed cid.
 func (a *API) FetchMetric(cid CIDType) (*Metric, error) {
+	return a.FetchMetricWithContext(context.Background(), cid)
+}
+
+// FetchMetricWithContext retrieves metric with passed cid, stopping when ctx
+// is done.
+func (a *API) FetchMetricWithContext(ctx context.Context, cid CIDType) (*Metric, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.New("invalid metric CID (none)")
 	}
@@ -50,25 +54,21 @@ func (a *API) FetchMetric(cid CIDType) (*Metric, error) {
 		metricCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.MetricCIDRegex, metricCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.MetricPrefix, metricCID) {
 		return nil, errors.Errorf("invalid metric CID (%s)", metricCID)
 	}
 
-	result, err := a.Get(metricCID)
+	result, err := a.GetWithContext(ctx, metricCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching metric")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch metric, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch metric, received JSON: %s", string(result))
 	}
 
 	metric := &Metric{}
-	if err := json.Unmarshal(result, metric); err != nil {
+	if err := a.unmarshalJSON(result, metric); err != nil {
 		return nil, errors.Wrap(err, "parsing metric")
 	}
 
@@ -77,14 +77,15 @@ func (a *API) FetchMetric(cid CIDType) (*Metric, error) {
 
 // FetchMetrics retrieves all metrics available to API Token.
 func (a *API) FetchMetrics() (*[]Metric, error) {
-	result, err := a.Get(config.MetricPrefix)
-	if err != nil {
-		return nil, errors.Wrap(err, "fetching metrics")
-	}
+	return a.FetchMetricsWithContext(context.Background())
+}
 
+// FetchMetricsWithContext retrieves all metrics available to API Token,
+// stopping when ctx is done.
+func (a *API) FetchMetricsWithContext(ctx context.Context) (*[]Metric, error) {
 	var metrics []Metric
-	if err := json.Unmarshal(result, &metrics); err != nil {
-		return nil, errors.Wrap(err, "parsing metrics")
+	if err := a.getJSONContext(ctx, config.MetricPrefix, &metrics); err != nil {
+		return nil, errors.Wrap(err, "fetching metrics")
 	}
 
 	return &metrics, nil
@@ -92,36 +93,37 @@ func (a *API) FetchMetrics() (*[]Metric, error) {
 
 // UpdateMetric updates passed metric.
 func (a *API) UpdateMetric(cfg *Metric) (*Metric, error) {
+	return a.UpdateMetricWithContext(context.Background(), cfg)
+}
+
+// UpdateMetricWithContext updates passed metric, stopping when ctx is done.
+func (a *API) UpdateMetricWithContext(ctx context.Context, cfg *Metric) (*Metric, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid metric config (nil)")
 	}
 
 	metricCID := cfg.CID
 
-	matched, err := regexp.MatchString(config.MetricCIDRegex, metricCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.MetricPrefix, metricCID) {
 		return nil, errors.Errorf("invalid metric CID (%s)", metricCID)
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("update metric, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("update metric, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Put(metricCID, jsonCfg)
+	result, err := a.PutWithContext(ctx, metricCID, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "updating metric")
 	}
 
 	metric := &Metric{}
-	if err := json.Unmarshal(result, metric); err != nil {
+	if err := a.unmarshalJSON(result, metric); err != nil {
 		return nil, errors.Wrap(err, "parsing metric")
 	}
 
@@ -132,37 +134,19 @@ func (a *API) UpdateMetric(cfg *Metric) (*Metric, error) {
 // and/or filter. If nil is passed for both parameters all metrics
 // will be returned.
 func (a *API) SearchMetrics(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Metric, error) {
-	q := url.Values{}
-
-	if searchCriteria != nil && *searchCriteria != "" {
-		q.Set("search", string(*searchCriteria))
-	}
-
-	if filterCriteria != nil && len(*filterCriteria) > 0 {
-		for filter, criteria := range *filterCriteria {
-			for _, val := range criteria {
-				q.Add(filter, val)
-			}
-		}
-	}
-
-	if q.Encode() == "" {
-		return a.FetchMetrics()
-	}
-
-	reqURL := url.URL{
-		Path:     config.MetricPrefix,
-		RawQuery: q.Encode(),
-	}
+	return a.SearchMetricsWithContext(context.Background(), searchCriteria, filterCriteria)
+}
 
-	result, err := a.Get(reqURL.String())
-	if err != nil {
-		return nil, errors.Wrap(err, "searching metrics")
+// SearchMetricsWithContext returns metrics matching the specified search
+// query and/or filter, stopping when ctx is done.
+func (a *API) SearchMetricsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Metric, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchMetricsWithContext(ctx)
 	}
 
 	var metrics []Metric
-	if err := json.Unmarshal(result, &metrics); err != nil {
-		return nil, errors.Wrap(err, "parsing metrics")
+	if err := a.searchJSONContext(ctx, config.MetricPrefix, searchCriteria, filterCriteria, &metrics); err != nil {
+		return nil, errors.Wrap(err, "searching metrics")
 	}
 
 	return &metrics, nil
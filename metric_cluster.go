@@ -8,10 +8,9 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/url"
-	"regexp"
 	"strings"
 
 	"github.com/circonus-labs/go-apiclient/config"
@@ -42,6 +41,12 @@ func NewMetricCluster() *MetricCluster {
 
 // FetchMetricCluster retrieves metric cluster with passed cid.
 func (a *API) FetchMetricCluster(cid CIDType, extras string) (*MetricCluster, error) {
+	return a.FetchMetricClusterWithContext(context.Background(), cid, extras)
+}
+
+// FetchMetricClusterWithContext retrieves metric cluster with passed cid,
+// stopping when ctx is done.
+func (a *API) FetchMetricClusterWithContext(ctx context.Context, cid CIDType, extras string) (*MetricCluster, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.New("invalid metric cluster CID (none)")
 	}
@@ -53,11 +58,7 @@ func (a *API) FetchMetricCluster(cid CIDType, extras string) (*MetricCluster, er
 		clusterCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.MetricClusterCIDRegex, clusterCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.MetricClusterPrefix, clusterCID) {
 		return nil, errors.Errorf("invalid metric cluster CID (%s)", clusterCID)
 	}
 
@@ -79,17 +80,17 @@ func (a *API) FetchMetricCluster(cid CIDType, extras string) (*MetricCluster, er
 		reqURL.RawQuery = q.Encode()
 	}
 
-	result, err := a.Get(reqURL.String())
+	result, err := a.GetWithContext(ctx, reqURL.String())
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching metric cluster")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch metric cluster, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch metric cluster, received JSON: %s", string(result))
 	}
 
 	cluster := &MetricCluster{}
-	if err := json.Unmarshal(result, cluster); err != nil {
+	if err := a.unmarshalJSON(result, cluster); err != nil {
 		return nil, errors.Wrap(err, "parsing metric cluster")
 	}
 
@@ -98,6 +99,12 @@ func (a *API) FetchMetricCluster(cid CIDType, extras string) (*MetricCluster, er
 
 // FetchMetricClusters retrieves all metric clusters available to API Token.
 func (a *API) FetchMetricClusters(extras string) (*[]MetricCluster, error) {
+	return a.FetchMetricClustersWithContext(context.Background(), extras)
+}
+
+// FetchMetricClustersWithContext retrieves all metric clusters available to
+// API Token, stopping when ctx is done.
+func (a *API) FetchMetricClustersWithContext(ctx context.Context, extras string) (*[]MetricCluster, error) {
 	reqURL := url.URL{
 		Path: config.MetricClusterPrefix,
 	}
@@ -116,14 +123,9 @@ func (a *API) FetchMetricClusters(extras string) (*[]MetricCluster, error) {
 		reqURL.RawQuery = q.Encode()
 	}
 
-	result, err := a.Get(reqURL.String())
-	if err != nil {
-		return nil, errors.Wrap(err, "fetching metric clusters")
-	}
-
 	var clusters []MetricCluster
-	if err := json.Unmarshal(result, &clusters); err != nil {
-		return nil, errors.Wrap(err, "parsing metric clusters")
+	if err := a.getJSONContext(ctx, reqURL.String(), &clusters); err != nil {
+		return nil, errors.Wrap(err, "fetching metric clusters")
 	}
 
 	return &clusters, nil
@@ -131,36 +133,38 @@ func (a *API) FetchMetricClusters(extras string) (*[]MetricCluster, error) {
 
 // UpdateMetricCluster updates passed metric cluster.
 func (a *API) UpdateMetricCluster(cfg *MetricCluster) (*MetricCluster, error) {
+	return a.UpdateMetricClusterWithContext(context.Background(), cfg)
+}
+
+// UpdateMetricClusterWithContext updates passed metric cluster, stopping when
+// ctx is done.
+func (a *API) UpdateMetricClusterWithContext(ctx context.Context, cfg *MetricCluster) (*MetricCluster, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid metric cluster config (nil)")
 	}
 
 	clusterCID := cfg.CID
 
-	matched, err := regexp.MatchString(config.MetricClusterCIDRegex, clusterCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.MetricClusterPrefix, clusterCID) {
 		return nil, errors.Errorf("invalid metric cluster CID (%s)", clusterCID)
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("update metric cluster, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("update metric cluster, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Put(clusterCID, jsonCfg)
+	result, err := a.PutWithContext(ctx, clusterCID, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "updating metric cluster")
 	}
 
 	cluster := &MetricCluster{}
-	if err := json.Unmarshal(result, cluster); err != nil {
+	if err := a.unmarshalJSON(result, cluster); err != nil {
 		return nil, errors.Wrap(err, "parsing metric cluster")
 	}
 
@@ -169,26 +173,32 @@ func (a *API) UpdateMetricCluster(cfg *MetricCluster) (*MetricCluster, error) {
 
 // CreateMetricCluster creates a new metric cluster.
 func (a *API) CreateMetricCluster(cfg *MetricCluster) (*MetricCluster, error) {
+	return a.CreateMetricClusterWithContext(context.Background(), cfg)
+}
+
+// CreateMetricClusterWithContext creates a new metric cluster, stopping when
+// ctx is done.
+func (a *API) CreateMetricClusterWithContext(ctx context.Context, cfg *MetricCluster) (*MetricCluster, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid metric cluster config (nil)")
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("create metric cluster, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("create metric cluster, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Post(config.MetricClusterPrefix, jsonCfg)
+	result, err := a.PostWithContext(ctx, config.MetricClusterPrefix, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "creating metric cluster")
 	}
 
 	cluster := &MetricCluster{}
-	if err := json.Unmarshal(result, cluster); err != nil {
+	if err := a.unmarshalJSON(result, cluster); err != nil {
 		return nil, errors.Wrap(err, "parsing metric cluster")
 	}
 
@@ -197,14 +207,26 @@ func (a *API) CreateMetricCluster(cfg *MetricCluster) (*MetricCluster, error) {
 
 // DeleteMetricCluster deletes passed metric cluster.
 func (a *API) DeleteMetricCluster(cfg *MetricCluster) (bool, error) {
+	return a.DeleteMetricClusterWithContext(context.Background(), cfg)
+}
+
+// DeleteMetricClusterWithContext deletes passed metric cluster, stopping when
+// ctx is done.
+func (a *API) DeleteMetricClusterWithContext(ctx context.Context, cfg *MetricCluster) (bool, error) {
 	if cfg == nil {
 		return false, errors.New("invalid metric cluster config (nil)")
 	}
-	return a.DeleteMetricClusterByCID(CIDType(&cfg.CID))
+	return a.DeleteMetricClusterByCIDWithContext(ctx, CIDType(&cfg.CID))
 }
 
 // DeleteMetricClusterByCID deletes metric cluster with passed cid.
 func (a *API) DeleteMetricClusterByCID(cid CIDType) (bool, error) {
+	return a.DeleteMetricClusterByCIDWithContext(context.Background(), cid)
+}
+
+// DeleteMetricClusterByCIDWithContext deletes metric cluster with passed cid,
+// stopping when ctx is done.
+func (a *API) DeleteMetricClusterByCIDWithContext(ctx context.Context, cid CIDType) (bool, error) {
 	if cid == nil || *cid == "" {
 		return false, errors.New("invalid metric cluster CID (none)")
 	}
@@ -216,15 +238,11 @@ func (a *API) DeleteMetricClusterByCID(cid CIDType) (bool, error) {
 		clusterCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.MetricClusterCIDRegex, clusterCID)
-	if err != nil {
-		return false, err
-	}
-	if !matched {
+	if !validCID(config.MetricClusterPrefix, clusterCID) {
 		return false, errors.Errorf("invalid metric cluster CID (%s)", clusterCID)
 	}
 
-	_, err = a.Delete(clusterCID)
+	_, err := a.DeleteWithContext(ctx, clusterCID)
 	if err != nil {
 		return false, errors.Wrap(err, "deleting metric cluster")
 	}
@@ -236,37 +254,19 @@ func (a *API) DeleteMetricClusterByCID(cid CIDType) (bool, error) {
 // search query and/or filter. If nil is passed for both parameters
 // all metric clusters will be returned.
 func (a *API) SearchMetricClusters(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]MetricCluster, error) {
-	q := url.Values{}
-
-	if searchCriteria != nil && *searchCriteria != "" {
-		q.Set("search", string(*searchCriteria))
-	}
-
-	if filterCriteria != nil && len(*filterCriteria) > 0 {
-		for filter, criteria := range *filterCriteria {
-			for _, val := range criteria {
-				q.Add(filter, val)
-			}
-		}
-	}
-
-	if q.Encode() == "" {
-		return a.FetchMetricClusters("")
-	}
-
-	reqURL := url.URL{
-		Path:     config.MetricClusterPrefix,
-		RawQuery: q.Encode(),
-	}
+	return a.SearchMetricClustersWithContext(context.Background(), searchCriteria, filterCriteria)
+}
 
-	result, err := a.Get(reqURL.String())
-	if err != nil {
-		return nil, errors.Wrap(err, "searching metric clusters")
+// SearchMetricClustersWithContext returns metric clusters matching the
+// specified search query and/or filter, stopping when ctx is done.
+func (a *API) SearchMetricClustersWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]MetricCluster, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchMetricClustersWithContext(ctx, "")
 	}
 
 	var clusters []MetricCluster
-	if err := json.Unmarshal(result, &clusters); err != nil {
-		return nil, errors.Wrap(err, "parsing metric clusters")
+	if err := a.searchJSONContext(ctx, config.MetricClusterPrefix, searchCriteria, filterCriteria, &clusters); err != nil {
+		return nil, errors.Wrap(err, "searching metric clusters")
 	}
 
 	return &clusters, nil
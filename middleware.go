@@ -0,0 +1,83 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Middleware - request and response hooks (see Config RequestHooks and
+// ResponseHooks) around each attempt of an API call
+
+package apiclient
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// RequestHook is called with each request before it is sent, and may change
+// it (e.g. add headers, or sign it). Returning an error fails the call.
+type RequestHook func(req *http.Request) error
+
+// ResponseHook is called with each response received, before the API reads
+// it. Returning an error fails the call.
+type ResponseHook func(resp *http.Response) error
+
+// hookError is the error of a request or response hook, the call is not
+// retried
+type hookError struct {
+	err error
+}
+
+func (e *hookError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the error of the hook.
+func (e *hookError) Unwrap() error {
+	return e.err
+}
+
+// isHookError returns true if the error of a request is the error of a hook
+func isHookError(err error) bool {
+	if ue, ok := err.(*url.Error); ok {
+		_, ok = ue.Err.(*hookError)
+		return ok
+	}
+	return false
+}
+
+// middlewareTransport calls the request hooks, in order, before the next
+// transport's RoundTrip, and the response hooks, in order, after it
+type middlewareTransport struct {
+	next     http.RoundTripper
+	request  []RequestHook
+	response []ResponseHook
+}
+
+// RoundTrip calls the hooks around the next transport's RoundTrip
+func (t *middlewareTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(t.request) > 0 {
+		// the request of a RoundTrip must not be changed
+		req = req.Clone(req.Context())
+		for _, hook := range t.request {
+			if err := hook(req); err != nil {
+				if req.Body != nil {
+					req.Body.Close() // nolint: errcheck
+				}
+				return nil, &hookError{err: errors.Wrap(err, "request hook")}
+			}
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, hook := range t.response {
+		if err := hook(resp); err != nil {
+			resp.Body.Close() // nolint: errcheck
+			return nil, &hookError{err: errors.Wrap(err, "response hook")}
+		}
+	}
+	return resp, nil
+}
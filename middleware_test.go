@@ -0,0 +1,130 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.Header.Get("X-Signature") != "signed:"+r.URL.Path {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("X-Served-By", "test")
+		fmt.Fprintln(w, `[]`)
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var order []string
+	record := func(s string) {
+		mu.Lock()
+		order = append(order, s)
+		mu.Unlock()
+	}
+	errRefused := errors.New("refused")
+	refuse := false
+
+	apih, err := New(&Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      server.URL,
+		RequestHooks: []RequestHook{
+			func(req *http.Request) error {
+				record("request 1")
+				if refuse {
+					return errRefused
+				}
+				req.Header.Set("X-Signature", "signed:"+req.URL.Path)
+				return nil
+			},
+			func(req *http.Request) error {
+				record("request 2 " + req.Header.Get("X-Signature"))
+				return nil
+			},
+		},
+		ResponseHooks: []ResponseHook{
+			func(resp *http.Response) error {
+				record("response " + resp.Header.Get("X-Served-By"))
+				return nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if _, err := apih.FetchGraphs(); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	expected := []string{"request 1", "request 2 signed:/graph", "response test"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("expected %v, got %v", expected, order)
+	}
+
+	// a hook's error fails the call, without retrying or sending it
+	refuse = true
+	order = nil
+	_, err = apih.FetchGraphs()
+	if !errors.Is(err, errRefused) {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+	if !reflect.DeepEqual(order, []string{"request 1"}) {
+		t.Fatalf("expected a single attempt, got %v", order)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("unexpected calls (%d)", n)
+	}
+
+	// copies of the API keep the hooks
+	refuse = false
+	acct, err := apih.WithAccount("/account/2")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if _, err := acct.FetchGraphs(); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+}
+
+func TestMiddlewareResponseError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `[]`)
+	}))
+	defer server.Close()
+
+	errUnsigned := errors.New("unsigned response")
+	apih, err := New(&Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      server.URL,
+		ResponseHooks: []ResponseHook{
+			func(resp *http.Response) error {
+				if resp.Header.Get("X-Signature") == "" {
+					return errUnsigned
+				}
+				return nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if _, err := apih.FetchGraphs(); !errors.Is(err, errUnsigned) {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+}
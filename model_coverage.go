@@ -0,0 +1,263 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Model coverage - report the objects and fields returned by the API which
+// the library does not model
+
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// DefaultCoverageSampleSize is the number of objects of each type checked
+// for model coverage
+const DefaultCoverageSampleSize = 5
+
+// coverageUnlisted are the types not checked unless requested, as they
+// cannot be listed (check bundle metrics), or not cheaply (metrics)
+var coverageUnlisted = map[string]bool{
+	config.CheckBundleMetricsPrefix: true,
+	config.MetricPrefix:             true,
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// ModelCoverageConfig defines the types checked for model coverage
+type ModelCoverageConfig struct {
+	// Types (cid prefixes, e.g. config.GraphPrefix) to check - default all
+	// the library models, other than check bundle metrics and metrics
+	Types []string
+
+	// SampleSize is the number of objects of each type checked - default
+	// DefaultCoverageSampleSize
+	SampleSize int
+}
+
+// TypeCoverage defines how well the library models the objects of a type
+type TypeCoverage struct {
+	Type          string
+	Sampled       int      // objects checked
+	UnknownFields []string // fields returned not modeled (e.g. "config.url", "datapoints[].axis")
+	Errors        []string // failures to list the objects, or to decode them (e.g. a field of another type)
+}
+
+// Covered returns true if the objects checked are fully modeled.
+func (c *TypeCoverage) Covered() bool {
+	return len(c.UnknownFields) == 0 && len(c.Errors) == 0
+}
+
+// ModelCoverageReport defines the model coverage of the types checked
+type ModelCoverageReport struct {
+	Types []TypeCoverage // in order of type
+}
+
+// Gaps returns the types not fully modeled.
+func (r *ModelCoverageReport) Gaps() []TypeCoverage {
+	gaps := []TypeCoverage{}
+	for _, c := range r.Types {
+		if !c.Covered() {
+			gaps = append(gaps, c)
+		}
+	}
+	return gaps
+}
+
+// Coverage returns the fraction (0-1) of the types checked which are fully
+// modeled, 1 if none were.
+func (r *ModelCoverageReport) Coverage() float64 {
+	if len(r.Types) == 0 {
+		return 1
+	}
+	return float64(len(r.Types)-len(r.Gaps())) / float64(len(r.Types))
+}
+
+// ModelCoverage lists objects of each type, decoding a sample of them into
+// the library's models and reporting the fields the models do not have
+// (which are dropped when objects are updated from the models), and the
+// objects which fail to decode - to check whether the library models the
+// live API before relying on round-tripping objects. Failures of a type
+// are reported in its coverage, only ctx being done is returned as an error.
+func (a *API) ModelCoverage(ctx context.Context, cfg *ModelCoverageConfig) (*ModelCoverageReport, error) {
+	var types []string
+	sampleSize := DefaultCoverageSampleSize
+	if cfg != nil {
+		types = append(types, cfg.Types...)
+		if cfg.SampleSize > 0 {
+			sampleSize = cfg.SampleSize
+		}
+	}
+	if len(types) == 0 {
+		for prefix := range batchTypes {
+			if !coverageUnlisted[prefix] {
+				types = append(types, prefix)
+			}
+		}
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		if batchTypes[t] == nil {
+			return nil, errors.Errorf("invalid model coverage type (%s)", t)
+		}
+	}
+
+	report := &ModelCoverageReport{Types: make([]TypeCoverage, 0, len(types))}
+	for _, t := range types {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		coverage := TypeCoverage{Type: t, UnknownFields: []string{}, Errors: []string{}}
+
+		var objs []json.RawMessage
+		if err := a.getJSONContext(ctx, t, &objs); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return report, ctxErr
+			}
+			coverage.Errors = append(coverage.Errors, errors.Wrapf(err, "listing %s", t).Error())
+			report.Types = append(report.Types, coverage)
+			continue
+		}
+		if len(objs) > sampleSize {
+			objs = objs[:sampleSize]
+		}
+
+		unknown := map[string]bool{}
+		for _, data := range objs {
+			coverage.Sampled++
+			obj := batchTypes[t]()
+			if err := json.Unmarshal(data, obj); err != nil {
+				coverage.Errors = append(coverage.Errors, err.Error())
+			}
+			var raw interface{}
+			if err := json.Unmarshal(data, &raw); err == nil {
+				unknownFields(reflect.TypeOf(obj), raw, "", unknown)
+			}
+		}
+		for field := range unknown {
+			coverage.UnknownFields = append(coverage.UnknownFields, field)
+		}
+		sort.Strings(coverage.UnknownFields)
+		coverage.Errors = uniqueStrings(coverage.Errors)
+
+		report.Types = append(report.Types, coverage)
+	}
+
+	return report, nil
+}
+
+// unknownFields adds the paths of the fields of the decoded json value v
+// which the type t does not have to found
+func unknownFields(t reflect.Type, v interface{}, path string, found map[string]bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return // decoded as the type sees fit
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := jsonFields(t)
+		for k, val := range obj {
+			name := k
+			if path != "" {
+				name = path + "." + k
+			}
+			ft, ok := fields[k]
+			if !ok {
+				// as encoding/json, names match case insensitively
+				for n, f := range fields {
+					if strings.EqualFold(n, k) {
+						ft, ok = f, true
+						break
+					}
+				}
+			}
+			if !ok {
+				found[name] = true
+				continue
+			}
+			unknownFields(ft, val, name, found)
+		}
+	case reflect.Map:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for _, val := range obj {
+			unknownFields(t.Elem(), val, path+".*", found)
+		}
+	case reflect.Slice, reflect.Array:
+		list, ok := v.([]interface{})
+		if !ok {
+			return
+		}
+		for _, val := range list {
+			unknownFields(t.Elem(), val, path+"[]", found)
+		}
+	}
+}
+
+// jsonFields returns the types of the fields of a struct by json name,
+// including those of embedded structs
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			embedded = append(embedded, ft)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	// fields of the struct take precedence over those embedded
+	for _, et := range embedded {
+		for name, ft := range jsonFields(et) {
+			if _, ok := fields[name]; !ok {
+				fields[name] = ft
+			}
+		}
+	}
+	return fields
+}
+
+// uniqueStrings returns the strings without duplicates, in order
+func uniqueStrings(s []string) []string {
+	seen := make(map[string]bool, len(s))
+	unique := s[:0]
+	for _, v := range s {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
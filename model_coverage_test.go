@@ -0,0 +1,88 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
+)
+
+func TestModelCoverage(t *testing.T) {
+	fake, err := fakecirconus.New(&fakecirconus.Config{Fixtures: map[string]interface{}{
+		"/graph/1": map[string]interface{}{"_cid": "/graph/1", "title": "web", "new_field": true,
+			"datapoints": []interface{}{map[string]interface{}{"metric_name": "cpu", "new_axis": "left"}}},
+		"/graph/2":         map[string]interface{}{"_cid": "/graph/2", "Title": "db", "guides": []interface{}{map[string]interface{}{"color": "#fff", "hidden": true, "shade": 1}}},
+		"/rule_set/1_cpu":  map[string]interface{}{"_cid": "/rule_set/1_cpu", "rules": "not a list"},
+		"/contact_group/1": ContactGroup{CID: "/contact_group/1", Name: "ops"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", URL: fake.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	cfg := &ModelCoverageConfig{Types: []string{config.RuleSetPrefix, config.GraphPrefix, config.ContactGroupPrefix, config.AccountPrefix}}
+	report, err := apih.ModelCoverage(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	types := []string{}
+	for _, c := range report.Types {
+		types = append(types, c.Type)
+	}
+	if !reflect.DeepEqual(types, []string{"/account", "/contact_group", "/graph", "/rule_set"}) {
+		t.Fatalf("unexpected types (%v)", types)
+	}
+
+	tests := []struct {
+		id              string
+		coverage        TypeCoverage
+		expectedSampled int
+		expectedFields  []string
+		expectedErr     string
+	}{
+		{"none", report.Types[0], 0, []string{}, ""},
+		{"covered", report.Types[1], 1, []string{}, ""},
+		{"unknown fields", report.Types[2], 2, []string{"datapoints[].new_axis", "guides[].shade", "new_field"}, ""},
+		{"decode error", report.Types[3], 1, []string{}, "cannot unmarshal string"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			c := test.coverage
+			if c.Sampled != test.expectedSampled {
+				t.Fatalf("unexpected sampled (%d)", c.Sampled)
+			}
+			if !reflect.DeepEqual(c.UnknownFields, test.expectedFields) {
+				t.Fatalf("unexpected unknown fields (%v)", c.UnknownFields)
+			}
+			if test.expectedErr == "" && len(c.Errors) > 0 {
+				t.Fatalf("unexpected errors (%v)", c.Errors)
+			}
+			if test.expectedErr != "" && (len(c.Errors) != 1 || !strings.Contains(c.Errors[0], test.expectedErr)) {
+				t.Fatalf("unexpected errors (%v)", c.Errors)
+			}
+		})
+	}
+
+	if gaps := report.Gaps(); len(gaps) != 2 || report.Coverage() != 0.5 {
+		t.Fatalf("unexpected gaps (%v) coverage (%f)", gaps, report.Coverage())
+	}
+
+	if _, err := apih.ModelCoverage(context.Background(), &ModelCoverageConfig{Types: []string{"/nope"}}); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Notification audit - report gaps in how alerts reach people
+
+package apiclient
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// Notification audit finding kinds, see NotificationFinding.Kind
+const (
+	// FindingSilent - alerts of a severity notify nobody
+	FindingSilent = "silent"
+	// FindingNoEscalation - alerts of a severity are not escalated by any of the contact groups notified
+	FindingNoEscalation = "no_escalation"
+	// FindingMissingContactGroup - a contact group referenced does not exist
+	FindingMissingContactGroup = "missing_contact_group"
+	// FindingInvalidContact - a contact method points at an invalid endpoint
+	FindingInvalidContact = "invalid_contact"
+)
+
+// phoneRegex matches phone numbers, digits with optional separators
+var phoneRegex = regexp.MustCompile(`^\+?[0-9][0-9 ().-]{5,}$`)
+
+// NotificationFinding defines a notification policy problem
+type NotificationFinding struct {
+	Kind     string // see Finding* constants
+	CID      string // rule set, rule set group, or contact group
+	Name     string
+	Severity uint // alert severity, 0 if not specific to one
+	Detail   string
+}
+
+// NotificationAudit defines the findings of a notification policy audit,
+// ordered by kind, cid, and severity
+type NotificationAudit struct {
+	Findings []NotificationFinding
+}
+
+// AuditNotifications fetches the rule sets, rule set groups, and contact
+// groups of the account and audits them, see AuditNotificationPolicies.
+func (a *API) AuditNotifications() (*NotificationAudit, error) {
+	ruleSets, err := a.FetchRuleSets()
+	if err != nil {
+		return nil, err
+	}
+	groups, err := a.FetchRuleSetGroups()
+	if err != nil {
+		return nil, err
+	}
+	contactGroups, err := a.FetchContactGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	return AuditNotificationPolicies(*ruleSets, *groups, *contactGroups), nil
+}
+
+// AuditNotificationPolicies reports, for each severity rule sets and rule set
+// groups raise alerts at, severities which notify nobody (no contact groups,
+// or only missing or empty ones) and severities none of the contact groups
+// notified escalate. Rule sets which notify nobody themselves are not
+// reported if they are a condition of a rule set group which notifies
+// someone. Contact groups referenced but missing, and contact methods with
+// invalid endpoints (email addresses, phone numbers, URLs, or users), are
+// reported as well.
+func AuditNotificationPolicies(ruleSets []RuleSet, groups []RuleSetGroup, contactGroups []ContactGroup) *NotificationAudit {
+	audit := &NotificationAudit{Findings: []NotificationFinding{}}
+
+	contacts := make(map[string]*ContactGroup, len(contactGroups))
+	for i := range contactGroups {
+		cg := &contactGroups[i]
+		contacts[cg.CID] = cg
+		audit.Findings = append(audit.Findings, auditContactGroup(cg)...)
+	}
+	// escalations to missing groups, once all groups are known
+	for _, cg := range contactGroups {
+		for i, e := range cg.Escalations {
+			if e != nil && e.ContactGroupCID != "" && contacts[e.ContactGroupCID] == nil {
+				audit.Findings = append(audit.Findings, NotificationFinding{
+					Kind:     FindingMissingContactGroup,
+					CID:      cg.CID,
+					Name:     cg.Name,
+					Severity: uint(i + 1),
+					Detail:   fmt.Sprintf("escalates to missing contact group %s", e.ContactGroupCID),
+				})
+			}
+		}
+	}
+
+	grouped := map[string]bool{} // rule sets in groups notifying someone
+	for _, g := range groups {
+		severities := []uint{}
+		for _, f := range g.Formulas {
+			severities = append(severities, f.RaiseSeverity)
+		}
+		findings, notifies := auditContactGroups(g.CID, g.Name, severities, g.ContactGroups, contacts)
+		audit.Findings = append(audit.Findings, findings...)
+		if notifies {
+			for _, c := range g.RuleSetConditions {
+				grouped[c.RuleSetCID] = true
+			}
+		}
+	}
+
+	for _, rs := range ruleSets {
+		severities := []uint{}
+		for _, r := range rs.Rules {
+			severities = append(severities, r.Severity)
+		}
+		findings, _ := auditContactGroups(rs.CID, rs.Name, severities, rs.ContactGroups, contacts)
+		for _, f := range findings {
+			if f.Kind == FindingMissingContactGroup || !grouped[rs.CID] {
+				audit.Findings = append(audit.Findings, f)
+			}
+		}
+	}
+
+	sort.SliceStable(audit.Findings, func(i, j int) bool {
+		fi, fj := audit.Findings[i], audit.Findings[j]
+		if fi.Kind != fj.Kind {
+			return fi.Kind < fj.Kind
+		}
+		if fi.CID != fj.CID {
+			return fi.CID < fj.CID
+		}
+		return fi.Severity < fj.Severity
+	})
+
+	return audit
+}
+
+// auditContactGroups returns the findings for the severities raised by a rule
+// set or rule set group, and whether any severity notifies someone
+func auditContactGroups(cid, name string, severities []uint, groups map[uint8][]string, contacts map[string]*ContactGroup) ([]NotificationFinding, bool) {
+	findings := []NotificationFinding{}
+	notifies := false
+
+	seen := map[uint]bool{}
+	for _, sev := range severities {
+		if sev == 0 || seen[sev] {
+			continue
+		}
+		seen[sev] = true
+
+		reached, escalated := 0, false
+		for _, groupCID := range groups[uint8(sev)] {
+			cg := contacts[groupCID]
+			if cg == nil {
+				findings = append(findings, NotificationFinding{
+					Kind:     FindingMissingContactGroup,
+					CID:      cid,
+					Name:     name,
+					Severity: sev,
+					Detail:   fmt.Sprintf("notifies missing contact group %s", groupCID),
+				})
+				continue
+			}
+			if len(cg.Contacts.External)+len(cg.Contacts.Users) > 0 {
+				reached++
+			}
+			if int(sev) <= len(cg.Escalations) && cg.Escalations[sev-1] != nil && cg.Escalations[sev-1].ContactGroupCID != "" {
+				escalated = true
+			}
+		}
+
+		switch {
+		case reached == 0:
+			findings = append(findings, NotificationFinding{
+				Kind:     FindingSilent,
+				CID:      cid,
+				Name:     name,
+				Severity: sev,
+				Detail:   fmt.Sprintf("severity %d alerts notify nobody", sev),
+			})
+		case !escalated:
+			notifies = true
+			findings = append(findings, NotificationFinding{
+				Kind:     FindingNoEscalation,
+				CID:      cid,
+				Name:     name,
+				Severity: sev,
+				Detail:   fmt.Sprintf("severity %d alerts are not escalated", sev),
+			})
+		default:
+			notifies = true
+		}
+	}
+
+	return findings, notifies
+}
+
+// auditContactGroup returns the findings for the contact methods of a contact group
+func auditContactGroup(cg *ContactGroup) []NotificationFinding {
+	findings := []NotificationFinding{}
+	invalid := func(detail string) {
+		findings = append(findings, NotificationFinding{
+			Kind:   FindingInvalidContact,
+			CID:    cg.CID,
+			Name:   cg.Name,
+			Detail: detail,
+		})
+	}
+
+	for _, c := range cg.Contacts.External {
+		if err := validateContactEndpoint(c.Method, c.Info); err != nil {
+			invalid(err.Error())
+		}
+	}
+	for _, u := range cg.Contacts.Users {
+		if !validCID(config.UserPrefix, u.UserCID) {
+			invalid(fmt.Sprintf("%s contact, invalid user (%s)", u.Method, u.UserCID))
+		}
+	}
+
+	return findings
+}
+
+// validateContactEndpoint checks the contact info of an external contact method
+func validateContactEndpoint(method, info string) error {
+	info = strings.TrimSpace(info)
+	if info == "" {
+		return errors.Errorf("%s contact, endpoint (none)", method)
+	}
+
+	switch method {
+	case "email", "xmpp":
+		if _, err := mail.ParseAddress(info); err != nil {
+			return errors.Errorf("%s contact, invalid address (%s)", method, info)
+		}
+	case "sms", "phone":
+		if !phoneRegex.MatchString(info) {
+			return errors.Errorf("%s contact, invalid phone number (%s)", method, info)
+		}
+	case "http", "https", "slack", "webhook":
+		if strings.HasPrefix(info, "#") && method == "slack" {
+			return nil // slack channel
+		}
+		u, err := url.Parse(info)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.Errorf("%s contact, invalid URL (%s)", method, info)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
+)
+
+func testNotificationContactGroups() []ContactGroup {
+	oncall := ContactGroup{
+		CID:         "/contact_group/1",
+		Name:        "oncall",
+		Escalations: make([]*ContactGroupEscalation, 5),
+		Contacts: ContactGroupContacts{
+			External: []ContactGroupContactsExternal{
+				{Method: "email", Info: "oncall@example.com"},
+				{Method: "sms", Info: "+1 555-0100"},
+				{Method: "http", Info: "https://hooks.example.com/alert"},
+			},
+			Users: []ContactGroupContactsUser{{Method: "email", UserCID: "/user/1"}},
+		},
+	}
+	oncall.Escalations[0] = &ContactGroupEscalation{After: 900, ContactGroupCID: "/contact_group/2"}
+	oncall.Escalations[1] = &ContactGroupEscalation{After: 900, ContactGroupCID: "/contact_group/9"}
+
+	managers := ContactGroup{
+		CID:         "/contact_group/2",
+		Name:        "managers",
+		Escalations: make([]*ContactGroupEscalation, 5),
+		Contacts: ContactGroupContacts{
+			External: []ContactGroupContactsExternal{
+				{Method: "email", Info: "managers"},
+				{Method: "sms", Info: "call me"},
+				{Method: "http", Info: "hooks.example.com"},
+				{Method: "slack", Info: "#alerts"},
+				{Method: "pagerduty", Info: ""},
+			},
+			Users: []ContactGroupContactsUser{{Method: "email", UserCID: "1234"}},
+		},
+	}
+
+	empty := ContactGroup{CID: "/contact_group/3", Name: "empty", Escalations: make([]*ContactGroupEscalation, 5)}
+
+	return []ContactGroup{oncall, managers, empty}
+}
+
+func testNotificationRuleSets() ([]RuleSet, []RuleSetGroup) {
+	ruleSets := []RuleSet{
+		{
+			CID:           "/rule_set/1",
+			Name:          "escalated",
+			Rules:         []RuleSetRule{{Severity: 1}, {Severity: 1}},
+			ContactGroups: map[uint8][]string{1: {"/contact_group/1"}},
+		},
+		{
+			CID:           "/rule_set/2",
+			Name:          "mixed",
+			Rules:         []RuleSetRule{{Severity: 2}, {Severity: 3}, {Severity: 4}},
+			ContactGroups: map[uint8][]string{2: {"/contact_group/3", "/contact_group/2"}, 3: {"/contact_group/3"}, 4: {"/contact_group/8"}},
+		},
+		{
+			CID:   "/rule_set/3",
+			Name:  "group member",
+			Rules: []RuleSetRule{{Severity: 1}},
+		},
+	}
+	groups := []RuleSetGroup{
+		{
+			CID:               "/rule_set_group/1",
+			Name:              "group",
+			Formulas:          []RuleSetGroupFormula{{Expression: "A", RaiseSeverity: 1}, {Expression: "A and B", RaiseSeverity: 5}},
+			RuleSetConditions: []RuleSetGroupCondition{{MatchingSeverities: []string{"1"}, RuleSetCID: "/rule_set/3"}},
+			ContactGroups:     map[uint8][]string{1: {"/contact_group/1"}},
+		},
+	}
+	return ruleSets, groups
+}
+
+func TestAuditNotificationPolicies(t *testing.T) {
+	ruleSets, groups := testNotificationRuleSets()
+	audit := AuditNotificationPolicies(ruleSets, groups, testNotificationContactGroups())
+
+	expected := []NotificationFinding{
+		{Kind: FindingInvalidContact, CID: "/contact_group/2", Name: "managers", Detail: "email contact, invalid address (managers)"},
+		{Kind: FindingInvalidContact, CID: "/contact_group/2", Name: "managers", Detail: "sms contact, invalid phone number (call me)"},
+		{Kind: FindingInvalidContact, CID: "/contact_group/2", Name: "managers", Detail: "http contact, invalid URL (hooks.example.com)"},
+		{Kind: FindingInvalidContact, CID: "/contact_group/2", Name: "managers", Detail: "pagerduty contact, endpoint (none)"},
+		{Kind: FindingInvalidContact, CID: "/contact_group/2", Name: "managers", Detail: "email contact, invalid user (1234)"},
+		{Kind: FindingMissingContactGroup, CID: "/contact_group/1", Name: "oncall", Severity: 2, Detail: "escalates to missing contact group /contact_group/9"},
+		{Kind: FindingMissingContactGroup, CID: "/rule_set/2", Name: "mixed", Severity: 4, Detail: "notifies missing contact group /contact_group/8"},
+		{Kind: FindingNoEscalation, CID: "/rule_set/2", Name: "mixed", Severity: 2, Detail: "severity 2 alerts are not escalated"},
+		{Kind: FindingSilent, CID: "/rule_set/2", Name: "mixed", Severity: 3, Detail: "severity 3 alerts notify nobody"},
+		{Kind: FindingSilent, CID: "/rule_set/2", Name: "mixed", Severity: 4, Detail: "severity 4 alerts notify nobody"},
+		{Kind: FindingSilent, CID: "/rule_set_group/1", Name: "group", Severity: 5, Detail: "severity 5 alerts notify nobody"},
+	}
+	if !reflect.DeepEqual(audit.Findings, expected) {
+		t.Fatalf("unexpected findings\n%#v\nexpected\n%#v", audit.Findings, expected)
+	}
+
+	// without the group notifying anyone, its member rule set is reported too
+	groups[0].ContactGroups = nil
+	audit = AuditNotificationPolicies(ruleSets, groups, testNotificationContactGroups())
+	silent := []string{}
+	for _, f := range audit.Findings {
+		if f.Kind == FindingSilent {
+			silent = append(silent, f.CID)
+		}
+	}
+	expectedSilent := []string{"/rule_set/2", "/rule_set/2", "/rule_set/3", "/rule_set_group/1", "/rule_set_group/1"}
+	if !reflect.DeepEqual(silent, expectedSilent) {
+		t.Fatalf("unexpected silent findings (%v)", silent)
+	}
+}
+
+func TestValidateContactEndpoint(t *testing.T) {
+	tests := []struct {
+		method string
+		info   string
+		valid  bool
+	}{
+		{"email", "ops@example.com", true},
+		{"email", "Ops <ops@example.com>", true},
+		{"email", "ops", false},
+		{"xmpp", "ops@chat.example.com", true},
+		{"sms", "+44 20 7946 0000", true},
+		{"sms", "555", false},
+		{"http", "http://example.com/hook", true},
+		{"http", "ftp://example.com/hook", false},
+		{"slack", "#ops", true},
+		{"slack", "https://hooks.slack.com/services/X", true},
+		{"slack", "ops", false},
+		{"pagerduty", "abc123", true},
+		{"pagerduty", " ", false},
+	}
+
+	for _, test := range tests {
+		err := validateContactEndpoint(test.method, test.info)
+		if test.valid && err != nil {
+			t.Errorf("%s %q: unexpected error (%s)", test.method, test.info, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s %q: expected error", test.method, test.info)
+		}
+	}
+}
+
+func TestAuditNotifications(t *testing.T) {
+	ruleSets, groups := testNotificationRuleSets()
+	fixtures := map[string]interface{}{}
+	for _, rs := range ruleSets {
+		fixtures[rs.CID] = rs
+	}
+	for _, g := range groups {
+		fixtures[g.CID] = g
+	}
+	for _, cg := range testNotificationContactGroups() {
+		fixtures[cg.CID] = cg
+	}
+
+	fake, err := fakecirconus.New(&fakecirconus.Config{Fixtures: fixtures})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: fake.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	audit, err := apih.AuditNotifications()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(audit.Findings) != 11 {
+		t.Fatalf("unexpected findings (%#v)", audit.Findings)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Notification preview - who an alert of a rule set would notify
+
+package apiclient
+
+import (
+	"fmt"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// NotificationTarget defines a contact method an alert would be sent to
+type NotificationTarget struct {
+	ContactGroupCID string
+	ContactGroup    string // name
+	Method          string // e.g. email, sms, slack
+	Info            string // endpoint (address, number, URL, or channel)
+	UserCID         string // for user contact methods
+	Escalated       bool   // reached by escalation, if the alert is not cleared
+	After           uint   // seconds from the alert to the escalation, 0 if not escalated
+}
+
+// NotificationPreview defines the contact groups and contact methods an
+// alert of a rule set would notify
+type NotificationPreview struct {
+	RuleSetCID    string
+	Severity      uint
+	ContactGroups []string             // notified, then escalated to, in order
+	Targets       []NotificationTarget // of the contact groups, in order
+	Problems      []string             // e.g. contact groups missing or without contacts
+}
+
+// PreviewNotifications resolves the contact groups, and their contact
+// methods, an alert of the rule set with the passed severity would notify,
+// following escalations - without raising an alert. Problems which would
+// keep the alert from reaching someone are reported in the preview, not as
+// errors.
+func (a *API) PreviewNotifications(ruleSetCID string, severity uint) (*NotificationPreview, error) {
+	if severity < 1 || severity > config.NumSeverityLevels {
+		return nil, errors.Errorf("invalid severity (%d), must be 1-%d", severity, config.NumSeverityLevels)
+	}
+
+	rs, err := a.FetchRuleSet(CIDType(&ruleSetCID))
+	if err != nil {
+		return nil, err
+	}
+	contactGroups, err := a.FetchContactGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	return PreviewRuleSetNotifications(rs, severity, *contactGroups), nil
+}
+
+// PreviewRuleSetNotifications resolves the contact groups an alert of the
+// rule set with the passed severity would notify, see PreviewNotifications.
+func PreviewRuleSetNotifications(rs *RuleSet, severity uint, contactGroups []ContactGroup) *NotificationPreview {
+	preview := &NotificationPreview{
+		RuleSetCID:    rs.CID,
+		Severity:      severity,
+		ContactGroups: []string{},
+		Targets:       []NotificationTarget{},
+		Problems:      []string{},
+	}
+
+	raised := false
+	for _, r := range rs.Rules {
+		if r.Severity == severity {
+			raised = true
+			break
+		}
+	}
+	if !raised {
+		preview.Problems = append(preview.Problems, fmt.Sprintf("no rule raises severity %d alerts", severity))
+	}
+
+	contacts := make(map[string]*ContactGroup, len(contactGroups))
+	for i := range contactGroups {
+		contacts[contactGroups[i].CID] = &contactGroups[i]
+	}
+
+	type pending struct {
+		groupCID  string
+		escalated bool
+		after     uint
+	}
+	groups := rs.ContactGroups[uint8(severity)]
+	if len(groups) == 0 {
+		preview.Problems = append(preview.Problems, fmt.Sprintf("severity %d alerts notify no contact groups", severity))
+	}
+	queue := make([]pending, 0, len(groups))
+	for _, groupCID := range groups {
+		queue = append(queue, pending{groupCID: groupCID})
+	}
+
+	// the groups notified, then those they escalate to
+	seen := map[string]bool{}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if seen[p.groupCID] {
+			continue
+		}
+		seen[p.groupCID] = true
+
+		cg := contacts[p.groupCID]
+		if cg == nil {
+			preview.Problems = append(preview.Problems, fmt.Sprintf("contact group %s does not exist", p.groupCID))
+			continue
+		}
+		preview.ContactGroups = append(preview.ContactGroups, cg.CID)
+		if len(cg.Contacts.External)+len(cg.Contacts.Users) == 0 {
+			preview.Problems = append(preview.Problems, fmt.Sprintf("contact group %s (%s) has no contacts", cg.CID, cg.Name))
+		}
+		target := NotificationTarget{ContactGroupCID: cg.CID, ContactGroup: cg.Name, Escalated: p.escalated, After: p.after}
+		for _, c := range cg.Contacts.External {
+			t := target
+			t.Method, t.Info = c.Method, c.Info
+			preview.Targets = append(preview.Targets, t)
+		}
+		for _, u := range cg.Contacts.Users {
+			t := target
+			t.Method, t.Info, t.UserCID = u.Method, u.Info, u.UserCID
+			preview.Targets = append(preview.Targets, t)
+		}
+
+		if int(severity) <= len(cg.Escalations) {
+			if e := cg.Escalations[severity-1]; e != nil && e.ContactGroupCID != "" {
+				queue = append(queue, pending{groupCID: e.ContactGroupCID, escalated: true, after: p.after + e.After})
+			}
+		}
+	}
+
+	return preview
+}
@@ -0,0 +1,124 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
+)
+
+func TestPreviewRuleSetNotifications(t *testing.T) {
+	contactGroups := []ContactGroup{
+		{CID: "/contact_group/1", Name: "oncall",
+			Contacts: ContactGroupContacts{
+				External: []ContactGroupContactsExternal{{Method: "sms", Info: "+15555550100"}},
+				Users:    []ContactGroupContactsUser{{Method: "email", Info: "ops@example.com", UserCID: "/user/1"}},
+			},
+			Escalations: []*ContactGroupEscalation{{After: 600, ContactGroupCID: "/contact_group/2"}, nil, nil, nil, nil},
+		},
+		{CID: "/contact_group/2", Name: "leads",
+			Contacts:    ContactGroupContacts{External: []ContactGroupContactsExternal{{Method: "slack", Info: "#leads"}}},
+			Escalations: []*ContactGroupEscalation{{After: 900, ContactGroupCID: "/contact_group/1"}, nil, nil, nil, nil},
+		},
+		{CID: "/contact_group/3", Name: "empty"},
+	}
+	rs := &RuleSet{
+		CID:   "/rule_set/1234_cpu",
+		Rules: []RuleSetRule{{Severity: 1}, {Severity: 2}, {Severity: 3}},
+		ContactGroups: map[uint8][]string{
+			1: {"/contact_group/1"},
+			2: {"/contact_group/2", "/contact_group/1"},
+			3: {"/contact_group/3", "/contact_group/9"},
+		},
+	}
+
+	tests := []struct {
+		id               string
+		severity         uint
+		expectedGroups   []string
+		expectedTargets  []string
+		expectedProblems []string
+	}{
+		{"escalated", 1,
+			[]string{"/contact_group/1", "/contact_group/2"},
+			[]string{"/contact_group/1 sms +15555550100 0", "/contact_group/1 email ops@example.com 0", "/contact_group/2 slack #leads 600 (escalated)"},
+			[]string{}},
+		{"not escalated", 2,
+			[]string{"/contact_group/2", "/contact_group/1"},
+			[]string{"/contact_group/2 slack #leads 0", "/contact_group/1 sms +15555550100 0", "/contact_group/1 email ops@example.com 0"},
+			[]string{}},
+		{"no contacts", 3,
+			[]string{"/contact_group/3"},
+			[]string{},
+			[]string{"contact group /contact_group/3 (empty) has no contacts", "contact group /contact_group/9 does not exist"}},
+		{"not raised", 4,
+			[]string{},
+			[]string{},
+			[]string{"no rule raises severity 4 alerts", "severity 4 alerts notify no contact groups"}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			preview := PreviewRuleSetNotifications(rs, test.severity, contactGroups)
+			if !reflect.DeepEqual(preview.ContactGroups, test.expectedGroups) {
+				t.Fatalf("unexpected contact groups (%v)", preview.ContactGroups)
+			}
+			targets := []string{}
+			for _, tgt := range preview.Targets {
+				s := fmt.Sprintf("%s %s %s %d", tgt.ContactGroupCID, tgt.Method, tgt.Info, tgt.After)
+				if tgt.Escalated {
+					s += " (escalated)"
+				}
+				targets = append(targets, s)
+			}
+			if !reflect.DeepEqual(targets, test.expectedTargets) {
+				t.Fatalf("unexpected targets (%v)", targets)
+			}
+			if !reflect.DeepEqual(preview.Problems, test.expectedProblems) {
+				t.Fatalf("unexpected problems (%v)", preview.Problems)
+			}
+		})
+	}
+}
+
+func TestPreviewNotifications(t *testing.T) {
+	fake, err := fakecirconus.New(&fakecirconus.Config{Fixtures: map[string]interface{}{
+		"/rule_set/1234_cpu": RuleSet{CID: "/rule_set/1234_cpu", Rules: []RuleSetRule{{Severity: 1}},
+			ContactGroups: map[uint8][]string{1: {"/contact_group/1"}}},
+		"/contact_group/1": ContactGroup{CID: "/contact_group/1", Name: "oncall",
+			Contacts: ContactGroupContacts{External: []ContactGroupContactsExternal{{Method: "email", Info: "oncall@example.com"}}}},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", URL: fake.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	preview, err := apih.PreviewNotifications("1234_cpu", 1)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(preview.Targets) != 1 || preview.Targets[0].Info != "oncall@example.com" || len(preview.Problems) != 0 {
+		t.Fatalf("unexpected preview (%+v)", preview)
+	}
+
+	if _, err := apih.PreviewNotifications("1234_cpu", 6); err == nil || !strings.Contains(err.Error(), "invalid severity (6)") {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+	for _, r := range fake.Requests() {
+		if r.Method != "GET" {
+			t.Fatalf("unexpected request (%s %s)", r.Method, r.URL)
+		}
+	}
+}
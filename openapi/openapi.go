@@ -0,0 +1,344 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package openapi describes the endpoints the client supports, and the
+// objects they send and return, as an OpenAPI 3 document generated from the
+// client's typed models - so non-Go consumers (and request validation
+// middleware) reuse the same definitions.
+//
+//	doc := openapi.Generate()
+//	data, _ := json.MarshalIndent(doc, "", "  ")
+//
+// The operations of an endpoint are those the client implements (e.g. an
+// endpoint with FetchGraph and UpdateGraph has GET and PUT operations on
+// /graph/{id}), each with the client method as its operationId. Schemas
+// follow the json encoding of the models, under components/schemas, and
+// are valid JSON Schema.
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/circonus-labs/go-apiclient/config"
+)
+
+// Version of the OpenAPI specification of generated documents
+const Version = "3.0.3"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers"`
+	Security   []map[string][]string `json:"security"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Server is an API URL
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem holds the operations of a path, keyed by lower case method
+type PathItem map[string]*Operation
+
+// Operation is an API call
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary"`
+	Tags        []string             `json:"tags"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter is a path or query parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is the object sent
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a response of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas of the models, by model name
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme is an API token header
+type SecurityScheme struct {
+	Type string `json:"type"`
+	In   string `json:"in"`
+	Name string `json:"name"`
+}
+
+// Schema describes a value
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// resource defines an endpoint, its operations are the client methods
+// named after it (e.g. FetchGraph, FetchGraphs, SearchGraphs, CreateGraph,
+// UpdateGraph, and DeleteGraph for Graph)
+type resource struct {
+	name   string      // method name stem
+	prefix string      // endpoint prefix
+	model  interface{} // object sent and returned
+}
+
+// resources supported by the client, by prefix
+var resources = []resource{
+	{"Account", config.AccountPrefix, apiclient.Account{}},
+	{"Acknowledgement", config.AcknowledgementPrefix, apiclient.Acknowledgement{}},
+	{"Alert", config.AlertPrefix, apiclient.Alert{}},
+	{"Annotation", config.AnnotationPrefix, apiclient.Annotation{}},
+	{"Broker", config.BrokerPrefix, apiclient.Broker{}},
+	{"Check", config.CheckPrefix, apiclient.Check{}},
+	{"CheckBundle", config.CheckBundlePrefix, apiclient.CheckBundle{}},
+	{"CheckBundleMetrics", config.CheckBundleMetricsPrefix, apiclient.CheckBundleMetrics{}},
+	{"CheckMove", config.CheckMovePrefix, apiclient.CheckMove{}},
+	{"ContactGroup", config.ContactGroupPrefix, apiclient.ContactGroup{}},
+	{"Dashboard", config.DashboardPrefix, apiclient.Dashboard{}},
+	{"Graph", config.GraphPrefix, apiclient.Graph{}},
+	{"MaintenanceWindow", config.MaintenancePrefix, apiclient.Maintenance{}},
+	{"Metric", config.MetricPrefix, apiclient.Metric{}},
+	{"MetricCluster", config.MetricClusterPrefix, apiclient.MetricCluster{}},
+	{"OutlierReport", config.OutlierReportPrefix, apiclient.OutlierReport{}},
+	{"ProvisionBroker", config.ProvisionBrokerPrefix, apiclient.ProvisionBroker{}},
+	{"RuleSet", config.RuleSetPrefix, apiclient.RuleSet{}},
+	{"RuleSetGroup", config.RuleSetGroupPrefix, apiclient.RuleSetGroup{}},
+	{"Tag", config.TagPrefix, apiclient.Tag{}},
+	{"CheckTemplate", config.CheckTemplatePrefix, apiclient.CheckTemplate{}},
+	{"User", config.UserPrefix, apiclient.User{}},
+	{"Worksheet", config.WorksheetPrefix, apiclient.Worksheet{}},
+}
+
+var (
+	apiType       = reflect.TypeOf(&apiclient.API{})
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textType      = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Generate returns the document of the endpoints the client supports
+func Generate() *Document {
+	g := &generator{schemas: make(map[string]*Schema)}
+	doc := &Document{
+		OpenAPI:  Version,
+		Info:     Info{Title: "Circonus API", Version: "v2"},
+		Servers:  []Server{{URL: "https://api.circonus.com/v2"}},
+		Security: []map[string][]string{{"token": {}, "app": {}}},
+		Paths:    make(map[string]PathItem),
+		Components: Components{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]*SecurityScheme{
+				"token": {Type: "apiKey", In: "header", Name: "X-Circonus-Auth-Token"},
+				"app":   {Type: "apiKey", In: "header", Name: "X-Circonus-App-Name"},
+			},
+		},
+	}
+
+	for _, res := range resources {
+		ref := g.schema(reflect.TypeOf(res.model))
+		list := &Schema{Type: "array", Items: ref}
+		tag := strings.TrimPrefix(res.prefix, "/")
+
+		collection := PathItem{}
+		if op, ok := operation(res.name+"s", "Fetch", "list", tag, nil, list); ok {
+			collection["get"] = op
+		}
+		if op, ok := operation(res.name+"s", "Search", "search", tag, nil, list); ok {
+			op.Parameters = []Parameter{
+				{Name: "search", In: "query", Description: "search query", Schema: &Schema{Type: "string"}},
+				{Name: "filters", In: "query", Description: "f_<field>=value filters", Schema: &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}},
+			}
+			if _, listed := collection["get"]; !listed {
+				collection["get"] = op
+			}
+		}
+		if op, ok := operation(res.name, "Create", "create", tag, ref, ref); ok {
+			collection["post"] = op
+		}
+		if len(collection) > 0 {
+			doc.Paths[res.prefix] = collection
+		}
+
+		object := PathItem{}
+		if op, ok := operation(res.name, "Fetch", "fetch", tag, nil, ref); ok {
+			object["get"] = op
+		}
+		if op, ok := operation(res.name, "Update", "update", tag, ref, ref); ok {
+			object["put"] = op
+		}
+		if op, ok := operation(res.name, "Delete", "delete", tag, nil, nil); ok {
+			object["delete"] = op
+		}
+		if len(object) > 0 {
+			for _, op := range object {
+				op.Parameters = append([]Parameter{{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string"}}}, op.Parameters...)
+			}
+			doc.Paths[res.prefix+"/{id}"] = object
+		}
+	}
+
+	return doc
+}
+
+// operation returns the operation of the client method verb+name, false if
+// the client has no such method
+func operation(name, verb, summary, tag string, body, result *Schema) (*Operation, bool) {
+	method := verb + name
+	if _, ok := apiType.MethodByName(method); !ok {
+		return nil, false
+	}
+	op := &Operation{
+		OperationID: method,
+		Summary:     summary + " " + strings.Replace(tag, "_", " ", -1),
+		Tags:        []string{tag},
+		Responses:   map[string]*Response{"default": {Description: "error"}},
+	}
+	if body != nil {
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{"application/json": {Schema: body}}}
+	}
+	ok := &Response{Description: "success"}
+	if result != nil {
+		ok.Content = map[string]MediaType{"application/json": {Schema: result}}
+	}
+	op.Responses["200"] = ok
+	return op, true
+}
+
+// generator builds the schemas of models, named struct types are added to
+// schemas and referenced
+type generator struct {
+	schemas map[string]*Schema
+}
+
+// schema returns the schema of a type, as encoded by encoding/json
+func (g *generator) schema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Ptr {
+		s := g.schema(t.Elem())
+		if s.Ref != "" {
+			return s // siblings of $ref are ignored
+		}
+		s.Nullable = true
+		return s
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType):
+		return &Schema{Description: "custom encoding"}
+	case t.Implements(textType) || reflect.PtrTo(t).Implements(textType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		if _, ok := g.schemas[t.Name()]; !ok {
+			g.schemas[t.Name()] = &Schema{} // placeholder, for recursive types
+			g.schemas[t.Name()] = g.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	}
+	return &Schema{} // interface{}, any value
+}
+
+// object returns the schema of a struct's json fields
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.fields(t, s.Properties)
+	return s
+}
+
+// fields adds the json fields of a struct to props, including those of
+// embedded structs
+func (g *generator) fields(t reflect.Type, props map[string]*Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, props)
+				continue
+			}
+		}
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schema(f.Type)
+	}
+}
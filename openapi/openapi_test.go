@@ -0,0 +1,106 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	doc := Generate()
+
+	tests := []struct {
+		path    string
+		methods []string
+		ops     []string
+	}{
+		{"/graph", []string{"get", "post"}, []string{"FetchGraphs", "CreateGraph"}},
+		{"/graph/{id}", []string{"delete", "get", "put"}, []string{"DeleteGraph", "FetchGraph", "UpdateGraph"}},
+		{"/maintenance", []string{"get", "post"}, []string{"FetchMaintenanceWindows", "CreateMaintenanceWindow"}},
+		{"/alert", []string{"get"}, []string{"FetchAlerts"}},
+		{"/alert/{id}", []string{"get"}, []string{"FetchAlert"}},
+		{"/check_bundle_metrics/{id}", []string{"get", "put"}, []string{"FetchCheckBundleMetrics", "UpdateCheckBundleMetrics"}},
+	}
+
+	for _, test := range tests {
+		item, ok := doc.Paths[test.path]
+		if !ok {
+			t.Errorf("%s: missing path", test.path)
+			continue
+		}
+		methods := make([]string, 0, len(item))
+		for m := range item {
+			methods = append(methods, m)
+		}
+		sort.Strings(methods)
+		if !reflect.DeepEqual(methods, test.methods) {
+			t.Errorf("%s: unexpected methods (%v)", test.path, methods)
+			continue
+		}
+		ops := make([]string, 0, len(test.ops))
+		for _, m := range []string{"delete", "get", "post", "put"} {
+			if op, ok := item[m]; ok {
+				ops = append(ops, op.OperationID)
+			}
+		}
+		sort.Strings(ops)
+		expected := append([]string(nil), test.ops...)
+		sort.Strings(expected)
+		if !reflect.DeepEqual(ops, expected) {
+			t.Errorf("%s: unexpected operations (%v)", test.path, ops)
+		}
+	}
+
+	if _, ok := doc.Paths["/check_bundle_metrics"]; ok {
+		t.Error("unexpected list of check bundle metrics")
+	}
+	if doc.Paths["/graph/{id}"]["get"].Parameters[0].Name != "id" {
+		t.Error("expected id path parameter")
+	}
+}
+
+func TestGenerateSchemas(t *testing.T) {
+	doc := Generate()
+
+	graph, ok := doc.Components.Schemas["Graph"]
+	if !ok {
+		t.Fatal("missing Graph schema")
+	}
+	tests := []struct {
+		field    string
+		expected Schema
+	}{
+		{"title", Schema{Type: "string"}},
+		{"tags", Schema{Type: "array", Items: &Schema{Type: "string"}}},
+		{"_cid", Schema{Type: "string"}},
+		{"style", Schema{Type: "string", Nullable: true}},
+		{"datapoints", Schema{Type: "array", Items: &Schema{Ref: "#/components/schemas/GraphDatapoint"}}},
+	}
+	for _, test := range tests {
+		s, ok := graph.Properties[test.field]
+		if !ok {
+			t.Errorf("%s: missing field", test.field)
+			continue
+		}
+		if !reflect.DeepEqual(*s, test.expected) {
+			t.Errorf("%s: unexpected schema (%#v)", test.field, s)
+		}
+	}
+	if _, ok := doc.Components.Schemas["GraphDatapoint"]; !ok {
+		t.Error("missing referenced GraphDatapoint schema")
+	}
+
+	bundle := doc.Components.Schemas["CheckBundle"]
+	if cfg := bundle.Properties["config"]; cfg.Type != "object" || cfg.AdditionalProperties == nil || cfg.AdditionalProperties.Type != "string" {
+		t.Errorf("unexpected check bundle config schema (%#v)", cfg)
+	}
+
+	if _, err := json.Marshal(doc); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+}
@@ -8,11 +8,10 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
-	"regexp"
 	"strings"
+	"time"
 
 	"github.com/circonus-labs/go-apiclient/config"
 	"github.com/pkg/errors"
@@ -38,6 +37,12 @@ func NewOutlierReport() *OutlierReport {
 
 // FetchOutlierReport retrieves outlier report with passed cid.
 func (a *API) FetchOutlierReport(cid CIDType) (*OutlierReport, error) {
+	return a.FetchOutlierReportWithContext(context.Background(), cid)
+}
+
+// FetchOutlierReportWithContext retrieves outlier report with passed cid,
+// stopping when ctx is done.
+func (a *API) FetchOutlierReportWithContext(ctx context.Context, cid CIDType) (*OutlierReport, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.New("invalid outlier report CID (none)")
 	}
@@ -49,25 +54,21 @@ func (a *API) FetchOutlierReport(cid CIDType) (*OutlierReport, error) {
 		reportCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.OutlierReportCIDRegex, reportCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.OutlierReportPrefix, reportCID) {
 		return nil, errors.Errorf("invalid outlier report CID (%s)", reportCID)
 	}
 
-	result, err := a.Get(reportCID)
+	result, err := a.GetWithContext(ctx, reportCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching outlier report")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch outlier report, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch outlier report, received JSON: %s", string(result))
 	}
 
 	report := &OutlierReport{}
-	if err := json.Unmarshal(result, report); err != nil {
+	if err := a.unmarshalJSON(result, report); err != nil {
 		return nil, errors.Wrap(err, "parsing outlier report")
 	}
 
@@ -76,14 +77,15 @@ func (a *API) FetchOutlierReport(cid CIDType) (*OutlierReport, error) {
 
 // FetchOutlierReports retrieves all outlier reports available to API Token.
 func (a *API) FetchOutlierReports() (*[]OutlierReport, error) {
-	result, err := a.Get(config.OutlierReportPrefix)
-	if err != nil {
-		return nil, errors.Wrap(err, "fetching outlier reports")
-	}
+	return a.FetchOutlierReportsWithContext(context.Background())
+}
 
+// FetchOutlierReportsWithContext retrieves all outlier reports available to
+// API Token, stopping when ctx is done.
+func (a *API) FetchOutlierReportsWithContext(ctx context.Context) (*[]OutlierReport, error) {
 	var reports []OutlierReport
-	if err := json.Unmarshal(result, &reports); err != nil {
-		return nil, errors.Wrap(err, "parsing outlier reports")
+	if err := a.getJSONContext(ctx, config.OutlierReportPrefix, &reports); err != nil {
+		return nil, errors.Wrap(err, "fetching outlier reports")
 	}
 
 	return &reports, nil
@@ -91,36 +93,38 @@ func (a *API) FetchOutlierReports() (*[]OutlierReport, error) {
 
 // UpdateOutlierReport updates passed outlier report.
 func (a *API) UpdateOutlierReport(cfg *OutlierReport) (*OutlierReport, error) {
+	return a.UpdateOutlierReportWithContext(context.Background(), cfg)
+}
+
+// UpdateOutlierReportWithContext updates passed outlier report, stopping when
+// ctx is done.
+func (a *API) UpdateOutlierReportWithContext(ctx context.Context, cfg *OutlierReport) (*OutlierReport, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid outlier report config (nil)")
 	}
 
 	reportCID := cfg.CID
 
-	matched, err := regexp.MatchString(config.OutlierReportCIDRegex, reportCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.OutlierReportPrefix, reportCID) {
 		return nil, errors.Errorf("invalid outlier report CID (%s)", reportCID)
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("update outlier report, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("update outlier report, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Put(reportCID, jsonCfg)
+	result, err := a.PutWithContext(ctx, reportCID, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "updating outlier report")
 	}
 
 	report := &OutlierReport{}
-	if err := json.Unmarshal(result, report); err != nil {
+	if err := a.unmarshalJSON(result, report); err != nil {
 		return nil, errors.Wrap(err, "parsing outlier report")
 	}
 
@@ -129,26 +133,32 @@ func (a *API) UpdateOutlierReport(cfg *OutlierReport) (*OutlierReport, error) {
 
 // CreateOutlierReport creates a new outlier report.
 func (a *API) CreateOutlierReport(cfg *OutlierReport) (*OutlierReport, error) {
+	return a.CreateOutlierReportWithContext(context.Background(), cfg)
+}
+
+// CreateOutlierReportWithContext creates a new outlier report, stopping when
+// ctx is done.
+func (a *API) CreateOutlierReportWithContext(ctx context.Context, cfg *OutlierReport) (*OutlierReport, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid outlier report config (nil)")
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("create outlier report, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("create outlier report, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Post(config.OutlierReportPrefix, jsonCfg)
+	result, err := a.PostWithContext(ctx, config.OutlierReportPrefix, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "creating outlier report")
 	}
 
 	report := &OutlierReport{}
-	if err := json.Unmarshal(result, report); err != nil {
+	if err := a.unmarshalJSON(result, report); err != nil {
 		return nil, errors.Wrap(err, "parsing outlier report")
 	}
 
@@ -157,14 +167,26 @@ func (a *API) CreateOutlierReport(cfg *OutlierReport) (*OutlierReport, error) {
 
 // DeleteOutlierReport deletes passed outlier report.
 func (a *API) DeleteOutlierReport(cfg *OutlierReport) (bool, error) {
+	return a.DeleteOutlierReportWithContext(context.Background(), cfg)
+}
+
+// DeleteOutlierReportWithContext deletes passed outlier report, stopping when
+// ctx is done.
+func (a *API) DeleteOutlierReportWithContext(ctx context.Context, cfg *OutlierReport) (bool, error) {
 	if cfg == nil {
 		return false, errors.New("invalid outlier report config (nil)")
 	}
-	return a.DeleteOutlierReportByCID(CIDType(&cfg.CID))
+	return a.DeleteOutlierReportByCIDWithContext(ctx, CIDType(&cfg.CID))
 }
 
 // DeleteOutlierReportByCID deletes outlier report with passed cid.
 func (a *API) DeleteOutlierReportByCID(cid CIDType) (bool, error) {
+	return a.DeleteOutlierReportByCIDWithContext(context.Background(), cid)
+}
+
+// DeleteOutlierReportByCIDWithContext deletes outlier report with passed cid,
+// stopping when ctx is done.
+func (a *API) DeleteOutlierReportByCIDWithContext(ctx context.Context, cid CIDType) (bool, error) {
 	if cid == nil || *cid == "" {
 		return false, errors.New("invalid outlier report CID (none)")
 	}
@@ -176,15 +198,11 @@ func (a *API) DeleteOutlierReportByCID(cid CIDType) (bool, error) {
 		reportCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.OutlierReportCIDRegex, reportCID)
-	if err != nil {
-		return false, err
-	}
-	if !matched {
+	if !validCID(config.OutlierReportPrefix, reportCID) {
 		return false, errors.Errorf("invalid outlier report CID (%s)", reportCID)
 	}
 
-	_, err = a.Delete(reportCID)
+	_, err := a.DeleteWithContext(ctx, reportCID)
 	if err != nil {
 		return false, errors.Wrap(err, "deleting outlier report")
 	}
@@ -196,38 +214,169 @@ func (a *API) DeleteOutlierReportByCID(cid CIDType) (bool, error) {
 // specified search query and/or filter. If nil is passed for
 // both parameters all outlier report will be returned.
 func (a *API) SearchOutlierReports(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]OutlierReport, error) {
-	q := url.Values{}
+	return a.SearchOutlierReportsWithContext(context.Background(), searchCriteria, filterCriteria)
+}
+
+// SearchOutlierReportsWithContext returns outlier report matching the
+// specified search query and/or filter, stopping when ctx is done.
+func (a *API) SearchOutlierReportsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]OutlierReport, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchOutlierReportsWithContext(ctx)
+	}
+
+	var reports []OutlierReport
+	if err := a.searchJSONContext(ctx, config.OutlierReportPrefix, searchCriteria, filterCriteria, &reports); err != nil {
+		return nil, errors.Wrap(err, "searching outlier reports")
+	}
+
+	return &reports, nil
+}
+
+// OutlierReportWindowFilter defines the criteria used by SearchOutlierReportsByWindow.
+// A zero Start or End leaves that side of the time range open.
+type OutlierReportWindowFilter struct {
+	MetricClusterCID string    // optional, only reports for this metric cluster
+	Start            time.Time // optional, beginning of time range
+	End              time.Time // optional, end of time range
+}
+
+// Overlaps reports whether the outlier report was in existence at any point
+// within the passed time range. A report is considered to span from its
+// creation until its last modification (or creation, if never modified).
+func (r *OutlierReport) Overlaps(start, end time.Time) bool {
+	if r == nil {
+		return false
+	}
+
+	from := r.Created
+	until := r.LastModified
+	if until < from {
+		until = from
+	}
 
-	if searchCriteria != nil && *searchCriteria != "" {
-		q.Set("search", string(*searchCriteria))
+	if !end.IsZero() && int64(from) > end.Unix() {
+		return false
 	}
+	if !start.IsZero() && int64(until) < start.Unix() {
+		return false
+	}
+
+	return true
+}
+
+// SearchOutlierReportsByWindow returns outlier reports which overlap the time
+// range in the passed filter, optionally restricted to a single metric cluster.
+func (a *API) SearchOutlierReportsByWindow(filter *OutlierReportWindowFilter) (*[]OutlierReport, error) {
+	return a.SearchOutlierReportsByWindowWithContext(context.Background(), filter)
+}
+
+// SearchOutlierReportsByWindowWithContext returns outlier reports which
+// overlap the time range in the passed filter, optionally restricted to a
+// single metric cluster, stopping when ctx is done.
+func (a *API) SearchOutlierReportsByWindowWithContext(ctx context.Context, filter *OutlierReportWindowFilter) (*[]OutlierReport, error) {
+	if filter == nil {
+		return nil, errors.New("invalid outlier report window filter (nil)")
+	}
+
+	if !filter.Start.IsZero() && !filter.End.IsZero() && filter.End.Before(filter.Start) {
+		return nil, errors.Errorf("invalid outlier report window (end %s before start %s)", filter.End, filter.Start)
+	}
+
+	var filterCriteria *SearchFilterType
+	var clusterCID string
+	if filter.MetricClusterCID != "" {
+		clusterCID = filter.MetricClusterCID
+		if !strings.HasPrefix(clusterCID, config.MetricClusterPrefix) {
+			clusterCID = fmt.Sprintf("%s/%s", config.MetricClusterPrefix, clusterCID)
+		}
 
-	if filterCriteria != nil && len(*filterCriteria) > 0 {
-		for filter, criteria := range *filterCriteria {
-			for _, val := range criteria {
-				q.Add(filter, val)
-			}
+		if !validCID(config.MetricClusterPrefix, clusterCID) {
+			return nil, errors.Errorf("invalid metric cluster CID (%s)", clusterCID)
 		}
+
+		filterCriteria = &SearchFilterType{"f_metric_cluster": []string{clusterCID}}
 	}
 
-	if q.Encode() == "" {
-		return a.FetchOutlierReports()
+	reports, err := a.SearchOutlierReportsWithContext(ctx, nil, filterCriteria)
+	if err != nil {
+		return nil, err
 	}
 
-	reqURL := url.URL{
-		Path:     config.OutlierReportPrefix,
-		RawQuery: q.Encode(),
+	matches := []OutlierReport{}
+	for _, report := range *reports {
+		report := report
+		if clusterCID != "" && report.MetricClusterCID != clusterCID {
+			continue
+		}
+		if !report.Overlaps(filter.Start, filter.End) {
+			continue
+		}
+		matches = append(matches, report)
 	}
 
-	result, err := a.Get(reqURL.String())
+	return &matches, nil
+}
+
+// OutlierReportRetention defines the options used by PurgeOutlierReports.
+type OutlierReportRetention struct {
+	MaxAge   time.Duration // reports not modified within MaxAge are deleted
+	DryRun   bool          // report what would be deleted, do not delete
+	Interval time.Duration // optional, delay between delete requests (rate limit)
+}
+
+// Age returns how long it has been since the outlier report was last modified
+// (or created, if it has never been modified).
+func (r *OutlierReport) Age() time.Duration {
+	if r == nil {
+		return 0
+	}
+	ts := r.LastModified
+	if ts < r.Created {
+		ts = r.Created
+	}
+	return time.Since(time.Unix(int64(ts), 0))
+}
+
+// PurgeOutlierReports deletes outlier reports older than the retention MaxAge.
+// Returns the reports deleted (or, with DryRun, the reports which would have
+// been deleted). On error, the reports deleted before the failure are returned
+// along with the error.
+func (a *API) PurgeOutlierReports(retention *OutlierReportRetention) (*[]OutlierReport, error) {
+	if retention == nil {
+		return nil, errors.New("invalid outlier report retention (nil)")
+	}
+	if retention.MaxAge <= 0 {
+		return nil, errors.Errorf("invalid outlier report retention max age (%s)", retention.MaxAge)
+	}
+
+	reports, err := a.FetchOutlierReports()
 	if err != nil {
-		return nil, errors.Wrap(err, "searching outlier reports")
+		return nil, err
 	}
 
-	var reports []OutlierReport
-	if err := json.Unmarshal(result, &reports); err != nil {
-		return nil, errors.Wrap(err, "parsing outlier reports")
+	purged := []OutlierReport{}
+	for _, report := range *reports {
+		report := report
+		if report.Age() < retention.MaxAge {
+			continue
+		}
+
+		if retention.DryRun {
+			a.apiLog().Printf("purge outlier reports, dry run, would delete %s (%s)", report.CID, report.Title)
+			purged = append(purged, report)
+			continue
+		}
+
+		if len(purged) > 0 && retention.Interval > 0 {
+			time.Sleep(retention.Interval)
+		}
+
+		if _, err := a.DeleteOutlierReport(&report); err != nil {
+			return &purged, errors.Wrapf(err, "purging outlier report %s", report.CID)
+		}
+
+		purged = append(purged, report)
 	}
 
-	return &reports, nil
+	return &purged, nil
 }
@@ -12,6 +12,7 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 var (
@@ -70,6 +71,8 @@ func testOutlierReportServer() *httptest.Server {
 					c = []OutlierReport{testOutlierReport}
 				case "/outlier_report?f_tags_has=service%3Aweb&search=requests+per+second":
 					c = []OutlierReport{testOutlierReport}
+				case "/outlier_report?f_metric_cluster=%2Fmetric_cluster%2F1234":
+					c = []OutlierReport{testOutlierReport}
 				case "/outlier_report":
 					c = []OutlierReport{testOutlierReport}
 				default:
@@ -376,3 +379,111 @@ func TestSearchOutlierReports(t *testing.T) {
 		})
 	}
 }
+
+func TestOutlierReportOverlaps(t *testing.T) {
+	created := time.Unix(int64(testOutlierReport.Created), 0)
+
+	tests := []struct {
+		id       string
+		start    time.Time
+		end      time.Time
+		expected bool
+	}{
+		{"open range", time.Time{}, time.Time{}, true},
+		{"contains", created.Add(-time.Hour), created.Add(time.Hour), true},
+		{"open start", time.Time{}, created, true},
+		{"open end", created, time.Time{}, true},
+		{"before", created.Add(-2 * time.Hour), created.Add(-time.Hour), false},
+		{"after", created.Add(time.Hour), created.Add(2 * time.Hour), false},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			if got := testOutlierReport.Overlaps(test.start, test.end); got != test.expected {
+				t.Fatalf("expected %t, got %t", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestSearchOutlierReportsByWindow(t *testing.T) {
+	apih, server := outlierReportTestBootstrap(t)
+	defer server.Close()
+
+	created := time.Unix(int64(testOutlierReport.Created), 0)
+
+	tests := []struct {
+		id          string
+		filter      *OutlierReportWindowFilter
+		expectedNum int
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (nil)", nil, 0, true, "invalid outlier report window filter (nil)"},
+		{"invalid (range)", &OutlierReportWindowFilter{Start: created, End: created.Add(-time.Hour)}, 0, true, fmt.Sprintf("invalid outlier report window (end %s before start %s)", created.Add(-time.Hour), created)},
+		{"window, no cluster", &OutlierReportWindowFilter{Start: created.Add(-time.Hour), End: created.Add(time.Hour)}, 1, false, ""},
+		{"window, short cluster cid", &OutlierReportWindowFilter{MetricClusterCID: "1234", Start: created.Add(-time.Hour)}, 1, false, ""},
+		{"window, long cluster cid", &OutlierReportWindowFilter{MetricClusterCID: "/metric_cluster/1234", End: created.Add(time.Hour)}, 1, false, ""},
+		{"window, no overlap", &OutlierReportWindowFilter{Start: created.Add(time.Hour)}, 0, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			reports, err := apih.SearchOutlierReportsByWindow(test.filter)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				} else if len(*reports) != test.expectedNum {
+					t.Fatalf("expected %d reports, got %d", test.expectedNum, len(*reports))
+				}
+			}
+		})
+	}
+}
+
+func TestPurgeOutlierReports(t *testing.T) {
+	apih, server := outlierReportTestBootstrap(t)
+	defer server.Close()
+
+	tests := []struct {
+		id          string
+		retention   *OutlierReportRetention
+		expectedNum int
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (nil)", nil, 0, true, "invalid outlier report retention (nil)"},
+		{"invalid (max age)", &OutlierReportRetention{}, 0, true, "invalid outlier report retention max age (0s)"},
+		{"dry run", &OutlierReportRetention{MaxAge: time.Hour, DryRun: true}, 1, false, ""},
+		{"delete", &OutlierReportRetention{MaxAge: time.Hour, Interval: time.Millisecond}, 1, false, ""},
+		{"none aged", &OutlierReportRetention{MaxAge: 100 * 365 * 24 * time.Hour}, 0, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			purged, err := apih.PurgeOutlierReports(test.retention)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				} else if len(*purged) != test.expectedNum {
+					t.Fatalf("expected %d reports, got %d", test.expectedNum, len(*purged))
+				}
+			}
+		})
+	}
+}
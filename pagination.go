@@ -0,0 +1,233 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Pagination - list and search objects a page (size objects, from an offset)
+// at a time
+
+package apiclient
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// DefaultPageSize is the number of objects requested per page when none is
+// configured
+const DefaultPageSize = 100
+
+// PageOptions defines a page of objects listed or searched
+type PageOptions struct {
+	// Size is the number of objects per page - default DefaultPageSize
+	Size uint
+
+	// From is the offset of the first object of the page
+	From uint
+}
+
+// size returns the number of objects per page
+func (p *PageOptions) size() uint {
+	if p == nil || p.Size == 0 {
+		return DefaultPageSize
+	}
+	return p.Size
+}
+
+// from returns the offset of the first object of the page
+func (p *PageOptions) from() uint {
+	if p == nil {
+		return 0
+	}
+	return p.From
+}
+
+// pagePath returns the request path of a page of the objects with the cid
+// prefix matching the search query and filter criteria
+func pagePath(prefix string, search *SearchQueryType, filter *SearchFilterType, size, from uint) string {
+	f := SearchFilterType{}
+	if filter != nil {
+		for k, v := range *filter {
+			f[k] = v
+		}
+	}
+	f["size"] = []string{strconv.FormatUint(uint64(size), 10)}
+	f["from"] = []string{strconv.FormatUint(uint64(from), 10)}
+	return searchPath(prefix, search, &f)
+}
+
+// FetchPage gets a page of the objects with the cid prefix (e.g.
+// config.AlertPrefix) matching the search query and filter criteria (both
+// may be nil), decoding them into v (a pointer to a slice).
+func (a *API) FetchPage(ctx context.Context, prefix string, search *SearchQueryType, filter *SearchFilterType, page *PageOptions, v interface{}) error {
+	if err := a.getJSONContext(ctx, pagePath(prefix, search, filter, page.size(), page.from()), v); err != nil {
+		return errors.Wrapf(err, "fetching page of %s", prefix)
+	}
+	return nil
+}
+
+// FetchAllPages gets the objects with the cid prefix matching the search
+// query and filter criteria a page at a time, starting from page.From, and
+// appends them to v (a pointer to a slice). Pages are fetched until one is
+// short, or has no objects not already fetched (objects created or deleted
+// meanwhile shift those of later pages).
+func (a *API) FetchAllPages(ctx context.Context, prefix string, search *SearchQueryType, filter *SearchFilterType, page *PageOptions, v interface{}) error {
+	out := reflect.ValueOf(v)
+	if out.Kind() != reflect.Ptr || out.Elem().Kind() != reflect.Slice {
+		return errors.Errorf("invalid page result (%T), must be a pointer to a slice", v)
+	}
+	out = out.Elem()
+
+	size, from := page.size(), page.from()
+	seen := map[string]bool{}
+	for {
+		part := reflect.New(out.Type())
+		if err := a.FetchPage(ctx, prefix, search, filter, &PageOptions{Size: size, From: from}, part.Interface()); err != nil {
+			return err
+		}
+		part = part.Elem()
+
+		added := 0
+		for i := 0; i < part.Len(); i++ {
+			item := part.Index(i)
+			if elem := reflect.Indirect(item); elem.Kind() == reflect.Struct {
+				if cid, ok := jsonField(elem, "_cid"); ok && cid.Kind() == reflect.String && cid.String() != "" {
+					if seen[cid.String()] {
+						continue
+					}
+					seen[cid.String()] = true
+				}
+			}
+			out.Set(reflect.Append(out, item))
+			added++
+		}
+
+		if uint(part.Len()) < size || added == 0 {
+			return nil
+		}
+		from += size
+	}
+}
+
+// SearchAlertsPage returns a page of the alerts matching the specified
+// search query and/or filter (both may be nil).
+func (a *API) SearchAlertsPage(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType, page *PageOptions) (*[]Alert, error) {
+	var alerts []Alert
+	if err := a.FetchPage(ctx, config.AlertPrefix, searchCriteria, filterCriteria, page, &alerts); err != nil {
+		return nil, err
+	}
+	return &alerts, nil
+}
+
+// FetchAllAlerts returns all alerts matching the specified search query
+// and/or filter (both may be nil), fetched a page of DefaultPageSize at a
+// time.
+func (a *API) FetchAllAlerts(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Alert, error) {
+	alerts := []Alert{}
+	if err := a.FetchAllPages(ctx, config.AlertPrefix, searchCriteria, filterCriteria, nil, &alerts); err != nil {
+		return nil, err
+	}
+	return &alerts, nil
+}
+
+// AlertsIterator iterates over the alerts matching a search, fetching them
+// a page at a time as they are iterated over:
+//
+//	it := apih.NewAlertsIterator(ctx, nil, nil, nil)
+//	for it.Next() {
+//		alert := it.Alert()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type AlertsIterator struct {
+	api    *API
+	ctx    context.Context
+	search *SearchQueryType
+	filter *SearchFilterType
+	size   uint
+	from   uint
+
+	page  []Alert
+	idx   int
+	alert *Alert
+	seen  map[string]bool
+	last  bool
+	err   error
+}
+
+// NewAlertsIterator returns an iterator over the alerts matching the
+// specified search query and/or filter (both may be nil), starting from
+// page.From.
+func (a *API) NewAlertsIterator(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType, page *PageOptions) *AlertsIterator {
+	return &AlertsIterator{
+		api:    a,
+		ctx:    ctx,
+		search: searchCriteria,
+		filter: filterCriteria,
+		size:   page.size(),
+		from:   page.from(),
+		seen:   map[string]bool{},
+	}
+}
+
+// Next advances to the next alert, fetching the next page when needed. It
+// returns false when there are no more alerts, or fetching failed (see Err).
+func (it *AlertsIterator) Next() bool {
+	for it.err == nil {
+		for it.idx < len(it.page) {
+			alert := &it.page[it.idx]
+			it.idx++
+			if alert.CID != "" {
+				if it.seen[alert.CID] {
+					continue
+				}
+				it.seen[alert.CID] = true
+			}
+			it.alert = alert
+			return true
+		}
+		if it.last {
+			break
+		}
+
+		alerts, err := it.api.SearchAlertsPage(it.ctx, it.search, it.filter, &PageOptions{Size: it.size, From: it.from})
+		if err != nil {
+			it.err = err
+			break
+		}
+		it.page, it.idx = *alerts, 0
+		it.from += it.size
+		if uint(len(it.page)) < it.size {
+			it.last = true
+		} else if !it.newAlerts() {
+			it.last = true // a page of alerts already iterated over
+		}
+	}
+	it.alert = nil
+	return false
+}
+
+// newAlerts returns true if the page has alerts not already iterated over
+func (it *AlertsIterator) newAlerts() bool {
+	for _, alert := range it.page {
+		if alert.CID == "" || !it.seen[alert.CID] {
+			return true
+		}
+	}
+	return false
+}
+
+// Alert returns the current alert, nil before Next is called or once it
+// returns false.
+func (it *AlertsIterator) Alert() *Alert {
+	return it.alert
+}
+
+// Err returns the error which stopped the iteration, nil if there were no
+// more alerts.
+func (it *AlertsIterator) Err() error {
+	return it.err
+}
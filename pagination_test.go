@@ -0,0 +1,194 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
+)
+
+func paginationBootstrap(t *testing.T, n int) (*API, *fakecirconus.Server) {
+	fixtures := map[string]interface{}{}
+	for i := 1; i <= n; i++ {
+		cid := fmt.Sprintf("/alert/%02d", i)
+		fixtures[cid] = Alert{CID: cid, Severity: uint(i%2 + 1)}
+	}
+	fake, err := fakecirconus.New(&fakecirconus.Config{Fixtures: fixtures})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	apih, err := New(&Config{TokenKey: "abc123", URL: fake.URL})
+	if err != nil {
+		fake.Close()
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	return apih, fake
+}
+
+func alertCIDs(alerts []Alert) []string {
+	cids := []string{}
+	for _, a := range alerts {
+		cids = append(cids, a.CID)
+	}
+	return cids
+}
+
+func TestPagePath(t *testing.T) {
+	search := SearchQueryType("web")
+	tests := []struct {
+		id       string
+		search   *SearchQueryType
+		filter   *SearchFilterType
+		page     *PageOptions
+		expected string
+	}{
+		{"default", nil, nil, nil, "/alert?from=0&size=100"},
+		{"page", nil, nil, &PageOptions{Size: 10, From: 20}, "/alert?from=20&size=10"},
+		{"search", &search, &SearchFilterType{"f_severity": {"1"}}, &PageOptions{Size: 5}, "/alert?f_severity=1&from=0&search=web&size=5"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			if path := pagePath(config.AlertPrefix, test.search, test.filter, test.page.size(), test.page.from()); path != test.expected {
+				t.Fatalf("unexpected path (%s)", path)
+			}
+		})
+	}
+}
+
+func TestSearchAlertsPage(t *testing.T) {
+	apih, fake := paginationBootstrap(t, 5)
+	defer fake.Close()
+
+	alerts, err := apih.SearchAlertsPage(context.Background(), nil, nil, &PageOptions{Size: 2, From: 2})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if cids := alertCIDs(*alerts); !reflect.DeepEqual(cids, []string{"/alert/03", "/alert/04"}) {
+		t.Fatalf("unexpected alerts (%v)", cids)
+	}
+}
+
+func TestFetchAllPages(t *testing.T) {
+	apih, fake := paginationBootstrap(t, 5)
+	defer fake.Close()
+
+	var alerts []Alert
+	filter := SearchFilterType{"f__severity": {"1"}}
+	if err := apih.FetchAllPages(context.Background(), config.AlertPrefix, nil, &filter, &PageOptions{Size: 1}, &alerts); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if cids := alertCIDs(alerts); !reflect.DeepEqual(cids, []string{"/alert/02", "/alert/04"}) {
+		t.Fatalf("unexpected alerts (%v)", cids)
+	}
+	if n := len(fake.Requests()); n != 3 {
+		t.Fatalf("unexpected requests (%d)", n)
+	}
+
+	if err := apih.FetchAllPages(context.Background(), config.AlertPrefix, nil, nil, nil, alerts); err == nil || !strings.Contains(err.Error(), "must be a pointer to a slice") {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+}
+
+func TestFetchAllPagesUnpaged(t *testing.T) {
+	// an endpoint ignoring size returns every object on each page
+	all := []Alert{{CID: "/alert/1"}, {CID: "/alert/2"}, {CID: "/alert/3"}}
+	fake, err := fakecirconus.New(&fakecirconus.Config{Fixtures: map[string]interface{}{
+		"/alert?from=0&size=2": all,
+		"/alert?from=2&size=2": all,
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+	apih, err := New(&Config{TokenKey: "abc123", URL: fake.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	var alerts []Alert
+	if err := apih.FetchAllPages(context.Background(), config.AlertPrefix, nil, nil, &PageOptions{Size: 2}, &alerts); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if cids := alertCIDs(alerts); !reflect.DeepEqual(cids, []string{"/alert/1", "/alert/2", "/alert/3"}) {
+		t.Fatalf("unexpected alerts (%v)", cids)
+	}
+}
+
+func TestFetchAllAlerts(t *testing.T) {
+	apih, fake := paginationBootstrap(t, 3)
+	defer fake.Close()
+
+	alerts, err := apih.FetchAllAlerts(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(*alerts) != 3 {
+		t.Fatalf("unexpected alerts (%v)", alertCIDs(*alerts))
+	}
+}
+
+func TestAlertsIterator(t *testing.T) {
+	apih, fake := paginationBootstrap(t, 5)
+	defer fake.Close()
+
+	tests := []struct {
+		id               string
+		page             *PageOptions
+		expected         []string
+		expectedRequests int
+	}{
+		{"default", nil, []string{"/alert/01", "/alert/02", "/alert/03", "/alert/04", "/alert/05"}, 1},
+		{"pages", &PageOptions{Size: 2}, []string{"/alert/01", "/alert/02", "/alert/03", "/alert/04", "/alert/05"}, 3},
+		{"exact pages", &PageOptions{Size: 5}, []string{"/alert/01", "/alert/02", "/alert/03", "/alert/04", "/alert/05"}, 2},
+		{"from", &PageOptions{Size: 2, From: 3}, []string{"/alert/04", "/alert/05"}, 2},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			before := len(fake.Requests())
+			it := apih.NewAlertsIterator(context.Background(), nil, nil, test.page)
+			if it.Alert() != nil {
+				t.Fatal("expected no alert before Next")
+			}
+			cids := []string{}
+			for it.Next() {
+				cids = append(cids, it.Alert().CID)
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if !reflect.DeepEqual(cids, test.expected) {
+				t.Fatalf("unexpected alerts (%v)", cids)
+			}
+			if n := len(fake.Requests()) - before; n != test.expectedRequests {
+				t.Fatalf("unexpected requests (%d)", n)
+			}
+			if it.Next() || it.Alert() != nil {
+				t.Fatal("expected iteration done")
+			}
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		it := apih.NewAlertsIterator(ctx, nil, nil, nil)
+		if it.Next() {
+			t.Fatal("expected no alerts")
+		}
+		if err := it.Err(); err == nil || !strings.Contains(err.Error(), "context canceled") {
+			t.Fatalf("unexpected error (%v)", err)
+		}
+	})
+}
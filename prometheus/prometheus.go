@@ -0,0 +1,400 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package prometheus converts stored data and CAQL results to Prometheus time
+// series, written in the text exposition format or as a remote write request.
+//
+//	result, _ := apih.CAQL(`find("duration")`, start, end, time.Minute)
+//	series, _ := prometheus.FromCAQL("", nil, result)
+//	req, _ := prometheus.NewRemoteWriteRequest("http://prometheus:9090/api/v1/write", series)
+//	resp, err := http.DefaultClient.Do(req)
+//
+// Circonus metric names are sanitized to Prometheus names and stream tags
+// (name|ST[key:value,...]) become labels. Histograms become classic
+// Prometheus histograms (_bucket, _sum, and _count series), with bucket
+// upper bounds from the log-linear bins. Note histogram counts are those of
+// each period, not cumulative over time.
+package prometheus
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/pkg/errors"
+)
+
+// Series types
+const (
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+// LabelName is the label holding the metric name in remote write requests
+const LabelName = "__name__"
+
+var (
+	invalidNameChars  = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+	invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+	streamTags        = regexp.MustCompile(`\|ST\[([^\]]*)\]`)
+	histogramSuffixes = []string{"_bucket", "_sum", "_count"}
+)
+
+// Label defines a label of a series
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample defines a value at a point in time
+type Sample struct {
+	Value     float64
+	Timestamp time.Time
+}
+
+// Series defines a Prometheus time series
+type Series struct {
+	Name    string  // metric name, e.g. duration or duration_bucket
+	Type    string  // TypeGauge, or TypeHistogram for the series of a histogram
+	Labels  []Label // ordered by name, without the metric name
+	Samples []Sample
+}
+
+// FromData returns the series of stored numeric data, samples are the values
+// of the periods with data. The name defaults to the metric of the data cid.
+func FromData(name string, labels map[string]string, d *apiclient.Data) (*Series, error) {
+	if d == nil {
+		return nil, errors.New("invalid data (nil)")
+	}
+	name, lbls, err := seriesName(name, dataMetric(d.CID), labels)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Series{Name: name, Type: TypeGauge, Labels: lbls}
+	for _, p := range d.Points {
+		if p.Value != nil {
+			s.Samples = append(s.Samples, Sample{Value: *p.Value, Timestamp: p.Timestamp})
+		}
+	}
+	return s, nil
+}
+
+// FromHistogramData returns the histogram series of stored histogram data.
+// The name defaults to the metric of the data cid.
+func FromHistogramData(name string, labels map[string]string, d *apiclient.HistogramData) ([]Series, error) {
+	if d == nil {
+		return nil, errors.New("invalid histogram data (nil)")
+	}
+	name, lbls, err := seriesName(name, dataMetric(d.CID), labels)
+	if err != nil {
+		return nil, err
+	}
+
+	hb := newHistogramBuilder(name, lbls)
+	for _, p := range d.Points {
+		if p.Histogram != nil {
+			hb.add(p.Histogram, p.Timestamp)
+		}
+	}
+	return hb.series(), nil
+}
+
+// FromCAQL returns the series of a CAQL result. Numeric output series become
+// gauges and histogram output series histograms, text output series are
+// skipped. The name of each series defaults to its label; when name is set
+// and there are several output series they are told apart by a series label
+// (the output series index).
+func FromCAQL(name string, labels map[string]string, r *apiclient.CAQLResult) ([]Series, error) {
+	if r == nil {
+		return nil, errors.New("invalid caql result (nil)")
+	}
+
+	var series []Series
+	for i, cs := range r.Series() {
+		lbls := labels
+		if name != "" && r.NumSeries() > 1 {
+			lbls = make(map[string]string, len(labels)+1)
+			for k, v := range labels {
+				lbls[k] = v
+			}
+			lbls["series"] = strconv.Itoa(i)
+		}
+		sname, sl, err := seriesName(name, cs.Label, lbls)
+		if err != nil {
+			return nil, errors.Wrapf(err, "caql series %d", i)
+		}
+
+		switch cs.Kind {
+		case "histogram":
+			hists, err := r.Histograms(i)
+			if err != nil {
+				return nil, err
+			}
+			hb := newHistogramBuilder(sname, sl)
+			for j, h := range hists {
+				if h != nil {
+					hb.add(h, r.Rows[j].Timestamp)
+				}
+			}
+			series = append(series, hb.series()...)
+		case "text":
+			continue
+		default:
+			s := Series{Name: sname, Type: TypeGauge, Labels: sl}
+			for _, p := range cs.Points {
+				if p.Value != nil {
+					s.Samples = append(s.Samples, Sample{Value: *p.Value, Timestamp: p.Timestamp})
+				}
+			}
+			series = append(series, s)
+		}
+	}
+
+	return series, nil
+}
+
+// WriteText writes the series in the Prometheus text exposition format, one
+// sample per series (the latest) with its timestamp. Series without samples
+// are skipped.
+func WriteText(w io.Writer, series []Series) error {
+	written := make(map[string]bool)
+	for _, s := range series {
+		if len(s.Samples) == 0 {
+			continue
+		}
+		family := s.family()
+		if !written[family] {
+			written[family] = true
+			if _, err := fmt.Fprintf(w, "# TYPE %s %s\n", family, s.typ()); err != nil {
+				return err
+			}
+		}
+
+		latest := s.Samples[0]
+		for _, smp := range s.Samples[1:] {
+			if smp.Timestamp.After(latest.Timestamp) {
+				latest = smp
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s%s %s %d\n", s.Name, textLabels(s.Labels), formatValue(latest.Value), timestampMillis(latest.Timestamp)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// family returns the metric family of a series, the name without the
+// histogram suffix for histogram series
+func (s *Series) family() string {
+	if s.Type == TypeHistogram {
+		for _, suffix := range histogramSuffixes {
+			if strings.HasSuffix(s.Name, suffix) {
+				return strings.TrimSuffix(s.Name, suffix)
+			}
+		}
+	}
+	return s.Name
+}
+
+// typ returns the type of a series, gauge if not set
+func (s *Series) typ() string {
+	if s.Type == "" {
+		return TypeGauge
+	}
+	return s.Type
+}
+
+// histogramBuilder accumulates the histograms of a series, to build its
+// bucket, sum, and count series
+type histogramBuilder struct {
+	name   string
+	labels []Label
+	hists  []*apiclient.Histogram
+	times  []time.Time
+}
+
+func newHistogramBuilder(name string, labels []Label) *histogramBuilder {
+	return &histogramBuilder{name: name, labels: labels}
+}
+
+// add adds the histogram at a point in time
+func (hb *histogramBuilder) add(h *apiclient.Histogram, ts time.Time) {
+	hb.hists = append(hb.hists, h)
+	hb.times = append(hb.times, ts)
+}
+
+// series returns the bucket series, by upper bound, then the sum and count
+// series. Every bucket has a sample for every histogram, so the buckets are
+// the union of the bins of all the histograms.
+func (hb *histogramBuilder) series() []Series {
+	if len(hb.hists) == 0 {
+		return nil
+	}
+
+	seen := make(map[float64]bool)
+	bounds := []float64{math.Inf(1)}
+	for _, h := range hb.hists {
+		for _, b := range h.Bins() {
+			if le := binUpper(b); !seen[le] {
+				seen[le] = true
+				bounds = append(bounds, le)
+			}
+		}
+	}
+	sort.Float64s(bounds)
+
+	series := make([]Series, len(bounds), len(bounds)+2)
+	for i, le := range bounds {
+		labels := append([]Label{{Name: "le", Value: formatValue(le)}}, hb.labels...)
+		sortLabels(labels)
+		series[i] = Series{Name: hb.name + "_bucket", Type: TypeHistogram, Labels: labels}
+	}
+	sum := Series{Name: hb.name + "_sum", Type: TypeHistogram, Labels: hb.labels}
+	count := Series{Name: hb.name + "_count", Type: TypeHistogram, Labels: hb.labels}
+
+	for i, h := range hb.hists {
+		ts := hb.times[i]
+		bins := h.Bins()
+		var cumulative uint64
+		next := 0
+		for j, le := range bounds {
+			for next < len(bins) && binUpper(bins[next]) <= le {
+				cumulative += h.BinCount(bins[next])
+				next++
+			}
+			series[j].Samples = append(series[j].Samples, Sample{Value: float64(cumulative), Timestamp: ts})
+		}
+		total := 0.0
+		if cumulative > 0 {
+			total = h.Mean() * float64(cumulative)
+		}
+		sum.Samples = append(sum.Samples, Sample{Value: total, Timestamp: ts})
+		count.Samples = append(count.Samples, Sample{Value: float64(cumulative), Timestamp: ts})
+	}
+
+	return append(series, sum, count)
+}
+
+// binUpper returns the upper bound of a histogram bin
+func binUpper(b apiclient.HistogramBin) float64 {
+	if b.Val <= 0 {
+		return b.Lower()
+	}
+	return b.Lower() + b.Width()
+}
+
+// seriesName returns the sanitized name of a series, and its labels. The
+// name defaults to the base name of the circonus metric, the stream tags of
+// which are added to the labels.
+func seriesName(name, metric string, labels map[string]string) (string, []Label, error) {
+	all := make(map[string]string, len(labels))
+	base := metric
+	if m := streamTags.FindStringSubmatchIndex(metric); m != nil {
+		base = metric[:m[0]]
+		for _, tag := range strings.Split(metric[m[2]:m[3]], ",") {
+			kv := strings.SplitN(tag, ":", 2)
+			if len(kv) != 2 || kv[0] == "" {
+				continue
+			}
+			all[labelName(decodeTag(kv[0]))] = decodeTag(kv[1])
+		}
+	}
+	for k, v := range labels {
+		all[labelName(k)] = v
+	}
+
+	if name == "" {
+		name = base
+	}
+	name = invalidNameChars.ReplaceAllString(name, "_")
+	if name == "" {
+		return "", nil, errors.New("invalid series name (none)")
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+
+	lbls := make([]Label, 0, len(all))
+	for k, v := range all {
+		lbls = append(lbls, Label{Name: k, Value: v})
+	}
+	sortLabels(lbls)
+	return name, lbls, nil
+}
+
+// decodeTag decodes a base64 encoded stream tag key or value, b"..."
+func decodeTag(s string) string {
+	if strings.HasPrefix(s, `b"`) && strings.HasSuffix(s, `"`) && len(s) > 2 {
+		if dec, err := base64.StdEncoding.DecodeString(s[2 : len(s)-1]); err == nil {
+			return string(dec)
+		}
+	}
+	return s
+}
+
+// labelName returns a valid label name
+func labelName(s string) string {
+	s = invalidLabelChars.ReplaceAllString(s, "_")
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		s = "_" + s
+	}
+	return s
+}
+
+// dataMetric returns the metric name of a data cid, /data/<check id>_<metric>
+func dataMetric(cid string) string {
+	id := cid[strings.LastIndex(cid, "/")+1:]
+	idx := strings.Index(id, "_")
+	if idx < 0 {
+		return ""
+	}
+	metric, err := url.PathUnescape(id[idx+1:])
+	if err != nil {
+		return id[idx+1:]
+	}
+	return metric
+}
+
+func sortLabels(labels []Label) {
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+}
+
+// textLabels returns the labels in exposition format, {name="value",...}
+func textLabels(labels []Label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	parts := make([]string, len(labels))
+	for i, l := range labels {
+		v := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(l.Value)
+		parts[i] = fmt.Sprintf(`%s="%s"`, l.Name, v)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// formatValue returns the exposition format of a value
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func timestampMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,222 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package prometheus
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+)
+
+func testCAQLResult(t *testing.T) *apiclient.CAQLResult {
+	var r apiclient.CAQLResult
+	err := json.Unmarshal([]byte(`{
+		"_query": "find(\"duration\")",
+		"_start": 1483033000,
+		"_end": 1483033120,
+		"_period": 60,
+		"_meta": [
+			{"kind": "numeric", "label": "duration|ST[env:prod,b\"c2VydmljZQ==\":b\"d2Vi\"]"},
+			{"kind": "histogram", "label": "latency"},
+			{"kind": "text", "label": "version"}
+		],
+		"_data": [
+			[1483033000, [1.5, {"H[1.0e+00]": 2, "H[2.0e+00]": 1}, "1.0"]],
+			[1483033060, [null, {"H[3.0e+00]": 4}, "1.1"]]
+		]
+	}`), &r)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	return &r
+}
+
+func TestFromCAQL(t *testing.T) {
+	series, err := FromCAQL("", map[string]string{"source": "circonus"}, testCAQLResult(t))
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	t0 := time.Unix(1483033000, 0).UTC()
+	t1 := time.Unix(1483033060, 0)
+	source := Label{Name: "source", Value: "circonus"}
+
+	if len(series) != 7 {
+		t.Fatalf("unexpected series (%#v)", series)
+	}
+
+	expectedGauge := Series{
+		Name:    "duration",
+		Type:    TypeGauge,
+		Labels:  []Label{{Name: "env", Value: "prod"}, {Name: "service", Value: "web"}, source},
+		Samples: []Sample{{Value: 1.5, Timestamp: t0}},
+	}
+	if !reflect.DeepEqual(series[0], expectedGauge) {
+		t.Fatalf("unexpected gauge (%#v)", series[0])
+	}
+
+	expectedBuckets := []struct {
+		le     string
+		values []float64
+	}{
+		{"1.1", []float64{2, 0}},
+		{"2.1", []float64{3, 0}},
+		{"3.1", []float64{3, 4}},
+		{"+Inf", []float64{3, 4}},
+	}
+	for i, e := range expectedBuckets {
+		s := series[i+1]
+		if s.Name != "latency_bucket" || s.Type != TypeHistogram || s.Labels[0] != (Label{Name: "le", Value: e.le}) {
+			t.Fatalf("unexpected bucket (%#v)", s)
+		}
+		if len(s.Samples) != 2 || s.Samples[0].Value != e.values[0] || s.Samples[1].Value != e.values[1] || !s.Samples[1].Timestamp.Equal(t1) {
+			t.Fatalf("unexpected bucket %s samples (%v)", e.le, s.Samples)
+		}
+	}
+	if series[5].Name != "latency_sum" || series[6].Name != "latency_count" || series[6].Samples[1].Value != 4 {
+		t.Fatalf("unexpected sum/count (%#v, %#v)", series[5], series[6])
+	}
+}
+
+func TestFromCAQLName(t *testing.T) {
+	series, err := FromCAQL("web:latency", nil, testCAQLResult(t))
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if series[0].Name != "web:latency" || series[0].Labels[0] != (Label{Name: "env", Value: "prod"}) || series[0].Labels[1] != (Label{Name: "series", Value: "0"}) {
+		t.Fatalf("unexpected series (%#v)", series[0])
+	}
+}
+
+func TestFromData(t *testing.T) {
+	v1, v2 := 10.0, 12.5
+	d := &apiclient.Data{
+		CID: "/data/1234_http%60200",
+		Points: []apiclient.DataPoint{
+			{Timestamp: time.Unix(1483033000, 0), DataValues: apiclient.DataValues{Value: &v1}},
+			{Timestamp: time.Unix(1483033060, 0)},
+			{Timestamp: time.Unix(1483033120, 0), DataValues: apiclient.DataValues{Value: &v2}},
+		},
+	}
+
+	s, err := FromData("", map[string]string{"check-id": "1234"}, d)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if s.Name != "http_200" || !reflect.DeepEqual(s.Labels, []Label{{Name: "check_id", Value: "1234"}}) || len(s.Samples) != 2 {
+		t.Fatalf("unexpected series (%#v)", s)
+	}
+
+	if _, err := FromData("", nil, &apiclient.Data{}); err == nil || err.Error() != "invalid series name (none)" {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	series, err := FromCAQL("", nil, testCAQLResult(t))
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteText(&buf, series); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	expected := `# TYPE duration gauge
+duration{env="prod",service="web"} 1.5 1483033000000
+# TYPE latency histogram
+latency_bucket{le="1.1"} 0 1483033060000
+latency_bucket{le="2.1"} 0 1483033060000
+latency_bucket{le="3.1"} 4 1483033060000
+latency_bucket{le="+Inf"} 4 1483033060000
+latency_sum 12.2 1483033060000
+latency_count 4 1483033060000
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output\n%s", buf.String())
+	}
+}
+
+// snappyDecode decodes the literal only snappy encoding written by snappyEncode
+func snappyDecode(t *testing.T, b []byte) []byte {
+	n, i := decodeVarint(b)
+	b = b[i:]
+	var out []byte
+	for len(b) > 0 {
+		tag := int(b[0] >> 2)
+		b = b[1:]
+		l := tag
+		switch tag {
+		case 60:
+			l = int(b[0])
+			b = b[1:]
+		case 61:
+			l = int(b[0]) | int(b[1])<<8
+			b = b[2:]
+		}
+		out = append(out, b[:l+1]...)
+		b = b[l+1:]
+	}
+	if uint64(len(out)) != n {
+		t.Fatalf("unexpected length (%d != %d)", len(out), n)
+	}
+	return out
+}
+
+func decodeVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i, c := range b {
+		v |= uint64(c&0x7f) << (7 * uint(i))
+		if c < 0x80 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
+
+func TestEncodeRemoteWrite(t *testing.T) {
+	series := []Series{
+		{Name: "up", Labels: []Label{{Name: "job", Value: "a"}}, Samples: []Sample{{Value: 1, Timestamp: time.Unix(1, 0)}}},
+		{Name: "empty"},
+	}
+
+	expected := []byte{
+		0x0a, 0x28, // timeseries, 40 bytes
+		0x0a, 0x0e, 0x0a, 0x08, '_', '_', 'n', 'a', 'm', 'e', '_', '_', 0x12, 0x02, 'u', 'p', // label __name__=up
+		0x0a, 0x08, 0x0a, 0x03, 'j', 'o', 'b', 0x12, 0x01, 'a', // label job=a
+		0x12, 0x0c, 0x09, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f, 0x10, 0xe8, 0x07, // sample 1 @ 1000ms
+	}
+	if got := marshalWriteRequest(series); !bytes.Equal(got, expected) {
+		t.Fatalf("unexpected write request (% x)", got)
+	}
+
+	if got := snappyDecode(t, EncodeRemoteWrite(series)); !bytes.Equal(got, expected) {
+		t.Fatalf("unexpected snappy decoded request (% x)", got)
+	}
+
+	long := bytes.Repeat([]byte("x"), 3*maxLiteral+100)
+	if got := snappyDecode(t, snappyEncode(long)); !bytes.Equal(got, long) {
+		t.Fatal("unexpected snappy round trip")
+	}
+}
+
+func TestNewRemoteWriteRequest(t *testing.T) {
+	if _, err := NewRemoteWriteRequest("", nil); err == nil || err.Error() != "invalid remote write URL (none)" {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+
+	req, err := NewRemoteWriteRequest("http://localhost:9090/api/v1/write", nil)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if req.Method != "POST" || req.Header.Get("Content-Encoding") != "snappy" || req.Header.Get("Content-Type") != RemoteWriteContentType {
+		t.Fatalf("unexpected request (%#v)", req)
+	}
+}
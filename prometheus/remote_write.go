@@ -0,0 +1,137 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package prometheus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Remote write request headers
+const (
+	RemoteWriteContentType     = "application/x-protobuf"
+	RemoteWriteContentEncoding = "snappy"
+	RemoteWriteVersion         = "0.1.0"
+)
+
+// protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+// maxLiteral is the longest snappy literal element written
+const maxLiteral = 1 << 16
+
+// EncodeRemoteWrite returns the body of a remote write request for the series:
+// a snappy compressed, protobuf encoded WriteRequest. Series without samples
+// are skipped. The snappy encoding stores the data as is (literals only),
+// which any snappy decoder reads.
+func EncodeRemoteWrite(series []Series) []byte {
+	return snappyEncode(marshalWriteRequest(series))
+}
+
+// NewRemoteWriteRequest returns a request posting the series to a remote
+// write endpoint (e.g. http://prometheus:9090/api/v1/write).
+func NewRemoteWriteRequest(url string, series []Series) (*http.Request, error) {
+	if url == "" {
+		return nil, errors.New("invalid remote write URL (none)")
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(EncodeRemoteWrite(series)))
+	if err != nil {
+		return nil, errors.Wrap(err, "creating remote write request")
+	}
+	req.Header.Set("Content-Type", RemoteWriteContentType)
+	req.Header.Set("Content-Encoding", RemoteWriteContentEncoding)
+	req.Header.Set("X-Prometheus-Remote-Write-Version", RemoteWriteVersion)
+	return req, nil
+}
+
+// marshalWriteRequest encodes the series as a prometheus.WriteRequest
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label { string name = 1; string value = 2; }
+//	message Sample { double value = 1; int64 timestamp = 2; }
+func marshalWriteRequest(series []Series) []byte {
+	var req []byte
+	for _, s := range series {
+		if len(s.Samples) == 0 {
+			continue
+		}
+
+		labels := append([]Label{{Name: LabelName, Value: s.Name}}, s.Labels...)
+		sortLabels(labels)
+
+		var ts []byte
+		for _, l := range labels {
+			var lb []byte
+			lb = appendBytes(lb, 1, []byte(l.Name))
+			lb = appendBytes(lb, 2, []byte(l.Value))
+			ts = appendBytes(ts, 1, lb)
+		}
+		for _, smp := range s.Samples {
+			var sb []byte
+			sb = appendTag(sb, 1, wireFixed64)
+			sb = appendFixed64(sb, math.Float64bits(smp.Value))
+			sb = appendTag(sb, 2, wireVarint)
+			sb = appendVarint(sb, uint64(timestampMillis(smp.Timestamp)))
+			ts = appendBytes(ts, 2, sb)
+		}
+		req = appendBytes(req, 1, ts)
+	}
+	return req
+}
+
+func appendTag(b []byte, field, wireType int) []byte {
+	return appendVarint(b, uint64(field<<3|wireType))
+}
+
+func appendVarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+func appendFixed64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+func appendBytes(b []byte, field int, v []byte) []byte {
+	b = appendTag(b, field, wireBytes)
+	b = appendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// snappyEncode returns data in the snappy block format, as literal elements:
+// the uncompressed length (varint) then, per literal, a tag byte holding the
+// length (or the number of length bytes which follow it) and the data
+func snappyEncode(data []byte) []byte {
+	out := appendVarint(make([]byte, 0, len(data)+len(data)/maxLiteral*4+16), uint64(len(data)))
+	for len(data) > 0 {
+		n := len(data)
+		if n > maxLiteral {
+			n = maxLiteral
+		}
+		switch l := n - 1; {
+		case l < 60:
+			out = append(out, byte(l<<2))
+		case l < 1<<8:
+			out = append(out, 60<<2, byte(l))
+		default:
+			out = append(out, 61<<2, byte(l), byte(l>>8))
+		}
+		out = append(out, data[:n]...)
+		data = data[n:]
+	}
+	return out
+}
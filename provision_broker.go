@@ -10,10 +10,13 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
-	"regexp"
+	"net"
 	"strings"
+	"time"
 
 	"github.com/circonus-labs/go-apiclient/config"
 	"github.com/pkg/errors"
@@ -26,6 +29,29 @@ type BrokerStratcon struct {
 	Port string `json:"port,omitempty"` // string
 }
 
+// Address returns the stratcon host and port in host:port form.
+func (s BrokerStratcon) Address() string {
+	return net.JoinHostPort(s.Host, s.Port)
+}
+
+// ProvisionBrokerCert defines the certificate material issued for a provisioned broker,
+// as needed to install the certificate on the broker host.
+type ProvisionBrokerCert struct {
+	CN          string            // broker common name
+	CertPEM     []byte            // PEM encoded broker certificate
+	Certificate *x509.Certificate // parsed broker certificate
+	CSRPEM      []byte            // PEM encoded certificate signing request, if any
+	Stratcons   []BrokerStratcon  // stratcons the broker should permit connections from
+}
+
+// ExpiresWithin reports whether the broker certificate expires within the passed duration.
+func (c *ProvisionBrokerCert) ExpiresWithin(d time.Duration) bool {
+	if c == nil || c.Certificate == nil {
+		return true
+	}
+	return time.Now().Add(d).After(c.Certificate.NotAfter)
+}
+
 // ProvisionBroker defines a provision broker [request]. See https://login.circonus.com/resources/api/calls/provision_broker for more details.
 type ProvisionBroker struct {
 	Cert                    string           `json:"_cert,omitempty"`                     // string
@@ -44,6 +70,83 @@ type ProvisionBroker struct {
 	Tags                    []string         `json:"tags,omitempty"`                      // [] len >= 0
 }
 
+// CN returns the broker common name embedded in the provision broker CID.
+func (pb *ProvisionBroker) CN() string {
+	if pb == nil {
+		return ""
+	}
+	return strings.TrimPrefix(pb.CID, config.ProvisionBrokerPrefix+"/")
+}
+
+// Certificate parses and returns the broker certificate issued by the provisioning request.
+func (pb *ProvisionBroker) Certificate() (*x509.Certificate, error) {
+	if pb == nil {
+		return nil, errors.New("invalid provision broker (nil)")
+	}
+	if pb.Cert == "" {
+		return nil, errors.New("provision broker has no certificate")
+	}
+
+	block, _ := pem.Decode([]byte(pb.Cert))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("decoding provision broker certificate (invalid PEM)")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing provision broker certificate")
+	}
+
+	return cert, nil
+}
+
+// CertificateRequest parses and returns the certificate signing request submitted for the broker.
+func (pb *ProvisionBroker) CertificateRequest() (*x509.CertificateRequest, error) {
+	if pb == nil {
+		return nil, errors.New("invalid provision broker (nil)")
+	}
+	if pb.CSR == "" {
+		return nil, errors.New("provision broker has no CSR")
+	}
+
+	block, _ := pem.Decode([]byte(pb.CSR))
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, errors.New("decoding provision broker CSR (invalid PEM)")
+	}
+
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing provision broker CSR")
+	}
+
+	return csr, nil
+}
+
+// CertMaterial returns the certificate material needed to install the provisioned broker.
+func (pb *ProvisionBroker) CertMaterial() (*ProvisionBrokerCert, error) {
+	cert, err := pb.Certificate()
+	if err != nil {
+		return nil, err
+	}
+
+	cn := pb.CN()
+	if cn == "" {
+		cn = cert.Subject.CommonName
+	}
+
+	material := &ProvisionBrokerCert{
+		CN:          cn,
+		CertPEM:     []byte(pb.Cert),
+		Certificate: cert,
+		Stratcons:   pb.Stratcons,
+	}
+	if pb.CSR != "" {
+		material.CSRPEM = []byte(pb.CSR)
+	}
+
+	return material, nil
+}
+
 // NewProvisionBroker returns a new ProvisionBroker (with defaults, if applicable)
 func NewProvisionBroker() *ProvisionBroker {
 	return &ProvisionBroker{}
@@ -51,6 +154,12 @@ func NewProvisionBroker() *ProvisionBroker {
 
 // FetchProvisionBroker retrieves provision broker [request] with passed cid.
 func (a *API) FetchProvisionBroker(cid CIDType) (*ProvisionBroker, error) {
+	return a.FetchProvisionBrokerWithContext(context.Background(), cid)
+}
+
+// FetchProvisionBrokerWithContext retrieves provision broker [request] with
+// passed cid, stopping when ctx is done.
+func (a *API) FetchProvisionBrokerWithContext(ctx context.Context, cid CIDType) (*ProvisionBroker, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.New("invalid provision broker CID (none)")
 	}
@@ -62,25 +171,21 @@ func (a *API) FetchProvisionBroker(cid CIDType) (*ProvisionBroker, error) {
 		brokerCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.ProvisionBrokerCIDRegex, brokerCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.ProvisionBrokerPrefix, brokerCID) {
 		return nil, errors.Errorf("invalid provision broker CID (%s)", brokerCID)
 	}
 
-	result, err := a.Get(brokerCID)
+	result, err := a.GetWithContext(ctx, brokerCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching provision broker")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch broker provision request, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch broker provision request, received JSON: %s", string(result))
 	}
 
 	broker := &ProvisionBroker{}
-	if err := json.Unmarshal(result, broker); err != nil {
+	if err := a.unmarshalJSON(result, broker); err != nil {
 		return nil, errors.Wrap(err, "parsing provision broker")
 	}
 
@@ -89,6 +194,12 @@ func (a *API) FetchProvisionBroker(cid CIDType) (*ProvisionBroker, error) {
 
 // UpdateProvisionBroker updates a broker definition [request].
 func (a *API) UpdateProvisionBroker(cid CIDType, cfg *ProvisionBroker) (*ProvisionBroker, error) {
+	return a.UpdateProvisionBrokerWithContext(context.Background(), cid, cfg)
+}
+
+// UpdateProvisionBrokerWithContext updates a broker definition [request],
+// stopping when ctx is done.
+func (a *API) UpdateProvisionBrokerWithContext(ctx context.Context, cid CIDType, cfg *ProvisionBroker) (*ProvisionBroker, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.New("invalid provision broker CID (none)")
 	}
@@ -99,30 +210,26 @@ func (a *API) UpdateProvisionBroker(cid CIDType, cfg *ProvisionBroker) (*Provisi
 
 	brokerCID := *cid
 
-	matched, err := regexp.MatchString(config.ProvisionBrokerCIDRegex, brokerCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.ProvisionBrokerPrefix, brokerCID) {
 		return nil, errors.Errorf("invalid provision broker CID (%s)", brokerCID)
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("update broker provision request, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("update broker provision request, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Put(brokerCID, jsonCfg)
+	result, err := a.PutWithContext(ctx, brokerCID, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "updating provision broker")
 	}
 
 	broker := &ProvisionBroker{}
-	if err := json.Unmarshal(result, broker); err != nil {
+	if err := a.unmarshalJSON(result, broker); err != nil {
 		return nil, errors.Wrap(err, "parsing provision broker")
 	}
 
@@ -131,28 +238,131 @@ func (a *API) UpdateProvisionBroker(cid CIDType, cfg *ProvisionBroker) (*Provisi
 
 // CreateProvisionBroker creates a new provison broker [request].
 func (a *API) CreateProvisionBroker(cfg *ProvisionBroker) (*ProvisionBroker, error) {
+	return a.CreateProvisionBrokerWithContext(context.Background(), cfg)
+}
+
+// CreateProvisionBrokerWithContext creates a new provison broker [request],
+// stopping when ctx is done.
+func (a *API) CreateProvisionBrokerWithContext(ctx context.Context, cfg *ProvisionBroker) (*ProvisionBroker, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid provision broker config (nil)")
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("create broker provision request, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("create broker provision request, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Post(config.ProvisionBrokerPrefix, jsonCfg)
+	result, err := a.PostWithContext(ctx, config.ProvisionBrokerPrefix, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "creating provision broker")
 	}
 
 	broker := &ProvisionBroker{}
-	if err := json.Unmarshal(result, broker); err != nil {
+	if err := a.unmarshalJSON(result, broker); err != nil {
 		return nil, errors.Wrap(err, "parsing provision broker")
 	}
 
 	return broker, nil
 }
+
+// RotateProvisionBrokerCert requests re-issuance of the certificate for the provisioned
+// broker with passed cid using the passed PEM encoded CSR, and returns the new material.
+func (a *API) RotateProvisionBrokerCert(cid CIDType, csr string) (*ProvisionBrokerCert, error) {
+	if csr == "" {
+		return nil, errors.New("invalid provision broker CSR (none)")
+	}
+
+	current, err := a.FetchProvisionBroker(cid)
+	if err != nil {
+		return nil, err
+	}
+
+	brokerCID := current.CID
+	if brokerCID == "" {
+		brokerCID = fmt.Sprintf("%s/%s", config.ProvisionBrokerPrefix, strings.TrimPrefix(*cid, config.ProvisionBrokerPrefix+"/"))
+	}
+
+	req := *current
+	req.CID = ""
+	req.Cert = ""
+	req.CSR = csr
+	req.Rebuild = true
+
+	if a.debugEnabled() {
+		a.apiLog().Printf("rotate provision broker certificate, requesting re-issue for %s", brokerCID)
+	}
+
+	rotated, err := a.UpdateProvisionBroker(CIDType(&brokerCID), &req)
+	if err != nil {
+		return nil, errors.Wrap(err, "rotating provision broker certificate")
+	}
+	if rotated.CID == "" {
+		rotated.CID = brokerCID
+	}
+
+	return rotated.CertMaterial()
+}
+
+// provisionBrokerPollInterval is the delay between broker status checks in ProvisionBrokerAndWait
+var provisionBrokerPollInterval = 5 * time.Second
+
+// ProvisionBrokerAndWait submits a provision broker request and waits, up to timeout,
+// for the resulting broker to report an active status. The provisioned broker is
+// returned once active. An error is returned if the broker is decommissioned while
+// waiting or does not become active before the timeout expires.
+func (a *API) ProvisionBrokerAndWait(cfg *ProvisionBroker, timeout time.Duration) (*Broker, error) {
+	if cfg == nil {
+		return nil, errors.New("invalid provision broker config (nil)")
+	}
+	if timeout <= 0 {
+		return nil, errors.Errorf("invalid provision broker timeout (%s)", timeout)
+	}
+
+	provisioned, err := a.CreateProvisionBroker(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	cn := provisioned.CN()
+	if cn == "" {
+		return nil, errors.New("invalid provision broker CID (none)")
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		brokers, err := a.FetchBrokers()
+		if err != nil {
+			return nil, errors.Wrap(err, "waiting for provisioned broker")
+		}
+
+		for _, broker := range *brokers {
+			broker := broker
+			for _, detail := range broker.Details {
+				if detail.CN != cn {
+					continue
+				}
+				switch detail.Status {
+				case "active":
+					return &broker, nil
+				case "decommissioned":
+					return nil, errors.Errorf("provisioned broker %s (%s) was decommissioned", broker.CID, cn)
+				}
+			}
+		}
+
+		if time.Now().Add(provisionBrokerPollInterval).After(deadline) {
+			return nil, errors.Errorf("timed out after %s waiting for provisioned broker (%s) to become active", timeout, cn)
+		}
+
+		if a.debugEnabled() {
+			a.apiLog().Printf("provision broker, waiting for broker (%s) to become active", cn)
+		}
+
+		time.Sleep(provisionBrokerPollInterval)
+	}
+}
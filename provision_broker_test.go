@@ -5,17 +5,26 @@
 package apiclient
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 var (
 	testProvisionBroker = ProvisionBroker{
+		CID:  "/provision_broker/abc-1234",
 		Cert: "...",
 		Stratcons: []BrokerStratcon{
 			{CN: "foobar", Host: "foobar.example.com", Port: "12345"},
@@ -34,6 +43,11 @@ var (
 	}
 )
 
+var (
+	numProvisionBrokerPolls        = 0
+	testProvisionBrokerRotatedCert = ""
+)
+
 func testProvisionBrokerServer() *httptest.Server {
 	f := func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
@@ -54,6 +68,17 @@ func testProvisionBrokerServer() *httptest.Server {
 				if err != nil {
 					panic(err)
 				}
+				var pb ProvisionBroker
+				if err := json.Unmarshal(b, &pb); err != nil {
+					panic(err)
+				}
+				if pb.Rebuild {
+					pb.Cert = testProvisionBrokerRotatedCert
+					b, err = json.Marshal(pb)
+					if err != nil {
+						panic(err)
+					}
+				}
 				w.WriteHeader(200)
 				w.Header().Set("Content-Type", "application/json")
 				fmt.Fprintln(w, string(b))
@@ -80,6 +105,31 @@ func testProvisionBrokerServer() *httptest.Server {
 				w.WriteHeader(404)
 				fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
 			}
+		case "/broker":
+			switch r.Method {
+			case "GET":
+				status := "active"
+				if numProvisionBrokerPolls < 1 {
+					status = "provisioned"
+				}
+				numProvisionBrokerPolls++
+				c := []Broker{
+					{
+						CID:     "/broker/1234",
+						Details: []BrokerDetail{{CN: "abc-1234", Status: status}},
+					},
+				}
+				ret, err := json.Marshal(c)
+				if err != nil {
+					panic(err)
+				}
+				w.WriteHeader(200)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintln(w, string(ret))
+			default:
+				w.WriteHeader(404)
+				fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
+			}
 		default:
 			w.WriteHeader(404)
 			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
@@ -225,3 +275,197 @@ func TestCreateProvisionBroker(t *testing.T) {
 		})
 	}
 }
+
+func TestProvisionBrokerAndWait(t *testing.T) {
+	apih, server := provisionBrokerTestBootstrap(t)
+	defer server.Close()
+
+	provisionBrokerPollInterval = 10 * time.Millisecond
+
+	tests := []struct {
+		id          string
+		cfg         *ProvisionBroker
+		timeout     time.Duration
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (nil)", nil, time.Second, true, "invalid provision broker config (nil)"},
+		{"invalid (timeout)", &testProvisionBroker, 0, true, "invalid provision broker timeout (0s)"},
+		{"valid", &testProvisionBroker, time.Second, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			broker, err := apih.ProvisionBrokerAndWait(test.cfg, test.timeout)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				} else if broker.CID != "/broker/1234" {
+					t.Fatalf("unexpected broker (%s)", broker.CID)
+				} else if numProvisionBrokerPolls < 2 {
+					t.Fatalf("expected at least 2 polls, got %d", numProvisionBrokerPolls)
+				}
+			}
+		})
+	}
+}
+
+func testProvisionBrokerCertPEM(t *testing.T, cn string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: cn}}, key)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr}))
+}
+
+func TestBrokerStratconAddress(t *testing.T) {
+	if addr := testProvisionBroker.Stratcons[0].Address(); addr != "foobar.example.com:12345" {
+		t.Fatalf("unexpected address (%s)", addr)
+	}
+}
+
+func TestProvisionBrokerCertMaterial(t *testing.T) {
+	certPEM, csrPEM := testProvisionBrokerCertPEM(t, "abc-1234")
+
+	tests := []struct {
+		id          string
+		cfg         *ProvisionBroker
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (nil)", nil, true, "invalid provision broker (nil)"},
+		{"invalid (no cert)", &ProvisionBroker{}, true, "provision broker has no certificate"},
+		{"invalid (pem)", &ProvisionBroker{Cert: "..."}, true, "decoding provision broker certificate (invalid PEM)"},
+		{"valid (cert only)", &ProvisionBroker{Cert: certPEM}, false, ""},
+		{"valid", &ProvisionBroker{CID: "/provision_broker/abc-1234", Cert: certPEM, CSR: csrPEM, Stratcons: testProvisionBroker.Stratcons}, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			material, err := test.cfg.CertMaterial()
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				} else if material.CN != "abc-1234" {
+					t.Fatalf("unexpected cn (%s)", material.CN)
+				} else if material.Certificate.Subject.CommonName != "abc-1234" {
+					t.Fatalf("unexpected certificate cn (%s)", material.Certificate.Subject.CommonName)
+				}
+			}
+		})
+	}
+}
+
+func TestProvisionBrokerCertificateRequest(t *testing.T) {
+	_, csrPEM := testProvisionBrokerCertPEM(t, "abc-1234")
+
+	tests := []struct {
+		id          string
+		cfg         *ProvisionBroker
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (no csr)", &ProvisionBroker{}, true, "provision broker has no CSR"},
+		{"invalid (pem)", &ProvisionBroker{CSR: "..."}, true, "decoding provision broker CSR (invalid PEM)"},
+		{"valid", &ProvisionBroker{CSR: csrPEM}, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			csr, err := test.cfg.CertificateRequest()
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				} else if csr.Subject.CommonName != "abc-1234" {
+					t.Fatalf("unexpected csr cn (%s)", csr.Subject.CommonName)
+				}
+			}
+		})
+	}
+}
+
+func TestRotateProvisionBrokerCert(t *testing.T) {
+	apih, server := provisionBrokerTestBootstrap(t)
+	defer server.Close()
+
+	var csrPEM string
+	testProvisionBrokerRotatedCert, csrPEM = testProvisionBrokerCertPEM(t, "abc-1234")
+
+	tests := []struct {
+		id          string
+		cid         string
+		csr         string
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (csr)", "abc-1234", "", true, "invalid provision broker CSR (none)"},
+		{"invalid (cid)", "", csrPEM, true, "invalid provision broker CID (none)"},
+		{"short cid", "abc-1234", csrPEM, false, ""},
+		{"long cid", "/provision_broker/abc-1234", csrPEM, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			material, err := apih.RotateProvisionBrokerCert(CIDType(&test.cid), test.csr)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				} else if material.CN != "abc-1234" {
+					t.Fatalf("unexpected cn (%s)", material.CN)
+				} else if string(material.CSRPEM) != csrPEM {
+					t.Fatal("expected csr to match request")
+				} else if material.ExpiresWithin(time.Minute) {
+					t.Fatal("expected certificate not to expire within a minute")
+				} else if !material.ExpiresWithin(2 * time.Hour) {
+					t.Fatal("expected certificate to expire within two hours")
+				}
+			}
+		})
+	}
+}
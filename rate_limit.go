@@ -0,0 +1,104 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Rate limit - throttle every request made to the API, adapting to the rate
+// limit the API reports
+
+package apiclient
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// rateLimitResetEpoch distinguishes X-RateLimit-Reset values which are a
+// time (unix seconds) from those which are the seconds until the reset
+const rateLimitResetEpoch = 1e9
+
+// RateLimit throttles the requests of the API and all its copies (see
+// Config RateLimit), on top of any EndpointLimits
+type RateLimit struct {
+	// RequestsPerSecond is the rate of requests, required
+	RequestsPerSecond float64
+
+	// Burst is the number of requests which can be made at once, before
+	// the rate limit applies - default 1
+	Burst int
+
+	// Adaptive slows requests to the rate the API allows, when lower, as
+	// reported by the X-RateLimit-Remaining and X-RateLimit-Reset headers
+	// of its responses: the requests remaining spread over the time until
+	// the reset, requests waiting for the reset once none remain.
+	Adaptive bool
+}
+
+// newRateLimiter returns the limiter for the rate limit, nil if there is
+// none
+func newRateLimiter(limit *RateLimit) (*endpointLimiter, error) {
+	if limit == nil {
+		return nil, nil
+	}
+	if limit.RequestsPerSecond <= 0 {
+		return nil, errors.Errorf("invalid rate limit (%v requests per second), must be greater than 0", limit.RequestsPerSecond)
+	}
+	if limit.Burst < 0 {
+		return nil, errors.Errorf("invalid rate limit burst (%d), must not be negative", limit.Burst)
+	}
+	l := &endpointLimiter{
+		rate:     limit.RequestsPerSecond,
+		maxRate:  limit.RequestsPerSecond,
+		burst:    float64(limit.Burst),
+		adaptive: limit.Adaptive,
+	}
+	if l.burst == 0 {
+		l.burst = 1
+	}
+	l.tokens = l.burst
+	return l, nil
+}
+
+// adapt sets the rate of the limiter to the rate the API allows, as
+// reported by the rate limit headers of the response, no higher than its
+// configured rate
+func (l *endpointLimiter) adapt(resp *http.Response) {
+	remaining, err := strconv.ParseFloat(resp.Header.Get("X-RateLimit-Remaining"), 64)
+	if err != nil || remaining < 0 {
+		return
+	}
+	now := time.Now()
+	reset := rateLimitReset(resp.Header.Get("X-RateLimit-Reset"), now)
+	if reset <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if remaining < 1 {
+		l.until = now.Add(reset)
+		l.tokens = 0
+		return
+	}
+	rate := remaining / reset.Seconds()
+	if rate > l.maxRate {
+		rate = l.maxRate
+	}
+	l.rate = rate
+}
+
+// rateLimitReset returns the time until the rate limit resets from an
+// X-RateLimit-Reset header, either the seconds until the reset or the time
+// of the reset (unix seconds), 0 if it is invalid or has passed
+func rateLimitReset(v string, now time.Time) time.Duration {
+	secs, err := strconv.ParseFloat(v, 64)
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	if secs >= rateLimitResetEpoch {
+		return time.Unix(int64(secs), 0).Sub(now)
+	}
+	return time.Duration(secs * float64(time.Second))
+}
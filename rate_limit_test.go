@@ -0,0 +1,195 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		fmt.Fprintln(w, `[]`)
+	}))
+	defer server.Close()
+
+	apih, err := New(&Config{
+		TokenKey:  "abc123",
+		TokenApp:  "test",
+		URL:       server.URL,
+		RateLimit: &RateLimit{RequestsPerSecond: 20, Burst: 2},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	acct, err := apih.WithAccount("/account/2")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	// the limit applies to every endpoint, and is shared with the account
+	// copy: 2 requests at once, then 4 more at 20/s
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		for _, api := range []*API{apih, acct} {
+			api := api
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := api.FetchGraphs(); err != nil {
+					t.Errorf("unexpected error (%s)", err)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Fatalf("expected requests throttled (%s)", elapsed)
+	}
+	if n := atomic.LoadInt32(&calls); n != 6 {
+		t.Fatalf("unexpected calls (%d)", n)
+	}
+
+	invalid := []struct {
+		limit       *RateLimit
+		expectedErr string
+	}{
+		{&RateLimit{}, "invalid rate limit (0 requests per second), must be greater than 0"},
+		{&RateLimit{RequestsPerSecond: 1, Burst: -1}, "invalid rate limit burst (-1), must not be negative"},
+	}
+	for _, test := range invalid {
+		_, err := New(&Config{TokenKey: "abc123", URL: "http://localhost", RateLimit: test.limit})
+		if err == nil || err.Error() != test.expectedErr {
+			t.Errorf("unexpected error (%v)", err)
+		}
+	}
+}
+
+func TestRateLimitAdaptive(t *testing.T) {
+	var remaining int32 = 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&remaining, -1)
+		if n < 0 {
+			n = 0
+		}
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(int(n)))
+		w.Header().Set("X-RateLimit-Reset", "0.2")
+		fmt.Fprintln(w, `[]`)
+	}))
+	defer server.Close()
+
+	apih, err := New(&Config{
+		TokenKey:  "abc123",
+		TokenApp:  "test",
+		URL:       server.URL,
+		RateLimit: &RateLimit{RequestsPerSecond: 1000, Burst: 10, Adaptive: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	// no requests remain after the first, the second waits for the reset
+	if _, err := apih.FetchGraphs(); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	start := time.Now()
+	if _, err := apih.FetchGraphs(); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("expected request held until the reset (%s)", elapsed)
+	}
+}
+
+// TestRateLimitAdaptiveConcurrent makes requests from many goroutines while
+// the rate adapts to their responses, run with -race to detect the rate read
+// without the limiter's lock
+func TestRateLimitAdaptiveConcurrent(t *testing.T) {
+	var remaining int32 = 1000
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(int(atomic.AddInt32(&remaining, -1))))
+		w.Header().Set("X-RateLimit-Reset", "2")
+		fmt.Fprintln(w, `[]`)
+	}))
+	defer server.Close()
+
+	apih, err := New(&Config{
+		TokenKey:  "abc123",
+		TokenApp:  "test",
+		URL:       server.URL,
+		RateLimit: &RateLimit{RequestsPerSecond: 1000, Burst: 4, Adaptive: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				if _, err := apih.FetchGraphs(); err != nil {
+					t.Errorf("unexpected error (%s)", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&remaining); n != 960 {
+		t.Fatalf("unexpected calls (%d)", 1000-n)
+	}
+}
+
+func TestRateLimiterAdapt(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		id           string
+		remaining    string
+		reset        string
+		expectedRate float64
+		paused       bool
+	}{
+		{"no headers", "", "", 10, false},
+		{"invalid", "x", "5", 10, false},
+		{"slower", "10", "5", 2, false},
+		{"capped", "1000", "5", 10, false},
+		{"epoch", "8", strconv.FormatInt(now.Add(8*time.Second).Unix(), 10), 1, false},
+		{"none remaining", "0", "5", 10, true},
+		{"reset passed", "0", strconv.FormatInt(now.Add(-time.Minute).Unix(), 10), 10, false},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			l, err := newRateLimiter(&RateLimit{RequestsPerSecond: 10, Adaptive: true})
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			resp := &http.Response{Header: http.Header{}}
+			if test.remaining != "" {
+				resp.Header.Set("X-RateLimit-Remaining", test.remaining)
+				resp.Header.Set("X-RateLimit-Reset", test.reset)
+			}
+			l.adapt(resp)
+			// the epoch reset is rounded to the second
+			if l.rate < test.expectedRate*0.7 || l.rate > test.expectedRate*1.3 {
+				t.Fatalf("unexpected rate (%v)", l.rate)
+			}
+			if paused := l.until.After(time.Now()); paused != test.paused {
+				t.Fatalf("unexpected paused (%t)", paused)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Redirect - which redirects of API calls are followed
+
+package apiclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultMaxRedirects is the number of redirects followed when no limit is
+// configured (as net/http)
+const DefaultMaxRedirects = 10
+
+// RedirectPolicy defines which redirects of API calls are followed
+type RedirectPolicy struct {
+	// Disable following redirects, the redirect response is returned as an
+	// error
+	Disable bool
+
+	// MaxRedirects limits the redirects followed by a call - default
+	// DefaultMaxRedirects
+	MaxRedirects int
+
+	// SendTokenCrossHost sends the API Token (the X-Circonus-Auth-Token
+	// header) on redirects to another host, or from https to http - default
+	// false, it is removed from those requests
+	SendTokenCrossHost bool
+}
+
+// redirectError is a redirect refused by the redirect policy
+type redirectError string
+
+func (e redirectError) Error() string {
+	return string(e)
+}
+
+// isRedirectError returns true if the error of a request is a redirect
+// refused by the redirect policy
+func isRedirectError(err error) bool {
+	if ue, ok := err.(*url.Error); ok {
+		_, ok = ue.Err.(redirectError)
+		return ok
+	}
+	return false
+}
+
+// check applies the policy to a redirect (see http.Client CheckRedirect),
+// via holds the requests made so far, oldest first
+func (p RedirectPolicy) check(req *http.Request, via []*http.Request) error {
+	if p.Disable {
+		return http.ErrUseLastResponse
+	}
+	max := p.MaxRedirects
+	if max <= 0 {
+		max = DefaultMaxRedirects
+	}
+	if len(via) > max {
+		return redirectError(fmt.Sprintf("stopped after %d redirects", max))
+	}
+	if !p.SendTokenCrossHost && len(via) > 0 {
+		orig := via[0].URL
+		if !strings.EqualFold(req.URL.Host, orig.Host) || (orig.Scheme == "https" && req.URL.Scheme != "https") {
+			req.Header.Del("X-Circonus-Auth-Token")
+		}
+	}
+	return nil
+}
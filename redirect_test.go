@@ -0,0 +1,108 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRedirectPolicy(t *testing.T) {
+	var mu sync.Mutex
+	tokens := map[string]string{}
+	requests := 0
+
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		tokens["other"+r.URL.Path] = r.Header.Get("X-Circonus-Auth-Token")
+		mu.Unlock()
+		fmt.Fprintln(w, `{}`)
+	}))
+	defer other.Close()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		tokens[r.URL.Path] = r.Header.Get("X-Circonus-Auth-Token")
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/cross":
+			http.Redirect(w, r, other.URL+"/moved", http.StatusFound)
+		case "/same":
+			http.Redirect(w, r, server.URL+"/moved", http.StatusFound)
+		case "/loop":
+			http.Redirect(w, r, server.URL+"/loop", http.StatusFound)
+		default:
+			fmt.Fprintln(w, `{}`)
+		}
+	}))
+	defer server.Close()
+
+	newAPI := func(p *RedirectPolicy) *API {
+		apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL, Redirects: p})
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		return apih
+	}
+
+	tests := []struct {
+		id          string
+		policy      *RedirectPolicy
+		path        string
+		expectedErr string
+		token       string // token received by the other host
+		requests    int
+	}{
+		{"cross host", nil, "/cross", "", "", 1},
+		{"cross host token", &RedirectPolicy{SendTokenCrossHost: true}, "/cross", "", "abc123", 1},
+		{"same host", nil, "/same", "", "", 2},
+		{"disabled", &RedirectPolicy{Disable: true}, "/same", "API response code 302", "", 1},
+		{"limit", &RedirectPolicy{MaxRedirects: 3}, "/loop", "stopped after 3 redirects", "", 4},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			mu.Lock()
+			tokens = map[string]string{}
+			requests = 0
+			mu.Unlock()
+
+			_, err := newAPI(test.policy).Get(test.path)
+			if test.expectedErr != "" {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if !strings.Contains(err.Error(), test.expectedErr) {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if tokens[test.path] != "abc123" {
+				t.Fatalf("token not sent to the API (%q)", tokens[test.path])
+			}
+			if test.path == "/same" && test.expectedErr == "" && tokens["/moved"] != "abc123" {
+				t.Fatalf("token not sent to the same host (%q)", tokens["/moved"])
+			}
+			if tokens["other/moved"] != test.token {
+				t.Fatalf("unexpected token sent to the other host (%q)", tokens["other/moved"])
+			}
+			// refused redirects are not retried
+			if requests != test.requests {
+				t.Fatalf("unexpected requests (%d)", requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Refresh - conditional re-fetching of objects which have changed
+
+package apiclient
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxObjectValidators limits the objects whose validators are kept
+const maxObjectValidators = 1024
+
+// objectValidator holds what identifies the version of an object last
+// fetched by RefreshIfChanged, from the response headers, and the instance
+// of the object which received it
+type objectValidator struct {
+	etag         string
+	lastModified string
+	owner        uintptr // the address of the object
+	ownerVersion uint64  // the _last_modified of the object, if it has one
+}
+
+// objectValidators holds the validators of the objects last fetched by
+// RefreshIfChanged, by cid, shared by copies of the API
+type objectValidators struct {
+	mu    sync.Mutex
+	byCID map[string]objectValidator
+}
+
+// get returns the validator of an object, only for the instance (with the
+// version) which received it
+func (vs *objectValidators) get(cid string, owner uintptr, version uint64) (objectValidator, bool) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+	val, ok := vs.byCID[cid]
+	if !ok || val.owner != owner || val.ownerVersion != version {
+		return objectValidator{}, false
+	}
+	return val, true
+}
+
+// track records the ETag and Last-Modified headers of a response with an
+// object, for the next RefreshIfChanged of the instance which received it
+func (vs *objectValidators) track(cid string, owner uintptr, version uint64, h http.Header) {
+	val := objectValidator{etag: h.Get("ETag"), lastModified: h.Get("Last-Modified"), owner: owner, ownerVersion: version}
+
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+	if val.etag == "" && val.lastModified == "" {
+		delete(vs.byCID, cid)
+		return
+	}
+	if vs.byCID == nil {
+		vs.byCID = make(map[string]objectValidator)
+	}
+	if _, ok := vs.byCID[cid]; !ok && len(vs.byCID) >= maxObjectValidators {
+		for k := range vs.byCID {
+			delete(vs.byCID, k)
+			break
+		}
+	}
+	vs.byCID[cid] = val
+}
+
+// RefreshIfChanged re-fetches the object (a pointer to a Circonus object,
+// e.g. *CheckBundle, with its CID set) only if it changed since it was last
+// fetched, updating it in place. It returns true if the object changed.
+//
+// The request is conditional: If-Modified-Since is sent with the object's
+// _last_modified time (when it has one), and If-None-Match and
+// If-Modified-Since with the ETag and Last-Modified headers of the previous
+// RefreshIfChanged of the same instance of the object (other copies of it are
+// not known to be current). The object is not transferred when the API
+// responds that it is not modified; when the API ignores the conditions and
+// returns the object, it is still only updated if its _last_modified time
+// changed (objects without one are always updated).
+func (a *API) RefreshIfChanged(obj interface{}) (bool, error) {
+	v := reflect.ValueOf(obj)
+	if !v.IsValid() || v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return false, errors.Errorf("invalid object (%T), must be a pointer to a struct", obj)
+	}
+	elem := v.Elem()
+
+	cidField, ok := jsonField(elem, "_cid")
+	if !ok || cidField.Kind() != reflect.String {
+		return false, errors.Errorf("invalid object (%T), no _cid", obj)
+	}
+	cid := cidField.String()
+	if cid == "" {
+		return false, errors.Errorf("invalid object (%T), cid (none)", obj)
+	}
+	var lastModified uint64
+	lmField, hasLastModified := jsonField(elem, "_last_modified")
+	if hasLastModified && lmField.Kind() == reflect.Uint {
+		lastModified = lmField.Uint()
+	} else {
+		hasLastModified = false
+	}
+
+	header := http.Header{}
+	prev, tracked := a.validators.get(cid, v.Pointer(), lastModified)
+	if tracked && prev.etag != "" {
+		header.Set("If-None-Match", prev.etag)
+	}
+	switch {
+	case tracked && prev.lastModified != "":
+		header.Set("If-Modified-Since", prev.lastModified)
+	case lastModified > 0:
+		header.Set("If-Modified-Since", time.Unix(int64(lastModified), 0).UTC().Format(http.TimeFormat))
+	}
+
+	fresh := reflect.New(elem.Type())
+	notModified := false
+	err := a.withBackoff(context.Background(), func(ctx context.Context) error {
+		return a.apiDo(ctx, "GET", cid, nil, header, func(resp *http.Response) error {
+			if resp.StatusCode == http.StatusNotModified {
+				notModified = true
+				return nil
+			}
+			if err := a.decodeJSON(resp.Body, fresh.Interface()); err != nil {
+				return err
+			}
+			var version uint64
+			if hasLastModified {
+				if freshLM, ok := jsonField(fresh.Elem(), "_last_modified"); ok {
+					version = freshLM.Uint()
+				}
+			}
+			a.validators.track(cid, v.Pointer(), version, resp.Header)
+			return nil
+		})
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "refreshing %s", cid)
+	}
+	if notModified {
+		return false, nil
+	}
+
+	if hasLastModified {
+		if freshLM, ok := jsonField(fresh.Elem(), "_last_modified"); ok && freshLM.Uint() == lastModified && lastModified > 0 {
+			return false, nil
+		}
+	}
+
+	elem.Set(fresh.Elem())
+	return true, nil
+}
+
+// jsonField returns the field of the struct with the passed json name,
+// including fields of embedded structs
+func jsonField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("json")
+		if idx := strings.Index(tag, ","); idx >= 0 {
+			tag = tag[:idx]
+		}
+		if tag == name {
+			return v.Field(i), true
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if fv, ok := jsonField(v.Field(i), name); ok {
+				return fv, true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
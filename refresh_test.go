@@ -0,0 +1,172 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestRefreshIfChanged(t *testing.T) {
+	var mu sync.Mutex
+	graphVersion := "1"
+	bundleModified := uint(1483228800)
+	var lastHeader http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		lastHeader = r.Header.Clone()
+		switch r.URL.Path {
+		case "/graph/1":
+			etag := `"v` + graphVersion + `"`
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", etag)
+			_ = json.NewEncoder(w).Encode(Graph{CID: "/graph/1", Title: "graph v" + graphVersion})
+		case "/check_bundle/1":
+			// conditions ignored, the bundle is always returned
+			_ = json.NewEncoder(w).Encode(CheckBundle{CID: "/check_bundle/1", DisplayName: "bundle", LastModified: bundleModified})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	t.Run("etag", func(t *testing.T) {
+		g := &Graph{CID: "/graph/1"}
+		for i, expected := range []bool{true, false} {
+			changed, err := apih.RefreshIfChanged(g)
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if changed != expected {
+				t.Fatalf("refresh %d, unexpected changed (%t)", i, changed)
+			}
+		}
+		if g.Title != "graph v1" {
+			t.Fatalf("unexpected title (%s)", g.Title)
+		}
+		if inm := lastHeader.Get("If-None-Match"); inm != `"v1"` {
+			t.Fatalf("unexpected If-None-Match (%s)", inm)
+		}
+
+		mu.Lock()
+		graphVersion = "2"
+		mu.Unlock()
+		changed, err := apih.RefreshIfChanged(g)
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if !changed || g.Title != "graph v2" {
+			t.Fatalf("unexpected refresh (%t, %s)", changed, g.Title)
+		}
+	})
+
+	t.Run("copies", func(t *testing.T) {
+		g := &Graph{CID: "/graph/1"}
+		if _, err := apih.RefreshIfChanged(g); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+
+		// a copy of the api shares the validators of the object
+		acct, err := apih.WithAccount("123")
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if changed, err := acct.RefreshIfChanged(g); err != nil || changed {
+			t.Fatalf("unexpected refresh (%t, %v)", changed, err)
+		}
+
+		// another instance of the object is not current
+		other := &Graph{CID: "/graph/1"}
+		changed, err := apih.RefreshIfChanged(other)
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if !changed || other.Title != g.Title {
+			t.Fatalf("unexpected refresh (%t, %s)", changed, other.Title)
+		}
+		if inm := lastHeader.Get("If-None-Match"); inm != "" {
+			t.Fatalf("unexpected If-None-Match (%s)", inm)
+		}
+	})
+
+	t.Run("last modified", func(t *testing.T) {
+		b := &CheckBundle{CID: "/check_bundle/1", DisplayName: "stale", LastModified: bundleModified}
+		changed, err := apih.RefreshIfChanged(b)
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if changed || b.DisplayName != "stale" {
+			t.Fatalf("unexpected refresh (%t, %s)", changed, b.DisplayName)
+		}
+		if ims := lastHeader.Get("If-Modified-Since"); ims != "Sun, 01 Jan 2017 00:00:00 GMT" {
+			t.Fatalf("unexpected If-Modified-Since (%s)", ims)
+		}
+
+		mu.Lock()
+		bundleModified += 60
+		mu.Unlock()
+		changed, err = apih.RefreshIfChanged(b)
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if !changed || b.DisplayName != "bundle" || b.LastModified != bundleModified {
+			t.Fatalf("unexpected refresh (%t, %#v)", changed, b)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			id          string
+			obj         interface{}
+			expectedErr string
+		}{
+			{"nil", nil, "invalid object (<nil>), must be a pointer to a struct"},
+			{"not pointer", Graph{}, "invalid object (apiclient.Graph), must be a pointer to a struct"},
+			{"no cid field", &struct{ Name string }{}, "invalid object (*struct { Name string }), no _cid"},
+			{"no cid", &Graph{}, "invalid object (*apiclient.Graph), cid (none)"},
+			{"not found", &Graph{CID: "/graph/2"}, "refreshing /graph/2: API response code 404: "},
+		}
+		for _, test := range tests {
+			_, err := apih.RefreshIfChanged(test.obj)
+			if err == nil {
+				t.Fatalf("%s: expected error", test.id)
+			}
+			if err.Error() != test.expectedErr {
+				t.Fatalf("%s: unexpected error (%s)", test.id, err)
+			}
+		}
+	})
+}
+
+func TestObjectValidatorsBound(t *testing.T) {
+	vs := &objectValidators{}
+	h := http.Header{"Etag": []string{`"v1"`}}
+	for i := 0; i < maxObjectValidators+10; i++ {
+		vs.track(fmt.Sprintf("/graph/%d", i), 1, 0, h)
+	}
+	if len(vs.byCID) != maxObjectValidators {
+		t.Fatalf("unexpected validators (%d)", len(vs.byCID))
+	}
+	if _, ok := vs.get(fmt.Sprintf("/graph/%d", maxObjectValidators+9), 1, 0); !ok {
+		t.Fatal("expected validator")
+	}
+	if _, ok := vs.get(fmt.Sprintf("/graph/%d", maxObjectValidators+9), 2, 0); ok {
+		t.Fatal("unexpected validator, other owner")
+	}
+}
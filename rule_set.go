@@ -8,11 +8,11 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/circonus-labs/go-apiclient/config"
 	"github.com/pkg/errors"
@@ -37,6 +37,7 @@ type RuleSet struct {
 	Derive        *string            `json:"derive,omitempty"`         // string or null
 	Filter        string             `json:"filter,omitempty"`         // string
 	Link          *string            `json:"link"`                     // string or null
+	LookupKey     *string            `json:"lookup_key,omitempty"`     // string or null (pattern rule sets)
 	MetricName    string             `json:"metric_name,omitempty"`    // string (name or pattern)
 	MetricPattern string             `json:"metric_pattern,omitempty"` // string (name or pattern)
 	MetricTags    []string           `json:"metric_tags"`              // [] len >= 0
@@ -53,8 +54,60 @@ func NewRuleSet() *RuleSet {
 	return &RuleSet{}
 }
 
+// IsPattern reports whether the rule set alerts on metrics selected by a
+// metric pattern and/or tag filter rather than a single named metric.
+func (rs *RuleSet) IsPattern() bool {
+	if rs == nil {
+		return false
+	}
+	return rs.MetricPattern != "" || rs.Filter != ""
+}
+
+// MatchesMetric reports whether the rule set applies to the metric with
+// passed name. Only the metric name or pattern is considered, the tag
+// filter (if any) is evaluated by the API.
+func (rs *RuleSet) MatchesMetric(metricName string) (bool, error) {
+	if rs == nil {
+		return false, errors.New("invalid rule set (nil)")
+	}
+	if rs.MetricPattern == "" {
+		return rs.MetricName == "" || rs.MetricName == metricName, nil
+	}
+	re, err := regexp.Compile(rs.MetricPattern)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid rule set metric pattern (%s)", rs.MetricPattern)
+	}
+	return re.MatchString(metricName), nil
+}
+
+// validatePattern checks the metric selection attributes of a pattern rule set
+func (rs *RuleSet) validatePattern() []string {
+	var problems []string
+	if rs.MetricPattern != "" {
+		if rs.MetricName != "" && rs.MetricName != rs.MetricPattern {
+			problems = append(problems, fmt.Sprintf("metric name %q and metric pattern %q are mutually exclusive", rs.MetricName, rs.MetricPattern))
+		}
+		if _, err := regexp.Compile(rs.MetricPattern); err != nil {
+			problems = append(problems, fmt.Sprintf("metric pattern %q: %s", rs.MetricPattern, err))
+		}
+	}
+	if rs.Filter != "" && rs.MetricPattern == "" && rs.MetricName == "" {
+		problems = append(problems, fmt.Sprintf("filter %q requires a metric pattern", rs.Filter))
+	}
+	if rs.MetricType == "" {
+		problems = append(problems, "metric type required for pattern rule sets")
+	}
+	return problems
+}
+
 // FetchRuleSet retrieves rule set with passed cid.
 func (a *API) FetchRuleSet(cid CIDType) (*RuleSet, error) {
+	return a.FetchRuleSetWithContext(context.Background(), cid)
+}
+
+// FetchRuleSetWithContext retrieves rule set with passed cid, stopping when
+// ctx is done.
+func (a *API) FetchRuleSetWithContext(ctx context.Context, cid CIDType) (*RuleSet, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.New("invalid rule set CID (none)")
 	}
@@ -66,25 +119,21 @@ func (a *API) FetchRuleSet(cid CIDType) (*RuleSet, error) {
 		rulesetCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.RuleSetCIDRegex, rulesetCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.RuleSetPrefix, rulesetCID) {
 		return nil, errors.Errorf("invalid rule set CID (%s)", rulesetCID)
 	}
 
-	result, err := a.Get(rulesetCID)
+	result, err := a.GetWithContext(ctx, rulesetCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching rule set")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch rule set, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch rule set, received JSON: %s", string(result))
 	}
 
 	ruleset := &RuleSet{}
-	if err := json.Unmarshal(result, ruleset); err != nil {
+	if err := a.unmarshalJSON(result, ruleset); err != nil {
 		return nil, errors.Wrap(err, "parsing rule set")
 	}
 
@@ -93,14 +142,15 @@ func (a *API) FetchRuleSet(cid CIDType) (*RuleSet, error) {
 
 // FetchRuleSets retrieves all rule sets available to API Token.
 func (a *API) FetchRuleSets() (*[]RuleSet, error) {
-	result, err := a.Get(config.RuleSetPrefix)
-	if err != nil {
-		return nil, errors.Wrap(err, "fetching rule sets")
-	}
+	return a.FetchRuleSetsWithContext(context.Background())
+}
 
+// FetchRuleSetsWithContext retrieves all rule sets available to API Token,
+// stopping when ctx is done.
+func (a *API) FetchRuleSetsWithContext(ctx context.Context) (*[]RuleSet, error) {
 	var rulesets []RuleSet
-	if err := json.Unmarshal(result, &rulesets); err != nil {
-		return nil, errors.Wrap(err, "parsing rule sets")
+	if err := a.getJSONContext(ctx, config.RuleSetPrefix, &rulesets); err != nil {
+		return nil, errors.Wrap(err, "fetching rule sets")
 	}
 
 	return &rulesets, nil
@@ -108,36 +158,37 @@ func (a *API) FetchRuleSets() (*[]RuleSet, error) {
 
 // UpdateRuleSet updates passed rule set.
 func (a *API) UpdateRuleSet(cfg *RuleSet) (*RuleSet, error) {
+	return a.UpdateRuleSetWithContext(context.Background(), cfg)
+}
+
+// UpdateRuleSetWithContext updates passed rule set, stopping when ctx is done.
+func (a *API) UpdateRuleSetWithContext(ctx context.Context, cfg *RuleSet) (*RuleSet, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid rule set config (nil)")
 	}
 
 	rulesetCID := cfg.CID
 
-	matched, err := regexp.MatchString(config.RuleSetCIDRegex, rulesetCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.RuleSetPrefix, rulesetCID) {
 		return nil, errors.Errorf("invalid rule set CID (%s)", rulesetCID)
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("update rule set, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("update rule set, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Put(rulesetCID, jsonCfg)
+	result, err := a.PutWithContext(ctx, rulesetCID, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "updating rule set")
 	}
 
 	ruleset := &RuleSet{}
-	if err := json.Unmarshal(result, ruleset); err != nil {
+	if err := a.unmarshalJSON(result, ruleset); err != nil {
 		return nil, errors.Wrap(err, "parsing rule set")
 	}
 
@@ -146,26 +197,31 @@ func (a *API) UpdateRuleSet(cfg *RuleSet) (*RuleSet, error) {
 
 // CreateRuleSet creates a new rule set.
 func (a *API) CreateRuleSet(cfg *RuleSet) (*RuleSet, error) {
+	return a.CreateRuleSetWithContext(context.Background(), cfg)
+}
+
+// CreateRuleSetWithContext creates a new rule set, stopping when ctx is done.
+func (a *API) CreateRuleSetWithContext(ctx context.Context, cfg *RuleSet) (*RuleSet, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid rule set config (nil)")
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("create rule set, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("create rule set, sending JSON: %s", jsonCfg.String())
 	}
 
-	resp, err := a.Post(config.RuleSetPrefix, jsonCfg)
+	resp, err := a.PostWithContext(ctx, config.RuleSetPrefix, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "creating rule set")
 	}
 
 	ruleset := &RuleSet{}
-	if err := json.Unmarshal(resp, ruleset); err != nil {
+	if err := a.unmarshalJSON(resp, ruleset); err != nil {
 		return nil, errors.Wrap(err, "parsing rule set")
 	}
 
@@ -174,14 +230,25 @@ func (a *API) CreateRuleSet(cfg *RuleSet) (*RuleSet, error) {
 
 // DeleteRuleSet deletes passed rule set.
 func (a *API) DeleteRuleSet(cfg *RuleSet) (bool, error) {
+	return a.DeleteRuleSetWithContext(context.Background(), cfg)
+}
+
+// DeleteRuleSetWithContext deletes passed rule set, stopping when ctx is done.
+func (a *API) DeleteRuleSetWithContext(ctx context.Context, cfg *RuleSet) (bool, error) {
 	if cfg == nil {
 		return false, errors.New("invalid rule set config (nil)")
 	}
-	return a.DeleteRuleSetByCID(CIDType(&cfg.CID))
+	return a.DeleteRuleSetByCIDWithContext(ctx, CIDType(&cfg.CID))
 }
 
 // DeleteRuleSetByCID deletes rule set with passed cid.
 func (a *API) DeleteRuleSetByCID(cid CIDType) (bool, error) {
+	return a.DeleteRuleSetByCIDWithContext(context.Background(), cid)
+}
+
+// DeleteRuleSetByCIDWithContext deletes rule set with passed cid, stopping
+// when ctx is done.
+func (a *API) DeleteRuleSetByCIDWithContext(ctx context.Context, cid CIDType) (bool, error) {
 	if cid == nil || *cid == "" {
 		return false, errors.New("invalid rule set CID (none)")
 	}
@@ -193,15 +260,11 @@ func (a *API) DeleteRuleSetByCID(cid CIDType) (bool, error) {
 		rulesetCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.RuleSetCIDRegex, rulesetCID)
-	if err != nil {
-		return false, err
-	}
-	if !matched {
+	if !validCID(config.RuleSetPrefix, rulesetCID) {
 		return false, errors.Errorf("invalid rule set CID (%s)", rulesetCID)
 	}
 
-	_, err = a.Delete(rulesetCID)
+	_, err := a.DeleteWithContext(ctx, rulesetCID)
 	if err != nil {
 		return false, errors.Wrap(err, "deleting rule set")
 	}
@@ -213,38 +276,323 @@ func (a *API) DeleteRuleSetByCID(cid CIDType) (bool, error) {
 // query and/or filter. If nil is passed for both parameters all
 // rule sets will be returned.
 func (a *API) SearchRuleSets(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]RuleSet, error) {
-	q := url.Values{}
+	return a.SearchRuleSetsWithContext(context.Background(), searchCriteria, filterCriteria)
+}
+
+// SearchRuleSetsWithContext returns rule sets matching the specified search
+// query and/or filter, stopping when ctx is done.
+func (a *API) SearchRuleSetsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]RuleSet, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchRuleSetsWithContext(ctx)
+	}
+
+	var rulesets []RuleSet
+	if err := a.searchJSONContext(ctx, config.RuleSetPrefix, searchCriteria, filterCriteria, &rulesets); err != nil {
+		return nil, errors.Wrap(err, "searching rule sets")
+	}
+
+	return &rulesets, nil
+}
+
+// RuleSetValidationError lists the problems found by ValidateRuleSet.
+type RuleSetValidationError struct {
+	CID      string   // rule set cid, if any
+	Problems []string // one entry per problem found
+}
+
+// Error returns the problems found, in order.
+func (e *RuleSetValidationError) Error() string {
+	name := e.CID
+	if name == "" {
+		name = "new rule set"
+	}
+	return fmt.Sprintf("invalid rule set (%s): %s", name, strings.Join(e.Problems, "; "))
+}
+
+// ValidateRuleSet checks the passed rule set against the account before it is
+// created or updated: the referenced check and metric (or, for pattern rule
+// sets, a valid metric pattern and metric type, and the check only without a
+// metric pattern) must exist, each rule's criteria must suit the metric type,
+// and every severity used by a rule must have at least one (existing) contact
+// group. Returns a *RuleSetValidationError listing every problem found, or
+// nil if the rule set is valid.
+func (a *API) ValidateRuleSet(cfg *RuleSet) error {
+	if cfg == nil {
+		return errors.New("invalid rule set config (nil)")
+	}
 
-	if searchCriteria != nil && *searchCriteria != "" {
-		q.Set("search", string(*searchCriteria))
+	verr := &RuleSetValidationError{CID: cfg.CID}
+
+	// metric pattern rule sets apply to the metrics of every check
+	var check *Check
+	if cfg.MetricPattern == "" {
+		checkCID := cfg.CheckCID
+		var err error
+		if check, err = a.FetchCheck(CIDType(&checkCID)); err != nil {
+			verr.Problems = append(verr.Problems, fmt.Sprintf("check %q: %s", cfg.CheckCID, err))
+		}
+	}
+
+	if cfg.IsPattern() {
+		verr.Problems = append(verr.Problems, cfg.validatePattern()...)
+	}
+
+	metricType := cfg.MetricType
+	if check != nil && cfg.MetricName != "" && !cfg.IsPattern() {
+		checkID := strings.TrimPrefix(check.CID, config.CheckPrefix+"/")
+		metricCID := fmt.Sprintf("%s/%s_%s", config.MetricPrefix, checkID, cfg.MetricName)
+		metric, err := a.FetchMetric(CIDType(&metricCID))
+		switch {
+		case err != nil:
+			verr.Problems = append(verr.Problems, fmt.Sprintf("metric %q: %s", cfg.MetricName, err))
+		case metricType != "" && metric.MetricType != "" && metric.MetricType != metricType:
+			verr.Problems = append(verr.Problems, fmt.Sprintf("metric %q type is %s, rule set specifies %s", cfg.MetricName, metric.MetricType, metricType))
+		case metric.MetricType != "":
+			metricType = metric.MetricType
+		}
 	}
 
-	if filterCriteria != nil && len(*filterCriteria) > 0 {
-		for filter, criteria := range *filterCriteria {
-			for _, val := range criteria {
-				q.Add(filter, val)
+	severities := make(map[uint8]bool)
+	for idx, rule := range cfg.Rules {
+		rule := rule
+		if err := rule.Validate(); err != nil {
+			verr.Problems = append(verr.Problems, fmt.Sprintf("rule %d: %s", idx, err))
+			continue
+		}
+		criteria := RuleSetCriteria(rule.Criteria)
+		switch metricType {
+		case "numeric", "histogram":
+			if !criteria.IsNumeric() {
+				verr.Problems = append(verr.Problems, fmt.Sprintf("rule %d: criteria %q not valid for %s metric", idx, criteria, metricType))
 			}
+		case "text":
+			if !criteria.IsText() {
+				verr.Problems = append(verr.Problems, fmt.Sprintf("rule %d: criteria %q not valid for %s metric", idx, criteria, metricType))
+			}
+		}
+		if rule.Severity > 0 {
+			severities[uint8(rule.Severity)] = true
 		}
 	}
 
-	if q.Encode() == "" {
-		return a.FetchRuleSets()
+	checked := make(map[string]bool)
+	for sev := uint8(1); sev <= MaxRuleSetSeverity; sev++ {
+		if !severities[sev] {
+			continue
+		}
+		groups := cfg.ContactGroups[sev]
+		if len(groups) == 0 {
+			verr.Problems = append(verr.Problems, fmt.Sprintf("severity %d: no contact groups", sev))
+			continue
+		}
+		for _, groupCID := range groups {
+			if checked[groupCID] {
+				continue
+			}
+			checked[groupCID] = true
+			groupCID := groupCID
+			if _, err := a.FetchContactGroup(CIDType(&groupCID)); err != nil {
+				verr.Problems = append(verr.Problems, fmt.Sprintf("severity %d: contact group %q: %s", sev, groupCID, err))
+			}
+		}
 	}
 
-	reqURL := url.URL{
-		Path:     config.RuleSetPrefix,
-		RawQuery: q.Encode(),
+	if len(verr.Problems) > 0 {
+		return verr
+	}
+
+	return nil
+}
+
+// CloneRuleSet creates a copy of the rule set with passed cid for another check
+// and metric, preserving its rules and contact groups. If newMetricName is empty,
+// the metric name (or pattern) of the source rule set is kept.
+func (a *API) CloneRuleSet(cid CIDType, newCheckCID, newMetricName string) (*RuleSet, error) {
+	if newCheckCID == "" {
+		return nil, errors.New("invalid rule set clone check CID (none)")
 	}
 
-	result, err := a.Get(reqURL.String())
+	src, err := a.FetchRuleSet(cid)
 	if err != nil {
-		return nil, errors.Wrap(err, "searching rule sets")
+		return nil, err
 	}
 
-	var rulesets []RuleSet
-	if err := json.Unmarshal(result, &rulesets); err != nil {
-		return nil, errors.Wrap(err, "parsing rule sets")
+	checkCID := newCheckCID
+	if !strings.HasPrefix(checkCID, config.CheckPrefix) {
+		checkCID = fmt.Sprintf("%s/%s", config.CheckPrefix, checkCID)
 	}
 
-	return &rulesets, nil
+	if !validCID(config.CheckPrefix, checkCID) {
+		return nil, errors.Errorf("invalid check CID (%s)", checkCID)
+	}
+
+	clone := *src
+	clone.CID = ""
+	clone.Host = ""
+	clone.CheckCID = checkCID
+	if newMetricName != "" {
+		clone.MetricName = newMetricName
+		clone.MetricPattern = ""
+	}
+
+	clone.Rules = append([]RuleSetRule{}, src.Rules...)
+	clone.Tags = append([]string{}, src.Tags...)
+	clone.MetricTags = append([]string{}, src.MetricTags...)
+	clone.ContactGroups = make(map[uint8][]string, len(src.ContactGroups))
+	for sev, groups := range src.ContactGroups {
+		clone.ContactGroups[sev] = append([]string{}, groups...)
+	}
+
+	rs, err := a.CreateRuleSet(&clone)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cloning rule set %s", src.CID)
+	}
+
+	return rs, nil
+}
+
+// RuleSetsNotifying returns the rule sets which notify the contact group with
+// passed cid, at any severity.
+func (a *API) RuleSetsNotifying(contactGroupCID CIDType) (*[]RuleSet, error) {
+	if contactGroupCID == nil || *contactGroupCID == "" {
+		return nil, errors.New("invalid contact group CID (none)")
+	}
+
+	groupCID := *contactGroupCID
+	if !strings.HasPrefix(groupCID, config.ContactGroupPrefix) {
+		groupCID = fmt.Sprintf("%s/%s", config.ContactGroupPrefix, groupCID)
+	}
+
+	rulesets, err := a.FetchRuleSets()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []RuleSet{}
+	for _, rs := range *rulesets {
+		found := false
+		for _, groups := range rs.ContactGroups {
+			for _, cid := range groups {
+				if cid == groupCID {
+					found = true
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+		if found {
+			matches = append(matches, rs)
+		}
+	}
+
+	return &matches, nil
+}
+
+// RuleSetsWithSeverity returns the rule sets with at least one rule raising
+// alerts of the passed severity.
+func (a *API) RuleSetsWithSeverity(severity uint) (*[]RuleSet, error) {
+	if severity < 1 || severity > MaxRuleSetSeverity {
+		return nil, errors.Errorf("invalid rule set severity (%d)", severity)
+	}
+
+	rulesets, err := a.FetchRuleSets()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []RuleSet{}
+	for _, rs := range *rulesets {
+		for _, rule := range rs.Rules {
+			if rule.Severity == severity {
+				matches = append(matches, rs)
+				break
+			}
+		}
+	}
+
+	return &matches, nil
+}
+
+// RuleSetMute records the notification state of a rule set prior to MuteRuleSet,
+// for use with UnmuteRuleSet.
+type RuleSetMute struct {
+	CID           string             // rule set cid
+	ContactGroups map[uint8][]string // contact groups prior to muting
+	MutedOn       time.Time          // when the rule set was muted
+}
+
+// MuteRuleSet disables notifications for the rule set with passed cid by removing
+// its contact groups. The previous contact groups are returned so they can be
+// restored with UnmuteRuleSet.
+func (a *API) MuteRuleSet(cid CIDType) (*RuleSetMute, error) {
+	rs, err := a.FetchRuleSet(cid)
+	if err != nil {
+		return nil, err
+	}
+
+	mute := &RuleSetMute{
+		CID:           rs.CID,
+		ContactGroups: make(map[uint8][]string),
+		MutedOn:       time.Now(),
+	}
+	for sev, groups := range rs.ContactGroups {
+		if len(groups) > 0 {
+			mute.ContactGroups[sev] = append([]string{}, groups...)
+		}
+	}
+	if len(mute.ContactGroups) == 0 {
+		return nil, errors.Errorf("rule set %s has no contact groups (already muted?)", rs.CID)
+	}
+
+	rs.ContactGroups = make(map[uint8][]string, config.NumSeverityLevels)
+	for sev := uint8(1); sev <= config.NumSeverityLevels; sev++ {
+		rs.ContactGroups[sev] = []string{}
+	}
+
+	if _, err := a.UpdateRuleSet(rs); err != nil {
+		return nil, errors.Wrapf(err, "muting rule set %s", rs.CID)
+	}
+
+	return mute, nil
+}
+
+// UnmuteRuleSet restores the contact groups recorded by MuteRuleSet. Any contact
+// groups added to the rule set while muted are kept.
+func (a *API) UnmuteRuleSet(mute *RuleSetMute) (*RuleSet, error) {
+	if mute == nil {
+		return nil, errors.New("invalid rule set mute (nil)")
+	}
+
+	cid := mute.CID
+	rs, err := a.FetchRuleSet(CIDType(&cid))
+	if err != nil {
+		return nil, err
+	}
+
+	if rs.ContactGroups == nil {
+		rs.ContactGroups = make(map[uint8][]string, config.NumSeverityLevels)
+	}
+	for sev, groups := range mute.ContactGroups {
+		for _, group := range groups {
+			found := false
+			for _, existing := range rs.ContactGroups[sev] {
+				if existing == group {
+					found = true
+					break
+				}
+			}
+			if !found {
+				rs.ContactGroups[sev] = append(rs.ContactGroups[sev], group)
+			}
+		}
+	}
+
+	updated, err := a.UpdateRuleSet(rs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmuting rule set %s", rs.CID)
+	}
+
+	return updated, nil
 }
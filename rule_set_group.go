@@ -8,10 +8,9 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
-	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/circonus-labs/go-apiclient/config"
@@ -48,6 +47,12 @@ func NewRuleSetGroup() *RuleSetGroup {
 
 // FetchRuleSetGroup retrieves rule set group with passed cid.
 func (a *API) FetchRuleSetGroup(cid CIDType) (*RuleSetGroup, error) {
+	return a.FetchRuleSetGroupWithContext(context.Background(), cid)
+}
+
+// FetchRuleSetGroupWithContext retrieves rule set group with passed cid,
+// stopping when ctx is done.
+func (a *API) FetchRuleSetGroupWithContext(ctx context.Context, cid CIDType) (*RuleSetGroup, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.New("invalid rule set group CID (none)")
 	}
@@ -59,25 +64,21 @@ func (a *API) FetchRuleSetGroup(cid CIDType) (*RuleSetGroup, error) {
 		groupCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.RuleSetGroupCIDRegex, groupCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.RuleSetGroupPrefix, groupCID) {
 		return nil, errors.Errorf("invalid rule set group CID (%s)", groupCID)
 	}
 
-	result, err := a.Get(groupCID)
+	result, err := a.GetWithContext(ctx, groupCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching rule set group")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch rule set group, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch rule set group, received JSON: %s", string(result))
 	}
 
 	rulesetGroup := &RuleSetGroup{}
-	if err := json.Unmarshal(result, rulesetGroup); err != nil {
+	if err := a.unmarshalJSON(result, rulesetGroup); err != nil {
 		return nil, errors.Wrap(err, "parsing rule set group")
 	}
 
@@ -86,14 +87,15 @@ func (a *API) FetchRuleSetGroup(cid CIDType) (*RuleSetGroup, error) {
 
 // FetchRuleSetGroups retrieves all rule set groups available to API Token.
 func (a *API) FetchRuleSetGroups() (*[]RuleSetGroup, error) {
-	result, err := a.Get(config.RuleSetGroupPrefix)
-	if err != nil {
-		return nil, errors.Wrap(err, "fetching rule set groups")
-	}
+	return a.FetchRuleSetGroupsWithContext(context.Background())
+}
 
+// FetchRuleSetGroupsWithContext retrieves all rule set groups available to
+// API Token, stopping when ctx is done.
+func (a *API) FetchRuleSetGroupsWithContext(ctx context.Context) (*[]RuleSetGroup, error) {
 	var rulesetGroups []RuleSetGroup
-	if err := json.Unmarshal(result, &rulesetGroups); err != nil {
-		return nil, errors.Wrap(err, "parsing rule set groups")
+	if err := a.getJSONContext(ctx, config.RuleSetGroupPrefix, &rulesetGroups); err != nil {
+		return nil, errors.Wrap(err, "fetching rule set groups")
 	}
 
 	return &rulesetGroups, nil
@@ -101,36 +103,38 @@ func (a *API) FetchRuleSetGroups() (*[]RuleSetGroup, error) {
 
 // UpdateRuleSetGroup updates passed rule set group.
 func (a *API) UpdateRuleSetGroup(cfg *RuleSetGroup) (*RuleSetGroup, error) {
+	return a.UpdateRuleSetGroupWithContext(context.Background(), cfg)
+}
+
+// UpdateRuleSetGroupWithContext updates passed rule set group, stopping when
+// ctx is done.
+func (a *API) UpdateRuleSetGroupWithContext(ctx context.Context, cfg *RuleSetGroup) (*RuleSetGroup, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid rule set group config (nil)")
 	}
 
 	groupCID := cfg.CID
 
-	matched, err := regexp.MatchString(config.RuleSetGroupCIDRegex, groupCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.RuleSetGroupPrefix, groupCID) {
 		return nil, errors.Errorf("invalid rule set group CID (%s)", groupCID)
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, errors.Wrap(err, "updating rule set group")
 	}
 
-	if a.Debug {
-		a.Log.Printf("update rule set group, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("update rule set group, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Put(groupCID, jsonCfg)
+	result, err := a.PutWithContext(ctx, groupCID, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "updating rule set group")
 	}
 
 	groups := &RuleSetGroup{}
-	if err := json.Unmarshal(result, groups); err != nil {
+	if err := a.unmarshalJSON(result, groups); err != nil {
 		return nil, errors.Wrap(err, "parsing rule set group")
 	}
 
@@ -139,26 +143,32 @@ func (a *API) UpdateRuleSetGroup(cfg *RuleSetGroup) (*RuleSetGroup, error) {
 
 // CreateRuleSetGroup creates a new rule set group.
 func (a *API) CreateRuleSetGroup(cfg *RuleSetGroup) (*RuleSetGroup, error) {
+	return a.CreateRuleSetGroupWithContext(context.Background(), cfg)
+}
+
+// CreateRuleSetGroupWithContext creates a new rule set group, stopping when
+// ctx is done.
+func (a *API) CreateRuleSetGroupWithContext(ctx context.Context, cfg *RuleSetGroup) (*RuleSetGroup, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid rule set group config (nil)")
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("create rule set group, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("create rule set group, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Post(config.RuleSetGroupPrefix, jsonCfg)
+	result, err := a.PostWithContext(ctx, config.RuleSetGroupPrefix, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "creating rule set group")
 	}
 
 	group := &RuleSetGroup{}
-	if err := json.Unmarshal(result, group); err != nil {
+	if err := a.unmarshalJSON(result, group); err != nil {
 		return nil, errors.Wrap(err, "parsing rule set group")
 	}
 
@@ -167,14 +177,26 @@ func (a *API) CreateRuleSetGroup(cfg *RuleSetGroup) (*RuleSetGroup, error) {
 
 // DeleteRuleSetGroup deletes passed rule set group.
 func (a *API) DeleteRuleSetGroup(cfg *RuleSetGroup) (bool, error) {
+	return a.DeleteRuleSetGroupWithContext(context.Background(), cfg)
+}
+
+// DeleteRuleSetGroupWithContext deletes passed rule set group, stopping when
+// ctx is done.
+func (a *API) DeleteRuleSetGroupWithContext(ctx context.Context, cfg *RuleSetGroup) (bool, error) {
 	if cfg == nil {
 		return false, errors.New("invalid rule set group config (nil)")
 	}
-	return a.DeleteRuleSetGroupByCID(CIDType(&cfg.CID))
+	return a.DeleteRuleSetGroupByCIDWithContext(ctx, CIDType(&cfg.CID))
 }
 
 // DeleteRuleSetGroupByCID deletes rule set group with passed cid.
 func (a *API) DeleteRuleSetGroupByCID(cid CIDType) (bool, error) {
+	return a.DeleteRuleSetGroupByCIDWithContext(context.Background(), cid)
+}
+
+// DeleteRuleSetGroupByCIDWithContext deletes rule set group with passed cid,
+// stopping when ctx is done.
+func (a *API) DeleteRuleSetGroupByCIDWithContext(ctx context.Context, cid CIDType) (bool, error) {
 	if cid == nil || *cid == "" {
 		return false, errors.New("invalid rule set group CID (none)")
 	}
@@ -186,15 +208,11 @@ func (a *API) DeleteRuleSetGroupByCID(cid CIDType) (bool, error) {
 		groupCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.RuleSetGroupCIDRegex, groupCID)
-	if err != nil {
-		return false, err
-	}
-	if !matched {
+	if !validCID(config.RuleSetGroupPrefix, groupCID) {
 		return false, errors.Errorf("invalid rule set group CID (%s)", groupCID)
 	}
 
-	_, err = a.Delete(groupCID)
+	_, err := a.DeleteWithContext(ctx, groupCID)
 	if err != nil {
 		return false, errors.Wrap(err, "deleting rule set group")
 	}
@@ -206,38 +224,187 @@ func (a *API) DeleteRuleSetGroupByCID(cid CIDType) (bool, error) {
 // specified search query and/or filter. If nil is passed for
 // both parameters all rule set groups will be returned.
 func (a *API) SearchRuleSetGroups(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]RuleSetGroup, error) {
-	q := url.Values{}
+	return a.SearchRuleSetGroupsWithContext(context.Background(), searchCriteria, filterCriteria)
+}
+
+// SearchRuleSetGroupsWithContext returns rule set groups matching the
+// specified search query and/or filter, stopping when ctx is done.
+func (a *API) SearchRuleSetGroupsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]RuleSetGroup, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchRuleSetGroupsWithContext(ctx)
+	}
 
-	if searchCriteria != nil && *searchCriteria != "" {
-		q.Set("search", string(*searchCriteria))
+	var groups []RuleSetGroup
+	if err := a.searchJSONContext(ctx, config.RuleSetGroupPrefix, searchCriteria, filterCriteria, &groups); err != nil {
+		return nil, errors.Wrap(err, "searching rule set groups")
 	}
 
-	if filterCriteria != nil && len(*filterCriteria) > 0 {
-		for filter, criteria := range *filterCriteria {
-			for _, val := range criteria {
-				q.Add(filter, val)
+	return &groups, nil
+}
+
+// RuleSetGroupMemberError identifies a rule set group member which failed validation.
+type RuleSetGroupMemberError struct {
+	Member     string // member letter used in formulas (A == first condition)
+	RuleSetCID string // member rule set cid
+	Reason     string // why the member is invalid
+}
+
+// Error returns the member, rule set, and reason.
+func (e *RuleSetGroupMemberError) Error() string {
+	return fmt.Sprintf("member %s (%s): %s", e.Member, e.RuleSetCID, e.Reason)
+}
+
+// RuleSetGroupValidationError lists the problems found by ValidateRuleSetGroup.
+type RuleSetGroupValidationError struct {
+	CID      string                    // rule set group cid, if any
+	Members  []RuleSetGroupMemberError // invalid members
+	Formulas error                     // formula problem, if any
+}
+
+// Error returns all of the problems found.
+func (e *RuleSetGroupValidationError) Error() string {
+	name := e.CID
+	if name == "" {
+		name = "new rule set group"
+	}
+	problems := make([]string, 0, len(e.Members)+1)
+	for _, m := range e.Members {
+		m := m
+		problems = append(problems, m.Error())
+	}
+	if e.Formulas != nil {
+		problems = append(problems, e.Formulas.Error())
+	}
+	return fmt.Sprintf("invalid rule set group (%s): %s", name, strings.Join(problems, "; "))
+}
+
+// ValidateRuleSetGroup checks the passed rule set group before it is created or
+// updated: every member rule set must exist, each member's matching severities
+// must be valid and raised by at least one rule in the member rule set, and the
+// formulas must only reference existing members. Returns a
+// *RuleSetGroupValidationError naming the offending members, or nil if valid.
+func (a *API) ValidateRuleSetGroup(cfg *RuleSetGroup) error {
+	if cfg == nil {
+		return errors.New("invalid rule set group config (nil)")
+	}
+
+	verr := &RuleSetGroupValidationError{CID: cfg.CID}
+
+	if len(cfg.RuleSetConditions) == 0 {
+		verr.Formulas = errors.New("no rule set conditions")
+		return verr
+	}
+
+	seen := make(map[string]string)
+	for idx, cond := range cfg.RuleSetConditions {
+		member := ruleSetGroupMember(idx).String()
+		memberErr := func(reason string, args ...interface{}) {
+			verr.Members = append(verr.Members, RuleSetGroupMemberError{
+				Member:     member,
+				RuleSetCID: cond.RuleSetCID,
+				Reason:     fmt.Sprintf(reason, args...),
+			})
+		}
+
+		if prev, dup := seen[cond.RuleSetCID]; dup {
+			memberErr("duplicate of member %s", prev)
+			continue
+		}
+		seen[cond.RuleSetCID] = member
+
+		if len(cond.MatchingSeverities) == 0 {
+			memberErr("no matching severities")
+			continue
+		}
+
+		cid := cond.RuleSetCID
+		rs, err := a.FetchRuleSet(CIDType(&cid))
+		if err != nil {
+			memberErr("%s", err)
+			continue
+		}
+
+		raised := make(map[uint]bool)
+		for _, rule := range rs.Rules {
+			raised[rule.Severity] = true
+		}
+		for _, s := range cond.MatchingSeverities {
+			sev, err := strconv.ParseUint(s, 10, 8)
+			if err != nil || sev < 1 || sev > MaxRuleSetSeverity {
+				memberErr("invalid matching severity (%s)", s)
+				continue
+			}
+			if !raised[uint(sev)] {
+				memberErr("matching severity %d is not raised by any rule", sev)
 			}
 		}
 	}
 
-	if q.Encode() == "" {
-		return a.FetchRuleSetGroups()
+	verr.Formulas = cfg.ValidateFormulas()
+
+	if len(verr.Members) > 0 || verr.Formulas != nil {
+		return verr
 	}
 
-	reqURL := url.URL{
-		Path:     config.RuleSetGroupPrefix,
-		RawQuery: q.Encode(),
+	return nil
+}
+
+// CloneRuleSetGroup creates a copy of the rule set group with passed cid, replacing
+// each member rule set with its counterpart in memberMap (source rule set cid to
+// new rule set cid). Formulas reference members by position, so they carry over
+// unchanged. Every member must have a mapping.
+func (a *API) CloneRuleSetGroup(cid CIDType, memberMap map[string]string) (*RuleSetGroup, error) {
+	if len(memberMap) == 0 {
+		return nil, errors.New("invalid rule set group member map (empty)")
 	}
 
-	result, err := a.Get(reqURL.String())
+	src, err := a.FetchRuleSetGroup(cid)
 	if err != nil {
-		return nil, errors.Wrap(err, "searching rule set groups")
+		return nil, err
 	}
 
-	var groups []RuleSetGroup
-	if err := json.Unmarshal(result, &groups); err != nil {
-		return nil, errors.Wrap(err, "parsing rule set groups")
+	normalize := func(rsCID string) string {
+		if !strings.HasPrefix(rsCID, config.RuleSetPrefix) {
+			return fmt.Sprintf("%s/%s", config.RuleSetPrefix, rsCID)
+		}
+		return rsCID
 	}
 
-	return &groups, nil
+	mapping := make(map[string]string, len(memberMap))
+	for from, to := range memberMap {
+		mapping[normalize(from)] = normalize(to)
+	}
+
+	clone := *src
+	clone.CID = ""
+	clone.Formulas = append([]RuleSetGroupFormula{}, src.Formulas...)
+	clone.Tags = append([]string{}, src.Tags...)
+	clone.ContactGroups = make(map[uint8][]string, len(src.ContactGroups))
+	for sev, groups := range src.ContactGroups {
+		clone.ContactGroups[sev] = append([]string{}, groups...)
+	}
+
+	clone.RuleSetConditions = make([]RuleSetGroupCondition, len(src.RuleSetConditions))
+	var unmapped []string
+	for idx, cond := range src.RuleSetConditions {
+		to, ok := mapping[cond.RuleSetCID]
+		if !ok {
+			unmapped = append(unmapped, fmt.Sprintf("%s (%s)", ruleSetGroupMember(idx), cond.RuleSetCID))
+			continue
+		}
+		clone.RuleSetConditions[idx] = RuleSetGroupCondition{
+			MatchingSeverities: append([]string{}, cond.MatchingSeverities...),
+			RuleSetCID:         to,
+		}
+	}
+	if len(unmapped) > 0 {
+		return nil, errors.Errorf("cloning rule set group %s, no mapping for member(s) %s", src.CID, strings.Join(unmapped, ", "))
+	}
+
+	group, err := a.CreateRuleSetGroup(&clone)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cloning rule set group %s", src.CID)
+	}
+
+	return group, nil
 }
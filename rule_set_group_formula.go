@@ -0,0 +1,480 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Rule Set Group formula builder - typed construction, parsing, and validation
+// of rule set group formula expressions.
+//
+// A formula expression is either a threshold (raise when at least N member rule
+// sets are in a matching state, encoded as a number) or a boolean expression
+// over the members, where each member is referenced by a letter corresponding
+// to its position in RuleSetConditions (A is the first condition, B the second,
+// and so on), combined with 'and', 'or', 'not', and parentheses.
+// e.g. "(A and B) and not C"
+
+package apiclient
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxRuleSetGroupMembers is the number of members addressable by letter (A-Z)
+	maxRuleSetGroupMembers = 26
+)
+
+// RuleSetGroupExpression defines a rule set group formula expression
+type RuleSetGroupExpression interface {
+	// String returns the expression in the encoding used by the API
+	String() string
+	// Evaluate reports whether the expression is true, given whether each
+	// member (by condition index) is currently in a matching state
+	Evaluate(matching []bool) bool
+	// members returns the member indexes referenced by the expression
+	members() []int
+}
+
+type ruleSetGroupMember int
+type ruleSetGroupAnd []RuleSetGroupExpression
+type ruleSetGroupOr []RuleSetGroupExpression
+type ruleSetGroupNot struct{ expr RuleSetGroupExpression }
+type ruleSetGroupThreshold uint
+
+// RuleSetGroupMember references the member rule set condition at passed index (0 == A).
+func RuleSetGroupMember(idx int) RuleSetGroupExpression {
+	return ruleSetGroupMember(idx)
+}
+
+// RuleSetGroupAnd is true when all of the passed expressions are true.
+func RuleSetGroupAnd(exprs ...RuleSetGroupExpression) RuleSetGroupExpression {
+	return ruleSetGroupAnd(exprs)
+}
+
+// RuleSetGroupOr is true when any of the passed expressions are true.
+func RuleSetGroupOr(exprs ...RuleSetGroupExpression) RuleSetGroupExpression {
+	return ruleSetGroupOr(exprs)
+}
+
+// RuleSetGroupNot is true when the passed expression is false.
+func RuleSetGroupNot(expr RuleSetGroupExpression) RuleSetGroupExpression {
+	return ruleSetGroupNot{expr: expr}
+}
+
+// RuleSetGroupAtLeast is true when at least n members are in a matching state.
+func RuleSetGroupAtLeast(n uint) RuleSetGroupExpression {
+	return ruleSetGroupThreshold(n)
+}
+
+func (m ruleSetGroupMember) String() string {
+	if m < 0 || m >= maxRuleSetGroupMembers {
+		return fmt.Sprintf("?%d", int(m))
+	}
+	return string(rune('A' + int(m)))
+}
+
+func (m ruleSetGroupMember) Evaluate(matching []bool) bool {
+	return int(m) >= 0 && int(m) < len(matching) && matching[m]
+}
+
+func (m ruleSetGroupMember) members() []int {
+	return []int{int(m)}
+}
+
+// ruleSetGroupOperand renders a sub-expression, parenthesized if compound
+func ruleSetGroupOperand(e RuleSetGroupExpression) string {
+	switch v := e.(type) {
+	case ruleSetGroupAnd:
+		if len(v) > 1 {
+			return "(" + v.String() + ")"
+		}
+	case ruleSetGroupOr:
+		if len(v) > 1 {
+			return "(" + v.String() + ")"
+		}
+	}
+	return e.String()
+}
+
+func (e ruleSetGroupAnd) String() string {
+	parts := make([]string, len(e))
+	for i, sub := range e {
+		parts[i] = ruleSetGroupOperand(sub)
+	}
+	return strings.Join(parts, " and ")
+}
+
+func (e ruleSetGroupAnd) Evaluate(matching []bool) bool {
+	for _, sub := range e {
+		if !sub.Evaluate(matching) {
+			return false
+		}
+	}
+	return len(e) > 0
+}
+
+func (e ruleSetGroupAnd) members() []int {
+	var m []int
+	for _, sub := range e {
+		m = append(m, sub.members()...)
+	}
+	return m
+}
+
+func (e ruleSetGroupOr) String() string {
+	parts := make([]string, len(e))
+	for i, sub := range e {
+		parts[i] = ruleSetGroupOperand(sub)
+	}
+	return strings.Join(parts, " or ")
+}
+
+func (e ruleSetGroupOr) Evaluate(matching []bool) bool {
+	for _, sub := range e {
+		if sub.Evaluate(matching) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e ruleSetGroupOr) members() []int {
+	var m []int
+	for _, sub := range e {
+		m = append(m, sub.members()...)
+	}
+	return m
+}
+
+func (e ruleSetGroupNot) String() string {
+	return "not " + ruleSetGroupOperand(e.expr)
+}
+
+func (e ruleSetGroupNot) Evaluate(matching []bool) bool {
+	return !e.expr.Evaluate(matching)
+}
+
+func (e ruleSetGroupNot) members() []int {
+	return e.expr.members()
+}
+
+func (t ruleSetGroupThreshold) String() string {
+	return strconv.FormatUint(uint64(t), 10)
+}
+
+func (t ruleSetGroupThreshold) Evaluate(matching []bool) bool {
+	n := uint(0)
+	for _, m := range matching {
+		if m {
+			n++
+		}
+	}
+	return n >= uint(t)
+}
+
+func (t ruleSetGroupThreshold) members() []int {
+	return nil
+}
+
+// ParseRuleSetGroupExpression parses a formula expression as returned by the API
+// (a string, or a number for thresholds). Errors include the position of the problem.
+func ParseRuleSetGroupExpression(expr interface{}) (RuleSetGroupExpression, error) {
+	var src string
+	switch v := expr.(type) {
+	case string:
+		src = v
+	case float64:
+		if v < 0 || v != float64(uint(v)) {
+			return nil, errors.Errorf("invalid rule set group threshold (%v)", v)
+		}
+		return ruleSetGroupThreshold(uint(v)), nil
+	case int:
+		if v < 0 {
+			return nil, errors.Errorf("invalid rule set group threshold (%d)", v)
+		}
+		return ruleSetGroupThreshold(uint(v)), nil
+	case uint:
+		return ruleSetGroupThreshold(v), nil
+	default:
+		return nil, errors.Errorf("invalid rule set group expression type (%T)", expr)
+	}
+
+	src = strings.TrimSpace(src)
+	if src == "" {
+		return nil, errors.New("invalid rule set group expression (empty)")
+	}
+	if n, err := strconv.ParseUint(src, 10, 32); err == nil {
+		return ruleSetGroupThreshold(uint(n)), nil
+	}
+
+	p := &ruleSetGroupParser{src: src}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		tok := p.tokens[p.pos]
+		return nil, errors.Errorf("invalid rule set group expression %q: unexpected %q at position %d", src, tok.text, tok.offset)
+	}
+	return e, nil
+}
+
+type ruleSetGroupToken struct {
+	text   string
+	offset int
+}
+
+type ruleSetGroupParser struct {
+	src    string
+	tokens []ruleSetGroupToken
+	pos    int
+}
+
+func (p *ruleSetGroupParser) tokenize() error {
+	runes := []rune(p.src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			p.tokens = append(p.tokens, ruleSetGroupToken{text: string(r), offset: i})
+			i++
+		case unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && unicode.IsLetter(runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			switch strings.ToLower(word) {
+			case "and", "or", "not":
+				word = strings.ToLower(word)
+			default:
+				if len(word) != 1 || word[0] < 'A' || word[0] > 'Z' {
+					return errors.Errorf("invalid rule set group expression %q: unknown term %q at position %d", p.src, word, start)
+				}
+			}
+			p.tokens = append(p.tokens, ruleSetGroupToken{text: word, offset: start})
+		default:
+			return errors.Errorf("invalid rule set group expression %q: unexpected %q at position %d", p.src, string(r), i)
+		}
+	}
+	return nil
+}
+
+func (p *ruleSetGroupParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos].text
+	}
+	return ""
+}
+
+func (p *ruleSetGroupParser) parseOr() (RuleSetGroupExpression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	exprs := []RuleSetGroupExpression{left}
+	for p.peek() == "or" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, right)
+	}
+	if len(exprs) == 1 {
+		return left, nil
+	}
+	return ruleSetGroupOr(exprs), nil
+}
+
+func (p *ruleSetGroupParser) parseAnd() (RuleSetGroupExpression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	exprs := []RuleSetGroupExpression{left}
+	for p.peek() == "and" {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, right)
+	}
+	if len(exprs) == 1 {
+		return left, nil
+	}
+	return ruleSetGroupAnd(exprs), nil
+}
+
+func (p *ruleSetGroupParser) parseUnary() (RuleSetGroupExpression, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, errors.Errorf("invalid rule set group expression %q: unexpected end of expression", p.src)
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	switch tok.text {
+	case "not":
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return ruleSetGroupNot{expr: e}, nil
+	case "(":
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, errors.Errorf("invalid rule set group expression %q: missing ')' for '(' at position %d", p.src, tok.offset)
+		}
+		p.pos++
+		return e, nil
+	case "and", "or", ")":
+		return nil, errors.Errorf("invalid rule set group expression %q: unexpected %q at position %d", p.src, tok.text, tok.offset)
+	default:
+		return ruleSetGroupMember(int(tok.text[0] - 'A')), nil
+	}
+}
+
+// validateRuleSetGroupExpression checks the expression against the number of group members
+func validateRuleSetGroupExpression(e RuleSetGroupExpression, numMembers int) error {
+	if t, ok := e.(ruleSetGroupThreshold); ok {
+		if t == 0 || int(t) > numMembers {
+			return errors.Errorf("invalid rule set group threshold (%d), group has %d members", uint(t), numMembers)
+		}
+		return nil
+	}
+	for _, idx := range e.members() {
+		if idx < 0 || idx >= numMembers || idx >= maxRuleSetGroupMembers {
+			return errors.Errorf("invalid rule set group expression %q: member %s does not exist, group has %d members", e.String(), ruleSetGroupMember(idx), numMembers)
+		}
+	}
+	return nil
+}
+
+// AddFormula validates the passed expression against the group's rule set
+// conditions and appends it as a new formula.
+func (g *RuleSetGroup) AddFormula(expr RuleSetGroupExpression, raiseSeverity, wait uint) error {
+	if g == nil {
+		return errors.New("invalid rule set group (nil)")
+	}
+	if expr == nil {
+		return errors.New("invalid rule set group expression (nil)")
+	}
+	if raiseSeverity < 1 || raiseSeverity > MaxRuleSetSeverity {
+		return errors.Errorf("invalid rule set group raise severity (%d)", raiseSeverity)
+	}
+	if err := validateRuleSetGroupExpression(expr, len(g.RuleSetConditions)); err != nil {
+		return err
+	}
+
+	g.Formulas = append(g.Formulas, RuleSetGroupFormula{
+		Expression:    expr.String(),
+		RaiseSeverity: raiseSeverity,
+		Wait:          wait,
+	})
+
+	return nil
+}
+
+// ValidateFormulas checks that each formula expression parses and only references
+// existing members, and that each raise severity is valid.
+func (g *RuleSetGroup) ValidateFormulas() error {
+	if g == nil {
+		return errors.New("invalid rule set group (nil)")
+	}
+	for idx, f := range g.Formulas {
+		e, err := ParseRuleSetGroupExpression(f.Expression)
+		if err != nil {
+			return errors.Wrapf(err, "formula %d", idx)
+		}
+		if err := validateRuleSetGroupExpression(e, len(g.RuleSetConditions)); err != nil {
+			return errors.Wrapf(err, "formula %d", idx)
+		}
+		if f.RaiseSeverity < 1 || f.RaiseSeverity > MaxRuleSetSeverity {
+			return errors.Errorf("formula %d: invalid rule set group raise severity (%d)", idx, f.RaiseSeverity)
+		}
+	}
+	return nil
+}
+
+// RuleSetGroupEvaluation is the result of evaluating a rule set group's formulas
+// against the current state of its members.
+type RuleSetGroupEvaluation struct {
+	Matching []bool                // per member (condition index), true if in a matching state
+	Fired    []RuleSetGroupFormula // formulas which would raise an alert
+	Severity uint                  // most severe (lowest) raise severity of the fired formulas, 0 if none
+}
+
+// EvaluateRuleSetGroup computes whether the group's formulas would fire given the
+// passed alerts. A member is matching when an uncleared alert for its rule set has
+// one of the member's matching severities.
+func EvaluateRuleSetGroup(g *RuleSetGroup, alerts []Alert) (*RuleSetGroupEvaluation, error) {
+	if g == nil {
+		return nil, errors.New("invalid rule set group (nil)")
+	}
+
+	eval := &RuleSetGroupEvaluation{
+		Matching: make([]bool, len(g.RuleSetConditions)),
+	}
+
+	for idx, cond := range g.RuleSetConditions {
+		severities := make(map[string]bool, len(cond.MatchingSeverities))
+		for _, s := range cond.MatchingSeverities {
+			severities[s] = true
+		}
+		for _, alert := range alerts {
+			if alert.ClearedOn != nil || alert.RuleSetCID != cond.RuleSetCID {
+				continue
+			}
+			if severities[strconv.FormatUint(uint64(alert.Severity), 10)] {
+				eval.Matching[idx] = true
+				break
+			}
+		}
+	}
+
+	for idx, f := range g.Formulas {
+		e, err := ParseRuleSetGroupExpression(f.Expression)
+		if err != nil {
+			return nil, errors.Wrapf(err, "formula %d", idx)
+		}
+		if !e.Evaluate(eval.Matching) {
+			continue
+		}
+		eval.Fired = append(eval.Fired, f)
+		if eval.Severity == 0 || f.RaiseSeverity < eval.Severity {
+			eval.Severity = f.RaiseSeverity
+		}
+	}
+
+	return eval, nil
+}
+
+// PreviewRuleSetGroup fetches the rule set group with passed cid and the currently
+// uncleared alerts, and evaluates whether the group's formulas would fire. Useful
+// for testing composite alert logic before enabling notifications.
+func (a *API) PreviewRuleSetGroup(cid CIDType) (*RuleSetGroupEvaluation, error) {
+	g, err := a.FetchRuleSetGroup(cid)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := SearchFilterType{"f__cleared_on": []string{"null"}}
+	alerts, err := a.SearchAlerts(nil, &filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "previewing rule set group")
+	}
+
+	return EvaluateRuleSetGroup(g, *alerts)
+}
@@ -0,0 +1,267 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRuleSetGroupExpressionString(t *testing.T) {
+	a, b, c := RuleSetGroupMember(0), RuleSetGroupMember(1), RuleSetGroupMember(2)
+
+	tests := []struct {
+		id       string
+		expr     RuleSetGroupExpression
+		expected string
+	}{
+		{"member", a, "A"},
+		{"and", RuleSetGroupAnd(a, b), "A and B"},
+		{"or", RuleSetGroupOr(a, b, c), "A or B or C"},
+		{"nested", RuleSetGroupAnd(RuleSetGroupAnd(a, b), RuleSetGroupNot(c)), "(A and B) and not C"},
+		{"not compound", RuleSetGroupNot(RuleSetGroupOr(a, b)), "not (A or B)"},
+		{"threshold", RuleSetGroupAtLeast(3), "3"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			if s := test.expr.String(); s != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, s)
+			}
+			parsed, err := ParseRuleSetGroupExpression(test.expected)
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if parsed.String() != test.expected {
+				t.Fatalf("round trip: expected %q, got %q", test.expected, parsed.String())
+			}
+		})
+	}
+}
+
+func TestParseRuleSetGroupExpression(t *testing.T) {
+	tests := []struct {
+		id          string
+		expr        interface{}
+		expected    string
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"numeric", float64(2), "2", false, ""},
+		{"numeric string", "3", "3", false, ""},
+		{"precedence", "A or B and C", "A or (B and C)", false, ""},
+		{"case", "A AND not B", "A and not B", false, ""},
+		{"empty", "", "", true, "invalid rule set group expression (empty)"},
+		{"type", true, "", true, "invalid rule set group expression type (bool)"},
+		{"negative", float64(-1), "", true, "invalid rule set group threshold (-1)"},
+		{"unknown term", "A xor B", "", true, `invalid rule set group expression "A xor B": unknown term "xor" at position 2`},
+		{"bad char", "A & B", "", true, `invalid rule set group expression "A & B": unexpected "&" at position 2`},
+		{"unbalanced", "(A and B", "", true, `invalid rule set group expression "(A and B": missing ')' for '(' at position 0`},
+		{"dangling", "A and", "", true, `invalid rule set group expression "A and": unexpected end of expression`},
+		{"trailing", "A B", "", true, `invalid rule set group expression "A B": unexpected "B" at position 2`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			e, err := ParseRuleSetGroupExpression(test.expr)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if e.String() != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, e.String())
+			}
+		})
+	}
+}
+
+func TestRuleSetGroupExpressionEvaluate(t *testing.T) {
+	e, err := ParseRuleSetGroupExpression("(A and B) and not C")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if !e.Evaluate([]bool{true, true, false}) {
+		t.Fatal("expected true")
+	}
+	if e.Evaluate([]bool{true, true, true}) {
+		t.Fatal("expected false")
+	}
+	if !RuleSetGroupAtLeast(2).Evaluate([]bool{true, false, true}) {
+		t.Fatal("expected true")
+	}
+	if RuleSetGroupAtLeast(2).Evaluate([]bool{true, false, false}) {
+		t.Fatal("expected false")
+	}
+}
+
+func TestRuleSetGroupAddFormula(t *testing.T) {
+	g := testRuleSetGroup
+	g.Formulas = nil
+
+	tests := []struct {
+		id          string
+		expr        RuleSetGroupExpression
+		severity    uint
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (nil)", nil, 1, true, "invalid rule set group expression (nil)"},
+		{"invalid severity", RuleSetGroupMember(0), 0, true, "invalid rule set group raise severity (0)"},
+		{"invalid member", RuleSetGroupAnd(RuleSetGroupMember(0), RuleSetGroupMember(3)), 1, true, `invalid rule set group expression "A and D": member D does not exist, group has 3 members`},
+		{"invalid threshold", RuleSetGroupAtLeast(4), 1, true, "invalid rule set group threshold (4), group has 3 members"},
+		{"valid expression", RuleSetGroupAnd(RuleSetGroupMember(0), RuleSetGroupMember(2)), 2, false, ""},
+		{"valid threshold", RuleSetGroupAtLeast(2), 1, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			err := g.AddFormula(test.expr, test.severity, 0)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+		})
+	}
+
+	if len(g.Formulas) != 2 {
+		t.Fatalf("expected 2 formulas, got %d", len(g.Formulas))
+	}
+	if err := g.ValidateFormulas(); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if err := testRuleSetGroup.ValidateFormulas(); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	g.Formulas = append(g.Formulas, RuleSetGroupFormula{Expression: "A or E", RaiseSeverity: 1})
+	if err := g.ValidateFormulas(); err == nil {
+		t.Fatal("expected error")
+	} else if err.Error() != `formula 2: invalid rule set group expression "A or E": member E does not exist, group has 3 members` {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+}
+
+func TestEvaluateRuleSetGroup(t *testing.T) {
+	cleared := uint(1483033102)
+	alert := func(rs string, sev uint, clearedOn *uint) Alert {
+		return Alert{RuleSetCID: rs, Severity: sev, ClearedOn: clearedOn}
+	}
+
+	tests := []struct {
+		id               string
+		alerts           []Alert
+		expectedMatching []bool
+		expectedFired    int
+		expectedSeverity uint
+	}{
+		{"none", nil, []bool{false, false, false}, 0, 0},
+		{"A and B", []Alert{
+			alert("/rule_set/1234_tt_firstbyte", 1, nil),
+			alert("/rule_set/5678_tt_firstbyte", 2, nil),
+		}, []bool{true, true, false}, 1, 2},
+		{"all", []Alert{
+			alert("/rule_set/1234_tt_firstbyte", 1, nil),
+			alert("/rule_set/5678_tt_firstbyte", 2, nil),
+			alert("/rule_set/9012_tt_firstbyte", 1, nil),
+		}, []bool{true, true, true}, 1, 1},
+		{"cleared and non-matching severity", []Alert{
+			alert("/rule_set/1234_tt_firstbyte", 1, &cleared),
+			alert("/rule_set/5678_tt_firstbyte", 3, nil),
+		}, []bool{false, false, false}, 0, 0},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			eval, err := EvaluateRuleSetGroup(&testRuleSetGroup, test.alerts)
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if !reflect.DeepEqual(eval.Matching, test.expectedMatching) {
+				t.Fatalf("unexpected matching (%v)", eval.Matching)
+			}
+			if len(eval.Fired) != test.expectedFired {
+				t.Fatalf("expected %d fired, got %d", test.expectedFired, len(eval.Fired))
+			}
+			if eval.Severity != test.expectedSeverity {
+				t.Fatalf("expected severity %d, got %d", test.expectedSeverity, eval.Severity)
+			}
+		})
+	}
+
+	if _, err := EvaluateRuleSetGroup(nil, nil); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+// testRuleSetGroupPreviewServer serves testRuleSetGroup and the open alerts
+// of its members
+func testRuleSetGroupPreviewServer() *httptest.Server {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		reqURL := r.URL.String()
+		var obj interface{}
+		switch reqURL {
+		case "/rule_set_group/1234":
+			obj = testRuleSetGroup
+		case "/alert?f__cleared_on=null":
+			obj = []Alert{
+				{RuleSetCID: "/rule_set/1234_tt_firstbyte", Severity: 1},
+				{RuleSetCID: "/rule_set/5678_tt_firstbyte", Severity: 1},
+			}
+		}
+		if obj == nil || r.Method != "GET" {
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, reqURL))
+			return
+		}
+		ret, err := json.Marshal(obj)
+		if err != nil {
+			panic(err)
+		}
+		w.WriteHeader(200)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, string(ret))
+	}
+
+	return httptest.NewServer(http.HandlerFunc(f))
+}
+
+func TestPreviewRuleSetGroup(t *testing.T) {
+	server := testRuleSetGroupPreviewServer()
+	defer server.Close()
+	apih, err := NewAPI(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	cid := "1234"
+	eval, err := apih.PreviewRuleSetGroup(CIDType(&cid))
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if eval.Severity != 2 {
+		t.Fatalf("expected severity 2, got %d", eval.Severity)
+	}
+}
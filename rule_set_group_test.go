@@ -115,17 +115,13 @@ func testRuleSetGroupServer() *httptest.Server {
 				}
 			case "POST":
 				defer r.Body.Close()
-				_, err := ioutil.ReadAll(r.Body)
-				if err != nil {
-					panic(err)
-				}
-				ret, err := json.Marshal(testRuleSetGroup)
+				b, err := ioutil.ReadAll(r.Body)
 				if err != nil {
 					panic(err)
 				}
 				w.WriteHeader(200)
 				w.Header().Set("Content-Type", "application/json")
-				fmt.Fprintln(w, string(ret))
+				fmt.Fprintln(w, string(b))
 			default:
 				w.WriteHeader(404)
 				fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
@@ -157,6 +153,36 @@ func ruleSetGroupTestBootstrap(t *testing.T) (*API, *httptest.Server) {
 	return apih, server
 }
 
+// testRuleSetGroupMemberServer serves the member rule sets of testRuleSetGroup
+func testRuleSetGroupMemberServer() *httptest.Server {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		switch path {
+		case "/rule_set/1234_tt_firstbyte", "/rule_set/5678_tt_firstbyte", "/rule_set/9012_tt_firstbyte":
+			switch r.Method {
+			case "GET":
+				rs := testRuleSet
+				rs.CID = path
+				ret, err := json.Marshal(rs)
+				if err != nil {
+					panic(err)
+				}
+				w.WriteHeader(200)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintln(w, string(ret))
+			default:
+				w.WriteHeader(404)
+				fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
+			}
+		default:
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
+		}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(f))
+}
+
 func TestNewRuleSetGroup(t *testing.T) {
 	ruleSetGroup := NewRuleSetGroup()
 	if reflect.TypeOf(ruleSetGroup).String() != "*apiclient.RuleSetGroup" {
@@ -401,3 +427,132 @@ func TestSearchRuleSetGroups(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRuleSetGroup(t *testing.T) {
+	server := testRuleSetGroupMemberServer()
+	defer server.Close()
+	apih, err := NewAPI(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	missing := testRuleSetGroup
+	missing.RuleSetConditions = []RuleSetGroupCondition{
+		testRuleSetGroup.RuleSetConditions[0],
+		{MatchingSeverities: []string{"1"}, RuleSetCID: "/rule_set/0000_tt_firstbyte"},
+	}
+	missing.Formulas = []RuleSetGroupFormula{{Expression: "A and C", RaiseSeverity: 1}}
+
+	severity := testRuleSetGroup
+	severity.RuleSetConditions = []RuleSetGroupCondition{
+		{MatchingSeverities: []string{"1", "3"}, RuleSetCID: "/rule_set/1234_tt_firstbyte"},
+		{MatchingSeverities: []string{"x"}, RuleSetCID: "/rule_set/5678_tt_firstbyte"},
+		{MatchingSeverities: []string{"1"}, RuleSetCID: "/rule_set/1234_tt_firstbyte"},
+	}
+
+	tests := []struct {
+		id            string
+		cfg           *RuleSetGroup
+		expectMembers []string
+		shouldFail    bool
+		expectedErr   string
+	}{
+		{"invalid (nil)", nil, nil, true, "invalid rule set group config (nil)"},
+		{"invalid (no conditions)", &RuleSetGroup{}, nil, true, "invalid rule set group (new rule set group): no rule set conditions"},
+		{"valid", &testRuleSetGroup, nil, false, ""},
+		{"missing member", &missing, []string{"B"}, true, ""},
+		{"severity mapping", &severity, []string{"A", "B", "C"}, true, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			err := apih.ValidateRuleSetGroup(test.cfg)
+			if !test.shouldFail {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if test.expectedErr != "" {
+				if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			verr, ok := err.(*RuleSetGroupValidationError)
+			if !ok {
+				t.Fatalf("unexpected error type (%T)", err)
+			}
+			var members []string
+			for _, m := range verr.Members {
+				members = append(members, m.Member)
+			}
+			if !reflect.DeepEqual(members, test.expectMembers) {
+				t.Fatalf("unexpected members (%v): %s", members, err)
+			}
+		})
+	}
+
+	err = apih.ValidateRuleSetGroup(&missing)
+	if verr, ok := err.(*RuleSetGroupValidationError); !ok || verr.Formulas == nil {
+		t.Fatalf("expected formula error (%v)", err)
+	}
+}
+
+func TestCloneRuleSetGroup(t *testing.T) {
+	apih, server := ruleSetGroupTestBootstrap(t)
+	defer server.Close()
+
+	full := map[string]string{
+		"/rule_set/1234_tt_firstbyte": "/rule_set/4321_tt_firstbyte",
+		"5678_tt_firstbyte":           "8765_tt_firstbyte",
+		"/rule_set/9012_tt_firstbyte": "/rule_set/2109_tt_firstbyte",
+	}
+
+	tests := []struct {
+		id          string
+		cid         string
+		memberMap   map[string]string
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (map)", "1234", nil, true, "invalid rule set group member map (empty)"},
+		{"invalid (cid)", "", full, true, "invalid rule set group CID (none)"},
+		{"unmapped", "1234", map[string]string{"/rule_set/1234_tt_firstbyte": "/rule_set/4321_tt_firstbyte"}, true, "cloning rule set group /rule_set_group/1234, no mapping for member(s) B (/rule_set/5678_tt_firstbyte), C (/rule_set/9012_tt_firstbyte)"},
+		{"valid", "1234", full, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			group, err := apih.CloneRuleSetGroup(CIDType(&test.cid), test.memberMap)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			expected := []string{"/rule_set/4321_tt_firstbyte", "/rule_set/8765_tt_firstbyte", "/rule_set/2109_tt_firstbyte"}
+			for idx, cond := range group.RuleSetConditions {
+				if cond.RuleSetCID != expected[idx] {
+					t.Fatalf("unexpected member %d (%s)", idx, cond.RuleSetCID)
+				}
+			}
+			if len(group.Formulas) != len(testRuleSetGroup.Formulas) {
+				t.Fatalf("unexpected formulas (%#v)", group.Formulas)
+			}
+			if testRuleSetGroup.RuleSetConditions[0].RuleSetCID != "/rule_set/1234_tt_firstbyte" {
+				t.Fatal("expected source group to be unmodified")
+			}
+		})
+	}
+}
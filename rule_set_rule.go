@@ -0,0 +1,237 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Rule Set Rule builder - typed construction and local validation of rule set rules
+// See: https://login.circonus.com/resources/api/calls/rule_set
+
+package apiclient
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RuleSetCriteria defines the criteria used by a rule set rule
+type RuleSetCriteria string
+
+// Rule set rule criteria
+const (
+	CriteriaMinValue       = RuleSetCriteria("min value")
+	CriteriaMaxValue       = RuleSetCriteria("max value")
+	CriteriaOnAbsence      = RuleSetCriteria("on absence")
+	CriteriaOnChange       = RuleSetCriteria("on change")
+	CriteriaMatch          = RuleSetCriteria("match")
+	CriteriaDoesNotMatch   = RuleSetCriteria("does not match")
+	CriteriaContains       = RuleSetCriteria("contains")
+	CriteriaDoesNotContain = RuleSetCriteria("does not contain")
+)
+
+// RuleSetWindowingFunction defines the windowing function applied to a rule set rule
+type RuleSetWindowingFunction string
+
+// Rule set rule windowing functions
+const (
+	WindowingAverage        = RuleSetWindowingFunction("average")
+	WindowingStddev         = RuleSetWindowingFunction("stddev")
+	WindowingDerive         = RuleSetWindowingFunction("derive")
+	WindowingDeriveStddev   = RuleSetWindowingFunction("derive_stddev")
+	WindowingCounter        = RuleSetWindowingFunction("counter")
+	WindowingCounterStddev  = RuleSetWindowingFunction("counter_stddev")
+	WindowingDerive2        = RuleSetWindowingFunction("derive_2")
+	WindowingDerive2Stddev  = RuleSetWindowingFunction("derive_2_stddev")
+	WindowingCounter2       = RuleSetWindowingFunction("counter_2")
+	WindowingCounter2Stddev = RuleSetWindowingFunction("counter_2_stddev")
+)
+
+const (
+	// MaxRuleSetSeverity is the highest (least urgent) severity a rule may raise
+	MaxRuleSetSeverity = 5
+)
+
+// IsNumeric reports whether the criteria applies to numeric metrics.
+func (c RuleSetCriteria) IsNumeric() bool {
+	switch c {
+	case CriteriaMinValue, CriteriaMaxValue, CriteriaOnAbsence, CriteriaOnChange:
+		return true
+	}
+	return false
+}
+
+// IsText reports whether the criteria applies to text metrics.
+func (c RuleSetCriteria) IsText() bool {
+	switch c {
+	case CriteriaMatch, CriteriaDoesNotMatch, CriteriaContains, CriteriaDoesNotContain, CriteriaOnAbsence, CriteriaOnChange:
+		return true
+	}
+	return false
+}
+
+// valid reports whether the criteria is known
+func (c RuleSetCriteria) valid() bool {
+	return c.IsNumeric() || c.IsText()
+}
+
+// valid reports whether the windowing function is known
+func (f RuleSetWindowingFunction) valid() bool {
+	switch f {
+	case WindowingAverage, WindowingStddev,
+		WindowingDerive, WindowingDeriveStddev,
+		WindowingCounter, WindowingCounterStddev,
+		WindowingDerive2, WindowingDerive2Stddev,
+		WindowingCounter2, WindowingCounter2Stddev:
+		return true
+	}
+	return false
+}
+
+// Validate checks the rule for a legal combination of criteria, value,
+// windowing settings, and severity.
+func (r *RuleSetRule) Validate() error {
+	if r == nil {
+		return errors.New("invalid rule set rule (nil)")
+	}
+
+	criteria := RuleSetCriteria(r.Criteria)
+	if !criteria.valid() {
+		return errors.Errorf("invalid rule set rule criteria (%s)", r.Criteria)
+	}
+
+	if r.Severity > MaxRuleSetSeverity {
+		return errors.Errorf("invalid rule set rule severity (%d)", r.Severity)
+	}
+
+	value := ruleSetRuleValue(r.Value)
+
+	switch criteria {
+	case CriteriaMinValue, CriteriaMaxValue:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return errors.Errorf("invalid rule set rule value (%v), %s requires a numeric value", r.Value, criteria)
+		}
+	case CriteriaOnAbsence:
+		secs, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || secs == 0 {
+			return errors.Errorf("invalid rule set rule value (%v), %s requires a duration in seconds", r.Value, criteria)
+		}
+	case CriteriaMatch, CriteriaDoesNotMatch, CriteriaContains, CriteriaDoesNotContain:
+		if value == "" {
+			return errors.Errorf("invalid rule set rule value (none), %s requires a value", criteria)
+		}
+	}
+
+	if r.WindowingFunction != nil && *r.WindowingFunction != "" {
+		fn := RuleSetWindowingFunction(*r.WindowingFunction)
+		if !fn.valid() {
+			return errors.Errorf("invalid rule set rule windowing function (%s)", fn)
+		}
+		if criteria != CriteriaMinValue && criteria != CriteriaMaxValue {
+			return errors.Errorf("invalid rule set rule, windowing not supported with %s", criteria)
+		}
+		if r.WindowingDuration == 0 {
+			return errors.Errorf("invalid rule set rule, windowing function %s requires a windowing duration", fn)
+		}
+	}
+
+	return nil
+}
+
+// ruleSetRuleValue normalizes a rule value (string or numeric) to a string
+func ruleSetRuleValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32)
+	case int:
+		return strconv.FormatInt(int64(val), 10)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case uint:
+		return strconv.FormatUint(uint64(val), 10)
+	case uint64:
+		return strconv.FormatUint(val, 10)
+	default:
+		return ""
+	}
+}
+
+// RuleSetRuleBuilder constructs a rule set rule using a fluent interface,
+// e.g. NewRuleSetRuleBuilder(CriteriaMaxValue).Threshold(1000).Severity(2).Build()
+type RuleSetRuleBuilder struct {
+	rule RuleSetRule
+}
+
+// NewRuleSetRuleBuilder returns a builder for a rule using the passed criteria.
+func NewRuleSetRuleBuilder(criteria RuleSetCriteria) *RuleSetRuleBuilder {
+	return &RuleSetRuleBuilder{
+		rule: RuleSetRule{
+			Criteria: string(criteria),
+			Severity: 1,
+		},
+	}
+}
+
+// Threshold sets a numeric value, for min/max value criteria.
+func (b *RuleSetRuleBuilder) Threshold(v float64) *RuleSetRuleBuilder {
+	b.rule.Value = strconv.FormatFloat(v, 'f', -1, 64)
+	return b
+}
+
+// Match sets a text value, for match/contains criteria.
+func (b *RuleSetRuleBuilder) Match(v string) *RuleSetRuleBuilder {
+	b.rule.Value = v
+	return b
+}
+
+// Absent sets the absence duration, for on absence criteria.
+func (b *RuleSetRuleBuilder) Absent(d time.Duration) *RuleSetRuleBuilder {
+	b.rule.Value = strconv.FormatInt(int64(d/time.Second), 10)
+	return b
+}
+
+// Severity sets the severity raised when the rule matches.
+func (b *RuleSetRuleBuilder) Severity(sev uint) *RuleSetRuleBuilder {
+	b.rule.Severity = sev
+	return b
+}
+
+// Wait sets the number of minutes to wait before raising an alert.
+func (b *RuleSetRuleBuilder) Wait(minutes uint) *RuleSetRuleBuilder {
+	b.rule.Wait = minutes
+	return b
+}
+
+// Window sets the windowing function and duration.
+func (b *RuleSetRuleBuilder) Window(fn RuleSetWindowingFunction, d time.Duration) *RuleSetRuleBuilder {
+	f := string(fn)
+	b.rule.WindowingFunction = &f
+	b.rule.WindowingDuration = uint(d / time.Second)
+	return b
+}
+
+// Build validates and returns the rule.
+func (b *RuleSetRuleBuilder) Build() (*RuleSetRule, error) {
+	rule := b.rule
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// AddRule validates and appends the passed rule to the rule set.
+func (rs *RuleSet) AddRule(rule *RuleSetRule) error {
+	if rs == nil {
+		return errors.New("invalid rule set (nil)")
+	}
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	rs.Rules = append(rs.Rules, *rule)
+	return nil
+}
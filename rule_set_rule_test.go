@@ -0,0 +1,149 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRuleSetRuleBuilder(t *testing.T) {
+	tests := []struct {
+		id          string
+		builder     *RuleSetRuleBuilder
+		expected    RuleSetRule
+		shouldFail  bool
+		expectedErr string
+	}{
+		{
+			"max value",
+			NewRuleSetRuleBuilder(CriteriaMaxValue).Threshold(1000).Severity(2).Wait(5),
+			RuleSetRule{Criteria: "max value", Severity: 2, Value: "1000", Wait: 5},
+			false, "",
+		},
+		{
+			"min value, windowed",
+			NewRuleSetRuleBuilder(CriteriaMinValue).Threshold(0.5).Window(WindowingAverage, 5*time.Minute),
+			RuleSetRule{Criteria: "min value", Severity: 1, Value: "0.5", WindowingDuration: 300, WindowingFunction: &[]string{"average"}[0]},
+			false, "",
+		},
+		{
+			"on absence",
+			NewRuleSetRuleBuilder(CriteriaOnAbsence).Absent(5 * time.Minute),
+			RuleSetRule{Criteria: "on absence", Severity: 1, Value: "300"},
+			false, "",
+		},
+		{
+			"match",
+			NewRuleSetRuleBuilder(CriteriaMatch).Match("down").Severity(3),
+			RuleSetRule{Criteria: "match", Severity: 3, Value: "down"},
+			false, "",
+		},
+		{
+			"invalid criteria",
+			NewRuleSetRuleBuilder(RuleSetCriteria("bogus")),
+			RuleSetRule{},
+			true, "invalid rule set rule criteria (bogus)",
+		},
+		{
+			"invalid severity",
+			NewRuleSetRuleBuilder(CriteriaMaxValue).Threshold(1).Severity(6),
+			RuleSetRule{},
+			true, "invalid rule set rule severity (6)",
+		},
+		{
+			"invalid numeric value",
+			NewRuleSetRuleBuilder(CriteriaMaxValue).Match("high"),
+			RuleSetRule{},
+			true, "invalid rule set rule value (high), max value requires a numeric value",
+		},
+		{
+			"invalid absence",
+			NewRuleSetRuleBuilder(CriteriaOnAbsence),
+			RuleSetRule{},
+			true, "invalid rule set rule value (<nil>), on absence requires a duration in seconds",
+		},
+		{
+			"invalid text value",
+			NewRuleSetRuleBuilder(CriteriaContains),
+			RuleSetRule{},
+			true, "invalid rule set rule value (none), contains requires a value",
+		},
+		{
+			"invalid windowing criteria",
+			NewRuleSetRuleBuilder(CriteriaMatch).Match("x").Window(WindowingAverage, time.Minute),
+			RuleSetRule{},
+			true, "invalid rule set rule, windowing not supported with match",
+		},
+		{
+			"invalid windowing function",
+			NewRuleSetRuleBuilder(CriteriaMaxValue).Threshold(1).Window(RuleSetWindowingFunction("median"), time.Minute),
+			RuleSetRule{},
+			true, "invalid rule set rule windowing function (median)",
+		},
+		{
+			"invalid windowing duration",
+			NewRuleSetRuleBuilder(CriteriaMaxValue).Threshold(1).Window(WindowingStddev, 0),
+			RuleSetRule{},
+			true, "invalid rule set rule, windowing function stddev requires a windowing duration",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			rule, err := test.builder.Build()
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if rule.Criteria != test.expected.Criteria ||
+				rule.Severity != test.expected.Severity ||
+				rule.Value != test.expected.Value ||
+				rule.Wait != test.expected.Wait ||
+				rule.WindowingDuration != test.expected.WindowingDuration {
+				t.Fatalf("unexpected rule (%#v)", rule)
+			}
+			if (rule.WindowingFunction == nil) != (test.expected.WindowingFunction == nil) ||
+				(rule.WindowingFunction != nil && *rule.WindowingFunction != *test.expected.WindowingFunction) {
+				t.Fatalf("unexpected windowing function (%v)", rule.WindowingFunction)
+			}
+		})
+	}
+}
+
+func TestRuleSetAddRule(t *testing.T) {
+	rs := NewRuleSet()
+
+	if err := rs.AddRule(&RuleSetRule{Criteria: "bogus"}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	rule, err := NewRuleSetRuleBuilder(CriteriaOnChange).Build()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if err := rs.AddRule(rule); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(rs.Rules) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(rs.Rules))
+	}
+
+	// existing rules fetched from the API validate
+	for _, r := range testRuleSet.Rules {
+		r := r
+		if err := r.Validate(); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Rule Set templates - instantiate a rule set definition across many checks/metrics
+
+package apiclient
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// RuleSetTemplateTarget identifies a check metric to instantiate a rule set template for.
+// Vars supply values for {{var}} placeholders in the template, in addition to the
+// built-in {{check_cid}}, {{check_id}}, and {{metric_name}}.
+type RuleSetTemplateTarget struct {
+	CheckCID   string
+	MetricName string
+	Vars       map[string]string
+}
+
+// RuleSetTemplateResult summarizes the changes made by ApplyRuleSetTemplate.
+type RuleSetTemplateResult struct {
+	Created   []RuleSet
+	Updated   []RuleSet
+	Unchanged []RuleSet
+}
+
+// InstantiateRuleSetTemplate returns a rule set for the passed target, with all
+// placeholders in the template name, notes, link, tags, and rule values substituted.
+func InstantiateRuleSetTemplate(template *RuleSet, target RuleSetTemplateTarget) (*RuleSet, error) {
+	if template == nil {
+		return nil, errors.New("invalid rule set template (nil)")
+	}
+	if target.CheckCID == "" {
+		return nil, errors.New("invalid rule set template target, check CID (none)")
+	}
+	if target.MetricName == "" {
+		return nil, errors.Errorf("invalid rule set template target %s, metric name (none)", target.CheckCID)
+	}
+
+	checkCID := target.CheckCID
+	if !strings.HasPrefix(checkCID, config.CheckPrefix) {
+		checkCID = fmt.Sprintf("%s/%s", config.CheckPrefix, checkCID)
+	}
+
+	pairs := []string{
+		"{{check_cid}}", checkCID,
+		"{{check_id}}", strings.TrimPrefix(checkCID, config.CheckPrefix+"/"),
+		"{{metric_name}}", target.MetricName,
+	}
+	for k, v := range target.Vars {
+		pairs = append(pairs, "{{"+k+"}}", v)
+	}
+	r := strings.NewReplacer(pairs...)
+
+	substPtr := func(s *string) *string {
+		if s == nil {
+			return nil
+		}
+		v := r.Replace(*s)
+		return &v
+	}
+
+	rs := *template
+	rs.CID = ""
+	rs.CheckCID = checkCID
+	rs.MetricName = target.MetricName
+	rs.Name = r.Replace(template.Name)
+	rs.Notes = substPtr(template.Notes)
+	rs.Link = substPtr(template.Link)
+
+	if template.Tags != nil {
+		rs.Tags = make([]string, len(template.Tags))
+		for i, tag := range template.Tags {
+			rs.Tags[i] = r.Replace(tag)
+		}
+	}
+
+	if template.ContactGroups != nil {
+		rs.ContactGroups = make(map[uint8][]string, len(template.ContactGroups))
+		for sev, groups := range template.ContactGroups {
+			rs.ContactGroups[sev] = append([]string{}, groups...)
+		}
+	}
+
+	rs.Rules = make([]RuleSetRule, len(template.Rules))
+	for i, rule := range template.Rules {
+		rule.WindowingFunction = substPtr(rule.WindowingFunction)
+		if v, ok := rule.Value.(string); ok {
+			rule.Value = r.Replace(v)
+		}
+		if strings.Contains(ruleSetRuleValue(rule.Value), "{{") {
+			return nil, errors.Errorf("rule set template target %s %s, rule %d: unresolved placeholder in value (%v)", checkCID, target.MetricName, i, rule.Value)
+		}
+		rs.Rules[i] = rule
+	}
+
+	return &rs, nil
+}
+
+// ApplyRuleSetTemplate instantiates the template for each target and creates the
+// rule set, or updates the existing rule set for the same check and metric if it
+// differs from the instantiated template. Returns a summary of the changes made;
+// on error the summary covers the targets processed before the failure.
+func (a *API) ApplyRuleSetTemplate(template *RuleSet, targets []RuleSetTemplateTarget) (*RuleSetTemplateResult, error) {
+	if template == nil {
+		return nil, errors.New("invalid rule set template (nil)")
+	}
+
+	existing, err := a.FetchRuleSets()
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]RuleSet, len(*existing))
+	for _, rs := range *existing {
+		current[rs.CheckCID+"`"+rs.MetricName] = rs
+	}
+
+	result := &RuleSetTemplateResult{}
+	for _, target := range targets {
+		desired, err := InstantiateRuleSetTemplate(template, target)
+		if err != nil {
+			return result, err
+		}
+
+		live, found := current[desired.CheckCID+"`"+desired.MetricName]
+		if !found {
+			created, err := a.CreateRuleSet(desired)
+			if err != nil {
+				return result, errors.Wrapf(err, "applying rule set template to %s %s", desired.CheckCID, desired.MetricName)
+			}
+			result.Created = append(result.Created, *created)
+			continue
+		}
+
+		desired.CID = live.CID
+		desired.Host = live.Host
+		if ruleSetTemplateEqual(desired, &live) {
+			result.Unchanged = append(result.Unchanged, live)
+			continue
+		}
+
+		updated, err := a.UpdateRuleSet(desired)
+		if err != nil {
+			return result, errors.Wrapf(err, "applying rule set template to %s %s", desired.CheckCID, desired.MetricName)
+		}
+		result.Updated = append(result.Updated, *updated)
+	}
+
+	return result, nil
+}
+
+// ruleSetTemplateEqual compares the attributes set by a template
+func ruleSetTemplateEqual(desired, live *RuleSet) bool {
+	strPtr := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	groups := func(m map[uint8][]string) map[uint8][]string {
+		n := make(map[uint8][]string)
+		for sev, g := range m {
+			if len(g) > 0 {
+				n[sev] = g
+			}
+		}
+		return n
+	}
+	rules := func(rr []RuleSetRule) []RuleSetRule {
+		n := make([]RuleSetRule, len(rr))
+		for i, rule := range rr {
+			rule.Value = ruleSetRuleValue(rule.Value)
+			if rule.WindowingFunction != nil && *rule.WindowingFunction == "" {
+				rule.WindowingFunction = nil
+			}
+			n[i] = rule
+		}
+		return n
+	}
+
+	return desired.Name == live.Name &&
+		desired.MetricType == live.MetricType &&
+		strPtr(desired.Notes) == strPtr(live.Notes) &&
+		strPtr(desired.Link) == strPtr(live.Link) &&
+		strPtr(desired.Parent) == strPtr(live.Parent) &&
+		strPtr(desired.Derive) == strPtr(live.Derive) &&
+		len(desired.Tags) == len(live.Tags) && (len(desired.Tags) == 0 || reflect.DeepEqual(desired.Tags, live.Tags)) &&
+		reflect.DeepEqual(groups(desired.ContactGroups), groups(live.ContactGroups)) &&
+		reflect.DeepEqual(rules(desired.Rules), rules(live.Rules))
+}
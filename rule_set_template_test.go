@@ -0,0 +1,166 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testRuleSetTemplate() RuleSet {
+	return RuleSet{
+		CheckCID: "",
+		ContactGroups: map[uint8][]string{
+			1: {"/contact_group/1234"},
+			2: {"/contact_group/1234"},
+		},
+		Link:       &[]string{"http://example.com/how2fix/{{metric_name}}/"}[0],
+		MetricType: "numeric",
+		Name:       "{{host}} {{metric_name}}",
+		Notes:      &[]string{"check {{check_id}}"}[0],
+		Rules: []RuleSetRule{
+			{Criteria: "on absence", Severity: 1, Value: "300", Wait: 5},
+			{Criteria: "max value", Severity: 2, Value: "{{threshold}}", Wait: 5},
+		},
+		Tags: []string{"host:{{host}}"},
+	}
+}
+
+// testRuleSetTemplateServer serves the existing rule sets, and echoes the
+// rule sets created and updated
+func testRuleSetTemplateServer(existing []RuleSet) *httptest.Server {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		switch {
+		case path == "/rule_set" && r.Method == "GET":
+			ret, err := json.Marshal(existing)
+			if err != nil {
+				panic(err)
+			}
+			w.WriteHeader(200)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintln(w, string(ret))
+		case path == "/rule_set" && r.Method == "POST",
+			strings.HasPrefix(path, "/rule_set/") && r.Method == "PUT":
+			defer r.Body.Close()
+			b, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				panic(err)
+			}
+			w.WriteHeader(200)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintln(w, string(b))
+		default:
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
+		}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(f))
+}
+
+func TestInstantiateRuleSetTemplate(t *testing.T) {
+	template := testRuleSetTemplate()
+
+	tests := []struct {
+		id          string
+		template    *RuleSet
+		target      RuleSetTemplateTarget
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (nil)", nil, RuleSetTemplateTarget{}, true, "invalid rule set template (nil)"},
+		{"invalid (check)", &template, RuleSetTemplateTarget{}, true, "invalid rule set template target, check CID (none)"},
+		{"invalid (metric)", &template, RuleSetTemplateTarget{CheckCID: "1234"}, true, "invalid rule set template target 1234, metric name (none)"},
+		{"unresolved placeholder", &template, RuleSetTemplateTarget{CheckCID: "1234", MetricName: "foo"}, true, "rule set template target /check/1234 foo, rule 1: unresolved placeholder in value ({{threshold}})"},
+		{"valid", &template, RuleSetTemplateTarget{CheckCID: "1234", MetricName: "foo", Vars: map[string]string{"host": "web1", "threshold": "1000"}}, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			rs, err := InstantiateRuleSetTemplate(test.template, test.target)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if rs.CheckCID != "/check/1234" {
+				t.Fatalf("unexpected check cid (%s)", rs.CheckCID)
+			}
+			if rs.Name != "web1 foo" {
+				t.Fatalf("unexpected name (%s)", rs.Name)
+			}
+			if *rs.Notes != "check 1234" {
+				t.Fatalf("unexpected notes (%s)", *rs.Notes)
+			}
+			if *rs.Link != "http://example.com/how2fix/foo/" {
+				t.Fatalf("unexpected link (%s)", *rs.Link)
+			}
+			if rs.Tags[0] != "host:web1" {
+				t.Fatalf("unexpected tags (%v)", rs.Tags)
+			}
+			if rs.Rules[1].Value != "1000" {
+				t.Fatalf("unexpected rule value (%v)", rs.Rules[1].Value)
+			}
+			if template.Rules[1].Value != "{{threshold}}" {
+				t.Fatal("expected template to be unmodified")
+			}
+		})
+	}
+}
+
+func TestApplyRuleSetTemplate(t *testing.T) {
+	template := testRuleSetTemplate()
+
+	unchanged, err := InstantiateRuleSetTemplate(&template, RuleSetTemplateTarget{CheckCID: "/check/1111", MetricName: "foo", Vars: map[string]string{"host": "web1", "threshold": "1000"}})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	unchanged.CID = "/rule_set/1111"
+
+	server := testRuleSetTemplateServer([]RuleSet{testRuleSetNewCID, *unchanged})
+	defer server.Close()
+	apih, err := NewAPI(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if _, err := apih.ApplyRuleSetTemplate(nil, nil); err == nil {
+		t.Fatal("expected error")
+	}
+
+	targets := []RuleSetTemplateTarget{
+		{CheckCID: "/check/1111", MetricName: "foo", Vars: map[string]string{"host": "web1", "threshold": "1000"}},
+		{CheckCID: "/check/1234", MetricName: "tt_firstbyte", Vars: map[string]string{"host": "web2", "threshold": "500"}},
+		{CheckCID: "/check/5678", MetricName: "tt_firstbyte", Vars: map[string]string{"host": "web3", "threshold": "500"}},
+	}
+
+	result, err := apih.ApplyRuleSetTemplate(&template, targets)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if len(result.Unchanged) != 1 || result.Unchanged[0].CID != "/rule_set/1111" {
+		t.Fatalf("unexpected unchanged (%#v)", result.Unchanged)
+	}
+	if len(result.Updated) != 1 || result.Updated[0].CID != "/rule_set/1234" {
+		t.Fatalf("unexpected updated (%#v)", result.Updated)
+	}
+	if len(result.Created) != 1 || result.Created[0].CheckCID != "/check/5678" {
+		t.Fatalf("unexpected created (%#v)", result.Created)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -87,6 +88,21 @@ var (
 	}
 )
 
+var testRuleSetMuted = RuleSet{
+	CID:           "/rule_set/5678",
+	CheckCID:      "/check/1234",
+	ContactGroups: map[uint8][]string{1: {}, 2: {}, 3: {}, 4: {}, 5: {}},
+	MetricName:    "tt_connect",
+	MetricType:    "numeric",
+	Rules: []RuleSetRule{
+		{
+			Criteria: "max value",
+			Severity: 1,
+			Value:    "1000",
+		},
+	},
+}
+
 func testRuleSetServer() *httptest.Server {
 	f := func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
@@ -143,6 +159,20 @@ func testRuleSetServer() *httptest.Server {
 				w.WriteHeader(404)
 				fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
 			}
+		case "/rule_set/5678":
+			switch r.Method {
+			case "GET":
+				ret, err := json.Marshal(testRuleSetMuted)
+				if err != nil {
+					panic(err)
+				}
+				w.WriteHeader(200)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintln(w, string(ret))
+			default:
+				w.WriteHeader(404)
+				fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
+			}
 		case "/rule_set":
 			switch r.Method {
 			case "GET":
@@ -174,17 +204,13 @@ func testRuleSetServer() *httptest.Server {
 				}
 			case "POST":
 				defer r.Body.Close()
-				_, err := ioutil.ReadAll(r.Body)
-				if err != nil {
-					panic(err)
-				}
-				ret, err := json.Marshal(testRuleSet)
+				b, err := ioutil.ReadAll(r.Body)
 				if err != nil {
 					panic(err)
 				}
 				w.WriteHeader(200)
 				w.Header().Set("Content-Type", "application/json")
-				fmt.Fprintln(w, string(ret))
+				fmt.Fprintln(w, string(b))
 			default:
 				w.WriteHeader(404)
 				fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
@@ -216,6 +242,56 @@ func ruleSetTestBootstrap(t *testing.T) (*API, *httptest.Server) {
 	return apih, server
 }
 
+// testRuleSetValidationServer serves the objects a rule set is validated against
+func testRuleSetValidationServer() *httptest.Server {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		var obj interface{}
+		switch path {
+		case "/check/1234":
+			obj = testCheck
+		case "/metric/1234_tt_firstbyte":
+			obj = Metric{CID: "/metric/1234_tt_firstbyte", MetricName: "tt_firstbyte", MetricType: "numeric"}
+		case "/metric/1234_status":
+			obj = Metric{CID: "/metric/1234_status", MetricName: "status", MetricType: "text"}
+		case "/contact_group/1234", "/contact_group/5678":
+			obj = testContactGroup
+		}
+		if obj == nil || r.Method != "GET" {
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, path))
+			return
+		}
+		ret, err := json.Marshal(obj)
+		if err != nil {
+			panic(err)
+		}
+		w.WriteHeader(200)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, string(ret))
+	}
+
+	return httptest.NewServer(http.HandlerFunc(f))
+}
+
+func ruleSetValidationTestBootstrap(t *testing.T) (*API, *httptest.Server) {
+	server := testRuleSetValidationServer()
+
+	ac := &Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      server.URL,
+	}
+	apih, err := NewAPI(ac)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+		server.Close()
+		return nil, nil
+	}
+
+	return apih, server
+}
+
 func TestNewRuleSet(t *testing.T) {
 	ruleSet := NewRuleSet()
 	if reflect.TypeOf(ruleSet).String() != "*apiclient.RuleSet" {
@@ -467,3 +543,339 @@ func TestSearchRuleSets(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRuleSet(t *testing.T) {
+	apih, server := ruleSetValidationTestBootstrap(t)
+	defer server.Close()
+
+	textRuleSet := testRuleSetNewCID
+	textRuleSet.MetricName = "status"
+	textRuleSet.MetricType = "text"
+
+	missingMetric := testRuleSetNewCID
+	missingMetric.MetricName = "missing"
+
+	noContacts := testRuleSetNewCID
+	noContacts.ContactGroups = map[uint8][]string{1: {"/contact_group/1234"}, 2: {}}
+
+	badContact := testRuleSetNewCID
+	badContact.ContactGroups = map[uint8][]string{1: {"/contact_group/9999"}, 2: {"/contact_group/1234"}}
+
+	badCheck := testRuleSetNewCID
+	badCheck.CheckCID = "/check/9999"
+
+	tests := []struct {
+		id          string
+		cfg         *RuleSet
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (nil)", nil, true, "invalid rule set config (nil)"},
+		{"valid", &testRuleSetNewCID, false, ""},
+		{"text metric, numeric criteria", &textRuleSet, true, `invalid rule set (/rule_set/1234): rule 1: criteria "max value" not valid for text metric`},
+		{"missing metric", &missingMetric, true, `invalid rule set (/rule_set/1234): metric "missing": fetching metric: API response code 404: not found: GET /metric/1234_missing`},
+		{"missing contact groups", &noContacts, true, "invalid rule set (/rule_set/1234): severity 2: no contact groups"},
+		{"invalid contact group", &badContact, true, `invalid rule set (/rule_set/1234): severity 1: contact group "/contact_group/9999": fetching contact group: API response code 404: not found: GET /contact_group/9999`},
+		{"missing check", &badCheck, true, `invalid rule set (/rule_set/1234): check "/check/9999": fetching check: API response code 404: not found: GET /check/9999`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			err := apih.ValidateRuleSet(test.cfg)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if strings.TrimSpace(err.Error()) != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+		})
+	}
+}
+
+func TestCloneRuleSet(t *testing.T) {
+	apih, server := ruleSetTestBootstrap(t)
+	defer server.Close()
+
+	tests := []struct {
+		id             string
+		cid            string
+		checkCID       string
+		metricName     string
+		expectedCheck  string
+		expectedMetric string
+		shouldFail     bool
+		expectedErr    string
+	}{
+		{"invalid (check)", "/rule_set/1234", "", "", "", "", true, "invalid rule set clone check CID (none)"},
+		{"invalid (cid)", "", "5678", "", "", "", true, "invalid rule set CID (none)"},
+		{"same metric", "/rule_set/1234", "5678", "", "/check/5678", "tt_firstbyte", false, ""},
+		{"new metric", "1234", "/check/5678", "tt_connect", "/check/5678", "tt_connect", false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			rs, err := apih.CloneRuleSet(CIDType(&test.cid), test.checkCID, test.metricName)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if rs.CID != "" {
+				t.Fatalf("unexpected cid (%s)", rs.CID)
+			}
+			if rs.CheckCID != test.expectedCheck {
+				t.Fatalf("unexpected check (%s)", rs.CheckCID)
+			}
+			if rs.MetricName != test.expectedMetric {
+				t.Fatalf("unexpected metric (%s)", rs.MetricName)
+			}
+			if len(rs.Rules) != len(testRuleSetNewCID.Rules) {
+				t.Fatalf("unexpected rules (%#v)", rs.Rules)
+			}
+			if len(rs.ContactGroups[1]) != 2 {
+				t.Fatalf("unexpected contact groups (%#v)", rs.ContactGroups)
+			}
+		})
+	}
+}
+
+func TestRuleSetsNotifying(t *testing.T) {
+	apih, server := ruleSetTestBootstrap(t)
+	defer server.Close()
+
+	tests := []struct {
+		id          string
+		cid         string
+		expectedNum int
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (cid)", "", 0, true, "invalid contact group CID (none)"},
+		{"short cid", "5678", 1, false, ""},
+		{"long cid", "/contact_group/1234", 1, false, ""},
+		{"not notified", "/contact_group/9999", 0, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			rulesets, err := apih.RuleSetsNotifying(CIDType(&test.cid))
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				} else if len(*rulesets) != test.expectedNum {
+					t.Fatalf("expected %d rule sets, got %d", test.expectedNum, len(*rulesets))
+				}
+			}
+		})
+	}
+}
+
+func TestRuleSetsWithSeverity(t *testing.T) {
+	apih, server := ruleSetTestBootstrap(t)
+	defer server.Close()
+
+	tests := []struct {
+		id          string
+		severity    uint
+		expectedNum int
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (0)", 0, 0, true, "invalid rule set severity (0)"},
+		{"invalid (6)", 6, 0, true, "invalid rule set severity (6)"},
+		{"used", 2, 1, false, ""},
+		{"unused", 4, 0, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			rulesets, err := apih.RuleSetsWithSeverity(test.severity)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				} else if len(*rulesets) != test.expectedNum {
+					t.Fatalf("expected %d rule sets, got %d", test.expectedNum, len(*rulesets))
+				}
+			}
+		})
+	}
+}
+
+func TestRuleSetPattern(t *testing.T) {
+	tests := []struct {
+		id        string
+		rs        RuleSet
+		metric    string
+		isPattern bool
+		matches   bool
+	}{
+		{"named metric", RuleSet{MetricName: "tt_firstbyte"}, "tt_firstbyte", false, true},
+		{"named metric, other", RuleSet{MetricName: "tt_firstbyte"}, "tt_connect", false, false},
+		{"pattern", RuleSet{MetricPattern: "^tt_.+"}, "tt_connect", true, true},
+		{"pattern, other", RuleSet{MetricPattern: "^tt_.+"}, "duration", true, false},
+		{"filter", RuleSet{Filter: "and(env:prod)"}, "duration", true, true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			if test.rs.IsPattern() != test.isPattern {
+				t.Fatalf("expected IsPattern %t", test.isPattern)
+			}
+			matches, err := test.rs.MatchesMetric(test.metric)
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if matches != test.matches {
+				t.Fatalf("expected MatchesMetric %t", test.matches)
+			}
+		})
+	}
+
+	bad := RuleSet{MetricPattern: "("}
+	if _, err := bad.MatchesMetric("foo"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestValidatePatternRuleSet(t *testing.T) {
+	apih, server := ruleSetValidationTestBootstrap(t)
+	defer server.Close()
+
+	valid := testRuleSetNewCID
+	valid.MetricName = ""
+	valid.MetricPattern = "^tt_.+"
+	valid.Filter = "and(env:prod)"
+	valid.LookupKey = &[]string{"host"}[0]
+
+	badPattern := valid
+	badPattern.MetricPattern = "("
+
+	noType := valid
+	noType.MetricType = ""
+
+	filterOnly := valid
+	filterOnly.MetricPattern = ""
+
+	noCheck := valid
+	noCheck.CheckCID = ""
+
+	tests := []struct {
+		id          string
+		cfg         *RuleSet
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"valid", &valid, false, ""},
+		{"valid (no check)", &noCheck, false, ""},
+		{"invalid pattern", &badPattern, true, "invalid rule set (/rule_set/1234): metric pattern \"(\": error parsing regexp: missing closing ): `(`"},
+		{"missing metric type", &noType, true, "invalid rule set (/rule_set/1234): metric type required for pattern rule sets"},
+		{"filter without pattern", &filterOnly, true, "invalid rule set (/rule_set/1234): filter \"and(env:prod)\" requires a metric pattern"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			err := apih.ValidateRuleSet(test.cfg)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+		})
+	}
+}
+
+func TestMuteRuleSet(t *testing.T) {
+	apih, server := ruleSetTestBootstrap(t)
+	defer server.Close()
+
+	tests := []struct {
+		id          string
+		cid         string
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (cid)", "", true, "invalid rule set CID (none)"},
+		{"already muted", "5678", true, "rule set /rule_set/5678 has no contact groups (already muted?)"},
+		{"valid", "1234", false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			mute, err := apih.MuteRuleSet(CIDType(&test.cid))
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if mute.CID != "/rule_set/1234" {
+				t.Fatalf("unexpected cid (%s)", mute.CID)
+			}
+			if !reflect.DeepEqual(mute.ContactGroups[1], testRuleSetNewCID.ContactGroups[1]) {
+				t.Fatalf("unexpected contact groups (%#v)", mute.ContactGroups)
+			}
+			if _, ok := mute.ContactGroups[4]; ok {
+				t.Fatal("expected empty severities to be omitted")
+			}
+		})
+	}
+}
+
+func TestUnmuteRuleSet(t *testing.T) {
+	apih, server := ruleSetTestBootstrap(t)
+	defer server.Close()
+
+	if _, err := apih.UnmuteRuleSet(nil); err == nil {
+		t.Fatal("expected error")
+	}
+
+	rs, err := apih.UnmuteRuleSet(&RuleSetMute{CID: "/rule_set/1234", ContactGroups: map[uint8][]string{1: {"/contact_group/9999", "/contact_group/1234"}, 2: {"/contact_group/1234"}}})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	expected := []string{"/contact_group/1234", "/contact_group/5678", "/contact_group/9999"}
+	if !reflect.DeepEqual(rs.ContactGroups[1], expected) {
+		t.Fatalf("unexpected severity 1 contact groups (%v)", rs.ContactGroups[1])
+	}
+	if !reflect.DeepEqual(rs.ContactGroups[2], []string{"/contact_group/1234"}) {
+		t.Fatalf("unexpected severity 2 contact groups (%v)", rs.ContactGroups[2])
+	}
+}
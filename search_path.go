@@ -0,0 +1,194 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Search path - request paths for searching and filtering objects
+
+package apiclient
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxSearchURLLength is the longest search URL requested, longer searches
+// are split into several requests (see searchJSON)
+var maxSearchURLLength = 4096
+
+// emptySearch returns true if there are no search or filter criteria
+func emptySearch(search *SearchQueryType, filter *SearchFilterType) bool {
+	return searchPath("", search, filter) == ""
+}
+
+// searchPath returns the request path searching objects with the cid prefix
+// (e.g. config.GraphPrefix) for the search query and filter criteria, "" if
+// there are none. The query is encoded as url.Values encodes it (keys
+// sorted, the search query before any "search" filter criteria), without
+// building a url.Values or url.URL.
+func searchPath(prefix string, search *SearchQueryType, filter *SearchFilterType) string {
+	hasSearch := search != nil && *search != ""
+	var filters SearchFilterType
+	if filter != nil {
+		filters = *filter
+	}
+
+	size := len(prefix) + 1
+	keys := make([]string, 0, len(filters)+1)
+	if hasSearch {
+		keys = append(keys, "search")
+		size += len("search") + len(*search) + 2
+	}
+	for k, vals := range filters {
+		if len(vals) == 0 {
+			continue
+		}
+		if k != "search" || !hasSearch {
+			keys = append(keys, k)
+		}
+		for _, v := range vals {
+			size += len(k) + len(v) + 2
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.Grow(size)
+	b.WriteString(prefix)
+	sep := byte('?')
+	add := func(k, v string) {
+		b.WriteByte(sep)
+		b.WriteString(url.QueryEscape(k))
+		b.WriteByte('=')
+		b.WriteString(url.QueryEscape(v))
+		sep = '&'
+	}
+	for _, k := range keys {
+		if k == "search" && hasSearch {
+			add(k, string(*search))
+		}
+		for _, v := range filters[k] {
+			add(k, v)
+		}
+	}
+
+	return b.String()
+}
+
+// searchJSON gets the objects with the cid prefix matching the search query
+// and filter criteria, decoding them into v (a pointer to a slice). When
+// the search URL would be longer than maxSearchURLLength, the values of the
+// filter with the most values are split across several requests (filter
+// values are alternatives, so the results of each are merged, without
+// duplicates).
+func (a *API) searchJSON(prefix string, search *SearchQueryType, filter *SearchFilterType, v interface{}) error {
+	return a.searchJSONContext(context.Background(), prefix, search, filter, v)
+}
+
+// searchJSONContext gets the objects matching the search, see searchJSON,
+// stopping when ctx is done
+func (a *API) searchJSONContext(ctx context.Context, prefix string, search *SearchQueryType, filter *SearchFilterType, v interface{}) error {
+	reqPath := searchPath(prefix, search, filter)
+	if reqPath == "" {
+		reqPath = prefix
+	}
+	limit := maxSearchURLLength - len(a.apiURL.String())
+	if len(reqPath) <= limit {
+		return a.getJSONContext(ctx, reqPath, v)
+	}
+
+	out := reflect.ValueOf(v)
+	if out.Kind() != reflect.Ptr || out.Elem().Kind() != reflect.Slice {
+		return errors.Errorf("invalid search result (%T), must be a pointer to a slice", v)
+	}
+	out = out.Elem()
+
+	chunks, err := splitSearchFilter(prefix, search, filter, limit)
+	if err != nil {
+		return err
+	}
+
+	parts := make([]reflect.Value, len(chunks))
+	err = a.FetchEach(ctx, len(chunks), nil, func(ctx context.Context, i int) error {
+		part := reflect.New(out.Type())
+		if err := a.getJSONContext(ctx, searchPath(prefix, search, &chunks[i]), part.Interface()); err != nil {
+			return err
+		}
+		parts[i] = part.Elem()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	for _, part := range parts {
+		for i := 0; i < part.Len(); i++ {
+			item := part.Index(i)
+			if elem := reflect.Indirect(item); elem.Kind() == reflect.Struct {
+				if cid, ok := jsonField(elem, "_cid"); ok && cid.Kind() == reflect.String && cid.String() != "" {
+					if seen[cid.String()] {
+						continue
+					}
+					seen[cid.String()] = true
+				}
+			}
+			out.Set(reflect.Append(out, item))
+		}
+	}
+
+	return nil
+}
+
+// splitSearchFilter splits the values of the filter with the most values
+// into as few filters as keep each search path within limit
+func splitSearchFilter(prefix string, search *SearchQueryType, filter *SearchFilterType, limit int) ([]SearchFilterType, error) {
+	var split string
+	if filter != nil {
+		for k, vals := range *filter {
+			if len(vals) > len((*filter)[split]) || (len(vals) == len((*filter)[split]) && k < split) {
+				split = k
+			}
+		}
+	}
+	if split == "" || len((*filter)[split]) < 2 {
+		return nil, errors.Errorf("search of %s too long (%d characters), no filter to split", prefix, len(searchPath(prefix, search, filter)))
+	}
+
+	chunk := func(vals []string) SearchFilterType {
+		f := make(SearchFilterType, len(*filter))
+		for k, v := range *filter {
+			f[k] = v
+		}
+		f[split] = vals
+		return f
+	}
+
+	var chunks []SearchFilterType
+	vals := (*filter)[split]
+	start := 0
+	for i := range vals {
+		next := chunk(vals[start : i+1])
+		if len(searchPath(prefix, search, &next)) <= limit {
+			continue
+		}
+		if i > start {
+			chunks = append(chunks, chunk(vals[start:i]))
+			start = i
+			next = chunk(vals[i : i+1])
+		}
+		if len(searchPath(prefix, search, &next)) > limit {
+			return nil, errors.Errorf("search of %s too long, %s value %q alone exceeds the URL length limit", prefix, split, vals[i])
+		}
+	}
+	chunks = append(chunks, chunk(vals[start:]))
+
+	return chunks, nil
+}
@@ -0,0 +1,182 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/circonus-labs/go-apiclient/config"
+)
+
+// urlValuesSearchPath builds a search path as the search methods did, with
+// url.Values and url.URL
+func urlValuesSearchPath(prefix string, search *SearchQueryType, filter *SearchFilterType) string {
+	q := url.Values{}
+	if search != nil && *search != "" {
+		q.Set("search", string(*search))
+	}
+	if filter != nil {
+		for f, criteria := range *filter {
+			for _, val := range criteria {
+				q.Add(f, val)
+			}
+		}
+	}
+	if q.Encode() == "" {
+		return ""
+	}
+	reqURL := url.URL{Path: prefix, RawQuery: q.Encode()}
+	return reqURL.String()
+}
+
+func TestSearchPath(t *testing.T) {
+	search := SearchQueryType("(active:1)web server")
+	empty := SearchQueryType("")
+
+	tests := []struct {
+		id       string
+		search   *SearchQueryType
+		filter   *SearchFilterType
+		expected string
+	}{
+		{"none", nil, nil, ""},
+		{"empty", &empty, &SearchFilterType{"f_type": {}}, ""},
+		{"search", &search, nil, "/graph?search=%28active%3A1%29web+server"},
+		{"filter", nil, &SearchFilterType{"f_tags_has": {"service:web", "env:prod"}, "f_active": {"true"}}, "/graph?f_active=true&f_tags_has=service%3Aweb&f_tags_has=env%3Aprod"},
+		{"both", &search, &SearchFilterType{"f_title": {"a&b"}, "t": {""}}, "/graph?f_title=a%26b&search=%28active%3A1%29web+server&t="},
+		{"search filter", &search, &SearchFilterType{"search": {"more"}}, "/graph?search=%28active%3A1%29web+server&search=more"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			path := searchPath(config.GraphPrefix, test.search, test.filter)
+			if path != test.expected {
+				t.Fatalf("unexpected path (%s)", path)
+			}
+			if old := urlValuesSearchPath(config.GraphPrefix, test.search, test.filter); path != old {
+				t.Fatalf("path (%s) differs from url.Values (%s)", path, old)
+			}
+		})
+	}
+}
+
+// BenchmarkSearchPath compares searchPath to building search paths with
+// url.Values and url.URL
+func BenchmarkSearchPath(b *testing.B) {
+	search := SearchQueryType("(active:1)web")
+	filter := SearchFilterType{"f_tags_has": {"service:web", "env:prod"}, "f_type": {"http"}}
+
+	b.Run("url.Values", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = urlValuesSearchPath(config.CheckBundlePrefix, &search, &filter)
+		}
+	})
+
+	b.Run("searchPath", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = searchPath(config.CheckBundlePrefix, &search, &filter)
+		}
+	})
+}
+
+func TestSearchJSONSplit(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.RawQuery)
+		mu.Unlock()
+		// a bundle per target, and one matching every target
+		bundles := []CheckBundle{{CID: "/check_bundle/all"}}
+		for _, target := range r.URL.Query()["f_target"] {
+			bundles = append(bundles, CheckBundle{CID: "/check_bundle/" + target, Target: target})
+		}
+		_ = json.NewEncoder(w).Encode(bundles)
+	}))
+	defer server.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	defer func(max int) { maxSearchURLLength = max }(maxSearchURLLength)
+	maxSearchURLLength = len(server.URL) + 80
+
+	targets := []string{}
+	for i := 0; i < 10; i++ {
+		targets = append(targets, fmt.Sprintf("host%d", i))
+	}
+	search := SearchQueryType("web")
+	filter := SearchFilterType{"f_target": targets, "f_type": {"http"}}
+
+	t.Run("split", func(t *testing.T) {
+		queries = nil
+		bundles, err := apih.SearchCheckBundles(&search, &filter)
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if len(queries) < 2 {
+			t.Fatalf("search not split (%q)", queries)
+		}
+		for _, q := range queries {
+			if len(server.URL)+len(config.CheckBundlePrefix)+1+len(q) > maxSearchURLLength {
+				t.Fatalf("search too long (%s)", q)
+			}
+			if !strings.Contains(q, "f_type=http") || !strings.Contains(q, "search=web") {
+				t.Fatalf("search criteria missing (%s)", q)
+			}
+		}
+		cids := []string{}
+		for _, b := range *bundles {
+			cids = append(cids, b.CID)
+		}
+		sort.Strings(cids)
+		expected := []string{"/check_bundle/all"}
+		for _, target := range targets {
+			expected = append(expected, "/check_bundle/"+target)
+		}
+		sort.Strings(expected)
+		if !reflect.DeepEqual(cids, expected) {
+			t.Fatalf("unexpected bundles (%v)", cids)
+		}
+	})
+
+	t.Run("not split", func(t *testing.T) {
+		queries = nil
+		small := SearchFilterType{"f_target": {"host1"}}
+		if _, err := apih.SearchCheckBundles(nil, &small); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if !reflect.DeepEqual(queries, []string{"f_target=host1"}) {
+			t.Fatalf("unexpected queries (%q)", queries)
+		}
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		long := SearchQueryType(strings.Repeat("x", 100))
+		_, err := apih.SearchCheckBundles(&long, nil)
+		if err == nil || !strings.HasSuffix(err.Error(), "no filter to split") {
+			t.Fatalf("unexpected error (%v)", err)
+		}
+		value := SearchFilterType{"f_target": {"host1", strings.Repeat("x", 100)}}
+		_, err = apih.SearchCheckBundles(nil, &value)
+		if err == nil || !strings.Contains(err.Error(), "alone exceeds the URL length limit") {
+			t.Fatalf("unexpected error (%v)", err)
+		}
+	})
+}
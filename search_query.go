@@ -0,0 +1,212 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Search query - build search queries and filters, escaping their values
+
+package apiclient
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// searchSpecial are the characters which cannot be used in unquoted
+	// search values
+	searchSpecial = "()\":= \t\r\n\\"
+	// textSpecial are those which cannot be used in unquoted search text
+	textSpecial = "()\":=\\"
+)
+
+// validSearchField returns true if the name can be used as a search or
+// filter field (e.g. "host", "_cleared_on", "display_name")
+func validSearchField(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '_' || r == '.' || r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// quoteSearchValue returns the value quoted, with quotes and backslashes
+// escaped
+func quoteSearchValue(v string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+}
+
+// SearchBuilder builds a search query (see SearchQueryType), its terms all
+// having to match, e.g.
+//
+//	q, err := NewSearch().Field("host").Eq("web1.example.com").Text("cpu").Query()
+type SearchBuilder struct {
+	terms []string
+	err   error
+}
+
+// SearchField is a field of a search query, to be given a value
+type SearchField struct {
+	s    *SearchBuilder
+	name string
+}
+
+// NewSearch returns a search query builder.
+func NewSearch() *SearchBuilder {
+	return &SearchBuilder{}
+}
+
+// Field adds a term for the field with the passed name (e.g. "host"),
+// completed by giving it a value.
+func (s *SearchBuilder) Field(name string) *SearchField {
+	if !validSearchField(name) && s.err == nil {
+		s.err = errors.Errorf("invalid search field (%s)", name)
+	}
+	return &SearchField{s: s, name: name}
+}
+
+// Eq matches objects whose field is the value, e.g. (host="web1").
+func (f *SearchField) Eq(value string) *SearchBuilder {
+	f.s.terms = append(f.s.terms, "("+f.name+"="+quoteSearchValue(value)+")")
+	return f.s
+}
+
+// Match matches objects whose field matches the value as the API matches
+// unquoted values, e.g. (active:1). The value is quoted if it contains
+// spaces or characters special to search queries.
+func (f *SearchField) Match(value string) *SearchBuilder {
+	if value == "" || strings.ContainsAny(value, searchSpecial) {
+		value = quoteSearchValue(value)
+	}
+	f.s.terms = append(f.s.terms, "("+f.name+":"+value+")")
+	return f.s
+}
+
+// Text matches objects containing the text, quoted if it contains
+// characters special to search queries (other than spaces).
+func (s *SearchBuilder) Text(text string) *SearchBuilder {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return s
+	}
+	if strings.ContainsAny(text, textSpecial) {
+		text = quoteSearchValue(text)
+	}
+	s.terms = append(s.terms, text)
+	return s
+}
+
+// And adds the terms of the other searches, all of which have to match.
+func (s *SearchBuilder) And(others ...*SearchBuilder) *SearchBuilder {
+	for _, o := range others {
+		if o == nil {
+			continue
+		}
+		if o.err != nil && s.err == nil {
+			s.err = o.err
+		}
+		s.terms = append(s.terms, o.terms...)
+	}
+	return s
+}
+
+// String returns the search query.
+func (s *SearchBuilder) String() string {
+	var b strings.Builder
+	for i, t := range s.terms {
+		// free text following a term or text needs separating
+		if i > 0 && !strings.HasPrefix(t, "(") {
+			b.WriteByte(' ')
+		}
+		b.WriteString(t)
+	}
+	return b.String()
+}
+
+// Query returns the search query, to be passed to the Search methods (e.g.
+// SearchCheckBundles), or an error if a field name is invalid.
+func (s *SearchBuilder) Query() (*SearchQueryType, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	q := SearchQueryType(s.String())
+	return &q, nil
+}
+
+// FilterBuilder builds search filter criteria (see SearchFilterType), the
+// criteria of different fields all having to match, e.g.
+//
+//	f, err := NewFilter().Field("_cleared_on").IsNull().Field("tags").Has("env:prod").Filter()
+type FilterBuilder struct {
+	filter SearchFilterType
+	err    error
+}
+
+// FilterField is a field of search filter criteria, to be given a value
+type FilterField struct {
+	f    *FilterBuilder
+	name string
+}
+
+// NewFilter returns a search filter builder.
+func NewFilter() *FilterBuilder {
+	return &FilterBuilder{filter: SearchFilterType{}}
+}
+
+// Field adds criteria for the field with the passed (json) name (e.g.
+// "_cleared_on" for f__cleared_on), completed by giving it a value.
+func (f *FilterBuilder) Field(name string) *FilterField {
+	if !validSearchField(name) && f.err == nil {
+		f.err = errors.Errorf("invalid filter field (%s)", name)
+	}
+	return &FilterField{f: f, name: name}
+}
+
+// add adds the values of the filter key
+func (ff *FilterField) add(key string, values []string) *FilterBuilder {
+	if len(values) == 0 && ff.f.err == nil {
+		ff.f.err = errors.Errorf("invalid filter %s (no values)", key)
+	}
+	ff.f.filter[key] = append(ff.f.filter[key], values...)
+	return ff.f
+}
+
+// Eq matches objects whose field is any of the values.
+func (ff *FilterField) Eq(values ...string) *FilterBuilder {
+	return ff.add("f_"+ff.name, values)
+}
+
+// IsNull matches objects whose field is not set (e.g. alerts not cleared).
+func (ff *FilterField) IsNull() *FilterBuilder {
+	return ff.add("f_"+ff.name, []string{"null"})
+}
+
+// Has matches objects whose field, a list (e.g. tags), has any of the
+// values.
+func (ff *FilterField) Has(values ...string) *FilterBuilder {
+	return ff.add("f_"+ff.name+"_has", values)
+}
+
+// Wildcard matches objects whose field matches any of the patterns, "*"
+// matching any text.
+func (ff *FilterField) Wildcard(patterns ...string) *FilterBuilder {
+	return ff.add("f_"+ff.name+"_wildcard", patterns)
+}
+
+// Filter returns the filter criteria, to be passed to the Search methods
+// (e.g. SearchAlerts), or an error if a field name or its values are
+// invalid. Values are escaped when the search is requested.
+func (f *FilterBuilder) Filter() (*SearchFilterType, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	filter := make(SearchFilterType, len(f.filter))
+	for k, v := range f.filter {
+		filter[k] = append([]string{}, v...)
+	}
+	return &filter, nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
+)
+
+func TestSearchBuilder(t *testing.T) {
+	tests := []struct {
+		id          string
+		search      *SearchBuilder
+		expected    string
+		expectedErr string
+	}{
+		{"empty", NewSearch(), "", ""},
+		{"eq", NewSearch().Field("host").Eq("web1.example.com"), `(host="web1.example.com")`, ""},
+		{"eq escaped", NewSearch().Field("display_name").Eq(`say "hi" \o/`), `(display_name="say \"hi\" \\o/")`, ""},
+		{"match", NewSearch().Field("active").Match("1").Text("web server"), `(active:1) web server`, ""},
+		{"match quoted", NewSearch().Field("type").Match("http json"), `(type:"http json")`, ""},
+		{"text quoted", NewSearch().Text("(cpu)"), `"(cpu)"`, ""},
+		{"and", NewSearch().Text("cpu").And(NewSearch().Field("host").Eq("a"), nil), `cpu(host="a")`, ""},
+		{"invalid field", NewSearch().Field("host)(x").Eq("a"), "", "invalid search field (host)(x)"},
+		{"invalid and", NewSearch().And(NewSearch().Field("").Eq("a")), "", "invalid search field ()"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			q, err := test.search.Query()
+			if test.expectedErr != "" {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%v)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if string(*q) != test.expected {
+				t.Fatalf("unexpected query (%s)", *q)
+			}
+		})
+	}
+}
+
+func TestFilterBuilder(t *testing.T) {
+	tests := []struct {
+		id          string
+		filter      *FilterBuilder
+		expected    SearchFilterType
+		expectedErr string
+	}{
+		{"empty", NewFilter(), SearchFilterType{}, ""},
+		{"null", NewFilter().Field("_cleared_on").IsNull(), SearchFilterType{"f__cleared_on": {"null"}}, ""},
+		{"eq", NewFilter().Field("type").Eq("http", "json").Field("type").Eq("ping_icmp"), SearchFilterType{"f_type": {"http", "json", "ping_icmp"}}, ""},
+		{"has", NewFilter().Field("tags").Has("env:prod").Field("title").Wildcard("web*"), SearchFilterType{"f_tags_has": {"env:prod"}, "f_title_wildcard": {"web*"}}, ""},
+		{"no values", NewFilter().Field("type").Eq(), nil, "invalid filter f_type (no values)"},
+		{"invalid field", NewFilter().Field("a&b").IsNull(), nil, "invalid filter field (a&b)"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			f, err := test.filter.Filter()
+			if test.expectedErr != "" {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%v)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if !reflect.DeepEqual(*f, test.expected) {
+				t.Fatalf("unexpected filter (%v)", *f)
+			}
+		})
+	}
+}
+
+func TestSearchBuilderRequest(t *testing.T) {
+	fake, err := fakecirconus.New(&fakecirconus.Config{Fixtures: map[string]interface{}{
+		"/alert/1": Alert{CID: "/alert/1"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", URL: fake.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	q, err := NewSearch().Field("host").Eq("a&b").Query()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	f, err := NewFilter().Field("_cleared_on").IsNull().Filter()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if _, err := apih.SearchAlerts(q, f); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	reqs := fake.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("unexpected requests (%v)", reqs)
+	}
+	if expected := "/alert?f__cleared_on=null&search=%28host%3D%22a%26b%22%29"; reqs[0].URL != expected {
+		t.Fatalf("unexpected request (%s)", reqs[0].URL)
+	}
+}
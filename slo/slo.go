@@ -0,0 +1,286 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package slo computes service level objective availability, burn rates and
+// remaining error budget from good and total event queries (CAQL or check
+// metric data).
+//
+//	s, _ := slo.New(&slo.Config{
+//		API:       apih,
+//		Good:      slo.Query{CAQL: `find:counter("requests", "and(status:2*)") | op:sum()`},
+//		Total:     slo.Query{CAQL: `find:counter("requests") | op:sum()`},
+//		Objective: 0.999,
+//	})
+//	report, err := s.Compute(time.Now())
+//	fmt.Printf("%.4f available, %.1f%% budget left\n", report.Availability, report.BudgetRemaining*100)
+package slo
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/pkg/errors"
+)
+
+const defaultBudgetPeriod = 30 * 24 * time.Hour
+
+// DefaultWindows are the burn rate windows used when none are configured
+var DefaultWindows = []time.Duration{time.Hour, 6 * time.Hour, 24 * time.Hour, 72 * time.Hour}
+
+// Query defines how event counts are retrieved, either a CAQL query or a
+// check metric. CAQL queries should return the number of events in each
+// period as their first output series. Check metric data is rolled up with
+// Function (default counter); counter and derivative values, which are per
+// second rates, are multiplied by the period length to count events.
+type Query struct {
+	CAQL string
+
+	CheckCID   string
+	MetricName string
+	Function   apiclient.DataFunction
+}
+
+// Config defines the SLO
+type Config struct {
+	// API is used to run the queries, required
+	API *apiclient.API
+
+	// Good and Total define the good and total event queries, required
+	Good  Query
+	Total Query
+
+	// Objective defines the target ratio of good to total events, 0 < Objective < 1, required
+	Objective float64
+
+	// BudgetPeriod defines the error budget period ending at the time
+	// computed - default 30 days
+	BudgetPeriod time.Duration
+
+	// Windows define the burn rate windows - default DefaultWindows
+	Windows []time.Duration
+}
+
+// SLO computes reports for a service level objective
+type SLO struct {
+	api          *apiclient.API
+	good         Query
+	total        Query
+	objective    float64
+	budgetPeriod time.Duration
+	windows      []time.Duration
+}
+
+// Events defines the good and total events over a window
+type Events struct {
+	Good  float64
+	Total float64
+}
+
+// Availability returns the ratio of good to total events, 1 when there were none.
+func (e Events) Availability() float64 {
+	if e.Total <= 0 {
+		return 1
+	}
+	return e.Good / e.Total
+}
+
+// BurnRate defines the rate the error budget is consumed over a window,
+// relative to the rate which exactly exhausts it over the budget period
+type BurnRate struct {
+	Window time.Duration
+	Events
+	Rate float64
+}
+
+// Report defines the state of the SLO at a point in time
+type Report struct {
+	Time         time.Time
+	Objective    float64
+	BudgetPeriod time.Duration
+
+	// Events over the budget period
+	Events
+
+	// Availability over the budget period
+	Availability float64
+
+	// ErrorBudget is the number of bad events allowed over the budget period
+	ErrorBudget float64
+
+	// BudgetRemaining is the fraction of the error budget not yet consumed,
+	// negative when the budget is exhausted
+	BudgetRemaining float64
+
+	// BurnRates per window, shortest window first
+	BurnRates []BurnRate
+}
+
+// New returns an SLO for the passed config.
+func New(cfg *Config) (*SLO, error) {
+	if cfg == nil {
+		return nil, errors.New("invalid slo config (nil)")
+	}
+	if cfg.API == nil {
+		return nil, errors.New("invalid slo API (nil)")
+	}
+	if cfg.Objective <= 0 || cfg.Objective >= 1 {
+		return nil, errors.Errorf("invalid slo objective (%v)", cfg.Objective)
+	}
+	if err := validateQuery(cfg.Good); err != nil {
+		return nil, errors.Wrap(err, "good")
+	}
+	if err := validateQuery(cfg.Total); err != nil {
+		return nil, errors.Wrap(err, "total")
+	}
+
+	budgetPeriod := cfg.BudgetPeriod
+	if budgetPeriod == 0 {
+		budgetPeriod = defaultBudgetPeriod
+	}
+	if budgetPeriod < apiclient.DataRollupPeriods[0] {
+		return nil, errors.Errorf("invalid slo budget period (%s)", budgetPeriod)
+	}
+
+	windows := cfg.Windows
+	if len(windows) == 0 {
+		windows = DefaultWindows
+	}
+	windows = append([]time.Duration{}, windows...)
+	sort.Slice(windows, func(i, j int) bool { return windows[i] < windows[j] })
+	for _, w := range windows {
+		if w < apiclient.DataRollupPeriods[0] || w > budgetPeriod {
+			return nil, errors.Errorf("invalid slo window (%s)", w)
+		}
+	}
+
+	return &SLO{
+		api:          cfg.API,
+		good:         cfg.Good,
+		total:        cfg.Total,
+		objective:    cfg.Objective,
+		budgetPeriod: budgetPeriod,
+		windows:      windows,
+	}, nil
+}
+
+// Compute returns the report for the budget period and burn rate windows
+// ending at the passed time.
+func (s *SLO) Compute(end time.Time) (*Report, error) {
+	events, err := s.Events(end.Add(-s.budgetPeriod), end)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Report{
+		Time:         end,
+		Objective:    s.objective,
+		BudgetPeriod: s.budgetPeriod,
+		Events:       events,
+		Availability: events.Availability(),
+		ErrorBudget:  (1 - s.objective) * events.Total,
+	}
+	r.BudgetRemaining = 1 - (1-r.Availability)/(1-s.objective)
+
+	for _, w := range s.windows {
+		e, err := s.Events(end.Add(-w), end)
+		if err != nil {
+			return nil, errors.Wrapf(err, "window %s", w)
+		}
+		r.BurnRates = append(r.BurnRates, BurnRate{
+			Window: w,
+			Events: e,
+			Rate:   (1 - e.Availability()) / (1 - s.objective),
+		})
+	}
+
+	return r, nil
+}
+
+// Events returns the good and total events between start and end.
+func (s *SLO) Events(start, end time.Time) (Events, error) {
+	good, err := s.count(s.good, start, end)
+	if err != nil {
+		return Events{}, errors.Wrap(err, "good events")
+	}
+	total, err := s.count(s.total, start, end)
+	if err != nil {
+		return Events{}, errors.Wrap(err, "total events")
+	}
+	return Events{Good: good, Total: total}, nil
+}
+
+// count returns the number of events of the query between start and end
+func (s *SLO) count(q Query, start, end time.Time) (float64, error) {
+	if q.CAQL != "" {
+		result, err := s.api.CAQL(q.CAQL, start, end, period(start, end))
+		if err != nil {
+			return 0, err
+		}
+		series := result.Series()
+		if len(series) == 0 {
+			return 0, nil
+		}
+		sum := 0.0
+		for _, p := range series[0].Points {
+			if p.Value != nil && !math.IsNaN(*p.Value) {
+				sum += *p.Value
+			}
+		}
+		return sum, nil
+	}
+
+	function := q.Function
+	if function == "" {
+		function = apiclient.DataFunctionCounter
+	}
+	cid := q.CheckCID
+	data, err := s.api.FetchDataWithOptions(&cid, q.MetricName, &apiclient.DataOptions{
+		Start:         start,
+		End:           end,
+		Period:        period(start, end),
+		ValueFunction: function,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	scale := 1.0
+	switch function {
+	case apiclient.DataFunctionCounter, apiclient.DataFunctionDerivative:
+		scale = period(start, end).Seconds()
+	}
+	sum := 0.0
+	for _, v := range data.Values() {
+		sum += v * scale
+	}
+	return sum, nil
+}
+
+// period returns the shortest rollup period keeping the range within the
+// default maximum number of data points
+func period(start, end time.Time) time.Duration {
+	span := end.Sub(start)
+	for _, p := range apiclient.DataRollupPeriods {
+		if (span+p-1)/p <= apiclient.DefaultDataMaxPoints {
+			return p
+		}
+	}
+	return apiclient.DataRollupPeriods[len(apiclient.DataRollupPeriods)-1]
+}
+
+func validateQuery(q Query) error {
+	switch {
+	case q.CAQL != "" && q.CheckCID != "":
+		return errors.New("invalid slo query, CAQL and check metric are exclusive")
+	case q.CAQL != "":
+		return apiclient.ValidateCAQL(q.CAQL)
+	case q.CheckCID == "":
+		return errors.New("invalid slo query (none)")
+	case q.MetricName == "":
+		return errors.New("invalid slo query metric name (none)")
+	}
+	return nil
+}
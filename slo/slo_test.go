@@ -0,0 +1,183 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+)
+
+// testServer answers caql queries with a constant number of events per
+// period (100 total, 99 good, 90 good in the last hour) and check metric data
+// with a constant counter rate (2/s total, 1.98/s good)
+func testServer(end time.Time) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		start, _ := strconv.ParseInt(q.Get("start"), 10, 64)
+		stop, _ := strconv.ParseInt(q.Get("end"), 10, 64)
+		period, _ := strconv.ParseInt(q.Get("period"), 10, 64)
+
+		switch {
+		case r.URL.Path == "/caql":
+			rows := [][]interface{}{}
+			for ts := start; ts < stop; ts += period {
+				v := 100.0
+				if strings.Contains(q.Get("query"), "good") {
+					v = 99
+					if ts >= end.Add(-time.Hour).Unix() {
+						v = 90
+					}
+				}
+				rows = append(rows, []interface{}{ts, []interface{}{v}})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"_meta": []map[string]string{{"kind": "numeric", "label": "requests"}},
+				"_data": rows,
+			})
+		case strings.HasPrefix(r.URL.Path, "/data/"):
+			rate := 2.0
+			if strings.HasSuffix(r.URL.Path, "_good") {
+				rate = 1.98
+			}
+			rows := [][]interface{}{}
+			for ts := start; ts < stop; ts += period {
+				rows = append(rows, []interface{}{ts, map[string]float64{"counter": rate, "value": 1}})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": rows})
+		default:
+			w.WriteHeader(404)
+			fmt.Fprintln(w, "not found")
+		}
+	}))
+}
+
+func testAPI(t *testing.T, url string) *apiclient.API {
+	apih, err := apiclient.New(&apiclient.Config{TokenKey: "abc123", TokenApp: "test", URL: url})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	return apih
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestNew(t *testing.T) {
+	apih := testAPI(t, "http://127.0.0.1:1")
+	good := Query{CAQL: `find("good")`}
+	total := Query{CAQL: `find("total")`}
+
+	tests := []struct {
+		id          string
+		cfg         *Config
+		expectedErr string
+	}{
+		{"invalid (nil)", nil, "invalid slo config (nil)"},
+		{"invalid (api)", &Config{Good: good, Total: total, Objective: 0.99}, "invalid slo API (nil)"},
+		{"invalid (objective)", &Config{API: apih, Good: good, Total: total, Objective: 1}, "invalid slo objective (1)"},
+		{"invalid (good none)", &Config{API: apih, Total: total, Objective: 0.99}, "good: invalid slo query (none)"},
+		{"invalid (total caql)", &Config{API: apih, Good: good, Total: Query{CAQL: `find("total"`}, Objective: 0.99}, `total: caql syntax error at line 1, column 5: unclosed "(" in call of find`},
+		{"invalid (exclusive)", &Config{API: apih, Good: Query{CAQL: "A", CheckCID: "/check/1"}, Total: total, Objective: 0.99}, "good: invalid slo query, CAQL and check metric are exclusive"},
+		{"invalid (metric)", &Config{API: apih, Good: Query{CheckCID: "/check/1"}, Total: total, Objective: 0.99}, "good: invalid slo query metric name (none)"},
+		{"invalid (window)", &Config{API: apih, Good: good, Total: total, Objective: 0.99, BudgetPeriod: time.Hour, Windows: []time.Duration{2 * time.Hour}}, "invalid slo window (2h0m0s)"},
+		{"valid", &Config{API: apih, Good: good, Total: total, Objective: 0.99}, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			_, err := New(test.cfg)
+			if test.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			} else if err.Error() != test.expectedErr {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+		})
+	}
+}
+
+func TestComputeCAQL(t *testing.T) {
+	end := time.Unix(1483033200, 0)
+	server := testServer(end)
+	defer server.Close()
+
+	s, err := New(&Config{
+		API:          testAPI(t, server.URL),
+		Good:         Query{CAQL: `find("good")`},
+		Total:        Query{CAQL: `find("total")`},
+		Objective:    0.995,
+		BudgetPeriod: 24 * time.Hour,
+		Windows:      []time.Duration{6 * time.Hour, time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	r, err := s.Compute(end)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	// 1440 one minute periods, the last 60 at 90% good
+	if r.Total != 144000 || r.Good != 1380*99+60*90 {
+		t.Fatalf("unexpected events (%#v)", r.Events)
+	}
+	if !approx(r.ErrorBudget, 720) || !approx(r.BudgetRemaining, 1-(144000-r.Good)/720) {
+		t.Fatalf("unexpected budget (%v, %v)", r.ErrorBudget, r.BudgetRemaining)
+	}
+	if len(r.BurnRates) != 2 || r.BurnRates[0].Window != time.Hour || r.BurnRates[1].Window != 6*time.Hour {
+		t.Fatalf("unexpected burn rates (%#v)", r.BurnRates)
+	}
+	if !approx(r.BurnRates[0].Rate, 20) || !approx(r.BurnRates[1].Rate, (1-(300*99+60*90)/36000.0)/0.005) {
+		t.Fatalf("unexpected burn rates (%#v)", r.BurnRates)
+	}
+}
+
+func TestComputeData(t *testing.T) {
+	end := time.Unix(1483033200, 0)
+	server := testServer(end)
+	defer server.Close()
+
+	s, err := New(&Config{
+		API:          testAPI(t, server.URL),
+		Good:         Query{CheckCID: "/check/1234", MetricName: "good"},
+		Total:        Query{CheckCID: "/check/1234", MetricName: "total"},
+		Objective:    0.995,
+		BudgetPeriod: 3 * 24 * time.Hour,
+		Windows:      []time.Duration{time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	r, err := s.Compute(end)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	// 3 days at 2/s, rolled up into 5m periods
+	if !approx(r.Total, 3*86400*2) || !approx(r.Availability, 0.99) || !approx(r.BudgetRemaining, -1) {
+		t.Fatalf("unexpected report (%#v)", r)
+	}
+	if !approx(r.BurnRates[0].Total, 7200) || !approx(r.BurnRates[0].Rate, 2) {
+		t.Fatalf("unexpected burn rate (%#v)", r.BurnRates[0])
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package submission
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+)
+
+// ConnectionErrorKind classifies a failure to connect to a broker
+type ConnectionErrorKind string
+
+// Kinds of connection errors, see ConnectionError
+const (
+	// ConnectionCAMismatch - the broker's certificate is not signed by (or
+	// not valid for) the trusted CA, the CA or the broker needs to be
+	// provisioned again
+	ConnectionCAMismatch ConnectionErrorKind = "ca_mismatch"
+	// ConnectionCNMismatch - the broker's certificate is not for the
+	// expected CN, the broker needs to be provisioned again (or the check
+	// bundle moved to the broker serving the submission URL)
+	ConnectionCNMismatch ConnectionErrorKind = "cn_mismatch"
+	// ConnectionTimeout - the broker did not respond in time, retry
+	ConnectionTimeout ConnectionErrorKind = "timeout"
+	// ConnectionRefused - the broker refused the connection, retry, the
+	// broker may be down
+	ConnectionRefused ConnectionErrorKind = "refused"
+	// ConnectionOther - any other failure
+	ConnectionOther ConnectionErrorKind = "other"
+)
+
+// ConnectionError is returned when a submission fails to reach the broker,
+// its Kind tells apart failures worth retrying from those which need the
+// broker provisioned again or a human.
+type ConnectionError struct {
+	Kind ConnectionErrorKind
+	Host string // broker host (and port) of the submission URL
+	Err  error  // underlying error
+}
+
+// Error returns the error message with its kind.
+func (e *ConnectionError) Error() string {
+	return fmt.Sprintf("submitting metrics to broker %s (%s): %s", e.Host, e.Kind, e.Err)
+}
+
+// Temporary returns true if the failure may not recur, so the submission
+// can be retried (timeouts and refused connections).
+func (e *ConnectionError) Temporary() bool {
+	return e.Kind == ConnectionTimeout || e.Kind == ConnectionRefused
+}
+
+// connectionError classifies the error of a request to the broker at host
+func connectionError(host string, err error) *ConnectionError {
+	// the url.Error's URL holds the submission secret, keep only its cause
+	if ue, ok := err.(*url.Error); ok {
+		err = ue.Err
+	}
+	return &ConnectionError{Kind: connectionErrorKind(err), Host: host, Err: err}
+}
+
+// connectionErrorKind returns the kind of the first error in the chain of
+// wrapped errors which can be classified
+func connectionErrorKind(err error) ConnectionErrorKind {
+	for err != nil {
+		switch e := err.(type) {
+		case x509.UnknownAuthorityError, *x509.UnknownAuthorityError,
+			x509.CertificateInvalidError, *x509.CertificateInvalidError:
+			return ConnectionCAMismatch
+		case x509.HostnameError, *x509.HostnameError:
+			return ConnectionCNMismatch
+		case syscall.Errno:
+			if e == syscall.ECONNREFUSED {
+				return ConnectionRefused
+			}
+		case net.Error:
+			if e.Timeout() {
+				return ConnectionTimeout
+			}
+		}
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = u.Unwrap()
+	}
+	return ConnectionOther
+}
@@ -0,0 +1,75 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package submission
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubmitConnectionErrors(t *testing.T) {
+	var received Metrics
+	broker := testBrokerServer(&received)
+	defer broker.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		fmt.Fprintln(w, `{"stats":1}`)
+	}))
+	defer slow.Close()
+
+	// a closed listener's address refuses connections
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	refused := l.Addr().String()
+	l.Close()
+
+	trusted := x509.NewCertPool()
+	trusted.AddCert(broker.Certificate())
+
+	tests := []struct {
+		id        string
+		url       string
+		tlsConfig *tls.Config
+		timeout   time.Duration
+		kind      ConnectionErrorKind
+		temporary bool
+	}{
+		{"ca mismatch", broker.URL, &tls.Config{RootCAs: x509.NewCertPool()}, 0, ConnectionCAMismatch, false},
+		{"cn mismatch", broker.URL, &tls.Config{RootCAs: trusted, ServerName: "broker.example.net"}, 0, ConnectionCNMismatch, false},
+		{"timeout", slow.URL, nil, 50 * time.Millisecond, ConnectionTimeout, true},
+		{"refused", "http://" + refused, nil, 0, ConnectionRefused, true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			s, err := New(&Config{CheckBundle: testCheckBundle(test.url + "/module/httptrap/abc/secret"), TLSConfig: test.tlsConfig, Timeout: test.timeout})
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			_, err = s.Submit(Metrics{"requests": Numeric(1)})
+			cerr, ok := err.(*ConnectionError)
+			if !ok {
+				t.Fatalf("unexpected error (%#v)", err)
+			}
+			if cerr.Kind != test.kind || cerr.Temporary() != test.temporary {
+				t.Fatalf("unexpected kind (%s, %t): %s", cerr.Kind, cerr.Temporary(), cerr)
+			}
+			if strings.Contains(cerr.Error(), "secret") {
+				t.Fatalf("submission secret in error (%s)", cerr)
+			}
+		})
+	}
+}
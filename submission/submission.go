@@ -0,0 +1,253 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package submission sends metrics to the broker submission URL of an HTTPTrap
+// check bundle.
+//
+//	bundle, _ := apih.FetchCheckBundle(&cid)
+//	s, _ := submission.New(&submission.Config{API: apih, CheckBundle: bundle})
+//	_, err := s.Submit(submission.Metrics{"requests": submission.Numeric(42)})
+package submission
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// Metric types, see Metric.Type
+const (
+	MetricTypeNumeric   = "n"
+	MetricTypeText      = "s"
+	MetricTypeHistogram = "h"
+)
+
+const (
+	httpTrapCheckType = "httptrap"
+	caCertPath        = "/pki/ca.crt"
+	defaultTimeout    = 10 * time.Second
+)
+
+// Metric defines a single metric value in an HTTPTrap submission
+type Metric struct {
+	Type  string      `json:"_type"`  // string
+	Value interface{} `json:"_value"` // number, string, or [] of histogram bins
+}
+
+// Metrics defines the metrics in a submission, keyed by metric name
+type Metrics map[string]Metric
+
+// Numeric returns a numeric metric.
+func Numeric(v float64) Metric {
+	return Metric{Type: MetricTypeNumeric, Value: v}
+}
+
+// Text returns a text metric.
+func Text(v string) Metric {
+	return Metric{Type: MetricTypeText, Value: v}
+}
+
+// Histogram returns a histogram metric, with bins in circllhist text form
+// (e.g. "H[1.2e+00]=3").
+func Histogram(h *apiclient.Histogram) Metric {
+	bins := []string{}
+	if h != nil {
+		for _, b := range h.Bins() {
+			bins = append(bins, fmt.Sprintf("%s=%d", b.String(), h.BinCount(b)))
+		}
+	}
+	return Metric{Type: MetricTypeHistogram, Value: bins}
+}
+
+// Config defines the submission configuration
+type Config struct {
+	// API is used to retrieve the broker CA certificate and CN, required
+	// for https submission URLs unless TLSConfig is set
+	API *apiclient.API
+
+	// CheckBundle defines the HTTPTrap check bundle to submit to, required
+	CheckBundle *apiclient.CheckBundle
+
+	// TLSConfig defines a custom tls configuration to use when submitting,
+	// overriding the broker CA retrieved via the API
+	TLSConfig *tls.Config
+
+	// Timeout defines the submission request timeout - default 10s
+	Timeout time.Duration
+}
+
+// Submitter sends metrics to a check bundle submission URL
+type Submitter struct {
+	client *http.Client
+	url    string
+	host   string
+}
+
+// New returns a Submitter for the HTTPTrap check bundle in the passed config.
+func New(cfg *Config) (*Submitter, error) {
+	if cfg == nil {
+		return nil, errors.New("invalid submission config (nil)")
+	}
+	if cfg.CheckBundle == nil {
+		return nil, errors.New("invalid submission check bundle (nil)")
+	}
+	if cfg.CheckBundle.Type != httpTrapCheckType {
+		return nil, errors.Errorf("invalid submission check bundle type (%s), expected %s", cfg.CheckBundle.Type, httpTrapCheckType)
+	}
+
+	submissionURL, ok := cfg.CheckBundle.Config[config.SubmissionURL]
+	if !ok || submissionURL == "" {
+		return nil, errors.Errorf("invalid submission check bundle %s, submission URL (none)", cfg.CheckBundle.CID)
+	}
+	u, err := url.Parse(submissionURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing submission URL")
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   timeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout: timeout,
+	}
+
+	if u.Scheme == "https" {
+		tlsConfig := cfg.TLSConfig
+		if tlsConfig == nil {
+			if cfg.API == nil {
+				return nil, errors.New("invalid submission config, API or TLSConfig required for https")
+			}
+			tlsConfig, err = brokerTLSConfig(cfg.API, cfg.CheckBundle, u)
+			if err != nil {
+				return nil, err
+			}
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &Submitter{
+		client: &http.Client{Transport: transport, Timeout: timeout},
+		url:    submissionURL,
+		host:   u.Host,
+	}, nil
+}
+
+// URL returns the submission URL.
+func (s *Submitter) URL() string {
+	return s.url
+}
+
+// Submit sends the metrics to the broker, returning the number of metrics the
+// broker accepted. Failures to reach the broker are returned as a
+// *ConnectionError.
+func (s *Submitter) Submit(metrics Metrics) (int, error) {
+	if len(metrics) == 0 {
+		return 0, nil
+	}
+
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		return 0, errors.Wrap(err, "encoding metrics")
+	}
+
+	req, err := http.NewRequest("PUT", s.url, bytes.NewReader(data))
+	if err != nil {
+		return 0, errors.Wrap(err, "creating submission request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, connectionError(s.host, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, errors.Wrap(err, "reading submission response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, errors.Errorf("submitting metrics - response: %d %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result struct {
+		Stats int `json:"stats"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, errors.Wrap(err, "parsing submission response")
+	}
+
+	return result.Stats, nil
+}
+
+// brokerTLSConfig returns a tls configuration trusting the broker CA and
+// expecting the CN of the broker serving the submission URL
+func brokerTLSConfig(api *apiclient.API, bundle *apiclient.CheckBundle, u *url.URL) (*tls.Config, error) {
+	data, err := api.Get(caCertPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching broker CA certificate")
+	}
+
+	var cert struct {
+		Contents string `json:"contents"`
+	}
+	if err := json.Unmarshal(data, &cert); err != nil {
+		return nil, errors.Wrap(err, "parsing broker CA certificate")
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(cert.Contents)) {
+		return nil, errors.New("invalid broker CA certificate (no certificates)")
+	}
+
+	cn, err := brokerCN(api, bundle, u.Hostname())
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		RootCAs:    pool,
+		ServerName: cn,
+	}, nil
+}
+
+// brokerCN returns the CN of the check bundle broker serving host
+func brokerCN(api *apiclient.API, bundle *apiclient.CheckBundle, host string) (string, error) {
+	for _, brokerCID := range bundle.Brokers {
+		brokerCID := brokerCID
+		broker, err := api.FetchBroker(apiclient.CIDType(&brokerCID))
+		if err != nil {
+			return "", errors.Wrap(err, "fetching submission broker")
+		}
+		for _, detail := range broker.Details {
+			if (detail.IP != nil && *detail.IP == host) ||
+				(detail.ExternalHost != nil && *detail.ExternalHost == host) ||
+				detail.CN == host {
+				return detail.CN, nil
+			}
+		}
+	}
+
+	return "", errors.Errorf("no broker for check bundle %s serves submission host %s", bundle.CID, host)
+}
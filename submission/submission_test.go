@@ -0,0 +1,157 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package submission
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/circonus-labs/go-apiclient/config"
+)
+
+func testBrokerServer(received *Metrics) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/module/httptrap/abc/secret" {
+			w.WriteHeader(404)
+			fmt.Fprintln(w, "not found")
+			return
+		}
+		defer r.Body.Close()
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			panic(err)
+		}
+		if err := json.Unmarshal(b, received); err != nil {
+			w.WriteHeader(400)
+			fmt.Fprintln(w, err)
+			return
+		}
+		w.WriteHeader(200)
+		fmt.Fprintf(w, `{"stats":%d}`, len(*received))
+	}))
+}
+
+func testAPIServer(broker *httptest.Server) *httptest.Server {
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: broker.Certificate().Raw})
+	u, _ := url.Parse(broker.URL)
+	ip := u.Hostname()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ret interface{}
+		switch r.URL.Path {
+		case "/pki/ca.crt":
+			ret = map[string]string{"contents": string(caPEM)}
+		case "/broker/1":
+			ret = apiclient.Broker{
+				CID:     "/broker/1",
+				Details: []apiclient.BrokerDetail{{CN: "example.com", IP: &ip}},
+			}
+		default:
+			w.WriteHeader(404)
+			fmt.Fprintln(w, "not found")
+			return
+		}
+		b, _ := json.Marshal(ret)
+		w.WriteHeader(200)
+		fmt.Fprintln(w, string(b))
+	}))
+}
+
+func testCheckBundle(submissionURL string) *apiclient.CheckBundle {
+	return &apiclient.CheckBundle{
+		Brokers: []string{"/broker/1"},
+		CID:     "/check_bundle/1234",
+		Config:  apiclient.CheckBundleConfig{config.SubmissionURL: submissionURL},
+		Type:    "httptrap",
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		id          string
+		cfg         *Config
+		expectedErr string
+	}{
+		{"invalid (nil)", nil, "invalid submission config (nil)"},
+		{"invalid (bundle)", &Config{}, "invalid submission check bundle (nil)"},
+		{"invalid (type)", &Config{CheckBundle: &apiclient.CheckBundle{Type: "json"}}, "invalid submission check bundle type (json), expected httptrap"},
+		{"invalid (url)", &Config{CheckBundle: testCheckBundle("")}, "invalid submission check bundle /check_bundle/1234, submission URL (none)"},
+		{"invalid (tls)", &Config{CheckBundle: testCheckBundle("https://127.0.0.1/module/httptrap/abc/secret")}, "invalid submission config, API or TLSConfig required for https"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			_, err := New(test.cfg)
+			if err == nil {
+				t.Fatal("expected error")
+			} else if err.Error() != test.expectedErr {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+		})
+	}
+}
+
+func TestSubmit(t *testing.T) {
+	var received Metrics
+	broker := testBrokerServer(&received)
+	defer broker.Close()
+	apiServer := testAPIServer(broker)
+	defer apiServer.Close()
+
+	apih, err := apiclient.New(&apiclient.Config{TokenKey: "abc123", URL: apiServer.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	s, err := New(&Config{API: apih, CheckBundle: testCheckBundle(broker.URL + "/module/httptrap/abc/secret")})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if n, err := s.Submit(nil); err != nil || n != 0 {
+		t.Fatalf("unexpected result (%d, %v)", n, err)
+	}
+
+	h := apiclient.NewHistogram()
+	h.Record(1.2, 3)
+
+	n, err := s.Submit(Metrics{
+		"requests": Numeric(42),
+		"version":  Text("1.2.3"),
+		"latency":  Histogram(h),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if n != 3 {
+		t.Fatalf("expected 3 stats, got %d", n)
+	}
+
+	expected := Metrics{
+		"requests": {Type: "n", Value: float64(42)},
+		"version":  {Type: "s", Value: "1.2.3"},
+		"latency":  {Type: "h", Value: []interface{}{"H[1.2e+00]=3"}},
+	}
+	if !reflect.DeepEqual(received, expected) {
+		t.Fatalf("unexpected submission (%#v)", received)
+	}
+
+	bad, err := New(&Config{API: apih, CheckBundle: testCheckBundle(broker.URL + "/module/httptrap/abc/wrong")})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if _, err := bad.Submit(Metrics{"requests": Numeric(1)}); err == nil {
+		t.Fatal("expected error")
+	}
+}
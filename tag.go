@@ -0,0 +1,149 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Tag API support - Fetch and Search
+// See: https://login.circonus.com/resources/api/calls/tag
+// Note: tags are created and deleted by tagging other objects
+
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// Tag defines a tag in use on the account. See https://login.circonus.com/resources/api/calls/tag for more information.
+type Tag struct {
+	CID string `json:"_cid,omitempty"` // string
+}
+
+// Name returns the tag (category:value) without the cid prefix.
+func (t *Tag) Name() string {
+	return strings.TrimPrefix(t.CID, config.TagPrefix+"/")
+}
+
+// Category returns the category of the tag, empty for uncategorized tags.
+func (t *Tag) Category() string {
+	name := t.Name()
+	if idx := strings.Index(name, ":"); idx >= 0 {
+		return name[:idx]
+	}
+	return ""
+}
+
+// Value returns the value of the tag.
+func (t *Tag) Value() string {
+	name := t.Name()
+	if idx := strings.Index(name, ":"); idx >= 0 {
+		return name[idx+1:]
+	}
+	return name
+}
+
+// FetchTag retrieves tag with passed cid.
+func (a *API) FetchTag(cid CIDType) (*Tag, error) {
+	return a.FetchTagWithContext(context.Background(), cid)
+}
+
+// FetchTagWithContext retrieves tag with passed cid, stopping when ctx is done.
+func (a *API) FetchTagWithContext(ctx context.Context, cid CIDType) (*Tag, error) {
+	if cid == nil || *cid == "" {
+		return nil, errors.New("invalid tag CID (none)")
+	}
+
+	var tagCID string
+	if !strings.HasPrefix(*cid, config.TagPrefix) {
+		tagCID = fmt.Sprintf("%s/%s", config.TagPrefix, *cid)
+	} else {
+		tagCID = *cid
+	}
+
+	if !validCID(config.TagPrefix, tagCID) {
+		return nil, errors.Errorf("invalid tag CID (%s)", tagCID)
+	}
+
+	result, err := a.GetWithContext(ctx, tagCID)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching tag")
+	}
+
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch tag, received JSON: %s", string(result))
+	}
+
+	tag := &Tag{}
+	if err := a.unmarshalJSON(result, tag); err != nil {
+		return nil, errors.Wrap(err, "parsing tag")
+	}
+
+	return tag, nil
+}
+
+// FetchTags retrieves all tags available to API Token.
+func (a *API) FetchTags() (*[]Tag, error) {
+	return a.FetchTagsWithContext(context.Background())
+}
+
+// FetchTagsWithContext retrieves all tags available to API Token, stopping
+// when ctx is done.
+func (a *API) FetchTagsWithContext(ctx context.Context) (*[]Tag, error) {
+	var tags []Tag
+	if err := a.getJSONContext(ctx, config.TagPrefix, &tags); err != nil {
+		return nil, errors.Wrap(err, "fetching tags")
+	}
+
+	return &tags, nil
+}
+
+// SearchTags returns tags matching the specified search query
+// and/or filter. If nil is passed for both parameters all tags
+// will be returned.
+func (a *API) SearchTags(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Tag, error) {
+	return a.SearchTagsWithContext(context.Background(), searchCriteria, filterCriteria)
+}
+
+// SearchTagsWithContext returns tags matching the specified search query
+// and/or filter, stopping when ctx is done.
+func (a *API) SearchTagsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Tag, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchTagsWithContext(ctx)
+	}
+
+	var tags []Tag
+	if err := a.searchJSONContext(ctx, config.TagPrefix, searchCriteria, filterCriteria, &tags); err != nil {
+		return nil, errors.Wrap(err, "searching tags")
+	}
+
+	return &tags, nil
+}
+
+// FetchTagCategories retrieves all tags and groups their values by category,
+// values are sorted. Uncategorized tags are grouped under the empty category.
+func (a *API) FetchTagCategories() (map[string][]string, error) {
+	return a.FetchTagCategoriesWithContext(context.Background())
+}
+
+// FetchTagCategoriesWithContext retrieves all tags and groups their values by
+// category, values are sorted, stopping when ctx is done.
+func (a *API) FetchTagCategoriesWithContext(ctx context.Context) (map[string][]string, error) {
+	tags, err := a.FetchTagsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make(map[string][]string)
+	for _, t := range *tags {
+		categories[t.Category()] = append(categories[t.Category()], t.Value())
+	}
+	for _, values := range categories {
+		sort.Strings(values)
+	}
+
+	return categories, nil
+}
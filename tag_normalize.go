@@ -0,0 +1,247 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Tag normalization - find and fix tags violating a policy across the
+// objects of the account
+
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// tagNormalizeTypes defines the types of objects tags are normalized on by
+// default (the tags of checks are those of their check bundles)
+var tagNormalizeTypes = []string{
+	config.CheckBundlePrefix,
+	config.GraphPrefix,
+	config.DashboardPrefix,
+	config.WorksheetPrefix,
+	config.MetricClusterPrefix,
+}
+
+// TagPolicy defines how tags (category:value) should be written
+type TagPolicy struct {
+	// Lowercase tags
+	Lowercase bool
+
+	// Categories allowed, any if empty
+	Categories []string
+
+	// CategoryAliases rename categories (e.g. "environment" to "env"),
+	// before they are checked against Categories
+	CategoryAliases map[string]string
+
+	// DefaultCategory is given to tags without a category, which otherwise
+	// violate the policy if Categories is set
+	DefaultCategory string
+
+	// RemoveInvalid removes tags violating the policy which cannot be
+	// fixed, otherwise they are reported and kept
+	RemoveInvalid bool
+}
+
+// Normalize returns the tags written as the policy requires, without
+// duplicates, and the tags violating the policy which could not be fixed
+// (removed if RemoveInvalid is set).
+func (p *TagPolicy) Normalize(tags []string) ([]string, []string) {
+	allowed := make(map[string]bool, len(p.Categories))
+	for _, c := range p.Categories {
+		if p.Lowercase {
+			c = strings.ToLower(c)
+		}
+		allowed[c] = true
+	}
+
+	normalized := make([]string, 0, len(tags))
+	var invalid []string
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if p.Lowercase {
+			tag = strings.ToLower(tag)
+		}
+		category, value := "", tag
+		if idx := strings.Index(tag, ":"); idx >= 0 {
+			category, value = strings.TrimSpace(tag[:idx]), strings.TrimSpace(tag[idx+1:])
+		}
+		if alias, ok := p.CategoryAliases[category]; ok {
+			category = alias
+			if p.Lowercase {
+				category = strings.ToLower(category)
+			}
+		}
+		if category == "" {
+			category = p.DefaultCategory
+		}
+		if category != "" {
+			tag = category + ":" + value
+		} else {
+			tag = value
+		}
+		if value == "" || (len(allowed) > 0 && !allowed[category]) {
+			invalid = append(invalid, tag)
+			if p.RemoveInvalid {
+				continue
+			}
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+
+	return normalized, invalid
+}
+
+// TagNormalizeConfig defines the tag policy to apply and how
+type TagNormalizeConfig struct {
+	Policy TagPolicy
+
+	// Types (cid prefixes, e.g. config.GraphPrefix) of objects to
+	// normalize the tags of - default check bundles, graphs, dashboards,
+	// worksheets, and metric clusters
+	Types []string
+
+	// DryRun reports what would be changed, without changing anything
+	DryRun bool
+	// Interval is the minimum time between updates
+	Interval time.Duration
+}
+
+// TagChange defines the tags of an object changed (or which would be, for
+// a dry run), and the tags violating the policy left as they are
+type TagChange struct {
+	CID     string
+	Before  []string
+	After   []string
+	Invalid []string
+	Err     error // nil if updated (or would be, for a dry run)
+}
+
+// TagNormalizeResult defines the outcome of a tag normalization
+type TagNormalizeResult struct {
+	DryRun  bool
+	Changed []TagChange // updated, or would be updated for a dry run
+	Invalid []TagChange // not changed, but with tags violating the policy
+	Failed  []TagChange
+}
+
+// Err returns an error summarizing the failed updates, nil if none failed.
+func (r *TagNormalizeResult) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(r.Failed))
+	for i, f := range r.Failed {
+		msgs[i] = fmt.Sprintf("%s: %s", f.CID, f.Err)
+	}
+	return errors.Errorf("tag normalization, %d of %d failed (%s)", len(r.Failed), len(r.Failed)+len(r.Changed), strings.Join(msgs, "; "))
+}
+
+// NormalizeTags applies the tag policy to the objects of the configured
+// types, updating those whose tags change (objects without tags are
+// skipped). Objects are updated as fetched, with only their tags changed.
+// Updates continue past failures, which are reported in the result. An
+// error is returned only if the objects could not be fetched.
+func (a *API) NormalizeTags(ctx context.Context, cfg *TagNormalizeConfig) (*TagNormalizeResult, error) {
+	if cfg == nil {
+		return nil, errors.New("invalid tag normalize config (nil)")
+	}
+	types := cfg.Types
+	if len(types) == 0 {
+		types = tagNormalizeTypes
+	}
+	for _, t := range types {
+		if !strings.HasPrefix(t, "/") || strings.Count(t, "/") > 1 {
+			return nil, errors.Errorf("invalid tag normalize type (%s)", t)
+		}
+	}
+
+	result := &TagNormalizeResult{DryRun: cfg.DryRun}
+
+	var last time.Time
+	for _, t := range types {
+		var objs []map[string]interface{}
+		if err := a.getJSONContext(ctx, t, &objs); err != nil {
+			return result, errors.Wrapf(err, "fetching %s", t)
+		}
+		sort.SliceStable(objs, func(i, j int) bool {
+			ci, _ := objs[i]["_cid"].(string)
+			cj, _ := objs[j]["_cid"].(string)
+			return ci < cj
+		})
+
+		for _, obj := range objs {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			cid, _ := obj["_cid"].(string)
+			list, ok := obj["tags"].([]interface{})
+			if cid == "" || !ok {
+				continue
+			}
+			before := make([]string, 0, len(list))
+			for _, v := range list {
+				if s, ok := v.(string); ok {
+					before = append(before, s)
+				}
+			}
+
+			after, invalid := cfg.Policy.Normalize(before)
+			change := TagChange{CID: cid, Before: before, After: after, Invalid: invalid}
+			if reflect.DeepEqual(before, after) {
+				if len(invalid) > 0 {
+					result.Invalid = append(result.Invalid, change)
+				}
+				continue
+			}
+			if cfg.DryRun {
+				result.Changed = append(result.Changed, change)
+				continue
+			}
+
+			if wait := cfg.Interval - time.Since(last); cfg.Interval > 0 && wait > 0 {
+				select {
+				case <-ctx.Done():
+					return result, ctx.Err()
+				case <-time.After(wait):
+				}
+			}
+			last = time.Now()
+			obj["tags"] = after
+			if change.Err = a.putObject(ctx, cid, obj); change.Err != nil {
+				result.Failed = append(result.Failed, change)
+			} else {
+				result.Changed = append(result.Changed, change)
+			}
+
+			if a.debugEnabled() {
+				a.apiLog().Printf("tag normalization, %s %v => %v: %v", cid, before, after, change.Err)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// putObject updates the object with the passed cid
+func (a *API) putObject(ctx context.Context, cid string, obj interface{}) error {
+	data, err := a.marshalJSON(obj)
+	if err != nil {
+		return errors.Wrapf(err, "encoding %s", cid)
+	}
+	if _, err := a.apiRequestContext(ctx, "PUT", cid, data.Bytes()); err != nil {
+		return errors.Wrapf(err, "updating %s", cid)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/circonus-labs/go-apiclient/testutil/fakecirconus"
+)
+
+func TestTagPolicyNormalize(t *testing.T) {
+	tests := []struct {
+		id              string
+		policy          TagPolicy
+		tags            []string
+		expected        []string
+		expectedInvalid []string
+	}{
+		{"no policy", TagPolicy{}, []string{" env:prod", "env:prod", "Web"}, []string{"env:prod", "Web"}, nil},
+		{"lowercase", TagPolicy{Lowercase: true}, []string{"Env:Prod", "env:prod"}, []string{"env:prod"}, nil},
+		{"aliases", TagPolicy{CategoryAliases: map[string]string{"environment": "env"}}, []string{"environment:prod", "env : prod"}, []string{"env:prod"}, nil},
+		{"default category", TagPolicy{Categories: []string{"env", "service"}, DefaultCategory: "service"}, []string{"web", "env:prod"}, []string{"service:web", "env:prod"}, nil},
+		{"invalid kept", TagPolicy{Categories: []string{"env"}}, []string{"env:prod", "team:ops", "web"}, []string{"env:prod", "team:ops", "web"}, []string{"team:ops", "web"}},
+		{"invalid removed", TagPolicy{Categories: []string{"Env"}, Lowercase: true, RemoveInvalid: true}, []string{"ENV:prod", "team:ops", "env:"}, []string{"env:prod"}, []string{"team:ops", "env:"}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			tags, invalid := test.policy.Normalize(test.tags)
+			if !reflect.DeepEqual(tags, test.expected) {
+				t.Fatalf("unexpected tags (%v)", tags)
+			}
+			if !reflect.DeepEqual(invalid, test.expectedInvalid) {
+				t.Fatalf("unexpected invalid tags (%v)", invalid)
+			}
+		})
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	fake, err := fakecirconus.New(&fakecirconus.Config{Fixtures: map[string]interface{}{
+		"/graph/1":        map[string]interface{}{"_cid": "/graph/1", "title": "web", "tags": []string{"Env:Prod"}, "extra": "kept"},
+		"/graph/2":        map[string]interface{}{"_cid": "/graph/2", "title": "db", "tags": []string{"env:prod"}},
+		"/graph/3":        map[string]interface{}{"_cid": "/graph/3", "title": "ops", "tags": []string{"team:ops"}},
+		"/check_bundle/1": map[string]interface{}{"_cid": "/check_bundle/1", "tags": []string{"ENV:dev", "env:dev"}},
+		"/dashboard/1":    map[string]interface{}{"_cid": "/dashboard/1", "title": "untagged"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	defer fake.Close()
+
+	apih, err := New(&Config{TokenKey: "abc123", URL: fake.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	policy := TagPolicy{Lowercase: true, Categories: []string{"env"}}
+
+	t.Log("dry run")
+	{
+		result, err := apih.NormalizeTags(context.Background(), &TagNormalizeConfig{Policy: policy, DryRun: true})
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		changed := []string{}
+		for _, c := range result.Changed {
+			changed = append(changed, c.CID)
+		}
+		if !reflect.DeepEqual(changed, []string{"/check_bundle/1", "/graph/1"}) {
+			t.Fatalf("unexpected changes (%v)", changed)
+		}
+		if len(result.Invalid) != 1 || result.Invalid[0].CID != "/graph/3" {
+			t.Fatalf("unexpected invalid (%+v)", result.Invalid)
+		}
+		for _, r := range fake.Requests() {
+			if r.Method != "GET" {
+				t.Fatalf("unexpected request (%s %s)", r.Method, r.URL)
+			}
+		}
+	}
+
+	t.Log("update, one failing")
+	{
+		fake.Fail(fakecirconus.Failure{Method: "PUT", Path: "/check_bundle", Status: 400, Times: 1})
+		cfg := &TagNormalizeConfig{Policy: policy, Types: []string{config.GraphPrefix, config.CheckBundlePrefix}, Interval: time.Millisecond}
+		result, err := apih.NormalizeTags(context.Background(), cfg)
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if len(result.Changed) != 1 || len(result.Failed) != 1 || result.Failed[0].CID != "/check_bundle/1" {
+			t.Fatalf("unexpected result (%+v)", result)
+		}
+		if err := result.Err(); err == nil || !strings.Contains(err.Error(), "tag normalization, 1 of 2 failed") {
+			t.Fatalf("unexpected error (%v)", err)
+		}
+
+		data, _ := fake.Object("/graph/1")
+		var graph map[string]interface{}
+		if err := json.Unmarshal(data, &graph); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if !reflect.DeepEqual(graph["tags"], []interface{}{"env:prod"}) || graph["extra"] != "kept" {
+			t.Fatalf("unexpected graph (%v)", graph)
+		}
+	}
+
+	if _, err := apih.NormalizeTags(context.Background(), &TagNormalizeConfig{Types: []string{"graph"}}); err == nil {
+		t.Fatal("expected error")
+	}
+}
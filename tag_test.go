@@ -0,0 +1,189 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+var (
+	testTags = []Tag{
+		{CID: "/tag/environment:production"},
+		{CID: "/tag/service:web"},
+		{CID: "/tag/environment:dev"},
+		{CID: "/tag/legacy"},
+	}
+)
+
+func testTagServer() *httptest.Server {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		reqURL := r.URL.String()
+		if r.Method != "GET" {
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, reqURL))
+			return
+		}
+
+		var ret interface{}
+		switch reqURL {
+		case "/tag/environment:production":
+			ret = testTags[0]
+		case "/tag":
+			ret = testTags
+		case "/tag?search=environment":
+			ret = []Tag{testTags[0], testTags[2]}
+		case "/tag?f__cid_wildcard=%2Fservice%3A%2A":
+			ret = []Tag{testTags[1]}
+		default:
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, reqURL))
+			return
+		}
+
+		b, err := json.Marshal(ret)
+		if err != nil {
+			panic(err)
+		}
+		w.WriteHeader(200)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, string(b))
+	}
+
+	return httptest.NewServer(http.HandlerFunc(f))
+}
+
+func tagTestBootstrap(t *testing.T) (*API, func()) {
+	server := testTagServer()
+
+	ac := &Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      server.URL,
+	}
+	apih, err := NewAPI(ac)
+	if err != nil {
+		server.Close()
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	return apih, server.Close
+}
+
+func TestTag(t *testing.T) {
+	tests := []struct {
+		tag      Tag
+		category string
+		value    string
+	}{
+		{testTags[0], "environment", "production"},
+		{Tag{CID: "/tag/url:http://example.com"}, "url", "http://example.com"},
+		{testTags[3], "", "legacy"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.tag.CID, func(t *testing.T) {
+			if c := test.tag.Category(); c != test.category {
+				t.Fatalf("expected category %q, got %q", test.category, c)
+			}
+			if v := test.tag.Value(); v != test.value {
+				t.Fatalf("expected value %q, got %q", test.value, v)
+			}
+		})
+	}
+}
+
+func TestFetchTag(t *testing.T) {
+	apih, done := tagTestBootstrap(t)
+	defer done()
+
+	tests := []struct {
+		id           string
+		cid          string
+		expectedType string
+		shouldFail   bool
+		expectedErr  string
+	}{
+		{"empty cid", "", "", true, "invalid tag CID (none)"},
+		{"short cid", "environment:production", "*apiclient.Tag", false, ""},
+		{"long cid", "/tag/environment:production", "*apiclient.Tag", false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			tag, err := apih.FetchTag(CIDType(&test.cid))
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				} else if reflect.TypeOf(tag).String() != test.expectedType {
+					t.Fatalf("unexpected type (%s)", reflect.TypeOf(tag).String())
+				}
+			}
+		})
+	}
+}
+
+func TestSearchTags(t *testing.T) {
+	apih, done := tagTestBootstrap(t)
+	defer done()
+
+	search := SearchQueryType("environment")
+	filter := SearchFilterType(map[string][]string{"f__cid_wildcard": {"/service:*"}})
+
+	tests := []struct {
+		id       string
+		search   *SearchQueryType
+		filter   *SearchFilterType
+		expected int
+	}{
+		{"no search, no filter", nil, nil, 4},
+		{"search no filter", &search, nil, 2},
+		{"filter no search", nil, &filter, 1},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			tags, err := apih.SearchTags(test.search, test.filter)
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if len(*tags) != test.expected {
+				t.Fatalf("expected %d tags, got %d", test.expected, len(*tags))
+			}
+		})
+	}
+}
+
+func TestFetchTagCategories(t *testing.T) {
+	apih, done := tagTestBootstrap(t)
+	defer done()
+
+	categories, err := apih.FetchTagCategories()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	expected := map[string][]string{
+		"":            {"legacy"},
+		"environment": {"dev", "production"},
+		"service":     {"web"},
+	}
+	if !reflect.DeepEqual(categories, expected) {
+		t.Fatalf("unexpected categories (%v)", categories)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package terraform
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// expr is an hcl expression written as is (e.g. a reference to another resource)
+type expr string
+
+// body accumulates the attributes and nested blocks of an hcl block
+type body struct {
+	buf    bytes.Buffer
+	indent int
+}
+
+func (b *body) line(format string, args ...interface{}) {
+	b.buf.WriteString(strings.Repeat("  ", b.indent))
+	fmt.Fprintf(&b.buf, format, args...)
+	b.buf.WriteByte('\n')
+}
+
+// attr writes an attribute, nil and empty values (including empty strings) are skipped
+func (b *body) attr(name string, v interface{}) {
+	val, ok := hclValue(v, b.indent)
+	if !ok {
+		return
+	}
+	b.line("%s = %s", name, val)
+}
+
+// block writes a nested block with the passed labels
+func (b *body) block(name string, labels []string, fn func(*body)) {
+	head := name
+	for _, l := range labels {
+		head += " " + strconv.Quote(l)
+	}
+	b.line("%s {", head)
+	b.indent++
+	fn(b)
+	b.indent--
+	b.line("}")
+}
+
+// hclValue returns the hcl encoding of a value, false for nil or empty values
+func hclValue(v interface{}, indent int) (string, bool) {
+	switch t := v.(type) {
+	case nil:
+		return "", false
+	case expr:
+		return string(t), t != ""
+	case string:
+		return hclString(t), t != ""
+	case *string:
+		if t == nil || *t == "" {
+			return "", false
+		}
+		return hclString(*t), true
+	case bool:
+		return strconv.FormatBool(t), true
+	case int:
+		return strconv.Itoa(t), true
+	case uint:
+		return strconv.FormatUint(uint64(t), 10), true
+	case *uint:
+		if t == nil {
+			return "", false
+		}
+		return strconv.FormatUint(uint64(*t), 10), true
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), true
+	case []string:
+		if len(t) == 0 {
+			return "", false
+		}
+		vals := make([]string, len(t))
+		for i, s := range t {
+			vals[i] = hclString(s)
+		}
+		return "[" + strings.Join(vals, ", ") + "]", true
+	case []expr:
+		if len(t) == 0 {
+			return "", false
+		}
+		vals := make([]string, len(t))
+		for i, s := range t {
+			vals[i] = string(s)
+		}
+		return "[" + strings.Join(vals, ", ") + "]", true
+	case map[string]string:
+		if len(t) == 0 {
+			return "", false
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pad := strings.Repeat("  ", indent+1)
+		var buf bytes.Buffer
+		buf.WriteString("{\n")
+		for _, k := range keys {
+			fmt.Fprintf(&buf, "%s%s = %s\n", pad, hclString(k), hclString(t[k]))
+		}
+		buf.WriteString(strings.Repeat("  ", indent) + "}")
+		return buf.String(), true
+	}
+	return hclString(fmt.Sprintf("%v", v)), true
+}
+
+// hclString quotes a string, escaping template sequences
+func hclString(s string) string {
+	q := strconv.Quote(s)
+	q = strings.Replace(q, "${", "$${", -1)
+	q = strings.Replace(q, "%{", "%%{", -1)
+	return q
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// resourceName returns a valid, unique terraform resource name for the passed
+// title, recording it in used
+func resourceName(title string, used map[string]bool) string {
+	name := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(title), "_"), "_")
+	if name == "" {
+		name = "unnamed"
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", name, i)
+	}
+	used[unique] = true
+	return unique
+}
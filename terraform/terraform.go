@@ -0,0 +1,466 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package terraform renders check bundles, rule sets, graphs, and dashboards
+// as resource blocks of the Circonus terraform provider, to bring existing
+// objects under terraform management.
+//
+//	f, _ := os.Create("circonus.tf")
+//	err := terraform.Export(apih, f, &terraform.Config{ImportBlocks: true})
+//
+// The rendering is best effort: check type specific configuration is mapped
+// by key name and attributes without a provider equivalent are omitted, so
+// review the output (terraform plan should report no changes) before use.
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+	"github.com/circonus-labs/go-apiclient/config"
+	"github.com/pkg/errors"
+)
+
+// Resource types of the Circonus provider
+const (
+	ResourceCheck     = "circonus_check"
+	ResourceDashboard = "circonus_dashboard"
+	ResourceGraph     = "circonus_graph"
+	ResourceRuleSet   = "circonus_rule_set"
+)
+
+// checkTypeBlocks maps check types to provider check blocks, where they differ
+var checkTypeBlocks = map[string]string{
+	"ping_icmp": "icmp_ping",
+}
+
+// brokerAssignedConfig are check bundle config keys the provider computes
+var brokerAssignedConfig = map[string]bool{
+	string(config.SubmissionURL):    true,
+	string(config.ReverseSecretKey): true,
+}
+
+// ruleCriteria maps rule set rule criteria to provider rule value attributes
+var ruleCriteria = map[string]string{
+	"on absence":       "absent",
+	"on change":        "changed",
+	"contains":         "contains",
+	"does not contain": "not_contain",
+	"match":            "match",
+	"does not match":   "not_match",
+	"min value":        "min_value",
+	"max value":        "max_value",
+	"equals":           "eq_value",
+	"does not equal":   "neq_value",
+}
+
+// Config defines the exporter configuration
+type Config struct {
+	// ImportBlocks writes an import block (terraform >= 1.5) for each resource,
+	// so terraform adopts the existing object instead of creating a new one
+	ImportBlocks bool
+}
+
+// Exporter writes terraform resource blocks. Objects referenced by objects
+// written later (e.g. the check of a rule set) are referenced by resource
+// address rather than cid, so write referenced objects first.
+type Exporter struct {
+	w            io.Writer
+	importBlocks bool
+	names        map[string]map[string]bool // resource type => names used
+	refs         map[string]expr            // cid => resource attribute reference
+}
+
+// NewExporter returns an Exporter writing to w.
+func NewExporter(w io.Writer, cfg *Config) *Exporter {
+	e := &Exporter{
+		w:     w,
+		names: make(map[string]map[string]bool),
+		refs:  make(map[string]expr),
+	}
+	if cfg != nil {
+		e.importBlocks = cfg.ImportBlocks
+	}
+	return e
+}
+
+// Export fetches all check bundles, graphs, rule sets, and dashboards and writes
+// them to w.
+func Export(api *apiclient.API, w io.Writer, cfg *Config) error {
+	if api == nil {
+		return errors.New("invalid terraform export API (nil)")
+	}
+	e := NewExporter(w, cfg)
+
+	bundles, err := api.FetchCheckBundles()
+	if err != nil {
+		return err
+	}
+	for i := range *bundles {
+		if err := e.CheckBundle(&(*bundles)[i]); err != nil {
+			return err
+		}
+	}
+
+	graphs, err := api.FetchGraphs()
+	if err != nil {
+		return err
+	}
+	for i := range *graphs {
+		if err := e.Graph(&(*graphs)[i]); err != nil {
+			return err
+		}
+	}
+
+	ruleSets, err := api.FetchRuleSets()
+	if err != nil {
+		return err
+	}
+	for i := range *ruleSets {
+		if err := e.RuleSet(&(*ruleSets)[i]); err != nil {
+			return err
+		}
+	}
+
+	dashboards, err := api.FetchDashboards()
+	if err != nil {
+		return err
+	}
+	for i := range *dashboards {
+		if err := e.Dashboard(&(*dashboards)[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckBundle writes a circonus_check resource for the passed check bundle.
+func (e *Exporter) CheckBundle(cb *apiclient.CheckBundle) error {
+	if cb == nil {
+		return errors.New("invalid check bundle (nil)")
+	}
+	name := e.name(ResourceCheck, cb.DisplayName)
+
+	b := &body{indent: 1}
+	b.attr("name", cb.DisplayName)
+	b.attr("active", cb.Status == "" || cb.Status == "active")
+	b.attr("target", cb.Target)
+	if cb.Period > 0 {
+		b.attr("period", fmt.Sprintf("%ds", cb.Period))
+	}
+	if cb.Timeout > 0 {
+		b.attr("timeout", fmt.Sprintf("%gs", cb.Timeout))
+	}
+	if cb.MetricLimit != 0 {
+		b.attr("metric_limit", cb.MetricLimit)
+	}
+	b.attr("notes", cb.Notes)
+	b.attr("tags", cb.Tags)
+	for _, broker := range cb.Brokers {
+		broker := broker
+		b.block("collector", nil, func(b *body) { b.attr("id", broker) })
+	}
+
+	blockName := strings.SplitN(cb.Type, ":", 2)[0]
+	if mapped, ok := checkTypeBlocks[blockName]; ok {
+		blockName = mapped
+	}
+	b.block(blockName, nil, func(b *body) {
+		headers := map[string]string{}
+		keys := make([]string, 0, len(cb.Config))
+		for k := range cb.Config {
+			keys = append(keys, string(k))
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := cb.Config[config.Key(k)]
+			switch {
+			case brokerAssignedConfig[k]:
+			case strings.HasPrefix(k, string(config.HeaderPrefix)):
+				headers[strings.TrimPrefix(k, string(config.HeaderPrefix))] = v
+			default:
+				b.attr(invalidNameChars.ReplaceAllString(strings.ToLower(k), "_"), v)
+			}
+		}
+		b.attr("headers", headers)
+	})
+
+	for _, m := range cb.Metrics {
+		m := m
+		b.block("metric", nil, func(b *body) {
+			b.attr("name", m.Name)
+			b.attr("type", m.Type)
+			b.attr("unit", m.Units)
+			b.attr("active", m.Status == "" || m.Status == "active")
+			b.attr("tags", m.Tags)
+		})
+	}
+
+	for i, check := range cb.Checks {
+		e.refs[check] = expr(fmt.Sprintf("%s.%s.checks[%d]", ResourceCheck, name, i))
+	}
+
+	return e.write(ResourceCheck, name, cb.CID, b)
+}
+
+// RuleSet writes a circonus_rule_set resource for the passed rule set.
+func (e *Exporter) RuleSet(rs *apiclient.RuleSet) error {
+	if rs == nil {
+		return errors.New("invalid rule set (nil)")
+	}
+	title := rs.Name
+	if title == "" {
+		title = rs.MetricName + rs.MetricPattern
+	}
+	name := e.name(ResourceRuleSet, title)
+
+	b := &body{indent: 1}
+	b.attr("check", e.ref(rs.CheckCID))
+	b.attr("metric_name", rs.MetricName)
+	b.attr("metric_type", rs.MetricType)
+	b.attr("link", rs.Link)
+	b.attr("notes", rs.Notes)
+	b.attr("parent", rs.Parent)
+	b.attr("tags", rs.Tags)
+
+	for _, rule := range rs.Rules {
+		rule := rule
+		b.block("if", nil, func(b *body) {
+			b.block("value", nil, func(b *body) {
+				attr, ok := ruleCriteria[rule.Criteria]
+				if !ok {
+					b.line("# unsupported criteria %s", hclString(rule.Criteria))
+					return
+				}
+				switch attr {
+				case "absent":
+					b.attr(attr, fmt.Sprintf("%vs", rule.Value))
+				case "changed":
+					b.attr(attr, true)
+				default:
+					b.attr(attr, fmt.Sprintf("%v", rule.Value))
+				}
+			})
+			b.block("then", nil, func(b *body) {
+				notify := make([]expr, 0, len(rs.ContactGroups[uint8(rule.Severity)]))
+				for _, cg := range rs.ContactGroups[uint8(rule.Severity)] {
+					notify = append(notify, e.ref(cg))
+				}
+				b.attr("notify", notify)
+				b.attr("severity", rule.Severity)
+			})
+		})
+	}
+
+	return e.write(ResourceRuleSet, name, rs.CID, b)
+}
+
+// Graph writes a circonus_graph resource for the passed graph.
+func (e *Exporter) Graph(g *apiclient.Graph) error {
+	if g == nil {
+		return errors.New("invalid graph (nil)")
+	}
+	name := e.name(ResourceGraph, g.Title)
+
+	b := &body{indent: 1}
+	b.attr("name", g.Title)
+	b.attr("description", g.Description)
+	b.attr("graph_style", g.Style)
+	b.attr("line_style", g.LineStyle)
+	b.attr("notes", g.Notes)
+	b.attr("tags", g.Tags)
+	if g.LogLeftY != nil || g.MaxLeftY != nil || g.MinLeftY != nil {
+		b.block("left", nil, func(b *body) {
+			if g.LogLeftY != nil {
+				b.attr("logarithmic", *g.LogLeftY)
+			}
+			if g.MaxLeftY != nil {
+				b.attr("max", *g.MaxLeftY)
+			}
+			if g.MinLeftY != nil {
+				b.attr("min", *g.MinLeftY)
+			}
+		})
+	}
+	if g.LogRightY != nil || g.MaxRightY != nil || g.MinRightY != nil {
+		b.block("right", nil, func(b *body) {
+			if g.LogRightY != nil {
+				b.attr("logarithmic", *g.LogRightY)
+			}
+			if g.MaxRightY != nil {
+				b.attr("max", *g.MaxRightY)
+			}
+			if g.MinRightY != nil {
+				b.attr("min", *g.MinRightY)
+			}
+		})
+	}
+
+	for _, dp := range g.Datapoints {
+		dp := dp
+		b.block("metric", nil, func(b *body) {
+			if dp.CheckID != 0 {
+				b.attr("check", e.ref(fmt.Sprintf("%s/%d", config.CheckPrefix, dp.CheckID)))
+			}
+			b.attr("metric_name", dp.MetricName)
+			b.attr("metric_type", dp.MetricType)
+			b.attr("name", dp.Name)
+			b.attr("active", !dp.Hidden)
+			b.attr("axis", dp.Axis)
+			b.attr("caql", dp.CAQL)
+			b.attr("color", dp.Color)
+			b.attr("formula", dp.DataFormula)
+			b.attr("legend_formula", dp.LegendFormula)
+			if fn, ok := dp.Derive.(string); ok {
+				b.attr("function", fn)
+			}
+			b.attr("stack", dp.Stack)
+		})
+	}
+
+	for _, mc := range g.MetricClusters {
+		mc := mc
+		b.block("metric_cluster", nil, func(b *body) {
+			b.attr("query", mc.MetricCluster)
+			b.attr("name", mc.Name)
+			b.attr("active", !mc.Hidden)
+			b.attr("aggregate", mc.AggregateFunc)
+			b.attr("axis", mc.Axis)
+			b.attr("color", mc.Color)
+			b.attr("stack", mc.Stack)
+		})
+	}
+
+	uuid := strings.TrimPrefix(g.CID, config.GraphPrefix+"/")
+	if uuid != "" {
+		// dashboards reference graphs by uuid, the id is the cid
+		e.refs[uuid] = expr(fmt.Sprintf("trimprefix(%s.%s.id, %q)", ResourceGraph, name, config.GraphPrefix+"/"))
+	}
+
+	return e.write(ResourceGraph, name, g.CID, b)
+}
+
+// Dashboard writes a circonus_dashboard resource for the passed dashboard.
+// Widget settings are written as set, without mapping.
+func (e *Exporter) Dashboard(d *apiclient.Dashboard) error {
+	if d == nil {
+		return errors.New("invalid dashboard (nil)")
+	}
+	name := e.name(ResourceDashboard, d.Title)
+
+	b := &body{indent: 1}
+	b.attr("title", d.Title)
+	b.attr("shared", d.Shared)
+	b.attr("account_default", d.AccountDefault)
+	b.block("grid_layout", nil, func(b *body) {
+		b.attr("height", d.GridLayout.Height)
+		b.attr("width", d.GridLayout.Width)
+	})
+
+	for _, w := range d.Widgets {
+		w := w
+		settings, err := widgetSettings(w.Settings)
+		if err != nil {
+			return errors.Wrapf(err, "dashboard %s widget %s", d.CID, w.WidgetID)
+		}
+		b.block("widget", nil, func(b *body) {
+			b.attr("type", w.Type)
+			b.attr("name", w.Name)
+			b.attr("active", w.Active)
+			b.attr("height", w.Height)
+			b.attr("width", w.Width)
+			b.attr("origin", w.Origin)
+			b.attr("widget_id", w.WidgetID)
+			b.block("settings", nil, func(b *body) {
+				keys := make([]string, 0, len(settings))
+				for k := range settings {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					if k == "graph_id" {
+						if s, ok := settings[k].(string); ok {
+							b.attr(k, e.ref(s))
+							continue
+						}
+					}
+					b.attr(k, settings[k])
+				}
+			})
+		})
+	}
+
+	return e.write(ResourceDashboard, name, d.CID, b)
+}
+
+// widgetSettings returns the set (non-zero) widget settings
+func widgetSettings(s apiclient.DashboardWidgetSettings) (map[string]interface{}, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]interface{}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	settings := make(map[string]interface{}, len(all))
+	for k, v := range all {
+		switch t := v.(type) {
+		case string, bool, float64:
+			if t != "" && t != false && t != float64(0) {
+				settings[k] = t
+			}
+		case []interface{}:
+			vals := make([]string, 0, len(t))
+			for _, s := range t {
+				if str, ok := s.(string); ok {
+					vals = append(vals, str)
+				}
+			}
+			if len(vals) > 0 {
+				settings[k] = vals
+			}
+		}
+	}
+	return settings, nil
+}
+
+// name returns a unique resource name of the passed type
+func (e *Exporter) name(resourceType, title string) string {
+	if e.names[resourceType] == nil {
+		e.names[resourceType] = make(map[string]bool)
+	}
+	return resourceName(title, e.names[resourceType])
+}
+
+// ref returns a reference to the resource written for cid, or cid if none was
+func (e *Exporter) ref(cid string) expr {
+	if ref, ok := e.refs[cid]; ok {
+		return ref
+	}
+	if cid == "" {
+		return ""
+	}
+	return expr(hclString(cid))
+}
+
+// write writes a resource block (and import block) to the exporter writer
+func (e *Exporter) write(resourceType, name, cid string, b *body) error {
+	var buf bytes.Buffer
+	if e.importBlocks && cid != "" {
+		fmt.Fprintf(&buf, "import {\n  to = %s.%s\n  id = %s\n}\n\n", resourceType, name, hclString(cid))
+	}
+	fmt.Fprintf(&buf, "resource %q %q {\n", resourceType, name)
+	buf.Write(b.buf.Bytes())
+	buf.WriteString("}\n\n")
+
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
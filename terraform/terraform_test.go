@@ -0,0 +1,213 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package terraform
+
+import (
+	"bytes"
+	"testing"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+)
+
+func TestExporter(t *testing.T) {
+	units := "ms"
+	stack := uint(1)
+	var buf bytes.Buffer
+	e := NewExporter(&buf, &Config{ImportBlocks: true})
+
+	err := e.CheckBundle(&apiclient.CheckBundle{
+		Brokers: []string{"/broker/1"},
+		Checks:  []string{"/check/11"},
+		CID:     "/check_bundle/1",
+		Config: apiclient.CheckBundleConfig{
+			"url":            "https://example.com/${path}",
+			"header_Host":    "example.com",
+			"submission_url": "https://broker/secret",
+			"auth:method":    "Basic",
+		},
+		DisplayName: "Example Web",
+		Metrics:     []apiclient.CheckBundleMetric{{Name: "duration", Type: "numeric", Units: &units, Status: "active"}},
+		Period:      60,
+		Status:      "active",
+		Tags:        []string{"service:web"},
+		Target:      "example.com",
+		Timeout:     10,
+		Type:        "http",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	err = e.Graph(&apiclient.Graph{
+		CID:        "/graph/abc-123",
+		Title:      "Example Web",
+		Datapoints: []apiclient.GraphDatapoint{{CheckID: 11, MetricName: "duration", MetricType: "numeric", Name: "duration", Axis: "l", Stack: &stack}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	err = e.RuleSet(&apiclient.RuleSet{
+		CID:           "/rule_set/11_duration",
+		CheckCID:      "/check/11",
+		ContactGroups: map[uint8][]string{1: {"/contact_group/1"}},
+		MetricName:    "duration",
+		MetricType:    "numeric",
+		Rules: []apiclient.RuleSetRule{
+			{Criteria: "max value", Severity: 1, Value: "500"},
+			{Criteria: "on absence", Severity: 2, Value: 300},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	err = e.Dashboard(&apiclient.Dashboard{
+		CID:        "/dashboard/1",
+		Title:      "Web",
+		GridLayout: apiclient.DashboardGridLayout{Height: 4, Width: 4},
+		Widgets: []apiclient.DashboardWidget{{
+			Active:   true,
+			Height:   1,
+			Name:     "Graph",
+			Origin:   "a0",
+			Settings: apiclient.DashboardWidgetSettings{GraphUUID: "abc-123", Period: 3600},
+			Type:     "graph",
+			WidgetID: "w1",
+			Width:    2,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	expected := `import {
+  to = circonus_check.example_web
+  id = "/check_bundle/1"
+}
+
+resource "circonus_check" "example_web" {
+  name = "Example Web"
+  active = true
+  target = "example.com"
+  period = "60s"
+  timeout = "10s"
+  tags = ["service:web"]
+  collector {
+    id = "/broker/1"
+  }
+  http {
+    auth_method = "Basic"
+    url = "https://example.com/$${path}"
+    headers = {
+      "Host" = "example.com"
+    }
+  }
+  metric {
+    name = "duration"
+    type = "numeric"
+    unit = "ms"
+    active = true
+  }
+}
+
+import {
+  to = circonus_graph.example_web
+  id = "/graph/abc-123"
+}
+
+resource "circonus_graph" "example_web" {
+  name = "Example Web"
+  metric {
+    check = circonus_check.example_web.checks[0]
+    metric_name = "duration"
+    metric_type = "numeric"
+    name = "duration"
+    active = true
+    axis = "l"
+    stack = 1
+  }
+}
+
+import {
+  to = circonus_rule_set.duration
+  id = "/rule_set/11_duration"
+}
+
+resource "circonus_rule_set" "duration" {
+  check = circonus_check.example_web.checks[0]
+  metric_name = "duration"
+  metric_type = "numeric"
+  if {
+    value {
+      max_value = "500"
+    }
+    then {
+      notify = ["/contact_group/1"]
+      severity = 1
+    }
+  }
+  if {
+    value {
+      absent = "300s"
+    }
+    then {
+      severity = 2
+    }
+  }
+}
+
+import {
+  to = circonus_dashboard.web
+  id = "/dashboard/1"
+}
+
+resource "circonus_dashboard" "web" {
+  title = "Web"
+  shared = false
+  account_default = false
+  grid_layout {
+    height = 4
+    width = 4
+  }
+  widget {
+    type = "graph"
+    name = "Graph"
+    active = true
+    height = 1
+    width = 2
+    origin = "a0"
+    widget_id = "w1"
+    settings {
+      graph_id = trimprefix(circonus_graph.example_web.id, "/graph/")
+      period = 3600
+    }
+  }
+}
+
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output\n%s", buf.String())
+	}
+}
+
+func TestResourceName(t *testing.T) {
+	used := map[string]bool{}
+	tests := []struct {
+		title    string
+		expected string
+	}{
+		{"Web Requests", "web_requests"},
+		{"web-requests", "web_requests_2"},
+		{"", "unnamed"},
+		{"5xx errors", "_5xx_errors"},
+	}
+
+	for _, test := range tests {
+		if name := resourceName(test.title, used); name != test.expected {
+			t.Fatalf("%q: unexpected name (%s)", test.title, name)
+		}
+	}
+}
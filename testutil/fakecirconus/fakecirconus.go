@@ -0,0 +1,440 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fakecirconus provides an in-memory fake of the Circonus API, to
+// test code using the API client without an account.
+//
+//	fake, _ := fakecirconus.New(&fakecirconus.Config{
+//		Fixtures: map[string]interface{}{
+//			"/graph/1234": apiclient.Graph{CID: "/graph/1234", Title: "web"},
+//		},
+//	})
+//	defer fake.Close()
+//	apih, _ := apiclient.New(&apiclient.Config{TokenKey: "test", URL: fake.URL})
+//
+// Objects are created (POST), fetched, listed (paged by the from and size
+// parameters), searched, updated (PUT), and deleted. Fixture keys with a
+// query string (e.g. "/graph?search=web") are canned responses returned as
+// is for GET requests of that exact URL.
+//
+// Latency and failures can be injected to exercise timeouts and error
+// handling. Note the client retries 429 and 5xx responses, with a delay.
+package fakecirconus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// firstID is the id of the first object created, ids of created objects
+// are assigned sequentially
+const firstID = 1001
+
+// Config defines the fake API
+type Config struct {
+	// Fixtures are the initial objects, keyed by cid (e.g. /graph/1234), and
+	// canned responses, keyed by request url (e.g. /graph?search=web). Values
+	// are encoded as json.
+	Fixtures map[string]interface{}
+
+	// Latency delays every response
+	Latency time.Duration
+
+	// Failures to inject, see Fail
+	Failures []Failure
+
+	// TokenKey, if set, is required in the X-Circonus-Auth-Token header of
+	// every request, others are rejected (403)
+	TokenKey string
+}
+
+// Failure defines the requests to fail and the response
+type Failure struct {
+	Method string // request method, any if empty
+	Path   string // request path prefix (e.g. /graph), any if empty
+	Status int    // response status - default 500
+	Body   string // response body - default a json error
+	Times  int    // number of requests to fail, 0 for every matching request
+}
+
+// Request defines a request received
+type Request struct {
+	Method string
+	URL    string // path and query
+	Body   []byte
+}
+
+// Server is a fake Circonus API
+type Server struct {
+	// URL of the fake API, use as the client Config URL
+	URL string
+
+	server   *httptest.Server
+	tokenKey string
+
+	mu       sync.Mutex
+	objects  map[string][]byte
+	nextID   int
+	latency  time.Duration
+	failures []*Failure
+	requests []Request
+}
+
+// New returns a running fake API, initialized with the passed config. Close
+// it when done.
+func New(cfg *Config) (*Server, error) {
+	if cfg == nil {
+		return nil, errors.New("invalid fake circonus config (nil)")
+	}
+
+	s := &Server{
+		tokenKey: cfg.TokenKey,
+		objects:  make(map[string][]byte, len(cfg.Fixtures)),
+		nextID:   firstID,
+		latency:  cfg.Latency,
+	}
+	for key, v := range cfg.Fixtures {
+		if err := s.Set(key, v); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range cfg.Failures {
+		s.Fail(f)
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	s.URL = s.server.URL
+
+	return s, nil
+}
+
+// Close shuts down the fake API.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Set stores an object (keyed by cid) or canned response (keyed by request url).
+func (s *Server) Set(key string, v interface{}) error {
+	if key == "" || key[0] != '/' {
+		return errors.Errorf("invalid fixture key (%s)", key)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrapf(err, "encoding fixture %s", key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	return nil
+}
+
+// Object returns the json of a stored object (or canned response), false if
+// there is none.
+func (s *Server) Object(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	return data, ok
+}
+
+// SetLatency changes the delay of every response.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// Fail injects a failure, matching requests receive the failure response
+// instead of being handled. The first matching failure (in the order
+// injected) is used.
+func (s *Server) Fail(f Failure) {
+	if f.Status == 0 {
+		f.Status = http.StatusInternalServerError
+	}
+	if f.Body == "" {
+		f.Body = fmt.Sprintf(`{"code":"%d","message":"injected failure","explanation":"%s"}`, f.Status, http.StatusText(f.Status))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &f)
+}
+
+// Requests returns the requests received, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request{}, s.requests...)
+}
+
+// handle responds to a request
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		respond(w, http.StatusBadRequest, []byte(err.Error()))
+		return
+	}
+
+	authorized := s.tokenKey == "" || r.Header.Get("X-Circonus-Auth-Token") == s.tokenKey
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, URL: r.URL.String(), Body: body})
+	latency := s.latency
+	var failure *Failure
+	if authorized {
+		failure = s.matchFailure(r)
+	}
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(latency):
+		}
+	}
+
+	if !authorized {
+		respond(w, http.StatusForbidden, []byte(`{"code":"Forbidden.BadToken","message":"The authentication token you supplied is invalid","explanation":""}`))
+		return
+	}
+	if failure != nil {
+		respond(w, failure.Status, []byte(failure.Body))
+		return
+	}
+
+	s.mu.Lock()
+	status, resp := s.serve(r, body)
+	s.mu.Unlock()
+
+	respond(w, status, resp)
+}
+
+// matchFailure returns the failure matching a request, nil if none, counting
+// the request against it
+func (s *Server) matchFailure(r *http.Request) *Failure {
+	for i, f := range s.failures {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+		matched := *f
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+		}
+		return &matched
+	}
+	return nil
+}
+
+// serve handles a request with the stored objects, s.mu must be held
+func (s *Server) serve(r *http.Request, body []byte) (int, []byte) {
+	path := strings.TrimPrefix(r.URL.Path, "/v2")
+	notFound := []byte(fmt.Sprintf("not found: %s %s", r.Method, r.URL.String()))
+
+	switch r.Method {
+	case "GET":
+		if data, ok := s.objects[strings.TrimPrefix(r.URL.String(), "/v2")]; ok {
+			return http.StatusOK, data
+		}
+		if isCID(path) {
+			if data, ok := s.objects[path]; ok {
+				return http.StatusOK, data
+			}
+			return http.StatusNotFound, notFound
+		}
+		return s.list(path, r)
+	case "POST":
+		if isCID(path) {
+			return http.StatusNotFound, notFound
+		}
+		return s.create(path, body)
+	case "PUT":
+		if _, ok := s.objects[path]; !ok {
+			return http.StatusNotFound, notFound
+		}
+		obj, err := decodeObject(body)
+		if err != nil {
+			return http.StatusBadRequest, []byte(err.Error())
+		}
+		obj["_cid"] = path
+		data, _ := json.Marshal(obj)
+		s.objects[path] = data
+		return http.StatusOK, data
+	case "DELETE":
+		if _, ok := s.objects[path]; !ok {
+			return http.StatusNotFound, notFound
+		}
+		delete(s.objects, path)
+		return http.StatusNoContent, nil
+	}
+
+	return http.StatusMethodNotAllowed, notFound
+}
+
+// create stores a new object of a type, assigning its cid
+func (s *Server) create(prefix string, body []byte) (int, []byte) {
+	obj, err := decodeObject(body)
+	if err != nil {
+		return http.StatusBadRequest, []byte(err.Error())
+	}
+
+	id := s.nextID
+	s.nextID++
+	cid := fmt.Sprintf("%s/%d", prefix, id)
+	obj["_cid"] = cid
+	if prefix == "/check_bundle" {
+		obj["_checks"] = []string{fmt.Sprintf("/check/%d", id)}
+	}
+
+	data, _ := json.Marshal(obj)
+	s.objects[cid] = data
+	return http.StatusOK, data
+}
+
+// list returns the objects of a type, by cid, matching the search (objects
+// containing the search text) and filters of the request, paged by its
+// from (offset) and size parameters
+func (s *Server) list(prefix string, r *http.Request) (int, []byte) {
+	q := r.URL.Query()
+	search := strings.ToLower(q.Get("search"))
+
+	keys := make([]string, 0)
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix+"/") && !strings.ContainsAny(key[len(prefix)+1:], "/?") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	objs := make([]json.RawMessage, 0, len(keys))
+	for _, key := range keys {
+		obj, err := decodeObject(s.objects[key])
+		if err != nil {
+			continue // not an object, can only be fetched
+		}
+		if search != "" && !strings.Contains(strings.ToLower(string(s.objects[key])), search) {
+			continue
+		}
+		if !matchFilters(obj, q) {
+			continue
+		}
+		objs = append(objs, s.objects[key])
+	}
+
+	if from, err := strconv.Atoi(q.Get("from")); err == nil && from > 0 {
+		if from > len(objs) {
+			from = len(objs)
+		}
+		objs = objs[from:]
+	}
+	if size, err := strconv.Atoi(q.Get("size")); err == nil && size >= 0 && size < len(objs) {
+		objs = objs[:size]
+	}
+
+	data, _ := json.Marshal(objs)
+	return http.StatusOK, data
+}
+
+// matchFilters reports whether an object matches the filters of a query,
+// f_<field>=value for equal fields and f_<field>_has=value for list fields
+// containing value
+func matchFilters(obj map[string]interface{}, q map[string][]string) bool {
+	for name, vals := range q {
+		if !strings.HasPrefix(name, "f_") {
+			continue
+		}
+		field := strings.TrimPrefix(name, "f_")
+		has := strings.HasSuffix(field, "_has")
+		if has {
+			field = strings.TrimSuffix(field, "_has")
+		}
+		for _, val := range vals {
+			if has && !listHas(obj[field], val) {
+				return false
+			}
+			if !has && valueString(obj[field]) != val {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// listHas reports whether a list contains a value
+func listHas(v interface{}, val string) bool {
+	list, ok := v.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, item := range list {
+		if valueString(item) == val {
+			return true
+		}
+	}
+	return false
+}
+
+// valueString returns the query string form of a value
+func valueString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	case bool:
+		return strconv.FormatBool(t)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// decodeObject decodes a json object
+func decodeObject(data []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil {
+		return nil, errors.Wrap(err, "parsing object")
+	}
+	if obj == nil {
+		return nil, errors.New("invalid object (null)")
+	}
+	return obj, nil
+}
+
+// isCID reports whether a path is an object cid (e.g. /graph/1234) rather
+// than a type (e.g. /graph)
+func isCID(path string) bool {
+	return strings.Count(path, "/") > 1
+}
+
+// respond writes a json response
+func respond(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if len(body) > 0 {
+		w.Write(body)
+		w.Write([]byte("\n"))
+	}
+}
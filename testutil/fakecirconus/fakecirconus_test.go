@@ -0,0 +1,195 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fakecirconus
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	apiclient "github.com/circonus-labs/go-apiclient"
+)
+
+func testBootstrap(t *testing.T, cfg *Config) (*apiclient.API, *Server) {
+	fake, err := New(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	apih, err := apiclient.New(&apiclient.Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      fake.URL,
+	})
+	if err != nil {
+		fake.Close()
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	return apih, fake
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		id          string
+		cfg         *Config
+		expectedErr string
+	}{
+		{"invalid (nil)", nil, "invalid fake circonus config (nil)"},
+		{"invalid (key)", &Config{Fixtures: map[string]interface{}{"graph/1": nil}}, "invalid fixture key (graph/1)"},
+		{"invalid (fixture)", &Config{Fixtures: map[string]interface{}{"/graph/1": func() {}}}, "encoding fixture /graph/1: json: unsupported type: func()"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			_, err := New(test.cfg)
+			if err == nil {
+				t.Fatal("expected error")
+			} else if err.Error() != test.expectedErr {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+		})
+	}
+}
+
+func TestCRUD(t *testing.T) {
+	apih, fake := testBootstrap(t, &Config{
+		Fixtures: map[string]interface{}{
+			"/graph/1":          apiclient.Graph{CID: "/graph/1", Title: "web requests", Tags: []string{"service:web"}},
+			"/graph/2":          apiclient.Graph{CID: "/graph/2", Title: "db queries", Tags: []string{"service:db"}},
+			"/graph?search=foo": []apiclient.Graph{{CID: "/graph/9", Title: "canned"}},
+		},
+		TokenKey: "abc123",
+	})
+	defer fake.Close()
+
+	t.Run("fetch", func(t *testing.T) {
+		cid := "/graph/1"
+		graph, err := apih.FetchGraph(apiclient.CIDType(&cid))
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if graph.Title != "web requests" {
+			t.Fatalf("unexpected graph (%#v)", graph)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		tests := []struct {
+			id       string
+			search   apiclient.SearchQueryType
+			filter   apiclient.SearchFilterType
+			expected []string
+		}{
+			{"search", "WEB", nil, []string{"/graph/1"}},
+			{"filter", "", apiclient.SearchFilterType{"f_tags_has": {"service:db"}}, []string{"/graph/2"}},
+			{"filter (field)", "", apiclient.SearchFilterType{"f_title": {"web requests"}}, []string{"/graph/1"}},
+			{"paged", "", apiclient.SearchFilterType{"size": {"1"}, "from": {"1"}}, []string{"/graph/2"}},
+			{"paged (past end)", "", apiclient.SearchFilterType{"size": {"1"}, "from": {"5"}}, []string{}},
+			{"canned", "foo", nil, []string{"/graph/9"}},
+			{"none", "bar", nil, []string{}},
+		}
+		for _, test := range tests {
+			graphs, err := apih.SearchGraphs(&test.search, &test.filter)
+			if err != nil {
+				t.Fatalf("%s: unexpected error (%s)", test.id, err)
+			}
+			cids := []string{}
+			for _, g := range *graphs {
+				cids = append(cids, g.CID)
+			}
+			if !reflect.DeepEqual(cids, test.expected) {
+				t.Fatalf("%s: unexpected graphs (%v)", test.id, cids)
+			}
+		}
+	})
+
+	t.Run("create, update, delete", func(t *testing.T) {
+		bundle, err := apih.CreateCheckBundle(&apiclient.CheckBundle{DisplayName: "web", Type: "http", Target: "example.com"})
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if bundle.CID != "/check_bundle/1001" || !reflect.DeepEqual(bundle.Checks, []string{"/check/1001"}) {
+			t.Fatalf("unexpected bundle (%#v)", bundle)
+		}
+
+		bundle.DisplayName = "web (updated)"
+		if _, err := apih.UpdateCheckBundle(bundle); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		data, ok := fake.Object("/check_bundle/1001")
+		if !ok || !strings.Contains(string(data), `"display_name":"web (updated)"`) {
+			t.Fatalf("unexpected stored bundle (%s)", data)
+		}
+
+		if _, err := apih.DeleteCheckBundleByCID(apiclient.CIDType(&bundle.CID)); err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if _, ok := fake.Object("/check_bundle/1001"); ok {
+			t.Fatal("expected bundle to be deleted")
+		}
+		if _, err := apih.DeleteCheckBundleByCID(apiclient.CIDType(&bundle.CID)); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("requests", func(t *testing.T) {
+		reqs := fake.Requests()
+		if len(reqs) == 0 || reqs[0].Method != "GET" || reqs[0].URL != "/graph/1" {
+			t.Fatalf("unexpected requests (%v)", reqs)
+		}
+	})
+
+	t.Run("token", func(t *testing.T) {
+		bad, err := apiclient.New(&apiclient.Config{TokenKey: "xyz", TokenApp: "test", URL: fake.URL})
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		if _, err := bad.Get("/graph/1"); err == nil || !strings.Contains(err.Error(), "API response code 403") {
+			t.Fatalf("unexpected error (%v)", err)
+		}
+	})
+}
+
+func TestFailures(t *testing.T) {
+	apih, fake := testBootstrap(t, &Config{
+		Fixtures: map[string]interface{}{"/graph/1": apiclient.Graph{CID: "/graph/1"}},
+		Failures: []Failure{{Method: "GET", Path: "/graph", Status: http.StatusNotFound, Times: 1}},
+	})
+	defer fake.Close()
+
+	if _, err := apih.Get("/graph/1"); err == nil || !strings.Contains(err.Error(), "API response code 404") {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+	if _, err := apih.Get("/graph/1"); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	fake.Fail(Failure{Path: "/graph", Status: http.StatusBadRequest, Body: `{"code":"bad"}`})
+	for i := 0; i < 2; i++ {
+		if _, err := apih.Get("/graph/1"); err == nil || err.Error() != `API response code 400: {"code":"bad"}`+"\n" {
+			t.Fatalf("unexpected error (%v)", err)
+		}
+	}
+}
+
+func TestLatency(t *testing.T) {
+	apih, fake := testBootstrap(t, &Config{
+		Fixtures: map[string]interface{}{"/graph/1": apiclient.Graph{CID: "/graph/1"}},
+	})
+	defer fake.Close()
+
+	fake.SetLatency(50 * time.Millisecond)
+	start := time.Now()
+	if _, err := apih.Get("/graph/1"); err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("expected latency, took %s", elapsed)
+	}
+}
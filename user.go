@@ -11,10 +11,8 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
-	"regexp"
 	"strings"
 
 	"github.com/circonus-labs/go-apiclient/config"
@@ -23,21 +21,107 @@ import (
 
 // UserContactInfo defines known contact details
 type UserContactInfo struct {
-	SMS  string `json:"sms,omitempty"`  // string
-	XMPP string `json:"xmpp,omitempty"` // string
+	SMS          string `json:"sms,omitempty"`            // string
+	SMSVerified  *bool  `json:"_sms_verified,omitempty"`  // bool or omitted, NOTE not settable - return/information value only
+	XMPP         string `json:"xmpp,omitempty"`           // string
+	XMPPVerified *bool  `json:"_xmpp_verified,omitempty"` // bool or omitted, NOTE not settable - return/information value only
+}
+
+// User contact methods, as used by contact group user entries
+const (
+	UserContactEmail = "email"
+	UserContactSMS   = "sms"
+	UserContactXMPP  = "xmpp"
+)
+
+// AccountRole defines the role of a user within an account
+type AccountRole string
+
+// Account roles, in increasing order of privilege
+const (
+	AccountRoleReadOnly = AccountRole("Read-Only")
+	AccountRoleNormal   = AccountRole("Normal")
+	AccountRoleAdmin    = AccountRole("Admin")
+)
+
+// rank returns the privilege level of the role, 0 if unknown
+func (r AccountRole) rank() int {
+	switch {
+	case strings.EqualFold(string(r), string(AccountRoleAdmin)):
+		return 3
+	case strings.EqualFold(string(r), string(AccountRoleNormal)):
+		return 2
+	case strings.EqualFold(string(r), string(AccountRoleReadOnly)), strings.EqualFold(string(r), "Read Only"):
+		return 1
+	}
+	return 0
+}
+
+// Allows reports whether the role grants at least the privileges of the passed role.
+func (r AccountRole) Allows(required AccountRole) bool {
+	have, need := r.rank(), required.rank()
+	return have > 0 && need > 0 && have >= need
 }
 
 // User defines a user. See https://login.circonus.com/resources/api/calls/user for more information.
 type User struct {
-	CID         string          `json:"_cid,omitempty"`         // string
-	ContactInfo UserContactInfo `json:"contact_info,omitempty"` // UserContactInfo
-	Email       string          `json:"email"`                  // string
-	Firstname   string          `json:"firstname"`              // string
-	Lastname    string          `json:"lastname"`               // string
+	CID         string                 `json:"_cid,omitempty"`         // string
+	ContactInfo UserContactInfo        `json:"contact_info,omitempty"` // UserContactInfo
+	Email       string                 `json:"email"`                  // string
+	Firstname   string                 `json:"firstname"`              // string
+	Lastname    string                 `json:"lastname"`               // string
+	Roles       map[string]AccountRole `json:"-"`                      // NOTE not part of the user object, role keyed by account cid - see FetchUserRoles
+}
+
+// Role returns the user's role in the account with passed cid. Roles are only
+// known after FetchUserRoles (or WhoAmI) has been used to populate them.
+func (u *User) Role(accountCID string) (AccountRole, bool) {
+	if u == nil || u.Roles == nil {
+		return "", false
+	}
+	if !strings.HasPrefix(accountCID, config.AccountPrefix) {
+		accountCID = fmt.Sprintf("%s/%s", config.AccountPrefix, accountCID)
+	}
+	role, ok := u.Roles[accountCID]
+	return role, ok
+}
+
+// HasRole reports whether the user has at least the passed role in the account with passed cid.
+func (u *User) HasRole(accountCID string, role AccountRole) bool {
+	have, ok := u.Role(accountCID)
+	return ok && have.Allows(role)
+}
+
+// IsAdmin reports whether the user has admin privileges in the account with passed cid.
+func (u *User) IsAdmin(accountCID string) bool {
+	return u.HasRole(accountCID, AccountRoleAdmin)
+}
+
+// setRoles populates the user's roles from the passed accounts, the account owner is an admin
+func (u *User) setRoles(accounts []Account) {
+	u.Roles = make(map[string]AccountRole, len(accounts))
+	for _, acct := range accounts {
+		if acct.OwnerCID != "" && acct.OwnerCID == u.CID {
+			u.Roles[acct.CID] = AccountRoleAdmin
+			continue
+		}
+		for _, au := range acct.Users {
+			if au.UserCID == u.CID {
+				u.Roles[acct.CID] = AccountRole(au.Role)
+				break
+			}
+		}
+	}
 }
 
 // FetchUser retrieves user with passed cid. Pass nil for '/user/current'.
 func (a *API) FetchUser(cid CIDType) (*User, error) {
+	return a.FetchUserWithContext(context.Background(), cid)
+}
+
+// FetchUserWithContext retrieves user with passed cid, stopping when ctx is
+// done.
+func (a *API) FetchUserWithContext(ctx context.Context, cid CIDType) (*User, error) {
 	var userCID string
 
 	switch {
@@ -51,25 +135,21 @@ func (a *API) FetchUser(cid CIDType) (*User, error) {
 		userCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.UserCIDRegex, userCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.UserPrefix, userCID) {
 		return nil, errors.Errorf("invalid user CID (%s)", userCID)
 	}
 
-	result, err := a.Get(userCID)
+	result, err := a.GetWithContext(ctx, userCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching user")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch user, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch user, received JSON: %s", string(result))
 	}
 
 	user := new(User)
-	if err := json.Unmarshal(result, user); err != nil {
+	if err := a.unmarshalJSON(result, user); err != nil {
 		return nil, errors.Wrap(err, "parsing user")
 	}
 
@@ -78,14 +158,15 @@ func (a *API) FetchUser(cid CIDType) (*User, error) {
 
 // FetchUsers retrieves all users available to API Token.
 func (a *API) FetchUsers() (*[]User, error) {
-	result, err := a.Get(config.UserPrefix)
-	if err != nil {
-		return nil, errors.Wrap(err, "fetching users")
-	}
+	return a.FetchUsersWithContext(context.Background())
+}
 
+// FetchUsersWithContext retrieves all users available to API Token, stopping
+// when ctx is done.
+func (a *API) FetchUsersWithContext(ctx context.Context) (*[]User, error) {
 	var users []User
-	if err := json.Unmarshal(result, &users); err != nil {
-		return nil, errors.Wrap(err, "parsing users")
+	if err := a.getJSONContext(ctx, config.UserPrefix, &users); err != nil {
+		return nil, errors.Wrap(err, "fetching users")
 	}
 
 	return &users, nil
@@ -93,36 +174,37 @@ func (a *API) FetchUsers() (*[]User, error) {
 
 // UpdateUser updates passed user.
 func (a *API) UpdateUser(cfg *User) (*User, error) {
+	return a.UpdateUserWithContext(context.Background(), cfg)
+}
+
+// UpdateUserWithContext updates passed user, stopping when ctx is done.
+func (a *API) UpdateUserWithContext(ctx context.Context, cfg *User) (*User, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid user config (nil)")
 	}
 
 	userCID := cfg.CID
 
-	matched, err := regexp.MatchString(config.UserCIDRegex, userCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.UserPrefix, userCID) {
 		return nil, errors.Errorf("invalid user CID (%s)", userCID)
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("update user, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("update user, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Put(userCID, jsonCfg)
+	result, err := a.PutWithContext(ctx, userCID, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "updating user")
 	}
 
 	user := &User{}
-	if err := json.Unmarshal(result, user); err != nil {
+	if err := a.unmarshalJSON(result, user); err != nil {
 		return nil, errors.Wrap(err, "parsing user")
 	}
 
@@ -133,34 +215,246 @@ func (a *API) UpdateUser(cfg *User) (*User, error) {
 // are not supported by the user endpoint). Pass nil as filter for all
 // users available to the API Token.
 func (a *API) SearchUsers(filterCriteria *SearchFilterType) (*[]User, error) {
-	q := url.Values{}
+	return a.SearchUsersWithContext(context.Background(), filterCriteria)
+}
 
-	if filterCriteria != nil && len(*filterCriteria) > 0 {
-		for filter, criteria := range *filterCriteria {
-			for _, val := range criteria {
-				q.Add(filter, val)
-			}
+// SearchUsersWithContext returns users matching a filter (search queries are
+// not supported by the user endpoint), stopping when ctx is done.
+func (a *API) SearchUsersWithContext(ctx context.Context, filterCriteria *SearchFilterType) (*[]User, error) {
+	if emptySearch(nil, filterCriteria) {
+		return a.FetchUsersWithContext(ctx)
+	}
+
+	var users []User
+	if err := a.searchJSONContext(ctx, config.UserPrefix, nil, filterCriteria, &users); err != nil {
+		return nil, errors.Wrap(err, "searching users")
+	}
+
+	return &users, nil
+}
+
+// FetchCurrentUser retrieves the user associated with the API Token ('/user/current').
+func (a *API) FetchCurrentUser() (*User, error) {
+	return a.FetchCurrentUserWithContext(context.Background())
+}
+
+// FetchCurrentUserWithContext retrieves the user associated with the API
+// Token ('/user/current'), stopping when ctx is done.
+func (a *API) FetchCurrentUserWithContext(ctx context.Context) (*User, error) {
+	cid := config.UserPrefix + "/current"
+	return a.FetchUserWithContext(ctx, CIDType(&cid))
+}
+
+// WhoAmI describes the identity associated with the API Token in use.
+type WhoAmI struct {
+	User     *User                  // the user owning the token
+	Accounts []Account              // accounts accessible to the token
+	Roles    map[string]AccountRole // role of the user, keyed by account cid
+}
+
+// WhoAmI returns the user, accessible accounts, and the user's role in each
+// account for the API Token in use. Useful for validating credentials at startup.
+func (a *API) WhoAmI() (*WhoAmI, error) {
+	user, err := a.FetchCurrentUser()
+	if err != nil {
+		return nil, errors.Wrap(err, "whoami")
+	}
+
+	accounts, err := a.FetchAccounts()
+	if err != nil {
+		return nil, errors.Wrap(err, "whoami")
+	}
+
+	user.setRoles(*accounts)
+
+	who := &WhoAmI{
+		User:     user,
+		Accounts: *accounts,
+		Roles:    user.Roles,
+	}
+
+	return who, nil
+}
+
+// ContactDetail returns the user's contact details for the passed method
+// (email, sms, or xmpp) and whether the detail is known to be verified.
+// Email addresses are always considered verified.
+func (u *User) ContactDetail(method string) (string, bool) {
+	if u == nil {
+		return "", false
+	}
+	verified := func(v *bool) bool {
+		return v != nil && *v
+	}
+	switch method {
+	case UserContactEmail:
+		return u.Email, u.Email != ""
+	case UserContactSMS:
+		return u.ContactInfo.SMS, verified(u.ContactInfo.SMSVerified)
+	case UserContactXMPP:
+		return u.ContactInfo.XMPP, verified(u.ContactInfo.XMPPVerified)
+	}
+	return "", false
+}
+
+// HasContactMethod reports whether the user has contact details for the passed method.
+func (u *User) HasContactMethod(method string) bool {
+	info, _ := u.ContactDetail(method)
+	return info != ""
+}
+
+// SetUserContactInfo sets the contact details of the user with passed cid for
+// the passed method (sms or xmpp). Pass an empty value to remove the method.
+// Changing a detail resets its verification state.
+func (a *API) SetUserContactInfo(cid CIDType, method, value string) (*User, error) {
+	value = strings.TrimSpace(value)
+	switch method {
+	case UserContactSMS:
+		if value != "" && !validUserSMS(value) {
+			return nil, errors.Errorf("invalid user sms number (%s)", value)
 		}
+	case UserContactXMPP:
+		if value != "" && !strings.Contains(value, "@") {
+			return nil, errors.Errorf("invalid user xmpp address (%s)", value)
+		}
+	default:
+		return nil, errors.Errorf("invalid user contact method (%s)", method)
+	}
+
+	if cid == nil || *cid == "" {
+		return nil, errors.New("invalid user CID (none)")
 	}
 
-	if q.Encode() == "" {
-		return a.FetchUsers()
+	user, err := a.FetchUser(cid)
+	if err != nil {
+		return nil, err
 	}
 
-	reqURL := url.URL{
-		Path:     config.UserPrefix,
-		RawQuery: q.Encode(),
+	switch method {
+	case UserContactSMS:
+		if user.ContactInfo.SMS == value {
+			return user, nil
+		}
+		user.ContactInfo.SMS = value
+		user.ContactInfo.SMSVerified = nil
+	case UserContactXMPP:
+		if user.ContactInfo.XMPP == value {
+			return user, nil
+		}
+		user.ContactInfo.XMPP = value
+		user.ContactInfo.XMPPVerified = nil
 	}
 
-	result, err := a.Get(reqURL.String())
+	return a.UpdateUser(user)
+}
+
+// validUserSMS checks for a plausible phone number, digits with optional
+// leading '+' and common separators
+func validUserSMS(number string) bool {
+	digits := 0
+	for i, r := range number {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '+' && i == 0:
+		case r == '-' || r == ' ' || r == '.' || r == '(' || r == ')':
+		default:
+			return false
+		}
+	}
+	return digits >= 7 && digits <= 15
+}
+
+// SearchUsersByEmail returns the users with passed email address (case insensitive).
+func (a *API) SearchUsersByEmail(addr string) (*[]User, error) {
+	return a.SearchUsersByEmailWithContext(context.Background(), addr)
+}
+
+// SearchUsersByEmailWithContext returns the users with passed email address
+// (case insensitive), stopping when ctx is done.
+func (a *API) SearchUsersByEmailWithContext(ctx context.Context, addr string) (*[]User, error) {
+	addr = strings.TrimSpace(addr)
+	if addr == "" {
+		return nil, errors.New("invalid user email (none)")
+	}
+
+	filter := SearchFilterType{"f_email": []string{addr}}
+	users, err := a.SearchUsersWithContext(ctx, &filter)
 	if err != nil {
-		return nil, errors.Wrap(err, "searching users")
+		return nil, err
 	}
 
-	var users []User
-	if err := json.Unmarshal(result, &users); err != nil {
-		return nil, errors.Wrap(err, "parsing user")
+	matches := []User{}
+	for _, u := range *users {
+		if strings.EqualFold(u.Email, addr) {
+			matches = append(matches, u)
+		}
 	}
 
-	return &users, nil
+	return &matches, nil
+}
+
+// FetchUsersInAccount returns the users of the current account having the passed
+// role (e.g. Admin, Normal, Read-Only). Pass an empty role for all users.
+func (a *API) FetchUsersInAccount(role string) (*[]User, error) {
+	return a.FetchUsersInAccountWithContext(context.Background(), role)
+}
+
+// FetchUsersInAccountWithContext returns the users of the current account
+// having the passed role (e.g, stopping when ctx is done.
+func (a *API) FetchUsersInAccountWithContext(ctx context.Context, role string) (*[]User, error) {
+	account, err := a.FetchAccountWithContext(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make(map[string]bool, len(account.Users))
+	for _, u := range account.Users {
+		if role == "" || strings.EqualFold(u.Role, role) {
+			members[u.UserCID] = true
+		}
+	}
+
+	matches := []User{}
+	if len(members) == 0 {
+		return &matches, nil
+	}
+
+	users, err := a.FetchUsersWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range *users {
+		if members[u.CID] {
+			matches = append(matches, u)
+		}
+	}
+
+	return &matches, nil
+}
+
+// FetchUserRoles retrieves the user with passed cid (nil for current) and populates
+// the user's Roles from the accounts accessible to the API Token.
+func (a *API) FetchUserRoles(cid CIDType) (*User, error) {
+	return a.FetchUserRolesWithContext(context.Background(), cid)
+}
+
+// FetchUserRolesWithContext retrieves the user with passed cid (nil for
+// current) and populates the user's Roles from the accounts accessible to the
+// API Token, stopping when ctx is done.
+func (a *API) FetchUserRolesWithContext(ctx context.Context, cid CIDType) (*User, error) {
+	user, err := a.FetchUserWithContext(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := a.FetchAccountsWithContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching user roles")
+	}
+
+	user.setRoles(*accounts)
+
+	return user, nil
 }
@@ -112,6 +112,80 @@ func userTestBootstrap(t *testing.T) (*API, *httptest.Server) {
 	return apih, server
 }
 
+// testUserAccountServer serves the current user and the users and accounts it
+// is related to: testAccount (no role), /account/5678 (owner) and
+// /account/9012 (normal), the current account having users 1234 (admin) and
+// 5678 (normal)
+func testUserAccountServer() *httptest.Server {
+	other := testUser
+	other.CID = "/user/5678"
+	other.Email = "johnny@example.com"
+
+	owned := testAccount
+	owned.CID = "/account/5678"
+	owned.OwnerCID = "/user/1234"
+	member := testAccount
+	member.CID = "/account/9012"
+	member.Users = []AccountUser{{Role: "Normal", UserCID: "/user/1234"}}
+
+	current := testAccount
+	current.Users = []AccountUser{
+		{Role: "Admin", UserCID: "/user/1234"},
+		{Role: "Normal", UserCID: "/user/5678"},
+	}
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		reqURL := r.URL.String()
+		var obj interface{}
+		switch reqURL {
+		case "/user/current":
+			obj = testUser
+		case "/user":
+			obj = []User{testUser, other, {CID: "/user/9999"}}
+		case "/user?f_email=John%40Example.com":
+			obj = []User{testUser, other}
+		case "/user?f_email=nobody%40example.com":
+			obj = []User{}
+		case "/account":
+			obj = []Account{testAccount, owned, member}
+		case "/account/current":
+			obj = current
+		}
+		if obj == nil || r.Method != "GET" {
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, reqURL))
+			return
+		}
+		ret, err := json.Marshal(obj)
+		if err != nil {
+			panic(err)
+		}
+		w.WriteHeader(200)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, string(ret))
+	}
+
+	return httptest.NewServer(http.HandlerFunc(f))
+}
+
+func userAccountTestBootstrap(t *testing.T) (*API, *httptest.Server) {
+	server := testUserAccountServer()
+
+	ac := &Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      server.URL,
+	}
+	apih, err := NewAPI(ac)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+		server.Close()
+		return nil, nil
+	}
+
+	return apih, server
+}
+
 func TestFetchUser(t *testing.T) {
 	apih, server := userTestBootstrap(t)
 	defer server.Close()
@@ -240,3 +314,221 @@ func TestSearchUsers(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchCurrentUser(t *testing.T) {
+	apih, server := userTestBootstrap(t)
+	defer server.Close()
+
+	user, err := apih.FetchCurrentUser()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if user.CID != testUser.CID {
+		t.Fatalf("unexpected user (%s)", user.CID)
+	}
+}
+
+func TestWhoAmI(t *testing.T) {
+	apih, server := userAccountTestBootstrap(t)
+	defer server.Close()
+
+	who, err := apih.WhoAmI()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if who.User.CID != "/user/1234" {
+		t.Fatalf("unexpected user (%s)", who.User.CID)
+	}
+	if len(who.Accounts) != 3 {
+		t.Fatalf("expected 3 accounts, got %d", len(who.Accounts))
+	}
+	if !reflect.DeepEqual(who.Roles, map[string]AccountRole{"/account/5678": AccountRoleAdmin, "/account/9012": AccountRoleNormal}) {
+		t.Fatalf("unexpected roles (%v)", who.Roles)
+	}
+}
+
+func TestUserContactDetail(t *testing.T) {
+	verified := true
+	u := testUser
+	u.ContactInfo.SMSVerified = &verified
+
+	tests := []struct {
+		method           string
+		expectedInfo     string
+		expectedVerified bool
+	}{
+		{UserContactEmail, "john@example.com", true},
+		{UserContactSMS, "123-456-7890", true},
+		{UserContactXMPP, "foobar", false},
+		{"pager", "", false},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.method, func(t *testing.T) {
+			info, ok := u.ContactDetail(test.method)
+			if info != test.expectedInfo || ok != test.expectedVerified {
+				t.Fatalf("unexpected contact info (%s, %t)", info, ok)
+			}
+			if u.HasContactMethod(test.method) != (test.expectedInfo != "") {
+				t.Fatal("unexpected HasContactMethod")
+			}
+		})
+	}
+}
+
+func TestSetUserContactInfo(t *testing.T) {
+	apih, server := userTestBootstrap(t)
+	defer server.Close()
+
+	tests := []struct {
+		id          string
+		cid         string
+		method      string
+		value       string
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (method)", "1234", "pager", "123", true, "invalid user contact method (pager)"},
+		{"invalid (sms)", "1234", UserContactSMS, "call me", true, "invalid user sms number (call me)"},
+		{"invalid (xmpp)", "1234", UserContactXMPP, "foobar", true, "invalid user xmpp address (foobar)"},
+		{"invalid (cid)", "", UserContactSMS, "+1 (555) 123-4567", true, "invalid user CID (none)"},
+		{"sms", "1234", UserContactSMS, "+1 (555) 123-4567", false, ""},
+		{"xmpp", "/user/1234", UserContactXMPP, "john@xmpp.example.com", false, ""},
+		{"remove", "1234", UserContactXMPP, "", false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			user, err := apih.SetUserContactInfo(CIDType(&test.cid), test.method, test.value)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if info, _ := user.ContactDetail(test.method); info != test.value {
+				t.Fatalf("unexpected contact info (%s)", info)
+			}
+		})
+	}
+}
+
+func TestSearchUsersByEmail(t *testing.T) {
+	apih, server := userAccountTestBootstrap(t)
+	defer server.Close()
+
+	tests := []struct {
+		id          string
+		email       string
+		expectedNum int
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (email)", " ", 0, true, "invalid user email (none)"},
+		{"match", "John@Example.com", 1, false, ""},
+		{"no match", "nobody@example.com", 0, false, ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			users, err := apih.SearchUsersByEmail(test.email)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error (%s)", err)
+				} else if len(*users) != test.expectedNum {
+					t.Fatalf("expected %d users, got %d", test.expectedNum, len(*users))
+				}
+			}
+		})
+	}
+}
+
+func TestFetchUsersInAccount(t *testing.T) {
+	apih, server := userAccountTestBootstrap(t)
+	defer server.Close()
+
+	tests := []struct {
+		role        string
+		expectedNum int
+	}{
+		{"", 2},
+		{"admin", 1},
+		{"Normal", 1},
+		{"Read-Only", 0},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.role, func(t *testing.T) {
+			users, err := apih.FetchUsersInAccount(test.role)
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if len(*users) != test.expectedNum {
+				t.Fatalf("expected %d users, got %d", test.expectedNum, len(*users))
+			}
+		})
+	}
+}
+
+func TestAccountRoleAllows(t *testing.T) {
+	tests := []struct {
+		role     AccountRole
+		required AccountRole
+		expected bool
+	}{
+		{AccountRoleAdmin, AccountRoleAdmin, true},
+		{AccountRoleAdmin, AccountRoleReadOnly, true},
+		{AccountRoleNormal, AccountRoleAdmin, false},
+		{AccountRoleNormal, AccountRoleNormal, true},
+		{AccountRole("admin"), AccountRoleNormal, true},
+		{AccountRoleReadOnly, AccountRoleNormal, false},
+		{AccountRole("bogus"), AccountRoleReadOnly, false},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(string(test.role)+">="+string(test.required), func(t *testing.T) {
+			if test.role.Allows(test.required) != test.expected {
+				t.Fatalf("expected %t", test.expected)
+			}
+		})
+	}
+}
+
+func TestFetchUserRoles(t *testing.T) {
+	apih, server := userAccountTestBootstrap(t)
+	defer server.Close()
+
+	user, err := apih.FetchUserRoles(nil)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if user.IsAdmin("/account/1234") {
+		t.Fatal("expected no role in /account/1234")
+	}
+	if !user.IsAdmin("5678") {
+		t.Fatal("expected admin (owner) in /account/5678")
+	}
+	if user.IsAdmin("/account/9012") || !user.HasRole("/account/9012", AccountRoleNormal) {
+		t.Fatal("expected normal role in /account/9012")
+	}
+	if role, ok := user.Role("/account/9012"); !ok || role != AccountRoleNormal {
+		t.Fatalf("unexpected role (%s)", role)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Watch - channels of alert and maintenance window events, by polling
+
+package apiclient
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WatchAlerts polls the alerts (see AlertFeed, and AlertFeedConfig for the
+// search, filter, and interval) and sends their raised, acknowledged, and
+// cleared events on the returned channel, until ctx is done, when the
+// channel is closed (a poll in flight is stopped, see PollWithContext).
+// Polling waits while events are not received.
+func (a *API) WatchAlerts(ctx context.Context, cfg *AlertFeedConfig) <-chan AlertEvent {
+	events := make(chan AlertEvent)
+	feed := a.NewAlertFeed(cfg)
+	feed.Subscribe(func(ev AlertEvent) {
+		select {
+		case events <- ev:
+		case <-ctx.Done():
+		}
+	})
+
+	go func() {
+		defer close(events)
+		feed.Run(ctx) // nolint: errcheck
+	}()
+
+	return events
+}
+
+// MaintenanceEventType defines the type of a maintenance window event
+type MaintenanceEventType string
+
+// Maintenance window events
+const (
+	MaintenanceAdded   = MaintenanceEventType("added")
+	MaintenanceChanged = MaintenanceEventType("changed")
+	MaintenanceRemoved = MaintenanceEventType("removed")
+	MaintenanceStarted = MaintenanceEventType("started")
+	MaintenanceEnded   = MaintenanceEventType("ended")
+)
+
+// MaintenanceEvent defines a change of a maintenance window
+type MaintenanceEvent struct {
+	Type        MaintenanceEventType
+	Maintenance Maintenance // as last seen, for removed windows
+}
+
+// MaintenanceWatchConfig defines the configuration of a maintenance window
+// watch
+type MaintenanceWatchConfig struct {
+	// Search restricts the windows watched (see NewSearch), optional
+	Search *SearchQueryType
+	// Filter restricts the windows watched (e.g. f_type), optional
+	Filter SearchFilterType
+	// Interval between polls - default 30s
+	Interval time.Duration
+	// MaxBackoff caps the delay between polls after errors - default 5m
+	MaxBackoff time.Duration
+}
+
+// maintenanceWatch holds the windows seen by the last poll
+type maintenanceWatch struct {
+	api    *API
+	cfg    MaintenanceWatchConfig
+	seen   map[string]Maintenance
+	active map[string]bool // the windows active at the last poll
+	now    func() time.Time
+}
+
+// WatchMaintenanceWindows polls the maintenance windows and sends events on
+// the returned channel as they are added, changed, or removed, and as they
+// start and end, until ctx is done, when the channel is closed. The first
+// poll sends the current windows as added (and started, if active). Polling
+// waits while events are not received.
+func (a *API) WatchMaintenanceWindows(ctx context.Context, cfg *MaintenanceWatchConfig) <-chan MaintenanceEvent {
+	w := a.newMaintenanceWatch(cfg)
+	events := make(chan MaintenanceEvent)
+
+	go func() {
+		defer close(events)
+		a.pollLoop(ctx, "maintenance watch", w.cfg.Interval, w.cfg.MaxBackoff, func() error { // nolint: errcheck
+			evs, err := w.poll(ctx)
+			for _, ev := range evs {
+				select {
+				case events <- ev:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+			return err
+		})
+	}()
+
+	return events
+}
+
+// newMaintenanceWatch returns a maintenance window watch, with defaults
+func (a *API) newMaintenanceWatch(cfg *MaintenanceWatchConfig) *maintenanceWatch {
+	w := &maintenanceWatch{api: a, seen: make(map[string]Maintenance), active: make(map[string]bool), now: time.Now}
+	if cfg != nil {
+		w.cfg = *cfg
+	}
+	if w.cfg.Interval <= 0 {
+		w.cfg.Interval = defaultAlertFeedInterval
+	}
+	if w.cfg.MaxBackoff <= 0 {
+		w.cfg.MaxBackoff = defaultAlertFeedMaxBackoff
+	}
+	if w.cfg.MaxBackoff < w.cfg.Interval {
+		w.cfg.MaxBackoff = w.cfg.Interval
+	}
+	return w
+}
+
+// poll fetches the windows once, returning the events since the last poll,
+// ordered by window cid
+func (w *maintenanceWatch) poll(ctx context.Context) ([]MaintenanceEvent, error) {
+	var filter *SearchFilterType
+	if len(w.cfg.Filter) > 0 {
+		filter = &w.cfg.Filter
+	}
+	windows, err := w.api.SearchMaintenanceWindowsWithContext(ctx, w.cfg.Search, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "polling maintenance windows")
+	}
+
+	now := uint(w.now().Unix())
+	active := func(m Maintenance) bool { return m.Start <= now && now < m.Stop }
+	current := make(map[string]Maintenance, len(*windows))
+	nowActive := make(map[string]bool)
+	for _, m := range *windows {
+		current[m.CID] = m
+		if active(m) {
+			nowActive[m.CID] = true
+		}
+	}
+
+	var events []MaintenanceEvent
+	for _, cid := range maintenanceCIDs(current, w.seen) {
+		m, ok := current[cid]
+		prev, seen := w.seen[cid]
+		switch {
+		case !ok:
+			events = append(events, MaintenanceEvent{Type: MaintenanceRemoved, Maintenance: prev})
+			if w.active[cid] {
+				events = append(events, MaintenanceEvent{Type: MaintenanceEnded, Maintenance: prev})
+			}
+			continue
+		case !seen:
+			events = append(events, MaintenanceEvent{Type: MaintenanceAdded, Maintenance: m})
+		case !reflect.DeepEqual(m, prev):
+			events = append(events, MaintenanceEvent{Type: MaintenanceChanged, Maintenance: m})
+		}
+		switch {
+		case nowActive[cid] && !w.active[cid]:
+			events = append(events, MaintenanceEvent{Type: MaintenanceStarted, Maintenance: m})
+		case !nowActive[cid] && w.active[cid]:
+			events = append(events, MaintenanceEvent{Type: MaintenanceEnded, Maintenance: m})
+		}
+	}
+
+	w.seen, w.active = current, nowActive
+	return events, nil
+}
+
+// maintenanceCIDs returns the cids of the windows of both maps, sorted
+func maintenanceCIDs(a, b map[string]Maintenance) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,199 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testMaintenanceWatchServer serves the maintenance windows
+func testMaintenanceWatchServer(mu *sync.Mutex, windows *[]Maintenance) *httptest.Server {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		reqURL := r.URL.String()
+		switch {
+		case r.Method == "GET" && reqURL == "/maintenance":
+			ret, err := json.Marshal(*windows)
+			if err != nil {
+				panic(err)
+			}
+			w.WriteHeader(200)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintln(w, string(ret))
+		default:
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, reqURL))
+		}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(f))
+}
+
+// watchTestBootstrap returns an API handle for the server of a watch test
+func watchTestBootstrap(t *testing.T, server *httptest.Server) *API {
+	apih, err := NewAPI(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL})
+	if err != nil {
+		server.Close()
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	return apih
+}
+
+func TestWatchAlerts(t *testing.T) {
+	var mu sync.Mutex
+	active := []Alert{{CID: "/alert/1", OccurredOn: uint(time.Now().Unix())}}
+	server := testAlertFeedServer(&mu, "f__cleared_on=null", &active, nil)
+	defer server.Close()
+	apih := watchTestBootstrap(t, server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := apih.WatchAlerts(ctx, &AlertFeedConfig{Interval: 10 * time.Millisecond, Since: time.Now().Add(-time.Minute)})
+
+	select {
+	case ev := <-events:
+		if ev.Type != AlertRaised || ev.Alert.CID != "/alert/1" {
+			t.Fatalf("unexpected event (%+v)", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for raised event")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("expected no more events")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the channel to close")
+	}
+}
+
+func TestWatchAlertsCancel(t *testing.T) {
+	requested := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case requested <- struct{}{}:
+		default:
+		}
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	apih, err := New(&Config{TokenKey: "abc123", TokenApp: "test", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	// the channel is closed without waiting for the poll in flight
+	ctx, cancel := context.WithCancel(context.Background())
+	events := apih.WatchAlerts(ctx, &AlertFeedConfig{Interval: time.Minute})
+	<-requested
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("expected no events")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the channel to close")
+	}
+}
+
+func TestMaintenanceWatch(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	var mu sync.Mutex
+	windows := []Maintenance{
+		{CID: "/maintenance/1", Start: 1499990000, Stop: 1500010000},
+		{CID: "/maintenance/2", Start: 1500005000, Stop: 1500010000},
+	}
+	setWindows := func(m ...Maintenance) {
+		mu.Lock()
+		windows = m
+		mu.Unlock()
+	}
+	server := testMaintenanceWatchServer(&mu, &windows)
+	defer server.Close()
+	apih := watchTestBootstrap(t, server)
+
+	w := apih.newMaintenanceWatch(nil)
+	w.now = func() time.Time { return now }
+
+	poll := func(expected ...string) {
+		t.Helper()
+		events, err := w.poll(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error (%s)", err)
+		}
+		var got []string
+		for _, ev := range events {
+			got = append(got, ev.Maintenance.CID+" "+string(ev.Type))
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+	}
+
+	// current windows added, and started if active
+	poll("/maintenance/1 added", "/maintenance/1 started", "/maintenance/2 added")
+	poll()
+
+	// changed, and started as time passes
+	now = time.Unix(1500006000, 0)
+	setWindows(
+		Maintenance{CID: "/maintenance/1", Start: 1499990000, Stop: 1500010000, Notes: "extended"},
+		Maintenance{CID: "/maintenance/2", Start: 1500005000, Stop: 1500010000},
+	)
+	poll("/maintenance/1 changed", "/maintenance/2 started")
+
+	// removed while active, ended
+	setWindows(Maintenance{CID: "/maintenance/2", Start: 1500005000, Stop: 1500010000})
+	poll("/maintenance/1 removed", "/maintenance/1 ended")
+
+	now = time.Unix(1500010000, 0)
+	poll("/maintenance/2 ended")
+}
+
+func TestWatchMaintenanceWindows(t *testing.T) {
+	var mu sync.Mutex
+	windows := []Maintenance{{CID: "/maintenance/1"}}
+	server := testMaintenanceWatchServer(&mu, &windows)
+	defer server.Close()
+	apih := watchTestBootstrap(t, server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := apih.WatchMaintenanceWindows(ctx, &MaintenanceWatchConfig{Interval: 10 * time.Millisecond})
+
+	select {
+	case ev := <-events:
+		if ev.Type != MaintenanceAdded || ev.Maintenance.CID != "/maintenance/1" {
+			t.Fatalf("unexpected event (%+v)", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for added event")
+	}
+
+	cancel()
+	for range events {
+		// drained until closed
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package webhook decodes the JSON payloads Circonus HTTP contact methods POST
+// when alerts are raised or cleared.
+//
+//	http.Handle("/circonus", webhook.Handler(func(p *webhook.Payload) error {
+//		for _, alert := range p.Alerts {
+//			log.Printf("%s %s sev %d", alert.Event(), alert.MetricName, alert.Severity)
+//		}
+//		return nil
+//	}))
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Alert events, see Alert.Event
+const (
+	EventRaised  = "raised"
+	EventCleared = "cleared"
+)
+
+// maxPayloadSize limits the size of payloads read by ParseRequest and Handler
+const maxPayloadSize = 10 << 20
+
+// timeLayouts are the formats used for alert and clear times in payloads
+var timeLayouts = []string{
+	"Mon, 02 Jan 2006 15:04:05",
+	"Mon, 02 Jan 2006 15:04:05 MST",
+	time.RFC1123Z,
+	time.RFC3339,
+}
+
+// Alert defines a single alert in a webhook payload
+type Alert struct {
+	AccountName   string   `json:"account_name"`              // string
+	AlertID       string   `json:"alert_id"`                  // string
+	AlertTime     string   `json:"alert_time"`                // string
+	AlertURL      string   `json:"alert_url"`                 // string
+	AlertValue    string   `json:"alert_value"`               // string
+	BrokerName    string   `json:"broker_name,omitempty"`     // string
+	CheckBundleID string   `json:"check_bundle_id,omitempty"` // string
+	CheckID       string   `json:"check_id,omitempty"`        // string
+	CheckName     string   `json:"check_name"`                // string
+	ClearTime     string   `json:"clear_time,omitempty"`      // string, empty if the alert is raised
+	ClearValue    string   `json:"clear_value,omitempty"`     // string, empty if the alert is raised
+	Host          string   `json:"host,omitempty"`            // string
+	MetricName    string   `json:"metric_name"`               // string
+	MetricNotes   string   `json:"metric_notes,omitempty"`    // string
+	RuleSetID     string   `json:"rule_set_id,omitempty"`     // string
+	Severity      uint8    `json:"severity"`                  // uint8 1-5
+	Tags          []string `json:"tags,omitempty"`            // [] len >= 0
+}
+
+// Event returns EventCleared if the alert has cleared, otherwise EventRaised.
+func (a *Alert) Event() string {
+	if a.ClearTime != "" {
+		return EventCleared
+	}
+	return EventRaised
+}
+
+// RaisedAt returns the time the alert was raised.
+func (a *Alert) RaisedAt() (time.Time, error) {
+	return parseTime(a.AlertTime)
+}
+
+// ClearedAt returns the time the alert cleared, zero if the alert has not cleared.
+func (a *Alert) ClearedAt() (time.Time, error) {
+	if a.ClearTime == "" {
+		return time.Time{}, nil
+	}
+	return parseTime(a.ClearTime)
+}
+
+// Validate checks the alert has the attributes receivers rely on.
+func (a *Alert) Validate() error {
+	if a.AlertID == "" {
+		return errors.New("invalid alert, alert_id (none)")
+	}
+	if a.Severity < 1 || a.Severity > 5 {
+		return errors.Errorf("invalid alert %s, severity (%d)", a.AlertID, a.Severity)
+	}
+	if a.MetricName == "" {
+		return errors.Errorf("invalid alert %s, metric_name (none)", a.AlertID)
+	}
+	if _, err := a.RaisedAt(); err != nil {
+		return errors.Wrapf(err, "invalid alert %s, alert_time", a.AlertID)
+	}
+	if _, err := a.ClearedAt(); err != nil {
+		return errors.Wrapf(err, "invalid alert %s, clear_time", a.AlertID)
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes an alert, accepting numeric or string ids.
+func (a *Alert) UnmarshalJSON(b []byte) error {
+	type alert Alert
+	var raw struct {
+		alert
+		AlertID       json.Number `json:"alert_id"`
+		CheckBundleID json.Number `json:"check_bundle_id,omitempty"`
+		CheckID       json.Number `json:"check_id,omitempty"`
+		RuleSetID     json.Number `json:"rule_set_id,omitempty"`
+		Severity      json.Number `json:"severity"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*a = Alert(raw.alert)
+	a.AlertID = raw.AlertID.String()
+	a.CheckBundleID = raw.CheckBundleID.String()
+	a.CheckID = raw.CheckID.String()
+	a.RuleSetID = raw.RuleSetID.String()
+	if raw.Severity != "" {
+		sev, err := strconv.ParseUint(raw.Severity.String(), 10, 8)
+		if err != nil {
+			return errors.Errorf("invalid alert severity (%s)", raw.Severity)
+		}
+		a.Severity = uint8(sev)
+	}
+
+	return nil
+}
+
+// Payload defines the body of a webhook POST
+type Payload struct {
+	Alerts []Alert `json:"alerts"` // [] len >= 1
+}
+
+// Validate checks the payload and each alert in it.
+func (p *Payload) Validate() error {
+	if len(p.Alerts) == 0 {
+		return errors.New("invalid payload, alerts (none)")
+	}
+	for i := range p.Alerts {
+		if err := p.Alerts[i].Validate(); err != nil {
+			return errors.Wrapf(err, "alert %d", i)
+		}
+	}
+	return nil
+}
+
+// Parse decodes and validates a webhook payload.
+func Parse(r io.Reader) (*Payload, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var p Payload
+	if err := dec.Decode(&p); err != nil {
+		return nil, errors.Wrap(err, "parsing webhook payload")
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// ParseRequest decodes and validates the payload of a webhook request.
+func ParseRequest(r *http.Request) (*Payload, error) {
+	if r.Method != http.MethodPost {
+		return nil, errors.Errorf("invalid webhook request method (%s)", r.Method)
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil || (mt != "application/json" && !strings.HasSuffix(mt, "+json")) {
+			return nil, errors.Errorf("invalid webhook request content type (%s)", ct)
+		}
+	}
+
+	defer r.Body.Close()
+	return Parse(io.LimitReader(r.Body, maxPayloadSize))
+}
+
+// Handler returns an http.Handler which parses webhook requests and calls fn
+// with each payload. Invalid requests are rejected with 400; errors returned by
+// fn result in a 500 so Circonus retries the notification.
+func Handler(fn func(*Payload) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := ParseRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := fn(p); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, errors.New("invalid time (none)")
+	}
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.Errorf("invalid time (%s)", s)
+}
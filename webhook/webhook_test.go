@@ -0,0 +1,144 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPayload = `{
+	"alerts": [
+		{
+			"account_name": "hoopers-store",
+			"alert_id": 12345,
+			"alert_time": "Thu, 29 Dec 2016 17:38:22",
+			"alert_url": "https://hoopers-store.circonus.com/fault-detection?alert_id=12345",
+			"alert_value": "1500",
+			"check_id": 1234,
+			"check_name": "www.example.com",
+			"metric_name": "tt_firstbyte",
+			"severity": 2,
+			"tags": ["service:web"]
+		},
+		{
+			"account_name": "hoopers-store",
+			"alert_id": "12346",
+			"alert_time": "1483033102",
+			"alert_value": "0",
+			"check_name": "db.example.com",
+			"clear_time": "Thu, 29 Dec 2016 17:48:22",
+			"clear_value": "1",
+			"metric_name": "up",
+			"severity": "1"
+		}
+	]
+}`
+
+func TestParse(t *testing.T) {
+	p, err := Parse(strings.NewReader(testPayload))
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(p.Alerts) != 2 {
+		t.Fatalf("expected 2 alerts, got %d", len(p.Alerts))
+	}
+
+	raised, cleared := p.Alerts[0], p.Alerts[1]
+	if raised.AlertID != "12345" || raised.CheckID != "1234" || raised.Severity != 2 || raised.Tags[0] != "service:web" {
+		t.Fatalf("unexpected alert (%#v)", raised)
+	}
+	if raised.Event() != EventRaised {
+		t.Fatalf("expected %s, got %s", EventRaised, raised.Event())
+	}
+	if at, _ := raised.RaisedAt(); !at.Equal(time.Date(2016, 12, 29, 17, 38, 22, 0, time.UTC)) {
+		t.Fatalf("unexpected alert time (%s)", at)
+	}
+	if at, _ := raised.ClearedAt(); !at.IsZero() {
+		t.Fatalf("unexpected clear time (%s)", at)
+	}
+
+	if cleared.AlertID != "12346" || cleared.Severity != 1 || cleared.Event() != EventCleared {
+		t.Fatalf("unexpected alert (%#v)", cleared)
+	}
+	if at, _ := cleared.RaisedAt(); at.Unix() != 1483033102 {
+		t.Fatalf("unexpected alert time (%s)", at)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		id          string
+		payload     string
+		expectedErr string
+	}{
+		{"not json", `alert`, "parsing webhook payload: invalid character 'a' looking for beginning of value"},
+		{"no alerts", `{"alerts": []}`, "invalid payload, alerts (none)"},
+		{"no id", `{"alerts": [{"severity": 1}]}`, "alert 0: invalid alert, alert_id (none)"},
+		{"severity", `{"alerts": [{"alert_id": 1, "severity": 7}]}`, "alert 0: invalid alert 1, severity (7)"},
+		{"metric", `{"alerts": [{"alert_id": 1, "severity": 1}]}`, "alert 0: invalid alert 1, metric_name (none)"},
+		{"time", `{"alerts": [{"alert_id": 1, "severity": 1, "metric_name": "up", "alert_time": "yesterday"}]}`, "alert 0: invalid alert 1, alert_time: invalid time (yesterday)"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(test.payload))
+			if err == nil {
+				t.Fatal("expected error")
+			} else if err.Error() != test.expectedErr {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var received *Payload
+	h := Handler(func(p *Payload) error {
+		received = p
+		if p.Alerts[0].MetricName == "fail" {
+			return errors.New("downstream unavailable")
+		}
+		return nil
+	})
+
+	tests := []struct {
+		id          string
+		method      string
+		contentType string
+		body        string
+		expected    int
+	}{
+		{"method", "GET", "", "", http.StatusBadRequest},
+		{"content type", "POST", "application/x-www-form-urlencoded", "alert_id=1", http.StatusBadRequest},
+		{"invalid", "POST", "application/json", `{}`, http.StatusBadRequest},
+		{"handler error", "POST", "application/json", `{"alerts": [{"alert_id": 1, "severity": 1, "metric_name": "fail", "alert_time": "1483033102"}]}`, http.StatusInternalServerError},
+		{"valid", "POST", "application/json; charset=utf-8", testPayload, http.StatusNoContent},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "/circonus", strings.NewReader(test.body))
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != test.expected {
+				t.Fatalf("expected %d, got %d (%s)", test.expected, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	if received == nil || len(received.Alerts) != 2 {
+		t.Fatalf("unexpected payload (%#v)", received)
+	}
+}
@@ -8,10 +8,8 @@
 package apiclient
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
-	"regexp"
 	"strings"
 
 	"github.com/circonus-labs/go-apiclient/config"
@@ -51,6 +49,12 @@ func NewWorksheet() *Worksheet {
 
 // FetchWorksheet retrieves worksheet with passed cid.
 func (a *API) FetchWorksheet(cid CIDType) (*Worksheet, error) {
+	return a.FetchWorksheetWithContext(context.Background(), cid)
+}
+
+// FetchWorksheetWithContext retrieves worksheet with passed cid, stopping
+// when ctx is done.
+func (a *API) FetchWorksheetWithContext(ctx context.Context, cid CIDType) (*Worksheet, error) {
 	if cid == nil || *cid == "" {
 		return nil, errors.New("invalid worksheet CID (none)")
 	}
@@ -62,25 +66,21 @@ func (a *API) FetchWorksheet(cid CIDType) (*Worksheet, error) {
 		worksheetCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.WorksheetCIDRegex, worksheetCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.WorksheetPrefix, worksheetCID) {
 		return nil, errors.Errorf("invalid worksheet CID (%s)", worksheetCID)
 	}
 
-	result, err := a.Get(worksheetCID)
+	result, err := a.GetWithContext(ctx, worksheetCID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching worksheet")
 	}
 
-	if a.Debug {
-		a.Log.Printf("fetch worksheet, received JSON: %s", string(result))
+	if a.debugEnabled() {
+		a.apiLog().Printf("fetch worksheet, received JSON: %s", string(result))
 	}
 
 	worksheet := new(Worksheet)
-	if err := json.Unmarshal(result, worksheet); err != nil {
+	if err := a.unmarshalJSON(result, worksheet); err != nil {
 		return nil, errors.Wrap(err, "parsing worksheet")
 	}
 
@@ -89,14 +89,15 @@ func (a *API) FetchWorksheet(cid CIDType) (*Worksheet, error) {
 
 // FetchWorksheets retrieves all worksheets available to API Token.
 func (a *API) FetchWorksheets() (*[]Worksheet, error) {
-	result, err := a.Get(config.WorksheetPrefix)
-	if err != nil {
-		return nil, errors.Wrap(err, "fetching worksheets")
-	}
+	return a.FetchWorksheetsWithContext(context.Background())
+}
 
+// FetchWorksheetsWithContext retrieves all worksheets available to API Token,
+// stopping when ctx is done.
+func (a *API) FetchWorksheetsWithContext(ctx context.Context) (*[]Worksheet, error) {
 	var worksheets []Worksheet
-	if err := json.Unmarshal(result, &worksheets); err != nil {
-		return nil, errors.Wrap(err, "parsing worksheets")
+	if err := a.getJSONContext(ctx, config.WorksheetPrefix, &worksheets); err != nil {
+		return nil, errors.Wrap(err, "fetching worksheets")
 	}
 
 	return &worksheets, nil
@@ -104,36 +105,38 @@ func (a *API) FetchWorksheets() (*[]Worksheet, error) {
 
 // UpdateWorksheet updates passed worksheet.
 func (a *API) UpdateWorksheet(cfg *Worksheet) (*Worksheet, error) {
+	return a.UpdateWorksheetWithContext(context.Background(), cfg)
+}
+
+// UpdateWorksheetWithContext updates passed worksheet, stopping when ctx is
+// done.
+func (a *API) UpdateWorksheetWithContext(ctx context.Context, cfg *Worksheet) (*Worksheet, error) {
 	if cfg == nil {
 		return nil, errors.Errorf("invalid worksheet config (nil)")
 	}
 
 	worksheetCID := cfg.CID
 
-	matched, err := regexp.MatchString(config.WorksheetCIDRegex, worksheetCID)
-	if err != nil {
-		return nil, err
-	}
-	if !matched {
+	if !validCID(config.WorksheetPrefix, worksheetCID) {
 		return nil, errors.Errorf("invalid worksheet CID (%s)", worksheetCID)
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("update worksheet, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("update worksheet, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Put(worksheetCID, jsonCfg)
+	result, err := a.PutWithContext(ctx, worksheetCID, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "updating worksheet")
 	}
 
 	worksheet := &Worksheet{}
-	if err := json.Unmarshal(result, worksheet); err != nil {
+	if err := a.unmarshalJSON(result, worksheet); err != nil {
 		return nil, errors.Wrap(err, "parsing worksheet")
 	}
 
@@ -142,26 +145,32 @@ func (a *API) UpdateWorksheet(cfg *Worksheet) (*Worksheet, error) {
 
 // CreateWorksheet creates a new worksheet.
 func (a *API) CreateWorksheet(cfg *Worksheet) (*Worksheet, error) {
+	return a.CreateWorksheetWithContext(context.Background(), cfg)
+}
+
+// CreateWorksheetWithContext creates a new worksheet, stopping when ctx is
+// done.
+func (a *API) CreateWorksheetWithContext(ctx context.Context, cfg *Worksheet) (*Worksheet, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid worksheet config (nil)")
 	}
 
-	jsonCfg, err := json.Marshal(cfg)
+	jsonCfg, err := a.marshalJSON(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if a.Debug {
-		a.Log.Printf("create annotation, sending JSON: %s", string(jsonCfg))
+	if a.debugEnabled() {
+		a.apiLog().Printf("create annotation, sending JSON: %s", jsonCfg.String())
 	}
 
-	result, err := a.Post(config.WorksheetPrefix, jsonCfg)
+	result, err := a.PostWithContext(ctx, config.WorksheetPrefix, jsonCfg.Bytes())
 	if err != nil {
 		return nil, errors.Wrap(err, "creating worksheet")
 	}
 
 	worksheet := &Worksheet{}
-	if err := json.Unmarshal(result, worksheet); err != nil {
+	if err := a.unmarshalJSON(result, worksheet); err != nil {
 		return nil, errors.Wrap(err, "parsing worksheet")
 	}
 
@@ -170,14 +179,26 @@ func (a *API) CreateWorksheet(cfg *Worksheet) (*Worksheet, error) {
 
 // DeleteWorksheet deletes passed worksheet.
 func (a *API) DeleteWorksheet(cfg *Worksheet) (bool, error) {
+	return a.DeleteWorksheetWithContext(context.Background(), cfg)
+}
+
+// DeleteWorksheetWithContext deletes passed worksheet, stopping when ctx is
+// done.
+func (a *API) DeleteWorksheetWithContext(ctx context.Context, cfg *Worksheet) (bool, error) {
 	if cfg == nil {
 		return false, errors.New("invalid worksheet config (nil)")
 	}
-	return a.DeleteWorksheetByCID(CIDType(&cfg.CID))
+	return a.DeleteWorksheetByCIDWithContext(ctx, CIDType(&cfg.CID))
 }
 
 // DeleteWorksheetByCID deletes worksheet with passed cid.
 func (a *API) DeleteWorksheetByCID(cid CIDType) (bool, error) {
+	return a.DeleteWorksheetByCIDWithContext(context.Background(), cid)
+}
+
+// DeleteWorksheetByCIDWithContext deletes worksheet with passed cid, stopping
+// when ctx is done.
+func (a *API) DeleteWorksheetByCIDWithContext(ctx context.Context, cid CIDType) (bool, error) {
 	if cid == nil || *cid == "" {
 		return false, errors.New("invalid worksheet CID (none)")
 	}
@@ -189,15 +210,11 @@ func (a *API) DeleteWorksheetByCID(cid CIDType) (bool, error) {
 		worksheetCID = *cid
 	}
 
-	matched, err := regexp.MatchString(config.WorksheetCIDRegex, worksheetCID)
-	if err != nil {
-		return false, err
-	}
-	if !matched {
+	if !validCID(config.WorksheetPrefix, worksheetCID) {
 		return false, errors.Errorf("invalid worksheet CID (%s)", worksheetCID)
 	}
 
-	_, err = a.Delete(worksheetCID)
+	_, err := a.DeleteWithContext(ctx, worksheetCID)
 	if err != nil {
 		return false, errors.Wrap(err, "deleting worksheet")
 	}
@@ -209,38 +226,364 @@ func (a *API) DeleteWorksheetByCID(cid CIDType) (bool, error) {
 // query and/or filter. If nil is passed for both parameters all
 // worksheets will be returned.
 func (a *API) SearchWorksheets(searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Worksheet, error) {
-	q := url.Values{}
+	return a.SearchWorksheetsWithContext(context.Background(), searchCriteria, filterCriteria)
+}
+
+// SearchWorksheetsWithContext returns worksheets matching the specified
+// search query and/or filter, stopping when ctx is done.
+func (a *API) SearchWorksheetsWithContext(ctx context.Context, searchCriteria *SearchQueryType, filterCriteria *SearchFilterType) (*[]Worksheet, error) {
+	if emptySearch(searchCriteria, filterCriteria) {
+		return a.FetchWorksheetsWithContext(ctx)
+	}
+
+	var worksheets []Worksheet
+	if err := a.searchJSONContext(ctx, config.WorksheetPrefix, searchCriteria, filterCriteria, &worksheets); err != nil {
+		return nil, errors.Wrap(err, "searching worksheets")
+	}
 
-	if searchCriteria != nil && *searchCriteria != "" {
-		q.Set("search", string(*searchCriteria))
+	return &worksheets, nil
+}
+
+// AddSmartQuery adds a smart query to the worksheet. Graphs matching the search
+// query are added to the worksheet dynamically; order lists graph CIDs which
+// should be displayed first, in the order given.
+func (ws *Worksheet) AddSmartQuery(name string, query SearchQueryType, order ...string) error {
+	if name == "" {
+		return errors.New("invalid worksheet smart query name (none)")
+	}
+	if query == "" {
+		return errors.Errorf("invalid worksheet smart query %q, query (none)", name)
+	}
+	for _, sq := range ws.SmartQueries {
+		if sq.Name == name {
+			return errors.Errorf("invalid worksheet smart query %q, duplicate name", name)
+		}
+	}
+
+	sq := WorksheetSmartQuery{
+		Name:  name,
+		Order: make([]string, 0, len(order)),
+		Query: string(query),
+	}
+	for _, cid := range order {
+		graphCID, err := worksheetGraphCID(cid)
+		if err != nil {
+			return errors.Wrapf(err, "invalid worksheet smart query %q", name)
+		}
+		sq.Order = append(sq.Order, graphCID)
 	}
 
-	if filterCriteria != nil && len(*filterCriteria) > 0 {
-		for filter, criteria := range *filterCriteria {
-			for _, val := range criteria {
-				q.Add(filter, val)
+	ws.SmartQueries = append(ws.SmartQueries, sq)
+	return nil
+}
+
+// RemoveSmartQuery removes the smart query with the passed name from the worksheet,
+// returns false if no smart query with that name exists.
+func (ws *Worksheet) RemoveSmartQuery(name string) bool {
+	for i, sq := range ws.SmartQueries {
+		if sq.Name == name {
+			ws.SmartQueries = append(ws.SmartQueries[:i], ws.SmartQueries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// FetchWorksheetSmartQueryGraphs returns the graphs currently matching the passed
+// smart query, in the order they would be displayed on the worksheet: graphs listed
+// in the query order first, followed by the remaining matches.
+func (a *API) FetchWorksheetSmartQueryGraphs(sq *WorksheetSmartQuery) (*[]Graph, error) {
+	return a.FetchWorksheetSmartQueryGraphsWithContext(context.Background(), sq)
+}
+
+// FetchWorksheetSmartQueryGraphsWithContext returns the graphs currently
+// matching the passed smart query, in the order they would be displayed on
+// the worksheet: graphs listed in the query order first, followed by the
+// remaining matches, stopping when ctx is done.
+func (a *API) FetchWorksheetSmartQueryGraphsWithContext(ctx context.Context, sq *WorksheetSmartQuery) (*[]Graph, error) {
+	if sq == nil {
+		return nil, errors.New("invalid worksheet smart query (nil)")
+	}
+	if sq.Query == "" {
+		return nil, errors.Errorf("invalid worksheet smart query %q, query (none)", sq.Name)
+	}
+
+	query := SearchQueryType(sq.Query)
+	graphs, err := a.SearchGraphsWithContext(ctx, &query, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching worksheet smart query %q graphs", sq.Name)
+	}
+
+	position := make(map[string]int, len(sq.Order))
+	for i, cid := range sq.Order {
+		position[cid] = i
+	}
+
+	ordered := make([]Graph, 0, len(*graphs))
+	for _, cid := range sq.Order {
+		for _, g := range *graphs {
+			if g.CID == cid {
+				ordered = append(ordered, g)
+				break
 			}
 		}
 	}
+	for _, g := range *graphs {
+		if _, listed := position[g.CID]; !listed {
+			ordered = append(ordered, g)
+		}
+	}
+
+	return &ordered, nil
+}
+
+// AddGraph appends the passed graph to the worksheet, returns false if the
+// graph is already on the worksheet.
+func (ws *Worksheet) AddGraph(cid string) (bool, error) {
+	graphCID, err := worksheetGraphCID(cid)
+	if err != nil {
+		return false, err
+	}
+	for _, g := range ws.Graphs {
+		if g.GraphCID == graphCID {
+			return false, nil
+		}
+	}
+	ws.Graphs = append(ws.Graphs, WorksheetGraph{GraphCID: graphCID})
+	return true, nil
+}
 
-	if q.Encode() == "" {
-		return a.FetchWorksheets()
+// RemoveGraph removes the passed graph from the worksheet, preserving the order
+// of the remaining graphs. Returns false if the graph is not on the worksheet.
+func (ws *Worksheet) RemoveGraph(cid string) (bool, error) {
+	graphCID, err := worksheetGraphCID(cid)
+	if err != nil {
+		return false, err
 	}
+	for i, g := range ws.Graphs {
+		if g.GraphCID == graphCID {
+			ws.Graphs = append(ws.Graphs[:i], ws.Graphs[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
 
-	reqURL := url.URL{
-		Path:     config.WorksheetPrefix,
-		RawQuery: q.Encode(),
+// AddGraphToWorksheet adds the passed graph to the end of the worksheet. The
+// worksheet is only updated if the graph is not already present.
+func (a *API) AddGraphToWorksheet(worksheetCID CIDType, graphCID CIDType) (*Worksheet, error) {
+	if graphCID == nil || *graphCID == "" {
+		return nil, errors.New("invalid graph CID (none)")
 	}
 
-	result, err := a.Get(reqURL.String())
+	worksheet, err := a.FetchWorksheet(worksheetCID)
 	if err != nil {
-		return nil, errors.Wrap(err, "searching worksheets")
+		return nil, err
 	}
 
-	var worksheets []Worksheet
-	if err := json.Unmarshal(result, &worksheets); err != nil {
-		return nil, errors.Wrap(err, "parsing worksheets")
+	added, err := worksheet.AddGraph(*graphCID)
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return worksheet, nil
 	}
 
-	return &worksheets, nil
+	return a.UpdateWorksheet(worksheet)
+}
+
+// RemoveGraphFromWorksheet removes the passed graph from the worksheet. The
+// worksheet is only updated if the graph is present.
+func (a *API) RemoveGraphFromWorksheet(worksheetCID CIDType, graphCID CIDType) (*Worksheet, error) {
+	if graphCID == nil || *graphCID == "" {
+		return nil, errors.New("invalid graph CID (none)")
+	}
+
+	worksheet, err := a.FetchWorksheet(worksheetCID)
+	if err != nil {
+		return nil, err
+	}
+
+	removed, err := worksheet.RemoveGraph(*graphCID)
+	if err != nil {
+		return nil, err
+	}
+	if !removed {
+		return worksheet, nil
+	}
+
+	return a.UpdateWorksheet(worksheet)
+}
+
+// worksheetGraphCID returns the normalized graph CID for use in a worksheet
+func worksheetGraphCID(cid string) (string, error) {
+	if cid == "" {
+		return "", errors.New("invalid graph CID (none)")
+	}
+
+	graphCID := cid
+	if !strings.HasPrefix(graphCID, config.GraphPrefix) {
+		graphCID = fmt.Sprintf("%s/%s", config.GraphPrefix, cid)
+	}
+
+	if !validCID(config.GraphPrefix, graphCID) {
+		return "", errors.Errorf("invalid graph CID (%s)", graphCID)
+	}
+
+	return graphCID, nil
+}
+
+// CloneWorksheet creates a copy of the worksheet with passed cid, substituting
+// graphs according to graphMap (source graph CID -> replacement graph CID). Graphs
+// without an entry in graphMap are matched by title: the replacement is the one
+// other graph with the same title as the source graph. Smart query ordering is
+// rewritten with the same substitutions.
+func (a *API) CloneWorksheet(cid CIDType, graphMap map[string]string) (*Worksheet, error) {
+	src, err := a.FetchWorksheet(cid)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := make(map[string]string, len(graphMap))
+	for from, to := range graphMap {
+		fromCID, err := worksheetGraphCID(from)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid worksheet graph map")
+		}
+		toCID, err := worksheetGraphCID(to)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid worksheet graph map")
+		}
+		mapping[fromCID] = toCID
+	}
+
+	var unmapped []string
+	for _, g := range src.Graphs {
+		if _, ok := mapping[g.GraphCID]; !ok {
+			unmapped = append(unmapped, g.GraphCID)
+		}
+	}
+	if len(unmapped) > 0 {
+		if err := a.mapWorksheetGraphsByTitle(unmapped, mapping); err != nil {
+			return nil, errors.Wrapf(err, "cloning worksheet %s", src.CID)
+		}
+	}
+
+	clone := *src
+	clone.CID = ""
+	clone.Tags = append([]string{}, src.Tags...)
+	clone.Graphs = make([]WorksheetGraph, len(src.Graphs))
+	for i, g := range src.Graphs {
+		clone.Graphs[i] = WorksheetGraph{GraphCID: mapping[g.GraphCID]}
+	}
+	clone.SmartQueries = make([]WorksheetSmartQuery, len(src.SmartQueries))
+	for i, sq := range src.SmartQueries {
+		order := make([]string, len(sq.Order))
+		for j, graphCID := range sq.Order {
+			if to, ok := mapping[graphCID]; ok {
+				graphCID = to
+			}
+			order[j] = graphCID
+		}
+		clone.SmartQueries[i] = WorksheetSmartQuery{Name: sq.Name, Order: order, Query: sq.Query}
+	}
+
+	worksheet, err := a.CreateWorksheet(&clone)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cloning worksheet %s", src.CID)
+	}
+
+	return worksheet, nil
+}
+
+// mapWorksheetGraphsByTitle adds a mapping for each of the passed graph CIDs to
+// the other graph with the same title
+func (a *API) mapWorksheetGraphsByTitle(graphCIDs []string, mapping map[string]string) error {
+	graphs, err := a.FetchGraphs()
+	if err != nil {
+		return err
+	}
+
+	titles := make(map[string]string, len(*graphs))
+	byTitle := make(map[string][]string, len(*graphs))
+	for _, g := range *graphs {
+		titles[g.CID] = g.Title
+		byTitle[g.Title] = append(byTitle[g.Title], g.CID)
+	}
+
+	for _, graphCID := range graphCIDs {
+		title, ok := titles[graphCID]
+		if !ok {
+			return errors.Errorf("no mapping for graph %s (graph not found)", graphCID)
+		}
+		var candidates []string
+		for _, c := range byTitle[title] {
+			if c != graphCID {
+				candidates = append(candidates, c)
+			}
+		}
+		switch len(candidates) {
+		case 0:
+			return errors.Errorf("no mapping for graph %s, no other graph titled %q", graphCID, title)
+		case 1:
+			mapping[graphCID] = candidates[0]
+		default:
+			return errors.Errorf("no mapping for graph %s, multiple graphs titled %q (%s)", graphCID, title, strings.Join(candidates, ", "))
+		}
+	}
+
+	return nil
+}
+
+// SetWorksheetFavorite sets the favorite flag on the worksheet with passed cid.
+// The worksheet is only updated if the flag changes.
+func (a *API) SetWorksheetFavorite(cid CIDType, favorite bool) (*Worksheet, error) {
+	worksheet, err := a.FetchWorksheet(cid)
+	if err != nil {
+		return nil, err
+	}
+
+	if worksheet.Favorite == favorite {
+		return worksheet, nil
+	}
+
+	worksheet.Favorite = favorite
+	return a.UpdateWorksheet(worksheet)
+}
+
+// SetWorksheetDescription sets the description of the worksheet with passed cid,
+// an empty description clears it. The worksheet is only updated if the
+// description changes.
+func (a *API) SetWorksheetDescription(cid CIDType, description string) (*Worksheet, error) {
+	worksheet, err := a.FetchWorksheet(cid)
+	if err != nil {
+		return nil, err
+	}
+
+	current := ""
+	if worksheet.Description != nil {
+		current = *worksheet.Description
+	}
+	if current == description {
+		return worksheet, nil
+	}
+
+	if description == "" {
+		worksheet.Description = nil
+	} else {
+		worksheet.Description = &description
+	}
+	return a.UpdateWorksheet(worksheet)
+}
+
+// FetchFavoriteWorksheets retrieves all worksheets flagged as favorites.
+func (a *API) FetchFavoriteWorksheets() (*[]Worksheet, error) {
+	return a.FetchFavoriteWorksheetsWithContext(context.Background())
+}
+
+// FetchFavoriteWorksheetsWithContext retrieves all worksheets flagged as
+// favorites, stopping when ctx is done.
+func (a *API) FetchFavoriteWorksheetsWithContext(ctx context.Context) (*[]Worksheet, error) {
+	filter := SearchFilterType{"f_favorite": []string{"true"}}
+	return a.SearchWorksheetsWithContext(ctx, nil, &filter)
 }
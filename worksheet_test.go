@@ -140,6 +140,82 @@ func worksheetTestBootstrap(t *testing.T) (*API, *httptest.Server) {
 	return apih, server
 }
 
+// testWorksheetGraphServer serves /worksheet/1234 and the graphs it (and its
+// clones) may reference, and echoes the worksheets created
+func testWorksheetGraphServer() *httptest.Server {
+	src := Worksheet{
+		CID:    "/worksheet/1234",
+		Graphs: []WorksheetGraph{{GraphCID: "/graph/p1"}, {GraphCID: "/graph/p2"}},
+		SmartQueries: []WorksheetSmartQuery{
+			{Name: "web", Order: []string{"/graph/p2", "/graph/x"}, Query: "web"},
+		},
+		Title: "prod",
+	}
+	graphs := []Graph{
+		{CID: "/graph/p1", Title: "latency"},
+		{CID: "/graph/p2", Title: "errors"},
+		{CID: "/graph/s1", Title: "latency"},
+		{CID: "/graph/s2", Title: "errors"},
+		{CID: "/graph/t2", Title: "errors"},
+	}
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		reqURL := r.URL.String()
+		if reqURL == "/worksheet" && r.Method == "POST" {
+			defer r.Body.Close()
+			b, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				panic(err)
+			}
+			w.WriteHeader(200)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintln(w, string(b))
+			return
+		}
+		var obj interface{}
+		switch reqURL {
+		case "/worksheet/1234":
+			obj = src
+		case "/graph":
+			obj = graphs
+		case "/graph?search=web":
+			obj = []Graph{{CID: "/graph/1"}, {CID: "/graph/2"}, {CID: "/graph/3"}}
+		}
+		if obj == nil || r.Method != "GET" {
+			w.WriteHeader(404)
+			fmt.Fprintln(w, fmt.Sprintf("not found: %s %s", r.Method, reqURL))
+			return
+		}
+		ret, err := json.Marshal(obj)
+		if err != nil {
+			panic(err)
+		}
+		w.WriteHeader(200)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, string(ret))
+	}
+
+	return httptest.NewServer(http.HandlerFunc(f))
+}
+
+func worksheetGraphTestBootstrap(t *testing.T) (*API, *httptest.Server) {
+	server := testWorksheetGraphServer()
+
+	ac := &Config{
+		TokenKey: "abc123",
+		TokenApp: "test",
+		URL:      server.URL,
+	}
+	apih, err := NewAPI(ac)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+		server.Close()
+		return nil, nil
+	}
+
+	return apih, server
+}
+
 func TestNewWorksheet(t *testing.T) {
 	worksheet := NewWorksheet()
 	if reflect.TypeOf(worksheet).String() != "*apiclient.Worksheet" {
@@ -385,3 +461,249 @@ func TestSearchWorksheets(t *testing.T) {
 		})
 	}
 }
+
+func TestWorksheetSmartQueries(t *testing.T) {
+	ws := NewWorksheet()
+
+	tests := []struct {
+		id          string
+		name        string
+		query       SearchQueryType
+		order       []string
+		shouldFail  bool
+		expectedErr string
+	}{
+		{"invalid (name)", "", "web", nil, true, "invalid worksheet smart query name (none)"},
+		{"invalid (query)", "web", "", nil, true, `invalid worksheet smart query "web", query (none)`},
+		{"valid", "web", "web servers", []string{"1234", "/graph/5678"}, false, ""},
+		{"invalid (duplicate)", "web", "web", nil, true, `invalid worksheet smart query "web", duplicate name`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			err := ws.AddSmartQuery(test.name, test.query, test.order...)
+			if test.shouldFail {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if err.Error() != test.expectedErr {
+					t.Fatalf("unexpected error (%s)", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+		})
+	}
+
+	expected := []WorksheetSmartQuery{{Name: "web", Order: []string{"/graph/1234", "/graph/5678"}, Query: "web servers"}}
+	if !reflect.DeepEqual(ws.SmartQueries, expected) {
+		t.Fatalf("unexpected smart queries (%#v)", ws.SmartQueries)
+	}
+
+	if ws.RemoveSmartQuery("db") {
+		t.Fatal("expected false (not found)")
+	}
+	if !ws.RemoveSmartQuery("web") || len(ws.SmartQueries) != 0 {
+		t.Fatal("expected smart query to be removed")
+	}
+}
+
+func TestFetchWorksheetSmartQueryGraphs(t *testing.T) {
+	apih, server := worksheetGraphTestBootstrap(t)
+	defer server.Close()
+
+	if _, err := apih.FetchWorksheetSmartQueryGraphs(nil); err == nil {
+		t.Fatal("expected error")
+	}
+
+	sq := &WorksheetSmartQuery{Name: "web", Order: []string{"/graph/3", "/graph/9"}, Query: "web"}
+	graphs, err := apih.FetchWorksheetSmartQueryGraphs(sq)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	var cids []string
+	for _, g := range *graphs {
+		cids = append(cids, g.CID)
+	}
+	if !reflect.DeepEqual(cids, []string{"/graph/3", "/graph/1", "/graph/2"}) {
+		t.Fatalf("unexpected order (%v)", cids)
+	}
+}
+
+func TestWorksheetGraphs(t *testing.T) {
+	ws := NewWorksheet()
+
+	for _, cid := range []string{"/graph/1", "2", "/graph/3"} {
+		if added, err := ws.AddGraph(cid); err != nil || !added {
+			t.Fatalf("expected %s to be added (%v)", cid, err)
+		}
+	}
+	if added, err := ws.AddGraph("/graph/2"); err != nil || added {
+		t.Fatal("expected duplicate to be ignored")
+	}
+	if _, err := ws.AddGraph(""); err == nil || err.Error() != "invalid graph CID (none)" {
+		t.Fatalf("unexpected error (%v)", err)
+	}
+
+	if removed, err := ws.RemoveGraph("2"); err != nil || !removed {
+		t.Fatal("expected graph to be removed")
+	}
+	if removed, err := ws.RemoveGraph("/graph/2"); err != nil || removed {
+		t.Fatal("expected false (not found)")
+	}
+
+	expected := []WorksheetGraph{{GraphCID: "/graph/1"}, {GraphCID: "/graph/3"}}
+	if !reflect.DeepEqual(ws.Graphs, expected) {
+		t.Fatalf("unexpected graphs (%#v)", ws.Graphs)
+	}
+}
+
+func TestAddGraphToWorksheet(t *testing.T) {
+	apih, server := worksheetTestBootstrap(t)
+	defer server.Close()
+
+	wsCID := testWorksheet.CID
+	existing := testWorksheet.Graphs[1].GraphCID
+	newGraph := "/graph/eeeeeeee-0000-1111-2222-0123456789ab"
+	empty := ""
+
+	if _, err := apih.AddGraphToWorksheet(CIDType(&wsCID), CIDType(&empty)); err == nil {
+		t.Fatal("expected error")
+	}
+
+	ws, err := apih.AddGraphToWorksheet(CIDType(&wsCID), CIDType(&existing))
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(ws.Graphs) != len(testWorksheet.Graphs) {
+		t.Fatalf("expected %d graphs, got %d", len(testWorksheet.Graphs), len(ws.Graphs))
+	}
+
+	ws, err = apih.AddGraphToWorksheet(CIDType(&wsCID), CIDType(&newGraph))
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(ws.Graphs) != len(testWorksheet.Graphs)+1 || ws.Graphs[len(ws.Graphs)-1].GraphCID != newGraph {
+		t.Fatalf("unexpected graphs (%#v)", ws.Graphs)
+	}
+}
+
+func TestRemoveGraphFromWorksheet(t *testing.T) {
+	apih, server := worksheetTestBootstrap(t)
+	defer server.Close()
+
+	wsCID := testWorksheet.CID
+	existing := testWorksheet.Graphs[1].GraphCID
+
+	ws, err := apih.RemoveGraphFromWorksheet(CIDType(&wsCID), CIDType(&existing))
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	expected := []WorksheetGraph{testWorksheet.Graphs[0], testWorksheet.Graphs[2]}
+	if !reflect.DeepEqual(ws.Graphs, expected) {
+		t.Fatalf("unexpected graphs (%#v)", ws.Graphs)
+	}
+	if len(testWorksheet.Graphs) != 3 {
+		t.Fatal("expected test worksheet to be unmodified")
+	}
+}
+
+func TestCloneWorksheet(t *testing.T) {
+	apih, server := worksheetGraphTestBootstrap(t)
+	defer server.Close()
+
+	cid := "1234"
+
+	if _, err := apih.CloneWorksheet(CIDType(&cid), nil); err == nil {
+		t.Fatal("expected error")
+	} else if err.Error() != `cloning worksheet /worksheet/1234: no mapping for graph /graph/p2, multiple graphs titled "errors" (/graph/s2, /graph/t2)` {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	ws, err := apih.CloneWorksheet(CIDType(&cid), map[string]string{"p2": "/graph/s2"})
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+
+	if ws.CID != "" {
+		t.Fatalf("unexpected cid (%s)", ws.CID)
+	}
+	expected := []WorksheetGraph{{GraphCID: "/graph/s1"}, {GraphCID: "/graph/s2"}}
+	if !reflect.DeepEqual(ws.Graphs, expected) {
+		t.Fatalf("unexpected graphs (%#v)", ws.Graphs)
+	}
+	if !reflect.DeepEqual(ws.SmartQueries[0].Order, []string{"/graph/s2", "/graph/x"}) {
+		t.Fatalf("unexpected smart query order (%v)", ws.SmartQueries[0].Order)
+	}
+}
+
+func TestSetWorksheetFavorite(t *testing.T) {
+	apih, server := worksheetTestBootstrap(t)
+	defer server.Close()
+
+	cid := testWorksheet.CID
+
+	ws, err := apih.SetWorksheetFavorite(CIDType(&cid), false)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if ws.Favorite {
+		t.Fatal("expected favorite to be false")
+	}
+
+	ws, err = apih.SetWorksheetFavorite(CIDType(&cid), true)
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if !ws.Favorite {
+		t.Fatal("expected favorite to be true")
+	}
+}
+
+func TestSetWorksheetDescription(t *testing.T) {
+	apih, server := worksheetTestBootstrap(t)
+	defer server.Close()
+
+	cid := testWorksheet.CID
+
+	tests := []struct {
+		id          string
+		description string
+	}{
+		{"unchanged", *testWorksheet.Description},
+		{"changed", "runbook: primary datacenter"},
+		{"cleared", ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.id, func(t *testing.T) {
+			ws, err := apih.SetWorksheetDescription(CIDType(&cid), test.description)
+			if err != nil {
+				t.Fatalf("unexpected error (%s)", err)
+			}
+			if test.description == "" {
+				if ws.Description != nil {
+					t.Fatalf("expected nil description, got %q", *ws.Description)
+				}
+			} else if ws.Description == nil || *ws.Description != test.description {
+				t.Fatalf("unexpected description (%v)", ws.Description)
+			}
+		})
+	}
+}
+
+func TestFetchFavoriteWorksheets(t *testing.T) {
+	apih, server := worksheetTestBootstrap(t)
+	defer server.Close()
+
+	worksheets, err := apih.FetchFavoriteWorksheets()
+	if err != nil {
+		t.Fatalf("unexpected error (%s)", err)
+	}
+	if len(*worksheets) != 1 {
+		t.Fatalf("expected 1 worksheet, got %d", len(*worksheets))
+	}
+}